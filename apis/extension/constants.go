@@ -21,4 +21,7 @@ const (
 
 	LabelPodQoS      = DomainPrefix + "qosClass"
 	LabelPodPriority = DomainPrefix + "priority"
+
+	// AnnotationPodQoS is the legacy way to specify the koordinator QoS class, LabelPodQoS takes precedence over it.
+	AnnotationPodQoS = DomainPrefix + "qosClass"
 )
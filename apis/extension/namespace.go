@@ -0,0 +1,48 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extension
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	slov1aplhpa1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+const (
+	// AnnotationNamespaceMemoryQoSPolicy is the default memory qos policy of the pods in the namespace, which is
+	// overridden by the policy of the pod annotation AnnotationPodMemoryQoS.
+	AnnotationNamespaceMemoryQoSPolicy = DomainPrefix + "memoryQoSPolicy"
+)
+
+// GetNamespaceMemoryQoSPolicy returns the default memory qos policy of the namespace, which is empty if not set.
+func GetNamespaceMemoryQoSPolicy(namespace *corev1.Namespace) (slov1aplhpa1.PodMemoryQoSPolicy, error) {
+	if namespace == nil || namespace.Annotations == nil {
+		return "", nil
+	}
+	value, exist := namespace.Annotations[AnnotationNamespaceMemoryQoSPolicy]
+	if !exist {
+		return "", nil
+	}
+	switch policy := slov1aplhpa1.PodMemoryQoSPolicy(value); policy {
+	case slov1aplhpa1.PodMemoryQoSPolicyDefault, slov1aplhpa1.PodMemoryQoSPolicyNone, slov1aplhpa1.PodMemoryQoSPolicyAuto:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported memory qos policy %q", value)
+	}
+}
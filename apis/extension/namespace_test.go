@@ -0,0 +1,79 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slov1aplhpa1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func TestGetNamespaceMemoryQoSPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace *corev1.Namespace
+		want      slov1aplhpa1.PodMemoryQoSPolicy
+		wantErr   bool
+	}{
+		{
+			name:      "nil namespace",
+			namespace: nil,
+			want:      "",
+		},
+		{
+			name:      "annotation not set",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}},
+			want:      "",
+		},
+		{
+			name: "policy none",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-ns",
+				Annotations: map[string]string{AnnotationNamespaceMemoryQoSPolicy: "none"},
+			}},
+			want: slov1aplhpa1.PodMemoryQoSPolicyNone,
+		},
+		{
+			name: "policy auto",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-ns",
+				Annotations: map[string]string{AnnotationNamespaceMemoryQoSPolicy: "auto"},
+			}},
+			want: slov1aplhpa1.PodMemoryQoSPolicyAuto,
+		},
+		{
+			name: "unsupported policy",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-ns",
+				Annotations: map[string]string{AnnotationNamespaceMemoryQoSPolicy: "unknown"},
+			}},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := GetNamespaceMemoryQoSPolicy(tt.namespace)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, gotErr != nil)
+		})
+	}
+}
@@ -18,6 +18,9 @@ package extension
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -28,8 +31,73 @@ const (
 	AnnotationPodCPUBurst = DomainPrefix + "cpuBurst"
 
 	AnnotationPodMemoryQoS = DomainPrefix + "memoryQoS"
+
+	// AnnotationPodMemoryWmarkMinAdj overrides the `memory.wmark_min_adj` of the pod configured by its qos class,
+	// which is an integer in [-25, 50].
+	AnnotationPodMemoryWmarkMinAdj = DomainPrefix + "memory-wmark-min-adj"
+
+	// AnnotationPodSoftEvictDeadline marks the pod to be evicted by koordlet. The workload is expected to terminate
+	// itself before the deadline (in RFC3339), otherwise the pod will be evicted.
+	AnnotationPodSoftEvictDeadline = DomainPrefix + "soft-evict-deadline"
+
+	// AnnotationPodEvictionCost is the cost of evicting the pod in int32, pods with lower cost are preferred to be
+	// evicted by koordlet. It follows the convention of `controller.kubernetes.io/pod-deletion-cost`.
+	AnnotationPodEvictionCost = DomainPrefix + "eviction-cost"
+
+	// AnnotationPodCPUSetSuppressExempt exempts the cpuset of the pod from being shrunk by the BE cpu suppression if
+	// it is "true". The cpu usage of the pod is still counted as BE.
+	AnnotationPodCPUSetSuppressExempt = DomainPrefix + "cpuset-suppress-exempt"
+
+	// AnnotationPodContainerStopOrder is the comma-separated names of the containers to stop first in order when
+	// koordlet kills the pod. The other containers are stopped after them.
+	AnnotationPodContainerStopOrder = DomainPrefix + "container-stop-order"
+
+	// AnnotationPodSidecarContainers is the comma-separated names of the sidecar containers of the pod, which are
+	// stopped after the other containers when koordlet kills the pod.
+	AnnotationPodSidecarContainers = DomainPrefix + "sidecar-containers"
+
+	// AnnotationPodEvictionReason is the reason of koordlet evicting the pod, e.g. "evictPodByNodeMemoryUsage".
+	AnnotationPodEvictionReason = DomainPrefix + "eviction-reason"
+
+	// AnnotationPodEvictionMessage is the message of koordlet evicting the pod, which carries the node pressure
+	// measured at the eviction.
+	AnnotationPodEvictionMessage = DomainPrefix + "eviction-message"
 )
 
+// GetPodContainerStopOrder returns the names of the containers to stop first in order, which is nil if not set.
+func GetPodContainerStopOrder(pod *corev1.Pod) []string {
+	if pod == nil || pod.Annotations == nil {
+		return nil
+	}
+	return splitContainerNames(pod.Annotations[AnnotationPodContainerStopOrder])
+}
+
+// GetPodSidecarContainers returns the names of the sidecar containers of the pod, which is nil if not set.
+func GetPodSidecarContainers(pod *corev1.Pod) []string {
+	if pod == nil || pod.Annotations == nil {
+		return nil
+	}
+	return splitContainerNames(pod.Annotations[AnnotationPodSidecarContainers])
+}
+
+func splitContainerNames(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsPodCPUSetSuppressExempt returns whether the cpuset of the pod is exempt from the BE cpu suppression.
+func IsPodCPUSetSuppressExempt(pod *corev1.Pod) bool {
+	if pod == nil || pod.Annotations == nil {
+		return false
+	}
+	return pod.Annotations[AnnotationPodCPUSetSuppressExempt] == "true"
+}
+
 func GetPodCPUBurstConfig(pod *corev1.Pod) (*slov1aplhpa1.CPUBurstConfig, error) {
 	if pod == nil || pod.Annotations == nil {
 		return nil, nil
@@ -62,3 +130,40 @@ func GetPodMemoryQoSConfig(pod *corev1.Pod) (*slov1aplhpa1.PodMemoryQoSConfig, e
 	}
 	return &cfg, nil
 }
+
+// GetPodMemoryWmarkMinAdj returns the `memory.wmark_min_adj` overridden by the pod, which is nil if not set.
+// An error is returned if the annotation is not an integer in [-25, 50].
+func GetPodMemoryWmarkMinAdj(pod *corev1.Pod) (*int64, error) {
+	if pod == nil || pod.Annotations == nil {
+		return nil, nil
+	}
+	value, exist := pod.Annotations[AnnotationPodMemoryWmarkMinAdj]
+	if !exist {
+		return nil, nil
+	}
+	adj, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if adj < -25 || adj > 50 {
+		return nil, fmt.Errorf("memory wmark min adj %v is out of range [-25, 50]", adj)
+	}
+	return &adj, nil
+}
+
+// GetPodEvictionCost returns the eviction cost of the pod, which is 0 if the annotation is not set.
+// An error is returned if the annotation is not a valid int32, where the cost is regarded as 0.
+func GetPodEvictionCost(pod *corev1.Pod) (int32, error) {
+	if pod == nil || pod.Annotations == nil {
+		return 0, nil
+	}
+	value, exist := pod.Annotations[AnnotationPodEvictionCost]
+	if !exist {
+		return 0, nil
+	}
+	cost, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int32(cost), nil
+}
@@ -0,0 +1,227 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetPodEvictionCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		want    int32
+		wantErr bool
+	}{
+		{
+			name: "nil pod",
+			pod:  nil,
+			want: 0,
+		},
+		{
+			name: "annotation not set",
+			pod:  &corev1.Pod{},
+			want: 0,
+		},
+		{
+			name: "valid cost",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodEvictionCost: "100"},
+			}},
+			want: 100,
+		},
+		{
+			name: "valid negative cost",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodEvictionCost: "-5"},
+			}},
+			want: -5,
+		},
+		{
+			name: "invalid cost",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodEvictionCost: "high"},
+			}},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "cost overflows int32",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodEvictionCost: "4294967296"},
+			}},
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetPodEvictionCost(tt.pod)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetPodMemoryWmarkMinAdj(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		want    *int64
+		wantErr bool
+	}{
+		{
+			name: "nil pod",
+			pod:  nil,
+			want: nil,
+		},
+		{
+			name: "annotation not set",
+			pod:  &corev1.Pod{},
+			want: nil,
+		},
+		{
+			name: "valid value",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodMemoryWmarkMinAdj: "-10"},
+			}},
+			want: pointer.Int64Ptr(-10),
+		},
+		{
+			name: "valid upper bound",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodMemoryWmarkMinAdj: "50"},
+			}},
+			want: pointer.Int64Ptr(50),
+		},
+		{
+			name: "below the range",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodMemoryWmarkMinAdj: "-26"},
+			}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "above the range",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodMemoryWmarkMinAdj: "51"},
+			}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid value",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{AnnotationPodMemoryWmarkMinAdj: "high"},
+			}},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetPodMemoryWmarkMinAdj(tt.pod)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsPodCPUSetSuppressExempt(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{
+			name: "nil pod",
+			pod:  nil,
+			want: false,
+		},
+		{
+			name: "annotation not set",
+			pod:  &corev1.Pod{},
+			want: false,
+		},
+		{
+			name: "exempt",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{AnnotationPodCPUSetSuppressExempt: "true"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "not exempt",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{AnnotationPodCPUSetSuppressExempt: "false"},
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPodCPUSetSuppressExempt(tt.pod))
+		})
+	}
+}
+
+func TestGetPodContainerStopOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		pod          *corev1.Pod
+		wantOrder    []string
+		wantSidecars []string
+	}{
+		{
+			name: "nil pod",
+			pod:  nil,
+		},
+		{
+			name: "annotation not set",
+			pod:  &corev1.Pod{},
+		},
+		{
+			name: "parse container names",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						AnnotationPodContainerStopOrder: "main, worker,",
+						AnnotationPodSidecarContainers:  "proxy",
+					},
+				},
+			},
+			wantOrder:    []string{"main", "worker"},
+			wantSidecars: []string{"proxy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantOrder, GetPodContainerStopOrder(tt.pod))
+			assert.Equal(t, tt.wantSidecars, GetPodSidecarContainers(tt.pod))
+		})
+	}
+}
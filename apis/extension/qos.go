@@ -29,15 +29,44 @@ const (
 	QoSNone   QoSClass = ""
 )
 
+// QoSClassLabelKey is the label key to get the koordinator QoS class of the pod, which is LabelPodQoS by default. It
+// can be configured to classify the pods by their existing labels, e.g. when migrating from other systems.
+var QoSClassLabelKey = LabelPodQoS
+
+// DefaultQoSClass is the koordinator QoS class of the unclassified pods, which is QoSNone by default to skip them.
+// It should be chosen carefully, e.g. a critical pod taken as BE could be suppressed or evicted.
+var DefaultQoSClass = QoSNone
+
+// GetPodQoSClass returns the koordinator QoS class of the pod. A valid QoSClassLabelKey label takes precedence over
+// the LabelPodQoS label if they differ, which takes precedence over the legacy AnnotationPodQoS annotation, and
+// DefaultQoSClass is returned if none of them specifies a valid QoS class.
 func GetPodQoSClass(pod *corev1.Pod) QoSClass {
-	if pod == nil || pod.Labels == nil {
+	if pod == nil {
 		return QoSNone
 	}
-
-	if q, ok := pod.Labels[LabelPodQoS]; ok {
-		return getPodQoSClassByName(q)
+	if q := getPodClassifiedQoSClass(pod); q != QoSNone {
+		return q
 	}
+	return DefaultQoSClass
+}
+
+// IsPodQoSClassified returns whether the pod specifies a valid koordinator QoS class, regardless of DefaultQoSClass.
+func IsPodQoSClassified(pod *corev1.Pod) bool {
+	return pod != nil && getPodClassifiedQoSClass(pod) != QoSNone
+}
 
+func getPodClassifiedQoSClass(pod *corev1.Pod) QoSClass {
+	if QoSClassLabelKey != "" && QoSClassLabelKey != LabelPodQoS {
+		if q := getPodQoSClassByName(pod.Labels[QoSClassLabelKey]); q != QoSNone {
+			return q
+		}
+	}
+	if q := getPodQoSClassByName(pod.Labels[LabelPodQoS]); q != QoSNone {
+		return q
+	}
+	if q := getPodQoSClassByName(pod.Annotations[AnnotationPodQoS]); q != QoSNone {
+		return q
+	}
 	return QoSNone
 }
 
@@ -0,0 +1,225 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPodQoSClass(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want QoSClass
+	}{
+		{
+			name: "nil pod",
+			pod:  nil,
+			want: QoSNone,
+		},
+		{
+			name: "pod without labels and annotations",
+			pod:  &corev1.Pod{},
+			want: QoSNone,
+		},
+		{
+			name: "qos specified by label",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{LabelPodQoS: string(QoSLSR)},
+				},
+			},
+			want: QoSLSR,
+		},
+		{
+			name: "system qos specified by label",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{LabelPodQoS: string(QoSSystem)},
+				},
+			},
+			want: QoSSystem,
+		},
+		{
+			name: "qos specified by legacy annotation",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{AnnotationPodQoS: string(QoSBE)},
+				},
+			},
+			want: QoSBE,
+		},
+		{
+			name: "label takes precedence over legacy annotation",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{LabelPodQoS: string(QoSLS)},
+					Annotations: map[string]string{AnnotationPodQoS: string(QoSBE)},
+				},
+			},
+			want: QoSLS,
+		},
+		{
+			name: "fall back to legacy annotation if label is invalid",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{LabelPodQoS: "unknown"},
+					Annotations: map[string]string{AnnotationPodQoS: string(QoSBE)},
+				},
+			},
+			want: QoSBE,
+		},
+		{
+			name: "invalid label and annotation",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{LabelPodQoS: "unknown"},
+					Annotations: map[string]string{AnnotationPodQoS: ""},
+				},
+			},
+			want: QoSNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetPodQoSClass(tt.pod))
+		})
+	}
+}
+
+func TestGetPodQoSClass_customLabelKey(t *testing.T) {
+	defer func() { QoSClassLabelKey = LabelPodQoS }()
+	QoSClassLabelKey = "example.com/qos"
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want QoSClass
+	}{
+		{
+			name: "qos specified by custom label",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"example.com/qos": string(QoSBE)},
+				},
+			},
+			want: QoSBE,
+		},
+		{
+			name: "custom label takes precedence over koordinator label",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"example.com/qos": string(QoSBE), LabelPodQoS: string(QoSLS)},
+				},
+			},
+			want: QoSBE,
+		},
+		{
+			name: "fall back to koordinator label if custom label is invalid",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"example.com/qos": "unknown", LabelPodQoS: string(QoSLS)},
+				},
+			},
+			want: QoSLS,
+		},
+		{
+			name: "fall back to legacy annotation",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{AnnotationPodQoS: string(QoSLSR)},
+				},
+			},
+			want: QoSLSR,
+		},
+		{
+			name: "pod without labels and annotations",
+			pod:  &corev1.Pod{},
+			want: QoSNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetPodQoSClass(tt.pod))
+		})
+	}
+}
+
+func TestGetPodQoSClass_defaultQoSClass(t *testing.T) {
+	defer func() { DefaultQoSClass = QoSNone }()
+	testingUnclassifiedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{LabelPodQoS: "unknown"},
+		},
+	}
+	testingClassifiedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{LabelPodQoS: string(QoSLSR)},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		defaultQoSClass QoSClass
+		pod             *corev1.Pod
+		want            QoSClass
+		wantClassified  bool
+	}{
+		{
+			name:            "skip unclassified pod by default",
+			defaultQoSClass: QoSNone,
+			pod:             testingUnclassifiedPod,
+			want:            QoSNone,
+		},
+		{
+			name:            "treat unclassified pod as LS",
+			defaultQoSClass: QoSLS,
+			pod:             testingUnclassifiedPod,
+			want:            QoSLS,
+		},
+		{
+			name:            "treat unclassified pod as BE",
+			defaultQoSClass: QoSBE,
+			pod:             testingUnclassifiedPod,
+			want:            QoSBE,
+		},
+		{
+			name:            "classified pod ignores the default",
+			defaultQoSClass: QoSBE,
+			pod:             testingClassifiedPod,
+			want:            QoSLSR,
+			wantClassified:  true,
+		},
+		{
+			name:            "nil pod ignores the default",
+			defaultQoSClass: QoSLS,
+			pod:             nil,
+			want:            QoSNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			DefaultQoSClass = tt.defaultQoSClass
+			assert.Equal(t, tt.want, GetPodQoSClass(tt.pod))
+			assert.Equal(t, tt.wantClassified, IsPodQoSClassified(tt.pod))
+		})
+	}
+}
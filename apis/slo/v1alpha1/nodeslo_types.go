@@ -17,6 +17,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -50,6 +51,12 @@ type MemoryQoS struct {
 	// Close: 0.
 	// +kubebuilder:validation:Minimum=0
 	ThrottlingPercent *int64 `json:"throttlingPercent,omitempty"`
+	// SwapLimitPercent specifies the swapFactor percentage to calculate `memory.swap.max` with container
+	// memory.limits or node allocatable memory, which limits the swap usage of the memcg.
+	// It only takes effect when the swap accounting is available (i.e. `memory.swap.max` exists).
+	// Close: nil (keep the system default).
+	// +kubebuilder:validation:Minimum=0
+	SwapLimitPercent *int64 `json:"swapLimitPercent,omitempty"`
 
 	// wmark_ratio (Anolis OS required)
 	// Async memory reclamation is triggered when cgroup memory usage exceeds `memory.wmark_high` and the reclamation
@@ -139,6 +146,32 @@ type CPUSuppressPolicy string
 const (
 	CPUSetPolicy      CPUSuppressPolicy = "cpuset"
 	CPUCfsQuotaPolicy CPUSuppressPolicy = "cfsQuota"
+	// CPUSetAndCfsQuotaPolicy shrinks the BE cpuset and throttles the BE cfs quota with the same suppress target
+	CPUSetAndCfsQuotaPolicy CPUSuppressPolicy = "cpusetAndCfsQuota"
+)
+
+type CPUSuppressCalcPolicy string
+
+const (
+	// CPUSuppressCalcByUsage allocates the BE cpu with the usage of the LS pods
+	CPUSuppressCalcByUsage CPUSuppressCalcPolicy = "usage"
+	// CPUSuppressCalcByRequest allocates the BE cpu with the requests of the LS pods
+	CPUSuppressCalcByRequest CPUSuppressCalcPolicy = "request"
+	// CPUSuppressCalcByMax allocates the BE cpu with the larger one of the usage and the request of each LS pod
+	CPUSuppressCalcByMax CPUSuppressCalcPolicy = "max"
+)
+
+type MetricAggregation string
+
+const (
+	// MetricAggregationAvg takes the average of the metrics in the window
+	MetricAggregationAvg MetricAggregation = "avg"
+	// MetricAggregationP95 takes the 95th percentile of the metrics in the window
+	MetricAggregationP95 MetricAggregation = "p95"
+	// MetricAggregationMax takes the max of the metrics in the window
+	MetricAggregationMax MetricAggregation = "max"
+	// MetricAggregationLast takes the latest metrics in the window
+	MetricAggregationLast MetricAggregation = "last"
 )
 
 type ResourceThresholdStrategy struct {
@@ -153,12 +186,81 @@ type ResourceThresholdStrategy struct {
 	// CPUSuppressPolicy
 	CPUSuppressPolicy CPUSuppressPolicy `json:"cpuSuppressPolicy,omitempty"`
 
+	// cpu suppress step percentage (0,100] of the node cpu capacity, limits how far the BE cfs quota moves
+	// toward the suppress target in each cycle; the target is applied immediately if not set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	CPUSuppressStepPercent *int64 `json:"cpuSuppressStepPercent,omitempty"`
+
+	// CPUSuppressCalcPolicy is the basis of the LS pods cpu to calculate the BE cpu suppress, default = usage
+	CPUSuppressCalcPolicy CPUSuppressCalcPolicy `json:"cpuSuppressCalcPolicy,omitempty"`
+
+	// cpu pressure threshold percentage (0,100] of the node cpu PSI (some avg10), the BE cpu suppress is tightened
+	// proportionally when the pressure exceeds it even if the cpu usage is moderate; disabled if not set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	CPUSuppressPSIThreshold *int64 `json:"cpuSuppressPSIThreshold,omitempty"`
+
+	// system cpu reserve percentage [0,100] of the node allocatable, which is kept free for the system daemons like
+	// kubelet and containerd by subtracting it from the BE suppress cpu, default = 0
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	SystemCPUReservePercent *int64 `json:"systemCPUReservePercent,omitempty"`
+
 	// upper: memory evict threshold percentage (0,100), default = 70
-	// +kubebuilder:default=70
 	MemoryEvictThresholdPercent *int64 `json:"memoryEvictThresholdPercent,omitempty"`
 
 	// lower: memory release util usage under MemoryEvictLowerPercent, default = MemoryEvictThresholdPercent - 2
 	MemoryEvictLowerPercent *int64 `json:"memoryEvictLowerPercent,omitempty"`
+
+	// the node memory in bytes to keep free, which overrides the percentages if set: BE pods are evicted when the free
+	// memory is less than it, until at least that many bytes are free.
+	// NOTE: MemoryEvictThresholdPercent and MemoryEvictReserveBytes should not be set at the same time.
+	MemoryEvictReserveBytes *resource.Quantity `json:"memoryEvictReserveBytes,omitempty"`
+
+	// the window in seconds to average the metrics for cpu suppress, default = the last collected metrics
+	// +kubebuilder:validation:Minimum=1
+	CPUSuppressMetricWindowSeconds *int64 `json:"cpuSuppressMetricWindowSeconds,omitempty"`
+
+	// the function to aggregate the metrics in CPUSuppressMetricWindowSeconds for cpu suppress, default = avg
+	CPUSuppressMetricAggregation MetricAggregation `json:"cpuSuppressMetricAggregation,omitempty"`
+
+	// the window in seconds to average the metrics for memory evict, default = the last collected metrics
+	// +kubebuilder:validation:Minimum=1
+	MemoryEvictMetricWindowSeconds *int64 `json:"memoryEvictMetricWindowSeconds,omitempty"`
+
+	// the function to aggregate the metrics in MemoryEvictMetricWindowSeconds for memory evict, default = avg
+	MemoryEvictMetricAggregation MetricAggregation `json:"memoryEvictMetricAggregation,omitempty"`
+
+	// prefer evicting the BE pods whose memory grows the fastest in MemoryEvictGrowthWindowSeconds when the node
+	// memory usage is rising, default = false
+	MemoryEvictPreferFastGrowers *bool `json:"memoryEvictPreferFastGrowers,omitempty"`
+
+	// the window in seconds to measure the memory growth of the node and pods, default = 60
+	// +kubebuilder:validation:Minimum=1
+	MemoryEvictGrowthWindowSeconds *int64 `json:"memoryEvictGrowthWindowSeconds,omitempty"`
+
+	// disk evict threshold percentage (0,100) of the node ephemeral storage, disk evict is disabled if not set
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	DiskUsedThresholdPercent *int64 `json:"diskUsedThresholdPercent,omitempty"`
+
+	// the grace period seconds of the evicted pods for each qos class, which overrides the
+	// terminationGracePeriodSeconds of the pods; the grace period of the pod is used if its qos class is not set
+	EvictGracePeriodSeconds *EvictGracePeriodSeconds `json:"evictGracePeriodSeconds,omitempty"`
+}
+
+// EvictGracePeriodSeconds is the grace period seconds of the evicted pods for each qos class.
+type EvictGracePeriodSeconds struct {
+	// grace period seconds for LSR pods
+	// +kubebuilder:validation:Minimum=0
+	LSR *int64 `json:"lsr,omitempty"`
+	// grace period seconds for LS pods
+	// +kubebuilder:validation:Minimum=0
+	LS *int64 `json:"ls,omitempty"`
+	// grace period seconds for BE pods
+	// +kubebuilder:validation:Minimum=0
+	BE *int64 `json:"be,omitempty"`
 }
 
 // ResctrlQoSCfg stores node-level config of resctrl qos
@@ -184,6 +286,10 @@ type ResctrlQoS struct {
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
 	MBAPercent *int64 `json:"mbaPercent,omitempty"`
+	// MBA bandwidth limit in MBps, only takes effect when the resctrl is mounted with the `mba_MBps` option.
+	// NOTE: MBAPercent and MBAMBps should not be set at the same time.
+	// +kubebuilder:validation:Minimum=0
+	MBAMBps *int64 `json:"mbaMBps,omitempty"`
 }
 
 type CPUBurstPolicy string
@@ -212,21 +318,46 @@ type CPUBurstConfig struct {
 	CFSQuotaBurstPeriodSeconds *int64 `json:"cfsQuotaBurstPeriodSeconds,omitempty"`
 }
 
+// CPUBurstPriorityScale scales the burst headroom of the pods linearly by the pod priority between MinPriority and
+// MaxPriority, i.e. the CPUBurstPercent and the part of CFSQuotaBurstPercent above 100.
+type CPUBurstPriorityScale struct {
+	// the pod priority to scale by MinScalePercent, which also applies to the pods of the lower priorities
+	MinPriority *int32 `json:"minPriority,omitempty"`
+	// the pod priority to scale by MaxScalePercent, which also applies to the pods of the higher priorities
+	MaxPriority *int32 `json:"maxPriority,omitempty"`
+	// the scale percentage of the burst headroom for the pods of MinPriority, default = 100
+	// +kubebuilder:validation:Minimum=0
+	MinScalePercent *int64 `json:"minScalePercent,omitempty"`
+	// the scale percentage of the burst headroom for the pods of MaxPriority, default = 100
+	// +kubebuilder:validation:Minimum=0
+	MaxScalePercent *int64 `json:"maxScalePercent,omitempty"`
+}
+
 type CPUBurstStrategy struct {
 	CPUBurstConfig `json:",inline"`
 	// scale down cfs quota if node cpu overload, default = 50
 	// +kubebuilder:default=50
 	SharePoolThresholdPercent *int64 `json:"sharePoolThresholdPercent,omitempty"`
+	// PriorityScale scales the burst percentages of the pods by the pod priority, unless the pod specifies them;
+	// all pods use the same burst percentages if not set
+	PriorityScale *CPUBurstPriorityScale `json:"priorityScale,omitempty"`
 }
 
 // NodeSLOSpec defines the desired state of NodeSLO
 type NodeSLOSpec struct {
 	// BE pods will be limited if node resource usage overload
 	ResourceUsedThresholdWithBE *ResourceThresholdStrategy `json:"resourceUsedThresholdWithBE,omitempty"`
+	// ShadowResourceUsedThresholdWithBE is evaluated against the live metrics without being applied, and the decisions
+	// under it are reported along with the active ones for canarying a new threshold config. The unset fields are
+	// merged with the default config.
+	ShadowResourceUsedThresholdWithBE *ResourceThresholdStrategy `json:"shadowResourceUsedThresholdWithBE,omitempty"`
 	// QoS config strategy for pods of different qos-class
 	ResourceQoSStrategy *ResourceQoSStrategy `json:"resourceQoSStrategy,omitempty"`
 	// CPU Burst Strategy
 	CPUBurstStrategy *CPUBurstStrategy `json:"cpuBurstStrategy,omitempty"`
+	// FeatureGates enables the koordlet features on the node at runtime, which are disabled by the koordlet
+	// feature gates flag, e.g. {"BECPUSuppress": true}. It can only enable features rather than disable them.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
 }
 
 // NodeSLOStatus defines the observed state of NodeSLO
@@ -0,0 +1,227 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateNodeSLOSpec checks the consistency of the NodeSLO spec, e.g. the lower threshold should be less than the upper
+// one, the percentages should be in range. It can be used by the admission webhook and the koordlet.
+func ValidateNodeSLOSpec(spec *NodeSLOSpec) field.ErrorList {
+	specPath := field.NewPath("spec")
+	if spec == nil {
+		return field.ErrorList{field.Required(specPath, "nodeSLO spec is nil")}
+	}
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateResourceThresholdStrategy(spec.ResourceUsedThresholdWithBE,
+		specPath.Child("resourceUsedThresholdWithBE"))...)
+	allErrs = append(allErrs, validateResourceThresholdStrategy(spec.ShadowResourceUsedThresholdWithBE,
+		specPath.Child("shadowResourceUsedThresholdWithBE"))...)
+	allErrs = append(allErrs, validateResourceQoSStrategy(spec.ResourceQoSStrategy, specPath.Child("resourceQoSStrategy"))...)
+	allErrs = append(allErrs, validateCPUBurstStrategy(spec.CPUBurstStrategy, specPath.Child("cpuBurstStrategy"))...)
+	return allErrs
+}
+
+func validateResourceThresholdStrategy(threshold *ResourceThresholdStrategy, fldPath *field.Path) field.ErrorList {
+	if threshold == nil {
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateRange(threshold.CPUSuppressThresholdPercent, 0, 100, fldPath.Child("cpuSuppressThresholdPercent"))...)
+	allErrs = append(allErrs, validateRange(threshold.CPUSuppressStepPercent, 1, 100, fldPath.Child("cpuSuppressStepPercent"))...)
+	allErrs = append(allErrs, validateRange(threshold.CPUSuppressPSIThreshold, 1, 100, fldPath.Child("cpuSuppressPSIThreshold"))...)
+	allErrs = append(allErrs, validateRange(threshold.SystemCPUReservePercent, 0, 100, fldPath.Child("systemCPUReservePercent"))...)
+	allErrs = append(allErrs, validateRange(threshold.MemoryEvictThresholdPercent, 0, 100, fldPath.Child("memoryEvictThresholdPercent"))...)
+	allErrs = append(allErrs, validateRange(threshold.MemoryEvictLowerPercent, 0, 100, fldPath.Child("memoryEvictLowerPercent"))...)
+	allErrs = append(allErrs, validateRange(threshold.DiskUsedThresholdPercent, 0, 100, fldPath.Child("diskUsedThresholdPercent"))...)
+	if threshold.MemoryEvictLowerPercent != nil && threshold.MemoryEvictThresholdPercent != nil &&
+		*threshold.MemoryEvictLowerPercent >= *threshold.MemoryEvictThresholdPercent {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("memoryEvictLowerPercent"), *threshold.MemoryEvictLowerPercent,
+			fmt.Sprintf("must be less than memoryEvictThresholdPercent %d", *threshold.MemoryEvictThresholdPercent)))
+	}
+	if threshold.MemoryEvictReserveBytes != nil {
+		if threshold.MemoryEvictThresholdPercent != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("memoryEvictReserveBytes"),
+				"may not be set with memoryEvictThresholdPercent at the same time"))
+		}
+		if threshold.MemoryEvictReserveBytes.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("memoryEvictReserveBytes"),
+				threshold.MemoryEvictReserveBytes.String(), "must be no less than 0"))
+		}
+	}
+	if gracePeriod := threshold.EvictGracePeriodSeconds; gracePeriod != nil {
+		gracePeriodPath := fldPath.Child("evictGracePeriodSeconds")
+		allErrs = append(allErrs, validateMinimum(gracePeriod.LSR, 0, gracePeriodPath.Child("lsr"))...)
+		allErrs = append(allErrs, validateMinimum(gracePeriod.LS, 0, gracePeriodPath.Child("ls"))...)
+		allErrs = append(allErrs, validateMinimum(gracePeriod.BE, 0, gracePeriodPath.Child("be"))...)
+	}
+	if threshold.CPUSuppressPolicy != "" && threshold.CPUSuppressPolicy != CPUSetPolicy &&
+		threshold.CPUSuppressPolicy != CPUCfsQuotaPolicy && threshold.CPUSuppressPolicy != CPUSetAndCfsQuotaPolicy {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("cpuSuppressPolicy"), threshold.CPUSuppressPolicy,
+			[]string{string(CPUSetPolicy), string(CPUCfsQuotaPolicy), string(CPUSetAndCfsQuotaPolicy)}))
+	}
+	if threshold.CPUSuppressCalcPolicy != "" && threshold.CPUSuppressCalcPolicy != CPUSuppressCalcByUsage &&
+		threshold.CPUSuppressCalcPolicy != CPUSuppressCalcByRequest && threshold.CPUSuppressCalcPolicy != CPUSuppressCalcByMax {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("cpuSuppressCalcPolicy"), threshold.CPUSuppressCalcPolicy,
+			[]string{string(CPUSuppressCalcByUsage), string(CPUSuppressCalcByRequest), string(CPUSuppressCalcByMax)}))
+	}
+	if threshold.CPUSuppressMetricWindowSeconds != nil && *threshold.CPUSuppressMetricWindowSeconds < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cpuSuppressMetricWindowSeconds"),
+			*threshold.CPUSuppressMetricWindowSeconds, "must be no less than 1"))
+	}
+	if threshold.MemoryEvictMetricWindowSeconds != nil && *threshold.MemoryEvictMetricWindowSeconds < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("memoryEvictMetricWindowSeconds"),
+			*threshold.MemoryEvictMetricWindowSeconds, "must be no less than 1"))
+	}
+	if threshold.MemoryEvictGrowthWindowSeconds != nil && *threshold.MemoryEvictGrowthWindowSeconds < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("memoryEvictGrowthWindowSeconds"),
+			*threshold.MemoryEvictGrowthWindowSeconds, "must be no less than 1"))
+	}
+	allErrs = append(allErrs, validateMetricAggregation(threshold.CPUSuppressMetricAggregation,
+		fldPath.Child("cpuSuppressMetricAggregation"))...)
+	allErrs = append(allErrs, validateMetricAggregation(threshold.MemoryEvictMetricAggregation,
+		fldPath.Child("memoryEvictMetricAggregation"))...)
+	return allErrs
+}
+
+func validateMetricAggregation(aggregation MetricAggregation, fldPath *field.Path) field.ErrorList {
+	switch aggregation {
+	case "", MetricAggregationAvg, MetricAggregationP95, MetricAggregationMax, MetricAggregationLast:
+		return nil
+	}
+	return field.ErrorList{field.NotSupported(fldPath, aggregation, []string{string(MetricAggregationAvg),
+		string(MetricAggregationP95), string(MetricAggregationMax), string(MetricAggregationLast)})}
+}
+
+func validateResourceQoSStrategy(strategy *ResourceQoSStrategy, fldPath *field.Path) field.ErrorList {
+	if strategy == nil {
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateResourceQoS(strategy.LSR, fldPath.Child("lsr"))...)
+	allErrs = append(allErrs, validateResourceQoS(strategy.LS, fldPath.Child("ls"))...)
+	allErrs = append(allErrs, validateResourceQoS(strategy.BE, fldPath.Child("be"))...)
+	allErrs = append(allErrs, validateResourceQoS(strategy.System, fldPath.Child("system"))...)
+	allErrs = append(allErrs, validateResourceQoS(strategy.CgroupRoot, fldPath.Child("cgroupRoot"))...)
+	return allErrs
+}
+
+func validateResourceQoS(qos *ResourceQoS, fldPath *field.Path) field.ErrorList {
+	if qos == nil {
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	if qos.MemoryQoS != nil {
+		allErrs = append(allErrs, validateMemoryQoS(&qos.MemoryQoS.MemoryQoS, fldPath.Child("memoryQoS"))...)
+	}
+	if qos.ResctrlQoS != nil {
+		allErrs = append(allErrs, validateResctrlQoS(&qos.ResctrlQoS.ResctrlQoS, fldPath.Child("resctrlQoS"))...)
+	}
+	return allErrs
+}
+
+func validateMemoryQoS(memoryQoS *MemoryQoS, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateMinimum(memoryQoS.MinLimitPercent, 0, fldPath.Child("minLimitPercent"))...)
+	allErrs = append(allErrs, validateMinimum(memoryQoS.LowLimitPercent, 0, fldPath.Child("lowLimitPercent"))...)
+	allErrs = append(allErrs, validateMinimum(memoryQoS.ThrottlingPercent, 0, fldPath.Child("throttlingPercent"))...)
+	allErrs = append(allErrs, validateMinimum(memoryQoS.SwapLimitPercent, 0, fldPath.Child("swapLimitPercent"))...)
+	allErrs = append(allErrs, validateRange(memoryQoS.WmarkRatio, 0, 100, fldPath.Child("wmarkRatio"))...)
+	allErrs = append(allErrs, validateRange(memoryQoS.WmarkScalePermill, 1, 1000, fldPath.Child("wmarkScalePermill"))...)
+	allErrs = append(allErrs, validateRange(memoryQoS.WmarkMinAdj, -25, 50, fldPath.Child("wmarkMinAdj"))...)
+	return allErrs
+}
+
+func validateResctrlQoS(resctrlQoS *ResctrlQoS, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateRange(resctrlQoS.CATRangeStartPercent, 0, 100, fldPath.Child("catRangeStartPercent"))...)
+	allErrs = append(allErrs, validateRange(resctrlQoS.CATRangeEndPercent, 0, 100, fldPath.Child("catRangeEndPercent"))...)
+	allErrs = append(allErrs, validateRange(resctrlQoS.MBAPercent, 0, 100, fldPath.Child("mbaPercent"))...)
+	allErrs = append(allErrs, validateMinimum(resctrlQoS.MBAMBps, 0, fldPath.Child("mbaMBps"))...)
+	allErrs = append(allErrs, ValidateResctrlMBA(resctrlQoS, fldPath)...)
+	if resctrlQoS.CATRangeStartPercent != nil && resctrlQoS.CATRangeEndPercent != nil &&
+		*resctrlQoS.CATRangeStartPercent >= *resctrlQoS.CATRangeEndPercent {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("catRangeStartPercent"), *resctrlQoS.CATRangeStartPercent,
+			fmt.Sprintf("must be less than catRangeEndPercent %d", *resctrlQoS.CATRangeEndPercent)))
+	}
+	return allErrs
+}
+
+// ValidateResctrlMBA checks that only one of MBAPercent and MBAMBps is set, since they are mutually exclusive. It is
+// also checked by the koordlet before applying the MB schemata.
+func ValidateResctrlMBA(resctrlQoS *ResctrlQoS, fldPath *field.Path) field.ErrorList {
+	if resctrlQoS == nil || resctrlQoS.MBAPercent == nil || resctrlQoS.MBAMBps == nil {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath.Child("mbaMBps"), *resctrlQoS.MBAMBps,
+		fmt.Sprintf("may not be set with mbaPercent %d at the same time", *resctrlQoS.MBAPercent))}
+}
+
+func validateCPUBurstStrategy(strategy *CPUBurstStrategy, fldPath *field.Path) field.ErrorList {
+	if strategy == nil {
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	switch strategy.Policy {
+	case "", CPUBurstNone, CPUBurstOnly, CFSQuotaBurstOnly, CPUBurstAuto:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("policy"), strategy.Policy,
+			[]string{string(CPUBurstNone), string(CPUBurstOnly), string(CFSQuotaBurstOnly), string(CPUBurstAuto)}))
+	}
+	allErrs = append(allErrs, validateRange(strategy.CPUBurstPercent, 0, 10000, fldPath.Child("cpuBurstPercent"))...)
+	allErrs = append(allErrs, validateMinimum(strategy.CFSQuotaBurstPercent, 0, fldPath.Child("cfsQuotaBurstPercent"))...)
+	allErrs = append(allErrs, validateRange(strategy.SharePoolThresholdPercent, 0, 100, fldPath.Child("sharePoolThresholdPercent"))...)
+	allErrs = append(allErrs, validateCPUBurstPriorityScale(strategy.PriorityScale, fldPath.Child("priorityScale"))...)
+	return allErrs
+}
+
+func validateCPUBurstPriorityScale(scale *CPUBurstPriorityScale, fldPath *field.Path) field.ErrorList {
+	if scale == nil {
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	if scale.MinPriority == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("minPriority"), "must be set to scale by priority"))
+	}
+	if scale.MaxPriority == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("maxPriority"), "must be set to scale by priority"))
+	}
+	if scale.MinPriority != nil && scale.MaxPriority != nil && *scale.MinPriority >= *scale.MaxPriority {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minPriority"), *scale.MinPriority,
+			fmt.Sprintf("must be less than maxPriority %d", *scale.MaxPriority)))
+	}
+	allErrs = append(allErrs, validateMinimum(scale.MinScalePercent, 0, fldPath.Child("minScalePercent"))...)
+	allErrs = append(allErrs, validateMinimum(scale.MaxScalePercent, 0, fldPath.Child("maxScalePercent"))...)
+	return allErrs
+}
+
+func validateRange(value *int64, min, max int64, fldPath *field.Path) field.ErrorList {
+	if value == nil || (*value >= min && *value <= max) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath, *value, fmt.Sprintf("must be in range [%d, %d]", min, max))}
+}
+
+func validateMinimum(value *int64, min int64, fldPath *field.Path) field.ErrorList {
+	if value == nil || *value >= min {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath, *value, fmt.Sprintf("must be no less than %d", min))}
+}
@@ -0,0 +1,489 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateNodeSLOSpec(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       *NodeSLOSpec
+		wantFields []string
+	}{
+		{
+			name:       "nil spec",
+			spec:       nil,
+			wantFields: []string{"spec"},
+		},
+		{
+			name: "empty spec",
+			spec: &NodeSLOSpec{},
+		},
+		{
+			name: "valid spec",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					Enable:                         pointer.BoolPtr(true),
+					CPUSuppressThresholdPercent:    pointer.Int64Ptr(65),
+					CPUSuppressPolicy:              CPUSetPolicy,
+					MemoryEvictThresholdPercent:    pointer.Int64Ptr(70),
+					MemoryEvictLowerPercent:        pointer.Int64Ptr(68),
+					CPUSuppressMetricWindowSeconds: pointer.Int64Ptr(60),
+					MemoryEvictMetricWindowSeconds: pointer.Int64Ptr(60),
+					DiskUsedThresholdPercent:       pointer.Int64Ptr(80),
+				},
+				ResourceQoSStrategy: &ResourceQoSStrategy{
+					LS: &ResourceQoS{
+						MemoryQoS: &MemoryQoSCfg{
+							MemoryQoS: MemoryQoS{
+								MinLimitPercent:   pointer.Int64Ptr(100),
+								LowLimitPercent:   pointer.Int64Ptr(0),
+								ThrottlingPercent: pointer.Int64Ptr(80),
+								SwapLimitPercent:  pointer.Int64Ptr(50),
+								WmarkRatio:        pointer.Int64Ptr(95),
+								WmarkScalePermill: pointer.Int64Ptr(20),
+								WmarkMinAdj:       pointer.Int64Ptr(-25),
+							},
+						},
+					},
+					BE: &ResourceQoS{
+						ResctrlQoS: &ResctrlQoSCfg{
+							ResctrlQoS: ResctrlQoS{
+								CATRangeStartPercent: pointer.Int64Ptr(0),
+								CATRangeEndPercent:   pointer.Int64Ptr(30),
+								MBAPercent:           pointer.Int64Ptr(100),
+							},
+						},
+					},
+				},
+				CPUBurstStrategy: &CPUBurstStrategy{
+					CPUBurstConfig: CPUBurstConfig{
+						Policy:                     CPUBurstAuto,
+						CPUBurstPercent:            pointer.Int64Ptr(1000),
+						CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+						CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(-1),
+					},
+					SharePoolThresholdPercent: pointer.Int64Ptr(50),
+				},
+			},
+		},
+		{
+			name: "threshold percentages out of range",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(101),
+					MemoryEvictThresholdPercent: pointer.Int64Ptr(200),
+					DiskUsedThresholdPercent:    pointer.Int64Ptr(-1),
+				},
+			},
+			wantFields: []string{
+				"spec.resourceUsedThresholdWithBE.cpuSuppressThresholdPercent",
+				"spec.resourceUsedThresholdWithBE.memoryEvictThresholdPercent",
+				"spec.resourceUsedThresholdWithBE.diskUsedThresholdPercent",
+			},
+		},
+		{
+			name: "shadow threshold percentages out of range",
+			spec: &NodeSLOSpec{
+				ShadowResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(101),
+				},
+			},
+			wantFields: []string{"spec.shadowResourceUsedThresholdWithBE.cpuSuppressThresholdPercent"},
+		},
+		{
+			name: "memory evict lower percent equals to the upper",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					MemoryEvictThresholdPercent: pointer.Int64Ptr(70),
+					MemoryEvictLowerPercent:     pointer.Int64Ptr(70),
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.memoryEvictLowerPercent"},
+		},
+		{
+			name: "memory evict lower percent larger than the upper",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					MemoryEvictThresholdPercent: pointer.Int64Ptr(70),
+					MemoryEvictLowerPercent:     pointer.Int64Ptr(80),
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.memoryEvictLowerPercent"},
+		},
+		{
+			name: "memory evict reserve bytes",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					MemoryEvictReserveBytes: resource.NewQuantity(1<<30, resource.BinarySI),
+				},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "memory evict reserve bytes set with the threshold percent",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					MemoryEvictThresholdPercent: pointer.Int64Ptr(70),
+					MemoryEvictReserveBytes:     resource.NewQuantity(1<<30, resource.BinarySI),
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.memoryEvictReserveBytes"},
+		},
+		{
+			name: "negative memory evict reserve bytes",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					MemoryEvictReserveBytes: resource.NewQuantity(-1, resource.BinarySI),
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.memoryEvictReserveBytes"},
+		},
+		{
+			name: "negative evict grace period seconds",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					EvictGracePeriodSeconds: &EvictGracePeriodSeconds{
+						LS: pointer.Int64Ptr(30),
+						BE: pointer.Int64Ptr(-1),
+					},
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.evictGracePeriodSeconds.be"},
+		},
+		{
+			name: "combined cpu suppress policy",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressPolicy: CPUSetAndCfsQuotaPolicy,
+				},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "unknown cpu suppress policy",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressPolicy: "unknown",
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.cpuSuppressPolicy"},
+		},
+		{
+			name: "request cpu suppress calc policy",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressCalcPolicy: CPUSuppressCalcByRequest,
+				},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "unknown cpu suppress calc policy",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressCalcPolicy: "unknown",
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.cpuSuppressCalcPolicy"},
+		},
+		{
+			name: "supported metric aggregations",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressMetricAggregation: MetricAggregationP95,
+					MemoryEvictMetricAggregation: MetricAggregationMax,
+				},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "unknown metric aggregations",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressMetricAggregation: "p99",
+					MemoryEvictMetricAggregation: "min",
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.cpuSuppressMetricAggregation",
+				"spec.resourceUsedThresholdWithBE.memoryEvictMetricAggregation"},
+		},
+		{
+			name: "cpu suppress step percent out of range",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressStepPercent: pointer.Int64Ptr(0),
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.cpuSuppressStepPercent"},
+		},
+		{
+			name: "cpu suppress psi threshold out of range",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressPSIThreshold: pointer.Int64Ptr(0),
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.cpuSuppressPSIThreshold"},
+		},
+		{
+			name: "system cpu reserve percent out of range",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					SystemCPUReservePercent: pointer.Int64Ptr(101),
+				},
+			},
+			wantFields: []string{"spec.resourceUsedThresholdWithBE.systemCPUReservePercent"},
+		},
+		{
+			name: "metric windows less than 1",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					CPUSuppressMetricWindowSeconds: pointer.Int64Ptr(0),
+					MemoryEvictMetricWindowSeconds: pointer.Int64Ptr(-1),
+					MemoryEvictGrowthWindowSeconds: pointer.Int64Ptr(0),
+				},
+			},
+			wantFields: []string{
+				"spec.resourceUsedThresholdWithBE.cpuSuppressMetricWindowSeconds",
+				"spec.resourceUsedThresholdWithBE.memoryEvictMetricWindowSeconds",
+				"spec.resourceUsedThresholdWithBE.memoryEvictGrowthWindowSeconds",
+			},
+		},
+		{
+			name: "memory qos values out of range",
+			spec: &NodeSLOSpec{
+				ResourceQoSStrategy: &ResourceQoSStrategy{
+					LSR: &ResourceQoS{
+						MemoryQoS: &MemoryQoSCfg{
+							MemoryQoS: MemoryQoS{
+								MinLimitPercent:   pointer.Int64Ptr(-1),
+								LowLimitPercent:   pointer.Int64Ptr(-1),
+								ThrottlingPercent: pointer.Int64Ptr(-1),
+								SwapLimitPercent:  pointer.Int64Ptr(-1),
+								WmarkRatio:        pointer.Int64Ptr(101),
+								WmarkScalePermill: pointer.Int64Ptr(0),
+								WmarkMinAdj:       pointer.Int64Ptr(51),
+							},
+						},
+					},
+				},
+			},
+			wantFields: []string{
+				"spec.resourceQoSStrategy.lsr.memoryQoS.minLimitPercent",
+				"spec.resourceQoSStrategy.lsr.memoryQoS.lowLimitPercent",
+				"spec.resourceQoSStrategy.lsr.memoryQoS.throttlingPercent",
+				"spec.resourceQoSStrategy.lsr.memoryQoS.swapLimitPercent",
+				"spec.resourceQoSStrategy.lsr.memoryQoS.wmarkRatio",
+				"spec.resourceQoSStrategy.lsr.memoryQoS.wmarkScalePermill",
+				"spec.resourceQoSStrategy.lsr.memoryQoS.wmarkMinAdj",
+			},
+		},
+		{
+			name: "resctrl qos values out of range",
+			spec: &NodeSLOSpec{
+				ResourceQoSStrategy: &ResourceQoSStrategy{
+					LS: &ResourceQoS{
+						ResctrlQoS: &ResctrlQoSCfg{
+							ResctrlQoS: ResctrlQoS{
+								MBAMBps: pointer.Int64Ptr(-1),
+							},
+						},
+					},
+					System: &ResourceQoS{
+						ResctrlQoS: &ResctrlQoSCfg{
+							ResctrlQoS: ResctrlQoS{
+								CATRangeStartPercent: pointer.Int64Ptr(-10),
+								CATRangeEndPercent:   pointer.Int64Ptr(110),
+								MBAPercent:           pointer.Int64Ptr(101),
+							},
+						},
+					},
+				},
+			},
+			wantFields: []string{
+				"spec.resourceQoSStrategy.ls.resctrlQoS.mbaMBps",
+				"spec.resourceQoSStrategy.system.resctrlQoS.catRangeStartPercent",
+				"spec.resourceQoSStrategy.system.resctrlQoS.catRangeEndPercent",
+				"spec.resourceQoSStrategy.system.resctrlQoS.mbaPercent",
+			},
+		},
+		{
+			name: "cat range start is not less than the end",
+			spec: &NodeSLOSpec{
+				ResourceQoSStrategy: &ResourceQoSStrategy{
+					BE: &ResourceQoS{
+						ResctrlQoS: &ResctrlQoSCfg{
+							ResctrlQoS: ResctrlQoS{
+								CATRangeStartPercent: pointer.Int64Ptr(30),
+								CATRangeEndPercent:   pointer.Int64Ptr(30),
+							},
+						},
+					},
+					CgroupRoot: &ResourceQoS{
+						ResctrlQoS: &ResctrlQoSCfg{
+							ResctrlQoS: ResctrlQoS{
+								CATRangeStartPercent: pointer.Int64Ptr(50),
+								CATRangeEndPercent:   pointer.Int64Ptr(20),
+							},
+						},
+					},
+				},
+			},
+			wantFields: []string{
+				"spec.resourceQoSStrategy.be.resctrlQoS.catRangeStartPercent",
+				"spec.resourceQoSStrategy.cgroupRoot.resctrlQoS.catRangeStartPercent",
+			},
+		},
+		{
+			name: "unknown cpu burst policy",
+			spec: &NodeSLOSpec{
+				CPUBurstStrategy: &CPUBurstStrategy{
+					CPUBurstConfig: CPUBurstConfig{
+						Policy: "unknown",
+					},
+				},
+			},
+			wantFields: []string{"spec.cpuBurstStrategy.policy"},
+		},
+		{
+			name: "cpu burst values out of range",
+			spec: &NodeSLOSpec{
+				CPUBurstStrategy: &CPUBurstStrategy{
+					CPUBurstConfig: CPUBurstConfig{
+						Policy:               CPUBurstOnly,
+						CPUBurstPercent:      pointer.Int64Ptr(10001),
+						CFSQuotaBurstPercent: pointer.Int64Ptr(-1),
+					},
+					SharePoolThresholdPercent: pointer.Int64Ptr(101),
+				},
+			},
+			wantFields: []string{
+				"spec.cpuBurstStrategy.cpuBurstPercent",
+				"spec.cpuBurstStrategy.cfsQuotaBurstPercent",
+				"spec.cpuBurstStrategy.sharePoolThresholdPercent",
+			},
+		},
+		{
+			name: "invalid cpu burst priority scale",
+			spec: &NodeSLOSpec{
+				CPUBurstStrategy: &CPUBurstStrategy{
+					PriorityScale: &CPUBurstPriorityScale{
+						MinPriority:     pointer.Int32Ptr(9000),
+						MaxPriority:     pointer.Int32Ptr(5000),
+						MaxScalePercent: pointer.Int64Ptr(-1),
+					},
+				},
+			},
+			wantFields: []string{
+				"spec.cpuBurstStrategy.priorityScale.minPriority",
+				"spec.cpuBurstStrategy.priorityScale.maxScalePercent",
+			},
+		},
+		{
+			name: "cpu burst priority scale without priorities",
+			spec: &NodeSLOSpec{
+				CPUBurstStrategy: &CPUBurstStrategy{
+					PriorityScale: &CPUBurstPriorityScale{
+						MinScalePercent: pointer.Int64Ptr(50),
+					},
+				},
+			},
+			wantFields: []string{
+				"spec.cpuBurstStrategy.priorityScale.minPriority",
+				"spec.cpuBurstStrategy.priorityScale.maxPriority",
+			},
+		},
+		{
+			name: "errors of multiple strategies",
+			spec: &NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &ResourceThresholdStrategy{
+					MemoryEvictThresholdPercent: pointer.Int64Ptr(60),
+					MemoryEvictLowerPercent:     pointer.Int64Ptr(65),
+				},
+				ResourceQoSStrategy: &ResourceQoSStrategy{
+					BE: &ResourceQoS{
+						ResctrlQoS: &ResctrlQoSCfg{
+							ResctrlQoS: ResctrlQoS{
+								CATRangeStartPercent: pointer.Int64Ptr(80),
+								CATRangeEndPercent:   pointer.Int64Ptr(20),
+							},
+						},
+					},
+				},
+				CPUBurstStrategy: &CPUBurstStrategy{
+					CPUBurstConfig: CPUBurstConfig{
+						Policy: "unknown",
+					},
+				},
+			},
+			wantFields: []string{
+				"spec.resourceUsedThresholdWithBE.memoryEvictLowerPercent",
+				"spec.resourceQoSStrategy.be.resctrlQoS.catRangeStartPercent",
+				"spec.cpuBurstStrategy.policy",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateNodeSLOSpec(tt.spec)
+			assert.Equal(t, tt.wantFields, getErrorFields(got))
+		})
+	}
+}
+
+func getErrorFields(errs field.ErrorList) []string {
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	return fields
+}
+
+func TestValidateNodeSLOSpec_mbaMutuallyExclusive(t *testing.T) {
+	errs := ValidateNodeSLOSpec(&NodeSLOSpec{
+		ResourceQoSStrategy: &ResourceQoSStrategy{
+			BE: &ResourceQoS{
+				ResctrlQoS: &ResctrlQoSCfg{
+					ResctrlQoS: ResctrlQoS{
+						MBAPercent: pointer.Int64Ptr(50),
+						MBAMBps:    pointer.Int64Ptr(1000),
+					},
+				},
+			},
+		},
+	})
+	assert.Equal(t, []string{"spec.resourceQoSStrategy.be.resctrlQoS.mbaMBps"}, getErrorFields(errs))
+	assert.Equal(t, field.ErrorTypeInvalid, errs[0].Type)
+	assert.Equal(t, int64(1000), errs[0].BadValue)
+}
+
+func TestValidateResctrlMBA(t *testing.T) {
+	fldPath := field.NewPath("be")
+	assert.Empty(t, ValidateResctrlMBA(nil, fldPath))
+	assert.Empty(t, ValidateResctrlMBA(&ResctrlQoS{MBAPercent: pointer.Int64Ptr(50)}, fldPath))
+	assert.Empty(t, ValidateResctrlMBA(&ResctrlQoS{MBAMBps: pointer.Int64Ptr(1000)}, fldPath))
+	got := ValidateResctrlMBA(&ResctrlQoS{MBAPercent: pointer.Int64Ptr(50), MBAMBps: pointer.Int64Ptr(1000)}, fldPath)
+	assert.Equal(t, []string{"be.mbaMBps"}, getErrorFields(got))
+}
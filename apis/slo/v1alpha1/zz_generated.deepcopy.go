@@ -56,6 +56,41 @@ func (in *CPUBurstConfig) DeepCopy() *CPUBurstConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPUBurstPriorityScale) DeepCopyInto(out *CPUBurstPriorityScale) {
+	*out = *in
+	if in.MinPriority != nil {
+		in, out := &in.MinPriority, &out.MinPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPriority != nil {
+		in, out := &in.MaxPriority, &out.MaxPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinScalePercent != nil {
+		in, out := &in.MinScalePercent, &out.MinScalePercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxScalePercent != nil {
+		in, out := &in.MaxScalePercent, &out.MaxScalePercent
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUBurstPriorityScale.
+func (in *CPUBurstPriorityScale) DeepCopy() *CPUBurstPriorityScale {
+	if in == nil {
+		return nil
+	}
+	out := new(CPUBurstPriorityScale)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CPUBurstStrategy) DeepCopyInto(out *CPUBurstStrategy) {
 	*out = *in
@@ -65,6 +100,11 @@ func (in *CPUBurstStrategy) DeepCopyInto(out *CPUBurstStrategy) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.PriorityScale != nil {
+		in, out := &in.PriorityScale, &out.PriorityScale
+		*out = new(CPUBurstPriorityScale)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUBurstStrategy.
@@ -77,6 +117,36 @@ func (in *CPUBurstStrategy) DeepCopy() *CPUBurstStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictGracePeriodSeconds) DeepCopyInto(out *EvictGracePeriodSeconds) {
+	*out = *in
+	if in.LSR != nil {
+		in, out := &in.LSR, &out.LSR
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LS != nil {
+		in, out := &in.LS, &out.LS
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BE != nil {
+		in, out := &in.BE, &out.BE
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictGracePeriodSeconds.
+func (in *EvictGracePeriodSeconds) DeepCopy() *EvictGracePeriodSeconds {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictGracePeriodSeconds)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MemoryQoS) DeepCopyInto(out *MemoryQoS) {
 	*out = *in
@@ -95,6 +165,11 @@ func (in *MemoryQoS) DeepCopyInto(out *MemoryQoS) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.SwapLimitPercent != nil {
+		in, out := &in.SwapLimitPercent, &out.SwapLimitPercent
+		*out = new(int64)
+		**out = **in
+	}
 	if in.WmarkRatio != nil {
 		in, out := &in.WmarkRatio, &out.WmarkRatio
 		*out = new(int64)
@@ -350,6 +425,11 @@ func (in *NodeSLOSpec) DeepCopyInto(out *NodeSLOSpec) {
 		*out = new(ResourceThresholdStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ShadowResourceUsedThresholdWithBE != nil {
+		in, out := &in.ShadowResourceUsedThresholdWithBE, &out.ShadowResourceUsedThresholdWithBE
+		*out = new(ResourceThresholdStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ResourceQoSStrategy != nil {
 		in, out := &in.ResourceQoSStrategy, &out.ResourceQoSStrategy
 		*out = new(ResourceQoSStrategy)
@@ -360,6 +440,13 @@ func (in *NodeSLOSpec) DeepCopyInto(out *NodeSLOSpec) {
 		*out = new(CPUBurstStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSLOSpec.
@@ -437,6 +524,11 @@ func (in *ResctrlQoS) DeepCopyInto(out *ResctrlQoS) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.MBAMBps != nil {
+		in, out := &in.MBAMBps, &out.MBAMBps
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResctrlQoS.
@@ -570,6 +662,21 @@ func (in *ResourceThresholdStrategy) DeepCopyInto(out *ResourceThresholdStrategy
 		*out = new(int64)
 		**out = **in
 	}
+	if in.CPUSuppressStepPercent != nil {
+		in, out := &in.CPUSuppressStepPercent, &out.CPUSuppressStepPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CPUSuppressPSIThreshold != nil {
+		in, out := &in.CPUSuppressPSIThreshold, &out.CPUSuppressPSIThreshold
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SystemCPUReservePercent != nil {
+		in, out := &in.SystemCPUReservePercent, &out.SystemCPUReservePercent
+		*out = new(int64)
+		**out = **in
+	}
 	if in.MemoryEvictThresholdPercent != nil {
 		in, out := &in.MemoryEvictThresholdPercent, &out.MemoryEvictThresholdPercent
 		*out = new(int64)
@@ -580,6 +687,41 @@ func (in *ResourceThresholdStrategy) DeepCopyInto(out *ResourceThresholdStrategy
 		*out = new(int64)
 		**out = **in
 	}
+	if in.MemoryEvictReserveBytes != nil {
+		in, out := &in.MemoryEvictReserveBytes, &out.MemoryEvictReserveBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CPUSuppressMetricWindowSeconds != nil {
+		in, out := &in.CPUSuppressMetricWindowSeconds, &out.CPUSuppressMetricWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MemoryEvictMetricWindowSeconds != nil {
+		in, out := &in.MemoryEvictMetricWindowSeconds, &out.MemoryEvictMetricWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MemoryEvictPreferFastGrowers != nil {
+		in, out := &in.MemoryEvictPreferFastGrowers, &out.MemoryEvictPreferFastGrowers
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MemoryEvictGrowthWindowSeconds != nil {
+		in, out := &in.MemoryEvictGrowthWindowSeconds, &out.MemoryEvictGrowthWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DiskUsedThresholdPercent != nil {
+		in, out := &in.DiskUsedThresholdPercent, &out.DiskUsedThresholdPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.EvictGracePeriodSeconds != nil {
+		in, out := &in.EvictGracePeriodSeconds, &out.EvictGracePeriodSeconds
+		*out = new(EvictGracePeriodSeconds)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceThresholdStrategy.
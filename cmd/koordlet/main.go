@@ -32,6 +32,7 @@ import (
 	agent "github.com/koordinator-sh/koordinator/pkg/koordlet"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/config"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resmanager"
 )
 
 func init() {}
@@ -50,6 +51,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := cfg.ResManagerConf.SetupQoSClassLabelKey(); err != nil {
+		klog.Error("Unable to setup qos class label key: ", err)
+		os.Exit(1)
+	}
+	if err := cfg.ResManagerConf.SetupDefaultQoSClass(); err != nil {
+		klog.Error("Unable to setup default qos class: ", err)
+		os.Exit(1)
+	}
+
 	stopCtx := signals.SetupSignalHandler()
 
 	// setup the default auditor
@@ -78,6 +88,15 @@ func main() {
 		if features.DefaultKoordletFeatureGate.Enabled(features.AuditEventsHTTPHandler) {
 			http.HandleFunc("/events", audit.HttpHandler())
 		}
+		http.HandleFunc("/debug/cpuburst", resmanager.CPUBurstStatesHttpHandler())
+		http.HandleFunc("/debug/memoryevictplan", resmanager.MemoryEvictionPlanHttpHandler())
+		http.HandleFunc("/debug/reconciledecisions", resmanager.ReconcileDecisionsHttpHandler())
+		http.HandleFunc("/debug/featurehealth", resmanager.FeatureHealthHttpHandler())
+		http.HandleFunc("/debug/featurestatus", resmanager.FeatureStatusHttpHandler())
+		if features.DefaultKoordletFeatureGate.Enabled(features.DebugActionHTTPHandler) {
+			http.HandleFunc("/debug/reconcilepod", resmanager.ReconcilePodHttpHandler())
+			http.HandleFunc("/debug/pausefeature", resmanager.PauseFeatureHttpHandler())
+		}
 		// http.HandleFunc("/healthz", d.HealthzHandler())
 		klog.Fatalf("Prometheus monitoring failed: %v", http.ListenAndServe(*options.ServerAddr, nil))
 	}()
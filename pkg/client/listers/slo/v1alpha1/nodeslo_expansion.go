@@ -0,0 +1,44 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+// NodeSLOListerExpansion allows custom methods to be added to
+// NodeSLOLister.
+type NodeSLOListerExpansion interface {
+	// ListAsMap lists all NodeSLOs in the indexer and returns them keyed by the node name.
+	// Objects returned here are deep copies and are safe to be modified by the caller.
+	ListAsMap() map[string]*v1alpha1.NodeSLO
+}
+
+// ListAsMap lists all NodeSLOs in the indexer and returns them keyed by the node name.
+func (s *nodeSLOLister) ListAsMap() map[string]*v1alpha1.NodeSLO {
+	objs := s.indexer.List()
+	ret := make(map[string]*v1alpha1.NodeSLO, len(objs))
+	for _, obj := range objs {
+		nodeSLO, ok := obj.(*v1alpha1.NodeSLO)
+		if !ok {
+			continue
+		}
+		// the NodeSLO is named after the node
+		ret[nodeSLO.Name] = nodeSLO.DeepCopy()
+	}
+	return ret
+}
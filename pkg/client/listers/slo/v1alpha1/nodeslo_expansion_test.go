@@ -0,0 +1,63 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+
+	v1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func TestNodeSLOLister_ListAsMap(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lister := NewNodeSLOLister(indexer)
+	assert.Equal(t, map[string]*v1alpha1.NodeSLO{}, lister.ListAsMap())
+
+	for _, nodeName := range []string{"test-node-0", "test-node-1"} {
+		err := indexer.Add(&v1alpha1.NodeSLO{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Spec: v1alpha1.NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &v1alpha1.ResourceThresholdStrategy{
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(65),
+				},
+			},
+		})
+		assert.NoError(t, err)
+	}
+
+	got := lister.ListAsMap()
+	assert.Equal(t, 2, len(got))
+	for _, nodeName := range []string{"test-node-0", "test-node-1"} {
+		nodeSLO, ok := got[nodeName]
+		assert.True(t, ok)
+		assert.Equal(t, nodeName, nodeSLO.Name)
+		assert.Equal(t, pointer.Int64Ptr(65), nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+	}
+
+	// modifying the returned objects does not change the objects in the indexer
+	got["test-node-0"].Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent = pointer.Int64Ptr(80)
+	got["test-node-0"].Labels = map[string]string{"modified": "true"}
+	cached, err := lister.Get("test-node-0")
+	assert.NoError(t, err)
+	assert.Equal(t, pointer.Int64Ptr(65), cached.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+	assert.Nil(t, cached.Labels)
+}
@@ -28,6 +28,10 @@ const (
 	// AuditEventsHTTPHandler is used to get recent events from koordlet port
 	AuditEventsHTTPHandler featuregate.Feature = "AuditEventsHTTPHandler"
 
+	// DebugActionHTTPHandler serves the debug http handlers taking actions on the node from koordlet port, e.g.
+	// reconciling a pod or pausing a feature loop
+	DebugActionHTTPHandler featuregate.Feature = "DebugActionHTTPHandler"
+
 	// BECgroupReconcile sets cpu memory limit for best-effort pod
 	BECgroupReconcile featuregate.Feature = "BECgroupReconcile"
 
@@ -37,14 +41,32 @@ const (
 	// BEMemoryEvict evict best-effort pod based on Memory
 	BEMemoryEvict featuregate.Feature = "BEMemoryEvict"
 
+	// BEDiskEvict evict best-effort pod based on the node ephemeral storage usage
+	BEDiskEvict featuregate.Feature = "BEDiskEvict"
+
 	// CPUBurst set cpu.cfs_burst_us; scale up cpu.cfs_quota_us if pod cpu throttled
 	CPUBurst featuregate.Feature = "CPUBurst"
 
 	// RdtResctrl sets intel rdt resctrl for processes belonging to ls or be pods
 	RdtResctrl featuregate.Feature = "RdtResctrl"
 
+	// RdtMonitor monitors the llc occupancy and memory bandwidth of the ls and be pods with the intel rdt resctrl
+	RdtMonitor featuregate.Feature = "RdtMonitor"
+
 	// CgroupReconcile reconciles qos config for resources like cpu, memory, disk, etc.
 	CgroupReconcile featuregate.Feature = "CgroupReconcile"
+
+	// QoSDriftAudit audits the applied cgroup values of the sampled pods against the merged NodeSLO
+	QoSDriftAudit featuregate.Feature = "QoSDriftAudit"
+
+	// BEOverloadTaint taints the node to stop scheduling best-effort pods when be pods are evicted frequently
+	BEOverloadTaint featuregate.Feature = "BEOverloadTaint"
+
+	// BEOOMQuarantine evicts the best-effort pods which are oom killed repeatedly instead of letting them restart-loop
+	BEOOMQuarantine featuregate.Feature = "BEOOMQuarantine"
+
+	// QoSUsageMetrics exports the aggregate cpu and memory usage of the pods of each qos class
+	QoSUsageMetrics featuregate.Feature = "QoSUsageMetrics"
 )
 
 func init() {
@@ -58,11 +80,18 @@ var (
 	defaultKoordletFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 		AuditEvents:            {Default: false, PreRelease: featuregate.Alpha},
 		AuditEventsHTTPHandler: {Default: false, PreRelease: featuregate.Alpha},
+		DebugActionHTTPHandler: {Default: false, PreRelease: featuregate.Alpha},
 		BECgroupReconcile:      {Default: false, PreRelease: featuregate.Alpha},
 		BECPUSuppress:          {Default: false, PreRelease: featuregate.Alpha},
 		BEMemoryEvict:          {Default: false, PreRelease: featuregate.Alpha},
+		BEDiskEvict:            {Default: false, PreRelease: featuregate.Alpha},
 		CPUBurst:               {Default: false, PreRelease: featuregate.Alpha},
 		RdtResctrl:             {Default: false, PreRelease: featuregate.Alpha},
+		RdtMonitor:             {Default: false, PreRelease: featuregate.Alpha},
 		CgroupReconcile:        {Default: false, PreRelease: featuregate.Alpha},
+		QoSDriftAudit:          {Default: false, PreRelease: featuregate.Alpha},
+		BEOverloadTaint:        {Default: false, PreRelease: featuregate.Alpha},
+		BEOOMQuarantine:        {Default: false, PreRelease: featuregate.Alpha},
+		QoSUsageMetrics:        {Default: false, PreRelease: featuregate.Alpha},
 	}
 )
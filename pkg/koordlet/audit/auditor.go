@@ -37,7 +37,7 @@ var (
 )
 
 func NewAuditor(c *Config) Auditor {
-	logWriter := NewFluentEventLogger(c.LogDir, c.MaxDiskSpaceMB, c.Verbose)
+	logWriter := NewFluentEventLoggerWithActionVerbose(c.LogDir, c.MaxDiskSpaceMB, c.Verbose, c.ActionVerbose)
 	logReader := NewEventReader(c.LogDir)
 	return &auditor{
 		config:        c,
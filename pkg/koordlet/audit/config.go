@@ -18,12 +18,17 @@ package audit
 
 import (
 	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
 	LogDir               string
 	Verbose              int
+	ActionVerbose        map[string]int
 	MaxDiskSpaceMB       int
 	MaxConcurrentReaders int
 	ActiveReaderTTL      time.Duration
@@ -48,5 +53,48 @@ func NewDefaultConfig() *Config {
 func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.LogDir, "AuditLogDir", c.LogDir, "The dir of audit log")
 	fs.IntVar(&c.Verbose, "AuditVerbose", c.Verbose, "The verbose of the audit log")
+	fs.Var(newActionVerboseFlag(&c.ActionVerbose), "AuditActionVerbose", "The verbose of the audit log overridden by the action reason, e.g. updateCgroups=1,adjustBEByNodeCPUUsage=-1, where a negative verbose excludes the action")
 	fs.IntVar(&c.MaxDiskSpaceMB, "AuditMaxDiskSpaceMB", c.MaxDiskSpaceMB, "Max disk space occupied of audit log")
 }
+
+// actionVerboseFlag parses the comma-separated reason=verbose pairs into the map.
+type actionVerboseFlag struct {
+	m *map[string]int
+}
+
+func newActionVerboseFlag(m *map[string]int) *actionVerboseFlag {
+	return &actionVerboseFlag{m: m}
+}
+
+func (f *actionVerboseFlag) String() string {
+	if f.m == nil || *f.m == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*f.m))
+	for action, verbose := range *f.m {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", action, verbose))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f *actionVerboseFlag) Set(value string) error {
+	actionVerbose := map[string]int{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return fmt.Errorf("malformed pair %q, expect reason=verbose", pair)
+		}
+		verbose, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return fmt.Errorf("invalid verbose of pair %q, err: %v", pair, err)
+		}
+		actionVerbose[strings.TrimSpace(kv[0])] = verbose
+	}
+	*f.m = actionVerbose
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package audit
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestConfigActionVerboseFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string]int
+		wantErr  bool
+	}{
+		{
+			name:     "parse the pairs",
+			value:    "updateCgroups=1, adjustBEByNodeCPUUsage=-1",
+			expected: map[string]int{"updateCgroups": 1, "adjustBEByNodeCPUUsage": -1},
+		},
+		{
+			name:     "empty value",
+			value:    "",
+			expected: map[string]int{},
+		},
+		{
+			name:    "malformed pair",
+			value:   "updateCgroups",
+			wantErr: true,
+		},
+		{
+			name:    "invalid verbose",
+			value:   "updateCgroups=high",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDefaultConfig()
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			c.InitFlags(fs)
+			err := fs.Parse([]string{"-AuditActionVerbose=" + tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.expected, c.ActionVerbose) {
+				t.Errorf("unexpected action verbose, expected: %v, actual: %v", tt.expected, c.ActionVerbose)
+			}
+		})
+	}
+}
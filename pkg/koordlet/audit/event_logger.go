@@ -35,20 +35,33 @@ const (
 // NewEventLogger create an EventWriter, it won't open the underly file until do a log call.
 // verbose=0 means no restrictions on verbose
 func NewEventLogger(dir string, sizeMB int, verbose int) EventWriter {
+	return NewEventLoggerWithActionVerbose(dir, sizeMB, verbose, nil)
+}
+
+// NewEventLoggerWithActionVerbose create an EventWriter which overrides the verbose by the event reason with the
+// actionVerbose. The events of the reason are logged only if their verbose is no more than the action verbose, so a
+// negative action verbose excludes the reason from the audit.
+func NewEventLoggerWithActionVerbose(dir string, sizeMB int, verbose int, actionVerbose map[string]int) EventWriter {
 	if sizeMB <= 0 {
 		klog.Fatalf("sizeMB [%d] should be larger than 0", sizeMB)
 	}
 	return &eventWriter{
-		dir:         dir,
-		maxFileSize: MaxFileSize,
-		maxFileNum:  sizeMB * (1 << 20) / MaxFileSize,
-		verbose:     verbose,
+		dir:           dir,
+		maxFileSize:   MaxFileSize,
+		maxFileNum:    sizeMB * (1 << 20) / MaxFileSize,
+		verbose:       verbose,
+		actionVerbose: actionVerbose,
 	}
 }
 
 // NewFluentEventLogger create an EventFluentWriter to simplify the audit.
 func NewFluentEventLogger(dir string, sizeMB int, verbose int) EventFluentWriter {
-	writer := NewEventLogger(dir, sizeMB, verbose)
+	return NewFluentEventLoggerWithActionVerbose(dir, sizeMB, verbose, nil)
+}
+
+// NewFluentEventLoggerWithActionVerbose create an EventFluentWriter with the verbose overridden by the event reason.
+func NewFluentEventLoggerWithActionVerbose(dir string, sizeMB int, verbose int, actionVerbose map[string]int) EventFluentWriter {
+	writer := NewEventLoggerWithActionVerbose(dir, sizeMB, verbose, actionVerbose)
 	return &eventFluentWriter{writer: writer}
 }
 
@@ -62,16 +75,22 @@ type eventWriter struct {
 	verbose     int
 	maxFileSize int
 	maxFileNum  int
+	// actionVerbose overrides the verbose for the events of each reason
+	actionVerbose map[string]int
 
 	logWriter LogWriter
 }
 
 // Log write an event to the underly storage
 func (e *eventWriter) Log(verbose int, event *Event) error {
-	if e.verbose > 0 && verbose > e.verbose {
+	if event == nil {
 		return nil
 	}
-	if event == nil {
+	if actionVerbose, ok := e.actionVerbose[event.Reason]; ok {
+		if verbose > actionVerbose {
+			return nil
+		}
+	} else if e.verbose > 0 && verbose > e.verbose {
 		return nil
 	}
 
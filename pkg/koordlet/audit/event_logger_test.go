@@ -47,6 +47,43 @@ func TestEventLogger(t *testing.T) {
 	}
 }
 
+func TestEventLoggerActionVerbose(t *testing.T) {
+	tempDir, err := ioutil.TempDir(".", "_test")
+	if err != nil {
+		t.Fatal("failed to create dir", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	logger := NewFluentEventLoggerWithActionVerbose(tempDir, 10, 3, map[string]int{
+		"evict":    0,
+		"suppress": 5,
+		"burst":    -1,
+	})
+	logger.V(0).Node().Reason("evict").Do()
+	logger.V(1).Node().Reason("evict").Do()
+	logger.V(5).Node().Reason("suppress").Do()
+	logger.V(6).Node().Reason("suppress").Do()
+	logger.V(0).Node().Reason("burst").Do()
+	logger.V(3).Node().Reason("other").Do()
+	logger.V(4).Node().Reason("other").Do()
+	logger.Close()
+
+	var got []string
+	iter := NewEventReader(tempDir).NewReverseInterator()
+	defer iter.Close()
+	for {
+		event, err := iter.Next()
+		if err != nil {
+			break
+		}
+		got = append(got, event.Reason)
+	}
+	expected := []string{"other", "suppress", "evict"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("unexpected events, expected: %v, actual: %v", expected, got)
+	}
+}
+
 func TestFluentEventLogger(t *testing.T) {
 	tempDir, err := ioutil.TempDir(".", "_test")
 	if err != nil {
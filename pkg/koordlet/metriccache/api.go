@@ -30,6 +30,10 @@ type MemoryMetric struct {
 	MemoryWithoutCache resource.Quantity
 }
 
+type DiskMetric struct {
+	DiskUsed resource.Quantity
+}
+
 type CPUThrottledMetric struct {
 	ThrottledRatio float64
 }
@@ -37,6 +41,7 @@ type CPUThrottledMetric struct {
 type NodeResourceMetric struct {
 	CPUUsed    CPUMetric
 	MemoryUsed MemoryMetric
+	DiskUsed   DiskMetric
 }
 
 type NodeResourceQueryResult struct {
@@ -48,6 +53,7 @@ type PodResourceMetric struct {
 	PodUID     string
 	CPUUsed    CPUMetric
 	MemoryUsed MemoryMetric
+	DiskUsed   DiskMetric
 }
 
 type PodResourceQueryResult struct {
@@ -32,7 +32,10 @@ type AggregationType string
 const (
 	AggregationTypeAVG   AggregationType = "AVG"
 	AggregationTypeP90   AggregationType = "P90"
+	AggregationTypeP95   AggregationType = "P95"
+	AggregationTypeMax   AggregationType = "max"
 	AggregationTypeLast  AggregationType = "last"
+	AggregationTypeFirst AggregationType = "first"
 	AggregationTypeCount AggregationType = "count"
 )
 
@@ -131,6 +134,11 @@ func (m *metricCache) GetNodeResourceMetric(param *QueryParam) NodeResourceQuery
 		result.Error = fmt.Errorf("get node aggregate MemoryUsedBytes failed, metrics %v, error %v", metrics, err)
 		return result
 	}
+	diskUsed, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "DiskUsedBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get node aggregate DiskUsedBytes failed, metrics %v, error %v", metrics, err)
+		return result
+	}
 
 	count, err := count(metrics)
 	if err != nil {
@@ -146,6 +154,9 @@ func (m *metricCache) GetNodeResourceMetric(param *QueryParam) NodeResourceQuery
 		MemoryUsed: MemoryMetric{
 			MemoryWithoutCache: *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
 		},
+		DiskUsed: DiskMetric{
+			DiskUsed: *resource.NewQuantity(int64(diskUsed), resource.BinarySI),
+		},
 	}
 
 	return result
@@ -180,6 +191,12 @@ func (m *metricCache) GetPodResourceMetric(podUID *string, param *QueryParam) Po
 			*podUID, metrics, err)
 		return result
 	}
+	diskUsed, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "DiskUsedBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get pod %v aggregate DiskUsedBytes failed, metrics %v, error %v",
+			*podUID, metrics, err)
+		return result
+	}
 
 	count, err := count(metrics)
 	if err != nil {
@@ -196,6 +213,9 @@ func (m *metricCache) GetPodResourceMetric(podUID *string, param *QueryParam) Po
 		MemoryUsed: MemoryMetric{
 			MemoryWithoutCache: *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
 		},
+		DiskUsed: DiskMetric{
+			DiskUsed: *resource.NewQuantity(int64(diskUsed), resource.BinarySI),
+		},
 	}
 	return result
 }
@@ -407,6 +427,7 @@ func (m *metricCache) InsertNodeResourceMetric(t time.Time, nodeResUsed *NodeRes
 	dbItem := &nodeResourceMetric{
 		CPUUsedCores:    float64(nodeResUsed.CPUUsed.CPUUsed.MilliValue()) / 1000,
 		MemoryUsedBytes: float64(nodeResUsed.MemoryUsed.MemoryWithoutCache.Value()),
+		DiskUsedBytes:   float64(nodeResUsed.DiskUsed.DiskUsed.Value()),
 		Timestamp:       t,
 	}
 	return m.db.InsertNodResourceMetric(dbItem)
@@ -417,6 +438,7 @@ func (m *metricCache) InsertPodResourceMetric(t time.Time, podResUsed *PodResour
 		PodUID:          podResUsed.PodUID,
 		CPUUsedCores:    float64(podResUsed.CPUUsed.CPUUsed.MilliValue()) / 1000,
 		MemoryUsedBytes: float64(podResUsed.MemoryUsed.MemoryWithoutCache.Value()),
+		DiskUsedBytes:   float64(podResUsed.DiskUsed.DiskUsed.Value()),
 		Timestamp:       t,
 	}
 	return m.db.InsertPodResourceMetric(dbItem)
@@ -506,8 +528,14 @@ func getAggregateFunc(aggregationType AggregationType) func(interface{}, Aggrega
 		return fieldAvgOfMetricList
 	case AggregationTypeP90:
 		return fieldP90OfMetricList
+	case AggregationTypeP95:
+		return fieldP95OfMetricList
+	case AggregationTypeMax:
+		return fieldMaxOfMetricList
 	case AggregationTypeLast:
 		return fieldLastOfMetricList
+	case AggregationTypeFirst:
+		return fieldFirstOfMetricList
 	case AggregationTypeCount:
 		return fieldCountOfMetricList
 	default:
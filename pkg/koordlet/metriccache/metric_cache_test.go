@@ -17,6 +17,7 @@
 package metriccache
 
 import (
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -54,6 +55,9 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(30, resource.BinarySI),
 						},
+						DiskUsed: DiskMetric{
+							DiskUsed: *resource.NewQuantity(30, resource.BinarySI),
+						},
 					},
 					now.Add(-time.Second * 10): {
 						CPUUsed: CPUMetric{
@@ -62,6 +66,9 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(10, resource.BinarySI),
 						},
+						DiskUsed: DiskMetric{
+							DiskUsed: *resource.NewQuantity(10, resource.BinarySI),
+						},
 					},
 					now.Add(-time.Second * 5): {
 						CPUUsed: CPUMetric{
@@ -70,6 +77,9 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskUsed: DiskMetric{
+							DiskUsed: *resource.NewQuantity(20, resource.BinarySI),
+						},
 					},
 				},
 			},
@@ -81,6 +91,9 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 					},
+					DiskUsed: DiskMetric{
+						DiskUsed: *resource.NewQuantity(20, resource.BinarySI),
+					},
 				},
 				QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 3}},
 			},
@@ -92,6 +105,9 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(15, resource.BinarySI),
 					},
+					DiskUsed: DiskMetric{
+						DiskUsed: *resource.NewQuantity(15, resource.BinarySI),
+					},
 				},
 				QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 2}},
 			},
@@ -171,6 +187,9 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(30, resource.BinarySI),
 						},
+						DiskUsed: DiskMetric{
+							DiskUsed: *resource.NewQuantity(30, resource.BinarySI),
+						},
 					},
 					now.Add(-time.Second * 10): {
 						PodUID: "pod-uid-1",
@@ -180,6 +199,9 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(10, resource.BinarySI),
 						},
+						DiskUsed: DiskMetric{
+							DiskUsed: *resource.NewQuantity(10, resource.BinarySI),
+						},
 					},
 					now.Add(-time.Second * 5): {
 						PodUID: "pod-uid-1",
@@ -189,6 +211,9 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskUsed: DiskMetric{
+							DiskUsed: *resource.NewQuantity(20, resource.BinarySI),
+						},
 					},
 					now.Add(-time.Second * 4): {
 						PodUID: "pod-uid-2",
@@ -198,6 +223,9 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskUsed: DiskMetric{
+							DiskUsed: *resource.NewQuantity(20, resource.BinarySI),
+						},
 					},
 				},
 			},
@@ -210,6 +238,9 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 					},
+					DiskUsed: DiskMetric{
+						DiskUsed: *resource.NewQuantity(20, resource.BinarySI),
+					},
 				},
 				QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 3}},
 			},
@@ -223,6 +254,9 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(15, resource.BinarySI),
 					},
+					DiskUsed: DiskMetric{
+						DiskUsed: *resource.NewQuantity(15, resource.BinarySI),
+					},
 				},
 				QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 2}},
 			},
@@ -938,3 +972,38 @@ func Test_metricCache_PodThrottledMetric_CRUD(t *testing.T) {
 		})
 	}
 }
+
+func Test_getAggregateFunc(t *testing.T) {
+	// a spiky series with the baseline 5, the spikes 30 and 40, and the latest 8
+	now := time.Now()
+	var metrics []nodeResourceMetric
+	for i, v := range []float64{5, 5, 5, 5, 5, 5, 5, 5, 5, 30, 5, 5, 5, 5, 40, 5, 5, 5, 5, 8} {
+		metrics = append(metrics, nodeResourceMetric{
+			CPUUsedCores: v,
+			Timestamp:    now.Add(time.Duration(i-20) * time.Second),
+		})
+	}
+	tests := []struct {
+		aggregationType AggregationType
+		want            float64
+	}{
+		{aggregationType: AggregationTypeAVG, want: 8.15},
+		{aggregationType: AggregationTypeP90, want: 8},
+		{aggregationType: AggregationTypeP95, want: 30},
+		{aggregationType: AggregationTypeMax, want: 40},
+		{aggregationType: AggregationTypeLast, want: 8},
+		{aggregationType: AggregationTypeFirst, want: 5},
+		{aggregationType: AggregationTypeCount, want: 20},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.aggregationType), func(t *testing.T) {
+			got, err := getAggregateFunc(tt.aggregationType)(metrics, AggregateParam{ValueFieldName: "CPUUsedCores", TimeFieldName: "Timestamp"})
+			if err != nil {
+				t.Errorf("aggregate metrics failed, error %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("aggregate metrics by %v, want %v, got %v", tt.aggregationType, tt.want, got)
+			}
+		})
+	}
+}
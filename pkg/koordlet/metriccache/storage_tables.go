@@ -28,6 +28,7 @@ type nodeResourceMetric struct {
 	ID              uint64 `gorm:"primarykey"`
 	CPUUsedCores    float64
 	MemoryUsedBytes float64
+	DiskUsedBytes   float64
 	Timestamp       time.Time
 }
 
@@ -36,6 +37,7 @@ type podResourceMetric struct {
 	PodUID          string `gorm:"index:idx_pod_res_uid"`
 	CPUUsedCores    float64
 	MemoryUsedBytes float64
+	DiskUsedBytes   float64
 	Timestamp       time.Time
 }
 
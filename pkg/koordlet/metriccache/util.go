@@ -89,8 +89,18 @@ func fieldPercentileOfMetricList(metricsList interface{}, aggregateParam Aggrega
 }
 
 func fieldLastOfMetricList(metricsList interface{}, aggregateParam AggregateParam) (float64, error) {
-	lastValue := 0.0
-	lastTime := int64(0)
+	return fieldEdgeOfMetricList(metricsList, aggregateParam, true)
+}
+
+func fieldFirstOfMetricList(metricsList interface{}, aggregateParam AggregateParam) (float64, error) {
+	return fieldEdgeOfMetricList(metricsList, aggregateParam, false)
+}
+
+// fieldEdgeOfMetricList returns the field value of the latest metric by the time field if last is true, otherwise the
+// earliest one.
+func fieldEdgeOfMetricList(metricsList interface{}, aggregateParam AggregateParam, last bool) (float64, error) {
+	edgeValue := 0.0
+	edgeTime := int64(0)
 
 	inputType := reflect.TypeOf(metricsList).Kind()
 	if inputType != reflect.Slice && inputType != reflect.Array {
@@ -126,12 +136,12 @@ func fieldLastOfMetricList(metricsList interface{}, aggregateParam AggregatePara
 		if !ok {
 			return 0, fmt.Errorf("timestamp field type must be *time.Time, and value must not be nil. %v is illegal! ", fieldTimeValue)
 		}
-		if timestamp.UnixNano() > lastTime {
-			lastTime = timestamp.UnixNano()
-			lastValue = fieldValue.Float()
+		if i == 0 || (last && timestamp.UnixNano() > edgeTime) || (!last && timestamp.UnixNano() < edgeTime) {
+			edgeTime = timestamp.UnixNano()
+			edgeValue = fieldValue.Float()
 		}
 	}
-	return lastValue, nil
+	return edgeValue, nil
 }
 
 func fieldCountOfMetricList(metricsList interface{}, aggregateParam AggregateParam) (float64, error) {
@@ -148,3 +158,11 @@ func fieldCountOfMetricList(metricsList interface{}, aggregateParam AggregatePar
 func fieldP90OfMetricList(metricsList interface{}, aggregateParam AggregateParam) (float64, error) {
 	return fieldPercentileOfMetricList(metricsList, aggregateParam, 0.90)
 }
+
+func fieldP95OfMetricList(metricsList interface{}, aggregateParam AggregateParam) (float64, error) {
+	return fieldPercentileOfMetricList(metricsList, aggregateParam, 0.95)
+}
+
+func fieldMaxOfMetricList(metricsList interface{}, aggregateParam AggregateParam) (float64, error) {
+	return fieldPercentileOfMetricList(metricsList, aggregateParam, 1)
+}
@@ -246,3 +246,54 @@ func Test_fieldLastOfMetricList(t *testing.T) {
 		})
 	}
 }
+
+func Test_fieldFirstOfMetricList(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		metricsList interface{}
+		want        float64
+		wantErr     bool
+	}{
+		{
+			name: "trow error for illegal list length",
+			metricsList: []struct {
+				v float32
+				T time.Time
+			}{},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "calculate single-element list",
+			metricsList: []struct {
+				v float32
+				T time.Time
+			}{
+				{v: 3.0, T: now},
+			},
+			want:    3.0,
+			wantErr: false,
+		},
+		{
+			name: "calculate unordered multi-element list",
+			metricsList: []struct {
+				v float32
+				T time.Time
+			}{
+				{v: 2.0, T: now.Add(-3 * time.Second)},
+				{v: 1.0, T: now.Add(-5 * time.Second)},
+				{v: 3.0, T: now},
+			},
+			want:    1.0,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fieldFirstOfMetricList(tt.metricsList, AggregateParam{ValueFieldName: "v", TimeFieldName: "T"})
+			assert.Equal(t, true, tt.wantErr == (err != nil))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
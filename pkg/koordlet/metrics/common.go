@@ -42,11 +42,132 @@ var (
 		Help:      "Number of cores suppress by koordlet",
 	}, []string{NodeKey, BESuppressTypeKey})
 
+	BESuppressedPods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "be_suppressed_pods",
+		Help:      "Number of the running BE pods under the cpu suppression by koordlet",
+	}, []string{NodeKey})
+
+	BECPUAllocation = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "be_cpu_allocation_cores",
+		Help:      "The cpu cores allocated to the BE pods by the cpu suppression, which is the node capacity if not suppressed",
+	}, []string{NodeKey})
+
+	AppliedMemoryMin = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "applied_memory_min_bytes",
+		Help:      "The total memory.min of the pods applied by the cgroup reconcile of koordlet",
+	}, []string{NodeKey})
+
+	BESuppressStaleMetricRelease = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "be_suppress_stale_metric_release_total",
+		Help:      "Number of BE cpu suppressions released by koordlet since the node metrics are stale",
+	}, []string{NodeKey})
+
+	QoSConfigDrift = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "qos_config_drift_total",
+		Help:      "Number of cgroup values found drifted from the qos config by koordlet",
+	}, []string{NodeKey, CgroupResourceKey})
+
+	NodeSLOMergeFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "node_slo_merge_failed_total",
+		Help:      "Number of NodeSLO updates rejected by koordlet since failing to merge",
+	}, []string{NodeKey})
+
+	CgroupReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "cgroup_reconcile_duration_seconds",
+		Help:      "Duration of koordlet writing a batch of cgroup resources in the reconcile",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{NodeKey, CgroupResourceKey})
+
+	ContainerKillRuntimeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "container_kill_runtime_errors_total",
+		Help:      "Number of containers failed to kill by koordlet since the runtime handler is unavailable",
+	}, []string{NodeKey, RuntimeTypeKey})
+
+	NodeSLOApplyLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "node_slo_apply_latency_seconds",
+		Help:      "Latency from koordlet receiving a NodeSLO spec update to a feature completing a cycle with it",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{NodeKey, FeatureKey})
+
+	UnclassifiedPods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "unclassified_pods",
+		Help:      "Number of the pods on the node without a valid koordinator qos class",
+	}, []string{NodeKey})
+
+	NodeSLOSpecInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "nodeslo_spec_info",
+		Help:      "The hash of the NodeSLO spec applied by koordlet after merging with the default config",
+	}, []string{NodeKey, SpecHashKey})
+
+	ResctrlLLCOccupancy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "resctrl_llc_occupancy_bytes",
+		Help:      "Bytes of the last level cache occupied by the pods of each qos class",
+	}, []string{NodeKey, QoSKey})
+
+	ResctrlMemoryBandwidth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "resctrl_memory_bandwidth_bytes_per_second",
+		Help:      "Memory bandwidth used by the pods of each qos class",
+	}, []string{NodeKey, QoSKey, BandwidthTypeKey})
+
+	ResctrlClosids = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "resctrl_closids",
+		Help:      "Number of the resctrl CLOSIDs in use by the resctrl groups and the platform maximum",
+	}, []string{NodeKey, ClosidTypeKey})
+
+	ThresholdStrategyDecision = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "threshold_strategy_decision",
+		Help:      "The decision of each feature under the active and the shadow threshold strategy, e.g. the be suppress cpu cores",
+	}, []string{NodeKey, FeatureKey, StrategyKey})
+
+	QoSResourceUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "qos_resource_usage",
+		Help:      "The aggregate resource usage of the pods of each qos class, the cpu in cores and the memory in bytes",
+	}, []string{NodeKey, QoSKey, ResourceKey})
+
+	FeatureStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "feature_status",
+		Help:      "The status of each resmanager feature, which is 1 for the current status and 0 for the others",
+	}, []string{NodeKey, FeatureKey, StatusKey})
+
 	CommonCollectors = []prometheus.Collector{
 		KoordletStartTime,
 		CollectNodeCPUInfoStatus,
 		PodEviction,
 		BESuppressCPU,
+		BESuppressedPods,
+		BECPUAllocation,
+		AppliedMemoryMin,
+		BESuppressStaleMetricRelease,
+		QoSConfigDrift,
+		NodeSLOMergeFailed,
+		CgroupReconcileDuration,
+		ContainerKillRuntimeErrors,
+		NodeSLOApplyLatency,
+		UnclassifiedPods,
+		NodeSLOSpecInfo,
+		ResctrlLLCOccupancy,
+		ResctrlMemoryBandwidth,
+		ResctrlClosids,
+		ThresholdStrategyDecision,
+		QoSResourceUsage,
+		FeatureStatus,
 	}
 )
 
@@ -86,3 +207,162 @@ func RecordBESuppressCores(suppressType string, value float64) {
 	labels[BESuppressTypeKey] = suppressType
 	BESuppressCPU.With(labels).Set(value)
 }
+
+func RecordBESuppressedPods(value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	BESuppressedPods.With(labels).Set(value)
+}
+
+func RecordBECPUAllocation(value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	BECPUAllocation.With(labels).Set(value)
+}
+
+func RecordAppliedMemoryMin(value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	AppliedMemoryMin.With(labels).Set(value)
+}
+
+func RecordBESuppressStaleMetricRelease() {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	BESuppressStaleMetricRelease.With(labels).Inc()
+}
+
+func RecordQoSConfigDrift(resourceName string) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[CgroupResourceKey] = resourceName
+	QoSConfigDrift.With(labels).Inc()
+}
+
+func RecordNodeSLOMergeFailed() {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	NodeSLOMergeFailed.With(labels).Inc()
+}
+
+// RecordCgroupReconcileDuration records the duration of a reconcile batch. The resourceType should be one of the
+// CgroupReconcileResource* to keep the label cardinality bounded.
+func RecordCgroupReconcileDuration(resourceType string, seconds float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[CgroupResourceKey] = resourceType
+	CgroupReconcileDuration.With(labels).Observe(seconds)
+}
+
+func RecordContainerKillRuntimeError(runtimeType string) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[RuntimeTypeKey] = runtimeType
+	ContainerKillRuntimeErrors.With(labels).Inc()
+}
+
+func RecordNodeSLOApplyLatency(feature string, seconds float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[FeatureKey] = feature
+	NodeSLOApplyLatency.With(labels).Observe(seconds)
+}
+
+func RecordUnclassifiedPods(value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	UnclassifiedPods.With(labels).Set(value)
+}
+
+// RecordNodeSLOSpecInfo records the hash of the applied NodeSLO spec, replacing the previous one.
+func RecordNodeSLOSpecInfo(specHash string) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[SpecHashKey] = specHash
+	NodeSLOSpecInfo.Reset()
+	NodeSLOSpecInfo.With(labels).Set(1)
+}
+
+func RecordResctrlLLCOccupancy(qos string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[QoSKey] = qos
+	ResctrlLLCOccupancy.With(labels).Set(value)
+}
+
+func RecordResctrlMemoryBandwidth(qos, bandwidthType string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[QoSKey] = qos
+	labels[BandwidthTypeKey] = bandwidthType
+	ResctrlMemoryBandwidth.With(labels).Set(value)
+}
+
+// RecordResctrlClosids records the number of the resctrl CLOSIDs, where the closidType should be one of
+// ResctrlClosidsUsed and ResctrlClosidsMax.
+func RecordResctrlClosids(closidType string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[ClosidTypeKey] = closidType
+	ResctrlClosids.With(labels).Set(value)
+}
+
+// RecordThresholdStrategyDecision records the decision of the feature under the strategy, which should be one of the
+// ThresholdStrategy*.
+func RecordThresholdStrategyDecision(feature, strategy string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[FeatureKey] = feature
+	labels[StrategyKey] = strategy
+	ThresholdStrategyDecision.With(labels).Set(value)
+}
+
+func RecordQoSResourceUsage(qos, resource string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[QoSKey] = qos
+	labels[ResourceKey] = resource
+	QoSResourceUsage.With(labels).Set(value)
+}
+
+func RecordFeatureStatus(feature, status string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[FeatureKey] = feature
+	labels[StatusKey] = status
+	FeatureStatus.With(labels).Set(value)
+}
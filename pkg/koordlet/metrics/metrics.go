@@ -39,6 +39,28 @@ const (
 
 	EvictionReasonKey = "reason"
 	BESuppressTypeKey = "type"
+	CgroupResourceKey = "resource"
+	RuntimeTypeKey    = "runtime_type"
+	FeatureKey        = "feature"
+	QoSKey            = "qos"
+	BandwidthTypeKey  = "type"
+	ClosidTypeKey     = "type"
+	SpecHashKey       = "spec_hash"
+	StrategyKey       = "strategy"
+	ResourceKey       = "resource"
+
+	CgroupReconcileResourceCPU     = "cpu"
+	CgroupReconcileResourceMemory  = "memory"
+	CgroupReconcileResourceResctrl = "resctrl"
+
+	ResctrlMemoryBandwidthTotal = "total"
+	ResctrlMemoryBandwidthLocal = "local"
+
+	ResctrlClosidsUsed = "used"
+	ResctrlClosidsMax  = "max"
+
+	ThresholdStrategyActive = "active"
+	ThresholdStrategyShadow = "shadow"
 )
 
 var (
@@ -71,5 +71,22 @@ func TestCommonCollectors(t *testing.T) {
 		RecordCollectNodeCPUInfoStatus(nil)
 		RecordBESuppressCores("cfsQuota", float64(1000))
 		RecordPodEviction("evictByCPU")
+		RecordQoSConfigDrift("memory.min")
+		RecordBESuppressedPods(3)
+		RecordBECPUAllocation(4.5)
+		RecordAppliedMemoryMin(1073741824)
+		RecordBESuppressStaleMetricRelease()
+		RecordNodeSLOMergeFailed()
+		RecordCgroupReconcileDuration(CgroupReconcileResourceMemory, 0.01)
+		RecordContainerKillRuntimeError("docker")
+		RecordNodeSLOApplyLatency("BECPUSuppress", 1.5)
+		RecordUnclassifiedPods(2)
+		RecordNodeSLOSpecInfo("5f1e9c3b8a2d4e60")
+		RecordResctrlLLCOccupancy("BE", 1048576)
+		RecordResctrlMemoryBandwidth("BE", ResctrlMemoryBandwidthTotal, 1024)
+		RecordResctrlClosids(ResctrlClosidsUsed, 4)
+		RecordThresholdStrategyDecision("BECPUSuppress", ThresholdStrategyShadow, 2.5)
+		RecordQoSResourceUsage("LS", "cpu", 4.5)
+		RecordFeatureStatus("BECPUSuppress", "Enabled", 1)
 	})
 }
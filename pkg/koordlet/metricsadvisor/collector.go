@@ -61,6 +61,11 @@ type contextRecord struct {
 	ts      time.Time
 }
 
+type diskRecord struct {
+	usedBytes int64
+	ts        time.Time
+}
+
 type collectContext struct {
 	// record latest cpu stat for calculate resource used
 	// lastBeCPUStat contextRecord
@@ -70,6 +75,11 @@ type collectContext struct {
 
 	lastPodCPUThrottled       sync.Map
 	lastContainerCPUThrottled sync.Map
+
+	// record latest pod disk usage since walking the pod dirs is expensive
+	lastPodDiskUsed sync.Map
+	// podDiskUsedRefreshing records the pods whose disk usage is being refreshed asynchronously
+	podDiskUsedRefreshing sync.Map
 }
 
 func newCollectContext() *collectContext {
@@ -78,6 +88,8 @@ func newCollectContext() *collectContext {
 		lastContainerCPUStat:      sync.Map{},
 		lastPodCPUThrottled:       sync.Map{},
 		lastContainerCPUThrottled: sync.Map{},
+		lastPodDiskUsed:           sync.Map{},
+		podDiskUsedRefreshing:     sync.Map{},
 	}
 }
 
@@ -178,6 +190,10 @@ func (c *collector) collectNodeResUsed() {
 		klog.Warningf("failed to collect node usage, CPU err: %s, Memory err: %s", err0, err1)
 		return
 	}
+	diskUsageValue, err := system.GetFilesystemUsedBytes(system.Conf.VarLibKubeletRootDir)
+	if err != nil {
+		klog.V(5).Infof("failed to collect node disk usage, err: %s", err)
+	}
 	lastCPUStat := c.context.lastNodeCPUStat
 	c.context.lastNodeCPUStat = contextRecord{
 		cpuTick: currentCPUTick,
@@ -199,6 +215,9 @@ func (c *collector) collectNodeResUsed() {
 			// 1.0 kB Memory = 1024 B
 			MemoryWithoutCache: *resource.NewQuantity(memUsageValue*1024, resource.BinarySI),
 		},
+		DiskUsed: metriccache.DiskMetric{
+			DiskUsed: *resource.NewQuantity(diskUsageValue, resource.BinarySI),
+		},
 	}
 
 	if err := c.metricCache.InsertNodeResourceMetric(collectTime, &nodeMetric); err != nil {
@@ -253,6 +272,9 @@ func (c *collector) collectPodResUsed() {
 				// 1.0 kB Memory = 1024 B
 				MemoryWithoutCache: *resource.NewQuantity(memUsageValue, resource.BinarySI),
 			},
+			DiskUsed: metriccache.DiskMetric{
+				DiskUsed: *resource.NewQuantity(c.getPodDiskUsedBytes(pod, collectTime), resource.BinarySI),
+			},
 		}
 		klog.V(6).Infof("collect pod %s/%s, uid %s finished, metric %+v",
 			meta.Pod.Namespace, meta.Pod.Name, meta.Pod.UID, podMetric)
@@ -269,6 +291,34 @@ func (c *collector) collectPodResUsed() {
 	klog.Infof("collectPodResUsed finished, pod num %d", len(podMetas))
 }
 
+// getPodDiskUsedBytes returns the last collected disk usage of the emptyDir volumes and the logs of the pod, which is
+// refreshed asynchronously at most once every CollectDiskUsedIntervalSeconds, so the slow walk of the pod dirs does not
+// block the collection. It returns 0 before the first refresh finishes.
+func (c *collector) getPodDiskUsedBytes(pod *corev1.Pod, now time.Time) int64 {
+	podUID := string(pod.UID)
+	var usedBytes int64
+	if value, ok := c.context.lastPodDiskUsed.Load(podUID); ok {
+		record := value.(diskRecord)
+		if now.Sub(record.ts) < time.Duration(c.config.CollectDiskUsedIntervalSeconds)*time.Second {
+			return record.usedBytes
+		}
+		usedBytes = record.usedBytes
+	}
+	if _, loaded := c.context.podDiskUsedRefreshing.LoadOrStore(podUID, struct{}{}); !loaded {
+		go c.refreshPodDiskUsedBytes(pod.Namespace, pod.Name, podUID, now)
+	}
+	return usedBytes
+}
+
+func (c *collector) refreshPodDiskUsedBytes(namespace, name, podUID string, now time.Time) {
+	defer c.context.podDiskUsedRefreshing.Delete(podUID)
+	usedBytes, err := system.GetPodDiskUsedBytes(namespace, name, podUID)
+	if err != nil {
+		klog.V(5).Infof("failed to collect disk usage for pod %s/%s, err: %s", namespace, name, err)
+	}
+	c.context.lastPodDiskUsed.Store(podUID, diskRecord{usedBytes: usedBytes, ts: now})
+}
+
 func (c *collector) collectContainerResUsed(meta *statesinformer.PodMeta) {
 	klog.V(6).Infof("start collectContainerResUsed")
 	pod := meta.Pod
@@ -465,4 +515,13 @@ func (c *collector) cleanupContext() {
 	cleanFunc(&c.context.lastPodCPUThrottled)
 	cleanFunc(&c.context.lastContainerCPUThrottled)
 
+	diskExpiredTime := time.Duration(c.config.CollectDiskUsedIntervalSeconds*contextExpiredRatio) * time.Second
+	c.context.lastPodDiskUsed.Range(func(k, v interface{}) bool {
+		record, _ := v.(diskRecord)
+		if cleanupTime.Sub(record.ts) > diskExpiredTime {
+			c.context.lastPodDiskUsed.Delete(k)
+		}
+		return true
+	})
+
 }
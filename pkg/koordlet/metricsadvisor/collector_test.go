@@ -17,11 +17,17 @@
 package metricsadvisor
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
 )
 
 func TestNewCollector(t *testing.T) {
@@ -53,15 +59,69 @@ func TestNewCollector(t *testing.T) {
 }
 
 func Test_cleanupContext(t *testing.T) {
-	c := collector{config: &Config{CollectResUsedIntervalSeconds: 1}, context: newCollectContext(), state: newCollectState()}
+	c := collector{config: &Config{CollectResUsedIntervalSeconds: 1, CollectDiskUsedIntervalSeconds: 1}, context: newCollectContext(), state: newCollectState()}
 	for k, v := range map[string]contextRecord{
 		"expired": {cpuTick: 100, ts: time.Now().Add(0 - 2*time.Duration(contextExpiredRatio)*time.Second)},
 		"valid":   {cpuTick: 10, ts: time.Now()},
 	} {
 		c.context.lastPodCPUStat.Store(k, v)
 	}
+	c.context.lastPodDiskUsed.Store("expired", diskRecord{usedBytes: 100, ts: time.Now().Add(-2 * time.Duration(contextExpiredRatio) * time.Second)})
+	c.context.lastPodDiskUsed.Store("valid", diskRecord{usedBytes: 10, ts: time.Now()})
 	c.cleanupContext()
 	if _, ok := c.context.lastPodCPUStat.Load("expired"); ok {
 		t.Errorf("expects removing the expired pod record after cleanupContext() but actually not")
 	}
+	if _, ok := c.context.lastPodDiskUsed.Load("expired"); ok {
+		t.Errorf("expects removing the expired pod disk record after cleanupContext() but actually not")
+	}
+	if _, ok := c.context.lastPodDiskUsed.Load("valid"); !ok {
+		t.Errorf("expects keeping the valid pod disk record after cleanupContext() but actually not")
+	}
+}
+
+func Test_getPodDiskUsedBytes(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldKubeletRootDir := system.Conf.VarLibKubeletRootDir
+	oldLogRootDir := system.Conf.VarLogRootDir
+	system.Conf.VarLibKubeletRootDir = filepath.Join(helper.TempDir, "kubelet")
+	system.Conf.VarLogRootDir = filepath.Join(helper.TempDir, "log")
+	defer func() {
+		system.Conf.VarLibKubeletRootDir = oldKubeletRootDir
+		system.Conf.VarLogRootDir = oldLogRootDir
+	}()
+
+	emptyDirFile := "kubelet/pods/test-pod-uid/volumes/kubernetes.io~empty-dir/cache/data"
+	helper.CreateFile(emptyDirFile)
+	helper.WriteFileContents(emptyDirFile, "0123456789")
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod", UID: "test-pod-uid"}}
+	c := collector{config: &Config{CollectDiskUsedIntervalSeconds: 60}, context: newCollectContext()}
+	isRefreshed := func(pod *corev1.Pod) func() bool {
+		return func() bool {
+			_, refreshing := c.context.podDiskUsedRefreshing.Load(string(pod.UID))
+			return !refreshing
+		}
+	}
+
+	// the usage is refreshed asynchronously
+	now := time.Now()
+	assert.Equal(t, int64(0), c.getPodDiskUsedBytes(pod, now))
+	assert.Eventually(t, isRefreshed(pod), 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(10), c.getPodDiskUsedBytes(pod, now))
+
+	// use the cached usage within the interval
+	helper.WriteFileContents(emptyDirFile, "01234567890123456789")
+	assert.Equal(t, int64(10), c.getPodDiskUsedBytes(pod, now.Add(30*time.Second)))
+
+	// return the stale usage and refresh it after the interval
+	assert.Equal(t, int64(10), c.getPodDiskUsedBytes(pod, now.Add(60*time.Second)))
+	assert.Eventually(t, isRefreshed(pod), 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(20), c.getPodDiskUsedBytes(pod, now.Add(60*time.Second)))
+
+	// pod dir not found
+	notExistPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "not-exist", UID: "not-exist-uid"}}
+	assert.Equal(t, int64(0), c.getPodDiskUsedBytes(notExistPod, now))
+	assert.Eventually(t, isRefreshed(notExistPod), 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(0), c.getPodDiskUsedBytes(notExistPod, now))
 }
@@ -26,6 +26,7 @@ func Test_NewDefaultConfig(t *testing.T) {
 	expectConfig := &Config{
 		CollectResUsedIntervalSeconds:     1,
 		CollectNodeCPUInfoIntervalSeconds: 60,
+		CollectDiskUsedIntervalSeconds:    60,
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
@@ -0,0 +1,178 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+// BEOverloadTainter taints the node with be-overloaded when BE pods are evicted frequently, so that no more BE pods
+// are scheduled to the node, and removes the taint when no BE pod is evicted during the cool down period.
+type BEOverloadTainter struct {
+	resmanager *resmanager
+
+	evictionTimes []time.Time
+	mutex         sync.Mutex
+}
+
+func NewBEOverloadTainter(r *resmanager) *BEOverloadTainter {
+	return &BEOverloadTainter{
+		resmanager: r,
+	}
+}
+
+// recordEviction records a BE eviction; it only keeps the evictions which still affect the taint state
+func (b *BEOverloadTainter) recordEviction(evictTime time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.evictionTimes = append(b.evictionTimes, evictTime)
+	b.pruneEvictions(evictTime)
+}
+
+func (b *BEOverloadTainter) pruneEvictions(now time.Time) {
+	keepDuration := b.windowDuration()
+	if coolDown := b.coolDownDuration(); coolDown > keepDuration {
+		keepDuration = coolDown
+	}
+	i := 0
+	for ; i < len(b.evictionTimes)-1; i++ {
+		// always keep the latest eviction for checking the cool down
+		if now.Sub(b.evictionTimes[i]) <= keepDuration {
+			break
+		}
+	}
+	b.evictionTimes = b.evictionTimes[i:]
+}
+
+// checkEvictions returns whether the node is overloaded and whether the node has been stable for a cool down period
+func (b *BEOverloadTainter) checkEvictions(now time.Time) (overloaded bool, stable bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.pruneEvictions(now)
+
+	count := 0
+	for _, evictTime := range b.evictionTimes {
+		if now.Sub(evictTime) <= b.windowDuration() {
+			count++
+		}
+	}
+	overloaded = b.resmanager.config.BEOverloadTaintEvictionCount > 0 && count >= b.resmanager.config.BEOverloadTaintEvictionCount
+	stable = len(b.evictionTimes) == 0 || now.Sub(b.evictionTimes[len(b.evictionTimes)-1]) >= b.coolDownDuration()
+	return overloaded, stable
+}
+
+func (b *BEOverloadTainter) windowDuration() time.Duration {
+	return time.Duration(b.resmanager.config.BEOverloadTaintWindowSeconds) * time.Second
+}
+
+func (b *BEOverloadTainter) coolDownDuration() time.Duration {
+	return time.Duration(b.resmanager.config.BEOverloadTaintCoolDownSeconds) * time.Second
+}
+
+func (b *BEOverloadTainter) reconcile() {
+	node := b.resmanager.statesInformer.GetNode()
+	if node == nil {
+		klog.Warningf("skip be overload taint reconcile, node is nil")
+		return
+	}
+
+	overloaded, stable := b.checkEvictions(time.Now())
+	tainted := hasBEOverloadedTaint(node)
+	if !tainted && overloaded {
+		klog.Infof("node %s is overloaded by frequent be evictions, add taint %s", node.Name, apiext.TaintNodeBEOverloaded)
+		err := b.updateBEOverloadedTaint(true)
+		if err != nil {
+			klog.Errorf("failed to add taint %s to node %s, error: %v", apiext.TaintNodeBEOverloaded, node.Name, err)
+		}
+		b.recordDecision("add taint", overloaded, stable, err)
+	} else if tainted && stable {
+		klog.Infof("node %s has been stable for be evictions, remove taint %s", node.Name, apiext.TaintNodeBEOverloaded)
+		err := b.updateBEOverloadedTaint(false)
+		if err != nil {
+			klog.Errorf("failed to remove taint %s from node %s, error: %v", apiext.TaintNodeBEOverloaded, node.Name, err)
+		}
+		b.recordDecision("remove taint", overloaded, stable, err)
+	}
+}
+
+func (b *BEOverloadTainter) recordDecision(action string, overloaded, stable bool, err error) {
+	inputs := fmt.Sprintf("overloaded=%v stable=%v evictionCount=%v window=%v coolDown=%v", overloaded, stable,
+		b.resmanager.config.BEOverloadTaintEvictionCount, b.windowDuration(), b.coolDownDuration())
+	if err != nil {
+		action = fmt.Sprintf("%s failed, error: %v", action, err)
+	}
+	b.resmanager.decisionLog.record(features.BEOverloadTaint, inputs, action)
+}
+
+// updateBEOverloadedTaint adds or removes the be-overloaded taint of the latest node, which is got again to retry on
+// the conflict, so the taints updated by others meanwhile are not overwritten
+func (b *BEOverloadTainter) updateBEOverloadedTaint(taint bool) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		return b.tryUpdateBEOverloadedTaint(taint)
+	})
+}
+
+func (b *BEOverloadTainter) tryUpdateBEOverloadedTaint(taint bool) error {
+	nodeClient := b.resmanager.kubeClient.CoreV1().Nodes()
+	node, err := nodeClient.Get(context.TODO(), b.resmanager.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if hasBEOverloadedTaint(node) == taint {
+		return nil
+	}
+
+	newNode := node.DeepCopy()
+	var taints []corev1.Taint
+	for _, t := range node.Spec.Taints {
+		if t.Key != apiext.TaintNodeBEOverloaded {
+			taints = append(taints, t)
+		}
+	}
+	if taint {
+		now := metav1.Now()
+		taints = append(taints, corev1.Taint{
+			Key:       apiext.TaintNodeBEOverloaded,
+			Effect:    corev1.TaintEffectNoSchedule,
+			TimeAdded: &now,
+		})
+	}
+	newNode.Spec.Taints = taints
+	b.resmanager.throttleWrite()
+	_, err = nodeClient.Update(context.TODO(), newNode, metav1.UpdateOptions{})
+	return err
+}
+
+func hasBEOverloadedTaint(node *corev1.Node) bool {
+	for _, t := range node.Spec.Taints {
+		if t.Key == apiext.TaintNodeBEOverloaded && t.Effect == corev1.TaintEffectNoSchedule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+)
+
+func Test_BEOverloadTainter_reconcile(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	testingNode := getNode("80", "120G")
+	// node is cluster-scoped
+	testingNode.Namespace = ""
+	testingNode.Spec.Taints = []corev1.Taint{
+		{Key: "other-taint", Effect: corev1.TaintEffectNoExecute},
+	}
+	client := clientsetfake.NewSimpleClientset(testingNode)
+	getLatestNode := func() *corev1.Node {
+		node, err := client.CoreV1().Nodes().Get(context.TODO(), testingNode.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		return node
+	}
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetNode().DoAndReturn(getLatestNode).AnyTimes()
+
+	cfg := NewDefaultConfig()
+	cfg.BEOverloadTaintEvictionCount = 3
+	cfg.BEOverloadTaintWindowSeconds = 60
+	cfg.BEOverloadTaintCoolDownSeconds = 120
+	r := &resmanager{config: cfg, nodeName: testingNode.Name, statesInformer: mockStatesInformer, kubeClient: client}
+	tainter := NewBEOverloadTainter(r)
+
+	now := time.Now()
+	tests := []struct {
+		name       string
+		evictAgo   []time.Duration
+		wantTaint  bool
+		wantTaints int
+	}{
+		{
+			name:       "evictions below the count do not taint",
+			evictAgo:   []time.Duration{50 * time.Second, 40 * time.Second},
+			wantTaint:  false,
+			wantTaints: 1,
+		},
+		{
+			name:       "taint when evictions reach the count in the window",
+			evictAgo:   []time.Duration{30 * time.Second},
+			wantTaint:  true,
+			wantTaints: 2,
+		},
+		{
+			name:       "keep the taint until cool down",
+			evictAgo:   nil,
+			wantTaint:  true,
+			wantTaints: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, ago := range tt.evictAgo {
+				tainter.recordEviction(now.Add(-ago))
+			}
+			tainter.reconcile()
+			node := getLatestNode()
+			assert.Equal(t, tt.wantTaint, hasBEOverloadedTaint(node))
+			assert.Equal(t, tt.wantTaints, len(node.Spec.Taints))
+		})
+	}
+
+	t.Run("keep the taint when evictions are out of the window but in the cool down", func(t *testing.T) {
+		tainter.evictionTimes = []time.Time{now.Add(-100 * time.Second)}
+		tainter.reconcile()
+		assert.True(t, hasBEOverloadedTaint(getLatestNode()))
+	})
+
+	t.Run("remove the taint when stable for the cool down", func(t *testing.T) {
+		tainter.evictionTimes = []time.Time{now.Add(-130 * time.Second)}
+		tainter.reconcile()
+		node := getLatestNode()
+		assert.False(t, hasBEOverloadedTaint(node))
+		assert.Equal(t, []corev1.Taint{{Key: "other-taint", Effect: corev1.TaintEffectNoExecute}}, node.Spec.Taints)
+	})
+}
+
+func Test_BEOverloadTainter_updateBEOverloadedTaint_conflict(t *testing.T) {
+	testingNode := getNode("80", "120G")
+	testingNode.Namespace = ""
+	client := clientsetfake.NewSimpleClientset(testingNode)
+	// another taint is added meanwhile, which makes the first update conflict
+	conflicts := 1
+	client.PrependReactor("update", "nodes", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+		if conflicts <= 0 {
+			return false, nil, nil
+		}
+		conflicts--
+		node, err := client.Tracker().Get(corev1.SchemeGroupVersion.WithResource("nodes"), "", testingNode.Name)
+		assert.NoError(t, err)
+		concurrentNode := node.(*corev1.Node).DeepCopy()
+		concurrentNode.Spec.Taints = append(concurrentNode.Spec.Taints,
+			corev1.Taint{Key: "concurrent-taint", Effect: corev1.TaintEffectNoSchedule})
+		assert.NoError(t, client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("nodes"), concurrentNode, ""))
+		return true, nil, errors.NewConflict(corev1.Resource("nodes"), testingNode.Name, fmt.Errorf("conflict"))
+	})
+	r := &resmanager{config: NewDefaultConfig(), nodeName: testingNode.Name, kubeClient: client}
+	tainter := NewBEOverloadTainter(r)
+
+	assert.NoError(t, tainter.updateBEOverloadedTaint(true))
+	node, err := client.CoreV1().Nodes().Get(context.TODO(), testingNode.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, hasBEOverloadedTaint(node))
+	assert.Equal(t, 2, len(node.Spec.Taints), "the concurrent taint should be kept")
+	assert.Equal(t, "concurrent-taint", node.Spec.Taints[0].Key)
+}
+
+func Test_evictPod_recordBEOverload(t *testing.T) {
+	bePod := createTestPod(apiext.QoSBE, "test_be_pod_overload")
+	lsPod := createTestPod(apiext.QoSLS, "test_ls_pod_overload")
+	node := getNode("80", "120G")
+	client := clientsetfake.NewSimpleClientset(bePod, lsPod)
+	r := &resmanager{config: NewDefaultConfig(), eventRecorder: &FakeRecorder{}, kubeClient: client}
+	r.beOverloadTainter = NewBEOverloadTainter(r)
+
+	assert.True(t, r.evictPod(bePod, node, "evict pod first", ""))
+	assert.True(t, r.evictPod(lsPod, node, "evict pod first", ""))
+	assert.Equal(t, 1, len(r.beOverloadTainter.evictionTimes))
+}
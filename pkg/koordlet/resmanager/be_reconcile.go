@@ -24,7 +24,6 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
-	"github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -35,10 +34,10 @@ func (r *resmanager) reconcileBECgroup() {
 	nodeSLO := r.getNodeSLOCopy()
 	podsMeta := r.statesInformer.GetAllPods()
 	for _, podMeta := range podsMeta {
-		if extension.GetPodQoSClass(podMeta.Pod) != extension.QoSBE {
+		if extension.GetPodQoSClass(podMeta.Pod) != extension.QoSBE || !r.isEnforcementEligible(podMeta.Pod) {
 			continue
 		}
-		if getCPUSuppressPolicy(nodeSLO) != v1alpha1.CPUCfsQuotaPolicy {
+		if !isCfsQuotaSuppressEnabled(getCPUSuppressPolicy(nodeSLO)) {
 			reconcileBECPULimit(podMeta)
 		}
 		reconcileBECPUShare(podMeta)
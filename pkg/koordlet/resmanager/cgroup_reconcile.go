@@ -17,15 +17,22 @@
 package resmanager
 
 import (
+	"fmt"
 	"math"
+	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 	"github.com/koordinator-sh/koordinator/pkg/util/system"
@@ -33,11 +40,34 @@ import (
 
 const (
 	CgroupResourcesReconcileForceUpdateSeconds int = 60
+
+	// rootCgroupOwner is the owner name of the root cgroup resources
+	rootCgroupOwner = "root"
 )
 
+// cgroupReconcileResourceTypes is the update order of the resource types; resources of the unknown type ("") are
+// updated at last without recording the duration.
+var cgroupReconcileResourceTypes = []string{metrics.CgroupReconcileResourceCPU, metrics.CgroupReconcileResourceMemory, ""}
+
+// defaultCgroupResourcesReconcile serves the running cgroup reconciler for the debug http handler.
+var defaultCgroupResourcesReconcile = &cgroupResourcesReconcileRef{}
+
 type CgroupResourcesReconcile struct {
 	resmanager *resmanager
 	executor   *LeveledResourceUpdateExecutor
+	// podQueue holds the uid of pods added or updated, which are reconciled immediately without waiting for the
+	// periodic reconciliation
+	podQueue workqueue.Interface
+	// nodeUpdated notifies to reconcile all cgroups immediately when the node resources change, which has 1 buffer so
+	// the notifications during a reconciliation are coalesced
+	nodeUpdated chan struct{}
+	// reconcileLock serializes the full reconciliations and the pod reconciliations triggered by the periodic loop,
+	// the node worker and the pod worker, since the leveled updates and the executor cache are not to interleave
+	reconcileLock sync.Mutex
+	// clock is to check the enforcement grace of the pods, which uses the real clock if nil
+	clock clock.Clock
+	// qosAppliedEvents records the events of the applied qos values on the pods, which is nil if disabled
+	qosAppliedEvents *qosAppliedEvents
 }
 
 // cgroupResourceSummary summarizes values of cgroup resources to update; nil value means not to update
@@ -46,6 +76,7 @@ type cgroupResourceSummary struct {
 	memoryMin              *int64
 	memoryLow              *int64
 	memoryHigh             *int64
+	memorySwapMax          *int64
 	memoryWmarkRatio       *int64
 	memoryWmarkScaleFactor *int64
 	memoryWmarkMinAdj      *int64
@@ -54,20 +85,180 @@ type cgroupResourceSummary struct {
 	memoryOomKillGroup     *int64
 }
 
+// memoryHighScaleState keeps the memory.high scale ratio applied by the last reconciliation, so that the lowered
+// memory.high can be released by steps of SuppressReleaseStepPercent.
+type memoryHighScaleState struct {
+	lock sync.Mutex
+	// ratio is 0 if no ratio is applied yet
+	ratio float64
+}
+
 func NewCgroupResourcesReconcile(resmanager *resmanager) *CgroupResourcesReconcile {
 	executor := NewLeveledResourceUpdateExecutor("CgroupResourcesExecutor", CgroupResourcesReconcileForceUpdateSeconds)
-	return &CgroupResourcesReconcile{
-		resmanager: resmanager,
-		executor:   executor,
+	m := &CgroupResourcesReconcile{
+		resmanager:  resmanager,
+		executor:    executor,
+		podQueue:    workqueue.New(),
+		nodeUpdated: make(chan struct{}, 1),
+		clock:       clock.RealClock{},
+	}
+	if resmanager != nil && resmanager.config != nil {
+		m.qosAppliedEvents = newQoSAppliedEvents(resmanager.eventRecorder,
+			resmanager.config.QoSAppliedEventIntervalSeconds, clock.RealClock{})
 	}
+	return m
+}
+
+func (m *CgroupResourcesReconcile) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock.Now()
 }
 
 func (m *CgroupResourcesReconcile) RunInit(stopCh <-chan struct{}) error {
 	m.executor.Run(stopCh)
+	defaultCgroupResourcesReconcile.set(m)
+	if m.resmanager == nil || m.resmanager.statesInformer == nil {
+		return nil
+	}
+	// reconcile pods once they are added or updated, and keep the periodic reconciliation as the full sweep
+	m.registerPodEventHandler()
+	go m.runPodWorker(stopCh)
+	// reconcile all cgroups once the node resources change, since the qos values derived from them become stale
+	m.registerNodeEventHandler()
+	go m.runNodeWorker(stopCh)
+	return nil
+}
+
+func (m *CgroupResourcesReconcile) registerPodEventHandler() {
+	m.resmanager.statesInformer.AddPodEventHandler(statesinformer.PodEventHandlerFuncs{
+		PodAddedFunc: func(pod *statesinformer.PodMeta) {
+			m.podQueue.Add(string(pod.Pod.UID))
+		},
+		PodUpdatedFunc: func(oldPod, newPod *statesinformer.PodMeta) {
+			m.podQueue.Add(string(newPod.Pod.UID))
+		},
+	})
+}
+
+func (m *CgroupResourcesReconcile) registerNodeEventHandler() {
+	m.resmanager.statesInformer.AddNodeEventHandler(statesinformer.NodeEventHandlerFuncs{
+		NodeUpdatedFunc: func(oldNode, newNode *corev1.Node) {
+			if !isNodeResourcesChanged(oldNode, newNode) {
+				return
+			}
+			klog.V(4).Infof("node %s resources changed, allocatable %v, capacity %v", newNode.Name,
+				util.DumpJSON(newNode.Status.Allocatable), util.DumpJSON(newNode.Status.Capacity))
+			select {
+			case m.nodeUpdated <- struct{}{}:
+			default:
+			}
+		},
+	})
+}
+
+// runNodeWorker reconciles all cgroups when notified by the node updates until the stopCh is closed.
+func (m *CgroupResourcesReconcile) runNodeWorker(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-m.nodeUpdated:
+			m.reconcile()
+		}
+	}
+}
+
+// isNodeResourcesChanged returns whether the cpu or memory of the node allocatable or capacity changes, where the
+// capacity is also compared since the qos values can be derived from it, e.g. by QoSNodeMemorySource.
+func isNodeResourcesChanged(oldNode, newNode *corev1.Node) bool {
+	if oldNode == nil || newNode == nil {
+		return oldNode != newNode
+	}
+	return oldNode.Status.Allocatable.Cpu().Cmp(*newNode.Status.Allocatable.Cpu()) != 0 ||
+		oldNode.Status.Allocatable.Memory().Cmp(*newNode.Status.Allocatable.Memory()) != 0 ||
+		oldNode.Status.Capacity.Cpu().Cmp(*newNode.Status.Capacity.Cpu()) != 0 ||
+		oldNode.Status.Capacity.Memory().Cmp(*newNode.Status.Capacity.Memory()) != 0
+}
+
+// runPodWorker reconciles the queued pods until the stopCh is closed.
+func (m *CgroupResourcesReconcile) runPodWorker(stopCh <-chan struct{}) {
+	go func() {
+		<-stopCh
+		m.podQueue.ShutDown()
+	}()
+	for m.processNextPod() {
+	}
+}
+
+func (m *CgroupResourcesReconcile) processNextPod() bool {
+	item, quit := m.podQueue.Get()
+	if quit {
+		return false
+	}
+	defer m.podQueue.Done(item)
+	if err := m.reconcilePod(item.(string), false); err != nil {
+		klog.V(5).Infof("skip reconciling pod %s, err: %v", item, err)
+	}
+	return true
+}
+
+// ReconcilePod force-updates the pod-level and container-level cgroup resources of the pod with the given UID,
+// regardless of the values cached by the last updates. It is for debugging and targeted remediation.
+func (m *CgroupResourcesReconcile) ReconcilePod(podUID string) error {
+	return m.reconcilePod(podUID, true)
+}
+
+// reconcilePod calculates and updates the pod-level and container-level resources of the pod. The qos-level
+// resources are left to the periodic reconciliation since they are summarized with all pods.
+func (m *CgroupResourcesReconcile) reconcilePod(podUID string, force bool) error {
+	m.reconcileLock.Lock()
+	defer m.reconcileLock.Unlock()
+	nodeSLO := m.resmanager.getNodeSLOCopy()
+	if nodeSLO == nil || nodeSLO.Spec.ResourceQoSStrategy == nil {
+		return fmt.Errorf("nodeSLO or ResourceQoSStrategy is nil")
+	}
+	node := m.resmanager.statesInformer.GetNode()
+	if node == nil || node.Status.Allocatable == nil {
+		return fmt.Errorf("node is invalid: %v", util.DumpJSON(node))
+	}
+	var podMeta *statesinformer.PodMeta
+	for _, meta := range m.resmanager.statesInformer.GetAllPods() {
+		if string(meta.Pod.UID) == podUID {
+			podMeta = meta
+			break
+		}
+	}
+	if podMeta == nil {
+		return fmt.Errorf("pod %s is not found on the node", podUID)
+	}
+
+	mergedPodCfg, ok := m.getReconciledPodResourceQoS(nodeSLO.Spec.ResourceQoSStrategy, podMeta.Pod)
+	if !ok {
+		return fmt.Errorf("pod %s is not to reconcile, e.g. not running or not eligible for enforcement",
+			util.GetPodKey(podMeta.Pod))
+	}
+	// keep the memory.min scaled as the periodic reconciliation does
+	totalMemoryMin := m.sumPodsMemoryMin(nodeSLO.Spec.ResourceQoSStrategy, m.resmanager.statesInformer.GetAllPods())
+	memoryMinRatio := m.getMemoryMinScaleRatio(totalMemoryMin, node)
+	memoryHighRatio := m.getAppliedMemoryHighScaleRatio(node)
+	podResources, containerResources := m.calculatePodAndContainerResources(podMeta, node, mergedPodCfg, memoryMinRatio,
+		memoryHighRatio)
+	leveledResources := [][]MergeableResourceUpdater{nil, podResources, containerResources}
+	if force {
+		m.executor.LeveledUpdateBatch(leveledResources)
+		klog.V(4).Infof("cgroup resources of pod %s is force updated", util.GetPodKey(podMeta.Pod))
+	} else if m.updateLeveledResourcesByType(leveledResources) {
+		klog.V(5).Infof("cgroup resources of pod %s is exactly updated", util.GetPodKey(podMeta.Pod))
+	}
+	m.recordQoSAppliedEvents([]*statesinformer.PodMeta{podMeta}, podResources, containerResources)
 	return nil
 }
 
 func (m *CgroupResourcesReconcile) reconcile() {
+	m.reconcileLock.Lock()
+	defer m.reconcileLock.Unlock()
 	nodeSLO := m.resmanager.getNodeSLOCopy()
 	if nodeSLO == nil || nodeSLO.Spec.ResourceQoSStrategy == nil {
 		// do nothing if nodeSLO == nil || nodeSLO.Spec.ResourceQoSStrategy == nil
@@ -105,10 +296,91 @@ func (m *CgroupResourcesReconcile) calculateAndUpdateResources(nodeSLO *slov1alp
 	// cgroup-level order.
 	// e.g. /kubepods.slice/memory.min, /kubepods.slice-podxxx/memory.min, /kubepods.slice-podxxx/docker-yyy/memory.min
 	leveledResources := [][]MergeableResourceUpdater{qosResources, podResources, containerResources}
-	updated := m.executor.LeveledUpdateBatchByCache(leveledResources)
+	updated := m.updateLeveledResourcesByType(leveledResources)
 	if updated {
 		klog.V(5).Info("cgroup resources is exactly updated")
 	}
+	m.recordQoSAppliedEvents(podMetas, podResources, containerResources)
+	m.qosAppliedEvents.prune(podMetas)
+}
+
+// recordQoSAppliedEvents records the qos applied events of the pods with their pod-level and container-level
+// resources.
+func (m *CgroupResourcesReconcile) recordQoSAppliedEvents(podMetas []*statesinformer.PodMeta, podResources,
+	containerResources []MergeableResourceUpdater) {
+	if m.qosAppliedEvents == nil {
+		return
+	}
+	podsResources := map[string][]MergeableResourceUpdater{}
+	for _, resources := range [][]MergeableResourceUpdater{podResources, containerResources} {
+		for _, resource := range resources {
+			owner := resource.Owner()
+			if owner == nil || owner.Type != podType {
+				continue
+			}
+			key := owner.Namespace + "/" + owner.Name
+			podsResources[key] = append(podsResources[key], resource)
+		}
+	}
+	for _, podMeta := range podMetas {
+		resources, ok := podsResources[podMeta.Pod.Namespace+"/"+podMeta.Pod.Name]
+		if !ok {
+			continue
+		}
+		m.qosAppliedEvents.record(podMeta.Pod, summarizeAppliedPodQoS(resources))
+	}
+}
+
+// updateLeveledResourcesByType updates the leveled resources in batches of the resource type, and records the
+// duration of each batch.
+func (m *CgroupResourcesReconcile) updateLeveledResourcesByType(leveledResources [][]MergeableResourceUpdater) bool {
+	typedResources := splitLeveledResourcesByType(leveledResources)
+	updated := false
+	for _, resourceType := range cgroupReconcileResourceTypes {
+		resources, ok := typedResources[resourceType]
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		if m.executor.LeveledUpdateBatchByCache(resources) {
+			updated = true
+		}
+		if resourceType != "" {
+			metrics.RecordCgroupReconcileDuration(resourceType, time.Since(start).Seconds())
+		}
+	}
+	return updated
+}
+
+// splitLeveledResourcesByType groups the leveled resources by the resource type and keeps the level order.
+func splitLeveledResourcesByType(leveledResources [][]MergeableResourceUpdater) map[string][][]MergeableResourceUpdater {
+	typedResources := map[string][][]MergeableResourceUpdater{}
+	for level, resources := range leveledResources {
+		for _, resource := range resources {
+			resourceType := getCgroupResourceType(resource)
+			if _, ok := typedResources[resourceType]; !ok {
+				typedResources[resourceType] = make([][]MergeableResourceUpdater, len(leveledResources))
+			}
+			typedResources[resourceType][level] = append(typedResources[resourceType][level], resource)
+		}
+	}
+	return typedResources
+}
+
+// getCgroupResourceType returns the resource type of a cgroup updater according to its cgroup subsystem.
+func getCgroupResourceType(resource MergeableResourceUpdater) string {
+	cgroupResource, ok := resource.(*CgroupResourceUpdater)
+	if !ok {
+		return ""
+	}
+	switch cgroupResource.file.Subfs {
+	case system.CgroupCPUDir, system.CgroupCPUSetDir, system.CgroupCPUacctDir:
+		return metrics.CgroupReconcileResourceCPU
+	case system.CgroupMemDir:
+		return metrics.CgroupReconcileResourceMemory
+	default:
+		return ""
+	}
 }
 
 // calculateResources calculates qos-level, pod-level and container-level resources with nodeCfg and podMetas
@@ -121,33 +393,50 @@ func (m *CgroupResourcesReconcile) calculateResources(nodeCfg *slov1alpha1.Resou
 		corev1.PodQOSBestEffort: {},
 	}
 
+	var reconciledPodMetas []*statesinformer.PodMeta
+	var reconciledPodCfgs []*slov1alpha1.ResourceQoS
 	for _, podMeta := range podMetas {
 		pod := podMeta.Pod
-		// ignore non-running pods
-		if pod.Status.Phase != corev1.PodRunning && pod.Status.Phase != corev1.PodPending {
-			klog.V(5).Infof("skip calculate cgroup summary for non-running pod %s", util.GetPodKey(pod))
-			continue
-		}
-
 		// retrieve pod-level config
-		kubeQoS := util.GetKubeQosClass(pod) // assert kubeQoS belongs to {Guaranteed, Burstable, Besteffort}
-		podQoSCfg := getPodResourceQoSByQoSClass(pod, nodeCfg, m.resmanager.config)
-		mergedPodCfg, err := m.getMergedPodResourceQoS(pod, podQoSCfg)
-		if err != nil {
-			klog.Errorf("failed to retrieve pod resourceQoS, err: %v", err)
+		mergedPodCfg, ok := m.getReconciledPodResourceQoS(nodeCfg, pod)
+		if !ok {
 			continue
 		}
+		kubeQoS := util.GetKubeQosClass(pod) // assert kubeQoS belongs to {Guaranteed, Burstable, Besteffort}
 
 		// update summary for qos resources
 		updateCgroupSummaryForQoS(qosSummary[kubeQoS], pod, mergedPodCfg)
+		reconciledPodMetas = append(reconciledPodMetas, podMeta)
+		reconciledPodCfgs = append(reconciledPodCfgs, mergedPodCfg)
+	}
+	// summarize qos-level resources
+	completeCgroupSummaryForQoS(qosSummary)
+
+	// scale down the memory.min of all levels if the total exceeds the node memory limit
+	memoryMinRatio := m.getMemoryMinScaleRatio(qosSummary[corev1.PodQOSGuaranteed].memoryMin, node)
+	for _, summary := range qosSummary {
+		scaleMemoryMin(summary, memoryMinRatio)
+	}
+	// the Guaranteed summary sums the memory.min of all pods since it is the ancestor of the others
+	var appliedMemoryMin int64
+	if qosSummary[corev1.PodQOSGuaranteed].memoryMin != nil {
+		appliedMemoryMin = *qosSummary[corev1.PodQOSGuaranteed].memoryMin
+	}
+	metrics.RecordAppliedMemoryMin(float64(appliedMemoryMin))
+	memoryHighRatio := m.rampMemoryHighScaleRatio(m.getMemoryHighScaleRatio(node))
 
+	for i, podMeta := range reconciledPodMetas {
 		// calculate pod-level and container-level resources and make resourceUpdaters
-		podResources, containerResources := m.calculatePodAndContainerResources(podMeta, node, mergedPodCfg)
+		podResources, containerResources := m.calculatePodAndContainerResources(podMeta, node, reconciledPodCfgs[i],
+			memoryMinRatio, memoryHighRatio)
 		podLevelResources = append(podLevelResources, podResources...)
 		containerLevelResources = append(containerLevelResources, containerResources...)
 	}
-	// summarize qos-level resources
-	completeCgroupSummaryForQoS(qosSummary)
+
+	// calculate root cgroup resources, which are at the top of the qos-level cgroups
+	if nodeCfg != nil {
+		qosLevelResources = append(qosLevelResources, m.calculateRootResources(nodeCfg.CgroupRoot)...)
+	}
 
 	// calculate qos-level resources with the qos summary
 	// NOTE: first visit Guaranteed since it actually has a higher level cgroup than others'
@@ -161,6 +450,28 @@ func (m *CgroupResourcesReconcile) calculateResources(nodeCfg *slov1alpha1.Resou
 	return
 }
 
+// getReconciledPodResourceQoS returns the merged pod-level config if the pod should be reconciled.
+func (m *CgroupResourcesReconcile) getReconciledPodResourceQoS(nodeCfg *slov1alpha1.ResourceQoSStrategy,
+	pod *corev1.Pod) (*slov1alpha1.ResourceQoS, bool) {
+	// ignore non-running pods
+	if pod.Status.Phase != corev1.PodRunning && pod.Status.Phase != corev1.PodPending {
+		klog.V(5).Infof("skip calculate cgroup summary for non-running pod %s", util.GetPodKey(pod))
+		return nil, false
+	}
+	if !m.resmanager.isEnforcementEligible(pod) {
+		klog.V(5).Infof("skip calculate cgroup summary for enforcement-ineligible pod %s", util.GetPodKey(pod))
+		return nil, false
+	}
+
+	podQoSCfg := getPodResourceQoSByQoSClass(pod, nodeCfg, m.resmanager.config)
+	mergedPodCfg, err := m.getMergedPodResourceQoS(pod, podQoSCfg)
+	if err != nil {
+		klog.Errorf("failed to retrieve pod resourceQoS, err: %v", err)
+		return nil, false
+	}
+	return mergedPodCfg, true
+}
+
 func (m *CgroupResourcesReconcile) calculateQoSResources(summary *cgroupResourceSummary, qos corev1.PodQOSClass,
 	qosCfg *slov1alpha1.ResourceQoS) []MergeableResourceUpdater {
 	// double-check qosCfg is not nil
@@ -181,14 +492,180 @@ func (m *CgroupResourcesReconcile) calculateQoSResources(summary *cgroupResource
 	return makeCgroupResources(GroupOwnerRef(string(qos)), qosDir, summary)
 }
 
+// calculateRootResources calculates the root cgroup resources with the CgroupRoot config. Since the root cgroup has
+// no limits or siblings, the fields derived from them are nonsensical at the root scope and are ignored.
+func (m *CgroupResourcesReconcile) calculateRootResources(rootCfg *slov1alpha1.ResourceQoS) []MergeableResourceUpdater {
+	if rootCfg == nil || rootCfg.MemoryQoS == nil || rootCfg.MemoryQoS.Enable == nil || !*rootCfg.MemoryQoS.Enable {
+		return nil
+	}
+
+	memoryQoS := &rootCfg.MemoryQoS.MemoryQoS
+	var ignoredFields []string
+	if memoryQoS.MinLimitPercent != nil {
+		ignoredFields = append(ignoredFields, "minLimitPercent")
+	}
+	if memoryQoS.LowLimitPercent != nil {
+		ignoredFields = append(ignoredFields, "lowLimitPercent")
+	}
+	if memoryQoS.ThrottlingPercent != nil {
+		ignoredFields = append(ignoredFields, "throttlingPercent")
+	}
+	if memoryQoS.SwapLimitPercent != nil {
+		ignoredFields = append(ignoredFields, "swapLimitPercent")
+	}
+	if memoryQoS.Priority != nil {
+		ignoredFields = append(ignoredFields, "priority")
+	}
+	if memoryQoS.OomKillGroup != nil {
+		ignoredFields = append(ignoredFields, "oomKillGroup")
+	}
+	if len(ignoredFields) > 0 {
+		klog.V(4).Infof("ignore memory qos fields %v of cgroupRoot, since they take no effect on the root cgroup",
+			ignoredFields)
+	}
+
+	summary := &cgroupResourceSummary{
+		memoryWmarkRatio:       memoryQoS.WmarkRatio,
+		memoryWmarkScaleFactor: memoryQoS.WmarkScalePermill,
+		memoryWmarkMinAdj:      memoryQoS.WmarkMinAdj,
+		memoryUsePriorityOom:   memoryQoS.PriorityEnable,
+	}
+	return makeCgroupResources(GroupOwnerRef(rootCgroupOwner), "", summary)
+}
+
+// sumPodsMemoryMin returns the total memory.min of the pods to reconcile, or nil if no pod sets memory.min.
+func (m *CgroupResourcesReconcile) sumPodsMemoryMin(nodeCfg *slov1alpha1.ResourceQoSStrategy,
+	podMetas []*statesinformer.PodMeta) *int64 {
+	summary := &cgroupResourceSummary{}
+	for _, podMeta := range podMetas {
+		mergedPodCfg, ok := m.getReconciledPodResourceQoS(nodeCfg, podMeta.Pod)
+		if !ok {
+			continue
+		}
+		updateCgroupSummaryForQoS(summary, podMeta.Pod, mergedPodCfg)
+	}
+	return summary.memoryMin
+}
+
+// getMemoryMinScaleRatio returns the ratio to scale down the memory.min of the cgroups, so that the total memory.min
+// does not exceed MemoryMinAllocatablePercent of the node memory for qos. It returns 1 if no need to scale.
+func (m *CgroupResourcesReconcile) getMemoryMinScaleRatio(totalMemoryMin *int64, node *corev1.Node) float64 {
+	if totalMemoryMin == nil || *totalMemoryMin <= 0 || node == nil || node.Status.Allocatable == nil ||
+		m.resmanager.config == nil || m.resmanager.config.MemoryMinAllocatablePercent <= 0 {
+		return 1
+	}
+	memoryMinLimit := m.getNodeMemoryForQoS(node) * int64(m.resmanager.config.MemoryMinAllocatablePercent) / 100
+	if *totalMemoryMin <= memoryMinLimit {
+		return 1
+	}
+	ratio := float64(memoryMinLimit) / float64(*totalMemoryMin)
+	klog.Warningf("total memory.min %v of pods exceeds %v%% of node memory %v, scale down memory.min by ratio %.4f",
+		*totalMemoryMin, m.resmanager.config.MemoryMinAllocatablePercent, memoryMinLimit, ratio)
+	return ratio
+}
+
+// getMemoryHighScaleRatio returns the ratio to scale down the memory.high of the BE containers according to the node
+// memory usage when MemoryHighDynamicScale is enabled. It returns 1 if the scaling is disabled or the node memory
+// usage is unknown.
+func (m *CgroupResourcesReconcile) getMemoryHighScaleRatio(node *corev1.Node) float64 {
+	cfg := m.resmanager.config
+	if cfg == nil || !cfg.MemoryHighDynamicScale || m.resmanager.metricCache == nil || node == nil {
+		return 1
+	}
+	memoryCapacity := node.Status.Capacity.Memory().Value()
+	if memoryCapacity <= 0 {
+		return 1
+	}
+	nodeMetric := m.resmanager.collectNodeMetricLast()
+	if nodeMetric == nil {
+		return 1
+	}
+	usagePercent := float64(nodeMetric.MemoryUsed.MemoryWithoutCache.Value()) * 100 / float64(memoryCapacity)
+	ratio := calculateMemoryHighScaleRatio(usagePercent, cfg.MemoryHighScaleStartPercent,
+		cfg.MemoryHighScaleFullPercent, cfg.MemoryHighScaleMinPercent)
+	if ratio < 1 {
+		klog.V(4).Infof("node memory usage %.2f%% exceeds %v%%, scale down memory.high of be containers by ratio %.4f",
+			usagePercent, cfg.MemoryHighScaleStartPercent, ratio)
+	}
+	return ratio
+}
+
+// rampMemoryHighScaleRatio returns the ratio to apply in this reconciliation by the target ratio. The ratio is lowered
+// at once, while it is raised by SuppressReleaseStepPercent of the gap to the target, so that the be memory usage
+// does not re-spike when the pressure subsides.
+func (m *CgroupResourcesReconcile) rampMemoryHighScaleRatio(targetRatio float64) float64 {
+	state := &m.resmanager.memoryHighScale
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	ratio := targetRatio
+	if stepPercent := m.resmanager.getSuppressReleaseStepPercent(); stepPercent > 0 && state.ratio > 0 &&
+		targetRatio > state.ratio {
+		ratio = state.ratio + (targetRatio-state.ratio)*float64(stepPercent)/100
+		// release the tiny remaining gap at once
+		if targetRatio-ratio < 0.01 {
+			ratio = targetRatio
+		}
+		klog.V(4).Infof("release memory.high of be containers by step %v%%, ratio %.4f, target ratio %.4f",
+			stepPercent, ratio, targetRatio)
+	}
+	state.ratio = ratio
+	return ratio
+}
+
+// getAppliedMemoryHighScaleRatio returns the memory.high scale ratio applied by the last periodic reconciliation, or
+// the target ratio if none is applied yet.
+func (m *CgroupResourcesReconcile) getAppliedMemoryHighScaleRatio(node *corev1.Node) float64 {
+	state := &m.resmanager.memoryHighScale
+	state.lock.Lock()
+	ratio := state.ratio
+	state.lock.Unlock()
+	if ratio > 0 {
+		return ratio
+	}
+	return m.getMemoryHighScaleRatio(node)
+}
+
+// calculateMemoryHighScaleRatio decreases the ratio linearly from 1 to minPercent% as the node memory usage rises from
+// startPercent% to fullPercent%, so the memory.high eases back as the pressure subsides.
+func calculateMemoryHighScaleRatio(usagePercent float64, startPercent, fullPercent, minPercent int) float64 {
+	if fullPercent <= startPercent {
+		klog.Warningf("skip scaling memory.high, full percent %v should be greater than start percent %v",
+			fullPercent, startPercent)
+		return 1
+	}
+	if minPercent < 0 {
+		minPercent = 0
+	} else if minPercent > 100 {
+		minPercent = 100
+	}
+	minRatio := float64(minPercent) / 100
+	if usagePercent <= float64(startPercent) {
+		return 1
+	}
+	if usagePercent >= float64(fullPercent) {
+		return minRatio
+	}
+	pressure := (usagePercent - float64(startPercent)) / float64(fullPercent-startPercent)
+	return 1 - (1-minRatio)*pressure
+}
+
+// scaleMemoryMin scales the memory.min of the summary by the ratio.
+func scaleMemoryMin(summary *cgroupResourceSummary, ratio float64) {
+	if summary == nil || summary.memoryMin == nil || ratio >= 1 {
+		return
+	}
+	*summary.memoryMin = int64(float64(*summary.memoryMin) * ratio)
+}
+
 func (m *CgroupResourcesReconcile) calculatePodAndContainerResources(podMeta *statesinformer.PodMeta, node *corev1.Node,
-	podCfg *slov1alpha1.ResourceQoS) (podResources, containerResources []MergeableResourceUpdater) {
+	podCfg *slov1alpha1.ResourceQoS, memoryMinRatio, memoryHighRatio float64) (podResources, containerResources []MergeableResourceUpdater) {
 	pod := podMeta.Pod
 	podDir := util.GetPodCgroupDirWithKube(podMeta.CgroupDir)
 
-	podResources = m.calculatePodResources(pod, podDir, podCfg)
+	podResources = m.calculatePodResources(pod, podDir, podCfg, memoryMinRatio)
 
-	for _, container := range pod.Spec.Containers {
+	// the ephemeral containers are reconciled as well as the containers
+	for _, container := range util.GetPodContainers(pod, true) {
 		_, containerStatus, err := util.FindContainerIdAndStatusByName(&pod.Status, container.Name)
 		if err != nil {
 			klog.Warningf("failed to find containerStatus, pod %s, container %s, err: %v", util.GetPodKey(pod),
@@ -201,14 +678,16 @@ func (m *CgroupResourcesReconcile) calculatePodAndContainerResources(podMeta *st
 			continue
 		}
 
-		curContainerResources := m.calculateContainerResources(&container, pod, node, containerDir, podCfg)
+		curContainerResources := m.calculateContainerResources(&container, pod, node, containerDir, podCfg, memoryMinRatio,
+			memoryHighRatio)
 		containerResources = append(containerResources, curContainerResources...)
 	}
 
 	return
 }
 
-func (m *CgroupResourcesReconcile) calculatePodResources(pod *corev1.Pod, parentDir string, podCfg *slov1alpha1.ResourceQoS) []MergeableResourceUpdater {
+func (m *CgroupResourcesReconcile) calculatePodResources(pod *corev1.Pod, parentDir string, podCfg *slov1alpha1.ResourceQoS,
+	memoryMinRatio float64) []MergeableResourceUpdater {
 	// double-check qos config is not nil
 	if podCfg == nil {
 		klog.V(5).Infof("calculatePodResources aborts since pod-level config is empty, cfg: %v", podCfg)
@@ -238,6 +717,7 @@ func (m *CgroupResourcesReconcile) calculatePodResources(pod *corev1.Pod, parent
 		if podCfg.MemoryQoS.MinLimitPercent != nil {
 			// assert no overflow for request < 1PiB
 			summary.memoryMin = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQoS.MinLimitPercent) / 100)
+			scaleMemoryMin(summary, memoryMinRatio)
 		}
 		if podCfg.MemoryQoS.LowLimitPercent != nil {
 			summary.memoryLow = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQoS.LowLimitPercent) / 100)
@@ -255,7 +735,7 @@ func (m *CgroupResourcesReconcile) calculatePodResources(pod *corev1.Pod, parent
 }
 
 func (m *CgroupResourcesReconcile) calculateContainerResources(container *corev1.Container, pod *corev1.Pod,
-	node *corev1.Node, parentDir string, podCfg *slov1alpha1.ResourceQoS) []MergeableResourceUpdater {
+	node *corev1.Node, parentDir string, podCfg *slov1alpha1.ResourceQoS, memoryMinRatio, memoryHighRatio float64) []MergeableResourceUpdater {
 	// double-check qos config is not nil
 	if podCfg == nil {
 		klog.V(5).Infof("calculateContainerResources aborts since pod-level config is empty, cfg: %v", podCfg)
@@ -287,23 +767,47 @@ func (m *CgroupResourcesReconcile) calculateContainerResources(container *corev1
 			memRequest = 0
 		}
 		// memory.min, memory.low: if container's memory request is not set, just consider it as zero
+		// requestMemoryMin is the memory.min derived from the request before the scaling by the node memory
+		var requestMemoryMin *int64
 		if podCfg.MemoryQoS.MinLimitPercent != nil {
-			summary.memoryMin = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQoS.MinLimitPercent) / 100)
+			requestMemoryMin = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQoS.MinLimitPercent) / 100)
+			summary.memoryMin = pointer.Int64Ptr(*requestMemoryMin)
+			scaleMemoryMin(summary, memoryMinRatio)
 		}
 		if podCfg.MemoryQoS.LowLimitPercent != nil {
 			summary.memoryLow = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQoS.LowLimitPercent) / 100)
 		}
 		// memory.high: if container's memory throttling factor is set as zero, disable memory.high by set to maximal;
-		// else if factor is set while container's limit not set, set memory.high with node memory allocatable
+		// else if factor is set while container's limit not set, set memory.high with node memory for qos
+		// the memory.high is disabled as well during the enforcement grace after the pod start
 		if podCfg.MemoryQoS.ThrottlingPercent != nil {
-			if *podCfg.MemoryQoS.ThrottlingPercent == 0 { // reset to system default if set 0
+			inGrace := m.resmanager.isPodInEnforcementGrace(pod, m.now())
+			if *podCfg.MemoryQoS.ThrottlingPercent == 0 || inGrace { // reset to system default if set 0
 				summary.memoryHigh = pointer.Int64Ptr(math.MaxInt64) // writing MaxInt64 is equal to write "max"
 			} else if memLimit > 0 {
 				summary.memoryHigh = pointer.Int64Ptr(memLimit * (*podCfg.MemoryQoS.ThrottlingPercent) / 100)
 			} else {
-				nodeLimit := node.Status.Allocatable.Memory().Value()
+				nodeLimit := m.getNodeMemoryForQoS(node)
 				summary.memoryHigh = pointer.Int64Ptr(nodeLimit * (*podCfg.MemoryQoS.ThrottlingPercent) / 100)
 			}
+			// lower the memory.high of BE containers under the node memory pressure, which is bounded by the memory.min
+			// derived from the request
+			if *podCfg.MemoryQoS.ThrottlingPercent != 0 && !inGrace && memoryHighRatio < 1 &&
+				apiext.GetPodQoSClass(pod) == apiext.QoSBE {
+				*summary.memoryHigh = int64(float64(*summary.memoryHigh) * memoryHighRatio)
+				if requestMemoryMin != nil && *summary.memoryHigh < *requestMemoryMin {
+					*summary.memoryHigh = *requestMemoryMin
+				}
+			}
+		}
+		// memory.swap.max: if container's limit not set, set memory.swap.max with node memory for qos
+		if podCfg.MemoryQoS.SwapLimitPercent != nil {
+			if memLimit > 0 {
+				summary.memorySwapMax = pointer.Int64Ptr(memLimit * (*podCfg.MemoryQoS.SwapLimitPercent) / 100)
+			} else {
+				nodeLimit := m.getNodeMemoryForQoS(node)
+				summary.memorySwapMax = pointer.Int64Ptr(nodeLimit * (*podCfg.MemoryQoS.SwapLimitPercent) / 100)
+			}
 		}
 		// values improved: memory.low is no less than memory.min
 		if summary.memoryMin != nil && summary.memoryLow != nil && *summary.memoryLow > 0 &&
@@ -339,14 +843,25 @@ func (m *CgroupResourcesReconcile) getMergedPodResourceQoS(pod *corev1.Pod, cfg
 }
 
 // mergePodResourceQoSForMemoryQoS merges pod-level memory qos config with node-level resource qos config
-// config overwrite: pod-level config > pod policy template > node-level config
+// config overwrite: pod-level wmark_min_adj > pod-level config > pod policy template > node-level config
 func (m *CgroupResourcesReconcile) mergePodResourceQoSForMemoryQoS(pod *corev1.Pod, cfg *slov1alpha1.ResourceQoS) {
 	// get the pod-level config and determine if the pod is allowed
-	// TODO: support namespaced switch
 	if cfg.MemoryQoS == nil {
 		cfg.MemoryQoS = &slov1alpha1.MemoryQoSCfg{}
 	}
+	// disable memory qos for the tiny pods, where the knobs bring overhead for no benefit
+	if m.isPodMemoryBelowQoSThreshold(pod) {
+		klog.V(5).Infof("disable memory qos for pod %s since its memory is below %v bytes", util.GetPodKey(pod),
+			m.resmanager.config.MinPodMemoryForQoSBytes)
+		cfg.MemoryQoS.MemoryQoS = *util.NoneMemoryQoS()
+		cfg.MemoryQoS.Enable = pointer.BoolPtr(false)
+		return
+	}
 	policy := slov1alpha1.PodMemoryQoSPolicyDefault
+	// the namespace-level policy is the default of the pods in the namespace
+	if nsPolicy := m.resmanager.getNamespaceMemoryQoSPolicy(pod.Namespace); nsPolicy != "" {
+		policy = nsPolicy
+	}
 
 	// get pod-level config
 	podCfg, err := apiext.GetPodMemoryQoSConfig(pod)
@@ -354,8 +869,8 @@ func (m *CgroupResourcesReconcile) mergePodResourceQoSForMemoryQoS(pod *corev1.P
 		klog.Errorf("failed to parse memory qos config, pod %s, err: %s", util.GetPodKey(pod), err)
 		podCfg = nil
 	}
-	if podCfg != nil {
-		policy = podCfg.Policy // policy="" is equal to policy="default"
+	if podCfg != nil && podCfg.Policy != "" {
+		policy = podCfg.Policy // policy="" inherits the namespace-level policy, which is "default" if not set
 	}
 	klog.V(5).Infof("memory qos podPolicy=%s for pod %s", policy, util.GetPodKey(pod))
 
@@ -368,19 +883,71 @@ func (m *CgroupResourcesReconcile) mergePodResourceQoSForMemoryQoS(pod *corev1.P
 		cfg.MemoryQoS.MemoryQoS = getPodResourceQoSByQoSClass(pod, util.DefaultResourceQoSStrategy(), m.resmanager.config).MemoryQoS.MemoryQoS
 	}
 
-	// no need to merge config if pod-level config is nil
-	if podCfg == nil {
-		return
+	// detailed pod-level config is specified, merge with node-level config for the pod
+	if podCfg != nil {
+		merged, err := util.MergeCfg(&cfg.MemoryQoS.MemoryQoS, &podCfg.MemoryQoS) // node config has been deep-copied
+		if err != nil {
+			// not change memory qos config if merge error
+			klog.Errorf("failed to merge memory qos config with node config, pod %s, err: %s", util.GetPodKey(pod), err)
+		} else {
+			cfg.MemoryQoS.MemoryQoS = *merged.(*slov1alpha1.MemoryQoS)
+			klog.V(6).Infof("get merged memory qos %v", util.DumpJSON(cfg.MemoryQoS))
+		}
 	}
-	// otherwise detailed pod-level config is specified, merge with node-level config for the pod
-	merged, err := util.MergeCfg(&cfg.MemoryQoS.MemoryQoS, &podCfg.MemoryQoS) // node config has been deep-copied
-	if err != nil {
-		// not change memory qos config if merge error
-		klog.Errorf("failed to merge memory qos config with node config, pod %s, err: %s", util.GetPodKey(pod), err)
-		return
+
+	// wmark_min_adj overridden by the pod takes precedence over the other configs
+	wmarkMinAdj, err := apiext.GetPodMemoryWmarkMinAdj(pod)
+	if err != nil { // ignore the override when parse error
+		klog.Errorf("failed to parse memory wmark min adj, pod %s, err: %s", util.GetPodKey(pod), err)
+	} else if wmarkMinAdj != nil {
+		cfg.MemoryQoS.WmarkMinAdj = wmarkMinAdj
+	}
+}
+
+// getNodeMemoryForQoS returns the node memory bytes for the qos calculations, which is QoSNodeMemoryOverrideBytes if
+// positive, otherwise the node memory capacity or allocatable selected by QoSNodeMemorySource.
+func (m *CgroupResourcesReconcile) getNodeMemoryForQoS(node *corev1.Node) int64 {
+	cfg := m.resmanager.config
+	if cfg != nil && cfg.QoSNodeMemoryOverrideBytes > 0 {
+		return cfg.QoSNodeMemoryOverrideBytes
+	}
+	if cfg != nil && cfg.QoSNodeMemorySource == NodeMemorySourceCapacity && node.Status.Capacity != nil {
+		return node.Status.Capacity.Memory().Value()
 	}
-	cfg.MemoryQoS.MemoryQoS = *merged.(*slov1alpha1.MemoryQoS)
-	klog.V(6).Infof("get merged memory qos %v", util.DumpJSON(cfg.MemoryQoS))
+	return node.Status.Allocatable.Memory().Value()
+}
+
+// isPodMemoryBelowQoSThreshold returns whether the memory of the pod is below MinPodMemoryForQoSBytes.
+func (m *CgroupResourcesReconcile) isPodMemoryBelowQoSThreshold(pod *corev1.Pod) bool {
+	if m.resmanager.config == nil || m.resmanager.config.MinPodMemoryForQoSBytes <= 0 {
+		return false
+	}
+	return getPodMemoryForQoS(pod) < m.resmanager.config.MinPodMemoryForQoSBytes
+}
+
+// getPodMemoryForQoS returns the memory bytes of the pod to compare with MinPodMemoryForQoSBytes, which is the memory
+// limit if all containers are limited, otherwise the memory request.
+func getPodMemoryForQoS(pod *corev1.Pod) int64 {
+	if apiext.GetPodQoSClass(pod) == apiext.QoSBE {
+		if memLimit := util.GetPodBEMemoryByteLimit(pod); memLimit > 0 {
+			return memLimit
+		}
+		return util.GetPodBEMemoryByteRequestIgnoreUnlimited(pod)
+	}
+	memLimit := int64(0)
+	for i := range pod.Spec.Containers {
+		containerMemLimit := util.GetContainerMemoryByteLimit(&pod.Spec.Containers[i])
+		if containerMemLimit <= 0 {
+			memLimit = -1
+			break
+		}
+		memLimit += containerMemLimit
+	}
+	if memLimit > 0 {
+		return memLimit
+	}
+	podRequest := util.GetPodRequest(pod)
+	return podRequest.Memory().Value()
 }
 
 // updateCgroupSummaryForQoS updates qos cgroup summary by pod to summarize qos-level cgroup according to belonging pods
@@ -453,8 +1020,18 @@ func completeCgroupSummaryForQoS(qosSummary map[corev1.PodQOSClass]*cgroupResour
 }
 
 func makeCgroupResources(owner *OwnerRef, parentDir string, summary *cgroupResourceSummary) []MergeableResourceUpdater {
+	if summary == nil {
+		return nil
+	}
 	var resources []MergeableResourceUpdater
 
+	// memory.swap.max is only available when the swap accounting is enabled
+	if v := summary.memorySwapMax; v != nil && system.IsMemorySwapAccountingEnabled(parentDir) &&
+		system.ValidateCgroupValue(v, parentDir, system.MemSwapMax) {
+		valueStr := strconv.FormatInt(*v, 10)
+		resources = append(resources, NewCommonCgroupResourceUpdater(owner, parentDir, system.MemSwapMax, valueStr))
+	}
+
 	anolisResources := makeCgroupResourcesForAnolis(owner, parentDir, summary)
 	if len(anolisResources) > 0 {
 		resources = append(resources, anolisResources...)
@@ -536,6 +1113,8 @@ func getKubeQoSResourceQoSByQoSClass(qosClass corev1.PodQOSClass, strategy *slov
 	return resourceQoS
 }
 
+// getPodResourceQoSByQoSClass gets pod config by the koordinator qos resolved by apiext.GetPodQoSClass.
+// The CgroupRoot config only applies to the root cgroup, so it is never returned for a pod.
 func getPodResourceQoSByQoSClass(pod *corev1.Pod, strategy *slov1alpha1.ResourceQoSStrategy, config *Config) *slov1alpha1.ResourceQoS {
 	if strategy == nil {
 		return nil
@@ -549,6 +1128,13 @@ func getPodResourceQoSByQoSClass(pod *corev1.Pod, strategy *slov1alpha1.Resource
 		resourceQoS = strategy.LS
 	case apiext.QoSBE:
 		resourceQoS = strategy.BE
+	case apiext.QoSSystem:
+		if strategy.System != nil {
+			resourceQoS = strategy.System
+			break
+		}
+		// qos=System pods without the system config uses config mapped from kubeQoS
+		fallthrough
 	default:
 		// qos=None pods uses config mapped from kubeQoS
 		resourceQoS = getKubeQoSResourceQoSByQoSClass(util.GetKubeQosClass(pod), strategy, config)
@@ -557,3 +1143,51 @@ func getPodResourceQoSByQoSClass(pod *corev1.Pod, strategy *slov1alpha1.Resource
 	}
 	return resourceQoS
 }
+
+type cgroupResourcesReconcileRef struct {
+	lock      sync.RWMutex
+	reconcile *CgroupResourcesReconcile
+}
+
+func (r *cgroupResourcesReconcileRef) set(reconcile *CgroupResourcesReconcile) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reconcile = reconcile
+}
+
+func (r *cgroupResourcesReconcileRef) get() *CgroupResourcesReconcile {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.reconcile
+}
+
+// ReconcilePodHttpHandler returns the http handler to force-reconcile the cgroup resources of the pod specified by
+// the query parameter "uid", e.g. `POST /debug/reconcilepod?uid=xxx`.
+func ReconcilePodHttpHandler() func(http.ResponseWriter, *http.Request) {
+	return defaultCgroupResourcesReconcile.httpHandler()
+}
+
+func (r *cgroupResourcesReconcileRef) httpHandler() func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		reconcile := r.get()
+		if reconcile == nil {
+			http.Error(rw, "cgroup reconcile is not running", http.StatusServiceUnavailable)
+			return
+		}
+		podUID := req.URL.Query().Get("uid")
+		if podUID == "" {
+			http.Error(rw, "pod uid is required", http.StatusBadRequest)
+			return
+		}
+		if err := reconcile.ReconcilePod(podUID); err != nil {
+			http.Error(rw, fmt.Sprintf("failed to reconcile pod, err: %v", err), http.StatusBadRequest)
+			return
+		}
+		klog.Infof("pod %s is force reconciled by client %v", podUID, req.RemoteAddr)
+		rw.WriteHeader(http.StatusOK)
+	}
+}
@@ -20,19 +20,32 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	testingclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -257,6 +270,8 @@ func Test_calculateAndUpdateResources(t *testing.T) {
 	}
 	testingNonRunningPod := createPod(corev1.PodQOSBestEffort, apiext.QoSBE)
 	testingNonRunningPod.Pod.Status.Phase = corev1.PodSucceeded
+	testingMirrorPod := createPod(corev1.PodQOSBestEffort, apiext.QoSBE)
+	testingMirrorPod.Pod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "mirror"}
 	type args struct {
 		name        string
 		qosStrategy *slov1alpha1.ResourceQoSStrategy
@@ -407,6 +422,14 @@ func Test_calculateAndUpdateResources(t *testing.T) {
 			},
 			expect: defaultQoSStrategy(),
 		},
+		{
+			name:        "ignore mirror pod",
+			qosStrategy: testingQoSStrategyBE,
+			podMetas: []*statesinformer.PodMeta{
+				testingMirrorPod,
+			},
+			expect: defaultQoSStrategy(),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -417,6 +440,8 @@ func Test_calculateAndUpdateResources(t *testing.T) {
 			resmgr := &resmanager{config: &Config{ReconcileIntervalSeconds: 1}, statesInformer: statesinformer}
 			statesinformer.EXPECT().GetNode().Return(testingNode).MaxTimes(1)
 			statesinformer.EXPECT().GetAllPods().Return(tt.podMetas).MaxTimes(1)
+			statesinformer.EXPECT().AddPodEventHandler(gomock.Any()).MaxTimes(1)
+			statesinformer.EXPECT().AddNodeEventHandler(gomock.Any()).MaxTimes(1)
 
 			reconciler := NewCgroupResourcesReconcile(resmgr)
 			stop := make(chan struct{})
@@ -437,6 +462,414 @@ func Test_calculateAndUpdateResources(t *testing.T) {
 	}
 }
 
+func TestCgroupResourcesReconcile_reconcileOnPodAdded(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+	testingStrategy := &slov1alpha1.ResourceQoSStrategy{
+		LS: &slov1alpha1.ResourceQoS{
+			MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+				Enable: pointer.BoolPtr(true),
+				MemoryQoS: slov1alpha1.MemoryQoS{
+					SwapLimitPercent: pointer.Int64Ptr(50),
+				},
+			},
+		},
+	}
+	testingPod := createPod(corev1.PodQOSBurstable, apiext.QoSLS)
+	testingPod.Pod.Status.Phase = corev1.PodRunning
+	testingPod.Pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("512Mi"),
+	}
+	containerDir, _ := util.GetContainerCgroupPathWithKube(testingPod.CgroupDir, &testingPod.Pod.Status.ContainerStatuses[0])
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+	system.HostSystemInfo.IsAnolisOS = false
+	defer func() {
+		system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+	}()
+	helper.WriteCgroupFileContents(containerDir, system.MemSwapMax, "max")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	si := mockstatesinformer.NewMockStatesInformer(ctrl)
+	var handler statesinformer.PodEventHandler
+	si.EXPECT().AddPodEventHandler(gomock.Any()).Do(func(h statesinformer.PodEventHandler) {
+		handler = h
+	}).Times(1)
+	si.EXPECT().GetNode().Return(testingNode).AnyTimes()
+	si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{testingPod}).AnyTimes()
+	resmgr := &resmanager{
+		config:         &Config{ReconcileIntervalSeconds: 3600},
+		statesInformer: si,
+		nodeSLO:        createNodeSLOWithQoSStrategy(testingStrategy),
+	}
+
+	reconciler := NewCgroupResourcesReconcile(resmgr)
+	stop := make(chan struct{})
+	reconciler.executor.Run(stop)
+	reconciler.registerPodEventHandler()
+	assert.NotNil(t, handler)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reconciler.runPodWorker(stop)
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	// the periodic reconciliation is not started, so the pod is reconciled only by the event
+	handler.OnPodAdded(testingPod)
+	assert.Eventually(t, func() bool {
+		return helper.ReadCgroupFileContents(containerDir, system.MemSwapMax) == strconv.FormatInt(256*1024*1024, 10)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCgroupResourcesReconcile_reconcileOnNodeUpdated(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+	testingStrategy := &slov1alpha1.ResourceQoSStrategy{
+		LS: &slov1alpha1.ResourceQoS{
+			MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+				Enable: pointer.BoolPtr(true),
+				MemoryQoS: slov1alpha1.MemoryQoS{
+					SwapLimitPercent: pointer.Int64Ptr(50),
+				},
+			},
+		},
+	}
+	// the container has no memory limit, so its memory.swap.max is derived from the node allocatable
+	testingPod := createPod(corev1.PodQOSBurstable, apiext.QoSLS)
+	testingPod.Pod.Status.Phase = corev1.PodRunning
+	testingPod.Pod.Spec.Containers[0].Resources = corev1.ResourceRequirements{}
+	containerDir, _ := util.GetContainerCgroupPathWithKube(testingPod.CgroupDir, &testingPod.Pod.Status.ContainerStatuses[0])
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+	system.HostSystemInfo.IsAnolisOS = false
+	defer func() {
+		system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+	}()
+	helper.WriteCgroupFileContents(containerDir, system.MemSwapMax, "max")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	si := mockstatesinformer.NewMockStatesInformer(ctrl)
+	var handler statesinformer.NodeEventHandler
+	si.EXPECT().AddNodeEventHandler(gomock.Any()).Do(func(h statesinformer.NodeEventHandler) {
+		handler = h
+	}).Times(1)
+	var nodeLock sync.Mutex
+	currentNode := testingNode
+	si.EXPECT().GetNode().DoAndReturn(func() *corev1.Node {
+		nodeLock.Lock()
+		defer nodeLock.Unlock()
+		return currentNode
+	}).AnyTimes()
+	si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{testingPod}).AnyTimes()
+	resmgr := &resmanager{
+		config:         &Config{ReconcileIntervalSeconds: 3600},
+		statesInformer: si,
+		nodeSLO:        createNodeSLOWithQoSStrategy(testingStrategy),
+	}
+
+	reconciler := NewCgroupResourcesReconcile(resmgr)
+	stop := make(chan struct{})
+	reconciler.executor.Run(stop)
+	reconciler.registerNodeEventHandler()
+	assert.NotNil(t, handler)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reconciler.runNodeWorker(stop)
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	reconciler.reconcile()
+	assert.Equal(t, strconv.FormatInt(512*1024*1024, 10), helper.ReadCgroupFileContents(containerDir, system.MemSwapMax))
+
+	// the node allocatable changes, and the periodic reconciliation is not started
+	newNode := testingNode.DeepCopy()
+	newNode.Status.Allocatable[corev1.ResourceMemory] = resource.MustParse("2Gi")
+	nodeLock.Lock()
+	currentNode = newNode
+	nodeLock.Unlock()
+	// the node worker waits for the running reconciliation
+	reconciler.reconcileLock.Lock()
+	handler.OnNodeUpdated(testingNode, newNode)
+	assert.Never(t, func() bool {
+		return helper.ReadCgroupFileContents(containerDir, system.MemSwapMax) == strconv.FormatInt(1024*1024*1024, 10)
+	}, 200*time.Millisecond, 10*time.Millisecond)
+	reconciler.reconcileLock.Unlock()
+	assert.Eventually(t, func() bool {
+		return helper.ReadCgroupFileContents(containerDir, system.MemSwapMax) == strconv.FormatInt(1024*1024*1024, 10)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_isNodeResourcesChanged(t *testing.T) {
+	testingNode := getNode("8", "32Gi")
+	withLabels := testingNode.DeepCopy()
+	withLabels.Labels = map[string]string{"test": "true"}
+	allocatableChanged := testingNode.DeepCopy()
+	allocatableChanged.Status.Allocatable[corev1.ResourceMemory] = resource.MustParse("30Gi")
+	capacityChanged := testingNode.DeepCopy()
+	capacityChanged.Status.Capacity[corev1.ResourceCPU] = resource.MustParse("16")
+	sameQuantity := testingNode.DeepCopy()
+	sameQuantity.Status.Allocatable[corev1.ResourceCPU] = resource.MustParse("8000m")
+
+	assert.False(t, isNodeResourcesChanged(testingNode, withLabels))
+	assert.False(t, isNodeResourcesChanged(testingNode, sameQuantity))
+	assert.True(t, isNodeResourcesChanged(testingNode, allocatableChanged))
+	assert.True(t, isNodeResourcesChanged(testingNode, capacityChanged))
+	assert.True(t, isNodeResourcesChanged(nil, testingNode))
+}
+
+func TestCgroupResourcesReconcile_ReconcilePod(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+	testingStrategy := &slov1alpha1.ResourceQoSStrategy{
+		LS: &slov1alpha1.ResourceQoS{
+			MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+				Enable: pointer.BoolPtr(true),
+				MemoryQoS: slov1alpha1.MemoryQoS{
+					SwapLimitPercent: pointer.Int64Ptr(50),
+				},
+			},
+		},
+	}
+	testingPod := createPod(corev1.PodQOSBurstable, apiext.QoSLS)
+	testingPod.Pod.Status.Phase = corev1.PodRunning
+	testingPod.Pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("512Mi"),
+	}
+	containerDir, _ := util.GetContainerCgroupPathWithKube(testingPod.CgroupDir, &testingPod.Pod.Status.ContainerStatuses[0])
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+	system.HostSystemInfo.IsAnolisOS = false
+	defer func() {
+		system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+	}()
+	helper.WriteCgroupFileContents(containerDir, system.MemSwapMax, "max")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	si := mockstatesinformer.NewMockStatesInformer(ctrl)
+	si.EXPECT().GetNode().Return(testingNode).AnyTimes()
+	si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{testingPod}).AnyTimes()
+	resmgr := &resmanager{
+		config:         &Config{ReconcileIntervalSeconds: 3600},
+		statesInformer: si,
+		nodeSLO:        createNodeSLOWithQoSStrategy(testingStrategy),
+	}
+	reconciler := NewCgroupResourcesReconcile(resmgr)
+	stop := make(chan struct{})
+	defer close(stop)
+	reconciler.executor.Run(stop)
+
+	wantSwapMax := strconv.FormatInt(256*1024*1024, 10)
+	assert.NoError(t, reconciler.ReconcilePod(string(testingPod.Pod.UID)))
+	assert.Equal(t, wantSwapMax, helper.ReadCgroupFileContents(containerDir, system.MemSwapMax))
+
+	// the cgroup value modified externally is not rewritten by the cached update, but by the forced one
+	assert.NoError(t, reconciler.reconcilePod(string(testingPod.Pod.UID), false))
+	helper.WriteCgroupFileContents(containerDir, system.MemSwapMax, "max")
+	assert.NoError(t, reconciler.reconcilePod(string(testingPod.Pod.UID), false))
+	assert.Equal(t, "max", helper.ReadCgroupFileContents(containerDir, system.MemSwapMax))
+	assert.NoError(t, reconciler.ReconcilePod(string(testingPod.Pod.UID)))
+	assert.Equal(t, wantSwapMax, helper.ReadCgroupFileContents(containerDir, system.MemSwapMax))
+
+	err := reconciler.ReconcilePod("unknown-uid")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown-uid is not found")
+}
+
+func TestCgroupResourcesReconcile_ReconcilePod_wmarkMinAdjOverride(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+	testingStrategy := &slov1alpha1.ResourceQoSStrategy{
+		LS: &slov1alpha1.ResourceQoS{
+			MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+				Enable: pointer.BoolPtr(true),
+				MemoryQoS: slov1alpha1.MemoryQoS{
+					WmarkMinAdj: pointer.Int64Ptr(-25),
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		wantWmarkMinAdj string
+	}{
+		{
+			name:            "use the class value if not overridden",
+			wantWmarkMinAdj: "-25",
+		},
+		{
+			name:            "use the value overridden by the pod",
+			annotations:     map[string]string{apiext.AnnotationPodMemoryWmarkMinAdj: "10"},
+			wantWmarkMinAdj: "10",
+		},
+		{
+			name:            "fall back to the class value if the override is out of range",
+			annotations:     map[string]string{apiext.AnnotationPodMemoryWmarkMinAdj: "60"},
+			wantWmarkMinAdj: "-25",
+		},
+		{
+			name:            "fall back to the class value if the override is invalid",
+			annotations:     map[string]string{apiext.AnnotationPodMemoryWmarkMinAdj: "low"},
+			wantWmarkMinAdj: "-25",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testingPod := createPod(corev1.PodQOSBurstable, apiext.QoSLS)
+			testingPod.Pod.Annotations = tt.annotations
+			podDir := util.GetPodCgroupDirWithKube(testingPod.CgroupDir)
+			containerDir, _ := util.GetContainerCgroupPathWithKube(testingPod.CgroupDir, &testingPod.Pod.Status.ContainerStatuses[0])
+
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+			system.HostSystemInfo.IsAnolisOS = true
+			defer func() {
+				system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+			}()
+			helper.WriteCgroupFileContents(podDir, system.MemWmarkMinAdj, "0")
+			helper.WriteCgroupFileContents(containerDir, system.MemWmarkMinAdj, "0")
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			si := mockstatesinformer.NewMockStatesInformer(ctrl)
+			si.EXPECT().GetNode().Return(testingNode).AnyTimes()
+			si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{testingPod}).AnyTimes()
+			resmgr := &resmanager{
+				config:         &Config{ReconcileIntervalSeconds: 3600},
+				statesInformer: si,
+				nodeSLO:        createNodeSLOWithQoSStrategy(testingStrategy),
+			}
+			reconciler := NewCgroupResourcesReconcile(resmgr)
+			stop := make(chan struct{})
+			defer close(stop)
+			reconciler.executor.Run(stop)
+
+			assert.NoError(t, reconciler.ReconcilePod(string(testingPod.Pod.UID)))
+			assert.Equal(t, tt.wantWmarkMinAdj, helper.ReadCgroupFileContents(podDir, system.MemWmarkMinAdj))
+			assert.Equal(t, tt.wantWmarkMinAdj, helper.ReadCgroupFileContents(containerDir, system.MemWmarkMinAdj))
+		})
+	}
+}
+
+func Test_cgroupResourcesReconcileRef_httpHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	si := mockstatesinformer.NewMockStatesInformer(ctrl)
+	si.EXPECT().GetNode().Return(getNode("8", "16Gi")).AnyTimes()
+	si.EXPECT().GetAllPods().Return(nil).AnyTimes()
+	resmgr := &resmanager{
+		config:         NewDefaultConfig(),
+		statesInformer: si,
+		nodeSLO:        createNodeSLOWithQoSStrategy(util.DefaultResourceQoSStrategy()),
+	}
+
+	ref := &cgroupResourcesReconcileRef{}
+	handler := ref.httpHandler()
+	tests := []struct {
+		name     string
+		running  bool
+		method   string
+		target   string
+		wantCode int
+	}{
+		{
+			name:     "method not allowed",
+			running:  true,
+			method:   http.MethodGet,
+			target:   "/debug/reconcilepod?uid=xxx",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "cgroup reconcile not running",
+			method:   http.MethodPost,
+			target:   "/debug/reconcilepod?uid=xxx",
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "missing uid",
+			running:  true,
+			method:   http.MethodPost,
+			target:   "/debug/reconcilepod",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown pod",
+			running:  true,
+			method:   http.MethodPost,
+			target:   "/debug/reconcilepod?uid=xxx",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.running {
+				ref.set(NewCgroupResourcesReconcile(resmgr))
+			} else {
+				ref.set(nil)
+			}
+			rw := httptest.NewRecorder()
+			handler(rw, httptest.NewRequest(tt.method, tt.target, nil))
+			assert.Equal(t, tt.wantCode, rw.Code)
+		})
+	}
+}
+
 func TestCgroupResourceReconcile_calculateResources(t *testing.T) {
 	testingPodLS := createPod(corev1.PodQOSBurstable, apiext.QoSLS)
 	podParentDirLS := util.GetPodCgroupDirWithKube(testingPodLS.CgroupDir)
@@ -816,6 +1249,191 @@ func TestCgroupResourceReconcile_calculateResources(t *testing.T) {
 	}
 }
 
+func TestCgroupResourceReconcile_calculateResources_scaleMemoryMin(t *testing.T) {
+	oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+	system.HostSystemInfo.IsAnolisOS = true
+	defer func() {
+		system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+	}()
+
+	createPodWithMemRequest := func(kubeQoS corev1.PodQOSClass, qos apiext.QoSClass, name string, memRequest string) *statesinformer.PodMeta {
+		podMeta := createPod(kubeQoS, qos)
+		podMeta.Pod.Name = name
+		podMeta.Pod.UID = types.UID(name)
+		podMeta.Pod.Spec.Containers[1].Resources.Requests[corev1.ResourceMemory] = resource.MustParse(memRequest)
+		podMeta.Pod.Spec.Containers[1].Resources.Limits[corev1.ResourceMemory] = resource.MustParse(memRequest)
+		podMeta.CgroupDir = util.GetPodKubeRelativePath(podMeta.Pod)
+		return podMeta
+	}
+	podMetas := []*statesinformer.PodMeta{
+		createPodWithMemRequest(corev1.PodQOSGuaranteed, apiext.QoSLSR, "test_lsr_pod", "2Gi"),
+		createPodWithMemRequest(corev1.PodQOSBurstable, apiext.QoSLS, "test_ls_pod", "4Gi"),
+		createPodWithMemRequest(corev1.PodQOSBurstable, apiext.QoSLS, "test_ls_pod_1", "6Gi"),
+	}
+	memoryQoSWithMin := &slov1alpha1.ResourceQoS{
+		MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+			Enable: pointer.BoolPtr(true),
+			MemoryQoS: slov1alpha1.MemoryQoS{
+				MinLimitPercent: pointer.Int64Ptr(100),
+			},
+		},
+	}
+	nodeCfg := &slov1alpha1.ResourceQoSStrategy{
+		LSR: memoryQoSWithMin,
+		LS:  memoryQoSWithMin,
+		BE:  &slov1alpha1.ResourceQoS{},
+	}
+	node := getNode("16", "8Gi")
+	gib := int64(1024 * 1024 * 1024)
+	metrics.Register(node)
+	defer metrics.Register(nil)
+
+	tests := []struct {
+		name                   string
+		allocatablePercent     int
+		wantQoSMemoryMin       map[string]int64
+		wantPodMemoryMin       map[string]int64
+		wantContainerMemoryMin map[string]int64
+	}{
+		{
+			name:               "not scale when the check is disabled",
+			allocatablePercent: 0,
+			wantQoSMemoryMin: map[string]int64{
+				string(corev1.PodQOSGuaranteed): 12 * gib,
+				string(corev1.PodQOSBurstable):  10 * gib,
+			},
+			wantPodMemoryMin: map[string]int64{
+				"test_lsr_pod":  2 * gib,
+				"test_ls_pod":   4 * gib,
+				"test_ls_pod_1": 6 * gib,
+			},
+			wantContainerMemoryMin: map[string]int64{
+				"test_lsr_pod":  2 * gib,
+				"test_ls_pod":   4 * gib,
+				"test_ls_pod_1": 6 * gib,
+			},
+		},
+		{
+			name:               "not scale when memory.min does not exceed allocatable",
+			allocatablePercent: 200,
+			wantQoSMemoryMin: map[string]int64{
+				string(corev1.PodQOSGuaranteed): 12 * gib,
+				string(corev1.PodQOSBurstable):  10 * gib,
+			},
+			wantPodMemoryMin: map[string]int64{
+				"test_lsr_pod":  2 * gib,
+				"test_ls_pod":   4 * gib,
+				"test_ls_pod_1": 6 * gib,
+			},
+			wantContainerMemoryMin: map[string]int64{
+				"test_lsr_pod":  2 * gib,
+				"test_ls_pod":   4 * gib,
+				"test_ls_pod_1": 6 * gib,
+			},
+		},
+		{
+			name:               "scale down proportionally when memory.min exceeds allocatable",
+			allocatablePercent: 75,
+			wantQoSMemoryMin: map[string]int64{
+				string(corev1.PodQOSGuaranteed): 6 * gib,
+				string(corev1.PodQOSBurstable):  5 * gib,
+			},
+			wantPodMemoryMin: map[string]int64{
+				"test_lsr_pod":  1 * gib,
+				"test_ls_pod":   2 * gib,
+				"test_ls_pod_1": 3 * gib,
+			},
+			wantContainerMemoryMin: map[string]int64{
+				"test_lsr_pod":  1 * gib,
+				"test_ls_pod":   2 * gib,
+				"test_ls_pod_1": 3 * gib,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+
+			cfg := NewDefaultConfig()
+			cfg.MemoryMinAllocatablePercent = tt.allocatablePercent
+			m := NewCgroupResourcesReconcile(&resmanager{config: cfg})
+
+			qosResources, podResources, containerResources := m.calculateResources(nodeCfg, node, podMetas)
+			getMemoryMins := func(resources []MergeableResourceUpdater, withContainer bool) map[string]int64 {
+				memoryMins := map[string]int64{}
+				for _, r := range resources {
+					cgroupResource := r.(*CgroupResourceUpdater)
+					if cgroupResource.file != system.MemMin {
+						continue
+					}
+					// ignore the container without memory request
+					if withContainer && cgroupResource.owner.Container != "main" {
+						continue
+					}
+					v, err := strconv.ParseInt(cgroupResource.value, 10, 64)
+					assert.NoError(t, err)
+					owner := cgroupResource.owner.Name
+					memoryMins[owner] = v
+				}
+				return memoryMins
+			}
+			gotQoSMemoryMin := getMemoryMins(qosResources, false)
+			delete(gotQoSMemoryMin, string(corev1.PodQOSBestEffort))
+			assert.Equal(t, tt.wantQoSMemoryMin, gotQoSMemoryMin)
+			assert.Equal(t, tt.wantPodMemoryMin, getMemoryMins(podResources, false))
+			assert.Equal(t, tt.wantContainerMemoryMin, getMemoryMins(containerResources, true))
+			// the gauge reports the total memory.min applied
+			assert.Equal(t, float64(tt.wantQoSMemoryMin[string(corev1.PodQOSGuaranteed)]),
+				testutil.ToFloat64(metrics.AppliedMemoryMin.WithLabelValues(node.Name)))
+		})
+	}
+}
+
+func TestCgroupResourceReconcile_calculateResources_skipTerminatingPod(t *testing.T) {
+	oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+	system.HostSystemInfo.IsAnolisOS = true
+	defer func() {
+		system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+	}()
+
+	createBEPod := func(name string) *statesinformer.PodMeta {
+		podMeta := createPod(corev1.PodQOSBestEffort, apiext.QoSBE)
+		podMeta.Pod.Name = name
+		podMeta.Pod.UID = types.UID(name)
+		podMeta.CgroupDir = util.GetPodKubeRelativePath(podMeta.Pod)
+		return podMeta
+	}
+	runningPodMeta := createBEPod("test_be_pod")
+	terminatingPodMeta := createBEPod("test_terminating_be_pod")
+	terminatingPodMeta.Pod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	nodeCfg := &slov1alpha1.ResourceQoSStrategy{
+		BE: &slov1alpha1.ResourceQoS{
+			MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+				Enable: pointer.BoolPtr(true),
+				MemoryQoS: slov1alpha1.MemoryQoS{
+					WmarkRatio:     pointer.Int64Ptr(95),
+					PriorityEnable: pointer.Int64Ptr(1),
+					Priority:       pointer.Int64Ptr(0),
+				},
+			},
+		},
+	}
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	m := NewCgroupResourcesReconcile(&resmanager{config: NewDefaultConfig()})
+	_, podResources, containerResources := m.calculateResources(nodeCfg, getNode("16", "32G"),
+		[]*statesinformer.PodMeta{runningPodMeta, terminatingPodMeta})
+
+	gotOwners := map[string]bool{}
+	for _, r := range append(podResources, containerResources...) {
+		gotOwners[r.(*CgroupResourceUpdater).owner.Name] = true
+	}
+	assert.True(t, gotOwners[runningPodMeta.Pod.Name], "the running pod should be reconciled")
+	assert.False(t, gotOwners[terminatingPodMeta.Pod.Name], "the terminating pod should not be reconciled")
+}
+
 func TestCgroupResourcesReconcile_getMergedPodResourceQoS(t *testing.T) {
 	testingNodeNoneResourceQoS := util.NoneResourceQoSStrategy().BE
 	testingMemoryQoSEnableResourceQoS := util.DefaultResourceQoSStrategy().BE // qos enable
@@ -1075,6 +1693,500 @@ func Test_makeCgroupResources(t *testing.T) {
 	}
 }
 
+func TestCgroupResourcesReconcile_calculateContainerResources_swapLimit(t *testing.T) {
+	testingContainer := &corev1.Container{
+		Name: "test",
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+	testingPod := createPod(corev1.PodQOSBurstable, apiext.QoSLS).Pod
+	testingNode := getNode("80", "120Gi")
+	containerDir := "pod0/container0"
+	tests := []struct {
+		name          string
+		swapAvailable bool
+		podCfg        *slov1alpha1.ResourceQoS
+		want          []MergeableResourceUpdater
+	}{
+		{
+			name:          "skip memory.swap.max when swap limit is not set",
+			swapAvailable: true,
+			podCfg: &slov1alpha1.ResourceQoS{
+				MemoryQoS: &slov1alpha1.MemoryQoSCfg{},
+			},
+			want: nil,
+		},
+		{
+			name:          "calculate memory.swap.max with container memory limit",
+			swapAvailable: true,
+			podCfg: &slov1alpha1.ResourceQoS{
+				MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+					MemoryQoS: slov1alpha1.MemoryQoS{
+						SwapLimitPercent: pointer.Int64Ptr(50),
+					},
+				},
+			},
+			want: []MergeableResourceUpdater{
+				NewCommonCgroupResourceUpdater(ContainerOwnerRef(testingPod.Namespace, testingPod.Name, testingContainer.Name),
+					containerDir, system.MemSwapMax, strconv.FormatInt(512*1024*1024, 10)),
+			},
+		},
+		{
+			name:          "skip memory.swap.max when swap accounting is unavailable",
+			swapAvailable: false,
+			podCfg: &slov1alpha1.ResourceQoS{
+				MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+					MemoryQoS: slov1alpha1.MemoryQoS{
+						SwapLimitPercent: pointer.Int64Ptr(50),
+					},
+				},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+			system.HostSystemInfo.IsAnolisOS = false
+			defer func() {
+				system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+			}()
+			if tt.swapAvailable {
+				helper.WriteCgroupFileContents(containerDir, system.MemSwapMax, "max")
+			}
+
+			m := &CgroupResourcesReconcile{resmanager: &resmanager{config: NewDefaultConfig()}}
+			got := m.calculateContainerResources(testingContainer, testingPod, testingNode, containerDir, tt.podCfg, 1, 1)
+			assertCgroupResourceEqual(t, tt.want, got)
+		})
+	}
+}
+
+func TestCgroupResourcesReconcile_calculatePodAndContainerResources_ephemeralContainers(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+	system.HostSystemInfo.IsAnolisOS = true
+	defer func() {
+		system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+	}()
+
+	podMeta := createPod(corev1.PodQOSBurstable, apiext.QoSLS)
+	podMeta.CgroupDir = util.GetPodKubeRelativePath(podMeta.Pod)
+	pod := podMeta.Pod
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{
+		{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger"}},
+		{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "not-started-debugger"}},
+	}
+	pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name:        "debugger",
+			ContainerID: fmt.Sprintf("docker://%s", "debugger"),
+			State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		},
+	}
+	podCfg := &slov1alpha1.ResourceQoS{
+		MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+			Enable: pointer.BoolPtr(true),
+			MemoryQoS: slov1alpha1.MemoryQoS{
+				WmarkRatio: pointer.Int64Ptr(95),
+			},
+		},
+	}
+
+	m := NewCgroupResourcesReconcile(&resmanager{config: NewDefaultConfig()})
+	_, containerResources := m.calculatePodAndContainerResources(podMeta, getNode("80", "120Gi"), podCfg, 1, 1)
+	var gotContainers []string
+	for _, r := range containerResources {
+		cgroupResource := r.(*CgroupResourceUpdater)
+		if cgroupResource.file == system.MemWmarkRatio {
+			gotContainers = append(gotContainers, cgroupResource.owner.Container)
+		}
+	}
+	// the ephemeral container without status is skipped
+	assert.Equal(t, []string{"test", "main", "debugger"}, gotContainers)
+}
+
+func Test_calculateMemoryHighScaleRatio(t *testing.T) {
+	// the node memory usage climbs and then subsides
+	usageModel := []float64{50, 70, 75, 82.5, 90, 95, 99, 90, 82.5, 70, 50}
+	wantRatios := []float64{1, 1, 0.9, 0.75, 0.6, 0.5, 0.5, 0.6, 0.75, 1, 1}
+	for i, usagePercent := range usageModel {
+		got := calculateMemoryHighScaleRatio(usagePercent, 70, 95, 50)
+		assert.InDelta(t, wantRatios[i], got, 1e-6, "usage %v", usagePercent)
+		assert.True(t, got >= 0.5 && got <= 1, "ratio %v out of bounds", got)
+	}
+
+	// invalid bounds disable the scaling
+	assert.Equal(t, float64(1), calculateMemoryHighScaleRatio(99, 95, 70, 50))
+	assert.Equal(t, float64(0), calculateMemoryHighScaleRatio(99, 70, 95, -10))
+}
+
+func TestCgroupResourcesReconcile_getMemoryHighScaleRatio(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	testingNode := getNode("80", "100Gi")
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	cfg := NewDefaultConfig()
+	m := &CgroupResourcesReconcile{resmanager: &resmanager{config: cfg, metricCache: mockMetricCache}}
+
+	// disabled by default
+	assert.Equal(t, float64(1), m.getMemoryHighScaleRatio(testingNode))
+
+	cfg.MemoryHighDynamicScale = true
+	for _, tt := range []struct {
+		memoryUsed string
+		want       float64
+	}{
+		{memoryUsed: "60Gi", want: 1},
+		{memoryUsed: "90Gi", want: 0.6},
+		{memoryUsed: "98Gi", want: 0.5},
+		{memoryUsed: "60Gi", want: 1},
+	} {
+		mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+			Metric: &metriccache.NodeResourceMetric{
+				MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse(tt.memoryUsed)},
+			},
+		}).Times(1)
+		assert.InDelta(t, tt.want, m.getMemoryHighScaleRatio(testingNode), 1e-6, "memory used %v", tt.memoryUsed)
+	}
+}
+
+func TestCgroupResourcesReconcile_rampMemoryHighScaleRatio(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.SuppressReleaseStepPercent = 50
+	m := &CgroupResourcesReconcile{resmanager: &resmanager{config: cfg}}
+
+	// the ratio is lowered at once and raised by half of the gap in each cycle
+	targets := []float64{0.5, 1, 1, 1, 0.6, 1}
+	wants := []float64{0.5, 0.75, 0.875, 0.9375, 0.6, 0.8}
+	for i, target := range targets {
+		assert.InDelta(t, wants[i], m.rampMemoryHighScaleRatio(target), 1e-6, "cycle %v", i)
+		assert.InDelta(t, wants[i], m.getAppliedMemoryHighScaleRatio(nil), 1e-6, "cycle %v", i)
+	}
+	for i := 0; i < 10; i++ {
+		m.rampMemoryHighScaleRatio(1)
+	}
+	assert.Equal(t, float64(1), m.getAppliedMemoryHighScaleRatio(nil))
+
+	// released at once without the step
+	cfg.SuppressReleaseStepPercent = 0
+	m.rampMemoryHighScaleRatio(0.5)
+	assert.Equal(t, float64(1), m.rampMemoryHighScaleRatio(1))
+}
+
+func TestCgroupResourcesReconcile_calculateContainerResources_dynamicMemoryHigh(t *testing.T) {
+	testingNode := getNode("80", "120Gi")
+	containerDir := "pod0/container0"
+	podCfg := &slov1alpha1.ResourceQoS{
+		MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+			MemoryQoS: slov1alpha1.MemoryQoS{
+				MinLimitPercent:   pointer.Int64Ptr(40),
+				ThrottlingPercent: pointer.Int64Ptr(80),
+			},
+		},
+	}
+	tests := []struct {
+		name            string
+		qosClass        apiext.QoSClass
+		memoryMinRatio  float64
+		memoryHighRatio float64
+		wantMemoryHigh  int64
+	}{
+		{
+			name:            "keep memory.high of be container without pressure",
+			qosClass:        apiext.QoSBE,
+			memoryHighRatio: 1,
+			wantMemoryHigh:  testingPodMemRequestLimitBytes * 80 / 100,
+		},
+		{
+			name:            "lower memory.high of be container under pressure",
+			qosClass:        apiext.QoSBE,
+			memoryHighRatio: 0.75,
+			wantMemoryHigh:  testingPodMemRequestLimitBytes * 80 / 100 * 3 / 4,
+		},
+		{
+			name:            "memory.high of be container is bounded by memory.min",
+			qosClass:        apiext.QoSBE,
+			memoryHighRatio: 0.25,
+			wantMemoryHigh:  testingPodMemRequestLimitBytes * 40 / 100,
+		},
+		{
+			name:            "memory.high of be container is bounded by the memory.min derived from the request",
+			qosClass:        apiext.QoSBE,
+			memoryMinRatio:  0.5,
+			memoryHighRatio: 0.25,
+			wantMemoryHigh:  testingPodMemRequestLimitBytes * 40 / 100,
+		},
+		{
+			name:            "keep memory.high of ls container under pressure",
+			qosClass:        apiext.QoSLS,
+			memoryHighRatio: 0.75,
+			wantMemoryHigh:  testingPodMemRequestLimitBytes * 80 / 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+			system.HostSystemInfo.IsAnolisOS = true
+			defer func() {
+				system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+			}()
+
+			kubeQoS := corev1.PodQOSBurstable
+			if tt.qosClass == apiext.QoSBE {
+				kubeQoS = corev1.PodQOSBestEffort
+			}
+			testingPod := createPod(kubeQoS, tt.qosClass).Pod
+			m := &CgroupResourcesReconcile{resmanager: &resmanager{config: NewDefaultConfig()}}
+			memoryMinRatio := tt.memoryMinRatio
+			if memoryMinRatio == 0 {
+				memoryMinRatio = 1
+			}
+			got := m.calculateContainerResources(&testingPod.Spec.Containers[1], testingPod, testingNode, containerDir,
+				podCfg, memoryMinRatio, tt.memoryHighRatio)
+			var gotMemoryHigh string
+			for _, r := range got {
+				if updater, ok := r.(*CgroupResourceUpdater); ok && updater.file == system.MemHigh {
+					gotMemoryHigh = updater.value
+				}
+			}
+			assert.Equal(t, strconv.FormatInt(tt.wantMemoryHigh, 10), gotMemoryHigh)
+		})
+	}
+}
+
+func TestCgroupResourcesReconcile_calculateContainerResources_nodeMemorySource(t *testing.T) {
+	// the container has no memory limit, so memory.high and memory.swap.max are calculated with the node memory
+	testingContainer := &corev1.Container{Name: "test"}
+	testingPod := createPod(corev1.PodQOSBurstable, apiext.QoSLS).Pod
+	testingNode := getNode("80", "100Gi")
+	testingNode.Status.Capacity[corev1.ResourceMemory] = resource.MustParse("120Gi")
+	containerDir := "pod0/container0"
+	podCfg := &slov1alpha1.ResourceQoS{
+		MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+			MemoryQoS: slov1alpha1.MemoryQoS{
+				ThrottlingPercent: pointer.Int64Ptr(80),
+				SwapLimitPercent:  pointer.Int64Ptr(50),
+			},
+		},
+	}
+	tests := []struct {
+		name              string
+		source            string
+		overrideBytes     int64
+		wantMemoryHigh    int64
+		wantMemorySwapMax int64
+	}{
+		{
+			name:              "calculate with node allocatable by default",
+			source:            NodeMemorySourceAllocatable,
+			wantMemoryHigh:    100 * 1024 * 1024 * 1024 * 80 / 100,
+			wantMemorySwapMax: 100 * 1024 * 1024 * 1024 * 50 / 100,
+		},
+		{
+			name:              "calculate with node capacity",
+			source:            NodeMemorySourceCapacity,
+			wantMemoryHigh:    120 * 1024 * 1024 * 1024 * 80 / 100,
+			wantMemorySwapMax: 120 * 1024 * 1024 * 1024 * 50 / 100,
+		},
+		{
+			name:              "calculate with the explicit override",
+			source:            NodeMemorySourceCapacity,
+			overrideBytes:     64 * 1024 * 1024 * 1024,
+			wantMemoryHigh:    64 * 1024 * 1024 * 1024 * 80 / 100,
+			wantMemorySwapMax: 64 * 1024 * 1024 * 1024 * 50 / 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+			system.HostSystemInfo.IsAnolisOS = true
+			defer func() {
+				system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+			}()
+			helper.WriteCgroupFileContents(containerDir, system.MemSwapMax, "max")
+
+			cfg := NewDefaultConfig()
+			cfg.QoSNodeMemorySource = tt.source
+			cfg.QoSNodeMemoryOverrideBytes = tt.overrideBytes
+			m := &CgroupResourcesReconcile{resmanager: &resmanager{config: cfg}}
+			got := m.calculateContainerResources(testingContainer, testingPod, testingNode, containerDir, podCfg, 1, 1)
+			var gotMemoryHigh, gotMemorySwapMax string
+			for _, r := range got {
+				if updater, ok := r.(*CgroupResourceUpdater); ok {
+					switch updater.file {
+					case system.MemHigh:
+						gotMemoryHigh = updater.value
+					case system.MemSwapMax:
+						gotMemorySwapMax = updater.value
+					}
+				}
+			}
+			assert.Equal(t, strconv.FormatInt(tt.wantMemoryHigh, 10), gotMemoryHigh)
+			assert.Equal(t, strconv.FormatInt(tt.wantMemorySwapMax, 10), gotMemorySwapMax)
+		})
+	}
+}
+
+func TestCgroupResourcesReconcile_calculateContainerResources_enforcementGrace(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+	system.HostSystemInfo.IsAnolisOS = true
+	defer func() {
+		system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+	}()
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	testingContainer := &corev1.Container{
+		Name: "test",
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("10Gi")},
+		},
+	}
+	testingPod := createPod(corev1.PodQOSBurstable, apiext.QoSLS).Pod
+	testingPod.Status.StartTime = &metav1.Time{Time: fakeClock.Now()}
+	testingNode := getNode("80", "100Gi")
+	podCfg := &slov1alpha1.ResourceQoS{
+		MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+			MemoryQoS: slov1alpha1.MemoryQoS{
+				ThrottlingPercent: pointer.Int64Ptr(80),
+			},
+		},
+	}
+	m := &CgroupResourcesReconcile{
+		resmanager: &resmanager{
+			config:           NewDefaultConfig(),
+			enforcementGrace: map[apiext.QoSClass]time.Duration{apiext.QoSLS: time.Minute},
+		},
+		clock: fakeClock,
+	}
+	getMemoryHigh := func() string {
+		got := m.calculateContainerResources(testingContainer, testingPod, testingNode, "pod0/container0", podCfg, 1, 1)
+		for _, r := range got {
+			if updater, ok := r.(*CgroupResourceUpdater); ok && updater.file == system.MemHigh {
+				return updater.value
+			}
+		}
+		return ""
+	}
+
+	// memory.high is relaxed during the grace
+	assert.Equal(t, strconv.FormatInt(math.MaxInt64, 10), getMemoryHigh())
+	fakeClock.Step(59 * time.Second)
+	assert.Equal(t, strconv.FormatInt(math.MaxInt64, 10), getMemoryHigh())
+
+	// memory.high is applied after the grace
+	fakeClock.Step(time.Second)
+	assert.Equal(t, strconv.FormatInt(10*1024*1024*1024*80/100, 10), getMemoryHigh())
+
+	// the pods of the other qos classes get no grace
+	bePod := createPod(corev1.PodQOSBestEffort, apiext.QoSBE).Pod
+	bePod.Status.StartTime = &metav1.Time{Time: fakeClock.Now()}
+	testingPod = bePod
+	assert.NotEqual(t, strconv.FormatInt(math.MaxInt64, 10), getMemoryHigh())
+}
+
+func TestCgroupResourcesReconcile_calculateAndUpdateRootResources(t *testing.T) {
+	testingNode := getNode("80", "120Gi")
+	rootFiles := []system.CgroupFile{system.MemWmarkRatio, system.MemWmarkScaleFactor, system.MemWmarkMinAdj,
+		system.MemUsePriorityOom, system.MemMin, system.MemLow, system.MemPriority, system.MemOomGroup}
+	tests := []struct {
+		name    string
+		rootCfg *slov1alpha1.ResourceQoS
+		want    map[string]string
+	}{
+		{
+			name:    "keep root cgroup if cgroupRoot is not set",
+			rootCfg: nil,
+			want:    map[string]string{},
+		},
+		{
+			name: "keep root cgroup if cgroupRoot memory qos is disabled",
+			rootCfg: &slov1alpha1.ResourceQoS{
+				MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+					Enable: pointer.BoolPtr(false),
+					MemoryQoS: slov1alpha1.MemoryQoS{
+						WmarkRatio: pointer.Int64Ptr(95),
+					},
+				},
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "update root cgroup and ignore the fields nonsensical at root",
+			rootCfg: &slov1alpha1.ResourceQoS{
+				MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+					Enable: pointer.BoolPtr(true),
+					MemoryQoS: slov1alpha1.MemoryQoS{
+						MinLimitPercent:   pointer.Int64Ptr(100),
+						LowLimitPercent:   pointer.Int64Ptr(100),
+						WmarkRatio:        pointer.Int64Ptr(95),
+						WmarkScalePermill: pointer.Int64Ptr(20),
+						WmarkMinAdj:       pointer.Int64Ptr(-25),
+						PriorityEnable:    pointer.Int64Ptr(1),
+						Priority:          pointer.Int64Ptr(12),
+						OomKillGroup:      pointer.Int64Ptr(1),
+					},
+				},
+			},
+			want: map[string]string{
+				system.MemWmarkRatioFileName:       "95",
+				system.MemWmarkScaleFactorFileName: "20",
+				system.MemWmarkMinAdjFileName:      "-25",
+				system.MemUsePriorityOomFileName:   "1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+			system.HostSystemInfo.IsAnolisOS = true
+			defer func() {
+				system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+			}()
+			for _, file := range rootFiles {
+				helper.WriteCgroupFileContents("", file, "0")
+			}
+
+			statesInformer := mockstatesinformer.NewMockStatesInformer(ctrl)
+			statesInformer.EXPECT().GetNode().Return(testingNode).MaxTimes(1)
+			statesInformer.EXPECT().GetAllPods().Return(nil).MaxTimes(1)
+			resmgr := &resmanager{config: NewDefaultConfig(), statesInformer: statesInformer}
+			reconciler := NewCgroupResourcesReconcile(resmgr)
+			stop := make(chan struct{})
+			defer close(stop)
+			reconciler.executor.Run(stop)
+
+			reconciler.calculateAndUpdateResources(createNodeSLOWithQoSStrategy(&slov1alpha1.ResourceQoSStrategy{
+				CgroupRoot: tt.rootCfg,
+			}))
+			for _, file := range rootFiles {
+				want, ok := tt.want[file.ResourceFileName]
+				if !ok {
+					want = "0"
+				}
+				assert.Equal(t, want, helper.ReadCgroupFileContents("", file), file.ResourceFileName)
+			}
+		})
+	}
+}
+
 func Test_getPodResourceQoSByQoSClass(t *testing.T) {
 	type args struct {
 		pod      *corev1.Pod
@@ -1118,6 +2230,29 @@ func Test_getPodResourceQoSByQoSClass(t *testing.T) {
 			},
 			want: defaultQoSStrategy().BE,
 		},
+		{
+			name: "get qos=System config",
+			args: args{
+				pod: createPod(corev1.PodQOSBurstable, apiext.QoSSystem).Pod,
+				strategy: func() *slov1alpha1.ResourceQoSStrategy {
+					strategy := defaultQoSStrategy()
+					strategy.System = defaultQoSStrategy().LSR
+					strategy.CgroupRoot = defaultQoSStrategy().BE
+					return strategy
+				}(),
+				config: NewDefaultConfig(),
+			},
+			want: defaultQoSStrategy().LSR,
+		},
+		{
+			name: "get qos=System kubeQoS=Burstable config when system config is missing",
+			args: args{
+				pod:      createPod(corev1.PodQOSBurstable, apiext.QoSSystem).Pod,
+				strategy: defaultQoSStrategy(),
+				config:   NewDefaultConfig(),
+			},
+			want: defaultQoSStrategy().LS,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1412,3 +2547,292 @@ func writeMemToCgroupFile(parentDir string, qos *slov1alpha1.ResourceQoS, helper
 	helper.WriteCgroupFileContents(parentDir, system.MemPriority, strconv.FormatInt(*qos.MemoryQoS.Priority, 10))
 	helper.WriteCgroupFileContents(parentDir, system.MemOomGroup, strconv.FormatInt(*qos.MemoryQoS.OomKillGroup, 10))
 }
+
+func Test_updateLeveledResourcesByType(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+	}
+	testingQoSDir := util.GetKubeQosRelativePath(corev1.PodQOSBurstable)
+	testingPodDir := util.GetPodCgroupDirWithKube("kubepods-burstable-pod-test")
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.CgroupReconcileDuration.Reset()
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.WriteCgroupFileContents(testingQoSDir, system.CPUShares, "1024")
+	helper.WriteCgroupFileContents(testingPodDir, system.CPUShares, "1024")
+	helper.WriteCgroupFileContents(testingQoSDir, system.MemoryLimit, "-1")
+
+	m := NewCgroupResourcesReconcile(&resmanager{})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.RunInit(stop))
+
+	leveledResources := [][]MergeableResourceUpdater{
+		{
+			NewCommonCgroupResourceUpdater(GroupOwnerRef(string(corev1.PodQOSBurstable)), testingQoSDir, system.CPUShares, "2048"),
+			NewCommonCgroupResourceUpdater(GroupOwnerRef(string(corev1.PodQOSBurstable)), testingQoSDir, system.MemoryLimit, "1073741824"),
+		},
+		{
+			NewCommonCgroupResourceUpdater(PodOwnerRef("default", "test"), testingPodDir, system.CPUShares, "512"),
+		},
+		{},
+	}
+	m.updateLeveledResourcesByType(leveledResources)
+
+	assert.Equal(t, "2048", helper.ReadCgroupFileContents(testingQoSDir, system.CPUShares))
+	assert.Equal(t, "512", helper.ReadCgroupFileContents(testingPodDir, system.CPUShares))
+	assert.Equal(t, "1073741824", helper.ReadCgroupFileContents(testingQoSDir, system.MemoryLimit))
+	assert.Equal(t, uint64(1), getCgroupReconcileDurationSampleCount(t, metrics.CgroupReconcileResourceCPU))
+	assert.Equal(t, uint64(1), getCgroupReconcileDurationSampleCount(t, metrics.CgroupReconcileResourceMemory))
+}
+
+func Test_splitLeveledResourcesByType(t *testing.T) {
+	cpuQoS := NewCommonCgroupResourceUpdater(GroupOwnerRef("burstable"), "burstable", system.CPUShares, "1024")
+	cpuPod := NewCommonCgroupResourceUpdater(PodOwnerRef("default", "test"), "pod", system.CPUShares, "512")
+	memQoS := NewCommonCgroupResourceUpdater(GroupOwnerRef("burstable"), "burstable", system.MemoryLimit, "-1")
+
+	got := splitLeveledResourcesByType([][]MergeableResourceUpdater{
+		{cpuQoS, memQoS},
+		{cpuPod},
+	})
+	assert.Equal(t, [][]MergeableResourceUpdater{{cpuQoS}, {cpuPod}}, got[metrics.CgroupReconcileResourceCPU])
+	assert.Equal(t, [][]MergeableResourceUpdater{{memQoS}, nil}, got[metrics.CgroupReconcileResourceMemory])
+	assert.Equal(t, 2, len(got))
+}
+
+func getCgroupReconcileDurationSampleCount(t *testing.T, resourceType string) uint64 {
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(metrics.CgroupReconcileDuration))
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+	for _, mf := range metricFamilies {
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == metrics.CgroupResourceKey && label.GetValue() == resourceType {
+					return m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestCgroupResourcesReconcile_getMergedPodResourceQoS_namespacePolicy(t *testing.T) {
+	testingNodeNoneResourceQoS := util.NoneResourceQoSStrategy().BE
+	testingMemoryQoSNoneResourceQoS := util.DefaultResourceQoSStrategy().BE
+	testingMemoryQoSNoneResourceQoS.MemoryQoS = util.NoneResourceQoSStrategy().BE.MemoryQoS
+	testingMemoryQoSAutoResourceQoS := util.NoneResourceQoSStrategy().BE
+	testingMemoryQoSAutoResourceQoS.MemoryQoS.MemoryQoS = *util.DefaultMemoryQoS(apiext.QoSBE)
+	testingMemoryQoSAutoResourceQoS1 := util.NoneResourceQoSStrategy().BE
+	testingMemoryQoSAutoResourceQoS1.MemoryQoS.MemoryQoS = *util.DefaultMemoryQoS(apiext.QoSBE)
+	testingMemoryQoSAutoResourceQoS1.MemoryQoS.ThrottlingPercent = pointer.Int64Ptr(90)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for name, policy := range map[string]string{"ns-none": "none", "ns-auto": "auto", "ns-invalid": "unknown"} {
+		assert.NoError(t, indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{apiext.AnnotationNamespaceMemoryQoSPolicy: policy},
+		}}))
+	}
+	assert.NoError(t, indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-unset"}}))
+	r := &resmanager{
+		config:          NewDefaultConfig(),
+		namespaceLister: corelisterv1.NewNamespaceLister(indexer),
+	}
+
+	testingPod := func(namespace string, memoryQoS string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: namespace,
+				Labels: map[string]string{
+					apiext.LabelPodQoS: string(apiext.QoSBE),
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+			},
+		}
+		if memoryQoS != "" {
+			pod.Annotations = map[string]string{apiext.AnnotationPodMemoryQoS: memoryQoS}
+		}
+		return pod
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		cfg  *slov1alpha1.ResourceQoS
+		want *slov1alpha1.ResourceQoS
+	}{
+		{
+			name: "namespace policy not set, use node config",
+			pod:  testingPod("ns-unset", ""),
+			cfg:  testingNodeNoneResourceQoS,
+			want: testingNodeNoneResourceQoS,
+		},
+		{
+			name: "namespace not found, use node config",
+			pod:  testingPod("ns-absent", ""),
+			cfg:  testingNodeNoneResourceQoS,
+			want: testingNodeNoneResourceQoS,
+		},
+		{
+			name: "namespace policy invalid, use node config",
+			pod:  testingPod("ns-invalid", ""),
+			cfg:  testingNodeNoneResourceQoS,
+			want: testingNodeNoneResourceQoS,
+		},
+		{
+			name: "namespace policy is None, disable memory qos",
+			pod:  testingPod("ns-none", ""),
+			cfg:  util.DefaultResourceQoSStrategy().BE,
+			want: testingMemoryQoSNoneResourceQoS,
+		},
+		{
+			name: "namespace policy is Auto, use the policy template",
+			pod:  testingPod("ns-auto", ""),
+			cfg:  testingNodeNoneResourceQoS,
+			want: testingMemoryQoSAutoResourceQoS,
+		},
+		{
+			name: "pod policy overrides namespace policy",
+			pod:  testingPod("ns-auto", `{"policy":"none"}`),
+			cfg:  testingNodeNoneResourceQoS,
+			want: testingNodeNoneResourceQoS,
+		},
+		{
+			name: "pod policy default overrides namespace policy",
+			pod:  testingPod("ns-none", `{"policy":"default"}`),
+			cfg:  util.DefaultResourceQoSStrategy().BE,
+			want: util.DefaultResourceQoSStrategy().BE,
+		},
+		{
+			name: "pod config without policy inherits namespace policy",
+			pod:  testingPod("ns-auto", `{"throttlingPercent":90}`),
+			cfg:  testingNodeNoneResourceQoS,
+			want: testingMemoryQoSAutoResourceQoS1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := CgroupResourcesReconcile{resmanager: r}
+			got, gotErr := c.getMergedPodResourceQoS(tt.pod, tt.cfg)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCgroupResourcesReconcile_getMergedPodResourceQoS_minPodMemory(t *testing.T) {
+	testingMemoryQoSNoneResourceQoS := util.DefaultResourceQoSStrategy().LS
+	testingMemoryQoSNoneResourceQoS.MemoryQoS.MemoryQoS = *util.NoneMemoryQoS()
+	testingMemoryQoSNoneResourceQoS.MemoryQoS.Enable = pointer.BoolPtr(false)
+	testingBEMemoryQoSNoneResourceQoS := util.DefaultResourceQoSStrategy().BE
+	testingBEMemoryQoSNoneResourceQoS.MemoryQoS.MemoryQoS = *util.NoneMemoryQoS()
+	testingBEMemoryQoSNoneResourceQoS.MemoryQoS.Enable = pointer.BoolPtr(false)
+
+	testingPod := func(qos apiext.QoSClass, requests, limits corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "default",
+				Labels: map[string]string{
+					apiext.LabelPodQoS: string(qos),
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "test-container",
+						Resources: corev1.ResourceRequirements{
+							Requests: requests,
+							Limits:   limits,
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+			},
+		}
+	}
+	memory := func(quantity string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(quantity)}
+	}
+	batchMemory := func(quantity string) corev1.ResourceList {
+		return corev1.ResourceList{apiext.BatchMemory: resource.MustParse(quantity)}
+	}
+
+	tests := []struct {
+		name      string
+		threshold int64
+		pod       *corev1.Pod
+		cfg       *slov1alpha1.ResourceQoS
+		want      *slov1alpha1.ResourceQoS
+	}{
+		{
+			name:      "apply to the small pod if the threshold is not set",
+			threshold: 0,
+			pod:       testingPod(apiext.QoSLS, memory("16Mi"), memory("16Mi")),
+			cfg:       util.DefaultResourceQoSStrategy().LS,
+			want:      util.DefaultResourceQoSStrategy().LS,
+		},
+		{
+			name:      "skip the pod with a small memory limit",
+			threshold: 64 << 20,
+			pod:       testingPod(apiext.QoSLS, memory("16Mi"), memory("32Mi")),
+			cfg:       util.DefaultResourceQoSStrategy().LS,
+			want:      testingMemoryQoSNoneResourceQoS,
+		},
+		{
+			name:      "apply to the pod with a large memory limit",
+			threshold: 64 << 20,
+			pod:       testingPod(apiext.QoSLS, memory("16Mi"), memory("1Gi")),
+			cfg:       util.DefaultResourceQoSStrategy().LS,
+			want:      util.DefaultResourceQoSStrategy().LS,
+		},
+		{
+			name:      "skip the unlimited pod with a small memory request",
+			threshold: 64 << 20,
+			pod:       testingPod(apiext.QoSLS, memory("16Mi"), nil),
+			cfg:       util.DefaultResourceQoSStrategy().LS,
+			want:      testingMemoryQoSNoneResourceQoS,
+		},
+		{
+			name:      "apply to the unlimited pod with a large memory request",
+			threshold: 64 << 20,
+			pod:       testingPod(apiext.QoSLS, memory("1Gi"), nil),
+			cfg:       util.DefaultResourceQoSStrategy().LS,
+			want:      util.DefaultResourceQoSStrategy().LS,
+		},
+		{
+			name:      "skip the be pod with a small batch memory limit",
+			threshold: 64 << 20,
+			pod:       testingPod(apiext.QoSBE, batchMemory("16Mi"), batchMemory("32Mi")),
+			cfg:       util.DefaultResourceQoSStrategy().BE,
+			want:      testingBEMemoryQoSNoneResourceQoS,
+		},
+		{
+			name:      "apply to the be pod with a large batch memory limit",
+			threshold: 64 << 20,
+			pod:       testingPod(apiext.QoSBE, batchMemory("16Mi"), batchMemory("1Gi")),
+			cfg:       util.DefaultResourceQoSStrategy().BE,
+			want:      util.DefaultResourceQoSStrategy().BE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.MinPodMemoryForQoSBytes = tt.threshold
+			c := CgroupResourcesReconcile{resmanager: &resmanager{config: config}}
+			got, gotErr := c.getMergedPodResourceQoS(tt.pod, tt.cfg)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
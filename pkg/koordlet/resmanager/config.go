@@ -18,27 +18,226 @@ package resmanager
 
 import (
 	"flag"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	cliflag "k8s.io/component-base/cli/flag"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+const (
+	// EvictionCostOrderTieBreak compares the pod eviction cost only if the pods have the same priority
+	EvictionCostOrderTieBreak = "tieBreak"
+	// EvictionCostOrderPrimary compares the pod eviction cost before the pod priority
+	EvictionCostOrderPrimary = "primary"
+
+	// NodeMemorySourceAllocatable takes the node memory allocatable for the qos calculations
+	NodeMemorySourceAllocatable = "allocatable"
+	// NodeMemorySourceCapacity takes the node memory capacity for the qos calculations
+	NodeMemorySourceCapacity = "capacity"
+
+	// EvictAPIFallbackV1beta1 evicts the pod by the policy/v1beta1 Eviction API if the policy/v1 one is unavailable
+	EvictAPIFallbackV1beta1 = "v1beta1"
+	// EvictAPIFallbackDelete deletes the pod with the evict grace period if the policy/v1 Eviction API is unavailable
+	EvictAPIFallbackDelete = "delete"
 )
 
 type Config struct {
-	ReconcileIntervalSeconds   int
-	CPUSuppressIntervalSeconds int
-	MemoryEvictIntervalSeconds int
-	MemoryEvictCoolTimeSeconds int
+	ReconcileIntervalSeconds         int
+	CPUSuppressIntervalSeconds       int
+	CPUSuppressMetricStaleSeconds    int
+	MemoryEvictIntervalSeconds       int
+	MemoryEvictCoolTimeSeconds       int
+	QoSDriftAuditIntervalSeconds     int
+	QoSDriftAuditSamplePods          int
+	ExcludeDaemonSetPods             bool
+	EnforcementPodSelector           string
+	EnforcementGraceSeconds          map[string]string
+	EvictFailEventIntervalSeconds    int
+	QoSAppliedEventIntervalSeconds   int
+	EvictionDedupTTLSeconds          int
+	NodeSLOFallbackPath              string
+	DefaultNodeSLOSpecPath           string
+	NodeSLOUpdateCoalesceSeconds     int
+	NodeSLOStartupTimeoutSeconds     int
+	BEOverloadTaintEvictionCount     int
+	BEOverloadTaintWindowSeconds     int
+	BEOverloadTaintCoolDownSeconds   int
+	MemoryEvictSoftEvict             bool
+	MemoryEvictSoftEvictGraceSeconds int
+	MemoryEvictSkipLastReplica       bool
+	MemoryEvictCostOrder             string
+	MemoryEvictOOMKillThreshold      int
+	MemoryEvictCombinedPressure      bool
+	MemoryEvictCPUWeight             float64
+	MemoryEvictMemoryWeight          float64
+	MemoryEvictByDominantContainer   bool
+	MemoryEvictLSLastResort          bool
+	MemoryEvictLSSustainedSeconds    int
+	CPUBurstBackOffOnMemoryEvict     bool
+	OOMQuarantineKillCount           int
+	OOMQuarantineWindowSeconds       int
+	DiskEvictIntervalSeconds         int
+	DiskEvictCoolTimeSeconds         int
+	FeatureJitterFactor              float64
+	KillContainersStrict             bool
+	EvictPDBPreflight                bool
+	EvictAnnotatePod                 bool
+	EvictionSummaryLog               bool
+	EvictForceDeleteZeroGrace        bool
+	EvictAPIUnavailableFallback      string
+	EvictOwnerCooldownSeconds        int
+	EvictOnNodePressureCondition     bool
+	EvictMaxInFlight                 int
+	EvictionHistoryNamespace         string
+	EvictionHistoryRetention         int
+	NamespaceMemoryQoSPolicy         bool
+	QoSClassLabelKey                 string
+	DefaultQoSClass                  string
+	APIServerWriteQPS                float64
+	APIServerWriteBurst              int
+	MemoryMinAllocatablePercent      int
+	QoSNodeMemorySource              string
+	QoSNodeMemoryOverrideBytes       int64
+	MinPodMemoryForQoSBytes          int64
+	MemoryHighDynamicScale           bool
+	MemoryHighScaleStartPercent      int
+	MemoryHighScaleFullPercent       int
+	MemoryHighScaleMinPercent        int
+	SuppressReleaseStepPercent       int
+	ReconcileDecisionLogSize         int
+	ResctrlAutoMount                 bool
 }
 
 func NewDefaultConfig() *Config {
 	return &Config{
-		ReconcileIntervalSeconds:   1,
-		CPUSuppressIntervalSeconds: 1,
-		MemoryEvictIntervalSeconds: 1,
-		MemoryEvictCoolTimeSeconds: 4,
+		ReconcileIntervalSeconds:         1,
+		CPUSuppressIntervalSeconds:       1,
+		CPUSuppressMetricStaleSeconds:    60,
+		MemoryEvictIntervalSeconds:       1,
+		MemoryEvictCoolTimeSeconds:       4,
+		QoSDriftAuditIntervalSeconds:     300,
+		QoSDriftAuditSamplePods:          10,
+		EvictFailEventIntervalSeconds:    300,
+		EvictionDedupTTLSeconds:          120,
+		NodeSLOUpdateCoalesceSeconds:     1,
+		BEOverloadTaintEvictionCount:     3,
+		BEOverloadTaintWindowSeconds:     300,
+		BEOverloadTaintCoolDownSeconds:   600,
+		MemoryEvictSoftEvictGraceSeconds: 30,
+		MemoryEvictCostOrder:             EvictionCostOrderTieBreak,
+		MemoryEvictCPUWeight:             1,
+		MemoryEvictMemoryWeight:          1,
+		MemoryEvictLSSustainedSeconds:    300,
+		OOMQuarantineKillCount:           3,
+		OOMQuarantineWindowSeconds:       600,
+		DiskEvictIntervalSeconds:         10,
+		DiskEvictCoolTimeSeconds:         60,
+		FeatureJitterFactor:              0.1,
+		EvictionHistoryRetention:         50,
+		APIServerWriteQPS:                5,
+		APIServerWriteBurst:              10,
+		MemoryMinAllocatablePercent:      100,
+		QoSNodeMemorySource:              NodeMemorySourceAllocatable,
+		MemoryHighScaleStartPercent:      70,
+		MemoryHighScaleFullPercent:       95,
+		MemoryHighScaleMinPercent:        50,
+		QoSClassLabelKey:                 apiext.LabelPodQoS,
+		ReconcileDecisionLogSize:         256,
+		ResctrlAutoMount:                 true,
 	}
 }
 
 func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.IntVar(&c.ReconcileIntervalSeconds, "ReconcileIntervalSeconds", c.ReconcileIntervalSeconds, "reconcile be pod cgroup interval by seconds")
 	fs.IntVar(&c.CPUSuppressIntervalSeconds, "CPUSuppressIntervalSeconds", c.CPUSuppressIntervalSeconds, "suppress be pod cpu resource interval by seconds")
+	fs.IntVar(&c.CPUSuppressMetricStaleSeconds, "CPUSuppressMetricStaleSeconds", c.CPUSuppressMetricStaleSeconds, "release the be cpu suppression if no node metric is collected in the seconds, 0 to disable")
 	fs.IntVar(&c.MemoryEvictIntervalSeconds, "MemoryEvictIntervalSeconds", c.MemoryEvictIntervalSeconds, "evict be pod(memory) interval by seconds")
 	fs.IntVar(&c.MemoryEvictCoolTimeSeconds, "MemoryEvictCoolTimeSeconds", c.MemoryEvictCoolTimeSeconds, "cooling time: memory next evict time should after lastEvictTime + MemoryEvictCoolTimeSeconds")
+	fs.IntVar(&c.QoSDriftAuditIntervalSeconds, "QoSDriftAuditIntervalSeconds", c.QoSDriftAuditIntervalSeconds, "audit qos config drift of pod cgroups interval by seconds")
+	fs.IntVar(&c.QoSDriftAuditSamplePods, "QoSDriftAuditSamplePods", c.QoSDriftAuditSamplePods, "the number of pods sampled in each qos config drift audit")
+	fs.BoolVar(&c.ExcludeDaemonSetPods, "ExcludeDaemonSetPods", c.ExcludeDaemonSetPods, "exclude DaemonSet pods from qos enforcement and eviction")
+	fs.StringVar(&c.EnforcementPodSelector, "EnforcementPodSelector", c.EnforcementPodSelector, "the label selector of the pods handled by qos enforcement and eviction, e.g. koordinator.sh/qos-enforce=true, all pods if empty")
+	fs.Var(cliflag.NewMapStringString(&c.EnforcementGraceSeconds), "EnforcementGraceSeconds", "the seconds after the pod start for each qos class, during which the memory qos relaxes the memory.high and the cpu burst relaxes the cfs quota to the ceil of the pod, e.g. LS=60,BE=30")
+	fs.IntVar(&c.EvictFailEventIntervalSeconds, "EvictFailEventIntervalSeconds", c.EvictFailEventIntervalSeconds, "the minimum interval by seconds to record repeated evict failure events of the same pod and reason")
+	fs.IntVar(&c.QoSAppliedEventIntervalSeconds, "QoSAppliedEventIntervalSeconds", c.QoSAppliedEventIntervalSeconds, "the minimum interval by seconds to record the event on the pod when its qos cgroup values are first applied or changed, 0 disables the events")
+	fs.IntVar(&c.EvictionDedupTTLSeconds, "EvictionDedupTTLSeconds", c.EvictionDedupTTLSeconds, "the duration by seconds to skip evicting a pod again after it is evicted successfully")
+	fs.StringVar(&c.NodeSLOFallbackPath, "NodeSLOFallbackPath", c.NodeSLOFallbackPath, "the local file path to load NodeSLO at startup and persist the latest received NodeSLO, disabled if empty")
+	fs.StringVar(&c.DefaultNodeSLOSpecPath, "DefaultNodeSLOSpecPath", c.DefaultNodeSLOSpecPath, "the local file path of a json NodeSLO spec to override the built-in default spec which the NodeSLO is merged with, e.g. for the node class, disabled if empty")
+	fs.IntVar(&c.NodeSLOUpdateCoalesceSeconds, "NodeSLOUpdateCoalesceSeconds", c.NodeSLOUpdateCoalesceSeconds, "the window by seconds to coalesce the NodeSLO updates and only apply the latest one, 0 to disable")
+	fs.IntVar(&c.NodeSLOStartupTimeoutSeconds, "NodeSLOStartupTimeoutSeconds", c.NodeSLOStartupTimeoutSeconds, "start with the default NodeSLO spec if the NodeSLO of the node is not received in the seconds at startup, 0 to wait until received")
+	fs.IntVar(&c.BEOverloadTaintEvictionCount, "BEOverloadTaintEvictionCount", c.BEOverloadTaintEvictionCount, "taint the node as be-overloaded when be pods are evicted at least the count of times within BEOverloadTaintWindowSeconds")
+	fs.IntVar(&c.BEOverloadTaintWindowSeconds, "BEOverloadTaintWindowSeconds", c.BEOverloadTaintWindowSeconds, "the window by seconds to count the be evictions for tainting the node as be-overloaded")
+	fs.IntVar(&c.BEOverloadTaintCoolDownSeconds, "BEOverloadTaintCoolDownSeconds", c.BEOverloadTaintCoolDownSeconds, "remove the be-overloaded taint when no be pod is evicted in the cool down seconds")
+	fs.BoolVar(&c.MemoryEvictSoftEvict, "MemoryEvictSoftEvict", c.MemoryEvictSoftEvict, "mark be pods with a soft evict deadline before evicting them on memory pressure")
+	fs.IntVar(&c.MemoryEvictSoftEvictGraceSeconds, "MemoryEvictSoftEvictGraceSeconds", c.MemoryEvictSoftEvictGraceSeconds, "the grace period by seconds for the soft evicted pods to terminate themselves before evicted")
+	fs.BoolVar(&c.MemoryEvictSkipLastReplica, "MemoryEvictSkipLastReplica", c.MemoryEvictSkipLastReplica, "skip evicting the be pod on memory pressure if it is the last ready replica of its workload on the node")
+	fs.StringVar(&c.MemoryEvictCostOrder, "MemoryEvictCostOrder", c.MemoryEvictCostOrder, "how the eviction cost annotation orders the be pods to evict on memory pressure, \"tieBreak\" to compare it after the priority, \"primary\" to compare it before the priority")
+	fs.IntVar(&c.MemoryEvictOOMKillThreshold, "MemoryEvictOOMKillThreshold", c.MemoryEvictOOMKillThreshold, "evict a be pod if the oom kills in the be cgroups increase by the threshold since the last memory evict process, 0 to disable")
+	fs.BoolVar(&c.MemoryEvictCombinedPressure, "MemoryEvictCombinedPressure", c.MemoryEvictCombinedPressure, "prefer evicting the be pods relieving the most combined cpu and memory pressure of the node, instead of the most memory usage")
+	fs.Float64Var(&c.MemoryEvictCPUWeight, "MemoryEvictCPUWeight", c.MemoryEvictCPUWeight, "the weight of the cpu usage ratio of the node capacity to score the be pods when MemoryEvictCombinedPressure is enabled")
+	fs.Float64Var(&c.MemoryEvictMemoryWeight, "MemoryEvictMemoryWeight", c.MemoryEvictMemoryWeight, "the weight of the memory usage ratio of the node capacity to score the be pods when MemoryEvictCombinedPressure is enabled")
+	fs.BoolVar(&c.MemoryEvictByDominantContainer, "MemoryEvictByDominantContainer", c.MemoryEvictByDominantContainer, "prefer evicting the be pods whose largest container uses the most memory among the pods in the same order, instead of the pods using the most memory in total")
+	fs.BoolVar(&c.MemoryEvictLSLastResort, "MemoryEvictLSLastResort", c.MemoryEvictLSLastResort, "evict the ls pods with the lowest priority one at a time as the last resort, if no be pod is left to evict while the node memory pressure persists for MemoryEvictLSSustainedSeconds")
+	fs.BoolVar(&c.CPUBurstBackOffOnMemoryEvict, "CPUBurstBackOffOnMemoryEvict", c.CPUBurstBackOffOnMemoryEvict, "withdraw the cfs quota burst of the pods to the base while the memory eviction is active on the node memory pressure, so the memory eviction takes precedence over the cpu burst")
+	fs.IntVar(&c.MemoryEvictLSSustainedSeconds, "MemoryEvictLSSustainedSeconds", c.MemoryEvictLSSustainedSeconds, "the duration by seconds the node memory pressure persists with no be pod left to evict before evicting the ls pods when MemoryEvictLSLastResort is enabled")
+	fs.IntVar(&c.OOMQuarantineKillCount, "OOMQuarantineKillCount", c.OOMQuarantineKillCount, "evict a be pod with reason RepeatedOOM when it is oom killed at least the count of times within OOMQuarantineWindowSeconds")
+	fs.IntVar(&c.OOMQuarantineWindowSeconds, "OOMQuarantineWindowSeconds", c.OOMQuarantineWindowSeconds, "the window by seconds to count the oom kills of a be pod for the quarantine eviction")
+	fs.IntVar(&c.DiskEvictIntervalSeconds, "DiskEvictIntervalSeconds", c.DiskEvictIntervalSeconds, "evict be pod(disk) interval by seconds")
+	fs.IntVar(&c.DiskEvictCoolTimeSeconds, "DiskEvictCoolTimeSeconds", c.DiskEvictCoolTimeSeconds, "cooling time: disk next evict time should after lastEvictTime + DiskEvictCoolTimeSeconds")
+	fs.Float64Var(&c.FeatureJitterFactor, "FeatureJitterFactor", c.FeatureJitterFactor, "the max fraction of the interval to randomly delay the first run of each feature, 0 to disable")
+	fs.BoolVar(&c.KillContainersStrict, "KillContainersStrict", c.KillContainersStrict, "skip evicting the pod and retry it later if its containers fail to be killed since the runtime handler is unavailable")
+	fs.BoolVar(&c.EvictPDBPreflight, "EvictPDBPreflight", c.EvictPDBPreflight, "skip evicting the pod if a PodDisruptionBudget covering it allows no disruption, which watches the PodDisruptionBudgets of all namespaces")
+	fs.BoolVar(&c.EvictAnnotatePod, "EvictAnnotatePod", c.EvictAnnotatePod, "annotate the pod with the eviction reason and message of koordlet before evicting it, so the controllers can tell why the pod is evicted")
+	fs.BoolVar(&c.EvictForceDeleteZeroGrace, "EvictForceDeleteZeroGrace", c.EvictForceDeleteZeroGrace, "force delete the pod instead of evicting it if its grace period to evict with is zero, so it terminates at once on the node pressure")
+	fs.StringVar(&c.EvictAPIUnavailableFallback, "EvictAPIUnavailableFallback", c.EvictAPIUnavailableFallback, "the way to evict the pod if the policy/v1 Eviction API is unavailable on the apiserver, v1beta1 or delete, no fallback if empty")
+	fs.IntVar(&c.EvictOwnerCooldownSeconds, "EvictOwnerCooldownSeconds", c.EvictOwnerCooldownSeconds, "the duration by seconds to skip evicting another pod of the same controller owner after a pod is evicted, 0 to disable")
+	fs.BoolVar(&c.EvictOnNodePressureCondition, "EvictOnNodePressureCondition", c.EvictOnNodePressureCondition, "evict a be pod in each memory or disk evict round while the node reports the MemoryPressure or DiskPressure condition, even if the usage is below the threshold, to preempt the kubelet evicting the ls pods")
+	fs.IntVar(&c.EvictMaxInFlight, "EvictMaxInFlight", c.EvictMaxInFlight, "the max number of the evictions in flight across all the eviction features, which wait for a free slot beyond the limit, 0 to disable")
+	fs.StringVar(&c.EvictionHistoryNamespace, "EvictionHistoryNamespace", c.EvictionHistoryNamespace, "the namespace of the ConfigMap koordlet-eviction-history-<node> listing the recent evictions of the node with the pod, reason, pressure and time, disabled if empty")
+	fs.IntVar(&c.EvictionHistoryRetention, "EvictionHistoryRetention", c.EvictionHistoryRetention, "the max number of the recent evictions retained in the eviction history ConfigMap, where the oldest ones are trimmed")
+	fs.BoolVar(&c.EvictionSummaryLog, "EvictionSummaryLog", c.EvictionSummaryLog, "log a summary line at info level for each eviction cycle, with the node pressure, the numbers of the candidates considered and the pods evicted, and the reasons to skip the candidates")
+	fs.BoolVar(&c.NamespaceMemoryQoSPolicy, "NamespaceMemoryQoSPolicy", c.NamespaceMemoryQoSPolicy, "inherit the default memory qos policy of pods from the namespace annotation koordinator.sh/memoryQoSPolicy, which watches all namespaces")
+	fs.StringVar(&c.QoSClassLabelKey, "QoSClassLabelKey", c.QoSClassLabelKey, "the label key to classify the koordinator qos class of pods, which takes precedence over the koordinator qos label if they differ")
+	fs.StringVar(&c.DefaultQoSClass, "DefaultQoSClass", c.DefaultQoSClass, "the koordinator qos class of the pods without a valid one, \"LS\" or \"BE\", empty to skip them; note the pods taken as BE can be suppressed or evicted")
+	fs.Float64Var(&c.APIServerWriteQPS, "APIServerWriteQPS", c.APIServerWriteQPS, "the qps to limit the apiserver writes like evictions and node updates, 0 to disable")
+	fs.IntVar(&c.APIServerWriteBurst, "APIServerWriteBurst", c.APIServerWriteBurst, "the burst to limit the apiserver writes like evictions and node updates")
+	fs.Int64Var(&c.MinPodMemoryForQoSBytes, "MinPodMemoryForQoSBytes", c.MinPodMemoryForQoSBytes, "disable the memory qos of the pods whose memory limit, or memory request if not limited, is below the bytes, 0 to apply to all pods")
+	fs.IntVar(&c.MemoryMinAllocatablePercent, "MemoryMinAllocatablePercent", c.MemoryMinAllocatablePercent, "the max percent of the node memory selected by QoSNodeMemorySource protected by the memory.min of all pods, which are scaled down proportionally if exceeded, 0 to disable")
+	fs.StringVar(&c.QoSNodeMemorySource, "QoSNodeMemorySource", c.QoSNodeMemorySource, "the node memory for the memory qos calculations, e.g. the memory.high and memory.swap.max of the containers without memory limits and the memory.min protection limit, \"allocatable\" or \"capacity\"")
+	fs.Int64Var(&c.QoSNodeMemoryOverrideBytes, "QoSNodeMemoryOverrideBytes", c.QoSNodeMemoryOverrideBytes, "the explicit node memory bytes for the memory qos calculations, which overrides QoSNodeMemorySource if positive")
+	fs.BoolVar(&c.MemoryHighDynamicScale, "MemoryHighDynamicScale", c.MemoryHighDynamicScale, "lower the memory.high of be containers as the node memory usage climbs, and ease it back as the usage drops")
+	fs.IntVar(&c.MemoryHighScaleStartPercent, "MemoryHighScaleStartPercent", c.MemoryHighScaleStartPercent, "the node memory usage percent to start lowering the memory.high of be containers")
+	fs.IntVar(&c.MemoryHighScaleFullPercent, "MemoryHighScaleFullPercent", c.MemoryHighScaleFullPercent, "the node memory usage percent where the memory.high of be containers is lowered to MemoryHighScaleMinPercent")
+	fs.IntVar(&c.MemoryHighScaleMinPercent, "MemoryHighScaleMinPercent", c.MemoryHighScaleMinPercent, "the min percent of the configured memory.high of be containers to lower to, which is no less than memory.min")
+	fs.IntVar(&c.SuppressReleaseStepPercent, "SuppressReleaseStepPercent", c.SuppressReleaseStepPercent, "the percent of the gap to restore in each cycle when releasing the be cpu suppression and the lowered memory.high, 0 or 100 to release at once")
+	fs.IntVar(&c.ReconcileDecisionLogSize, "ReconcileDecisionLogSize", c.ReconcileDecisionLogSize, "the number of the latest reconcile decisions retained for the debug endpoint /debug/reconciledecisions, 0 to disable")
+	fs.BoolVar(&c.ResctrlAutoMount, "ResctrlAutoMount", c.ResctrlAutoMount, "try to mount the resctrl fs at the resctrl root dir if l3 cat is not enabled, otherwise the resctrl reconcile is disabled")
+}
+
+// SetupQoSClassLabelKey validates the QoSClassLabelKey and sets it as the label key to get the pod qos class. It should
+// be called once after the flags are parsed and before any component starts, since the key is read without a lock.
+func (c *Config) SetupQoSClassLabelKey() error {
+	if c.QoSClassLabelKey == "" {
+		return nil
+	}
+	if errs := validation.IsQualifiedName(c.QoSClassLabelKey); len(errs) > 0 {
+		return fmt.Errorf("invalid QoSClassLabelKey %q, %s", c.QoSClassLabelKey, strings.Join(errs, "; "))
+	}
+	apiext.QoSClassLabelKey = c.QoSClassLabelKey
+	return nil
+}
+
+// SetupDefaultQoSClass validates the DefaultQoSClass and sets it as the qos class of the unclassified pods. It should be
+// called once after the flags are parsed and before any component starts, like SetupQoSClassLabelKey.
+func (c *Config) SetupDefaultQoSClass() error {
+	switch defaultQoSClass := apiext.QoSClass(c.DefaultQoSClass); defaultQoSClass {
+	case apiext.QoSNone, apiext.QoSLS, apiext.QoSBE:
+		apiext.DefaultQoSClass = defaultQoSClass
+		return nil
+	default:
+		return fmt.Errorf("unsupported DefaultQoSClass %q, should be LS, BE or empty", c.DefaultQoSClass)
+	}
 }
@@ -0,0 +1,74 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestConfig_SetupQoSClassLabelKey(t *testing.T) {
+	defer func() { apiext.QoSClassLabelKey = apiext.LabelPodQoS }()
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{name: "keep the default key if empty", key: "", want: apiext.LabelPodQoS},
+		{name: "set the valid key", key: "example.com/qos", want: "example.com/qos"},
+		{name: "reject the invalid key", key: "example.com/invalid/qos", want: apiext.LabelPodQoS, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiext.QoSClassLabelKey = apiext.LabelPodQoS
+			c := NewDefaultConfig()
+			c.QoSClassLabelKey = tt.key
+			err := c.SetupQoSClassLabelKey()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, apiext.QoSClassLabelKey)
+		})
+	}
+}
+
+func TestConfig_SetupDefaultQoSClass(t *testing.T) {
+	defer func() { apiext.DefaultQoSClass = apiext.QoSNone }()
+	tests := []struct {
+		name            string
+		defaultQoSClass string
+		want            apiext.QoSClass
+		wantErr         bool
+	}{
+		{name: "skip the unclassified pods if empty", defaultQoSClass: "", want: apiext.QoSNone},
+		{name: "take the unclassified pods as LS", defaultQoSClass: "LS", want: apiext.QoSLS},
+		{name: "take the unclassified pods as BE", defaultQoSClass: "BE", want: apiext.QoSBE},
+		{name: "reject the unsupported qos class", defaultQoSClass: "LSR", want: apiext.QoSNone, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiext.DefaultQoSClass = apiext.QoSNone
+			c := NewDefaultConfig()
+			c.DefaultQoSClass = tt.defaultQoSClass
+			err := c.SetupDefaultQoSClass()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, apiext.DefaultQoSClass)
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
@@ -42,6 +43,16 @@ const (
 
 	cpuThresholdPercentForLimiterConsumeTokens = 100
 	cpuThresholdPercentForLimiterSavingTokens  = 60
+
+	// the burst record of a pod is recycled if it has not been updated for the duration
+	podBurstRecordExpireDuration = 10 * time.Minute
+
+	// cfsQuotaBurstOnlyQuietDuration is how long the throttling of a container stays absent before its cfs quota
+	// steps down to the baseline in CFSQuotaBurstOnly, since the quiet rounds usually come from the scale-up itself
+	cfsQuotaBurstOnlyQuietDuration = time.Minute
+
+	// maxCPUBurstPercent is the upper bound of the CPUBurstPercent scaled by the pod priority
+	maxCPUBurstPercent = 10000
 )
 
 // cfsOperation is used for CFSQuotaBurst strategy
@@ -151,19 +162,53 @@ func (l *burstLimiter) Expire() bool {
 	return time.Since(l.lastUpdateTime) > l.expireDuration
 }
 
+// podBurstRecord accounts the cumulative duration a pod stays in the cfs quota burst state
+type podBurstRecord struct {
+	burstDuration  time.Duration
+	lastUpdateTime time.Time
+	// withdrawn means the burst duration exceeds the CFSQuotaBurstPeriodSeconds, the cfs quota is kept as the
+	// baseline until the pod is not throttled
+	withdrawn bool
+}
+
+// containerCPUStat is the last cpu.stat point read for a container, which is used to calculate the throttling
+// between the reconcile rounds
+type containerCPUStat struct {
+	stat           *system.CPUStatRaw
+	lastUpdateTime time.Time
+	// lastThrottledTime is the last time the container is found throttled, which is zero if never
+	lastThrottledTime time.Time
+}
+
 type CPUBurst struct {
 	resmanager           *resmanager
 	executor             *ResourceUpdateExecutor
 	nodeCPUBurstStrategy *slov1alpha1.CPUBurstStrategy
 	containerLimiter     map[string]*burstLimiter
+	podBurstRecords      map[string]*podBurstRecord
+	// graceRelaxedPods records the last time of the pods relaxing the cfs quota to the ceil in the enforcement grace,
+	// which are reset to the base cfs quota once the grace ends
+	graceRelaxedPods map[string]time.Time
+	// containerCPUStats records the last cpu.stat point of the containers, keyed by the container id
+	containerCPUStats map[string]*containerCPUStat
+	burstStates       *burstStateStore
+	clock             clock.Clock
+	// sharePoolOverloadDegree is how far the share pool usage exceeds the threshold in the current round, which
+	// is 0 at the threshold and 1 at the full usage
+	sharePoolOverloadDegree float64
 }
 
 func NewCPUBurst(resmanager *resmanager) *CPUBurst {
 	executor := NewResourceUpdateExecutor("CPUBurstExecutor", resmanager.config.ReconcileIntervalSeconds*60)
 	return &CPUBurst{
-		resmanager:       resmanager,
-		executor:         executor,
-		containerLimiter: make(map[string]*burstLimiter),
+		resmanager:        resmanager,
+		executor:          executor,
+		containerLimiter:  make(map[string]*burstLimiter),
+		podBurstRecords:   make(map[string]*podBurstRecord),
+		graceRelaxedPods:  make(map[string]time.Time),
+		containerCPUStats: make(map[string]*containerCPUStat),
+		burstStates:       defaultBurstStates,
+		clock:             clock.RealClock{},
 	}
 }
 
@@ -184,9 +229,16 @@ func (b *CPUBurst) start() {
 	podsMeta := b.resmanager.statesInformer.GetAllPods()
 
 	// get node state by node share pool usage
-	nodeState := b.getNodeStateForBurst(*b.nodeCPUBurstStrategy.SharePoolThresholdPercent, podsMeta)
-	klog.V(5).Infof("get node state %v for cpu burst", nodeState)
+	nodeState, overloadDegree := b.getNodeStateForBurst(*b.nodeCPUBurstStrategy.SharePoolThresholdPercent, podsMeta)
+	if b.resmanager.pressureState.isMemoryPressureActive() {
+		// the memory eviction takes precedence, withdraw all the cfs quota burst as the share pool fully overloaded
+		klog.V(4).Infof("memory eviction is active on the node pressure, back off the cpu burst")
+		nodeState, overloadDegree = nodeBurstOverload, 1
+	}
+	b.sharePoolOverloadDegree = overloadDegree
+	klog.V(5).Infof("get node state %v for cpu burst, share pool overload degree %v", nodeState, overloadDegree)
 
+	burstStates := make([]PodBurstState, 0, len(podsMeta))
 	for _, podMeta := range podsMeta {
 		if podMeta == nil || podMeta.Pod == nil {
 			klog.Warningf("podMeta is illegal, detail %v", podMeta)
@@ -197,6 +249,11 @@ func (b *CPUBurst) start() {
 			// ignore LSR and BE pod
 			continue
 		}
+		if isPodCPUPinnedByKubelet(podMeta) {
+			klog.V(5).Infof("ignore pod %v/%v for cpu burst since it is pinned by the kubelet cpu manager",
+				podMeta.Pod.Namespace, podMeta.Pod.Name)
+			continue
+		}
 		// merge burst config from pod and node
 		cpuBurstCfg := genPodBurstConfig(podMeta.Pod, &b.nodeCPUBurstStrategy.CPUBurstConfig)
 		if cpuBurstCfg == nil {
@@ -204,30 +261,39 @@ func (b *CPUBurst) start() {
 				podMeta.Pod.Namespace, podMeta.Pod.Name, cpuBurstCfg)
 			continue
 		}
+		cpuBurstCfg = scalePodBurstConfigByPriority(podMeta.Pod, cpuBurstCfg, b.nodeCPUBurstStrategy.PriorityScale)
 		klog.V(5).Infof("get pod %v/%v cpu burst config: %v", podMeta.Pod.Namespace, podMeta.Pod.Name, cpuBurstCfg)
 		// set cpu.cfs_burst_us for containers
 		b.applyCPUBurst(cpuBurstCfg, podMeta)
 		// scale cpu.cfs_quota_us for pod and containers
 		b.applyCFSQuotaBurst(cpuBurstCfg, podMeta, nodeState)
+		burstStates = append(burstStates, getPodBurstState(cpuBurstCfg, podMeta))
+	}
+	if b.burstStates != nil {
+		b.burstStates.set(burstStates)
 	}
 	b.Recycle()
 }
 
 // getNodeStateForBurst checks whether node share pool cpu usage beyonds the threshold
-// return isOverload, share pool usage ratio and message detail
+// return node burst state and the overload degree of share pool usage
 func (b *CPUBurst) getNodeStateForBurst(sharePoolThresholdPercent int64,
-	podsMeta []*statesinformer.PodMeta) nodeStateForBurst {
+	podsMeta []*statesinformer.PodMeta) (nodeStateForBurst, float64) {
 	overloadMetricDurationSeconds := util.MinInt64(int64(b.resmanager.config.ReconcileIntervalSeconds*5), 10)
 	queryParam := generateQueryParamsAvg(overloadMetricDurationSeconds)
 	nodeMetric, podsMetric := b.resmanager.collectNodeAndPodMetrics(queryParam)
 	if nodeMetric == nil {
 		klog.Warningf("node metric is nil during handle cfs burst scale down")
-		return nodeBurstUnknown
+		return nodeBurstUnknown, 0
+	}
+	if podsMetric == nil {
+		klog.Warningf("pod metrics are nil during handle cfs burst scale down")
+		return nodeBurstUnknown, 0
 	}
 	nodeCPUInfo, err := b.resmanager.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
 	if err != nil || nodeCPUInfo == nil {
 		klog.Warningf("get node cpu info failed, detail %v, error %v", nodeCPUInfo, err)
-		return nodeBurstUnknown
+		return nodeBurstUnknown, 0
 	}
 
 	podMetricMap := make(map[string]*metriccache.PodResourceMetric)
@@ -278,13 +344,37 @@ func (b *CPUBurst) getNodeStateForBurst(sharePoolThresholdPercent int64,
 	} else { // sharePoolUsageRatio < sharePoolCoolingRatio
 		nodeBurstState = nodeBurstIdle
 	}
-	return nodeBurstState
+	return nodeBurstState, getSharePoolOverloadDegree(sharePoolUsageRatio, sharePoolThresholdRatio)
+}
+
+// getSharePoolOverloadDegree returns how far the share pool usage exceeds the threshold, which grows linearly from 0
+// at the threshold to 1 at the full usage
+func getSharePoolOverloadDegree(sharePoolUsageRatio, sharePoolThresholdRatio float64) float64 {
+	if sharePoolUsageRatio <= sharePoolThresholdRatio {
+		return 0
+	}
+	if sharePoolUsageRatio >= 1 || sharePoolThresholdRatio >= 1 {
+		return 1
+	}
+	return (sharePoolUsageRatio - sharePoolThresholdRatio) / (1 - sharePoolThresholdRatio)
+}
+
+// getBurstScaleFactor returns the ratio of cfs quota burst allowed by the share pool overload degree
+func getBurstScaleFactor(overloadDegree float64) float64 {
+	return 1 - overloadDegree
 }
 
 // scale cpu.cfs_quota_us for pod/containers by container throttled state and node state
 func (b *CPUBurst) applyCFSQuotaBurst(burstCfg *slov1alpha1.CPUBurstConfig, podMeta *statesinformer.PodMeta,
 	nodeState nodeStateForBurst) {
 	pod := podMeta.Pod
+	burstAllowedByPeriod := b.cfsBurstAllowedByPeriod(burstCfg, pod)
+	// relax the cfs quota to the ceil during the enforcement grace after the pod start, and tighten it to the base
+	// once the grace ends
+	inGrace := cfsQuotaBurstEnabled(burstCfg.Policy) && b.resmanager.isPodInEnforcementGrace(pod, b.clock.Now())
+	_, graceRelaxed := b.graceRelaxedPods[string(pod.UID)]
+	graceEnded := !inGrace && graceRelaxed
+	podInBurst, podThrottled := false, false
 	containerMap := make(map[string]*corev1.Container)
 	for i := range pod.Spec.Containers {
 		container := &pod.Spec.Containers[i]
@@ -313,8 +403,29 @@ func (b *CPUBurst) applyCFSQuotaBurst(burstCfg *slov1alpha1.CPUBurstConfig, podM
 		if burstCfg.CFSQuotaBurstPercent != nil && *burstCfg.CFSQuotaBurstPercent > 100 {
 			containerCeilCFS = int64(float64(containerBaseCFS) * float64(*burstCfg.CFSQuotaBurstPercent) / 100)
 		}
+		if nodeState == nodeBurstOverload {
+			// withdraw the burst ceil proportionally to how far the share pool is overloaded
+			containerCeilCFS = containerBaseCFS +
+				int64(float64(containerCeilCFS-containerBaseCFS)*getBurstScaleFactor(b.sharePoolOverloadDegree))
+		}
+
+		if containerCurCFS > containerBaseCFS {
+			podInBurst = true
+		}
 
-		originOperation := b.genOperationByContainer(burstCfg, pod, container, containerStat)
+		var originOperation cfsOperation
+		if inGrace {
+			originOperation = cfsScaleUp
+		} else if graceEnded {
+			originOperation = cfsReset
+		} else if burstAllowedByPeriod {
+			originOperation = b.genOperationByContainer(burstCfg, podMeta, container, containerStat)
+			podThrottled = podThrottled || originOperation == cfsScaleUp
+		} else {
+			// burst is withdrawn since the pod has been in burst longer than the period
+			originOperation = cfsReset
+			podThrottled = podThrottled || b.isContainerThrottled(pod, containerStat)
+		}
 		klog.V(6).Infof("cfs burst operation for container %v/%v/%v is %v",
 			pod.Namespace, pod.Name, containerStat.Name, originOperation)
 
@@ -328,7 +439,9 @@ func (b *CPUBurst) applyCFSQuotaBurst(burstCfg *slov1alpha1.CPUBurstConfig, podM
 		}
 
 		containerTargetCFS := containerCurCFS
-		if finalOperation == cfsScaleUp {
+		if finalOperation == cfsScaleUp && inGrace {
+			containerTargetCFS = containerCeilCFS
+		} else if finalOperation == cfsScaleUp {
 			containerTargetCFS = int64(float64(containerCurCFS) * cfsIncreaseStep)
 		} else if finalOperation == cfsScaleDown {
 			containerTargetCFS = int64(float64(containerCurCFS) * cfsDecreaseStep)
@@ -352,6 +465,76 @@ func (b *CPUBurst) applyCFSQuotaBurst(burstCfg *slov1alpha1.CPUBurstConfig, podM
 		klog.Infof("scale container %v/%v/%v cfs quota success, operation %v, current cfs %v, target cfs %v",
 			pod.Namespace, pod.Name, containerStat.Name, finalOperation, containerCurCFS, containerTargetCFS)
 	} // end for containers
+
+	if inGrace {
+		// the burst in the grace is not accounted in the CFSQuotaBurstPeriodSeconds
+		b.graceRelaxedPods[string(pod.UID)] = b.clock.Now()
+		return
+	}
+	if graceEnded {
+		delete(b.graceRelaxedPods, string(pod.UID))
+	}
+	b.updatePodBurstRecord(burstCfg, pod, podInBurst, podThrottled)
+}
+
+// check if cfs burst for pod is allowed by CFSQuotaBurstPeriodSeconds, return false if the pod has been in burst
+// cumulatively longer than the period and still throttled
+func (b *CPUBurst) cfsBurstAllowedByPeriod(burstCfg *slov1alpha1.CPUBurstConfig, pod *corev1.Pod) bool {
+	if burstCfg.CFSQuotaBurstPeriodSeconds == nil || *burstCfg.CFSQuotaBurstPeriodSeconds < 0 {
+		return true
+	}
+	record, exist := b.podBurstRecords[string(pod.UID)]
+	return !exist || !record.withdrawn
+}
+
+// updatePodBurstRecord accumulates the burst duration of the pod and withdraws the burst once the duration exceeds
+// the CFSQuotaBurstPeriodSeconds; the withdrawn pod restarts the accounting when it is no longer throttled
+func (b *CPUBurst) updatePodBurstRecord(burstCfg *slov1alpha1.CPUBurstConfig, pod *corev1.Pod, inBurst, throttled bool) {
+	podUID := string(pod.UID)
+	if burstCfg.CFSQuotaBurstPeriodSeconds == nil || *burstCfg.CFSQuotaBurstPeriodSeconds < 0 {
+		delete(b.podBurstRecords, podUID)
+		return
+	}
+	now := b.clock.Now()
+	record, exist := b.podBurstRecords[podUID]
+	if !exist {
+		record = &podBurstRecord{lastUpdateTime: now}
+		b.podBurstRecords[podUID] = record
+	}
+
+	if record.withdrawn {
+		if !throttled {
+			klog.Infof("pod %v/%v is not throttled after burst withdrawn, restart the burst accounting",
+				pod.Namespace, pod.Name)
+			record.burstDuration = 0
+			record.withdrawn = false
+		}
+		record.lastUpdateTime = now
+		return
+	}
+
+	if inBurst {
+		record.burstDuration += now.Sub(record.lastUpdateTime)
+	}
+	record.lastUpdateTime = now
+	burstPeriod := time.Duration(*burstCfg.CFSQuotaBurstPeriodSeconds) * time.Second
+	if record.burstDuration >= burstPeriod {
+		klog.Infof("pod %v/%v has been in burst for %v exceeding the period %v, withdraw the burst",
+			pod.Namespace, pod.Name, record.burstDuration, burstPeriod)
+		record.withdrawn = true
+	}
+}
+
+func (b *CPUBurst) isContainerThrottled(pod *corev1.Pod, containerStat *corev1.ContainerStatus) bool {
+	containerThrottled := b.resmanager.collectContainerThrottledMetricLast(&containerStat.ContainerID)
+	if containerThrottled.Error != nil || containerThrottled.Metric == nil ||
+		containerThrottled.Metric.CPUThrottledMetric == nil {
+		klog.V(5).Infof("container %s/%s/%s throttled metric is invalid, detail %v",
+			pod.Namespace, pod.Name, containerStat.Name, containerThrottled)
+		// keep the burst withdrawn if the throttled state is unknown
+		return true
+	}
+	return containerThrottled.Metric.CPUThrottledMetric.ThrottledRatio > 0
 }
 
 // check if cfs burst for container is allowed by limiter config, return true if allowed
@@ -389,9 +572,9 @@ func (b *CPUBurst) cfsBurstAllowedByLimiter(burstCfg *slov1alpha1.CPUBurstConfig
 	return allowed
 }
 
-func (b *CPUBurst) genOperationByContainer(burstCfg *slov1alpha1.CPUBurstConfig, pod *corev1.Pod,
+func (b *CPUBurst) genOperationByContainer(burstCfg *slov1alpha1.CPUBurstConfig, podMeta *statesinformer.PodMeta,
 	container *corev1.Container, containerStat *corev1.ContainerStatus) cfsOperation {
-
+	pod := podMeta.Pod
 	allowedByLimiterCfg := b.cfsBurstAllowedByLimiter(burstCfg, container, &containerStat.ContainerID)
 	if !cfsQuotaBurstEnabled(burstCfg.Policy) {
 		return cfsReset
@@ -400,6 +583,16 @@ func (b *CPUBurst) genOperationByContainer(burstCfg *slov1alpha1.CPUBurstConfig,
 		return cfsScaleDown
 	}
 
+	if burstCfg.Policy == slov1alpha1.CFSQuotaBurstOnly {
+		// only scale up the throttled containers, and step down to the baseline once the throttling stops
+		if throttled, ok := b.isContainerThrottledByCPUStat(podMeta, containerStat); ok {
+			if throttled {
+				return cfsScaleUp
+			}
+			return b.genBurstOnlyOperationForUnthrottled(pod, containerStat)
+		}
+	}
+
 	containerThrottled := b.resmanager.collectContainerThrottledMetricLast(&containerStat.ContainerID)
 	if containerThrottled.Error != nil {
 		klog.Warningf("failed to get container %s/%s/%s throttled metric, skip this round, error %v",
@@ -414,13 +607,72 @@ func (b *CPUBurst) genOperationByContainer(burstCfg *slov1alpha1.CPUBurstConfig,
 	}
 
 	if containerThrottled.Metric.CPUThrottledMetric.ThrottledRatio > 0 {
+		if cpuStat, ok := b.containerCPUStats[containerStat.ContainerID]; ok {
+			cpuStat.lastThrottledTime = b.clock.Now()
+		}
 		return cfsScaleUp
 	}
+	if burstCfg.Policy == slov1alpha1.CFSQuotaBurstOnly {
+		return b.genBurstOnlyOperationForUnthrottled(pod, containerStat)
+	}
 	klog.V(5).Infof("container %s/%s/%s is not throttled, no need to scale up cfs quota",
 		pod.Namespace, pod.Name, containerStat.Name)
 	return cfsRemain
 }
 
+// isContainerThrottledByCPUStat checks if the nr_throttled or throttled_time in the container cpu.stat increases
+// since the last round, return ok=false if the throttling is unknown, e.g. the first point or the read failure
+func (b *CPUBurst) isContainerThrottledByCPUStat(podMeta *statesinformer.PodMeta,
+	containerStat *corev1.ContainerStatus) (throttled bool, ok bool) {
+	pod := podMeta.Pod
+	statPath, err := util.GetContainerCgroupCPUStatPath(podMeta.CgroupDir, containerStat)
+	if err != nil {
+		klog.V(5).Infof("get container %s/%s/%s cpu.stat path failed, error %v",
+			pod.Namespace, pod.Name, containerStat.Name, err)
+		return false, false
+	}
+	curStat, err := system.GetCPUStatRaw(statPath)
+	if err != nil {
+		klog.V(5).Infof("read container %s/%s/%s cpu.stat failed, error %v",
+			pod.Namespace, pod.Name, containerStat.Name, err)
+		return false, false
+	}
+	now := b.clock.Now()
+	lastStat, exist := b.containerCPUStats[containerStat.ContainerID]
+	cpuStat := &containerCPUStat{stat: curStat, lastUpdateTime: now}
+	if exist {
+		cpuStat.lastThrottledTime = lastStat.lastThrottledTime
+	}
+	b.containerCPUStats[containerStat.ContainerID] = cpuStat
+	if !exist || curStat.NrThrottled < lastStat.stat.NrThrottled ||
+		curStat.ThrottledNanoSeconds < lastStat.stat.ThrottledNanoSeconds {
+		klog.V(6).Infof("container %s/%s/%s cpu.stat has no valid last point", pod.Namespace, pod.Name,
+			containerStat.Name)
+		return false, false
+	}
+	throttled = curStat.NrThrottled > lastStat.stat.NrThrottled ||
+		curStat.ThrottledNanoSeconds > lastStat.stat.ThrottledNanoSeconds
+	if throttled {
+		cpuStat.lastThrottledTime = now
+	}
+	return throttled, true
+}
+
+// genBurstOnlyOperationForUnthrottled keeps the cfs quota of the unthrottled container in CFSQuotaBurstOnly until the
+// throttling stays absent for cfsQuotaBurstOnlyQuietDuration, and then steps it down to the baseline, so the quota does
+// not flap between the scale-up and the baseline
+func (b *CPUBurst) genBurstOnlyOperationForUnthrottled(pod *corev1.Pod, containerStat *corev1.ContainerStatus) cfsOperation {
+	if cpuStat, ok := b.containerCPUStats[containerStat.ContainerID]; ok && !cpuStat.lastThrottledTime.IsZero() &&
+		b.clock.Since(cpuStat.lastThrottledTime) < cfsQuotaBurstOnlyQuietDuration {
+		klog.V(5).Infof("container %s/%s/%s is not throttled since %v, keep the cfs quota",
+			pod.Namespace, pod.Name, containerStat.Name, cpuStat.lastThrottledTime)
+		return cfsRemain
+	}
+	klog.V(5).Infof("container %s/%s/%s is not throttled, step down cfs quota to the baseline",
+		pod.Namespace, pod.Name, containerStat.Name)
+	return cfsScaleDown
+}
+
 func (b *CPUBurst) applyContainerCFSQuota(podMeta *statesinformer.PodMeta, containerStat *corev1.ContainerStatus,
 	curContaienrCFS, deltaContainerCFS int64) error {
 	curPodCFS, podPathErr := util.GetPodCurCFSQuota(podMeta.CgroupDir)
@@ -542,6 +794,22 @@ func (b *CPUBurst) Recycle() {
 			klog.Infof("recycle limiter for container %v", key)
 		}
 	}
+	for podUID, record := range b.podBurstRecords {
+		if b.clock.Since(record.lastUpdateTime) > podBurstRecordExpireDuration {
+			delete(b.podBurstRecords, podUID)
+			klog.Infof("recycle burst record for pod %v", podUID)
+		}
+	}
+	for podUID, lastRelaxed := range b.graceRelaxedPods {
+		if b.clock.Since(lastRelaxed) > podBurstRecordExpireDuration {
+			delete(b.graceRelaxedPods, podUID)
+		}
+	}
+	for containerID, cpuStat := range b.containerCPUStats {
+		if b.clock.Since(cpuStat.lastUpdateTime) > podBurstRecordExpireDuration {
+			delete(b.containerCPUStats, containerID)
+		}
+	}
 }
 
 // container cpu.cfs_burst_us = container.limit * burstCfg.CPUBurstPercent * cfs_period_us
@@ -573,6 +841,11 @@ func genPodBurstConfig(pod *corev1.Pod, nodeCfg *slov1alpha1.CPUBurstConfig) *sl
 	if podCPUBurstCfg == nil {
 		return nodeCfg
 	}
+	if podCPUBurstCfg.Policy != "" && !isValidCPUBurstPolicy(podCPUBurstCfg.Policy) {
+		klog.Infof("pod %s/%s cpu burst policy %v is invalid, use node config instead",
+			pod.Namespace, pod.Name, podCPUBurstCfg.Policy)
+		return nodeCfg
+	}
 	if nodeCfg == nil {
 		return podCPUBurstCfg
 	}
@@ -583,6 +856,127 @@ func genPodBurstConfig(pod *corev1.Pod, nodeCfg *slov1alpha1.CPUBurstConfig) *sl
 	return out
 }
 
+// scalePodBurstConfigByPriority scales the burst headroom of the pod by its priority with the priority scale, i.e. the
+// CPUBurstPercent and the part of CFSQuotaBurstPercent above 100, while the percentages specified by the pod are kept.
+// The burst config keeps unchanged if the scale is not set or the pod has no priority.
+func scalePodBurstConfigByPriority(pod *corev1.Pod, burstCfg *slov1alpha1.CPUBurstConfig,
+	scale *slov1alpha1.CPUBurstPriorityScale) *slov1alpha1.CPUBurstConfig {
+	if burstCfg == nil || scale == nil || pod.Spec.Priority == nil {
+		return burstCfg
+	}
+	scalePercent, ok := getBurstPriorityScalePercent(*pod.Spec.Priority, scale)
+	if !ok || scalePercent == 100 {
+		return burstCfg
+	}
+	// the pod config is ignored if its policy is invalid, see genPodBurstConfig
+	podCfg, err := apiext.GetPodCPUBurstConfig(pod)
+	if err != nil || (podCfg != nil && podCfg.Policy != "" && !isValidCPUBurstPolicy(podCfg.Policy)) {
+		podCfg = nil
+	}
+
+	out := burstCfg.DeepCopy()
+	if out.CPUBurstPercent != nil && (podCfg == nil || podCfg.CPUBurstPercent == nil) {
+		cpuBurstPercent := *out.CPUBurstPercent * scalePercent / 100
+		if cpuBurstPercent > maxCPUBurstPercent {
+			cpuBurstPercent = maxCPUBurstPercent
+		}
+		out.CPUBurstPercent = &cpuBurstPercent
+	}
+	if out.CFSQuotaBurstPercent != nil && *out.CFSQuotaBurstPercent > 100 &&
+		(podCfg == nil || podCfg.CFSQuotaBurstPercent == nil) {
+		cfsQuotaBurstPercent := 100 + (*out.CFSQuotaBurstPercent-100)*scalePercent/100
+		out.CFSQuotaBurstPercent = &cfsQuotaBurstPercent
+	}
+	klog.V(5).Infof("scale cpu burst config of pod %s/%s by %v%% for priority %v", pod.Namespace, pod.Name,
+		scalePercent, *pod.Spec.Priority)
+	return out
+}
+
+// getBurstPriorityScalePercent returns the scale percentage interpolated linearly by the priority between MinPriority
+// and MaxPriority, and false if the scale is invalid.
+func getBurstPriorityScalePercent(priority int32, scale *slov1alpha1.CPUBurstPriorityScale) (int64, bool) {
+	if scale.MinPriority == nil || scale.MaxPriority == nil || *scale.MinPriority >= *scale.MaxPriority {
+		return 0, false
+	}
+	minScalePercent, maxScalePercent := int64(100), int64(100)
+	if scale.MinScalePercent != nil {
+		minScalePercent = *scale.MinScalePercent
+	}
+	if scale.MaxScalePercent != nil {
+		maxScalePercent = *scale.MaxScalePercent
+	}
+	if minScalePercent < 0 || maxScalePercent < 0 {
+		return 0, false
+	}
+	if priority <= *scale.MinPriority {
+		return minScalePercent, true
+	}
+	if priority >= *scale.MaxPriority {
+		return maxScalePercent, true
+	}
+	return minScalePercent + (maxScalePercent-minScalePercent)*int64(priority-*scale.MinPriority)/
+		int64(*scale.MaxPriority-*scale.MinPriority), true
+}
+
+// isPodCPUPinnedByKubelet returns whether any container of the pod is pinned to the exclusive cpus by the static
+// policy of the kubelet cpu manager, i.e. the pod is Guaranteed, the container requests integer cpus and its cpuset is
+// narrower than the cpuset of the kubepods cgroup. The cfs settings of the pinned pods are left to the kubelet.
+func isPodCPUPinnedByKubelet(podMeta *statesinformer.PodMeta) bool {
+	pod := podMeta.Pod
+	if util.GetKubeQosClass(pod) != corev1.PodQOSGuaranteed {
+		return false
+	}
+	var rootCPUSet []int32
+	containerMap := make(map[string]*corev1.Container, len(pod.Spec.Containers))
+	for i := range pod.Spec.Containers {
+		containerMap[pod.Spec.Containers[i].Name] = &pod.Spec.Containers[i]
+	}
+	for i := range pod.Status.ContainerStatuses {
+		containerStat := &pod.Status.ContainerStatuses[i]
+		container, exist := containerMap[containerStat.Name]
+		if !exist || container == nil {
+			continue
+		}
+		cpuMilli := container.Resources.Requests.Cpu().MilliValue()
+		if cpuMilli <= 0 || cpuMilli%1000 != 0 {
+			continue
+		}
+		containerDir, err := util.GetContainerCgroupPathWithKube(podMeta.CgroupDir, containerStat)
+		if err != nil {
+			continue
+		}
+		content, err := system.CgroupFileRead(containerDir, system.CPUSet)
+		if err != nil {
+			klog.V(5).Infof("failed to read cpuset of container %v/%v/%v, regard it as not pinned, error %v",
+				pod.Namespace, pod.Name, containerStat.Name, err)
+			continue
+		}
+		containerCPUSet, err := util.ParseCPUSetStr(content)
+		if err != nil || len(containerCPUSet) == 0 {
+			continue
+		}
+		if rootCPUSet == nil {
+			if rootCPUSet, err = util.GetRootCgroupCurCPUSet(corev1.PodQOSGuaranteed); err != nil {
+				klog.V(5).Infof("failed to read cpuset of kubepods cgroup, regard pod %v/%v as not pinned, error %v",
+					pod.Namespace, pod.Name, err)
+				return false
+			}
+		}
+		if len(containerCPUSet) < len(rootCPUSet) {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidCPUBurstPolicy(burstPolicy slov1alpha1.CPUBurstPolicy) bool {
+	switch burstPolicy {
+	case slov1alpha1.CPUBurstNone, slov1alpha1.CPUBurstOnly, slov1alpha1.CFSQuotaBurstOnly, slov1alpha1.CPUBurstAuto:
+		return true
+	}
+	return false
+}
+
 func cpuBurstEnabled(burstPolicy slov1alpha1.CPUBurstPolicy) bool {
 	return burstPolicy == slov1alpha1.CPUBurstAuto || burstPolicy == slov1alpha1.CPUBurstOnly
 }
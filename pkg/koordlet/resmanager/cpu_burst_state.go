@@ -0,0 +1,147 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+var (
+	// defaultBurstStates keeps the cpu burst states of pods applied in the latest round of the CPUBurst, which is
+	// dumped by the debug http handler instead of being exported as metrics to avoid the label cardinality.
+	defaultBurstStates = &burstStateStore{}
+)
+
+// PodBurstState is the current cpu burst state of a pod.
+type PodBurstState struct {
+	Namespace string                     `json:"namespace"`
+	Name      string                     `json:"name"`
+	Policy    slov1alpha1.CPUBurstPolicy `json:"policy"`
+	// CFSBurstUS is the applied `cpu.cfs_burst_us` of the pod, -1 if failed to read
+	CFSBurstUS int64 `json:"cfsBurstUS"`
+	// CFSQuotaUS is the applied `cpu.cfs_quota_us` of the pod, -1 if unlimited or failed to read
+	CFSQuotaUS int64                 `json:"cfsQuotaUS"`
+	Containers []ContainerBurstState `json:"containers,omitempty"`
+}
+
+// ContainerBurstState is the current cpu burst state of a container.
+type ContainerBurstState struct {
+	Name           string `json:"name"`
+	CFSBurstUS     int64  `json:"cfsBurstUS"`
+	CFSQuotaUS     int64  `json:"cfsQuotaUS"`
+	BaseCFSQuotaUS int64  `json:"baseCFSQuotaUS"`
+}
+
+type burstStateStore struct {
+	lock   sync.RWMutex
+	states []PodBurstState
+}
+
+func (s *burstStateStore) set(states []PodBurstState) {
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Namespace != states[j].Namespace {
+			return states[i].Namespace < states[j].Namespace
+		}
+		return states[i].Name < states[j].Name
+	})
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.states = states
+}
+
+func (s *burstStateStore) get() []PodBurstState {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	states := make([]PodBurstState, len(s.states))
+	copy(states, s.states)
+	return states
+}
+
+// CPUBurstStatesHttpHandler returns the http handler to dump the cpu burst states of pods.
+func CPUBurstStatesHttpHandler() func(http.ResponseWriter, *http.Request) {
+	return defaultBurstStates.httpHandler()
+}
+
+func (s *burstStateStore) httpHandler() func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		data, err := json.Marshal(s.get())
+		if err != nil {
+			http.Error(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		if _, err = rw.Write(data); err != nil {
+			klog.Warningf("failed to write cpu burst states to client %v, error %v", r.RemoteAddr, err)
+		}
+	}
+}
+
+// getPodBurstState reads the applied cpu burst values of the pod and its containers from cgroup files.
+func getPodBurstState(burstCfg *slov1alpha1.CPUBurstConfig, podMeta *statesinformer.PodMeta) PodBurstState {
+	pod := podMeta.Pod
+	state := PodBurstState{
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		Policy:     burstCfg.Policy,
+		CFSBurstUS: readCPUBurstValue(util.GetPodCgroupDirWithKube(podMeta.CgroupDir)),
+		CFSQuotaUS: -1,
+	}
+	if podCFSQuota, err := util.GetPodCurCFSQuota(podMeta.CgroupDir); err == nil {
+		state.CFSQuotaUS = podCFSQuota
+	}
+
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		_, containerStat, err := util.FindContainerIdAndStatusByName(&pod.Status, container.Name)
+		if err != nil {
+			continue
+		}
+		containerState := ContainerBurstState{
+			Name:           container.Name,
+			CFSBurstUS:     -1,
+			CFSQuotaUS:     -1,
+			BaseCFSQuotaUS: util.GetContainerBaseCFSQuota(container),
+		}
+		if containerDir, err := util.GetContainerCgroupPathWithKube(podMeta.CgroupDir, containerStat); err == nil {
+			containerState.CFSBurstUS = readCPUBurstValue(containerDir)
+		}
+		if containerCFSQuota, err := util.GetContainerCurCFSQuota(podMeta.CgroupDir, containerStat); err == nil {
+			containerState.CFSQuotaUS = containerCFSQuota
+		}
+		state.Containers = append(state.Containers, containerState)
+	}
+	return state
+}
+
+func readCPUBurstValue(cgroupDir string) int64 {
+	value, err := system.CgroupFileReadInt(cgroupDir, system.CPUBurst)
+	if err != nil || value == nil {
+		klog.V(6).Infof("failed to read cpu burst of %v, error %v", cgroupDir, err)
+		return -1
+	}
+	return *value
+}
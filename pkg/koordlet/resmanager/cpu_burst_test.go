@@ -19,18 +19,23 @@ package resmanager
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+	testingclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
@@ -416,13 +421,45 @@ func TestCPUBurst_getNodeStateForBurst(t *testing.T) {
 				config:         NewDefaultConfig(),
 			}
 			b := NewCPUBurst(resmanager)
-			if got := b.getNodeStateForBurst(tt.args.sharePoolThresholdPercent, podMetas); got != tt.want {
+			if got, _ := b.getNodeStateForBurst(tt.args.sharePoolThresholdPercent, podMetas); got != tt.want {
 				t.Errorf("getNodeStateForBurst() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestCPUBurst_getNodeStateForBurst_emptyPodMetrics(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	pods := []*corev1.Pod{createTestPod(apiext.QoSLS, "ls-pod-1"), createTestPod(apiext.QoSLSR, "lsr-pod-2")}
+	mockstatesinformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockstatesinformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+
+	// the node looks idle, but no pod metric is collected after the metric cache restarts
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			CPUUsed: metriccache.CPUMetric{CPUUsed: *resource.NewQuantity(2, resource.DecimalSI)},
+		},
+	}).AnyTimes()
+	mockMetricCache.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{}).AnyTimes()
+	mockMetricCache.EXPECT().GetNodeCPUInfo(gomock.Any()).Return(testNodeInfo, nil).AnyTimes()
+
+	r := &resmanager{
+		statesInformer: mockstatesinformer,
+		metricCache:    mockMetricCache,
+		config:         NewDefaultConfig(),
+	}
+	b := NewCPUBurst(r)
+	got, overloadDegree := b.getNodeStateForBurst(50, getPodMetas(pods))
+	assert.Equal(t, nodeBurstUnknown, got)
+	assert.Equal(t, float64(0), overloadDegree)
+	// the unknown node state never scales up the cfs quota
+	_, operation := changeOperationByNode(got, cfsScaleUp)
+	assert.Equal(t, cfsRemain, operation)
+}
+
 func getPodMetas(pods []*corev1.Pod) []*statesinformer.PodMeta {
 	podMetas := make([]*statesinformer.PodMeta, len(pods))
 
@@ -505,6 +542,107 @@ func Test_genPodBurstConfig(t *testing.T) {
 				CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
 			},
 		},
+		{
+			name: "override-policy-none",
+			args: args{
+				podCfg: &slov1alpha1.CPUBurstConfig{
+					Policy: slov1alpha1.CPUBurstNone,
+				},
+				nodeCfg: &slov1alpha1.CPUBurstConfig{
+					Policy:                     slov1alpha1.CPUBurstAuto,
+					CPUBurstPercent:            pointer.Int64Ptr(1000),
+					CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+					CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+				},
+			},
+			want: &slov1alpha1.CPUBurstConfig{
+				Policy:                     slov1alpha1.CPUBurstNone,
+				CPUBurstPercent:            pointer.Int64Ptr(1000),
+				CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+				CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+			},
+		},
+		{
+			name: "override-policy-cpu-burst-only",
+			args: args{
+				podCfg: &slov1alpha1.CPUBurstConfig{
+					Policy: slov1alpha1.CPUBurstOnly,
+				},
+				nodeCfg: &slov1alpha1.CPUBurstConfig{
+					Policy:                     slov1alpha1.CPUBurstAuto,
+					CPUBurstPercent:            pointer.Int64Ptr(1000),
+					CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+					CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+				},
+			},
+			want: &slov1alpha1.CPUBurstConfig{
+				Policy:                     slov1alpha1.CPUBurstOnly,
+				CPUBurstPercent:            pointer.Int64Ptr(1000),
+				CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+				CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+			},
+		},
+		{
+			name: "override-policy-cfs-quota-burst-only",
+			args: args{
+				podCfg: &slov1alpha1.CPUBurstConfig{
+					Policy: slov1alpha1.CFSQuotaBurstOnly,
+				},
+				nodeCfg: &slov1alpha1.CPUBurstConfig{
+					Policy:                     slov1alpha1.CPUBurstAuto,
+					CPUBurstPercent:            pointer.Int64Ptr(1000),
+					CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+					CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+				},
+			},
+			want: &slov1alpha1.CPUBurstConfig{
+				Policy:                     slov1alpha1.CFSQuotaBurstOnly,
+				CPUBurstPercent:            pointer.Int64Ptr(1000),
+				CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+				CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+			},
+		},
+		{
+			name: "override-policy-auto",
+			args: args{
+				podCfg: &slov1alpha1.CPUBurstConfig{
+					Policy: slov1alpha1.CPUBurstAuto,
+				},
+				nodeCfg: &slov1alpha1.CPUBurstConfig{
+					Policy:                     slov1alpha1.CPUBurstOnly,
+					CPUBurstPercent:            pointer.Int64Ptr(1000),
+					CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+					CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+				},
+			},
+			want: &slov1alpha1.CPUBurstConfig{
+				Policy:                     slov1alpha1.CPUBurstAuto,
+				CPUBurstPercent:            pointer.Int64Ptr(1000),
+				CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+				CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+			},
+		},
+		{
+			name: "invalid-pod-policy-use-node-config",
+			args: args{
+				podCfg: &slov1alpha1.CPUBurstConfig{
+					Policy:          "unknown",
+					CPUBurstPercent: pointer.Int64Ptr(500),
+				},
+				nodeCfg: &slov1alpha1.CPUBurstConfig{
+					Policy:                     slov1alpha1.CPUBurstAuto,
+					CPUBurstPercent:            pointer.Int64Ptr(1000),
+					CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+					CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+				},
+			},
+			want: &slov1alpha1.CPUBurstConfig{
+				Policy:                     slov1alpha1.CPUBurstAuto,
+				CPUBurstPercent:            pointer.Int64Ptr(1000),
+				CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+				CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(600),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -529,6 +667,125 @@ func Test_genPodBurstConfig(t *testing.T) {
 	}
 }
 
+func Test_scalePodBurstConfigByPriority(t *testing.T) {
+	nodeCfg := &slov1alpha1.CPUBurstConfig{
+		Policy:                     slov1alpha1.CPUBurstAuto,
+		CPUBurstPercent:            pointer.Int64Ptr(1000),
+		CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+		CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(-1),
+	}
+	priorityScale := &slov1alpha1.CPUBurstPriorityScale{
+		MinPriority:     pointer.Int32Ptr(5000),
+		MaxPriority:     pointer.Int32Ptr(9000),
+		MinScalePercent: pointer.Int64Ptr(50),
+		MaxScalePercent: pointer.Int64Ptr(150),
+	}
+	tests := []struct {
+		name                     string
+		priority                 *int32
+		podCfg                   *slov1alpha1.CPUBurstConfig
+		scale                    *slov1alpha1.CPUBurstPriorityScale
+		wantCPUBurstPercent      int64
+		wantCFSQuotaBurstPercent int64
+	}{
+		{
+			name:                     "keep the node config if the scale is not set",
+			priority:                 pointer.Int32Ptr(9000),
+			wantCPUBurstPercent:      1000,
+			wantCFSQuotaBurstPercent: 300,
+		},
+		{
+			name:                     "keep the node config if the pod has no priority",
+			scale:                    priorityScale,
+			wantCPUBurstPercent:      1000,
+			wantCFSQuotaBurstPercent: 300,
+		},
+		{
+			name:                     "scale by min percent below the min priority",
+			priority:                 pointer.Int32Ptr(3000),
+			scale:                    priorityScale,
+			wantCPUBurstPercent:      500,
+			wantCFSQuotaBurstPercent: 200,
+		},
+		{
+			name:                     "scale by min percent at the min priority",
+			priority:                 pointer.Int32Ptr(5000),
+			scale:                    priorityScale,
+			wantCPUBurstPercent:      500,
+			wantCFSQuotaBurstPercent: 200,
+		},
+		{
+			name:                     "scale linearly between the priorities",
+			priority:                 pointer.Int32Ptr(8000),
+			scale:                    priorityScale,
+			wantCPUBurstPercent:      1250,
+			wantCFSQuotaBurstPercent: 350,
+		},
+		{
+			name:                     "scale by max percent above the max priority",
+			priority:                 pointer.Int32Ptr(9999),
+			scale:                    priorityScale,
+			wantCPUBurstPercent:      1500,
+			wantCFSQuotaBurstPercent: 400,
+		},
+		{
+			name:     "keep the percent specified by the pod",
+			priority: pointer.Int32Ptr(9000),
+			podCfg: &slov1alpha1.CPUBurstConfig{
+				CPUBurstPercent: pointer.Int64Ptr(800),
+			},
+			scale:                    priorityScale,
+			wantCPUBurstPercent:      800,
+			wantCFSQuotaBurstPercent: 400,
+		},
+		{
+			name:     "bound the scaled cpu burst percent",
+			priority: pointer.Int32Ptr(9000),
+			scale: &slov1alpha1.CPUBurstPriorityScale{
+				MinPriority:     pointer.Int32Ptr(5000),
+				MaxPriority:     pointer.Int32Ptr(9000),
+				MaxScalePercent: pointer.Int64Ptr(2000),
+			},
+			wantCPUBurstPercent:      10000,
+			wantCFSQuotaBurstPercent: 4100,
+		},
+		{
+			name:     "keep the node config if the scale is invalid",
+			priority: pointer.Int32Ptr(9000),
+			scale: &slov1alpha1.CPUBurstPriorityScale{
+				MinPriority:     pointer.Int32Ptr(9000),
+				MaxPriority:     pointer.Int32Ptr(5000),
+				MaxScalePercent: pointer.Int64Ptr(150),
+			},
+			wantCPUBurstPercent:      1000,
+			wantCFSQuotaBurstPercent: 300,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Annotations: map[string]string{},
+				},
+				Spec: corev1.PodSpec{
+					Priority: tt.priority,
+				},
+			}
+			if tt.podCfg != nil {
+				annoStr, _ := json.Marshal(tt.podCfg)
+				pod.Annotations[apiext.AnnotationPodCPUBurst] = string(annoStr)
+			}
+			got := scalePodBurstConfigByPriority(pod, genPodBurstConfig(pod, nodeCfg), tt.scale)
+			assert.Equal(t, tt.wantCPUBurstPercent, *got.CPUBurstPercent)
+			assert.Equal(t, tt.wantCFSQuotaBurstPercent, *got.CFSQuotaBurstPercent)
+			// the node config is not modified
+			assert.Equal(t, int64(1000), *nodeCfg.CPUBurstPercent)
+			assert.Equal(t, int64(300), *nodeCfg.CFSQuotaBurstPercent)
+		})
+	}
+}
+
 func TestCPUBurst_applyCPUBurst(t *testing.T) {
 	type fields struct {
 		podName      string
@@ -1222,6 +1479,8 @@ func TestCPUBurst_applyCFSQuotaBurst(t *testing.T) {
 				resmanager:       resmanager,
 				executor:         NewResourceUpdateExecutor("CPUBurstTestExecutor", 60),
 				containerLimiter: make(map[string]*burstLimiter),
+				podBurstRecords:  make(map[string]*podBurstRecord),
+				clock:            clock.RealClock{},
 			}
 			_ = b.init(stop)
 			b.applyCFSQuotaBurst(&tt.args.burstCfg, podMeta, tt.args.nodeState)
@@ -1243,6 +1502,565 @@ func TestCPUBurst_applyCFSQuotaBurst(t *testing.T) {
 	}
 }
 
+func TestCPUBurst_applyCFSQuotaBurst_withdrawByPeriod(t *testing.T) {
+	testHelper := system.NewFileTestUtil(t)
+	defer testHelper.Cleanup()
+	stop := make(chan struct{})
+	defer func() { stop <- struct{}{} }()
+
+	testPodName := "test-pod-period"
+	testContainerName := "test-container-period"
+	testContainerID := genTestContainerIDByName(testContainerName)
+	podMeta := createPodMetaByResource(testPodName, map[string]corev1.ResourceRequirements{
+		testContainerName: {
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+			},
+		},
+	})
+	containerStat := &podMeta.Pod.Status.ContainerStatuses[0]
+	baseCFS := 2 * system.CFSBasePeriodValue
+	initPodCFSQuota(podMeta, -1, testHelper)
+	initContainerCFSQuota(podMeta, map[string]int64{testContainerName: baseCFS}, testHelper)
+
+	throttledRatio := 0.5
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetContainerResourceMetric(&testContainerID, gomock.Any()).
+		Return(*genTestContainerResourceQueryResult(testContainerID, 1500, 1000)).AnyTimes()
+	mockMetricCache.EXPECT().GetContainerThrottledMetric(&testContainerID, gomock.Any()).
+		DoAndReturn(func(containerID *string, param *metriccache.QueryParam) metriccache.ContainerThrottledQueryResult {
+			return *genTestContainerThrottledQueryResult(*containerID, throttledRatio)
+		}).AnyTimes()
+
+	burstCfg := slov1alpha1.CPUBurstConfig{
+		Policy:                     slov1alpha1.CFSQuotaBurstOnly,
+		CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+		CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(60),
+	}
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	b := &CPUBurst{
+		resmanager:       &resmanager{metricCache: mockMetricCache},
+		executor:         NewResourceUpdateExecutor("CPUBurstTestExecutor", 60),
+		containerLimiter: make(map[string]*burstLimiter),
+		podBurstRecords:  make(map[string]*podBurstRecord),
+		clock:            fakeClock,
+	}
+	// keep the limiter always allowing, so that only the burst period takes effect
+	b.containerLimiter[testContainerID] = &burstLimiter{
+		bucketCapacity: 60 * 200,
+		currentToken:   60 * 200,
+		lastUpdateTime: time.Now(),
+		expireDuration: time.Hour,
+	}
+	_ = b.init(stop)
+
+	scaleUpCFS := func(times int) int64 {
+		cfs := baseCFS
+		for i := 0; i < times; i++ {
+			cfs = int64(float64(cfs) * cfsIncreaseStep)
+		}
+		return cfs
+	}
+	steps := []struct {
+		name           string
+		elapsed        time.Duration
+		throttledRatio float64
+		wantCFS        int64
+		wantWithdrawn  bool
+	}{
+		{
+			name:           "scale up when throttled",
+			elapsed:        0,
+			throttledRatio: 0.5,
+			wantCFS:        scaleUpCFS(1),
+			wantWithdrawn:  false,
+		},
+		{
+			name:           "keep scaling up within the burst period",
+			elapsed:        30 * time.Second,
+			throttledRatio: 0.5,
+			wantCFS:        scaleUpCFS(2),
+			wantWithdrawn:  false,
+		},
+		{
+			name:           "withdraw after the burst period elapses",
+			elapsed:        31 * time.Second,
+			throttledRatio: 0.5,
+			wantCFS:        scaleUpCFS(3),
+			wantWithdrawn:  true,
+		},
+		{
+			name:           "reset to the baseline when withdrawn",
+			elapsed:        time.Second,
+			throttledRatio: 0.5,
+			wantCFS:        baseCFS,
+			wantWithdrawn:  true,
+		},
+		{
+			name:           "keep the baseline while still throttled",
+			elapsed:        time.Minute,
+			throttledRatio: 0.5,
+			wantCFS:        baseCFS,
+			wantWithdrawn:  true,
+		},
+		{
+			name:           "restart the accounting when not throttled",
+			elapsed:        time.Second,
+			throttledRatio: 0,
+			wantCFS:        baseCFS,
+			wantWithdrawn:  false,
+		},
+		{
+			name:           "scale up again after the accounting restarts",
+			elapsed:        time.Second,
+			throttledRatio: 0.5,
+			wantCFS:        scaleUpCFS(1),
+			wantWithdrawn:  false,
+		},
+	}
+	for _, step := range steps {
+		fakeClock.Step(step.elapsed)
+		throttledRatio = step.throttledRatio
+		b.applyCFSQuotaBurst(&burstCfg, podMeta, nodeBurstIdle)
+
+		got := getContainerCFSQuota(podMeta.CgroupDir, containerStat, testHelper)
+		assert.Equal(t, step.wantCFS, got, step.name)
+		record := b.podBurstRecords[string(podMeta.Pod.UID)]
+		assert.NotNil(t, record, step.name)
+		assert.Equal(t, step.wantWithdrawn, record.withdrawn, step.name)
+	}
+}
+
+func TestCPUBurst_applyCFSQuotaBurst_enforcementGrace(t *testing.T) {
+	testHelper := system.NewFileTestUtil(t)
+	defer testHelper.Cleanup()
+	stop := make(chan struct{})
+	defer func() { stop <- struct{}{} }()
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	testPodName := "test-pod-grace"
+	testContainerName := "test-container-grace"
+	testContainerID := genTestContainerIDByName(testContainerName)
+	podMeta := createPodMetaByResource(testPodName, map[string]corev1.ResourceRequirements{
+		testContainerName: {
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+			},
+		},
+	})
+	podMeta.Pod.Labels = map[string]string{apiext.LabelPodQoS: string(apiext.QoSLS)}
+	podMeta.Pod.Status.StartTime = &metav1.Time{Time: fakeClock.Now()}
+	containerStat := &podMeta.Pod.Status.ContainerStatuses[0]
+	baseCFS := 2 * system.CFSBasePeriodValue
+	initPodCFSQuota(podMeta, -1, testHelper)
+	initContainerCFSQuota(podMeta, map[string]int64{testContainerName: baseCFS}, testHelper)
+
+	// the container is never throttled, so the cfs quota is only scaled up by the grace
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetContainerResourceMetric(&testContainerID, gomock.Any()).
+		Return(*genTestContainerResourceQueryResult(testContainerID, 1500, 1000)).AnyTimes()
+	mockMetricCache.EXPECT().GetContainerThrottledMetric(&testContainerID, gomock.Any()).
+		Return(*genTestContainerThrottledQueryResult(testContainerID, 0)).AnyTimes()
+
+	burstCfg := slov1alpha1.CPUBurstConfig{
+		Policy:                     slov1alpha1.CFSQuotaBurstOnly,
+		CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+		CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(60),
+	}
+	b := &CPUBurst{
+		resmanager: &resmanager{
+			metricCache:      mockMetricCache,
+			enforcementGrace: map[apiext.QoSClass]time.Duration{apiext.QoSLS: time.Minute},
+		},
+		executor:         NewResourceUpdateExecutor("CPUBurstTestExecutor", 60),
+		containerLimiter: make(map[string]*burstLimiter),
+		podBurstRecords:  make(map[string]*podBurstRecord),
+		graceRelaxedPods: make(map[string]time.Time),
+		clock:            fakeClock,
+	}
+	_ = b.init(stop)
+
+	steps := []struct {
+		name      string
+		elapsed   time.Duration
+		nodeState nodeStateForBurst
+		wantCFS   int64
+	}{
+		{
+			name:      "relax to the ceil in the grace",
+			elapsed:   0,
+			nodeState: nodeBurstIdle,
+			wantCFS:   3 * baseCFS,
+		},
+		{
+			name:      "keep the ceil in the grace beyond the burst period",
+			elapsed:   59 * time.Second,
+			nodeState: nodeBurstIdle,
+			wantCFS:   3 * baseCFS,
+		},
+		{
+			name:      "tighten to the base after the grace",
+			elapsed:   time.Second,
+			nodeState: nodeBurstIdle,
+			wantCFS:   baseCFS,
+		},
+		{
+			name:      "keep the base while not throttled",
+			elapsed:   time.Second,
+			nodeState: nodeBurstIdle,
+			wantCFS:   baseCFS,
+		},
+	}
+	for _, step := range steps {
+		fakeClock.Step(step.elapsed)
+		b.applyCFSQuotaBurst(&burstCfg, podMeta, step.nodeState)
+
+		got := getContainerCFSQuota(podMeta.CgroupDir, containerStat, testHelper)
+		assert.Equal(t, step.wantCFS, got, step.name)
+	}
+	// the burst in the grace is not accounted
+	record := b.podBurstRecords[string(podMeta.Pod.UID)]
+	assert.NotNil(t, record)
+	assert.Equal(t, time.Duration(0), record.burstDuration)
+	assert.Len(t, b.graceRelaxedPods, 0)
+
+	// the node overload takes precedence over the grace
+	podMeta.Pod.Status.StartTime = &metav1.Time{Time: fakeClock.Now()}
+	b.sharePoolOverloadDegree = 1
+	b.applyCFSQuotaBurst(&burstCfg, podMeta, nodeBurstOverload)
+	assert.Equal(t, baseCFS, getContainerCFSQuota(podMeta.CgroupDir, containerStat, testHelper))
+}
+
+func TestCPUBurst_applyCFSQuotaBurst_throttledByCPUStat(t *testing.T) {
+	testHelper := system.NewFileTestUtil(t)
+	defer testHelper.Cleanup()
+	stop := make(chan struct{})
+	defer func() { stop <- struct{}{} }()
+
+	containerRes := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+		},
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+		},
+	}
+	throttledContainerName := "test-container-throttled"
+	idleContainerName := "test-container-idle"
+	throttledPodMeta := createPodMetaByResource("test-pod-throttled",
+		map[string]corev1.ResourceRequirements{throttledContainerName: containerRes})
+	idlePodMeta := createPodMetaByResource("test-pod-idle",
+		map[string]corev1.ResourceRequirements{idleContainerName: containerRes})
+	baseCFS := 2 * system.CFSBasePeriodValue
+	for _, podMeta := range []*statesinformer.PodMeta{throttledPodMeta, idlePodMeta} {
+		initPodCFSQuota(podMeta, -1, testHelper)
+		initContainerCFSQuota(podMeta, map[string]int64{podMeta.Pod.Spec.Containers[0].Name: baseCFS}, testHelper)
+	}
+	writeCPUStat := func(podMeta *statesinformer.PodMeta, nrPeriods, nrThrottled, throttledTime int64) {
+		containerPath, _ := util.GetContainerCgroupPathWithKube(podMeta.CgroupDir,
+			&podMeta.Pod.Status.ContainerStatuses[0])
+		testHelper.WriteCgroupFileContents(containerPath, system.CPUStat,
+			fmt.Sprintf("nr_periods %d\nnr_throttled %d\nthrottled_time %d\n", nrPeriods, nrThrottled, throttledTime))
+	}
+
+	// the collected throttled metric is only used in the first round when no cpu.stat point is recorded
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetContainerResourceMetric(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(containerID *string, param *metriccache.QueryParam) metriccache.ContainerResourceQueryResult {
+			return *genTestContainerResourceQueryResult(*containerID, 1500, 1000)
+		}).AnyTimes()
+	mockMetricCache.EXPECT().GetContainerThrottledMetric(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(containerID *string, param *metriccache.QueryParam) metriccache.ContainerThrottledQueryResult {
+			return *genTestContainerThrottledQueryResult(*containerID, 0)
+		}).AnyTimes()
+
+	burstCfg := slov1alpha1.CPUBurstConfig{
+		Policy:                     slov1alpha1.CFSQuotaBurstOnly,
+		CFSQuotaBurstPercent:       pointer.Int64Ptr(300),
+		CFSQuotaBurstPeriodSeconds: pointer.Int64Ptr(-1),
+	}
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	b := &CPUBurst{
+		resmanager:        &resmanager{metricCache: mockMetricCache},
+		executor:          NewResourceUpdateExecutor("CPUBurstTestExecutor", 60),
+		containerLimiter:  make(map[string]*burstLimiter),
+		podBurstRecords:   make(map[string]*podBurstRecord),
+		containerCPUStats: make(map[string]*containerCPUStat),
+		clock:             fakeClock,
+	}
+	_ = b.init(stop)
+
+	scaledUpCFS := int64(float64(int64(float64(baseCFS)*cfsIncreaseStep)) * cfsIncreaseStep)
+	reScaledUpCFS := int64(float64(int64(float64(scaledUpCFS)*cfsDecreaseStep)) * cfsIncreaseStep)
+	steps := []struct {
+		name                string
+		elapsed             time.Duration
+		throttledStat       [3]int64
+		idleStat            [3]int64
+		wantThrottledPodCFS int64
+		wantIdlePodCFS      int64
+	}{
+		{
+			name:                "keep the baseline for the first cpu.stat point",
+			throttledStat:       [3]int64{100, 10, 1000},
+			idleStat:            [3]int64{100, 0, 0},
+			wantThrottledPodCFS: baseCFS,
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "scale up only the throttled pod",
+			throttledStat:       [3]int64{200, 30, 5000},
+			idleStat:            [3]int64{200, 0, 0},
+			wantThrottledPodCFS: int64(float64(baseCFS) * cfsIncreaseStep),
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "keep scaling up when the throttled time increases",
+			throttledStat:       [3]int64{300, 30, 8000},
+			idleStat:            [3]int64{300, 0, 0},
+			wantThrottledPodCFS: scaledUpCFS,
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "keep the scaled quota when the throttling just stops",
+			elapsed:             20 * time.Second,
+			throttledStat:       [3]int64{400, 30, 8000},
+			idleStat:            [3]int64{400, 0, 0},
+			wantThrottledPodCFS: scaledUpCFS,
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "keep the scaled quota within the quiet duration",
+			elapsed:             cfsQuotaBurstOnlyQuietDuration - 30*time.Second,
+			throttledStat:       [3]int64{500, 30, 8000},
+			idleStat:            [3]int64{500, 0, 0},
+			wantThrottledPodCFS: scaledUpCFS,
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "step down after the throttling stays absent for the quiet duration",
+			elapsed:             20 * time.Second,
+			throttledStat:       [3]int64{600, 30, 8000},
+			idleStat:            [3]int64{600, 0, 0},
+			wantThrottledPodCFS: int64(float64(scaledUpCFS) * cfsDecreaseStep),
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "scale up again once throttled",
+			elapsed:             20 * time.Second,
+			throttledStat:       [3]int64{700, 40, 9000},
+			idleStat:            [3]int64{700, 0, 0},
+			wantThrottledPodCFS: reScaledUpCFS,
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "keep the scaled quota within the quiet duration since the last throttling",
+			elapsed:             cfsQuotaBurstOnlyQuietDuration - time.Second,
+			throttledStat:       [3]int64{800, 40, 9000},
+			idleStat:            [3]int64{800, 0, 0},
+			wantThrottledPodCFS: reScaledUpCFS,
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "step down after the throttling stays absent for the quiet duration again",
+			elapsed:             time.Second,
+			throttledStat:       [3]int64{900, 40, 9000},
+			idleStat:            [3]int64{900, 0, 0},
+			wantThrottledPodCFS: int64(float64(reScaledUpCFS) * cfsDecreaseStep),
+			wantIdlePodCFS:      baseCFS,
+		},
+		{
+			name:                "step down to the baseline at most",
+			elapsed:             20 * time.Second,
+			throttledStat:       [3]int64{1000, 40, 9000},
+			idleStat:            [3]int64{1000, 0, 0},
+			wantThrottledPodCFS: baseCFS,
+			wantIdlePodCFS:      baseCFS,
+		},
+	}
+	for _, step := range steps {
+		fakeClock.Step(step.elapsed)
+		writeCPUStat(throttledPodMeta, step.throttledStat[0], step.throttledStat[1], step.throttledStat[2])
+		writeCPUStat(idlePodMeta, step.idleStat[0], step.idleStat[1], step.idleStat[2])
+		b.applyCFSQuotaBurst(&burstCfg, throttledPodMeta, nodeBurstIdle)
+		b.applyCFSQuotaBurst(&burstCfg, idlePodMeta, nodeBurstIdle)
+
+		assert.Equal(t, step.wantThrottledPodCFS, getContainerCFSQuota(throttledPodMeta.CgroupDir,
+			&throttledPodMeta.Pod.Status.ContainerStatuses[0], testHelper), step.name)
+		assert.Equal(t, step.wantIdlePodCFS, getContainerCFSQuota(idlePodMeta.CgroupDir,
+			&idlePodMeta.Pod.Status.ContainerStatuses[0], testHelper), step.name)
+	}
+	assert.Len(t, b.containerCPUStats, 2)
+}
+
+func Test_getSharePoolOverloadDegree(t *testing.T) {
+	sharePoolThresholdRatio := 0.6
+	tests := []struct {
+		name            string
+		usageRatio      float64
+		wantDegree      float64
+		wantScaleFactor float64
+	}{
+		{
+			name:            "below threshold",
+			usageRatio:      0.5,
+			wantDegree:      0,
+			wantScaleFactor: 1,
+		},
+		{
+			name:            "at threshold",
+			usageRatio:      0.6,
+			wantDegree:      0,
+			wantScaleFactor: 1,
+		},
+		{
+			name:            "slightly overloaded",
+			usageRatio:      0.7,
+			wantDegree:      0.25,
+			wantScaleFactor: 0.75,
+		},
+		{
+			name:            "half overloaded",
+			usageRatio:      0.8,
+			wantDegree:      0.5,
+			wantScaleFactor: 0.5,
+		},
+		{
+			name:            "heavily overloaded",
+			usageRatio:      0.9,
+			wantDegree:      0.75,
+			wantScaleFactor: 0.25,
+		},
+		{
+			name:            "full usage",
+			usageRatio:      1,
+			wantDegree:      1,
+			wantScaleFactor: 0,
+		},
+		{
+			name:            "usage beyond share pool",
+			usageRatio:      1.2,
+			wantDegree:      1,
+			wantScaleFactor: 0,
+		},
+	}
+	lastScaleFactor := 1.0
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDegree := getSharePoolOverloadDegree(tt.usageRatio, sharePoolThresholdRatio)
+			assert.InDelta(t, tt.wantDegree, gotDegree, 1e-9)
+			gotScaleFactor := getBurstScaleFactor(gotDegree)
+			assert.InDelta(t, tt.wantScaleFactor, gotScaleFactor, 1e-9)
+			assert.LessOrEqual(t, gotScaleFactor, lastScaleFactor, "scale factor should decrease with usage")
+			lastScaleFactor = gotScaleFactor
+		})
+	}
+
+	t.Run("threshold at full usage", func(t *testing.T) {
+		assert.Equal(t, float64(0), getSharePoolOverloadDegree(1, 1))
+		assert.Equal(t, float64(1), getSharePoolOverloadDegree(1.1, 1))
+	})
+}
+
+func TestCPUBurst_applyCFSQuotaBurst_throttleBySharePoolOverload(t *testing.T) {
+	testPodName := "test-pod-overload"
+	testContainerName := "test-container-overload"
+	testContainerID := genTestContainerIDByName(testContainerName)
+	baseCFS := 2 * system.CFSBasePeriodValue
+	ceilCFS := 3 * baseCFS
+	burstCfg := slov1alpha1.CPUBurstConfig{
+		Policy:               slov1alpha1.CFSQuotaBurstOnly,
+		CFSQuotaBurstPercent: pointer.Int64Ptr(300),
+	}
+	tests := []struct {
+		name           string
+		overloadDegree float64
+		wantCFS        int64
+	}{
+		{
+			name:           "scale down by step when not limited by the overload degree",
+			overloadDegree: 0,
+			wantCFS:        int64(float64(ceilCFS) * cfsDecreaseStep),
+		},
+		{
+			name:           "scale down by step when the scaled ceil is above",
+			overloadDegree: 0.25,
+			wantCFS:        int64(float64(ceilCFS) * cfsDecreaseStep),
+		},
+		{
+			name:           "withdraw half of the burst",
+			overloadDegree: 0.5,
+			wantCFS:        baseCFS + (ceilCFS-baseCFS)/2,
+		},
+		{
+			name:           "withdraw most of the burst",
+			overloadDegree: 0.75,
+			wantCFS:        baseCFS + (ceilCFS-baseCFS)/4,
+		},
+		{
+			name:           "withdraw all of the burst",
+			overloadDegree: 1,
+			wantCFS:        baseCFS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testHelper := system.NewFileTestUtil(t)
+			defer testHelper.Cleanup()
+			stop := make(chan struct{})
+			defer func() { stop <- struct{}{} }()
+
+			podMeta := createPodMetaByResource(testPodName, map[string]corev1.ResourceRequirements{
+				testContainerName: {
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+					},
+				},
+			})
+			containerStat := &podMeta.Pod.Status.ContainerStatuses[0]
+			initPodCFSQuota(podMeta, -1, testHelper)
+			initContainerCFSQuota(podMeta, map[string]int64{testContainerName: ceilCFS}, testHelper)
+
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetContainerResourceMetric(&testContainerID, gomock.Any()).
+				Return(*genTestContainerResourceQueryResult(testContainerID, 1500, 1000)).AnyTimes()
+			mockMetricCache.EXPECT().GetContainerThrottledMetric(&testContainerID, gomock.Any()).
+				Return(*genTestContainerThrottledQueryResult(testContainerID, 0.5)).AnyTimes()
+
+			b := &CPUBurst{
+				resmanager:              &resmanager{metricCache: mockMetricCache},
+				executor:                NewResourceUpdateExecutor("CPUBurstTestExecutor", 60),
+				containerLimiter:        make(map[string]*burstLimiter),
+				podBurstRecords:         make(map[string]*podBurstRecord),
+				clock:                   clock.RealClock{},
+				sharePoolOverloadDegree: tt.overloadDegree,
+			}
+			_ = b.init(stop)
+			b.applyCFSQuotaBurst(&burstCfg, podMeta, nodeBurstOverload)
+
+			got := getContainerCFSQuota(podMeta.CgroupDir, containerStat, testHelper)
+			assert.Equal(t, tt.wantCFS, got)
+		})
+	}
+}
+
 func genTestContainerResourceQueryResult(containerID string, cpuMilliUsage,
 	memUsage int64) *metriccache.ContainerResourceQueryResult {
 	return &metriccache.ContainerResourceQueryResult{
@@ -1453,6 +2271,7 @@ func TestCPUBurst_start(t *testing.T) {
 		containerCurCFSQuota map[string]int64
 		containerMetric      map[string]metriccache.ContainerResourceQueryResult
 		containerThrottled   map[string]metriccache.ContainerThrottledQueryResult
+		memoryPressure       bool
 	}
 	type want struct {
 		podBurstVal          map[string]int64
@@ -1530,6 +2349,72 @@ func TestCPUBurst_start(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "back-off-for-normal-pod-under-memory-eviction",
+			fields: fields{
+				nodeMetric: metriccache.NodeResourceQueryResult{
+					QueryResult: metriccache.QueryResult{},
+					Metric: &metriccache.NodeResourceMetric{
+						CPUUsed: metriccache.CPUMetric{
+							CPUUsed: *resource.NewQuantity(10, resource.DecimalSI),
+						},
+						MemoryUsed: metriccache.MemoryMetric{},
+					},
+				},
+				podsMetric: map[string]metriccache.PodResourceQueryResult{
+					lsrPodName: *newPodUsage(lsrPodName, 7000, 7000),
+					lsPodName:  *newPodUsage(lsPodName, 200, 200),
+				},
+				nodeCPUInfo: testNodeInfo,
+				pods: []*corev1.Pod{
+					newTestPodWithQOS(lsrPodName, apiext.QoSLSR, 8000, 8000),
+					newTestPodWithQOS(lsPodName, apiext.QoSLS, 1000, 1000),
+				},
+				nodeSLO: &slov1alpha1.NodeSLO{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node-1",
+					},
+					Spec: slov1alpha1.NodeSLOSpec{
+						CPUBurstStrategy: defaultAutoBurstStrategy,
+					},
+				},
+				podsCurCFSQuota: map[string]int64{
+					lsrPodName: -1,
+					lsPodName:  2 * system.CFSBasePeriodValue,
+				},
+				containerCurCFSQuota: map[string]int64{
+					lsrContainerName: -1,
+					lsContainerName:  2 * system.CFSBasePeriodValue,
+				},
+				containerMetric: map[string]metriccache.ContainerResourceQueryResult{
+					lsrContainerID: *genTestContainerResourceQueryResult(lsrContainerID, 6000, 6000),
+					lsContainerID:  *genTestContainerResourceQueryResult(lsContainerID, 150, 100),
+				},
+				containerThrottled: map[string]metriccache.ContainerThrottledQueryResult{
+					lsrContainerID: *genTestContainerThrottledQueryResult(lsrContainerID, 0),
+					lsContainerID:  *genTestContainerThrottledQueryResult(lsContainerID, 0.5),
+				},
+				memoryPressure: true,
+			},
+			want: want{
+				podBurstVal: map[string]int64{
+					lsrPodName: 0,
+					lsPodName:  1 * 10 * system.CFSBasePeriodValue,
+				},
+				podCFSQuotaVal: map[string]int64{
+					lsrPodName: -1,
+					lsPodName:  1 * system.CFSBasePeriodValue,
+				},
+				containerBurstVal: map[string]int64{
+					lsrContainerName: 0,
+					lsContainerName:  1 * 10 * system.CFSBasePeriodValue,
+				},
+				containerCFSQuotaVal: map[string]int64{
+					lsrContainerName: -1,
+					lsContainerName:  1 * system.CFSBasePeriodValue,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1564,11 +2449,16 @@ func TestCPUBurst_start(t *testing.T) {
 				kubeClient:     client,
 				nodeSLO:        tt.fields.nodeSLO,
 			}
+			if tt.fields.memoryPressure {
+				resmanager.pressureState = &nodePressureState{clock: clock.RealClock{}}
+				resmanager.pressureState.setMemoryPressure(time.Minute)
+			}
 
 			testHelper := system.NewFileTestUtil(t)
 			defer testHelper.Cleanup()
 
 			b := NewCPUBurst(resmanager)
+			b.burstStates = &burstStateStore{}
 			stop := make(chan struct{})
 			b.init(stop)
 			defer func() { stop <- struct{}{} }()
@@ -1616,6 +2506,188 @@ func TestCPUBurst_start(t *testing.T) {
 					}
 				}
 			}
+
+			// the dumped burst states should reflect the applied values, where LSR and BE pods are ignored
+			var wantStates []PodBurstState
+			for _, podMeta := range podMetas {
+				podQOS := apiext.GetPodQoSClass(podMeta.Pod)
+				if podQOS == apiext.QoSLSR || podQOS == apiext.QoSBE {
+					continue
+				}
+				wantState := PodBurstState{
+					Namespace:  podMeta.Pod.Namespace,
+					Name:       podMeta.Pod.Name,
+					Policy:     tt.fields.nodeSLO.Spec.CPUBurstStrategy.Policy,
+					CFSBurstUS: tt.want.podBurstVal[podMeta.Pod.Name],
+					CFSQuotaUS: tt.want.podCFSQuotaVal[podMeta.Pod.Name],
+				}
+				for i := range podMeta.Pod.Spec.Containers {
+					container := &podMeta.Pod.Spec.Containers[i]
+					wantState.Containers = append(wantState.Containers, ContainerBurstState{
+						Name:           container.Name,
+						CFSBurstUS:     tt.want.containerBurstVal[container.Name],
+						CFSQuotaUS:     tt.want.containerCFSQuotaVal[container.Name],
+						BaseCFSQuotaUS: util.GetContainerBaseCFSQuota(container),
+					})
+				}
+				wantStates = append(wantStates, wantState)
+			}
+			recorder := httptest.NewRecorder()
+			b.burstStates.httpHandler()(recorder, httptest.NewRequest(http.MethodGet, "/debug/cpuburst", nil))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			var gotStates []PodBurstState
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &gotStates))
+			assert.Equal(t, wantStates, gotStates)
+		})
+	}
+}
+
+func TestCPUBurst_start_skipPinnedPod(t *testing.T) {
+	pinnedPodName := "ls-pod-pinned"
+	sharedPodName := "ls-pod-shared"
+	pinnedContainerID := genTestDefaultContainerIDByPod(pinnedPodName)
+	sharedContainerID := genTestDefaultContainerIDByPod(sharedPodName)
+	pods := []*corev1.Pod{
+		newTestPodWithQOS(pinnedPodName, apiext.QoSLS, 2000, 2000),
+		newTestPodWithQOS(sharedPodName, apiext.QoSLS, 2000, 2000),
+	}
+	podMetas := getPodMetas(pods)
+	// the pinned container is assigned the exclusive cpus by the kubelet, while the shared one takes all cpus
+	containerCPUSets := map[string]string{
+		pinnedPodName: "2-3",
+		sharedPodName: "0-7",
+	}
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			CPUUsed: metriccache.CPUMetric{CPUUsed: *resource.NewQuantity(1, resource.DecimalSI)},
+		},
+	}).AnyTimes()
+	for _, podName := range []string{pinnedPodName, sharedPodName} {
+		podMetric := *newPodUsage(podName, 200, 200)
+		mockMetricCache.EXPECT().GetPodResourceMetric(&podMetric.Metric.PodUID, gomock.Any()).Return(podMetric).AnyTimes()
+	}
+	mockMetricCache.EXPECT().GetNodeCPUInfo(gomock.Any()).Return(testNodeInfo, nil).AnyTimes()
+	for _, containerID := range []string{pinnedContainerID, sharedContainerID} {
+		containerMetric := *genTestContainerResourceQueryResult(containerID, 150, 100)
+		mockMetricCache.EXPECT().GetContainerResourceMetric(&containerMetric.Metric.ContainerID,
+			gomock.Any()).Return(containerMetric).AnyTimes()
+		containerThrottled := *genTestContainerThrottledQueryResult(containerID, 0.5)
+		mockMetricCache.EXPECT().GetContainerThrottledMetric(&containerThrottled.Metric.ContainerID,
+			gomock.Any()).Return(containerThrottled).AnyTimes()
+	}
+
+	r := &resmanager{
+		config:         NewDefaultConfig(),
+		statesInformer: mockStatesInformer,
+		metricCache:    mockMetricCache,
+		eventRecorder:  &FakeRecorder{},
+		kubeClient:     clientsetfake.NewSimpleClientset(),
+		nodeSLO: &slov1alpha1.NodeSLO{
+			Spec: slov1alpha1.NodeSLOSpec{CPUBurstStrategy: defaultAutoBurstStrategy},
+		},
+	}
+
+	testHelper := system.NewFileTestUtil(t)
+	defer testHelper.Cleanup()
+	testHelper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSGuaranteed), system.CPUSet, "0-7")
+
+	b := NewCPUBurst(r)
+	b.burstStates = &burstStateStore{}
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, b.init(stop))
+
+	for _, podMeta := range podMetas {
+		containerName := genTestDefaultContainerNameByPod(podMeta.Pod.Name)
+		initPodCPUBurst(podMeta, 0, testHelper)
+		initContainerCPUBurst(podMeta, 0, testHelper)
+		initPodCFSQuota(podMeta, 2*system.CFSBasePeriodValue, testHelper)
+		initContainerCFSQuota(podMeta, map[string]int64{containerName: 2 * system.CFSBasePeriodValue}, testHelper)
+		containerDir, _ := util.GetContainerCgroupPathWithKube(podMeta.CgroupDir, &podMeta.Pod.Status.ContainerStatuses[0])
+		testHelper.WriteCgroupFileContents(containerDir, system.CPUSet, containerCPUSets[podMeta.Pod.Name])
+	}
+
+	b.start()
+
+	for _, podMeta := range podMetas {
+		containerStat := &podMeta.Pod.Status.ContainerStatuses[0]
+		if podMeta.Pod.Name == pinnedPodName {
+			assert.Equal(t, int64(0), getContainerCPUBurst(podMeta.CgroupDir, containerStat, testHelper))
+			assert.Equal(t, 2*system.CFSBasePeriodValue, getContainerCFSQuota(podMeta.CgroupDir, containerStat, testHelper))
+			assert.Equal(t, 2*system.CFSBasePeriodValue, getPodCFSQuota(podMeta, testHelper))
+		} else {
+			assert.Equal(t, 2*10*system.CFSBasePeriodValue, getContainerCPUBurst(podMeta.CgroupDir, containerStat, testHelper))
+			assert.Equal(t, int64(2*cfsIncreaseStep*float64(system.CFSBasePeriodValue)),
+				getContainerCFSQuota(podMeta.CgroupDir, containerStat, testHelper))
+		}
+	}
+}
+
+func Test_isPodCPUPinnedByKubelet(t *testing.T) {
+	tests := []struct {
+		name            string
+		pod             *corev1.Pod
+		containerCPUSet string
+		rootCPUSet      string
+		want            bool
+	}{
+		{
+			name:            "guaranteed pod with integer cpus pinned",
+			pod:             newTestPodWithQOS("test-pod", apiext.QoSLS, 2000, 2000),
+			containerCPUSet: "2-3",
+			rootCPUSet:      "0-7",
+			want:            true,
+		},
+		{
+			name:            "guaranteed pod with integer cpus in the shared pool",
+			pod:             newTestPodWithQOS("test-pod", apiext.QoSLS, 2000, 2000),
+			containerCPUSet: "0-7",
+			rootCPUSet:      "0-7",
+			want:            false,
+		},
+		{
+			name:            "guaranteed pod with fractional cpus",
+			pod:             newTestPodWithQOS("test-pod", apiext.QoSLS, 1500, 2000),
+			containerCPUSet: "2-3",
+			rootCPUSet:      "0-7",
+			want:            false,
+		},
+		{
+			name: "burstable pod",
+			pod: func() *corev1.Pod {
+				pod := newTestPodWithQOS("test-pod", apiext.QoSLS, 2000, 2000)
+				delete(pod.Spec.Containers[0].Resources.Limits, corev1.ResourceCPU)
+				return pod
+			}(),
+			containerCPUSet: "2-3",
+			rootCPUSet:      "0-7",
+			want:            false,
+		},
+		{
+			name:            "cpuset unknown",
+			pod:             newTestPodWithQOS("test-pod", apiext.QoSLS, 2000, 2000),
+			containerCPUSet: "",
+			rootCPUSet:      "0-7",
+			want:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			podMeta := getPodMetas([]*corev1.Pod{tt.pod})[0]
+			helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSGuaranteed), system.CPUSet, tt.rootCPUSet)
+			if tt.containerCPUSet != "" {
+				containerDir, _ := util.GetContainerCgroupPathWithKube(podMeta.CgroupDir, &tt.pod.Status.ContainerStatuses[0])
+				helper.WriteCgroupFileContents(containerDir, system.CPUSet, tt.containerCPUSet)
+			}
+			assert.Equal(t, tt.want, isPodCPUPinnedByKubelet(podMeta))
 		})
 	}
 }
@@ -1658,6 +2730,8 @@ func TestCPUBurst_Recycle(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			b := &CPUBurst{
 				containerLimiter: tt.fields.containerLimiter,
+				podBurstRecords:  make(map[string]*podBurstRecord),
+				clock:            clock.RealClock{},
 			}
 			for name, lastUpdatePastSeconds := range tt.fields.limiterLastUpdatePastSeconds {
 				limiter := b.containerLimiter[name]
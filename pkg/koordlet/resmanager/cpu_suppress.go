@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -58,6 +59,8 @@ var (
 type CPUSuppress struct {
 	resmanager             *resmanager
 	suppressPolicyStatuses map[string]suppressPolicyStatus
+	// lastDecision is the last suppress action in the decision log, so that only the changed actions are recorded
+	lastDecision string
 }
 
 func NewCPUSuppress(resmanager *resmanager) *CPUSuppress {
@@ -110,14 +113,18 @@ func writeBECgroupsCPUSet(paths []string, cpusetStr string, isReversed bool) {
 	}
 }
 
-// calculateBESuppressCPU calculates the quantity of cpuset cpus for suppressing be pods
+// calculateBESuppressCPU calculates the quantity of cpuset cpus for suppressing be pods. The cpu of LS pods is counted
+// by the usage, the request or the larger one of each pod according to the calcPolicy.
 func (r *CPUSuppress) calculateBESuppressCPU(node *corev1.Node, nodeMetric *metriccache.NodeResourceMetric,
-	podMetrics []*metriccache.PodResourceMetric, podMetas []*statesinformer.PodMeta, beCPUUsedThreshold int64) *resource.Quantity {
+	podMetrics []*metriccache.PodResourceMetric, podMetas []*statesinformer.PodMeta, beCPUUsedThreshold int64,
+	calcPolicy slov1alpha1.CPUSuppressCalcPolicy) *resource.Quantity {
 	// node, nodeMetric, podMetric should not be nil
 	nodeUsedCPU := &nodeMetric.CPUUsed.CPUUsed
 
 	podAllUsedCPU := *resource.NewMilliQuantity(0, resource.DecimalSI)
-	podLSUsedCPU := *resource.NewMilliQuantity(0, resource.DecimalSI)
+	// podLSUsedMilliCPU records the cpu usage of LS pods by uid, and podsUnknownUsedCPU the usage of podMeta-missing pods
+	podLSUsedMilliCPU := map[string]int64{}
+	podsUnknownUsedCPU := *resource.NewMilliQuantity(0, resource.DecimalSI)
 
 	podMetaMap := map[string]*statesinformer.PodMeta{}
 	for _, podMeta := range podMetas {
@@ -130,10 +137,32 @@ func (r *CPUSuppress) calculateBESuppressCPU(node *corev1.Node, nodeMetric *metr
 		podMeta, ok := podMetaMap[podMetric.PodUID]
 		if !ok {
 			klog.Warningf("podMetric not included in the podMetas %v", podMetric.PodUID)
+			// NOTE: consider podMeta-missing pods as LS
+			podsUnknownUsedCPU.Add(*getPodMetricCPUUsage(podMetric))
+		} else if isLSPodForCPUSuppress(podMeta.Pod) {
+			// NOTE: consider non-BE pods as LS
+			podLSUsedMilliCPU[podMetric.PodUID] += getPodMetricCPUUsage(podMetric).MilliValue()
 		}
-		if !ok || (apiext.GetPodQoSClass(podMeta.Pod) != apiext.QoSBE && util.GetKubeQosClass(podMeta.Pod) != corev1.PodQOSBestEffort) {
-			// NOTE: consider non-BE pods and podMeta-missing pods as LS
-			podLSUsedCPU.Add(*getPodMetricCPUUsage(podMetric))
+	}
+
+	podLSCPU := podsUnknownUsedCPU.DeepCopy()
+	switch calcPolicy {
+	case slov1alpha1.CPUSuppressCalcByRequest, slov1alpha1.CPUSuppressCalcByMax:
+		for _, podMeta := range podMetas {
+			pod := podMeta.Pod
+			if !isLSPodForCPUSuppress(pod) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			podRequest := util.GetPodRequest(pod)
+			podMilliCPU := podRequest.Cpu().MilliValue()
+			if podUsed := podLSUsedMilliCPU[string(pod.UID)]; calcPolicy == slov1alpha1.CPUSuppressCalcByMax && podUsed > podMilliCPU {
+				podMilliCPU = podUsed
+			}
+			podLSCPU.Add(*resource.NewMilliQuantity(podMilliCPU, resource.DecimalSI))
+		}
+	default:
+		for _, podUsed := range podLSUsedMilliCPU {
+			podLSCPU.Add(*resource.NewMilliQuantity(podUsed, resource.DecimalSI))
 		}
 	}
 
@@ -148,15 +177,28 @@ func (r *CPUSuppress) calculateBESuppressCPU(node *corev1.Node, nodeMetric *metr
 	// NOTE: valid milli-cpu values should not larger than 2^20, so there is no overflow during the calculation
 	nodeBESuppressCPU := resource.NewMilliQuantity(node.Status.Allocatable.Cpu().MilliValue()*beCPUUsedThreshold/100,
 		node.Status.Allocatable.Cpu().Format)
-	nodeBESuppressCPU.Sub(podLSUsedCPU)
+	nodeBESuppressCPU.Sub(podLSCPU)
 	nodeBESuppressCPU.Sub(systemUsedCPU)
-	klog.Infof("nodeSuppressBE[CPU(Core)]:%v = node.Total:%v * SLOPercent:%v%% - systemUsage:%v - podLSUsed:%v\n",
+	klog.Infof("nodeSuppressBE[CPU(Core)]:%v = node.Total:%v * SLOPercent:%v%% - systemUsage:%v - podLS(%v):%v\n",
 		nodeBESuppressCPU.Value(), node.Status.Allocatable.Cpu().Value(), beCPUUsedThreshold, systemUsedCPU.Value(),
-		podLSUsedCPU.Value())
+		getCPUSuppressCalcPolicy(calcPolicy), podLSCPU.Value())
 
 	return nodeBESuppressCPU
 }
 
+// isLSPodForCPUSuppress returns whether the pod is counted as LS in the cpu suppress, i.e. the non-BE pods.
+func isLSPodForCPUSuppress(pod *corev1.Pod) bool {
+	return apiext.GetPodQoSClass(pod) != apiext.QoSBE && util.GetKubeQosClass(pod) != corev1.PodQOSBestEffort
+}
+
+// getCPUSuppressCalcPolicy returns the cpu suppress calc policy, which is usage by default.
+func getCPUSuppressCalcPolicy(calcPolicy slov1alpha1.CPUSuppressCalcPolicy) slov1alpha1.CPUSuppressCalcPolicy {
+	if calcPolicy == "" {
+		return slov1alpha1.CPUSuppressCalcByUsage
+	}
+	return calcPolicy
+}
+
 // calculateBESuppressPolicy calculates the be cpu suppress policy with cpuset cpus number and node cpu info
 func calculateBESuppressCPUSetPolicy(cpusetQuantity *resource.Quantity, oldCPUSetNum int, nodeCPUInfo *metriccache.NodeCPUInfo) []int32 {
 	// set the number of cpuset cpus no less than 2
@@ -254,7 +296,7 @@ func calculateBESuppressCPUSetPolicy(cpusetQuantity *resource.Quantity, oldCPUSe
 }
 
 // applyBESuppressPolicy applies the be suppress policy by writing best-effort cgroups
-func applyBESuppressCPUSetPolicy(cpuset []int32, oldCPUSet []int32) error {
+func applyBESuppressCPUSetPolicy(cpuset []int32, oldCPUSet []int32, exemptPodDirs []string) error {
 	// 1. get current be cgroups cpuset
 	// 2. temporarily write with a union of old cpuset and new cpuset from upper to lower, to avoid cgroup conflicts
 	// 3. write with the new cpuset from lower to upper to apply the real policy
@@ -268,19 +310,30 @@ func applyBESuppressCPUSetPolicy(cpuset []int32, oldCPUSet []int32) error {
 		klog.Warningf("applyBESuppressPolicy failed to get be cgroup cpuset paths, err: %s", err)
 		return fmt.Errorf("apply be suppress policy failed, err: %s", err)
 	}
+	if len(cpusetCgroupPaths) <= 0 {
+		return nil
+	}
+
+	// the cgroups of the exempt pods keep their cpusets, so the be root cgroup should still contain them
+	cpusetCgroupPaths, exemptCPUSet := excludeCPUSetSuppressExemptPaths(cpusetCgroupPaths, exemptPodDirs)
+	rootCPUSet := cpuset
+	if len(exemptCPUSet) > 0 {
+		rootCPUSet = util.MergeCPUSet(cpuset, exemptCPUSet)
+	}
 
 	// write a loose cpuset for all be cgroups before applying the real policy
-	mergedCPUSet := util.MergeCPUSet(oldCPUSet, cpuset)
+	mergedCPUSet := util.MergeCPUSet(oldCPUSet, rootCPUSet)
 	mergedCPUSetStr := util.GenerateCPUSetStr(mergedCPUSet)
 	klog.V(6).Infof("applyBESuppressPolicy temporarily writes cpuset from upper cgroup to lower, cpuset %v",
 		mergedCPUSet)
 	writeBECgroupsCPUSet(cpusetCgroupPaths, mergedCPUSetStr, false)
 
-	// apply the suppress policy from lower to upper
+	// apply the suppress policy from lower to upper, where the first path is the be root cgroup
 	cpusetStr := util.GenerateCPUSetStr(cpuset)
 	klog.V(6).Infof("applyBESuppressPolicy writes suppressed cpuset from lower cgroup to upper, cpuset %v",
 		cpuset)
-	writeBECgroupsCPUSet(cpusetCgroupPaths, cpusetStr, true)
+	writeBECgroupsCPUSet(cpusetCgroupPaths[1:], cpusetStr, true)
+	writeBECgroupsCPUSet(cpusetCgroupPaths[:1], util.GenerateCPUSetStr(rootCPUSet), true)
 	metrics.RecordBESuppressCores(string(slov1alpha1.CPUSetPolicy), float64(len(cpuset)))
 	return nil
 }
@@ -303,6 +356,7 @@ func (r *CPUSuppress) suppressBECPU() {
 	} else if disabled {
 		r.recoverCFSQuotaIfNeed()
 		r.recoverCPUSetIfNeed()
+		metrics.RecordBESuppressedPods(0)
 		klog.V(5).Infof("suppressBECPU skipped, nodeSLO disable the featuregate")
 		return
 	}
@@ -319,7 +373,22 @@ func (r *CPUSuppress) suppressBECPU() {
 		return
 	}
 
-	nodeMetric, podMetrics := r.resmanager.collectNodeAndPodMetricLast()
+	// fail open if the metric pipeline gets stuck, rather than keeping BE throttled on the stale data
+	if r.isNodeMetricStale() {
+		klog.Warningf("suppressBECPU released, no node metric is collected in the last %v seconds",
+			r.resmanager.config.CPUSuppressMetricStaleSeconds)
+		metrics.RecordBESuppressStaleMetricRelease()
+		r.recordDecision(fmt.Sprintf("metricStaleSeconds=%v", r.resmanager.config.CPUSuppressMetricStaleSeconds),
+			"release suppression on stale node metric")
+		r.recoverCFSQuotaIfNeed()
+		r.recoverCPUSetIfNeed()
+		recordBESuppressMetrics(node, podMetas, nil)
+		return
+	}
+
+	nodeMetric, podMetrics := r.resmanager.collectNodeAndPodMetricWithWindow(
+		nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressMetricWindowSeconds,
+		nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressMetricAggregation)
 	if nodeMetric == nil || podMetrics == nil {
 		klog.Warningf("suppressBECPU failed, got nil node metric or nil pod metrics, nodeMetric %v, podMetrics %v",
 			nodeMetric, podMetrics)
@@ -327,7 +396,14 @@ func (r *CPUSuppress) suppressBECPU() {
 	}
 
 	suppressCPUQuantity := r.calculateBESuppressCPU(node, nodeMetric, podMetrics, podMetas,
-		*nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+		*nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent,
+		nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressCalcPolicy)
+	suppressCPUQuantity = adjustBESuppressCPUByPSI(suppressCPUQuantity,
+		nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressPSIThreshold)
+	suppressCPUQuantity = reserveSystemCPU(suppressCPUQuantity, node,
+		nodeSLO.Spec.ResourceUsedThresholdWithBE.SystemCPUReservePercent)
+	r.evaluateShadowSuppress(nodeSLO.Spec.ShadowResourceUsedThresholdWithBE, node, nodeMetric, podMetrics, podMetas,
+		suppressCPUQuantity)
 
 	// Step 2.
 	nodeCPUInfo, err := r.resmanager.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
@@ -336,18 +412,147 @@ func (r *CPUSuppress) suppressBECPU() {
 		return
 	}
 
-	if nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressPolicy == slov1alpha1.CPUCfsQuotaPolicy {
-		adjustByCfsQuota(suppressCPUQuantity, node)
+	// Step 3.
+	// both the cpuset and the cfs quota are adjusted with the same suppress quantity if the combined policy is used,
+	// where the cpuset is rounded up to the cpus no less than the quota, so the quota keeps throttling inside the cpuset.
+	// the unused policy is recovered after the suppression is applied.
+	policy := nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressPolicy
+	cpusetEnabled, cfsQuotaEnabled := isCPUSetSuppressEnabled(policy), isCfsQuotaSuppressEnabled(policy)
+	if cpusetEnabled {
+		adjustByCPUSet(suppressCPUQuantity, nodeCPUInfo, getCPUSetSuppressExemptPodDirs(podMetas))
+		r.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)] = policyUsing
+	}
+	if cfsQuotaEnabled {
+		adjustByCfsQuota(suppressCPUQuantity, node, nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressStepPercent)
 		r.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)] = policyUsing
+	}
+	if !cpusetEnabled {
 		r.recoverCPUSetIfNeed()
-	} else {
-		adjustByCPUSet(suppressCPUQuantity, nodeCPUInfo)
-		r.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)] = policyUsing
+	}
+	if !cfsQuotaEnabled {
 		r.recoverCFSQuotaIfNeed()
 	}
+	recordBESuppressMetrics(node, podMetas, suppressCPUQuantity)
+	r.recordDecision(fmt.Sprintf("nodeCPUUsed=%vm thresholdPercent=%v calcPolicy=%q",
+		nodeMetric.CPUUsed.CPUUsed.MilliValue(), *nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent,
+		nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressCalcPolicy),
+		fmt.Sprintf("suppress be cpu to %vm by policy %s", suppressCPUQuantity.MilliValue(), policy))
+}
+
+// recordBESuppressMetrics records the cpu allocated to the BE pods and the number of the running BE pods under the
+// suppression, where a nil suppressCPU means the suppression is released. The BE pods are suppressed all together by
+// the be cgroup once the allocation is below the node capacity.
+func recordBESuppressMetrics(node *corev1.Node, podMetas []*statesinformer.PodMeta, suppressCPU *resource.Quantity) {
+	nodeCPU := node.Status.Capacity.Cpu()
+	if suppressCPU == nil || suppressCPU.Cmp(*nodeCPU) >= 0 {
+		metrics.RecordBECPUAllocation(float64(nodeCPU.MilliValue()) / 1000)
+		metrics.RecordBESuppressedPods(0)
+		return
+	}
+	suppressedPods := 0
+	for _, podMeta := range podMetas {
+		if podMeta == nil || podMeta.Pod == nil {
+			continue
+		}
+		if podMeta.Pod.Status.Phase == corev1.PodRunning && !isLSPodForCPUSuppress(podMeta.Pod) {
+			suppressedPods++
+		}
+	}
+	metrics.RecordBECPUAllocation(float64(suppressCPU.MilliValue()) / 1000)
+	metrics.RecordBESuppressedPods(float64(suppressedPods))
+}
+
+// recordDecision records the suppress action into the decision log only if it changes, since the suppression
+// reconciles every few seconds.
+func (r *CPUSuppress) recordDecision(inputs, action string) {
+	if action == r.lastDecision {
+		return
+	}
+	r.lastDecision = action
+	r.resmanager.decisionLog.record(features.BECPUSuppress, inputs, action)
+}
+
+// evaluateShadowSuppress calculates the BE suppress cpu under the shadow threshold strategy with the same metrics, and
+// reports it along with the active one. It returns nil if the shadow strategy is not set. The shadow suppress cpu is
+// never applied.
+func (r *CPUSuppress) evaluateShadowSuppress(shadow *slov1alpha1.ResourceThresholdStrategy, node *corev1.Node,
+	nodeMetric *metriccache.NodeResourceMetric, podMetrics []*metriccache.PodResourceMetric,
+	podMetas []*statesinformer.PodMeta, suppressCPU *resource.Quantity) *resource.Quantity {
+	if shadow == nil || shadow.CPUSuppressThresholdPercent == nil {
+		return nil
+	}
+	shadowSuppressCPU := r.calculateBESuppressCPU(node, nodeMetric, podMetrics, podMetas,
+		*shadow.CPUSuppressThresholdPercent, shadow.CPUSuppressCalcPolicy)
+	shadowSuppressCPU = adjustBESuppressCPUByPSI(shadowSuppressCPU, shadow.CPUSuppressPSIThreshold)
+	shadowSuppressCPU = reserveSystemCPU(shadowSuppressCPU, node, shadow.SystemCPUReservePercent)
+	metrics.RecordThresholdStrategyDecision(string(features.BECPUSuppress), metrics.ThresholdStrategyActive,
+		float64(suppressCPU.MilliValue())/1000)
+	metrics.RecordThresholdStrategyDecision(string(features.BECPUSuppress), metrics.ThresholdStrategyShadow,
+		float64(shadowSuppressCPU.MilliValue())/1000)
+	if shadowSuppressCPU.Cmp(*suppressCPU) != 0 {
+		klog.V(4).Infof("shadow threshold would suppress be cpu to %vm, while the active one suppresses to %vm",
+			shadowSuppressCPU.MilliValue(), suppressCPU.MilliValue())
+	}
+	return shadowSuppressCPU
+}
+
+// adjustBESuppressCPUByPSI tightens the BE suppress cpu proportionally when the node cpu pressure exceeds the
+// threshold, i.e. suppressCPU * threshold / pressure, since the LS pods can be stalled on cpu even if the usage is
+// moderate. The suppress cpu keeps unchanged if the threshold is not set or the cpu PSI is unavailable.
+func adjustBESuppressCPUByPSI(suppressCPU *resource.Quantity, psiThreshold *int64) *resource.Quantity {
+	if psiThreshold == nil || *psiThreshold <= 0 || suppressCPU.MilliValue() <= 0 {
+		return suppressCPU
+	}
+	pressure, err := util.GetNodeCPUPressure()
+	if err != nil {
+		klog.V(4).Infof("skip adjusting be suppress cpu by psi, failed to get node cpu pressure, err: %v", err)
+		return suppressCPU
+	}
+	if pressure.Avg10 <= float64(*psiThreshold) {
+		return suppressCPU
+	}
+	milliCPU := int64(float64(suppressCPU.MilliValue()) * float64(*psiThreshold) / pressure.Avg10)
+	klog.V(4).Infof("node cpu pressure %.2f%% exceeds the threshold %v%%, tighten be suppress cpu from %vm to %vm",
+		pressure.Avg10, *psiThreshold, suppressCPU.MilliValue(), milliCPU)
+	return resource.NewMilliQuantity(milliCPU, resource.DecimalSI)
+}
+
+// reserveSystemCPU subtracts the system cpu reserve of the node allocatable from the BE suppress cpu, so that the
+// system daemons always have the headroom besides their current usage even if the node is highly utilized. The
+// suppress cpu keeps unchanged if the reserve percent is not set.
+func reserveSystemCPU(suppressCPU *resource.Quantity, node *corev1.Node, reservePercent *int64) *resource.Quantity {
+	if reservePercent == nil || *reservePercent <= 0 {
+		return suppressCPU
+	}
+	reserveMilliCPU := node.Status.Allocatable.Cpu().MilliValue() * (*reservePercent) / 100
+	milliCPU := suppressCPU.MilliValue() - reserveMilliCPU
+	klog.V(4).Infof("reserve %vm cpu for the system, reduce be suppress cpu from %vm to %vm", reserveMilliCPU,
+		suppressCPU.MilliValue(), milliCPU)
+	return resource.NewMilliQuantity(milliCPU, resource.DecimalSI)
 }
 
-func adjustByCPUSet(cpusetQuantity *resource.Quantity, nodeCPUInfo *metriccache.NodeCPUInfo) {
+// isNodeMetricStale returns whether no node metric is collected within the staleness threshold, which is disabled if
+// the threshold is not positive.
+func (r *CPUSuppress) isNodeMetricStale() bool {
+	if r.resmanager.config == nil || r.resmanager.config.CPUSuppressMetricStaleSeconds <= 0 {
+		return false
+	}
+	queryParam := generateQueryParamsLast(int64(r.resmanager.config.CPUSuppressMetricStaleSeconds))
+	queryResult := r.resmanager.metricCache.GetNodeResourceMetric(queryParam)
+	return queryResult.Error != nil || queryResult.Metric == nil
+}
+
+// isCPUSetSuppressEnabled returns whether the suppress policy adjusts the BE cpuset, which is the default policy.
+func isCPUSetSuppressEnabled(policy slov1alpha1.CPUSuppressPolicy) bool {
+	return policy != slov1alpha1.CPUCfsQuotaPolicy
+}
+
+// isCfsQuotaSuppressEnabled returns whether the suppress policy adjusts the BE cfs quota.
+func isCfsQuotaSuppressEnabled(policy slov1alpha1.CPUSuppressPolicy) bool {
+	return policy == slov1alpha1.CPUCfsQuotaPolicy || policy == slov1alpha1.CPUSetAndCfsQuotaPolicy
+}
+
+func adjustByCPUSet(cpusetQuantity *resource.Quantity, nodeCPUInfo *metriccache.NodeCPUInfo, exemptPodDirs []string) {
 	oldCPUSet, err := util.GetRootCgroupCurCPUSet(corev1.PodQOSBestEffort)
 	if err != nil {
 		klog.Warningf("applyBESuppressPolicy failed to get current best-effort cgroup cpuset, err: %s", err)
@@ -359,7 +564,7 @@ func adjustByCPUSet(cpusetQuantity *resource.Quantity, nodeCPUInfo *metriccache.
 	// the new be suppress always need to apply since:
 	// - for a reduce of BE cpuset, we should make effort to protecting LS no matter how huge the decrease is;
 	// - for a enlargement of BE cpuset, it is welcome and costless for BE processes.
-	err = applyBESuppressCPUSetPolicy(beCPUSet, oldCPUSet)
+	err = applyBESuppressCPUSetPolicy(beCPUSet, oldCPUSet, exemptPodDirs)
 	if err != nil {
 		klog.Warningf("suppressBECPU failed to apply be cpu suppress policy, err: %s", err)
 		return
@@ -368,6 +573,51 @@ func adjustByCPUSet(cpusetQuantity *resource.Quantity, nodeCPUInfo *metriccache.
 	klog.Infof("suppressBECPU finished, suppress be cpu successfully: current cpuset %v", beCPUSet)
 }
 
+// getCPUSetSuppressExemptPodDirs returns the cpuset cgroup dirs of the pods exempt from the cpuset suppression
+func getCPUSetSuppressExemptPodDirs(podMetas []*statesinformer.PodMeta) []string {
+	var podDirs []string
+	for _, podMeta := range podMetas {
+		if podMeta == nil || !apiext.IsPodCPUSetSuppressExempt(podMeta.Pod) {
+			continue
+		}
+		podDirs = append(podDirs, filepath.Join(system.Conf.CgroupRootDir, system.CgroupCPUSetDir,
+			util.GetPodCgroupDirWithKube(podMeta.CgroupDir)))
+	}
+	return podDirs
+}
+
+// excludeCPUSetSuppressExemptPaths excludes the cgroup paths of the exempt pods and their containers, and returns the
+// union of the current cpusets of the exempt pods.
+func excludeCPUSetSuppressExemptPaths(paths []string, exemptPodDirs []string) ([]string, []int32) {
+	if len(exemptPodDirs) <= 0 {
+		return paths, nil
+	}
+	var exemptCPUSet []int32
+	filteredPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		exempt := false
+		for _, podDir := range exemptPodDirs {
+			if path == podDir {
+				cpuset, err := util.ReadCgroupCPUSet(podDir)
+				if err != nil {
+					klog.Warningf("failed to read cpuset of the suppress exempt pod cgroup %s, err: %s", podDir, err)
+				}
+				exemptCPUSet = util.MergeCPUSet(exemptCPUSet, cpuset)
+				exempt = true
+				break
+			}
+			if strings.HasPrefix(path, podDir+"/") {
+				exempt = true
+				break
+			}
+		}
+		if !exempt {
+			filteredPaths = append(filteredPaths, path)
+		}
+	}
+	return filteredPaths, exemptCPUSet
+}
+
 func (r *CPUSuppress) recoverCPUSetIfNeed() {
 	cpusetPolicyStatus, exist := r.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)]
 	if exist && cpusetPolicyStatus == policyRecovered {
@@ -385,13 +635,57 @@ func (r *CPUSuppress) recoverCPUSetIfNeed() {
 		return
 	}
 
+	if stepPercent := r.resmanager.getSuppressReleaseStepPercent(); stepPercent > 0 {
+		var exemptPodDirs []string
+		if r.resmanager.statesInformer != nil {
+			exemptPodDirs = getCPUSetSuppressExemptPodDirs(r.resmanager.statesInformer.GetAllPods())
+		}
+		if !r.rampReleaseCPUSet(rootCPUSet, stepPercent, exemptPodDirs) {
+			return
+		}
+	}
+
 	cpusetStr := util.GenerateCPUSetStr(rootCPUSet)
 	klog.V(6).Infof("recover bestEffort cpuset, cpuset %v", rootCPUSet)
 	writeBECgroupsCPUSet(cpusetCgroupPaths, cpusetStr, false)
 	r.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)] = policyRecovered
 }
 
-func adjustByCfsQuota(cpuQuantity *resource.Quantity, node *corev1.Node) {
+// rampReleaseCPUSet enlarges the be cpuset by stepPercent of the cpus it lacks from the root cpuset, so that the be
+// usage does not re-spike at once, where the cpusets of the exempt pods are kept. It returns whether the remaining cpus
+// should be released at once.
+func (r *CPUSuppress) rampReleaseCPUSet(rootCPUSet []int32, stepPercent int64, exemptPodDirs []string) bool {
+	beCPUSet, err := util.GetRootCgroupCurCPUSet(corev1.PodQOSBestEffort)
+	if err != nil {
+		klog.Warningf("release bestEffort cpuset at once, get current be cgroup cpuset err: %s", err)
+		return true
+	}
+	beCPUs := make(map[int32]struct{}, len(beCPUSet))
+	for _, cpu := range beCPUSet {
+		beCPUs[cpu] = struct{}{}
+	}
+	var lackedCPUs []int32
+	for _, cpu := range rootCPUSet {
+		if _, ok := beCPUs[cpu]; !ok {
+			lackedCPUs = append(lackedCPUs, cpu)
+		}
+	}
+	releaseNum := int(math.Ceil(float64(len(lackedCPUs)) * float64(stepPercent) / 100))
+	if releaseNum >= len(lackedCPUs) {
+		return true
+	}
+	newCPUSet := util.MergeCPUSet(beCPUSet, lackedCPUs[:releaseNum])
+	if err = applyBESuppressCPUSetPolicy(newCPUSet, beCPUSet, exemptPodDirs); err != nil {
+		klog.Warningf("release bestEffort cpuset at once, ramp be cpuset err: %s", err)
+		return true
+	}
+	klog.V(4).Infof("release bestEffort cpuset by step %v%%, current cpuset %v", stepPercent, newCPUSet)
+	return false
+}
+
+// adjustByCfsQuota updates the BE cfs quota to the suppress target. If stepPercent is set, the quota moves
+// toward the target by at most stepPercent of the node cpu capacity in each call.
+func adjustByCfsQuota(cpuQuantity *resource.Quantity, node *corev1.Node, stepPercent *int64) {
 	newBeQuota := cpuQuantity.MilliValue() * cfsPeriod / 1000
 	newBeQuota = int64(math.Max(float64(newBeQuota), float64(beMinQuota)))
 
@@ -416,6 +710,10 @@ func adjustByCfsQuota(cpuQuantity *resource.Quantity, node *corev1.Node) {
 		newBeQuota = *currentBeQuota + int64(beMaxIncreaseCPUQuota)
 	}
 
+	if stepPercent != nil {
+		newBeQuota = rampBEQuota(*currentBeQuota, newBeQuota, node, *stepPercent)
+	}
+
 	if err := system.CgroupFileWrite(beCgroupPath, system.CPUCFSQuota, strconv.FormatInt(newBeQuota, 10)); err != nil {
 		klog.Errorf("suppressBECPU: failed to write cfs_quota_us for offline pods, error: %v", err)
 		return
@@ -425,6 +723,27 @@ func adjustByCfsQuota(cpuQuantity *resource.Quantity, node *corev1.Node) {
 	klog.Infof("suppressBECPU: succeeded to write cfs_quota_us for offline pods, new value: %d", newBeQuota)
 }
 
+// rampBEQuota limits the move from currentQuota to targetQuota to stepPercent of the node cpu capacity.
+// An unlimited current quota is treated as the whole node capacity.
+func rampBEQuota(currentQuota, targetQuota int64, node *corev1.Node, stepPercent int64) int64 {
+	nodeQuota := node.Status.Capacity.Cpu().Value() * cfsPeriod
+	if currentQuota < 0 || currentQuota > nodeQuota {
+		currentQuota = nodeQuota
+	}
+	maxStepQuota := nodeQuota * stepPercent / 100
+	if targetQuota < currentQuota-maxStepQuota {
+		klog.V(5).Infof("suppressBECPU: ramp cfs quota down by step %d, current quota: %d, target quota: %d",
+			maxStepQuota, currentQuota, targetQuota)
+		return currentQuota - maxStepQuota
+	}
+	if targetQuota > currentQuota+maxStepQuota {
+		klog.V(5).Infof("suppressBECPU: ramp cfs quota up by step %d, current quota: %d, target quota: %d",
+			maxStepQuota, currentQuota, targetQuota)
+		return currentQuota + maxStepQuota
+	}
+	return targetQuota
+}
+
 func (r *CPUSuppress) recoverCFSQuotaIfNeed() {
 	cfsQuotaPolicyStatus, exist := r.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)]
 	if exist && cfsQuotaPolicyStatus == policyRecovered {
@@ -432,6 +751,10 @@ func (r *CPUSuppress) recoverCFSQuotaIfNeed() {
 	}
 
 	beCgroupPath := util.GetKubeQosRelativePath(corev1.PodQOSBestEffort)
+	if stepPercent := r.resmanager.getSuppressReleaseStepPercent(); stepPercent > 0 &&
+		!r.rampReleaseCFSQuota(beCgroupPath, stepPercent) {
+		return
+	}
 	if err := system.CgroupFileWrite(beCgroupPath, system.CPUCFSQuota, "-1"); err != nil {
 		klog.Errorf("recover bestEffort cfsQuota error: %v", err)
 		return
@@ -439,6 +762,34 @@ func (r *CPUSuppress) recoverCFSQuotaIfNeed() {
 	r.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)] = policyRecovered
 }
 
+// rampReleaseCFSQuota raises the be cfs quota by stepPercent of the gap to the node cpu capacity, so that the be
+// usage does not re-spike at once. It returns whether the remaining quota should be released at once.
+func (r *CPUSuppress) rampReleaseCFSQuota(beCgroupPath string, stepPercent int64) bool {
+	if r.resmanager.statesInformer == nil {
+		return true
+	}
+	node := r.resmanager.statesInformer.GetNode()
+	if node == nil {
+		return true
+	}
+	currentQuota, err := system.CgroupFileReadInt(beCgroupPath, system.CPUCFSQuota)
+	if err != nil || *currentQuota < 0 {
+		return true
+	}
+	nodeQuota := node.Status.Capacity.Cpu().Value() * cfsPeriod
+	newQuota := *currentQuota + (nodeQuota-*currentQuota)*stepPercent/100
+	if float64(nodeQuota-newQuota) < float64(nodeQuota)*suppressBypassQuotaDeltaRatio {
+		return true
+	}
+	if err = system.CgroupFileWrite(beCgroupPath, system.CPUCFSQuota, strconv.FormatInt(newQuota, 10)); err != nil {
+		klog.Errorf("release bestEffort cfsQuota by step error: %v", err)
+		return false
+	}
+	metrics.RecordBESuppressCores(string(slov1alpha1.CPUCfsQuotaPolicy), float64(newQuota)/float64(cfsPeriod))
+	klog.V(4).Infof("release bestEffort cfsQuota by step %v%%, current quota %v", stepPercent, newQuota)
+	return false
+}
+
 func getCPUSuppressPolicy(nodeSLO *slov1alpha1.NodeSLO) slov1alpha1.CPUSuppressPolicy {
 	if nodeSLO == nil || nodeSLO.Spec.ResourceUsedThresholdWithBE == nil ||
 		nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressPolicy == "" {
@@ -17,21 +17,27 @@
 package resmanager
 
 import (
+	"fmt"
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -439,6 +445,254 @@ func Test_cpuSuppress_suppressBECPU(t *testing.T) {
 			wantBECPUSet:             "15,14",
 			wantCPUSetPolicyStatus:   &policyUsing,
 		},
+		{
+			name: "calculate be suppress cpus and cfsQuota correctly for the combined policy",
+			args: args{
+				node: &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node0",
+					},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("40G"),
+						},
+						Capacity: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("40G"),
+						},
+					},
+				},
+				nodeMetric: &metriccache.NodeResourceMetric{
+					CPUUsed: metriccache.CPUMetric{
+						CPUUsed: resource.MustParse("12"),
+					},
+					MemoryUsed: metriccache.MemoryMetric{
+						MemoryWithoutCache: resource.MustParse("18G"),
+					},
+				},
+				podMetrics: []*metriccache.PodResourceMetric{
+					{
+						PodUID: "ls-pod",
+						CPUUsed: metriccache.CPUMetric{
+							CPUUsed: resource.MustParse("8"),
+						},
+						MemoryUsed: metriccache.MemoryMetric{
+							MemoryWithoutCache: resource.MustParse("10G"),
+						},
+					},
+					{
+						PodUID: "be-pod",
+						CPUUsed: metriccache.CPUMetric{
+							CPUUsed: resource.MustParse("2"),
+						},
+						MemoryUsed: metriccache.MemoryMetric{
+							MemoryWithoutCache: resource.MustParse("4G"),
+						},
+					},
+				},
+				podMetas: []*statesinformer.PodMeta{
+					{
+						Pod: &corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "ls-pod",
+								UID:  "ls-pod",
+								Labels: map[string]string{
+									apiext.LabelPodQoS: string(apiext.QoSLS),
+								},
+							},
+							Spec: corev1.PodSpec{
+								NodeName: "test-node",
+								Containers: []corev1.Container{
+									{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+											Limits: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+										},
+									},
+								},
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodRunning,
+							},
+						},
+					},
+					{
+						Pod: &corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "be-pod",
+								UID:  "be-pod",
+								Labels: map[string]string{
+									apiext.LabelPodQoS: string(apiext.QoSBE),
+								},
+							},
+							Spec: corev1.PodSpec{
+								NodeName: "test-node",
+								Containers: []corev1.Container{
+									{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												apiext.BatchCPU:    resource.MustParse("4"),
+												apiext.BatchMemory: resource.MustParse("6G"),
+											},
+											Limits: corev1.ResourceList{
+												apiext.BatchCPU:    resource.MustParse("4"),
+												apiext.BatchMemory: resource.MustParse("6G"),
+											},
+										},
+									},
+								},
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodRunning,
+							},
+						},
+					},
+				},
+				nodeCPUSet:    "0-15",
+				preBECPUSet:   "0-9",
+				preBECFSQuota: 8 * defaultCFSPeriod,
+				thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+					Enable:                      pointer.BoolPtr(true),
+					CPUSuppressPolicy:           slov1alpha1.CPUSetAndCfsQuotaPolicy,
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(70),
+				},
+			},
+			wantBECFSQuota:           1.2 * defaultCFSPeriod,
+			wantCFSQuotaPolicyStatus: &policyUsing,
+			wantBECPUSet:             "15,14",
+			wantCPUSetPolicyStatus:   &policyUsing,
+		},
+		{
+			name: "reset both cpuset and cfs quota if cpu qos disabled for the combined policy",
+			args: args{
+				node: &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node0",
+					},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("40G"),
+						},
+						Capacity: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("40G"),
+						},
+					},
+				},
+				nodeMetric: &metriccache.NodeResourceMetric{
+					CPUUsed: metriccache.CPUMetric{
+						CPUUsed: resource.MustParse("12"),
+					},
+					MemoryUsed: metriccache.MemoryMetric{
+						MemoryWithoutCache: resource.MustParse("18G"),
+					},
+				},
+				podMetrics: []*metriccache.PodResourceMetric{
+					{
+						PodUID: "ls-pod",
+						CPUUsed: metriccache.CPUMetric{
+							CPUUsed: resource.MustParse("8"),
+						},
+						MemoryUsed: metriccache.MemoryMetric{
+							MemoryWithoutCache: resource.MustParse("10G"),
+						},
+					},
+					{
+						PodUID: "be-pod",
+						CPUUsed: metriccache.CPUMetric{
+							CPUUsed: resource.MustParse("2"),
+						},
+						MemoryUsed: metriccache.MemoryMetric{
+							MemoryWithoutCache: resource.MustParse("4G"),
+						},
+					},
+				},
+				podMetas: []*statesinformer.PodMeta{
+					{
+						Pod: &corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "ls-pod",
+								UID:  "ls-pod",
+								Labels: map[string]string{
+									apiext.LabelPodQoS: string(apiext.QoSLS),
+								},
+							},
+							Spec: corev1.PodSpec{
+								NodeName: "test-node",
+								Containers: []corev1.Container{
+									{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+											Limits: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+										},
+									},
+								},
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodRunning,
+							},
+						},
+					},
+					{
+						Pod: &corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "be-pod",
+								UID:  "be-pod",
+								Labels: map[string]string{
+									apiext.LabelPodQoS: string(apiext.QoSBE),
+								},
+							},
+							Spec: corev1.PodSpec{
+								NodeName: "test-node",
+								Containers: []corev1.Container{
+									{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												apiext.BatchCPU:    resource.MustParse("4"),
+												apiext.BatchMemory: resource.MustParse("6G"),
+											},
+											Limits: corev1.ResourceList{
+												apiext.BatchCPU:    resource.MustParse("4"),
+												apiext.BatchMemory: resource.MustParse("6G"),
+											},
+										},
+									},
+								},
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodRunning,
+							},
+						},
+					},
+				},
+				nodeCPUSet:    "0-15",
+				preBECPUSet:   "0-9",
+				preBECFSQuota: 8 * defaultCFSPeriod,
+				thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+					Enable:                      pointer.BoolPtr(false),
+					CPUSuppressPolicy:           slov1alpha1.CPUSetAndCfsQuotaPolicy,
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(70),
+				},
+			},
+			wantBECFSQuota:           -1,
+			wantCFSQuotaPolicyStatus: &policyRecovered,
+			wantBECPUSet:             "0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15",
+			wantCPUSetPolicyStatus:   &policyRecovered,
+		},
 		{
 			name: "reset cpuset and cfs quota if cpu qos disabled",
 			args: args{
@@ -637,6 +891,345 @@ func Test_cpuSuppress_suppressBECPU(t *testing.T) {
 	}
 }
 
+func Test_adjustBESuppressCPUByPSI(t *testing.T) {
+	lsPod := createTestPod(apiext.QoSLS, "ls_pod")
+	lsPod.Status.QOSClass = corev1.PodQOSBurstable
+	podMetas := []*statesinformer.PodMeta{
+		{Pod: lsPod},
+		{Pod: createTestPod(apiext.QoSBE, "be_pod")},
+	}
+	tests := []struct {
+		name         string
+		psiContent   string
+		psiThreshold *int64
+		lsPodUsed    string
+		want         int64
+	}{
+		{
+			name:         "high psi with moderate utilization tightens the suppression",
+			psiContent:   "some avg10=40.00 avg60=30.00 avg300=10.00 total=123456\n",
+			psiThreshold: pointer.Int64Ptr(20),
+			lsPodUsed:    "4",
+			want:         4500, // (14 - 4 - 1) * 20 / 40
+		},
+		{
+			name:         "low psi with high utilization keeps the suppression",
+			psiContent:   "some avg10=5.00 avg60=5.00 avg300=5.00 total=123456\n",
+			psiThreshold: pointer.Int64Ptr(20),
+			lsPodUsed:    "12",
+			want:         1000, // 14 - 12 - 1
+		},
+		{
+			name:         "high psi is ignored if the threshold is not set",
+			psiContent:   "some avg10=40.00 avg60=30.00 avg300=10.00 total=123456\n",
+			psiThreshold: nil,
+			lsPodUsed:    "4",
+			want:         9000, // 14 - 4 - 1
+		},
+		{
+			name:         "keep the suppression if psi is not supported",
+			psiThreshold: pointer.Int64Ptr(20),
+			lsPodUsed:    "4",
+			want:         9000, // 14 - 4 - 1
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			if tt.psiContent != "" {
+				helper.WriteProcSubFileContents(util.ProcCPUPressureFileName, tt.psiContent)
+			}
+
+			// node.Total * SLOPercent = 20 * 70% = 14, system.Used = nodeUsed - podsUsed = 1
+			lsPodUsed := resource.MustParse(tt.lsPodUsed)
+			nodeUsed := lsPodUsed.DeepCopy()
+			nodeUsed.Add(resource.MustParse("3"))
+			nodeMetric := &metriccache.NodeResourceMetric{CPUUsed: metriccache.CPUMetric{CPUUsed: nodeUsed}}
+			podMetrics := []*metriccache.PodResourceMetric{
+				{PodUID: "ls_pod", CPUUsed: metriccache.CPUMetric{CPUUsed: lsPodUsed}},
+				{PodUID: "be_pod", CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("2")}},
+			}
+			cpuSuppress := NewCPUSuppress(&resmanager{})
+			suppressCPU := cpuSuppress.calculateBESuppressCPU(getNode("20", "40G"), nodeMetric, podMetrics, podMetas, 70, "")
+			got := adjustBESuppressCPUByPSI(suppressCPU, tt.psiThreshold)
+			assert.Equal(t, tt.want, got.MilliValue())
+		})
+	}
+}
+
+func Test_reserveSystemCPU(t *testing.T) {
+	lsPod := createTestPod(apiext.QoSLS, "ls_pod")
+	lsPod.Status.QOSClass = corev1.PodQOSBurstable
+	podMetas := []*statesinformer.PodMeta{
+		{Pod: lsPod},
+		{Pod: createTestPod(apiext.QoSBE, "be_pod")},
+	}
+	tests := []struct {
+		name           string
+		reservePercent *int64
+		lsPodUsed      string
+		want           int64
+	}{
+		{
+			name:           "reserve the system headroom at high utilization",
+			reservePercent: pointer.Int64Ptr(5),
+			lsPodUsed:      "12",
+			want:           0, // 14 - 12 - 1 - 20 * 5%
+		},
+		{
+			name:           "reserve the system headroom at moderate utilization",
+			reservePercent: pointer.Int64Ptr(5),
+			lsPodUsed:      "4",
+			want:           8000, // 14 - 4 - 1 - 20 * 5%
+		},
+		{
+			name:           "reserve more than the remaining cpu",
+			reservePercent: pointer.Int64Ptr(10),
+			lsPodUsed:      "12",
+			want:           -1000, // 14 - 12 - 1 - 20 * 10%
+		},
+		{
+			name:           "no reserve if not set",
+			reservePercent: nil,
+			lsPodUsed:      "12",
+			want:           1000, // 14 - 12 - 1
+		},
+		{
+			name:           "no reserve if set 0",
+			reservePercent: pointer.Int64Ptr(0),
+			lsPodUsed:      "12",
+			want:           1000, // 14 - 12 - 1
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// node.Total * SLOPercent = 20 * 70% = 14, system.Used = nodeUsed - podsUsed = 1
+			lsPodUsed := resource.MustParse(tt.lsPodUsed)
+			nodeUsed := lsPodUsed.DeepCopy()
+			nodeUsed.Add(resource.MustParse("3"))
+			nodeMetric := &metriccache.NodeResourceMetric{CPUUsed: metriccache.CPUMetric{CPUUsed: nodeUsed}}
+			podMetrics := []*metriccache.PodResourceMetric{
+				{PodUID: "ls_pod", CPUUsed: metriccache.CPUMetric{CPUUsed: lsPodUsed}},
+				{PodUID: "be_pod", CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("2")}},
+			}
+			node := getNode("20", "40G")
+			cpuSuppress := NewCPUSuppress(&resmanager{})
+			suppressCPU := cpuSuppress.calculateBESuppressCPU(node, nodeMetric, podMetrics, podMetas, 70, "")
+			got := reserveSystemCPU(suppressCPU, node, tt.reservePercent)
+			assert.Equal(t, tt.want, got.MilliValue())
+		})
+	}
+}
+
+func Test_cpuSuppress_suppressBECPU_staleMetric(t *testing.T) {
+	tests := []struct {
+		name           string
+		metricAge      time.Duration
+		staleSeconds   int
+		wantStale      bool
+		wantBECPUSet   string
+		wantBECFSQuota int64
+	}{
+		{
+			name:           "release the suppression on stale metrics",
+			metricAge:      120 * time.Second,
+			staleSeconds:   60,
+			wantStale:      true,
+			wantBECPUSet:   "0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15",
+			wantBECFSQuota: -1,
+		},
+		{
+			name:           "keep the suppression if the staleness check is disabled",
+			metricAge:      120 * time.Second,
+			staleSeconds:   0,
+			wantStale:      false,
+			wantBECPUSet:   "0-9",
+			wantBECFSQuota: 8 * defaultCFSPeriod,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			pod := createTestPod(apiext.QoSBE, "test_be_pod")
+			si := mockstatesinformer.NewMockStatesInformer(ctl)
+			si.EXPECT().GetAllPods().Return(getPodMetas([]*corev1.Pod{pod})).AnyTimes()
+			si.EXPECT().GetNode().Return(getNode("16", "32G")).AnyTimes()
+
+			mc, err := metriccache.NewMetricCache(metriccache.NewDefaultConfig())
+			assert.NoError(t, err)
+			// the metric pipeline gets stuck after reporting a high usage
+			err = mc.InsertNodeResourceMetric(time.Now().Add(-tt.metricAge), &metriccache.NodeResourceMetric{
+				CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("15")},
+			})
+			assert.NoError(t, err)
+
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSGuaranteed), system.CPUSet, "0-15")
+			helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUSet, "0-9")
+			helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUCFSQuota, strconv.FormatInt(8*defaultCFSPeriod, 10))
+
+			cfg := NewDefaultConfig()
+			cfg.CPUSuppressMetricStaleSeconds = tt.staleSeconds
+			r := &resmanager{
+				statesInformer: si,
+				metricCache:    mc,
+				config:         cfg,
+				nodeSLO: getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+					Enable:                      pointer.BoolPtr(true),
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(70),
+				}),
+				collectResUsedIntervalSeconds: 1,
+			}
+			cpuSuppress := NewCPUSuppress(r)
+			assert.Equal(t, tt.wantStale, cpuSuppress.isNodeMetricStale())
+			cpuSuppress.suppressBECPU()
+
+			gotBECPUSet := helper.ReadCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUSet)
+			assert.Equal(t, tt.wantBECPUSet, gotBECPUSet)
+			gotBECFSQuota := helper.ReadCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUCFSQuota)
+			assert.Equal(t, strconv.FormatInt(tt.wantBECFSQuota, 10), gotBECFSQuota)
+		})
+	}
+}
+
+func Test_cpuSuppress_suppressBECPU_shadowThreshold(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	testingNode := getNode("16", "32G")
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	si := mockstatesinformer.NewMockStatesInformer(ctl)
+	si.EXPECT().GetAllPods().Return(getPodMetas([]*corev1.Pod{pod})).AnyTimes()
+	si.EXPECT().GetNode().Return(testingNode).AnyTimes()
+
+	mc := mockmetriccache.NewMockMetricCache(ctl)
+	mc.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("8")}},
+	}).AnyTimes()
+	mc.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{
+		Metric: &metriccache.PodResourceMetric{
+			PodUID:  string(pod.UID),
+			CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("2")},
+		},
+	}).AnyTimes()
+	mc.EXPECT().GetNodeCPUInfo(gomock.Any()).Return(&metriccache.NodeCPUInfo{}, nil).AnyTimes()
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSGuaranteed), system.CPUSet, "0-15")
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUSet, "0-15")
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUCFSQuota, strconv.FormatInt(10*defaultCFSPeriod, 10))
+
+	nodeSLO := getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		CPUSuppressPolicy:           slov1alpha1.CPUCfsQuotaPolicy,
+		CPUSuppressThresholdPercent: pointer.Int64Ptr(70),
+	})
+	nodeSLO.Spec.ShadowResourceUsedThresholdWithBE = &slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		CPUSuppressPolicy:           slov1alpha1.CPUCfsQuotaPolicy,
+		CPUSuppressThresholdPercent: pointer.Int64Ptr(50),
+	}
+	r := &resmanager{
+		statesInformer:                si,
+		metricCache:                   mc,
+		config:                        NewDefaultConfig(),
+		nodeSLO:                       nodeSLO,
+		collectResUsedIntervalSeconds: 1,
+	}
+
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.ThresholdStrategyDecision.Reset()
+
+	cpuSuppress := NewCPUSuppress(r)
+	cpuSuppress.suppressBECPU()
+
+	// suppress(BE) = 16 * threshold - system(8 - 2), where the active one is 5.2 and the shadow one is 2
+	assert.Equal(t, 5.2, testutil.ToFloat64(metrics.ThresholdStrategyDecision.WithLabelValues(
+		testingNode.Name, string(features.BECPUSuppress), metrics.ThresholdStrategyActive)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.ThresholdStrategyDecision.WithLabelValues(
+		testingNode.Name, string(features.BECPUSuppress), metrics.ThresholdStrategyShadow)))
+	// only the active suppression is applied
+	gotBECFSQuota := helper.ReadCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUCFSQuota)
+	assert.Equal(t, strconv.FormatInt(int64(5.2*float64(defaultCFSPeriod)), 10), gotBECFSQuota)
+
+	// nothing is reported without the shadow strategy
+	assert.Nil(t, cpuSuppress.evaluateShadowSuppress(nil, testingNode, nil, nil, nil, nil))
+}
+
+func Test_cpuSuppress_suppressBECPU_metrics(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	testingNode := getNode("16", "32G")
+	var pods []*corev1.Pod
+	for i := 0; i < 3; i++ {
+		pod := createTestPod(apiext.QoSBE, fmt.Sprintf("test_be_pod_%d", i))
+		pod.Status.Phase = corev1.PodRunning
+		pods = append(pods, pod)
+	}
+	// the completed BE pod is not suppressed
+	pods[2].Status.Phase = corev1.PodSucceeded
+	si := mockstatesinformer.NewMockStatesInformer(ctl)
+	si.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	si.EXPECT().GetNode().Return(testingNode).AnyTimes()
+
+	mc := mockmetriccache.NewMockMetricCache(ctl)
+	mc.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("8")}},
+	}).AnyTimes()
+	mc.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(podUID *string, param *metriccache.QueryParam) metriccache.PodResourceQueryResult {
+			return metriccache.PodResourceQueryResult{
+				Metric: &metriccache.PodResourceMetric{
+					PodUID:  *podUID,
+					CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("1")},
+				},
+			}
+		}).AnyTimes()
+	mc.EXPECT().GetNodeCPUInfo(gomock.Any()).Return(&metriccache.NodeCPUInfo{}, nil).AnyTimes()
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSGuaranteed), system.CPUSet, "0-15")
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUSet, "0-15")
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUCFSQuota, strconv.FormatInt(10*defaultCFSPeriod, 10))
+
+	r := &resmanager{
+		statesInformer: si,
+		metricCache:    mc,
+		config:         NewDefaultConfig(),
+		nodeSLO: getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+			Enable:                      pointer.BoolPtr(true),
+			CPUSuppressPolicy:           slov1alpha1.CPUCfsQuotaPolicy,
+			CPUSuppressThresholdPercent: pointer.Int64Ptr(70),
+		}),
+		collectResUsedIntervalSeconds: 1,
+	}
+
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.BECPUAllocation.Reset()
+	metrics.BESuppressedPods.Reset()
+
+	cpuSuppress := NewCPUSuppress(r)
+	cpuSuppress.suppressBECPU()
+
+	// suppress(BE) = 16 * threshold - system(8 - 3), where the running BE pods are suppressed
+	assert.Equal(t, 6.2, testutil.ToFloat64(metrics.BECPUAllocation.WithLabelValues(testingNode.Name)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.BESuppressedPods.WithLabelValues(testingNode.Name)))
+
+	// no pod is suppressed once the suppression is released
+	recordBESuppressMetrics(testingNode, getPodMetas(pods), nil)
+	assert.Equal(t, float64(16), testutil.ToFloat64(metrics.BECPUAllocation.WithLabelValues(testingNode.Name)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.BESuppressedPods.WithLabelValues(testingNode.Name)))
+}
+
 func Test_cpuSuppress_calculateBESuppressCPU(t *testing.T) {
 	type args struct {
 		node               *corev1.Node
@@ -864,7 +1457,87 @@ func Test_cpuSuppress_calculateBESuppressCPU(t *testing.T) {
 			r := resmanager{}
 			cpuSuppress := NewCPUSuppress(&r)
 			got := cpuSuppress.calculateBESuppressCPU(tt.args.node, tt.args.nodeMetric, tt.args.podMetrics, tt.args.podMetas,
-				tt.args.beCPUUsedThreshold)
+				tt.args.beCPUUsedThreshold, "")
+			assert.Equal(t, tt.want.MilliValue(), got.MilliValue())
+		})
+	}
+}
+
+func Test_cpuSuppress_calculateBESuppressCPU_calcPolicy(t *testing.T) {
+	newPodMeta := func(uid string, qos apiext.QoSClass, kubeQoS corev1.PodQOSClass, cpuRequest string,
+		phase corev1.PodPhase) *statesinformer.PodMeta {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   uid,
+				UID:    types.UID(uid),
+				Labels: map[string]string{apiext.LabelPodQoS: string(qos)},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "main"}},
+			},
+			Status: corev1.PodStatus{Phase: phase, QOSClass: kubeQoS},
+		}
+		if cpuRequest != "" {
+			pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse(cpuRequest),
+			}
+		}
+		return &statesinformer.PodMeta{Pod: pod}
+	}
+	newPodMetric := func(uid string, cpuUsed string) *metriccache.PodResourceMetric {
+		return &metriccache.PodResourceMetric{
+			PodUID:  uid,
+			CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse(cpuUsed)},
+		}
+	}
+	node := getNode("20", "40G")
+	nodeMetric := &metriccache.NodeResourceMetric{
+		CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("11")},
+	}
+	podMetas := []*statesinformer.PodMeta{
+		newPodMeta("ls_pod_request_more", apiext.QoSLS, corev1.PodQOSBurstable, "4", corev1.PodRunning),
+		newPodMeta("ls_pod_use_more", apiext.QoSLS, corev1.PodQOSBurstable, "2", corev1.PodRunning),
+		newPodMeta("ls_pod_no_metric", apiext.QoSLS, corev1.PodQOSBurstable, "1", corev1.PodPending),
+		newPodMeta("ls_pod_succeeded", apiext.QoSLS, corev1.PodQOSBurstable, "5", corev1.PodSucceeded),
+		newPodMeta("be_pod", apiext.QoSBE, corev1.PodQOSBestEffort, "", corev1.PodRunning),
+	}
+	podMetrics := []*metriccache.PodResourceMetric{
+		newPodMetric("ls_pod_request_more", "2"),
+		newPodMetric("ls_pod_use_more", "3"),
+		newPodMetric("be_pod", "4"),
+		newPodMetric("pod_meta_missing", "1"),
+	}
+	// node.Total * SLOPercent = 20 * 70% = 14, system.Used = 11 - (2 + 3 + 4 + 1) = 1
+	tests := []struct {
+		name       string
+		calcPolicy slov1alpha1.CPUSuppressCalcPolicy
+		want       *resource.Quantity
+	}{
+		{
+			name:       "calculate by usage as default",
+			calcPolicy: "",
+			want:       resource.NewQuantity(7, resource.DecimalSI), // 14 - (2 + 3 + 1) - 1
+		},
+		{
+			name:       "calculate by usage",
+			calcPolicy: slov1alpha1.CPUSuppressCalcByUsage,
+			want:       resource.NewQuantity(7, resource.DecimalSI), // 14 - (2 + 3 + 1) - 1
+		},
+		{
+			name:       "calculate by request",
+			calcPolicy: slov1alpha1.CPUSuppressCalcByRequest,
+			want:       resource.NewQuantity(5, resource.DecimalSI), // 14 - (4 + 2 + 1 + 1) - 1
+		},
+		{
+			name:       "calculate by the max of usage and request",
+			calcPolicy: slov1alpha1.CPUSuppressCalcByMax,
+			want:       resource.NewQuantity(4, resource.DecimalSI), // 14 - (4 + 3 + 1 + 1) - 1
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpuSuppress := NewCPUSuppress(&resmanager{})
+			got := cpuSuppress.calculateBESuppressCPU(node, nodeMetric, podMetrics, podMetas, 70, tt.calcPolicy)
 			assert.Equal(t, tt.want.MilliValue(), got.MilliValue())
 		})
 	}
@@ -993,6 +1666,101 @@ func Test_cpuSuppress_recoverCFSQuotaIfNeed(t *testing.T) {
 	}
 }
 
+func Test_cpuSuppress_recoverCPUSetIfNeed_releaseStep(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	beQoSDir := util.GetKubeQosRelativePath(corev1.PodQOSBestEffort)
+	podDirs := []string{"pod1", "pod2"}
+	testingPrepareBECgroupData(helper, podDirs, "7,6")
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSGuaranteed), system.CPUSet, "0-7")
+
+	cfg := NewDefaultConfig()
+	cfg.SuppressReleaseStepPercent = 50
+	cpuSuppress := NewCPUSuppress(&resmanager{config: cfg})
+	cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)] = policyUsing
+
+	// the be cpuset is enlarged by half of the lacked cpus in each cycle
+	for _, wantCPUSet := range []string{"7,6,2,1,0", "7,6,4,3,2,1,0"} {
+		cpuSuppress.recoverCPUSetIfNeed()
+		assert.Equal(t, policyUsing, cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)])
+		assert.Equal(t, wantCPUSet, helper.ReadCgroupFileContents(beQoSDir, system.CPUSet))
+		for _, podDir := range podDirs {
+			assert.Equal(t, wantCPUSet, helper.ReadCgroupFileContents(filepath.Join(beQoSDir, podDir), system.CPUSet))
+		}
+	}
+
+	// the last cpu is released at once
+	cpuSuppress.recoverCPUSetIfNeed()
+	assert.Equal(t, policyRecovered, cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)])
+	assert.Equal(t, "0,1,2,3,4,5,6,7", helper.ReadCgroupFileContents(beQoSDir, system.CPUSet))
+}
+
+func Test_cpuSuppress_recoverCPUSetIfNeed_releaseStepExemptPod(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	beQoSDir := util.GetKubeQosRelativePath(corev1.PodQOSBestEffort)
+	testingPrepareBECgroupData(helper, []string{"pod1", "pod2"}, "7,6")
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSGuaranteed), system.CPUSet, "0-7")
+	// the exempt pod and its container keep a pinned cpuset
+	helper.WriteCgroupFileContents(filepath.Join(beQoSDir, "pod2"), system.CPUSet, "7")
+	helper.WriteCgroupFileContents(filepath.Join(beQoSDir, "pod2", "container0"), system.CPUSet, "7")
+
+	exemptPod := createTestPod(apiext.QoSBE, "pod2")
+	exemptPod.Annotations = map[string]string{apiext.AnnotationPodCPUSetSuppressExempt: "true"}
+	si := mockstatesinformer.NewMockStatesInformer(ctl)
+	si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{
+		{Pod: createTestPod(apiext.QoSBE, "pod1"),
+			CgroupDir: filepath.Join(system.CgroupPathFormatter.QOSDirFn(corev1.PodQOSBestEffort), "pod1")},
+		{Pod: exemptPod,
+			CgroupDir: filepath.Join(system.CgroupPathFormatter.QOSDirFn(corev1.PodQOSBestEffort), "pod2")},
+	}).AnyTimes()
+	cfg := NewDefaultConfig()
+	cfg.SuppressReleaseStepPercent = 50
+	cpuSuppress := NewCPUSuppress(&resmanager{config: cfg, statesInformer: si})
+	cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)] = policyUsing
+
+	// the be cpuset is enlarged while the exempt pod keeps its cpuset
+	cpuSuppress.recoverCPUSetIfNeed()
+	assert.Equal(t, policyUsing, cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUSetPolicy)])
+	assert.Equal(t, "7,6,2,1,0", helper.ReadCgroupFileContents(beQoSDir, system.CPUSet))
+	assert.Equal(t, "7,6,2,1,0", helper.ReadCgroupFileContents(filepath.Join(beQoSDir, "pod1"), system.CPUSet))
+	assert.Equal(t, "7", helper.ReadCgroupFileContents(filepath.Join(beQoSDir, "pod2"), system.CPUSet))
+	assert.Equal(t, "7", helper.ReadCgroupFileContents(filepath.Join(beQoSDir, "pod2", "container0"), system.CPUSet))
+}
+
+func Test_cpuSuppress_recoverCFSQuotaIfNeed_releaseStep(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	beQosDir := util.GetKubeQosRelativePath(corev1.PodQOSBestEffort)
+	helper.CreateCgroupFile(beQosDir, system.CPUCFSQuota)
+	helper.WriteCgroupFileContents(beQosDir, system.CPUCFSQuota, strconv.FormatInt(2*cfsPeriod, 10))
+
+	si := mockstatesinformer.NewMockStatesInformer(ctl)
+	si.EXPECT().GetNode().Return(getNode("10", "20Gi")).AnyTimes()
+	cfg := NewDefaultConfig()
+	cfg.SuppressReleaseStepPercent = 50
+	cpuSuppress := NewCPUSuppress(&resmanager{config: cfg, statesInformer: si})
+	cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)] = policyUsing
+
+	// the be quota restores half of the gap to the node capacity in each cycle
+	for _, wantQuota := range []int64{6 * cfsPeriod, 8 * cfsPeriod, 9 * cfsPeriod} {
+		cpuSuppress.recoverCFSQuotaIfNeed()
+		assert.Equal(t, policyUsing, cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)])
+		assert.Equal(t, strconv.FormatInt(wantQuota, 10), helper.ReadCgroupFileContents(beQosDir, system.CPUCFSQuota))
+	}
+
+	// the quota is released at once when the gap is small enough
+	for i := 0; i < 10 && cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)] != policyRecovered; i++ {
+		cpuSuppress.recoverCFSQuotaIfNeed()
+	}
+	assert.Equal(t, policyRecovered, cpuSuppress.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)])
+	assert.Equal(t, "-1", helper.ReadCgroupFileContents(beQosDir, system.CPUCFSQuota))
+}
+
 func Test_calculateBESuppressCPUSetPolicy(t *testing.T) {
 	type args struct {
 		cpusetQuantity *resource.Quantity
@@ -1205,7 +1973,7 @@ func Test_applyBESuppressCPUSetPolicy(t *testing.T) {
 	oldCPUSet, err := util.GetRootCgroupCurCPUSet(corev1.PodQOSBestEffort)
 	assert.NoError(t, err)
 
-	err = applyBESuppressCPUSetPolicy(cpuset, oldCPUSet)
+	err = applyBESuppressCPUSetPolicy(cpuset, oldCPUSet, nil)
 	assert.NoError(t, err)
 	gotCPUSetBECgroup := helper.ReadCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUSet)
 	assert.Equal(t, wantCPUSetStr, gotCPUSetBECgroup, "checkBECPUSet")
@@ -1292,7 +2060,7 @@ func Test_adjustByCPUSet(t *testing.T) {
 			podDirs := []string{"pod1", "pod2", "pod3"}
 			testingPrepareBECgroupData(helper, podDirs, tt.args.oldCPUSets)
 
-			adjustByCPUSet(tt.args.cpusetQuantity, tt.args.nodeCPUInfo)
+			adjustByCPUSet(tt.args.cpusetQuantity, tt.args.nodeCPUInfo, nil)
 
 			gotCPUSetBECgroup := helper.ReadCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUSet)
 			assert.Equal(t, tt.wantCPUSet, gotCPUSetBECgroup, "checkBECPUSet")
@@ -1304,6 +2072,47 @@ func Test_adjustByCPUSet(t *testing.T) {
 	}
 }
 
+func Test_adjustByCPUSet_exemptPod(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	nodeCPUInfo := &metriccache.NodeCPUInfo{
+		ProcessorInfos: []util.ProcessorInfo{
+			{CPUID: 0, CoreID: 0, SocketID: 0, NodeID: 0},
+			{CPUID: 1, CoreID: 0, SocketID: 0, NodeID: 0},
+			{CPUID: 2, CoreID: 1, SocketID: 0, NodeID: 0},
+			{CPUID: 3, CoreID: 1, SocketID: 0, NodeID: 0},
+			{CPUID: 4, CoreID: 2, SocketID: 1, NodeID: 1},
+			{CPUID: 5, CoreID: 2, SocketID: 1, NodeID: 1},
+			{CPUID: 6, CoreID: 3, SocketID: 1, NodeID: 1},
+			{CPUID: 7, CoreID: 3, SocketID: 1, NodeID: 1},
+		},
+	}
+	beQoSDir := util.GetKubeQosRelativePath(corev1.PodQOSBestEffort)
+	podDirs := []string{"pod1", "pod2", "pod3"}
+	testingPrepareBECgroupData(helper, podDirs, "7,6,3,2")
+	// the exempt pod and its container keep a pinned cpuset
+	helper.WriteCgroupFileContents(filepath.Join(beQoSDir, "pod2"), system.CPUSet, "2,1")
+	helper.WriteCgroupFileContents(filepath.Join(beQoSDir, "pod2", "container0"), system.CPUSet, "2")
+
+	exemptPod := createTestPod(apiext.QoSBE, "pod2")
+	exemptPod.Annotations = map[string]string{apiext.AnnotationPodCPUSetSuppressExempt: "true"}
+	podMetas := []*statesinformer.PodMeta{
+		{Pod: createTestPod(apiext.QoSBE, "pod1"),
+			CgroupDir: filepath.Join(system.CgroupPathFormatter.QOSDirFn(corev1.PodQOSBestEffort), "pod1")},
+		{Pod: exemptPod,
+			CgroupDir: filepath.Join(system.CgroupPathFormatter.QOSDirFn(corev1.PodQOSBestEffort), "pod2")},
+	}
+
+	adjustByCPUSet(resource.NewQuantity(3, resource.DecimalSI), nodeCPUInfo, getCPUSetSuppressExemptPodDirs(podMetas))
+
+	// the be root cgroup still contains the cpuset of the exempt pod
+	assert.Equal(t, "7,6,3,2,1", helper.ReadCgroupFileContents(beQoSDir, system.CPUSet))
+	assert.Equal(t, "7,6,3", helper.ReadCgroupFileContents(filepath.Join(beQoSDir, "pod1"), system.CPUSet))
+	assert.Equal(t, "7,6,3", helper.ReadCgroupFileContents(filepath.Join(beQoSDir, "pod3"), system.CPUSet))
+	assert.Equal(t, "2,1", helper.ReadCgroupFileContents(filepath.Join(beQoSDir, "pod2"), system.CPUSet))
+	assert.Equal(t, "2", helper.ReadCgroupFileContents(filepath.Join(beQoSDir, "pod2", "container0"), system.CPUSet))
+}
+
 func Test_adjustByCfsQuota(t *testing.T) {
 	helper := system.NewFileTestUtil(t)
 	defer helper.Cleanup()
@@ -1361,7 +2170,7 @@ func Test_adjustByCfsQuota(t *testing.T) {
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
 			helper.WriteCgroupFileContents(beQosDir, system.CPUCFSQuota, strconv.FormatInt(tt.preBECfsQuota, 10))
-			adjustByCfsQuota(tt.cpuQuantity, node)
+			adjustByCfsQuota(tt.cpuQuantity, node, nil)
 			gotBECfsQuota := helper.ReadCgroupFileContents(beQosDir, system.CPUCFSQuota)
 			if gotBECfsQuota != strconv.FormatInt(tt.wantBECfsQuota, 10) {
 				t.Errorf("failed to adjustByCfsQuota, want file %v cfs_quota %v, got %v", system.GetCgroupFilePath(beQosDir, system.CPUCFSQuota), tt.wantBECfsQuota,
@@ -1372,6 +2181,71 @@ func Test_adjustByCfsQuota(t *testing.T) {
 	}
 }
 
+func Test_adjustByCfsQuota_ramp(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	beQosDir := util.GetKubeQosRelativePath(corev1.PodQOSBestEffort)
+	helper.CreateCgroupFile(beQosDir, system.CPUCFSQuota)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node0",
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("80"),
+			},
+		},
+	}
+	type args struct {
+		name            string
+		cpuQuantity     *resource.Quantity
+		stepPercent     int64
+		preBECfsQuota   int64
+		wantBECfsQuotas []int64
+	}
+	testCases := []args{
+		{
+			name:            "suppress beCPU step by step",
+			cpuQuantity:     resource.NewMilliQuantity(20*1000, resource.BinarySI),
+			stepPercent:     5,
+			preBECfsQuota:   36 * cfsPeriod,
+			wantBECfsQuotas: []int64{32 * cfsPeriod, 28 * cfsPeriod, 24 * cfsPeriod, 20 * cfsPeriod, 20 * cfsPeriod},
+		},
+		{
+			name:            "suppress beCPU from unlimited quota",
+			cpuQuantity:     resource.NewMilliQuantity(40*1000, resource.BinarySI),
+			stepPercent:     20,
+			preBECfsQuota:   -1,
+			wantBECfsQuotas: []int64{64 * cfsPeriod, 48 * cfsPeriod, 40 * cfsPeriod, 40 * cfsPeriod},
+		},
+		{
+			name:            "suppress beCPU directly while the target is within the step",
+			cpuQuantity:     resource.NewMilliQuantity(20*1000, resource.BinarySI),
+			stepPercent:     10,
+			preBECfsQuota:   24 * cfsPeriod,
+			wantBECfsQuotas: []int64{20 * cfsPeriod},
+		},
+		{
+			name:            "increase CFSQuota step by step",
+			cpuQuantity:     resource.NewMilliQuantity(20*1000, resource.BinarySI),
+			stepPercent:     5,
+			preBECfsQuota:   10 * cfsPeriod,
+			wantBECfsQuotas: []int64{14 * cfsPeriod, 18 * cfsPeriod, 20 * cfsPeriod},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			helper.WriteCgroupFileContents(beQosDir, system.CPUCFSQuota, strconv.FormatInt(tt.preBECfsQuota, 10))
+			for i, want := range tt.wantBECfsQuotas {
+				adjustByCfsQuota(tt.cpuQuantity, node, &tt.stepPercent)
+				got := helper.ReadCgroupFileContents(beQosDir, system.CPUCFSQuota)
+				assert.Equal(t, strconv.FormatInt(want, 10), got, "cycle %d", i)
+			}
+		})
+	}
+}
+
 func Test_writeBECgroupsCPUSet(t *testing.T) {
 	// prepare testing files
 	helper := system.NewFileTestUtil(t)
@@ -1420,3 +2294,49 @@ func getNodeSLOByThreshold(thresholdConfig *slov1alpha1.ResourceThresholdStrateg
 		},
 	}
 }
+
+func Test_cpuSuppress_suppressBECPU_emptyPodMetrics(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	si := mockstatesinformer.NewMockStatesInformer(ctl)
+	si.EXPECT().GetAllPods().Return(getPodMetas([]*corev1.Pod{pod})).AnyTimes()
+	si.EXPECT().GetNode().Return(getNode("16", "32G")).AnyTimes()
+
+	// the metric cache just restarts, so only the node metric is collected
+	mc := mockmetriccache.NewMockMetricCache(ctl)
+	mc.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("10")},
+		},
+	}).AnyTimes()
+	mc.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{}).AnyTimes()
+	mc.EXPECT().GetNodeCPUInfo(gomock.Any()).Return(testNodeInfo, nil).AnyTimes()
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSGuaranteed), system.CPUSet, "0-15")
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUSet, "0-9")
+	helper.WriteCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUCFSQuota, strconv.FormatInt(8*defaultCFSPeriod, 10))
+
+	r := &resmanager{
+		statesInformer: si,
+		metricCache:    mc,
+		config:         NewDefaultConfig(),
+		nodeSLO: getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+			Enable:                      pointer.BoolPtr(true),
+			CPUSuppressThresholdPercent: pointer.Int64Ptr(70),
+			CPUSuppressPolicy:           slov1alpha1.CPUSetAndCfsQuotaPolicy,
+		}),
+		collectResUsedIntervalSeconds: 1,
+	}
+	cpuSuppress := NewCPUSuppress(r)
+	cpuSuppress.suppressBECPU()
+
+	// the missing pod metrics are not taken as zero usage, so the be cgroups are kept
+	gotBECPUSet := helper.ReadCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUSet)
+	assert.Equal(t, "0-9", gotBECPUSet)
+	gotBECFSQuota := helper.ReadCgroupFileContents(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort), system.CPUCFSQuota)
+	assert.Equal(t, strconv.FormatInt(8*defaultCFSPeriod, 10), gotBECFSQuota)
+}
@@ -0,0 +1,132 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// defaultDecisionLog serves the decision log of the running resmanager for the debug http handler.
+	defaultDecisionLog = &decisionLogRef{}
+)
+
+// ReconcileDecision is an action taken by a reconciler of the resmanager with the inputs it decides by.
+type ReconcileDecision struct {
+	Time    time.Time `json:"time"`
+	Feature string    `json:"feature"`
+	Inputs  string    `json:"inputs"`
+	Action  string    `json:"action"`
+}
+
+// decisionLog is a bounded ring buffer of the last reconcile decisions for the post-incident analysis. The entries
+// are preallocated and overwritten in place, so recording a decision allocates nothing besides the strings passed in.
+// A nil decisionLog is valid and records nothing.
+type decisionLog struct {
+	lock    sync.Mutex
+	entries []ReconcileDecision
+	// next is the index to write the next decision, and count the number of the recorded decisions
+	next  int
+	count int
+}
+
+// newDecisionLog returns a decisionLog retaining the last size decisions, or nil if size is not positive.
+func newDecisionLog(size int) *decisionLog {
+	if size <= 0 {
+		return nil
+	}
+	return &decisionLog{entries: make([]ReconcileDecision, size)}
+}
+
+func (l *decisionLog) record(feature featuregate.Feature, inputs, action string) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	entry := &l.entries[l.next]
+	entry.Time = time.Now()
+	entry.Feature = string(feature)
+	entry.Inputs = inputs
+	entry.Action = action
+	l.next = (l.next + 1) % len(l.entries)
+	if l.count < len(l.entries) {
+		l.count++
+	}
+}
+
+// list returns the recorded decisions from the oldest to the latest.
+func (l *decisionLog) list() []ReconcileDecision {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	decisions := make([]ReconcileDecision, 0, l.count)
+	start := (l.next - l.count + len(l.entries)) % len(l.entries)
+	for i := 0; i < l.count; i++ {
+		decisions = append(decisions, l.entries[(start+i)%len(l.entries)])
+	}
+	return decisions
+}
+
+type decisionLogRef struct {
+	lock sync.RWMutex
+	log  *decisionLog
+}
+
+func (r *decisionLogRef) set(log *decisionLog) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.log = log
+}
+
+func (r *decisionLogRef) get() *decisionLog {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.log
+}
+
+// ReconcileDecisionsHttpHandler returns the http handler to dump the last reconcile decisions of the resmanager.
+func ReconcileDecisionsHttpHandler() func(http.ResponseWriter, *http.Request) {
+	return defaultDecisionLog.httpHandler()
+}
+
+func (r *decisionLogRef) httpHandler() func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		log := r.get()
+		if log == nil {
+			http.Error(rw, "reconcile decision log is disabled", http.StatusServiceUnavailable)
+			return
+		}
+		data, err := json.Marshal(log.list())
+		if err != nil {
+			http.Error(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		if _, err = rw.Write(data); err != nil {
+			klog.Warningf("failed to write reconcile decisions to client %v, error %v", req.RemoteAddr, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+func Test_decisionLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		records    int
+		wantInputs []string
+	}{
+		{
+			name:       "not full",
+			size:       3,
+			records:    2,
+			wantInputs: []string{"0", "1"},
+		},
+		{
+			name:       "exactly full",
+			size:       3,
+			records:    3,
+			wantInputs: []string{"0", "1", "2"},
+		},
+		{
+			name:       "overwrite the oldest decisions",
+			size:       3,
+			records:    7,
+			wantInputs: []string{"4", "5", "6"},
+		},
+		{
+			name:       "empty",
+			size:       3,
+			records:    0,
+			wantInputs: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDecisionLog(tt.size)
+			for i := 0; i < tt.records; i++ {
+				l.record(features.BECPUSuppress, strconv.Itoa(i), "suppress")
+			}
+			decisions := l.list()
+			gotInputs := make([]string, 0, len(decisions))
+			for _, decision := range decisions {
+				assert.Equal(t, string(features.BECPUSuppress), decision.Feature)
+				assert.Equal(t, "suppress", decision.Action)
+				gotInputs = append(gotInputs, decision.Inputs)
+			}
+			assert.Equal(t, tt.wantInputs, gotInputs)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		l := newDecisionLog(0)
+		assert.Nil(t, l)
+		l.record(features.BECPUSuppress, "", "suppress")
+		assert.Nil(t, l.list())
+	})
+}
+
+func Test_decisionLogRef_httpHandler(t *testing.T) {
+	ref := &decisionLogRef{}
+	handler := ref.httpHandler()
+
+	rw := httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodGet, "/debug/reconciledecisions", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+
+	l := newDecisionLog(2)
+	l.record(features.BEMemoryEvict, "memoryUsed=100", "killed 1 be pods")
+	ref.set(l)
+	rw = httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodGet, "/debug/reconciledecisions", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	var got []ReconcileDecision
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &got))
+	assert.Len(t, got, 1)
+	assert.Equal(t, string(features.BEMemoryEvict), got[0].Feature)
+	assert.Equal(t, "killed 1 be pods", got[0].Action)
+}
+
+func Test_cpuSuppress_recordDecision(t *testing.T) {
+	r := NewCPUSuppress(&resmanager{decisionLog: newDecisionLog(4)})
+	r.recordDecision("nodeCPUUsed=1000m", "suppress be cpu to 2000m by policy cpuset")
+	r.recordDecision("nodeCPUUsed=1100m", "suppress be cpu to 2000m by policy cpuset")
+	r.recordDecision("nodeCPUUsed=1500m", "suppress be cpu to 1500m by policy cpuset")
+
+	// only the changed actions are recorded
+	decisions := r.resmanager.decisionLog.list()
+	assert.Len(t, decisions, 2)
+	assert.Equal(t, "nodeCPUUsed=1000m", decisions[0].Inputs)
+	assert.Equal(t, "suppress be cpu to 1500m by policy cpuset", decisions[1].Action)
+}
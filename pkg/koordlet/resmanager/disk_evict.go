@@ -0,0 +1,189 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+)
+
+type DiskEvictor struct {
+	resManager    *resmanager
+	lastEvictTime time.Time
+}
+
+func NewDiskEvictor(mgr *resmanager) *DiskEvictor {
+	return &DiskEvictor{
+		resManager:    mgr,
+		lastEvictTime: time.Now(),
+	}
+}
+
+func (d *DiskEvictor) diskEvict() {
+	klog.V(4).Infof("starting disk evict process")
+	defer klog.V(4).Infof("disk evict process completed")
+
+	if time.Now().Before(d.lastEvictTime.Add(time.Duration(d.resManager.config.DiskEvictCoolTimeSeconds) * time.Second)) {
+		klog.Infof("skip disk evict process, still in evict cooling time")
+		return
+	}
+
+	nodeSLO := d.resManager.getNodeSLOCopy()
+	if disabled, err := isFeatureDisabled(nodeSLO, features.BEDiskEvict); err != nil {
+		klog.Errorf("failed to acquire disk eviction feature-gate, error: %v", err)
+		return
+	} else if disabled {
+		klog.Warningf("skip disk evict, disabled in NodeSLO")
+		return
+	}
+
+	thresholdPercent := nodeSLO.Spec.ResourceUsedThresholdWithBE.DiskUsedThresholdPercent
+	if thresholdPercent == nil {
+		klog.V(5).Infof("skip disk evict, threshold percent is nil")
+		return
+	} else if *thresholdPercent < 0 {
+		klog.Warningf("skip disk evict, threshold percent(%v) should greater than 0", *thresholdPercent)
+		return
+	}
+
+	nodeMetric, podMetrics := d.resManager.collectNodeAndPodMetricLast()
+	if nodeMetric == nil || podMetrics == nil {
+		klog.Warningf("skip disk evict, got nil node metric or nil pod metrics, nodeMetric %v, podMetrics %v",
+			nodeMetric, podMetrics)
+		return
+	}
+
+	node := d.resManager.statesInformer.GetNode()
+	if node == nil {
+		klog.Warningf("skip disk evict, Node %v is nil", d.resManager.nodeName)
+		return
+	}
+
+	diskCapacity := node.Status.Capacity.StorageEphemeral().Value()
+	if diskCapacity <= 0 {
+		klog.Warningf("skip disk evict, ephemeral storage capacity(%v) should greater than 0", diskCapacity)
+		return
+	}
+
+	diskUsed := nodeMetric.DiskUsed.DiskUsed.Value()
+	nodeDiskUsage := diskUsed * 100 / diskCapacity
+	if nodeDiskUsage < *thresholdPercent {
+		if d.resManager.config.EvictOnNodePressureCondition && isNodeConditionTrue(node, corev1.NodeDiskPressure) {
+			d.evictBEPodByNodeCondition(node, podMetrics)
+			return
+		}
+		klog.V(5).Infof("skip disk evict, node disk usage(%v) is below threshold(%v)", nodeDiskUsage, *thresholdPercent)
+		return
+	}
+
+	klog.Infof("node(%v) DiskUsage(%v): %.2f, evictThresholdUsage: %.2f", d.resManager.nodeName, diskUsed,
+		float64(nodeDiskUsage)/100, float64(*thresholdPercent)/100)
+
+	d.evictBEPods(node, podMetrics, diskCapacity, diskUsed, *thresholdPercent)
+}
+
+// evictBEPods evicts BE pods by the order of the disk usage until the node disk usage drops below the threshold.
+// Since the disk of the evicted pods is released lazily, the node disk usage is estimated by subtracting the disk
+// usage of the evicted pods.
+func (d *DiskEvictor) evictBEPods(node *corev1.Node, podMetrics []*metriccache.PodResourceMetric,
+	diskCapacity, diskUsed, thresholdPercent int64) {
+	bePodInfos := d.getSortedPodInfos(podMetrics)
+	diskUpperBound := diskCapacity * thresholdPercent / 100
+	message := fmt.Sprintf("evictBEPods for node(%v), need to release disk: %v, disk used %v(%v%%) of capacity %v, "+
+		"threshold %v%%", d.resManager.nodeName, diskUsed-diskUpperBound, diskUsed, diskUsed*100/diskCapacity,
+		diskCapacity, thresholdPercent)
+
+	pressure := fmt.Sprintf("diskCapacity=%v diskUsed=%v thresholdPercent=%v", diskCapacity, diskUsed, thresholdPercent)
+	summary := newEvictionSummary(features.BEDiskEvict, evictPodByNodeDiskUsage, pressure)
+	evictedCount := 0
+	diskReleased := int64(0)
+	for _, bePod := range bePodInfos {
+		if diskUsed-diskReleased < diskUpperBound {
+			break
+		}
+		summary.consider()
+		d.resManager.evictPodIfNotEvicted(bePod.pod, node, evictPodByNodeDiskUsage, message)
+		evictedCount++
+		summary.evict()
+		diskReleased += bePod.podMetric.DiskUsed.DiskUsed.Value()
+	}
+
+	d.lastEvictTime = time.Now()
+	klog.Infof("disk evictBEPods completed, evicted pods %v, diskUpperBound(%v) diskUsed(%v) diskReleased(%v)",
+		evictedCount, diskUpperBound, diskUsed, diskReleased)
+	d.resManager.decisionLog.record(features.BEDiskEvict, pressure,
+		fmt.Sprintf("evicted %v be pods, diskReleased=%v", evictedCount, diskReleased))
+	d.resManager.logEvictionSummary(summary)
+}
+
+// evictBEPodByNodeCondition evicts the BE pod with the most disk usage while the node reports the DiskPressure
+// condition, so the kubelet is less likely to evict the LS pods by its own thresholds.
+func (d *DiskEvictor) evictBEPodByNodeCondition(node *corev1.Node, podMetrics []*metriccache.PodResourceMetric) {
+	bePodInfos := d.getSortedPodInfos(podMetrics)
+	message := fmt.Sprintf("evictBEPodByNodeCondition for node(%v), node condition DiskPressure is true",
+		d.resManager.nodeName)
+	pressure := fmt.Sprintf("nodeCondition=%v", corev1.NodeDiskPressure)
+	summary := newEvictionSummary(features.BEDiskEvict, evictPodByNodeDiskUsage, pressure)
+	evictedPod := ""
+	for _, bePod := range bePodInfos {
+		summary.consider()
+		d.resManager.evictPodIfNotEvicted(bePod.pod, node, evictPodByNodeDiskUsage, message)
+		summary.evict()
+		evictedPod = bePod.pod.Namespace + "/" + bePod.pod.Name
+		// a single pod is evicted for each round
+		break
+	}
+
+	d.lastEvictTime = time.Now()
+	klog.Infof("disk evictBEPodByNodeCondition completed, evicted pod %q", evictedPod)
+	d.resManager.decisionLog.record(features.BEDiskEvict, pressure, fmt.Sprintf("evicted be pod %q", evictedPod))
+	d.resManager.logEvictionSummary(summary)
+}
+
+// getSortedPodInfos returns the BE pods sorted by the disk usage in descending order.
+func (d *DiskEvictor) getSortedPodInfos(podMetrics []*metriccache.PodResourceMetric) []*podInfo {
+	podMetricMap := make(map[string]*metriccache.PodResourceMetric, len(podMetrics))
+	for _, podMetric := range podMetrics {
+		podMetricMap[podMetric.PodUID] = podMetric
+	}
+
+	var bePodInfos []*podInfo
+	for _, podMeta := range d.resManager.statesInformer.GetAllPods() {
+		pod := podMeta.Pod
+		if !d.resManager.isEnforcementEligible(pod) || extension.GetPodQoSClass(pod) != extension.QoSBE {
+			continue
+		}
+		podMetric, ok := podMetricMap[string(pod.UID)]
+		if !ok {
+			continue
+		}
+		bePodInfos = append(bePodInfos, &podInfo{pod: pod, podMetric: podMetric})
+	}
+
+	sort.Slice(bePodInfos, func(i, j int) bool {
+		return bePodInfos[i].podMetric.DiskUsed.DiskUsed.Value() > bePodInfos[j].podMetric.DiskUsed.DiskUsed.Value()
+	})
+	return bePodInfos
+}
@@ -0,0 +1,241 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/tools/cache"
+)
+
+func Test_diskEvict(t *testing.T) {
+	tests := []struct {
+		name             string
+		thresholdConfig  *slov1alpha1.ResourceThresholdStrategy
+		nodeDiskUsed     string
+		podDiskUsed      map[string]string
+		diskPressure     bool
+		expectEvictedPod map[string]bool
+	}{
+		{
+			name: "skip evict when the threshold is not set",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable: pointer.BoolPtr(true),
+			},
+			nodeDiskUsed: "90G",
+			podDiskUsed: map[string]string{
+				"test_be_pod_0": "5G",
+				"test_be_pod_1": "12G",
+				"test_be_pod_2": "3G",
+				"test_ls_pod_0": "50G",
+			},
+			expectEvictedPod: map[string]bool{},
+		},
+		{
+			name: "skip evict when the strategy is disabled",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                   pointer.BoolPtr(false),
+				DiskUsedThresholdPercent: pointer.Int64Ptr(80),
+			},
+			nodeDiskUsed: "90G",
+			podDiskUsed: map[string]string{
+				"test_be_pod_0": "5G",
+				"test_be_pod_1": "12G",
+				"test_be_pod_2": "3G",
+				"test_ls_pod_0": "50G",
+			},
+			expectEvictedPod: map[string]bool{},
+		},
+		{
+			name: "skip evict when the node disk usage is below the threshold",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                   pointer.BoolPtr(true),
+				DiskUsedThresholdPercent: pointer.Int64Ptr(80),
+			},
+			nodeDiskUsed: "70G",
+			podDiskUsed: map[string]string{
+				"test_be_pod_0": "5G",
+				"test_be_pod_1": "12G",
+				"test_be_pod_2": "3G",
+				"test_ls_pod_0": "50G",
+			},
+			expectEvictedPod: map[string]bool{},
+		},
+		{
+			name: "evict the be pod with the highest disk usage",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                   pointer.BoolPtr(true),
+				DiskUsedThresholdPercent: pointer.Int64Ptr(80),
+			},
+			nodeDiskUsed: "90G",
+			podDiskUsed: map[string]string{
+				"test_be_pod_0": "5G",
+				"test_be_pod_1": "12G",
+				"test_be_pod_2": "3G",
+				"test_ls_pod_0": "50G",
+			},
+			expectEvictedPod: map[string]bool{
+				"test_be_pod_1": true,
+			},
+		},
+		{
+			name: "evict be pods until the node disk usage drops below the threshold",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                   pointer.BoolPtr(true),
+				DiskUsedThresholdPercent: pointer.Int64Ptr(75),
+			},
+			nodeDiskUsed: "90G",
+			podDiskUsed: map[string]string{
+				"test_be_pod_0": "5G",
+				"test_be_pod_1": "12G",
+				"test_be_pod_2": "3G",
+				"test_ls_pod_0": "50G",
+			},
+			expectEvictedPod: map[string]bool{
+				"test_be_pod_0": true,
+				"test_be_pod_1": true,
+			},
+		},
+		{
+			name: "evict the be pod with the highest disk usage when the node reports disk pressure",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                   pointer.BoolPtr(true),
+				DiskUsedThresholdPercent: pointer.Int64Ptr(80),
+			},
+			nodeDiskUsed: "70G",
+			podDiskUsed: map[string]string{
+				"test_be_pod_0": "5G",
+				"test_be_pod_1": "12G",
+				"test_be_pod_2": "3G",
+				"test_ls_pod_0": "50G",
+			},
+			diskPressure: true,
+			expectEvictedPod: map[string]bool{
+				"test_be_pod_1": true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			pods := []*corev1.Pod{
+				createMemoryEvictTestPod("test_be_pod_0", apiext.QoSBE, 100),
+				createMemoryEvictTestPod("test_be_pod_1", apiext.QoSBE, 100),
+				createMemoryEvictTestPod("test_be_pod_2", apiext.QoSBE, 100),
+				createMemoryEvictTestPod("test_ls_pod_0", apiext.QoSLS, 500),
+			}
+			node := getNode("80", "120G")
+			node.Status.Capacity[corev1.ResourceEphemeralStorage] = resource.MustParse("100G")
+			if tt.diskPressure {
+				node.Status.Conditions = []corev1.NodeCondition{
+					{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+				}
+			}
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(node).AnyTimes()
+
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+				Metric: &metriccache.NodeResourceMetric{
+					DiskUsed: metriccache.DiskMetric{DiskUsed: resource.MustParse(tt.nodeDiskUsed)},
+				},
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(metriccache.PodResourceQueryResult{
+					Metric: &metriccache.PodResourceMetric{
+						PodUID:   podUID,
+						DiskUsed: metriccache.DiskMetric{DiskUsed: resource.MustParse(tt.podDiskUsed[pod.Name])},
+					},
+				}).AnyTimes()
+			}
+
+			fakeRecorder := &FakeRecorder{}
+			client := clientsetfake.NewSimpleClientset()
+			cfg := NewDefaultConfig()
+			cfg.EvictOnNodePressureCondition = true
+			r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+				eventRecorder: fakeRecorder, kubeClient: client, nodeSLO: getNodeSLOByThreshold(tt.thresholdConfig), config: cfg}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			diskEvictor := NewDiskEvictor(r)
+			diskEvictor.lastEvictTime = time.Now().Add(-time.Duration(r.config.DiskEvictCoolTimeSeconds) * time.Second)
+			diskEvictor.diskEvict()
+
+			for _, pod := range pods {
+				_, evicted := r.podsEvicted.Get(string(pod.UID))
+				assert.Equal(t, tt.expectEvictedPod[pod.Name], evicted, "check evicted for pod %s", pod.Name)
+			}
+		})
+	}
+}
+
+func Test_diskEvict_evictMessage(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	pod := createMemoryEvictTestPod("test_be_pod", apiext.QoSBE, 100)
+	node := getNode("80", "120G")
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas([]*corev1.Pod{pod})).AnyTimes()
+	podMetrics := []*metriccache.PodResourceMetric{
+		{PodUID: string(pod.UID), DiskUsed: metriccache.DiskMetric{DiskUsed: resource.MustParse("20G")}},
+	}
+
+	fakeRecorder := record.NewFakeRecorder(10)
+	client := clientsetfake.NewSimpleClientset()
+	r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(),
+		eventRecorder: fakeRecorder, kubeClient: client, config: NewDefaultConfig()}
+	stop := make(chan struct{})
+	_ = r.podsEvicted.Run(stop)
+	defer func() { stop <- struct{}{} }()
+	_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	NewDiskEvictor(r).evictBEPods(node, podMetrics, 100*1000*1000*1000, 90*1000*1000*1000, 80)
+
+	// the measured usage and the threshold go to the eviction event and audit with the same message
+	select {
+	case event := <-fakeRecorder.Events:
+		assert.Contains(t, event,
+			"need to release disk: 10000000000, disk used 90000000000(90%) of capacity 100000000000, threshold 80%")
+	default:
+		t.Error("no eviction event is recorded")
+	}
+}
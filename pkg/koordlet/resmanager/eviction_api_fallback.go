@@ -0,0 +1,102 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// evictionV1ReprobeInterval is the interval to probe the policy/v1 Eviction API again after it is found unavailable,
+// since the apiserver can be upgraded to serve it.
+const evictionV1ReprobeInterval = 10 * time.Minute
+
+// evictPodByAPI evicts the pod by the policy/v1 Eviction API with the grace period, or nil to use the
+// terminationGracePeriodSeconds of the pod. If the API is unavailable on the apiserver, e.g. it is older than v1.22,
+// the pod is evicted in the way of EvictAPIUnavailableFallback instead, which is also taken by the later evictions
+// until the API is probed again after evictionV1ReprobeInterval. Each write to the apiserver is throttled.
+func (r *resmanager) evictPodByAPI(pod *corev1.Pod, gracePeriodSeconds *int64) error {
+	fallback := ""
+	if r.config != nil {
+		fallback = r.config.EvictAPIUnavailableFallback
+	}
+	if len(fallback) <= 0 || r.shouldProbeEvictionV1() {
+		podEvict := policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+		if gracePeriodSeconds != nil {
+			podEvict.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds}
+		}
+		r.throttleWrite()
+		err := r.kubeClient.CoreV1().Pods(pod.Namespace).EvictV1(context.TODO(), &podEvict)
+		if len(fallback) <= 0 || !isEvictionAPIUnavailable(pod, err) {
+			atomic.StoreInt64(&r.evictionV1UnavailableTime, 0)
+			return err
+		}
+		klog.Warningf("policy/v1 eviction api is unavailable, fall back to %s to evict pod %v/%v and the later ones, error: %v",
+			fallback, pod.Namespace, pod.Name, err)
+		atomic.StoreInt64(&r.evictionV1UnavailableTime, time.Now().UnixNano())
+	}
+
+	r.throttleWrite()
+	if fallback == EvictAPIFallbackV1beta1 {
+		podEvict := policyv1beta1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+		if gracePeriodSeconds != nil {
+			podEvict.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds}
+		}
+		return r.kubeClient.CoreV1().Pods(pod.Namespace).EvictV1beta1(context.TODO(), &podEvict)
+	}
+	// the deletion bypasses the PodDisruptionBudgets checked by the eviction api
+	return r.kubeClient.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{
+		GracePeriodSeconds: gracePeriodSeconds,
+		Preconditions:      metav1.NewUIDPreconditions(string(pod.UID)),
+	})
+}
+
+// shouldProbeEvictionV1 returns whether to evict by the policy/v1 Eviction API, which is not found unavailable or has
+// been unavailable for evictionV1ReprobeInterval.
+func (r *resmanager) shouldProbeEvictionV1() bool {
+	unavailableTime := atomic.LoadInt64(&r.evictionV1UnavailableTime)
+	return unavailableTime == 0 || time.Since(time.Unix(0, unavailableTime)) >= evictionV1ReprobeInterval
+}
+
+// isEvictionAPIUnavailable returns whether the eviction of the pod fails since the Eviction API is not served, rather
+// than the eviction is rejected. A missing pod is reported with its name, while a missing api resource is not.
+func isEvictionAPIUnavailable(pod *corev1.Pod, err error) bool {
+	if err == nil {
+		return false
+	}
+	if apiruntime.IsNotRegisteredError(err) || errors.IsGone(err) || errors.IsMethodNotSupported(err) {
+		return true
+	}
+	if !errors.IsNotFound(err) {
+		return false
+	}
+	status, ok := err.(errors.APIStatus)
+	if !ok {
+		return false
+	}
+	details := status.Status().Details
+	return details == nil || details.Name != pod.Name
+}
@@ -0,0 +1,231 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func Test_isEvictionAPIUnavailable(t *testing.T) {
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	evictionGVK := schema.GroupVersionKind{Group: "policy", Version: "v1", Kind: "Eviction"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "no error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "eviction kind not registered",
+			err:  apiruntime.NewNotRegisteredErrForKind("test", evictionGVK),
+			want: true,
+		},
+		{
+			name: "eviction api gone",
+			err:  errors.NewGone("the server does not serve policy/v1"),
+			want: true,
+		},
+		{
+			name: "eviction resource not found",
+			err:  errors.NewNotFound(schema.GroupResource{Group: "policy", Resource: "evictions"}, ""),
+			want: true,
+		},
+		{
+			name: "pod not found",
+			err:  errors.NewNotFound(schema.GroupResource{Resource: "pods"}, pod.Name),
+			want: false,
+		},
+		{
+			name: "eviction rejected by pdb",
+			err:  errors.NewTooManyRequests("evict forbidden by pdb", 10),
+			want: false,
+		},
+		{
+			name: "other error",
+			err:  fmt.Errorf("connection refused"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isEvictionAPIUnavailable(pod, tt.err))
+		})
+	}
+}
+
+func Test_evictPod_evictAPIUnavailableFallback(t *testing.T) {
+	node := getNode("80", "120G")
+	tests := []struct {
+		name          string
+		fallback      string
+		wantEvicted   bool
+		wantV1beta1   int
+		wantDeleted   int
+		wantV1Evicted int
+	}{
+		{
+			name:          "fail without the fallback",
+			fallback:      "",
+			wantEvicted:   false,
+			wantV1Evicted: 2,
+		},
+		{
+			name:          "fall back to the policy/v1beta1 eviction",
+			fallback:      EvictAPIFallbackV1beta1,
+			wantEvicted:   true,
+			wantV1beta1:   2,
+			wantV1Evicted: 1,
+		},
+		{
+			name:          "fall back to delete the pod",
+			fallback:      EvictAPIFallbackDelete,
+			wantEvicted:   true,
+			wantDeleted:   2,
+			wantV1Evicted: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := []*corev1.Pod{createTestPod(apiext.QoSBE, "test_be_pod_0"), createTestPod(apiext.QoSBE, "test_be_pod_1")}
+			client := clientsetfake.NewSimpleClientset()
+			// the apiserver does not serve the policy/v1 eviction
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+				createAction, ok := action.(k8stesting.CreateAction)
+				if !ok || action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				if _, ok = createAction.GetObject().(*policyv1.Eviction); ok {
+					return true, nil, apiruntime.NewNotRegisteredErrForKind("test",
+						schema.GroupVersionKind{Group: "policy", Version: "v1", Kind: "Eviction"})
+				}
+				return false, nil, nil
+			})
+			rateLimiter := newCountingRateLimiter()
+			r := &resmanager{
+				config:           &Config{EvictAPIUnavailableFallback: tt.fallback},
+				eventRecorder:    &FakeRecorder{},
+				kubeClient:       client,
+				writeRateLimiter: rateLimiter,
+			}
+			for _, pod := range pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+
+			for _, pod := range pods {
+				assert.Equal(t, tt.wantEvicted, r.evictPod(pod, node, "evict pod", ""))
+			}
+
+			gotV1Evicted, gotV1beta1, gotDeleted := 0, 0, 0
+			for _, action := range client.Actions() {
+				if createAction, ok := action.(k8stesting.CreateAction); ok && action.GetSubresource() == "eviction" {
+					switch createAction.GetObject().(type) {
+					case *policyv1.Eviction:
+						gotV1Evicted++
+					case *policyv1beta1.Eviction:
+						gotV1beta1++
+					}
+				}
+				if _, ok := action.(k8stesting.DeleteAction); ok {
+					gotDeleted++
+				}
+			}
+			// the policy/v1 eviction is not retried within the reprobe interval once it is found unavailable
+			assert.Equal(t, tt.wantV1Evicted, gotV1Evicted)
+			assert.Equal(t, tt.wantV1beta1, gotV1beta1)
+			assert.Equal(t, tt.wantDeleted, gotDeleted)
+			// each write is throttled, including the fallback one after the policy/v1 eviction fails
+			assert.Equal(t, gotV1Evicted+gotV1beta1+gotDeleted, rateLimiter.getAccepts())
+		})
+	}
+}
+
+func Test_evictPodByAPI_reprobeEvictionV1(t *testing.T) {
+	v1Available := false
+	client := clientsetfake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+		createAction, ok := action.(k8stesting.CreateAction)
+		if !ok || action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if _, ok = createAction.GetObject().(*policyv1.Eviction); ok && !v1Available {
+			return true, nil, apiruntime.NewNotRegisteredErrForKind("test",
+				schema.GroupVersionKind{Group: "policy", Version: "v1", Kind: "Eviction"})
+		}
+		return true, nil, nil
+	})
+	r := &resmanager{
+		config:     &Config{EvictAPIUnavailableFallback: EvictAPIFallbackV1beta1},
+		kubeClient: client,
+	}
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	countEvictions := func() (int, int) {
+		gotV1, gotV1beta1 := 0, 0
+		for _, action := range client.Actions() {
+			if createAction, ok := action.(k8stesting.CreateAction); ok && action.GetSubresource() == "eviction" {
+				switch createAction.GetObject().(type) {
+				case *policyv1.Eviction:
+					gotV1++
+				case *policyv1beta1.Eviction:
+					gotV1beta1++
+				}
+			}
+		}
+		client.ClearActions()
+		return gotV1, gotV1beta1
+	}
+
+	// fall back once the policy/v1 eviction is unavailable
+	assert.NoError(t, r.evictPodByAPI(pod, nil))
+	gotV1, gotV1beta1 := countEvictions()
+	assert.Equal(t, []int{1, 1}, []int{gotV1, gotV1beta1})
+	assert.NoError(t, r.evictPodByAPI(pod, nil))
+	gotV1, gotV1beta1 = countEvictions()
+	assert.Equal(t, []int{0, 1}, []int{gotV1, gotV1beta1})
+
+	// probe the policy/v1 eviction again after the interval, which is taken once it is available
+	v1Available = true
+	atomic.StoreInt64(&r.evictionV1UnavailableTime, time.Now().Add(-evictionV1ReprobeInterval).UnixNano())
+	assert.NoError(t, r.evictPodByAPI(pod, nil))
+	gotV1, gotV1beta1 = countEvictions()
+	assert.Equal(t, []int{1, 0}, []int{gotV1, gotV1beta1})
+	assert.Equal(t, int64(0), atomic.LoadInt64(&r.evictionV1UnavailableTime))
+	assert.NoError(t, r.evictPodByAPI(pod, nil))
+	gotV1, gotV1beta1 = countEvictions()
+	assert.Equal(t, []int{1, 0}, []int{gotV1, gotV1beta1})
+}
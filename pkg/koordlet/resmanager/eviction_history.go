@@ -0,0 +1,184 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	// evictionHistoryConfigMapPrefix is the name prefix of the ConfigMap summarizing the recent evictions of a node
+	evictionHistoryConfigMapPrefix = "koordlet-eviction-history-"
+	// evictionHistoryDataKey is the data key of the json eviction records in the ConfigMap
+	evictionHistoryDataKey = "evictions"
+)
+
+// EvictionRecord is an eviction of koordlet summarized in the eviction history of the node.
+type EvictionRecord struct {
+	Pod    string `json:"pod"`
+	Reason string `json:"reason"`
+	// Message describes the node pressure which the pod is evicted for
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+func getEvictionHistoryConfigMapName(nodeName string) string {
+	return evictionHistoryConfigMapPrefix + nodeName
+}
+
+// evictionHistoryRecorder buffers the evictions to append to the history ConfigMap, so the ConfigMap is written in
+// batches by the background worker instead of inline in the eviction path.
+type evictionHistoryRecorder struct {
+	lock sync.Mutex
+	// pending is the records not written yet, which keeps at most EvictionHistoryRetention records since the older
+	// ones would be trimmed anyway
+	pending   []EvictionRecord
+	retention int
+	// notify wakes up the worker to write the pending records, which has 1 buffer so the notifications are coalesced
+	notify chan struct{}
+}
+
+// newEvictionHistoryRecorder returns nil if the eviction history is disabled.
+func newEvictionHistoryRecorder(cfg *Config) *evictionHistoryRecorder {
+	if cfg == nil || cfg.EvictionHistoryNamespace == "" || cfg.EvictionHistoryRetention <= 0 {
+		return nil
+	}
+	return &evictionHistoryRecorder{
+		retention: cfg.EvictionHistoryRetention,
+		notify:    make(chan struct{}, 1),
+	}
+}
+
+func (h *evictionHistoryRecorder) add(record EvictionRecord) {
+	h.lock.Lock()
+	h.pending = append(h.pending, record)
+	if len(h.pending) > h.retention {
+		h.pending = h.pending[len(h.pending)-h.retention:]
+	}
+	h.lock.Unlock()
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (h *evictionHistoryRecorder) takePending() []EvictionRecord {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	records := h.pending
+	h.pending = nil
+	return records
+}
+
+// recordEvictionHistory queues the eviction to append to the history ConfigMap of the node, where the oldest records
+// beyond EvictionHistoryRetention are trimmed, so the recent evictions are queryable after the events expire.
+func (r *resmanager) recordEvictionHistory(pod *corev1.Pod, reason string, message string) {
+	if r.evictionHistory == nil {
+		return
+	}
+	r.evictionHistory.add(EvictionRecord{
+		Pod:     util.GetPodKey(pod),
+		Reason:  reason,
+		Message: message,
+		Time:    time.Now(),
+	})
+}
+
+// runEvictionHistoryWorker writes the queued evictions to the history ConfigMap until the stopCh is closed.
+func (r *resmanager) runEvictionHistoryWorker(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-r.evictionHistory.notify:
+			r.flushEvictionHistory()
+		}
+	}
+}
+
+// flushEvictionHistory appends the queued evictions to the history ConfigMap in one write. The records are read from
+// the ConfigMap on every write, so the history persists across the koordlet restarts.
+func (r *resmanager) flushEvictionHistory() {
+	if r.evictionHistory == nil {
+		return
+	}
+	records := r.evictionHistory.takePending()
+	if len(records) <= 0 {
+		return
+	}
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		return r.appendEvictionHistory(records)
+	})
+	if err != nil {
+		klog.Warningf("failed to record the eviction history of %v pods, error: %v", len(records), err)
+	}
+}
+
+func (r *resmanager) appendEvictionHistory(newRecords []EvictionRecord) error {
+	namespace, name := r.config.EvictionHistoryNamespace, getEvictionHistoryConfigMapName(r.nodeName)
+	configMaps := r.kubeClient.CoreV1().ConfigMaps(namespace)
+	configMap, err := configMaps.Get(context.TODO(), name, metav1.GetOptions{})
+	notFound := errors.IsNotFound(err)
+	if notFound {
+		configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	} else if err != nil {
+		return err
+	}
+
+	var records []EvictionRecord
+	if data := configMap.Data[evictionHistoryDataKey]; data != "" {
+		if err = json.Unmarshal([]byte(data), &records); err != nil {
+			klog.Warningf("failed to parse the eviction history %s/%s, overwrite it, error: %v", namespace, name, err)
+			records = nil
+		}
+	}
+	records = append(records, newRecords...)
+	if len(records) > r.config.EvictionHistoryRetention {
+		records = records[len(records)-r.config.EvictionHistoryRetention:]
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[evictionHistoryDataKey] = string(data)
+
+	r.throttleWrite()
+	if notFound {
+		_, err = configMaps.Create(context.TODO(), configMap, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			// retry by the conflict to append to the ConfigMap created meanwhile
+			return errors.NewConflict(corev1.Resource("configmaps"), name, err)
+		}
+		return err
+	}
+	_, err = configMaps.Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	return err
+}
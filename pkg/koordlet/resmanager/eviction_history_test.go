@@ -0,0 +1,257 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/flowcontrol"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+// countingRateLimiter counts the writes throttled by the write rate limiter
+type countingRateLimiter struct {
+	flowcontrol.RateLimiter
+	lock    sync.Mutex
+	accepts int
+}
+
+func newCountingRateLimiter() *countingRateLimiter {
+	return &countingRateLimiter{RateLimiter: flowcontrol.NewFakeAlwaysRateLimiter()}
+}
+
+func (l *countingRateLimiter) Accept() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.accepts++
+}
+
+func (l *countingRateLimiter) getAccepts() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.accepts
+}
+
+func countActions(client *clientsetfake.Clientset, verb, resource, subresource string) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == verb && action.GetResource().Resource == resource && action.GetSubresource() == subresource {
+			count++
+		}
+	}
+	return count
+}
+
+func getEvictionHistory(t *testing.T, r *resmanager) []EvictionRecord {
+	configMap, err := r.kubeClient.CoreV1().ConfigMaps(r.config.EvictionHistoryNamespace).Get(context.TODO(),
+		getEvictionHistoryConfigMapName(r.nodeName), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	assert.NoError(t, err)
+	var records []EvictionRecord
+	assert.NoError(t, json.Unmarshal([]byte(configMap.Data[evictionHistoryDataKey]), &records))
+	return records
+}
+
+func Test_recordEvictionHistory(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespace  string
+		retention  int
+		existing   *corev1.ConfigMap
+		evictions  int
+		wantRecord []string
+	}{
+		{
+			name:       "disabled without namespace",
+			namespace:  "",
+			retention:  3,
+			evictions:  2,
+			wantRecord: nil,
+		},
+		{
+			name:       "create the history",
+			namespace:  "koordinator-system",
+			retention:  3,
+			evictions:  2,
+			wantRecord: []string{"default/test-pod-0", "default/test-pod-1"},
+		},
+		{
+			name:       "trim the oldest records to the retention",
+			namespace:  "koordinator-system",
+			retention:  3,
+			evictions:  5,
+			wantRecord: []string{"default/test-pod-2", "default/test-pod-3", "default/test-pod-4"},
+		},
+		{
+			name:      "append to the existing history",
+			namespace: "koordinator-system",
+			retention: 3,
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "koordinator-system", Name: "koordlet-eviction-history-test-node"},
+				Data:       map[string]string{evictionHistoryDataKey: `[{"pod":"default/test-pod-old","reason":"evictPodByNodeMemoryUsage"}]`},
+			},
+			evictions:  1,
+			wantRecord: []string{"default/test-pod-old", "default/test-pod-0"},
+		},
+		{
+			name:      "overwrite the invalid history",
+			namespace: "koordinator-system",
+			retention: 3,
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "koordinator-system", Name: "koordlet-eviction-history-test-node"},
+				Data:       map[string]string{evictionHistoryDataKey: "invalid"},
+			},
+			evictions:  1,
+			wantRecord: []string{"default/test-pod-0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := clientsetfake.NewSimpleClientset()
+			if tt.existing != nil {
+				_, err := client.CoreV1().ConfigMaps(tt.existing.Namespace).Create(context.TODO(), tt.existing, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			cfg := NewDefaultConfig()
+			cfg.EvictionHistoryNamespace = tt.namespace
+			cfg.EvictionHistoryRetention = tt.retention
+			r := &resmanager{config: cfg, nodeName: "test-node", kubeClient: client,
+				evictionHistory: newEvictionHistoryRecorder(cfg)}
+
+			for i := 0; i < tt.evictions; i++ {
+				pod := createTestPod(apiext.QoSBE, fmt.Sprintf("test-pod-%d", i))
+				pod.Namespace = "default"
+				r.recordEvictionHistory(pod, evictPodByNodeMemoryUsage, "node memory usage exceeds the threshold")
+			}
+			r.flushEvictionHistory()
+			var gotRecords []string
+			for _, record := range getEvictionHistory(t, r) {
+				gotRecords = append(gotRecords, record.Pod)
+			}
+			assert.Equal(t, tt.wantRecord, gotRecords)
+		})
+	}
+}
+
+func Test_evictPod_evictionHistory(t *testing.T) {
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	pod.Namespace = "default"
+	client := clientsetfake.NewSimpleClientset()
+	cfg := NewDefaultConfig()
+	cfg.EvictionHistoryNamespace = "koordinator-system"
+	r := &resmanager{config: cfg, nodeName: "test-node", eventRecorder: &FakeRecorder{}, kubeClient: client,
+		evictionHistory: newEvictionHistoryRecorder(cfg)}
+	_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	assert.True(t, r.evictPod(pod, getNode("80", "120G"), evictPodByNodeMemoryUsage, "need to release memory"))
+	// the history is not written in the eviction path
+	assert.Nil(t, getEvictionHistory(t, r))
+	r.flushEvictionHistory()
+	records := getEvictionHistory(t, r)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "default/test_be_pod", records[0].Pod)
+	assert.Equal(t, evictPodByNodeMemoryUsage, records[0].Reason)
+	assert.Equal(t, "need to release memory", records[0].Message)
+	assert.False(t, records[0].Time.IsZero())
+}
+
+func Test_flushEvictionHistory_throttleWrites(t *testing.T) {
+	client := clientsetfake.NewSimpleClientset()
+	cfg := NewDefaultConfig()
+	cfg.EvictionHistoryNamespace = "koordinator-system"
+	rateLimiter := newCountingRateLimiter()
+	r := &resmanager{config: cfg, nodeName: "test-node", kubeClient: client, writeRateLimiter: rateLimiter,
+		evictionHistory: newEvictionHistoryRecorder(cfg)}
+	recordEvictions := func(names ...string) {
+		for _, name := range names {
+			pod := createTestPod(apiext.QoSBE, name)
+			pod.Namespace = "default"
+			r.recordEvictionHistory(pod, evictPodByNodeMemoryUsage, "node memory usage exceeds the threshold")
+		}
+	}
+
+	// the queued evictions are written in one batch
+	recordEvictions("test-pod-0", "test-pod-1", "test-pod-2")
+	r.flushEvictionHistory()
+	assert.Len(t, getEvictionHistory(t, r), 3)
+	assert.Equal(t, 1, countActions(client, "create", "configmaps", ""))
+	assert.Equal(t, 1, rateLimiter.getAccepts())
+
+	// each write retried on the conflict is throttled
+	conflicts := 1
+	client.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts <= 0 {
+			return false, nil, nil
+		}
+		conflicts--
+		return true, nil, errors.NewConflict(corev1.Resource("configmaps"), "test", fmt.Errorf("conflict"))
+	})
+	recordEvictions("test-pod-3")
+	r.flushEvictionHistory()
+	assert.Len(t, getEvictionHistory(t, r), 4)
+	assert.Equal(t, 2, countActions(client, "update", "configmaps", ""))
+	assert.Equal(t, 3, rateLimiter.getAccepts())
+
+	// nothing to write
+	r.flushEvictionHistory()
+	assert.Equal(t, 3, rateLimiter.getAccepts())
+}
+
+func Test_runEvictionHistoryWorker(t *testing.T) {
+	client := clientsetfake.NewSimpleClientset()
+	cfg := NewDefaultConfig()
+	cfg.EvictionHistoryNamespace = "koordinator-system"
+	r := &resmanager{config: cfg, nodeName: "test-node", kubeClient: client,
+		evictionHistory: newEvictionHistoryRecorder(cfg)}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go r.runEvictionHistoryWorker(stopCh)
+
+	pod := createTestPod(apiext.QoSBE, "test-pod")
+	pod.Namespace = "default"
+	r.recordEvictionHistory(pod, evictPodByNodeMemoryUsage, "node memory usage exceeds the threshold")
+	assert.Eventually(t, func() bool {
+		records := getEvictionHistory(t, r)
+		return len(records) == 1 && records[0].Pod == "default/test-pod"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_newEvictionHistoryRecorder(t *testing.T) {
+	cfg := NewDefaultConfig()
+	assert.Nil(t, newEvictionHistoryRecorder(cfg))
+	cfg.EvictionHistoryNamespace = "koordinator-system"
+	cfg.EvictionHistoryRetention = 0
+	assert.Nil(t, newEvictionHistoryRecorder(cfg))
+	cfg.EvictionHistoryRetention = 3
+	assert.NotNil(t, newEvictionHistoryRecorder(cfg))
+}
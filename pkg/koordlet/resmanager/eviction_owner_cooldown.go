@@ -0,0 +1,58 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	evictPodSkippedByOwnerCooldown = "evictPodSkippedByOwnerCooldown"
+)
+
+// checkEvictionByOwnerCooldown returns false with the message if another pod of the same controller owner has been
+// evicted within EvictOwnerCooldownSeconds, so that the replicas of a workload are not evicted in rapid succession.
+// The pods without a controller owner are always allowed.
+func (r *resmanager) checkEvictionByOwnerCooldown(pod *corev1.Pod) (bool, string) {
+	if r.ownersEvicted == nil {
+		return true, ""
+	}
+	ownerRef := metav1.GetControllerOf(pod)
+	if ownerRef == nil {
+		return true, ""
+	}
+	lastEvicted, ok := r.ownersEvicted.Get(string(ownerRef.UID))
+	if !ok || lastEvicted.(types.UID) == pod.UID {
+		return true, ""
+	}
+	return false, fmt.Sprintf("another pod of %s %s was evicted within the cooldown of %d seconds",
+		ownerRef.Kind, ownerRef.Name, r.config.EvictOwnerCooldownSeconds)
+}
+
+// recordOwnerEviction starts the eviction cooldown of the controller owner of the evicted pod.
+func (r *resmanager) recordOwnerEviction(pod *corev1.Pod) {
+	if r.ownersEvicted == nil {
+		return
+	}
+	if ownerRef := metav1.GetControllerOf(pod); ownerRef != nil {
+		_ = r.ownersEvicted.SetDefault(string(ownerRef.UID), pod.UID)
+	}
+}
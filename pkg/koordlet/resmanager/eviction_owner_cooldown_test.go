@@ -0,0 +1,160 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	expireCache "github.com/koordinator-sh/koordinator/pkg/tools/cache"
+)
+
+func Test_evictPod_ownerCooldown(t *testing.T) {
+	node := getNode("80", "120G")
+	newPod := func(name string, ownerName string) *corev1.Pod {
+		pod := createTestPod(apiext.QoSBE, name)
+		if ownerName != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       ownerName,
+					UID:        types.UID(ownerName),
+					Controller: pointer.BoolPtr(true),
+				},
+			}
+		}
+		return pod
+	}
+	pods := []*corev1.Pod{
+		newPod("test_rs_a_pod_1", "rs_a"),
+		newPod("test_rs_a_pod_2", "rs_a"),
+		newPod("test_rs_a_pod_3", "rs_a"),
+		newPod("test_rs_b_pod_1", "rs_b"),
+		newPod("test_standalone_pod", ""),
+	}
+
+	client := clientsetfake.NewSimpleClientset()
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	r := &resmanager{
+		config:          &Config{EvictOwnerCooldownSeconds: 30},
+		eventRecorder:   &FakeRecorder{},
+		kubeClient:      client,
+		podsEvicted:     expireCache.NewCacheWithClock(time.Hour, time.Minute, fakeClock),
+		evictFailEvents: expireCache.NewCacheWithClock(time.Hour, time.Minute, fakeClock),
+		ownersEvicted:   expireCache.NewCacheWithClock(30*time.Second, time.Minute, fakeClock),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, r.podsEvicted.Run(stop))
+	assert.NoError(t, r.evictFailEvents.Run(stop))
+	assert.NoError(t, r.ownersEvicted.Run(stop))
+
+	cycles := []struct {
+		elapsed     time.Duration
+		wantEvicted []string
+	}{
+		{
+			elapsed:     0,
+			wantEvicted: []string{"test_rs_a_pod_1", "test_rs_b_pod_1", "test_standalone_pod"},
+		},
+		{
+			elapsed:     10 * time.Second,
+			wantEvicted: []string{},
+		},
+		{
+			elapsed:     21 * time.Second,
+			wantEvicted: []string{"test_rs_a_pod_2"},
+		},
+		{
+			elapsed:     10 * time.Second,
+			wantEvicted: []string{},
+		},
+		{
+			elapsed:     21 * time.Second,
+			wantEvicted: []string{"test_rs_a_pod_3"},
+		},
+	}
+	for i, cycle := range cycles {
+		fakeClock.Step(cycle.elapsed)
+		client.ClearActions()
+		r.evictPodsIfNotEvicted(pods, node, "evict pod", "")
+
+		gotEvicted := []string{}
+		for _, action := range client.Actions() {
+			if createAction, ok := action.(k8stesting.CreateAction); ok && action.GetSubresource() == "eviction" {
+				gotEvicted = append(gotEvicted, createAction.GetObject().(metav1.Object).GetName())
+			}
+		}
+		assert.Equal(t, cycle.wantEvicted, gotEvicted, "cycle %d", i)
+	}
+}
+
+func Test_checkEvictionByOwnerCooldown(t *testing.T) {
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	pod.OwnerReferences = []metav1.OwnerReference{
+		{Kind: "ReplicaSet", Name: "rs", UID: "rs", Controller: pointer.BoolPtr(true)},
+	}
+	otherPod := pod.DeepCopy()
+	otherPod.Name = "test_other_be_pod"
+	otherPod.UID = "test_other_be_pod"
+
+	t.Run("allow if disabled", func(t *testing.T) {
+		r := &resmanager{}
+		r.recordOwnerEviction(pod)
+		allowed, _ := r.checkEvictionByOwnerCooldown(otherPod)
+		assert.True(t, allowed)
+	})
+
+	t.Run("disallow the other pods of the owner during the cooldown", func(t *testing.T) {
+		r := &resmanager{
+			config:        &Config{EvictOwnerCooldownSeconds: 30},
+			ownersEvicted: expireCache.NewCache(30*time.Second, time.Minute),
+		}
+		stop := make(chan struct{})
+		defer close(stop)
+		assert.NoError(t, r.ownersEvicted.Run(stop))
+		allowed, _ := r.checkEvictionByOwnerCooldown(otherPod)
+		assert.True(t, allowed)
+
+		r.recordOwnerEviction(pod)
+		allowed, _ = r.checkEvictionByOwnerCooldown(pod)
+		assert.True(t, allowed, "the evicted pod itself is allowed to be evicted again")
+		allowed, message := r.checkEvictionByOwnerCooldown(otherPod)
+		assert.False(t, allowed)
+		assert.Contains(t, message, "ReplicaSet rs")
+
+		standalonePod := createTestPod(apiext.QoSBE, "test_standalone_pod")
+		allowed, _ = r.checkEvictionByOwnerCooldown(standalonePod)
+		assert.True(t, allowed)
+	})
+}
@@ -0,0 +1,67 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	policyinformerv1 "k8s.io/client-go/informers/policy/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	evictPodSkippedByPDB = "evictPodSkippedByPDB"
+)
+
+func newPDBInformer(client clientset.Interface) cache.SharedIndexInformer {
+	return policyinformerv1.NewPodDisruptionBudgetInformer(client, metav1.NamespaceAll, time.Hour*12, cache.Indexers{})
+}
+
+// checkEvictionByPDB is a best-effort preflight of the eviction. It returns false with the message if the eviction
+// would be rejected by a PodDisruptionBudget covering the pod, so that the api round-trip can be skipped. The
+// eviction is always allowed when the PDB informer is disabled or not synced yet.
+func (r *resmanager) checkEvictionByPDB(pod *corev1.Pod) (bool, string) {
+	if r.pdbLister == nil || r.pdbInformer == nil || !r.pdbInformer.HasSynced() {
+		return true, ""
+	}
+	// the apiserver always allows evicting the terminated pods
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true, ""
+	}
+
+	pdbs, err := r.pdbLister.PodDisruptionBudgets(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.V(4).Infof("failed to list PDBs for pod %s/%s, skip the preflight, error: %v", pod.Namespace, pod.Name, err)
+		return true, ""
+	}
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed <= 0 {
+			return false, fmt.Sprintf("PodDisruptionBudget %s/%s allows no disruption", pdb.Namespace, pdb.Name)
+		}
+	}
+	return true, ""
+}
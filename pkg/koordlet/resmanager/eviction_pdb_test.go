@@ -0,0 +1,150 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	policylisterv1 "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/tools/cache"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func newTestPDB(name string, matchLabels map[string]string, disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: matchLabels},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+	}
+}
+
+func Test_checkEvictionByPDB(t *testing.T) {
+	newPod := func(phase corev1.PodPhase) *corev1.Pod {
+		pod := createTestPod(apiext.QoSBE, "test_be_pod")
+		pod.Namespace = "default"
+		pod.Labels["app"] = "test"
+		pod.Status.Phase = phase
+		return pod
+	}
+	tests := []struct {
+		name        string
+		disablePDB  bool
+		pdbs        []*policyv1.PodDisruptionBudget
+		pod         *corev1.Pod
+		wantAllowed bool
+	}{
+		{
+			name:        "allow if the preflight is disabled",
+			disablePDB:  true,
+			pod:         newPod(corev1.PodRunning),
+			wantAllowed: true,
+		},
+		{
+			name:        "allow if no pdb",
+			pod:         newPod(corev1.PodRunning),
+			wantAllowed: true,
+		},
+		{
+			name:        "allow if pdb allows disruption",
+			pdbs:        []*policyv1.PodDisruptionBudget{newTestPDB("test-pdb", map[string]string{"app": "test"}, 1)},
+			pod:         newPod(corev1.PodRunning),
+			wantAllowed: true,
+		},
+		{
+			name:        "disallow if pdb allows no disruption",
+			pdbs:        []*policyv1.PodDisruptionBudget{newTestPDB("test-pdb", map[string]string{"app": "test"}, 0)},
+			pod:         newPod(corev1.PodRunning),
+			wantAllowed: false,
+		},
+		{
+			name: "disallow if any pdb allows no disruption",
+			pdbs: []*policyv1.PodDisruptionBudget{
+				newTestPDB("test-pdb-0", map[string]string{"app": "test"}, 1),
+				newTestPDB("test-pdb-1", map[string]string{apiext.LabelPodQoS: string(apiext.QoSBE)}, 0),
+			},
+			pod:         newPod(corev1.PodRunning),
+			wantAllowed: false,
+		},
+		{
+			name:        "allow if pdb does not cover the pod",
+			pdbs:        []*policyv1.PodDisruptionBudget{newTestPDB("test-pdb", map[string]string{"app": "other"}, 0)},
+			pod:         newPod(corev1.PodRunning),
+			wantAllowed: true,
+		},
+		{
+			name:        "allow the terminated pod",
+			pdbs:        []*policyv1.PodDisruptionBudget{newTestPDB("test-pdb", map[string]string{"app": "test"}, 0)},
+			pod:         newPod(corev1.PodSucceeded),
+			wantAllowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := clientsetfake.NewSimpleClientset()
+			for _, pdb := range tt.pdbs {
+				_, err := client.PolicyV1().PodDisruptionBudgets(pdb.Namespace).Create(context.TODO(), pdb, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			r := &resmanager{}
+			if !tt.disablePDB {
+				r.pdbInformer = newPDBInformer(client)
+				r.pdbLister = policylisterv1.NewPodDisruptionBudgetLister(r.pdbInformer.GetIndexer())
+				stopCh := make(chan struct{})
+				defer close(stopCh)
+				go r.pdbInformer.Run(stopCh)
+				assert.True(t, cache.WaitForCacheSync(stopCh, r.pdbInformer.HasSynced))
+			}
+
+			gotAllowed, gotMessage := r.checkEvictionByPDB(tt.pod)
+			assert.Equal(t, tt.wantAllowed, gotAllowed)
+			assert.Equal(t, tt.wantAllowed, gotMessage == "")
+		})
+	}
+}
+
+func Test_evictPod_skippedByPDB(t *testing.T) {
+	node := getNode("80", "120G")
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	pod.Namespace = "default"
+	client := clientsetfake.NewSimpleClientset(pod)
+	pdb := newTestPDB("test-pdb", map[string]string{apiext.LabelPodQoS: string(apiext.QoSBE)}, 0)
+	_, err := client.PolicyV1().PodDisruptionBudgets(pdb.Namespace).Create(context.TODO(), pdb, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	fakeRecorder := &FakeRecorder{}
+	r := &resmanager{eventRecorder: fakeRecorder, kubeClient: client, pdbInformer: newPDBInformer(client)}
+	r.pdbLister = policylisterv1.NewPodDisruptionBudgetLister(r.pdbInformer.GetIndexer())
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go r.pdbInformer.Run(stopCh)
+	assert.True(t, cache.WaitForCacheSync(stopCh, r.pdbInformer.HasSynced))
+
+	assert.False(t, r.evictPod(pod, node, "evict pod first", ""))
+	assert.Equal(t, evictPodSkippedByPDB, fakeRecorder.eventReason)
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "eviction", action.GetSubresource(), "expect no eviction request")
+	}
+}
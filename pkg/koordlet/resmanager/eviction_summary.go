@@ -0,0 +1,77 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+)
+
+const (
+	evictionSkipLastReadyReplica = "LastReadyReplica"
+	evictionSkipNotKilled        = "NotKilled"
+	evictionSkipKillFailed       = "KillFailed"
+)
+
+// evictionSummary counts the candidates of an eviction cycle, which is logged in one line at the end of the cycle.
+type evictionSummary struct {
+	feature    featuregate.Feature
+	reason     string
+	pressure   string
+	candidates int
+	evicted    int
+	// skipped is the number of the skipped candidates keyed by the skip reason
+	skipped map[string]int
+}
+
+func newEvictionSummary(feature featuregate.Feature, reason, pressure string) *evictionSummary {
+	return &evictionSummary{feature: feature, reason: reason, pressure: pressure, skipped: map[string]int{}}
+}
+
+func (s *evictionSummary) consider() {
+	s.candidates++
+}
+
+func (s *evictionSummary) evict() {
+	s.evicted++
+}
+
+func (s *evictionSummary) skip(reason string) {
+	s.skipped[reason]++
+}
+
+func (s *evictionSummary) String() string {
+	reasons := make([]string, 0, len(s.skipped))
+	for reason, count := range s.skipped {
+		reasons = append(reasons, fmt.Sprintf("%s=%d", reason, count))
+	}
+	sort.Strings(reasons)
+	return fmt.Sprintf("eviction summary: feature=%s reason=%s pressure={%s} candidates=%d evicted=%d skipped={%s}",
+		s.feature, s.reason, s.pressure, s.candidates, s.evicted, strings.Join(reasons, " "))
+}
+
+// logEvictionSummary logs the summary of the eviction cycle at info level if enabled.
+func (r *resmanager) logEvictionSummary(s *evictionSummary) {
+	if r.config == nil || !r.config.EvictionSummaryLog {
+		return
+	}
+	klog.Info(s.String())
+}
@@ -0,0 +1,156 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/runtime/handler"
+	"github.com/koordinator-sh/koordinator/pkg/tools/cache"
+)
+
+func Test_evictionSummary_String(t *testing.T) {
+	s := newEvictionSummary(features.BEMemoryEvict, evictPodByNodeMemoryUsage, "memoryCapacity=100 memoryUsed=90 lowerPercent=30")
+	assert.Equal(t, "eviction summary: feature=BEMemoryEvict reason=EvictPodByNodeMemoryUsage pressure={memoryCapacity=100 memoryUsed=90 lowerPercent=30} candidates=0 evicted=0 skipped={}",
+		s.String())
+
+	for i := 0; i < 4; i++ {
+		s.consider()
+	}
+	s.evict()
+	s.skip(evictionSkipNotKilled)
+	s.skip(evictionSkipLastReadyReplica)
+	s.skip(evictionSkipLastReadyReplica)
+	assert.Equal(t, "eviction summary: feature=BEMemoryEvict reason=EvictPodByNodeMemoryUsage pressure={memoryCapacity=100 memoryUsed=90 lowerPercent=30} candidates=4 evicted=1 skipped={LastReadyReplica=2 NotKilled=1}",
+		s.String())
+}
+
+func Test_memoryEvict_evictionSummaryLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		summaryLog  bool
+		wantSummary string
+	}{
+		{
+			name:        "log the summary of the eviction cycle",
+			summaryLog:  true,
+			wantSummary: "eviction summary: feature=BEMemoryEvict reason=EvictPodByNodeMemoryUsage pressure={memoryCapacity=100000000000 memoryUsed=85000000000 lowerPercent=30} candidates=4 evicted=2 skipped={LastReadyReplica=2}",
+		},
+		{
+			name:       "not log the summary if disabled",
+			summaryLog: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			newPod := func(name string, priority int32, ownerKind string) *corev1.Pod {
+				pod := createMemoryEvictTestPod(name, apiext.QoSBE, priority)
+				pod.Status.Phase = corev1.PodRunning
+				pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+				if ownerKind != "" {
+					pod.OwnerReferences = []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: ownerKind, Name: ownerKind, UID: types.UID(ownerKind), Controller: pointer.BoolPtr(true)},
+					}
+				}
+				return pod
+			}
+			pods := []*corev1.Pod{
+				newPod("test_be_rs_pod_0", 100, "ReplicaSet"),
+				newPod("test_be_rs_pod_1", 101, "ReplicaSet"),
+				newPod("test_be_sts_pod_0", 102, "StatefulSet"),
+				newPod("test_be_bare_pod_0", 103, ""),
+			}
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+			cfg := NewDefaultConfig()
+			cfg.MemoryEvictSkipLastReplica = true
+			cfg.EvictionSummaryLog = tt.summaryLog
+			thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(30),
+			}
+			r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(), eventRecorder: &FakeRecorder{},
+				kubeClient: clientsetfake.NewSimpleClientset(), nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+				Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("85G")},
+				},
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+			r.metricCache = mockMetricCache
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+
+			var buf bytes.Buffer
+			klog.LogToStderr(false)
+			klog.SetOutput(&buf)
+			defer klog.LogToStderr(true)
+
+			memoryEvictor := NewMemoryEvictor(r)
+			memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+			memoryEvictor.memoryEvict()
+			klog.Flush()
+
+			var gotSummaries []string
+			for _, line := range strings.Split(buf.String(), "\n") {
+				if idx := strings.Index(line, "eviction summary:"); idx >= 0 {
+					gotSummaries = append(gotSummaries, line[idx:])
+				}
+			}
+			if tt.wantSummary == "" {
+				assert.Empty(t, gotSummaries)
+			} else {
+				assert.Equal(t, []string{tt.wantSummary}, gotSummaries)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	// featureStalledIntervalMultiple is the number of the intervals a running feature can go without completing a
+	// cycle before it is reported unhealthy, which tolerates the start delay and a slow cycle.
+	featureStalledIntervalMultiple = 3
+)
+
+var (
+	// defaultFeatureHealth serves the feature health of the running resmanager for the debug http handler.
+	defaultFeatureHealth = &featureHealthRef{}
+)
+
+// FeatureHealthStatus is the liveness of a feature loop of the resmanager.
+type FeatureHealthStatus struct {
+	Feature             string    `json:"feature"`
+	IntervalSeconds     int       `json:"intervalSeconds"`
+	LastSuccessfulCycle time.Time `json:"lastSuccessfulCycle,omitempty"`
+	Healthy             bool      `json:"healthy"`
+}
+
+type featureHealthEntry struct {
+	interval time.Duration
+	// lastAlive is the last time the feature completed a cycle, or was started or disabled by the dynamic gate
+	lastAlive time.Time
+	// lastSucceed is the last time the feature completed a cycle
+	lastSucceed time.Time
+}
+
+// featureHealth tracks the liveness of the feature loops, so a loop which panics or gets stuck is reported unhealthy.
+type featureHealth struct {
+	lock    sync.RWMutex
+	clock   clock.Clock
+	entries map[featuregate.Feature]*featureHealthEntry
+}
+
+func newFeatureHealth() *featureHealth {
+	return &featureHealth{
+		clock:   clock.RealClock{},
+		entries: map[featuregate.Feature]*featureHealthEntry{},
+	}
+}
+
+// runFeature runs the feature loop with util.RunFeatureWithDynamicGate and tracks its liveness if the loop is started.
+func (h *featureHealth) runFeature(moduleInit func() error, moduleFunc func(), feature featuregate.Feature,
+	dynamicGate util.DynamicFeatureGate, interval int, stopCh <-chan struct{}) (bool, error) {
+	trackedFunc := func() {
+		moduleFunc()
+		h.alive(feature, true)
+	}
+	trackedGate := func(f featuregate.Feature) bool {
+		enabled := dynamicGate(f)
+		if !enabled {
+			// a disabled feature runs no cycle, which should not make it unhealthy once enabled
+			h.alive(feature, false)
+		}
+		return enabled
+	}
+	if dynamicGate == nil {
+		trackedGate = nil
+	}
+
+	h.register(feature, time.Duration(interval)*time.Second)
+	// the tracked closures are anonymous, so the loop is logged by the feature name
+	started, err := util.RunFeatureWithDynamicGate(string(feature), moduleInit, trackedFunc, []featuregate.Feature{feature},
+		trackedGate, interval, stopCh)
+	if !started {
+		h.unregister(feature)
+	}
+	return started, err
+}
+
+func (h *featureHealth) register(feature featuregate.Feature, interval time.Duration) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.entries[feature] = &featureHealthEntry{interval: interval, lastAlive: h.clock.Now()}
+}
+
+func (h *featureHealth) unregister(feature featuregate.Feature) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.entries, feature)
+}
+
+func (h *featureHealth) alive(feature featuregate.Feature, succeed bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	entry, ok := h.entries[feature]
+	if !ok {
+		return
+	}
+	entry.lastAlive = h.clock.Now()
+	if succeed {
+		entry.lastSucceed = entry.lastAlive
+	}
+}
+
+// isRunning returns whether the loop of the feature is started.
+func (h *featureHealth) isRunning(feature featuregate.Feature) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	_, ok := h.entries[feature]
+	return ok
+}
+
+// list returns the health status of the running features ordered by the feature name.
+func (h *featureHealth) list() []FeatureHealthStatus {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	now := h.clock.Now()
+	statuses := make([]FeatureHealthStatus, 0, len(h.entries))
+	for feature, entry := range h.entries {
+		statuses = append(statuses, FeatureHealthStatus{
+			Feature:             string(feature),
+			IntervalSeconds:     int(entry.interval / time.Second),
+			LastSuccessfulCycle: entry.lastSucceed,
+			Healthy:             now.Sub(entry.lastAlive) <= featureStalledIntervalMultiple*entry.interval,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Feature < statuses[j].Feature
+	})
+	return statuses
+}
+
+// healthz returns an error listing the features which have not completed a cycle in time.
+func (h *featureHealth) healthz() error {
+	var stalled []string
+	for _, status := range h.list() {
+		if !status.Healthy {
+			stalled = append(stalled, status.Feature)
+		}
+	}
+	if len(stalled) > 0 {
+		return fmt.Errorf("features stalled: %s", strings.Join(stalled, ","))
+	}
+	return nil
+}
+
+type featureHealthRef struct {
+	lock   sync.RWMutex
+	health *featureHealth
+}
+
+func (r *featureHealthRef) set(health *featureHealth) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.health = health
+}
+
+func (r *featureHealthRef) get() *featureHealth {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.health
+}
+
+// FeatureHealthHttpHandler returns the http handler to dump the liveness of the feature loops of the resmanager,
+// which responds 503 if any feature is stalled.
+func FeatureHealthHttpHandler() func(http.ResponseWriter, *http.Request) {
+	return defaultFeatureHealth.httpHandler()
+}
+
+func (r *featureHealthRef) httpHandler() func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		health := r.get()
+		if health == nil {
+			http.Error(rw, "resmanager is not running", http.StatusServiceUnavailable)
+			return
+		}
+		statuses := health.list()
+		data, err := json.Marshal(statuses)
+		if err != nil {
+			http.Error(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		for _, status := range statuses {
+			if !status.Healthy {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				break
+			}
+		}
+		if _, err = rw.Write(data); err != nil {
+			klog.Warningf("failed to write feature health to client %v, error %v", req.RemoteAddr, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/component-base/featuregate"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+func Test_featureHealth(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	h := newFeatureHealth()
+	h.clock = fakeClock
+	h.register(features.BECPUSuppress, 10*time.Second)
+	h.register(features.BEMemoryEvict, 10*time.Second)
+	assert.NoError(t, h.healthz())
+
+	fakeClock.Step(20 * time.Second)
+	h.alive(features.BECPUSuppress, true)
+	fakeClock.Step(15 * time.Second)
+	err := h.healthz()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), string(features.BEMemoryEvict))
+	assert.NotContains(t, err.Error(), string(features.BECPUSuppress))
+	statuses := h.list()
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, string(features.BECPUSuppress), statuses[0].Feature)
+	assert.True(t, statuses[0].Healthy)
+	assert.Equal(t, fakeClock.Now().Add(-15*time.Second), statuses[0].LastSuccessfulCycle)
+	assert.False(t, statuses[1].Healthy)
+	assert.True(t, statuses[1].LastSuccessfulCycle.IsZero())
+
+	// the feature disabled by the dynamic gate is healthy
+	h.alive(features.BEMemoryEvict, false)
+	assert.NoError(t, h.healthz())
+
+	h.unregister(features.BEMemoryEvict)
+	h.alive(features.BEMemoryEvict, true)
+	assert.Len(t, h.list(), 1)
+}
+
+func Test_featureHealth_runFeature(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	h := newFeatureHealth()
+	h.clock = fakeClock
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	enabledGate := func(feature featuregate.Feature) bool { return true }
+
+	// not started if the interval is disabled
+	started, err := h.runFeature(func() error { return nil }, func() {}, features.BEDiskEvict, enabledGate, 0, stopCh)
+	assert.False(t, started)
+	assert.NoError(t, err)
+	assert.Len(t, h.list(), 0)
+
+	// the feature completes the first cycle and gets stuck in the second one
+	cycles := 0
+	started, err = h.runFeature(func() error { return nil }, func() {
+		cycles++
+		if cycles > 1 {
+			<-stopCh
+		}
+	}, features.BECPUSuppress, enabledGate, 1, stopCh)
+	assert.True(t, started)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		statuses := h.list()
+		return len(statuses) == 1 && !statuses[0].LastSuccessfulCycle.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, h.healthz())
+
+	fakeClock.Step(10 * time.Second)
+	err = h.healthz()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), string(features.BECPUSuppress))
+}
+
+func Test_featureHealthRef_httpHandler(t *testing.T) {
+	ref := &featureHealthRef{}
+	handler := ref.httpHandler()
+
+	rw := httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodGet, "/debug/featurehealth", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	h := newFeatureHealth()
+	h.clock = fakeClock
+	h.register(features.BECPUSuppress, time.Second)
+	ref.set(h)
+	rw = httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodGet, "/debug/featurehealth", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	var got []FeatureHealthStatus
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &got))
+	assert.Len(t, got, 1)
+	assert.True(t, got[0].Healthy)
+
+	fakeClock.Step(10 * time.Second)
+	rw = httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodGet, "/debug/featurehealth", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &got))
+	assert.False(t, got[0].Healthy)
+}
@@ -0,0 +1,188 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+const (
+	defaultFeaturePauseTTL = 10 * time.Minute
+	// maxFeaturePauseTTL bounds the pause, so a feature paused during the incident response is never left off forever
+	maxFeaturePauseTTL = time.Hour
+)
+
+var (
+	// defaultFeaturePause serves the feature pauses of the running resmanager for the debug http handler.
+	defaultFeaturePause = &featurePauseRef{}
+)
+
+// FeaturePauseStatus is a feature loop of the resmanager paused by the debug http handler.
+type FeaturePauseStatus struct {
+	Feature     string    `json:"feature"`
+	PausedUntil time.Time `json:"pausedUntil"`
+}
+
+// featurePause holds the in-memory pauses of the feature loops, each of which expires after its ttl. A nil
+// featurePause is valid and pauses nothing.
+type featurePause struct {
+	lock  sync.RWMutex
+	clock clock.Clock
+	until map[featuregate.Feature]time.Time
+	// isRunning returns whether the feature loop is running, since only the running features can be paused
+	isRunning func(feature featuregate.Feature) bool
+}
+
+func newFeaturePause(isRunning func(feature featuregate.Feature) bool) *featurePause {
+	return &featurePause{
+		clock:     clock.RealClock{},
+		until:     map[featuregate.Feature]time.Time{},
+		isRunning: isRunning,
+	}
+}
+
+// pause pauses the feature for the ttl, or resumes it if the ttl is not positive.
+func (p *featurePause) pause(feature featuregate.Feature, ttl time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if ttl <= 0 {
+		delete(p.until, feature)
+		return
+	}
+	p.until[feature] = p.clock.Now().Add(ttl)
+}
+
+func (p *featurePause) isPaused(feature featuregate.Feature) bool {
+	if p == nil {
+		return false
+	}
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	until, ok := p.until[feature]
+	return ok && p.clock.Now().Before(until)
+}
+
+// list returns the unexpired pauses ordered by the feature name.
+func (p *featurePause) list() []FeaturePauseStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.clock.Now()
+	statuses := make([]FeaturePauseStatus, 0, len(p.until))
+	for feature, until := range p.until {
+		if !now.Before(until) {
+			delete(p.until, feature)
+			continue
+		}
+		statuses = append(statuses, FeaturePauseStatus{Feature: string(feature), PausedUntil: until})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Feature < statuses[j].Feature
+	})
+	return statuses
+}
+
+// wrap wraps the moduleFunc of the feature to skip the cycles while the feature is paused.
+func (p *featurePause) wrap(feature featuregate.Feature, moduleFunc func()) func() {
+	if p == nil {
+		return moduleFunc
+	}
+	return func() {
+		if p.isPaused(feature) {
+			klog.V(4).Infof("skip the cycle of feature %v, paused by the debug handler", feature)
+			return
+		}
+		moduleFunc()
+	}
+}
+
+type featurePauseRef struct {
+	lock  sync.RWMutex
+	pause *featurePause
+}
+
+func (r *featurePauseRef) set(pause *featurePause) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.pause = pause
+}
+
+func (r *featurePauseRef) get() *featurePause {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.pause
+}
+
+// PauseFeatureHttpHandler returns the http handler to pause the feature loop specified by the query parameter
+// "feature" for the duration "ttl", e.g. `POST /debug/pausefeature?feature=BECPUSuppress&ttl=30m`. The ttl defaults
+// to 10m and is at most 1h, and a zero ttl resumes the feature. Only the running features can be paused. It responds
+// the unexpired pauses.
+func PauseFeatureHttpHandler() func(http.ResponseWriter, *http.Request) {
+	return defaultFeaturePause.httpHandler()
+}
+
+func (r *featurePauseRef) httpHandler() func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		pause := r.get()
+		if pause == nil {
+			http.Error(rw, "resmanager is not running", http.StatusServiceUnavailable)
+			return
+		}
+		feature := req.URL.Query().Get("feature")
+		if feature == "" {
+			http.Error(rw, "feature is required", http.StatusBadRequest)
+			return
+		}
+		if !pause.isRunning(featuregate.Feature(feature)) {
+			http.Error(rw, fmt.Sprintf("feature %q is not running", feature), http.StatusBadRequest)
+			return
+		}
+		ttl := defaultFeaturePauseTTL
+		if ttlStr := req.URL.Query().Get("ttl"); ttlStr != "" {
+			var err error
+			if ttl, err = time.ParseDuration(ttlStr); err != nil || ttl < 0 || ttl > maxFeaturePauseTTL {
+				http.Error(rw, fmt.Sprintf("illegal ttl %q, should be a duration within %v", ttlStr,
+					maxFeaturePauseTTL), http.StatusBadRequest)
+				return
+			}
+		}
+		pause.pause(featuregate.Feature(feature), ttl)
+		klog.Infof("feature %s is paused for %v by client %v", feature, ttl, req.RemoteAddr)
+
+		data, err := json.Marshal(pause.list())
+		if err != nil {
+			http.Error(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		if _, err = rw.Write(data); err != nil {
+			klog.Warningf("failed to write feature pauses to client %v, error %v", req.RemoteAddr, err)
+		}
+	}
+}
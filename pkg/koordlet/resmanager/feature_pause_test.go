@@ -0,0 +1,153 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+func Test_featurePause_wrap(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	p := newFeaturePause(newFeatureHealth().isRunning)
+	p.clock = fakeClock
+	cycles := 0
+	cpuSuppressFunc := p.wrap(features.BECPUSuppress, func() { cycles++ })
+	memoryEvictFunc := p.wrap(features.BEMemoryEvict, func() { cycles++ })
+
+	p.pause(features.BECPUSuppress, time.Minute)
+	cpuSuppressFunc()
+	assert.Equal(t, 0, cycles)
+	// the other features are not paused
+	memoryEvictFunc()
+	assert.Equal(t, 1, cycles)
+
+	// the feature loop resumes after the ttl
+	fakeClock.Step(30 * time.Second)
+	cpuSuppressFunc()
+	assert.Equal(t, 1, cycles)
+	fakeClock.Step(30 * time.Second)
+	cpuSuppressFunc()
+	assert.Equal(t, 2, cycles)
+	assert.Len(t, p.list(), 0)
+
+	// a zero ttl resumes the feature at once
+	p.pause(features.BECPUSuppress, time.Minute)
+	p.pause(features.BECPUSuppress, 0)
+	cpuSuppressFunc()
+	assert.Equal(t, 3, cycles)
+
+	var nilPause *featurePause
+	nilPause.wrap(features.BECPUSuppress, func() { cycles++ })()
+	assert.Equal(t, 4, cycles)
+}
+
+func Test_featurePauseRef_httpHandler(t *testing.T) {
+	ref := &featurePauseRef{}
+	handler := ref.httpHandler()
+
+	rw := httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodPost, "/debug/pausefeature?feature=BECPUSuppress", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	health := newFeatureHealth()
+	health.register(features.BECPUSuppress, time.Second)
+	p := newFeaturePause(health.isRunning)
+	p.clock = fakeClock
+	ref.set(p)
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		wantCode int
+	}{
+		{
+			name:     "method not allowed",
+			method:   http.MethodGet,
+			url:      "/debug/pausefeature?feature=BECPUSuppress",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "feature is required",
+			method:   http.MethodPost,
+			url:      "/debug/pausefeature",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown feature",
+			method:   http.MethodPost,
+			url:      "/debug/pausefeature?feature=UnknownFeature",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "feature not running",
+			method:   http.MethodPost,
+			url:      "/debug/pausefeature?feature=BEMemoryEvict",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "illegal ttl",
+			method:   http.MethodPost,
+			url:      "/debug/pausefeature?feature=BECPUSuppress&ttl=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "ttl exceeds the max",
+			method:   http.MethodPost,
+			url:      "/debug/pausefeature?feature=BECPUSuppress&ttl=2h",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			handler(rw, httptest.NewRequest(tt.method, tt.url, nil))
+			assert.Equal(t, tt.wantCode, rw.Code)
+			assert.False(t, p.isPaused(features.BECPUSuppress))
+		})
+	}
+
+	rw = httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodPost, "/debug/pausefeature?feature=BECPUSuppress&ttl=5m", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	var got []FeaturePauseStatus
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &got))
+	assert.Len(t, got, 1)
+	assert.Equal(t, string(features.BECPUSuppress), got[0].Feature)
+	assert.True(t, p.isPaused(features.BECPUSuppress))
+	fakeClock.Step(5 * time.Minute)
+	assert.False(t, p.isPaused(features.BECPUSuppress))
+
+	// pause for the default ttl
+	rw = httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodPost, "/debug/pausefeature?feature=BECPUSuppress", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	fakeClock.Step(defaultFeaturePauseTTL - time.Second)
+	assert.True(t, p.isPaused(features.BECPUSuppress))
+	fakeClock.Step(time.Second)
+	assert.False(t, p.isPaused(features.BECPUSuppress))
+}
@@ -0,0 +1,162 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+)
+
+// FeatureStatusReason tells whether a feature is enforcing, or why it is not.
+type FeatureStatusReason string
+
+const (
+	// FeatureStatusEnabled means the feature is enabled and configured
+	FeatureStatusEnabled FeatureStatusReason = "Enabled"
+	// FeatureStatusGateOff means the feature is disabled by the koordlet feature gates and not enabled by the NodeSLO
+	FeatureStatusGateOff FeatureStatusReason = "GateOff"
+	// FeatureStatusNodeSLODisabled means the feature is switched off by the NodeSLO
+	FeatureStatusNodeSLODisabled FeatureStatusReason = "NodeSLODisabled"
+	// FeatureStatusUnconfigured means the NodeSLO or the strategy of the feature is not set
+	FeatureStatusUnconfigured FeatureStatusReason = "Unconfigured"
+)
+
+var (
+	featureStatusReasons = []FeatureStatusReason{FeatureStatusEnabled, FeatureStatusGateOff,
+		FeatureStatusNodeSLODisabled, FeatureStatusUnconfigured}
+
+	// defaultFeatureStatus serves the feature status of the running resmanager for the debug http handler.
+	defaultFeatureStatus = &featureStatusRef{}
+)
+
+// FeatureStatus is the status of a feature of the resmanager with the reason and a message to explain it.
+type FeatureStatus struct {
+	Feature string              `json:"feature"`
+	Status  FeatureStatusReason `json:"status"`
+	Message string              `json:"message,omitempty"`
+}
+
+// featureStatus returns the status of the feature, which distinguishes the reasons collapsed by isFeatureDisabled.
+func (r *resmanager) featureStatus(feature featuregate.Feature) FeatureStatus {
+	status := FeatureStatus{Feature: string(feature)}
+	if !features.DefaultKoordletFeatureGate.Enabled(feature) && !r.isFeatureEnabledByNodeSLO(feature) {
+		status.Status = FeatureStatusGateOff
+		status.Message = "feature gate is disabled by the koordlet flag and not enabled by the NodeSLO featureGates"
+		return status
+	}
+
+	nodeSLO := r.getNodeSLOCopy()
+	if nodeSLO == nil || reflect.DeepEqual(nodeSLO.Spec, slov1alpha1.NodeSLOSpec{}) {
+		status.Status = FeatureStatusUnconfigured
+		status.Message = "nodeSLO is not set"
+		return status
+	}
+	switch feature {
+	case features.BECPUSuppress, features.BEMemoryEvict, features.BEDiskEvict:
+		threshold := nodeSLO.Spec.ResourceUsedThresholdWithBE
+		if threshold == nil || threshold.Enable == nil {
+			status.Status = FeatureStatusUnconfigured
+			status.Message = "resourceUsedThresholdWithBE.enable of the nodeSLO is not set"
+			return status
+		}
+		if !*threshold.Enable {
+			status.Status = FeatureStatusNodeSLODisabled
+			status.Message = "resourceUsedThresholdWithBE.enable of the nodeSLO is false"
+			return status
+		}
+	}
+	status.Status = FeatureStatusEnabled
+	return status
+}
+
+// listFeatureStatuses returns the status of the running features ordered by the feature name.
+func (r *resmanager) listFeatureStatuses() []FeatureStatus {
+	if r.featureHealth == nil {
+		return nil
+	}
+	healthStatuses := r.featureHealth.list()
+	statuses := make([]FeatureStatus, 0, len(healthStatuses))
+	for _, healthStatus := range healthStatuses {
+		statuses = append(statuses, r.featureStatus(featuregate.Feature(healthStatus.Feature)))
+	}
+	return statuses
+}
+
+// recordFeatureStatuses exports the status of the running features, which is 1 for the current reason of each
+// feature and 0 for the others.
+func (r *resmanager) recordFeatureStatuses() {
+	for _, status := range r.listFeatureStatuses() {
+		for _, reason := range featureStatusReasons {
+			value := 0.0
+			if status.Status == reason {
+				value = 1
+			}
+			metrics.RecordFeatureStatus(status.Feature, string(reason), value)
+		}
+	}
+}
+
+type featureStatusRef struct {
+	lock       sync.RWMutex
+	resManager *resmanager
+}
+
+func (f *featureStatusRef) set(resManager *resmanager) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.resManager = resManager
+}
+
+func (f *featureStatusRef) get() *resmanager {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.resManager
+}
+
+// FeatureStatusHttpHandler returns the http handler to dump the status of the features of the resmanager, which
+// tells why a feature is not enforcing.
+func FeatureStatusHttpHandler() func(http.ResponseWriter, *http.Request) {
+	return defaultFeatureStatus.httpHandler()
+}
+
+func (f *featureStatusRef) httpHandler() func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		resManager := f.get()
+		if resManager == nil {
+			http.Error(rw, "resmanager is not running", http.StatusServiceUnavailable)
+			return
+		}
+		data, err := json.Marshal(resManager.listFeatureStatuses())
+		if err != nil {
+			http.Error(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		if _, err = rw.Write(data); err != nil {
+			klog.Warningf("failed to write feature status to client %v, error %v", req.RemoteAddr, err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+)
+
+func Test_featureStatus(t *testing.T) {
+	enabledGates := map[string]bool{
+		string(features.BECPUSuppress): true,
+		string(features.CPUBurst):      true,
+	}
+	tests := []struct {
+		name       string
+		nodeSLO    *slov1alpha1.NodeSLO
+		feature    featuregate.Feature
+		wantStatus FeatureStatusReason
+	}{
+		{
+			name:       "gate off if nodeSLO is nil",
+			nodeSLO:    nil,
+			feature:    features.BECPUSuppress,
+			wantStatus: FeatureStatusGateOff,
+		},
+		{
+			name: "gate off if not enabled by nodeSLO feature gates",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+						Enable: pointer.BoolPtr(true),
+					},
+				},
+			},
+			feature:    features.BECPUSuppress,
+			wantStatus: FeatureStatusGateOff,
+		},
+		{
+			name: "unconfigured if the threshold strategy is nil",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					FeatureGates: enabledGates,
+				},
+			},
+			feature:    features.BECPUSuppress,
+			wantStatus: FeatureStatusUnconfigured,
+		},
+		{
+			name: "unconfigured if the threshold switch is nil",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{},
+					FeatureGates:                enabledGates,
+				},
+			},
+			feature:    features.BECPUSuppress,
+			wantStatus: FeatureStatusUnconfigured,
+		},
+		{
+			name: "nodeSLO disabled if the threshold switch is off",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+						Enable: pointer.BoolPtr(false),
+					},
+					FeatureGates: enabledGates,
+				},
+			},
+			feature:    features.BECPUSuppress,
+			wantStatus: FeatureStatusNodeSLODisabled,
+		},
+		{
+			name: "enabled if the threshold switch is on",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+						Enable: pointer.BoolPtr(true),
+					},
+					FeatureGates: enabledGates,
+				},
+			},
+			feature:    features.BECPUSuppress,
+			wantStatus: FeatureStatusEnabled,
+		},
+		{
+			name: "enabled if the feature has no nodeSLO switch",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+						Enable: pointer.BoolPtr(false),
+					},
+					FeatureGates: enabledGates,
+				},
+			},
+			feature:    features.CPUBurst,
+			wantStatus: FeatureStatusEnabled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &resmanager{nodeSLO: tt.nodeSLO}
+			got := r.featureStatus(tt.feature)
+			assert.Equal(t, string(tt.feature), got.Feature)
+			assert.Equal(t, tt.wantStatus, got.Status)
+			if tt.wantStatus == FeatureStatusEnabled {
+				assert.Empty(t, got.Message)
+			} else {
+				assert.NotEmpty(t, got.Message)
+			}
+		})
+	}
+}
+
+func Test_recordFeatureStatuses(t *testing.T) {
+	metrics.Register(getNode("80", "120G"))
+	defer metrics.Register(nil)
+
+	r := &resmanager{
+		featureHealth: newFeatureHealth(),
+		nodeSLO: &slov1alpha1.NodeSLO{
+			Spec: slov1alpha1.NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+					Enable: pointer.BoolPtr(false),
+				},
+				FeatureGates: map[string]bool{string(features.BEMemoryEvict): true},
+			},
+		},
+	}
+	r.featureHealth.register(features.BEMemoryEvict, time.Second)
+	r.featureHealth.register(features.BEDiskEvict, time.Second)
+	r.recordFeatureStatuses()
+
+	getStatus := func(feature featuregate.Feature, status FeatureStatusReason) float64 {
+		return testutil.ToFloat64(metrics.FeatureStatus.WithLabelValues("test-node", string(feature), string(status)))
+	}
+	assert.Equal(t, float64(1), getStatus(features.BEMemoryEvict, FeatureStatusNodeSLODisabled))
+	assert.Equal(t, float64(0), getStatus(features.BEMemoryEvict, FeatureStatusEnabled))
+	assert.Equal(t, float64(1), getStatus(features.BEDiskEvict, FeatureStatusGateOff))
+	assert.Equal(t, float64(0), getStatus(features.BEDiskEvict, FeatureStatusNodeSLODisabled))
+}
+
+func Test_featureStatusRef_httpHandler(t *testing.T) {
+	ref := &featureStatusRef{}
+	handler := ref.httpHandler()
+
+	rw := httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodGet, "/debug/featurestatus", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+
+	r := &resmanager{featureHealth: newFeatureHealth()}
+	r.featureHealth.register(features.BECPUSuppress, time.Second)
+	ref.set(r)
+	rw = httptest.NewRecorder()
+	handler(rw, httptest.NewRequest(http.MethodGet, "/debug/featurestatus", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	var got []FeatureStatus
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &got))
+	assert.Len(t, got, 1)
+	assert.Equal(t, string(features.BECPUSuppress), got[0].Feature)
+	assert.Equal(t, FeatureStatusGateOff, got[0].Status)
+}
@@ -17,36 +17,78 @@
 package resmanager
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 const (
 	memoryReleaseBufferPercent = 2
+
+	defaultMemoryEvictGrowthWindowSeconds int64 = 60
 )
 
 type MemoryEvictor struct {
 	resManager    *resmanager
 	lastEvictTime time.Time
+	// softEvictDeadlines records the deadlines of the pods marked to be soft evicted, keyed by pod UID
+	softEvictDeadlines map[string]softEvictMark
+	clock              clock.Clock
+	// lastOOMKills is the oom kill count of the be cgroups at the last read, which is nil if not read yet
+	lastOOMKills *int64
+	// beExhaustedSince is the time since the memory pressure persists with no be pod left to evict, zero if not
+	beExhaustedSince time.Time
+}
+
+// softEvictMark is the soft evict deadline annotated on the pod
+type softEvictMark struct {
+	namespace string
+	name      string
+	deadline  time.Time
 }
 
 type podInfo struct {
 	pod       *corev1.Pod
 	podMetric *metriccache.PodResourceMetric
+	// containerMemoryUsed is the memory usage without cache of each container by name, which is only retrieved if
+	// MemoryEvictByDominantContainer is enabled
+	containerMemoryUsed map[string]int64
+}
+
+// dominantContainerMemoryUsed returns the memory usage of the container using the most memory in the pod.
+func (p *podInfo) dominantContainerMemoryUsed() int64 {
+	var dominant int64
+	for _, used := range p.containerMemoryUsed {
+		if used > dominant {
+			dominant = used
+		}
+	}
+	return dominant
 }
 
 func NewMemoryEvictor(mgr *resmanager) *MemoryEvictor {
 	return &MemoryEvictor{
-		resManager:    mgr,
-		lastEvictTime: time.Now(),
+		resManager:         mgr,
+		lastEvictTime:      time.Now(),
+		softEvictDeadlines: map[string]softEvictMark{},
+		clock:              clock.RealClock{},
 	}
 }
 
@@ -59,47 +101,249 @@ func (m *MemoryEvictor) memoryEvict() {
 		return
 	}
 
+	var oomKills int64
+	oomKillThreshold := m.resManager.config.MemoryEvictOOMKillThreshold
+	if oomKillThreshold > 0 {
+		oomKills = m.getIncreasedOOMKills()
+	}
+
+	evictCtx := m.prepareMemoryEvict()
+	if evictCtx != nil {
+		m.resManager.pressureState.setMemoryPressure(m.getMemoryPressureTTL())
+		m.killAndEvictBEPods(evictCtx)
+		return
+	}
+	m.beExhaustedSince = time.Time{}
+	if oomKillThreshold > 0 && oomKills >= int64(oomKillThreshold) {
+		m.resManager.pressureState.setMemoryPressure(m.getMemoryPressureTTL())
+		m.evictBEPodByOOMKills(oomKills, oomKillThreshold)
+		return
+	}
+	if m.resManager.config.EvictOnNodePressureCondition &&
+		isNodeConditionTrue(m.resManager.statesInformer.GetNode(), corev1.NodeMemoryPressure) {
+		m.resManager.pressureState.setMemoryPressure(m.getMemoryPressureTTL())
+		m.evictBEPodByNodeCondition()
+		return
+	}
+	m.resManager.pressureState.setMemoryPressure(0)
+	// the marked pods are no longer needed to release memory
+	m.clearSoftEvictMarks(nil)
+}
+
+// getMemoryPressureTTL keeps the memory pressure active over the evict cooling time, during which the pressure is not
+// checked, and expires it if the memory evict process stops.
+func (m *MemoryEvictor) getMemoryPressureTTL() time.Duration {
+	return time.Duration(m.resManager.config.MemoryEvictCoolTimeSeconds+2*m.resManager.config.MemoryEvictIntervalSeconds) *
+		time.Second
+}
+
+// getIncreasedOOMKills returns the increase of the oom kill count of the be cgroups since the last read. The first read
+// only takes the baseline, and a decreased count, e.g. the cgroup is recreated, resets the baseline.
+func (m *MemoryEvictor) getIncreasedOOMKills() int64 {
+	oomControl, err := util.GetCgroupMemOOMControl(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort))
+	if err != nil {
+		klog.V(4).Infof("failed to read memory oom control of be cgroups, error: %v", err)
+		return 0
+	}
+	lastOOMKills := m.lastOOMKills
+	m.lastOOMKills = &oomControl.OOMKill
+	if lastOOMKills == nil || oomControl.OOMKill < *lastOOMKills {
+		return 0
+	}
+	return oomControl.OOMKill - *lastOOMKills
+}
+
+// evictBEPodByOOMKills kills and evicts the first BE pod in the eviction order, which relieves the memory pressure
+// proactively while the oom kills are occurring in the be cgroups, even if the node memory usage is below threshold.
+func (m *MemoryEvictor) evictBEPodByOOMKills(oomKills int64, oomKillThreshold int) {
+	m.evictFirstBEPod("evictBEPodByOOMKills",
+		fmt.Sprintf("oom kills in be cgroups: %v, threshold: %v", oomKills, oomKillThreshold),
+		fmt.Sprintf("oomKills=%v", oomKills))
+}
+
+// evictBEPodByNodeCondition kills and evicts the first BE pod in the eviction order while the node reports the
+// MemoryPressure condition, so the kubelet is less likely to evict the LS pods by its own thresholds.
+func (m *MemoryEvictor) evictBEPodByNodeCondition() {
+	m.evictFirstBEPod("evictBEPodByNodeCondition", "node condition MemoryPressure is true",
+		fmt.Sprintf("nodeCondition=%v", corev1.NodeMemoryPressure))
+}
+
+// evictFirstBEPod kills and evicts a single BE pod in the eviction order for the cause besides the memory threshold.
+func (m *MemoryEvictor) evictFirstBEPod(caller, cause, inputs string) {
+	nodeSLO := m.resManager.getNodeSLOCopy()
+	if disabled, err := isFeatureDisabled(nodeSLO, features.BEMemoryEvict); err != nil || disabled {
+		klog.V(4).Infof("skip %s, disabled in NodeSLO, err: %v", caller, err)
+		return
+	}
+	node := m.resManager.statesInformer.GetNode()
+	if node == nil {
+		klog.Warningf("skip %s, Node %v is nil", caller, m.resManager.nodeName)
+		return
+	}
+	_, podMetrics := m.resManager.collectNodeAndPodMetricLast()
+	if podMetrics == nil {
+		klog.Warningf("skip %s, pod metrics are nil", caller)
+		return
+	}
+
+	bePodInfos := m.getSortedPodInfos(podMetrics)
+	m.pruneSoftEvictDeadlines(bePodInfos)
+	message := fmt.Sprintf("%s for node(%v), %s", caller, m.resManager.nodeName, cause)
+	var readyReplicas map[types.UID]int
+	if m.resManager.config.MemoryEvictSkipLastReplica {
+		readyReplicas = m.countReadyReplicasByOwner()
+	}
+	summary := newEvictionSummary(features.BEMemoryEvict, evictPodByNodeMemoryUsage, inputs)
+	killedPod := ""
+	for _, bePod := range bePodInfos {
+		summary.consider()
+		if readyReplicas != nil && isLastReadyReplica(bePod.pod, readyReplicas) {
+			klog.Infof("skip evicting pod %v/%v, it is the last ready replica of its owner",
+				bePod.pod.Namespace, bePod.pod.Name)
+			summary.skip(evictionSkipLastReadyReplica)
+			continue
+		}
+		result := m.killAndEvictBEPod(node, bePod.pod, message)
+		if result == podKillFailed {
+			summary.skip(evictionSkipKillFailed)
+			continue
+		}
+		if result == podKilled {
+			killedPod = bePod.pod.Namespace + "/" + bePod.pod.Name
+			summary.evict()
+		} else {
+			summary.skip(evictionSkipNotKilled)
+		}
+		// either killed or soft evicted, a single pod is evicted for each round
+		break
+	}
+
+	m.lastEvictTime = time.Now()
+	klog.Infof("%s completed, %s, killed pod %q", caller, inputs, killedPod)
+	m.resManager.decisionLog.record(features.BEMemoryEvict, inputs, fmt.Sprintf("killed be pod %q", killedPod))
+	m.resManager.logEvictionSummary(summary)
+}
+
+// memoryEvictContext is the node state to decide the memory eviction with
+type memoryEvictContext struct {
+	node             *corev1.Node
+	podMetrics       []*metriccache.PodResourceMetric
+	memoryCapacity   int64
+	memoryUsed       int64
+	thresholdPercent int64
+	lowerPercent     int64
+	// reserveBytes is the free memory in bytes to keep, which overrides the percentages if not nil
+	reserveBytes *int64
+}
+
+// memoryLowerBound returns the node memory usage in bytes that the eviction releases down to.
+func (c *memoryEvictContext) memoryLowerBound() int64 {
+	if c.reserveBytes != nil {
+		return c.memoryCapacity - *c.reserveBytes
+	}
+	return c.memoryCapacity * c.lowerPercent / 100
+}
+
+// isMemoryReleased returns whether the eviction can stop with the node memory usage.
+func (c *memoryEvictContext) isMemoryReleased(memoryUsed int64) bool {
+	if c.reserveBytes != nil {
+		return memoryUsed <= c.memoryLowerBound()
+	}
+	return memoryUsed < c.memoryLowerBound()
+}
+
+// formatUsage formats the node memory usage measured and the threshold to compare it with for the eviction message.
+func (c *memoryEvictContext) formatUsage() string {
+	if c.reserveBytes != nil {
+		return fmt.Sprintf("memory used %v of capacity %v, free memory %v below reserve bytes %v", c.memoryUsed,
+			c.memoryCapacity, c.memoryCapacity-c.memoryUsed, *c.reserveBytes)
+	}
+	return fmt.Sprintf("memory used %v(%v%%) of capacity %v, threshold %v%%", c.memoryUsed,
+		c.memoryUsed*100/c.memoryCapacity, c.memoryCapacity, c.thresholdPercent)
+}
+
+func (c *memoryEvictContext) String() string {
+	if c.reserveBytes != nil {
+		return fmt.Sprintf("memoryCapacity=%v memoryUsed=%v reserveBytes=%v", c.memoryCapacity, c.memoryUsed, *c.reserveBytes)
+	}
+	return fmt.Sprintf("memoryCapacity=%v memoryUsed=%v lowerPercent=%v", c.memoryCapacity, c.memoryUsed, c.lowerPercent)
+}
+
+// prepareMemoryEvict checks if the memory eviction is required with the current metrics, and returns the context to
+// evict with. It returns nil if no need to evict.
+func (m *MemoryEvictor) prepareMemoryEvict() *memoryEvictContext {
 	nodeSLO := m.resManager.getNodeSLOCopy()
 	if disabled, err := isFeatureDisabled(nodeSLO, features.BEMemoryEvict); err != nil {
 		klog.Errorf("failed to acquire memory eviction feature-gate, error: %v", err)
-		return
+		return nil
 	} else if disabled {
 		klog.Warningf("skip memory evict, disabled in NodeSLO")
-		return
+		return nil
 	}
 
 	thresholdConfig := nodeSLO.Spec.ResourceUsedThresholdWithBE
+	var reserveBytes *int64
+	if thresholdConfig.MemoryEvictReserveBytes != nil {
+		reserveBytes = pointer.Int64Ptr(thresholdConfig.MemoryEvictReserveBytes.Value())
+	}
 	thresholdPercent := thresholdConfig.MemoryEvictThresholdPercent
-	if thresholdPercent == nil {
+	if reserveBytes != nil {
+		if *reserveBytes < 0 {
+			klog.Warningf("skip memory evict, reserve bytes(%v) should not be less than 0", *reserveBytes)
+			return nil
+		}
+	} else if thresholdPercent == nil {
 		klog.Warningf("skip memory evict, threshold percent is nil")
-		return
+		return nil
 	} else if *thresholdPercent < 0 {
 		klog.Warningf("skip memory evict, threshold percent(%v) should greater than 0", thresholdPercent)
-		return
+		return nil
 	}
 
-	nodeMetric, podMetrics := m.resManager.collectNodeAndPodMetricLast()
-	if nodeMetric == nil {
-		klog.Warningf("skip memory evict, NodeMetric is nil")
-		return
+	nodeMetric, podMetrics := m.resManager.collectNodeAndPodMetricWithWindow(thresholdConfig.MemoryEvictMetricWindowSeconds,
+		thresholdConfig.MemoryEvictMetricAggregation)
+	if nodeMetric == nil || podMetrics == nil {
+		klog.Warningf("skip memory evict, got nil node metric or nil pod metrics, nodeMetric %v, podMetrics %v",
+			nodeMetric, podMetrics)
+		return nil
 	}
 
 	node := m.resManager.statesInformer.GetNode()
 	if node == nil {
 		klog.Warningf("skip memory evict, Node %v is nil", m.resManager.nodeName)
-		return
+		return nil
 	}
 
 	memoryCapacity := node.Status.Capacity.Memory().Value()
 	if memoryCapacity <= 0 {
 		klog.Warningf("skip memory evict, memory capacity(%v) should greater than 0", memoryCapacity)
-		return
+		return nil
+	}
+	m.evaluateShadowMemoryEvict(nodeSLO.Spec.ShadowResourceUsedThresholdWithBE, thresholdConfig, memoryCapacity,
+		nodeMetric.MemoryUsed.MemoryWithoutCache.Value())
+
+	if reserveBytes != nil {
+		memoryUsed := nodeMetric.MemoryUsed.MemoryWithoutCache.Value()
+		if memoryCapacity-memoryUsed >= *reserveBytes {
+			klog.Infof("skip memory evict, node memory free(%v) is no less than reserve bytes(%v)",
+				memoryCapacity-memoryUsed, *reserveBytes)
+			return nil
+		}
+		klog.Infof("node(%v) MemoryUsage(%v), MemoryCapacity(%v), evictReserveBytes(%v)",
+			m.resManager.nodeName, memoryUsed, memoryCapacity, *reserveBytes)
+		return &memoryEvictContext{
+			node:           node,
+			podMetrics:     podMetrics,
+			memoryCapacity: memoryCapacity,
+			memoryUsed:     memoryUsed,
+			reserveBytes:   reserveBytes,
+		}
 	}
 
 	nodeMemoryUsage := nodeMetric.MemoryUsed.MemoryWithoutCache.Value() * 100 / memoryCapacity
 	if nodeMemoryUsage < *thresholdPercent {
 		klog.Infof("skip memory evict, node memory usage(%v) is below threshold(%v)", nodeMemoryUsage, thresholdConfig)
-		return
+		return nil
 	}
 
 	klog.Infof("node(%v) MemoryUsage(%v): %.2f, evictThresholdUsage: %.2f",
@@ -109,60 +353,434 @@ func (m *MemoryEvictor) memoryEvict() {
 		float64(*thresholdPercent)/100,
 	)
 
-	lowPercent := *thresholdPercent - memoryReleaseBufferPercent
-	memoryNeedRelease := memoryCapacity * (nodeMemoryUsage - lowPercent) / 100
-	m.killAndEvictBEPods(node, podMetrics, memoryNeedRelease)
+	return &memoryEvictContext{
+		node:             node,
+		podMetrics:       podMetrics,
+		memoryCapacity:   memoryCapacity,
+		memoryUsed:       nodeMetric.MemoryUsed.MemoryWithoutCache.Value(),
+		thresholdPercent: *thresholdPercent,
+		lowerPercent:     getMemoryEvictLowerPercent(thresholdConfig),
+	}
 }
 
-func (m *MemoryEvictor) killAndEvictBEPods(node *corev1.Node, podMetrics []*metriccache.PodResourceMetric, memoryNeedRelease int64) {
-	bePodInfos := m.getSortedPodInfos(podMetrics)
-	message := fmt.Sprintf("killAndEvictBEPods for node(%v), need to release memory: %v", m.resManager.nodeName, memoryNeedRelease)
+// evaluateShadowMemoryEvict calculates the memory to release under the shadow threshold strategy with the same node
+// memory usage, and reports it along with the active one. It returns -1 if the shadow strategy is not set. No pod is
+// evicted by the shadow strategy.
+func (m *MemoryEvictor) evaluateShadowMemoryEvict(shadow, active *slov1alpha1.ResourceThresholdStrategy,
+	memoryCapacity, memoryUsed int64) int64 {
+	if shadow == nil {
+		return -1
+	}
+	activeRelease := getMemoryToRelease(active, memoryCapacity, memoryUsed)
+	shadowRelease := getMemoryToRelease(shadow, memoryCapacity, memoryUsed)
+	metrics.RecordThresholdStrategyDecision(string(features.BEMemoryEvict), metrics.ThresholdStrategyActive,
+		float64(activeRelease))
+	metrics.RecordThresholdStrategyDecision(string(features.BEMemoryEvict), metrics.ThresholdStrategyShadow,
+		float64(shadowRelease))
+	if shadowRelease != activeRelease {
+		klog.V(4).Infof("shadow threshold would release memory %v by evicting, while the active one releases %v",
+			shadowRelease, activeRelease)
+	}
+	return shadowRelease
+}
+
+// getMemoryToRelease returns the node memory in bytes to release by evicting under the threshold config, which is 0
+// if the eviction is not triggered.
+func getMemoryToRelease(thresholdConfig *slov1alpha1.ResourceThresholdStrategy, memoryCapacity, memoryUsed int64) int64 {
+	evictCtx := &memoryEvictContext{memoryCapacity: memoryCapacity, memoryUsed: memoryUsed}
+	if thresholdConfig.MemoryEvictReserveBytes != nil {
+		evictCtx.reserveBytes = pointer.Int64Ptr(thresholdConfig.MemoryEvictReserveBytes.Value())
+		if *evictCtx.reserveBytes < 0 || memoryCapacity-memoryUsed >= *evictCtx.reserveBytes {
+			return 0
+		}
+	} else {
+		thresholdPercent := thresholdConfig.MemoryEvictThresholdPercent
+		if thresholdPercent == nil || *thresholdPercent < 0 || memoryUsed*100/memoryCapacity < *thresholdPercent {
+			return 0
+		}
+		evictCtx.lowerPercent = getMemoryEvictLowerPercent(thresholdConfig)
+	}
+	if release := memoryUsed - evictCtx.memoryLowerBound(); release > 0 {
+		return release
+	}
+	return 0
+}
+
+// getMemoryEvictLowerPercent returns the percent of node memory usage that the eviction releases down to.
+// It uses MemoryEvictLowerPercent if it is valid, otherwise a buffer below MemoryEvictThresholdPercent.
+func getMemoryEvictLowerPercent(thresholdConfig *slov1alpha1.ResourceThresholdStrategy) int64 {
+	thresholdPercent := *thresholdConfig.MemoryEvictThresholdPercent
+	defaultLowerPercent := thresholdPercent - memoryReleaseBufferPercent
+	lowerPercent := thresholdConfig.MemoryEvictLowerPercent
+	if lowerPercent == nil {
+		return defaultLowerPercent
+	}
+	if *lowerPercent < 0 || *lowerPercent > thresholdPercent {
+		klog.Warningf("memory evict lower percent(%v) should be in [0, %v], use %v instead",
+			*lowerPercent, thresholdPercent, defaultLowerPercent)
+		return defaultLowerPercent
+	}
+	return *lowerPercent
+}
+
+// killAndEvictBEPods kills and evicts BE pods one by one until the node memory usage drops below the lower bound.
+// The node memory usage is re-measured between evictions. Since the measured usage can lag behind the kills, the
+// usage is also estimated by subtracting the memory of the killed pods, and the smaller one is taken.
+func (m *MemoryEvictor) killAndEvictBEPods(evictCtx *memoryEvictContext) {
+	node := evictCtx.node
+	bePodInfos := m.getSortedPodInfos(evictCtx.podMetrics)
+	m.pruneSoftEvictDeadlines(bePodInfos)
+	memoryLowerBound := evictCtx.memoryLowerBound()
+	memoryUsed := evictCtx.memoryUsed
+	// the usage in the message is taken from the decision rather than the metrics refreshed during the eviction
+	message := fmt.Sprintf("killAndEvictBEPods for node(%v), need to release memory: %v, %s", m.resManager.nodeName,
+		memoryUsed-memoryLowerBound, evictCtx.formatUsage())
+	initialMemoryUsed := memoryUsed
 	memoryReleased := int64(0)
 
-	var killedPods []*corev1.Pod
+	var readyReplicas map[types.UID]int
+	if m.resManager.config.MemoryEvictSkipLastReplica {
+		readyReplicas = m.countReadyReplicasByOwner()
+	}
+
+	summary := newEvictionSummary(features.BEMemoryEvict, evictPodByNodeMemoryUsage, evictCtx.String())
+	killedCount := 0
+	released := false
 	for _, bePod := range bePodInfos {
-		if memoryReleased < memoryNeedRelease {
-			killMsg := fmt.Sprintf("%v, kill pod: %v", message, bePod.pod.Name)
-			killContainers(bePod.pod, killMsg)
-			killedPods = append(killedPods, bePod.pod)
-			if bePod.podMetric != nil {
-				memoryReleased += bePod.podMetric.MemoryUsed.MemoryWithoutCache.Value()
+		if killedCount > 0 {
+			if nodeMetric := m.resManager.collectNodeMetricLast(); nodeMetric != nil {
+				memoryUsed = nodeMetric.MemoryUsed.MemoryWithoutCache.Value()
 			}
 		}
-	}
+		if estimatedMemoryUsed := initialMemoryUsed - memoryReleased; estimatedMemoryUsed < memoryUsed {
+			memoryUsed = estimatedMemoryUsed
+		}
+		if evictCtx.isMemoryReleased(memoryUsed) {
+			released = true
+			break
+		}
+
+		summary.consider()
+		if readyReplicas != nil && isLastReadyReplica(bePod.pod, readyReplicas) {
+			klog.Infof("skip evicting pod %v/%v, it is the last ready replica of its owner",
+				bePod.pod.Namespace, bePod.pod.Name)
+			summary.skip(evictionSkipLastReadyReplica)
+			continue
+		}
 
-	m.resManager.evictPodsIfNotEvicted(killedPods, node, evictPodByNodeMemoryUsage, message)
+		result := m.killAndEvictBEPod(node, bePod.pod, message)
+		if result == podKillFailed {
+			// the pod failed to kill keeps using the memory, try the next one
+			summary.skip(evictionSkipKillFailed)
+			continue
+		}
+		// the memory of the soft evicted pods is also taken as released, which is expected to be released by the
+		// workload itself before the deadline
+		if result == podKilled {
+			killedCount++
+			summary.evict()
+		} else {
+			summary.skip(evictionSkipNotKilled)
+		}
+		if ownerUID, ok := getReplicaOwnerUID(bePod.pod); ok && readyReplicas != nil && isPodReady(bePod.pod) {
+			readyReplicas[ownerUID]--
+		}
+		if bePod.podMetric != nil {
+			memoryReleased += bePod.podMetric.MemoryUsed.MemoryWithoutCache.Value()
+		}
+	}
 
 	m.lastEvictTime = time.Now()
-	klog.Infof("killAndEvictBEPods completed, memoryNeedRelease(%v) memoryReleased(%v)", memoryNeedRelease, memoryNeedRelease)
+	klog.Infof("killAndEvictBEPods completed, killed pods %v, memoryLowerBound(%v) memoryUsed(%v) memoryReleased(%v)",
+		killedCount, memoryLowerBound, memoryUsed, memoryReleased)
+	m.resManager.decisionLog.record(features.BEMemoryEvict, evictCtx.String(), fmt.Sprintf("killed %v be pods, memoryReleased=%v", killedCount, memoryReleased))
+	m.resManager.logEvictionSummary(summary)
+
+	if estimatedMemoryUsed := initialMemoryUsed - memoryReleased; estimatedMemoryUsed < memoryUsed {
+		memoryUsed = estimatedMemoryUsed
+	}
+	if released || evictCtx.isMemoryReleased(memoryUsed) {
+		m.beExhaustedSince = time.Time{}
+		return
+	}
+	m.evictLSPodLastResort(evictCtx, memoryUsed)
+}
+
+// podKillResult is the result of killing and evicting a BE pod
+type podKillResult int
+
+const (
+	// podKilled means the pod is killed and evicted
+	podKilled podKillResult = iota
+	// podSoftEvicted means the pod is marked with the soft evict deadline, or waits until the deadline
+	podSoftEvicted
+	// podKillFailed means the pod fails to kill in the KillContainersStrict mode, and is not evicted
+	podKillFailed
+)
+
+// killAndEvictBEPod kills and evicts the BE pod, and returns whether the pod is killed, soft evicted or failed to kill.
+// If soft eviction is enabled, the pod is marked with a deadline at first, and only gets killed and evicted if it is
+// still present after the deadline.
+func (m *MemoryEvictor) killAndEvictBEPod(node *corev1.Node, pod *corev1.Pod, message string) podKillResult {
+	if m.resManager.config.MemoryEvictSoftEvict {
+		mark, marked := m.softEvictDeadlines[string(pod.UID)]
+		if !marked {
+			err := m.markPodSoftEvict(pod)
+			if err == nil {
+				return podSoftEvicted
+			}
+			klog.Errorf("failed to mark pod %v/%v to soft evict, evict it directly, error: %v",
+				pod.Namespace, pod.Name, err)
+		} else if m.clock.Now().Before(mark.deadline) {
+			klog.V(4).Infof("pod %v/%v is soft evicted, wait until the deadline %v", pod.Namespace, pod.Name, mark.deadline)
+			return podSoftEvicted
+		}
+	}
+
+	killMsg := fmt.Sprintf("%v, kill pod: %v", message, pod.Name)
+	if err := killContainers(pod, killMsg); err != nil && m.resManager.config.KillContainersStrict {
+		klog.Errorf("failed to kill pod %v/%v, skip evicting it and retry later, error: %v", pod.Namespace, pod.Name, err)
+		return podKillFailed
+	}
+	m.resManager.evictPodIfNotEvicted(pod, node, evictPodByNodeMemoryUsage, message)
+	delete(m.softEvictDeadlines, string(pod.UID))
+	return podKilled
+}
+
+// markPodSoftEvict annotates the pod with the soft evict deadline, so the workload can terminate itself gracefully
+func (m *MemoryEvictor) markPodSoftEvict(pod *corev1.Pod) error {
+	deadline := m.clock.Now().Add(time.Duration(m.resManager.config.MemoryEvictSoftEvictGraceSeconds) * time.Second)
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, extension.AnnotationPodSoftEvictDeadline,
+		deadline.UTC().Format(time.RFC3339))
+	m.resManager.throttleWrite()
+	_, err := m.resManager.kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name,
+		types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return err
+	}
+	m.softEvictDeadlines[string(pod.UID)] = softEvictMark{namespace: pod.Namespace, name: pod.Name, deadline: deadline}
+	klog.Infof("mark pod %v/%v to soft evict, deadline %v", pod.Namespace, pod.Name, deadline)
+	return nil
+}
+
+// pruneSoftEvictDeadlines removes the deadlines and the annotations of the pods which are no longer the candidates,
+// e.g. terminated
+func (m *MemoryEvictor) pruneSoftEvictDeadlines(bePodInfos []*podInfo) {
+	if len(m.softEvictDeadlines) == 0 {
+		return
+	}
+	candidates := make(map[string]struct{}, len(bePodInfos))
+	for _, info := range bePodInfos {
+		candidates[string(info.pod.UID)] = struct{}{}
+	}
+	m.clearSoftEvictMarks(candidates)
+}
+
+// clearSoftEvictMarks removes the soft evict deadlines and the annotations of the marked pods except the kept ones. The
+// mark is kept to retry later if the annotation fails to remove, while it is dropped for a missing pod.
+func (m *MemoryEvictor) clearSoftEvictMarks(keptPods map[string]struct{}) {
+	for podUID, mark := range m.softEvictDeadlines {
+		if _, ok := keptPods[podUID]; ok {
+			continue
+		}
+		patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, extension.AnnotationPodSoftEvictDeadline)
+		m.resManager.throttleWrite()
+		_, err := m.resManager.kubeClient.CoreV1().Pods(mark.namespace).Patch(context.TODO(), mark.name,
+			types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			klog.Warningf("failed to clear the soft evict mark of pod %v/%v, error: %v", mark.namespace, mark.name, err)
+			continue
+		}
+		delete(m.softEvictDeadlines, podUID)
+		klog.V(4).Infof("clear the soft evict mark of pod %v/%v", mark.namespace, mark.name)
+	}
+}
+
+// countReadyReplicasByOwner counts the ready pods on the node by the UID of their workload owner.
+// It is best-effort since the replicas on the other nodes are invisible, which makes the skipping conservative.
+func (m *MemoryEvictor) countReadyReplicasByOwner() map[types.UID]int {
+	readyReplicas := map[types.UID]int{}
+	for _, podMeta := range m.resManager.statesInformer.GetAllPods() {
+		if podMeta == nil || podMeta.Pod == nil || !isPodReady(podMeta.Pod) {
+			continue
+		}
+		if ownerUID, ok := getReplicaOwnerUID(podMeta.Pod); ok {
+			readyReplicas[ownerUID]++
+		}
+	}
+	return readyReplicas
+}
+
+// isLastReadyReplica returns whether the pod is the only ready replica of its workload owner.
+func isLastReadyReplica(pod *corev1.Pod, readyReplicas map[types.UID]int) bool {
+	ownerUID, ok := getReplicaOwnerUID(pod)
+	if !ok || !isPodReady(pod) {
+		return false
+	}
+	return readyReplicas[ownerUID] <= 1
+}
+
+// getReplicaOwnerUID returns the UID of the controller owner if the pod is a replica of a ReplicaSet or StatefulSet.
+func getReplicaOwnerUID(pod *corev1.Pod) (types.UID, bool) {
+	ownerRef := metav1.GetControllerOf(pod)
+	if ownerRef == nil || (ownerRef.Kind != "ReplicaSet" && ownerRef.Kind != "StatefulSet") {
+		return "", false
+	}
+	return ownerRef.UID, true
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
 }
 
+// getSortedPodInfos returns the BE pods in the order to evict, which prefers the pods with lower priority, lower
+// eviction cost and more memory usage. The eviction cost is compared first if MemoryEvictCostOrder is primary.
+// The combined pressure score replaces the memory usage if MemoryEvictCombinedPressure is enabled.
 func (m *MemoryEvictor) getSortedPodInfos(podMetrics []*metriccache.PodResourceMetric) []*podInfo {
 	podMetricMap := make(map[string]*metriccache.PodResourceMetric, len(podMetrics))
 	for _, podMetric := range podMetrics {
 		podMetricMap[podMetric.PodUID] = podMetric
 	}
 
+	byDominantContainer := m.resManager.config != nil && m.resManager.config.MemoryEvictByDominantContainer
 	var bePodInfos []*podInfo
 	for _, podMeta := range m.resManager.statesInformer.GetAllPods() {
 		pod := podMeta.Pod
+		if !m.resManager.isEnforcementEligible(pod) {
+			continue
+		}
 		if extension.GetPodQoSClass(pod) == extension.QoSBE {
 			info := &podInfo{
 				pod:       pod,
 				podMetric: podMetricMap[string(pod.UID)],
 			}
+			if byDominantContainer {
+				info.containerMemoryUsed = m.resManager.collectContainersMemoryUsedLast(pod)
+			}
 			bePodInfos = append(bePodInfos, info)
 		}
 	}
 
+	costPrimary := m.resManager.config != nil && m.resManager.config.MemoryEvictCostOrder == EvictionCostOrderPrimary
+	costs := make(map[types.UID]int32, len(bePodInfos))
+	for _, info := range bePodInfos {
+		cost, err := extension.GetPodEvictionCost(info.pod)
+		if err != nil {
+			klog.V(4).Infof("failed to parse eviction cost of pod %s/%s, regard it as 0, err: %v",
+				info.pod.Namespace, info.pod.Name, err)
+		}
+		costs[info.pod.UID] = cost
+	}
+
+	var scores map[types.UID]float64
+	if m.resManager.config != nil && m.resManager.config.MemoryEvictCombinedPressure {
+		scores = m.getPodPressureScores(bePodInfos)
+	}
+
+	growths := m.getPodMemoryGrowths(bePodInfos)
+
 	sort.Slice(bePodInfos, func(i, j int) bool {
+		costI, costJ := costs[bePodInfos[i].pod.UID], costs[bePodInfos[j].pod.UID]
+		if costPrimary && costI != costJ {
+			return costI < costJ
+		}
 		// TODO: https://github.com/koordinator-sh/koordinator/pull/65#discussion_r849048467
-		if bePodInfos[i].pod.Spec.Priority == nil || bePodInfos[j].pod.Spec.Priority == nil ||
-			*bePodInfos[i].pod.Spec.Priority == *bePodInfos[j].pod.Spec.Priority {
-			return bePodInfos[i].podMetric.MemoryUsed.MemoryWithoutCache.Value() > bePodInfos[j].podMetric.MemoryUsed.MemoryWithoutCache.Value()
+		if bePodInfos[i].pod.Spec.Priority != nil && bePodInfos[j].pod.Spec.Priority != nil &&
+			*bePodInfos[i].pod.Spec.Priority != *bePodInfos[j].pod.Spec.Priority {
+			return *bePodInfos[i].pod.Spec.Priority < *bePodInfos[j].pod.Spec.Priority
+		}
+		if costI != costJ {
+			return costI < costJ
+		}
+		if growths != nil {
+			growthI, growthJ := growths[string(bePodInfos[i].pod.UID)], growths[string(bePodInfos[j].pod.UID)]
+			if growthI != growthJ {
+				return growthI > growthJ
+			}
+		}
+		if scores != nil {
+			return scores[bePodInfos[i].pod.UID] > scores[bePodInfos[j].pod.UID]
 		}
-		return *bePodInfos[i].pod.Spec.Priority < *bePodInfos[j].pod.Spec.Priority
+		if byDominantContainer {
+			dominantI, dominantJ := bePodInfos[i].dominantContainerMemoryUsed(), bePodInfos[j].dominantContainerMemoryUsed()
+			if dominantI != dominantJ {
+				return dominantI > dominantJ
+			}
+		}
+		return bePodInfos[i].podMetric.MemoryUsed.MemoryWithoutCache.Value() > bePodInfos[j].podMetric.MemoryUsed.MemoryWithoutCache.Value()
 	})
 
 	return bePodInfos
 }
+
+// getPodMemoryGrowths returns the memory growth rates of the BE pods in the growth window, or nil if preferring the fast
+// growers is disabled or the node memory usage is not rising.
+func (m *MemoryEvictor) getPodMemoryGrowths(bePodInfos []*podInfo) map[string]float64 {
+	nodeSLO := m.resManager.getNodeSLOCopy()
+	if nodeSLO == nil || nodeSLO.Spec.ResourceUsedThresholdWithBE == nil {
+		return nil
+	}
+	thresholdConfig := nodeSLO.Spec.ResourceUsedThresholdWithBE
+	if thresholdConfig.MemoryEvictPreferFastGrowers == nil || !*thresholdConfig.MemoryEvictPreferFastGrowers {
+		return nil
+	}
+	windowSeconds := defaultMemoryEvictGrowthWindowSeconds
+	if thresholdConfig.MemoryEvictGrowthWindowSeconds != nil && *thresholdConfig.MemoryEvictGrowthWindowSeconds > 0 {
+		windowSeconds = *thresholdConfig.MemoryEvictGrowthWindowSeconds
+	}
+
+	podsMeta := make([]*statesinformer.PodMeta, 0, len(bePodInfos))
+	for _, info := range bePodInfos {
+		podsMeta = append(podsMeta, &statesinformer.PodMeta{Pod: info.pod})
+	}
+	nodeGrowth, podGrowths := m.resManager.collectMemoryGrowthWithWindow(windowSeconds, podsMeta)
+	if nodeGrowth <= 0 {
+		klog.V(5).Infof("node memory is not rising in the last %v seconds, growth %v bytes/s", windowSeconds, nodeGrowth)
+		return nil
+	}
+	klog.V(5).Infof("node memory is rising by %v bytes/s, prefer evicting the fast growing be pods %v",
+		nodeGrowth, podGrowths)
+	return podGrowths
+}
+
+// getPodPressureScores returns the combined pressure scores of the BE pods, or nil if the node capacity is unknown.
+func (m *MemoryEvictor) getPodPressureScores(bePodInfos []*podInfo) map[types.UID]float64 {
+	node := m.resManager.statesInformer.GetNode()
+	if node == nil {
+		klog.Warningf("failed to score be pods by combined pressure, node %v is nil", m.resManager.nodeName)
+		return nil
+	}
+	cpuCapacity := node.Status.Capacity.Cpu().MilliValue()
+	memoryCapacity := node.Status.Capacity.Memory().Value()
+	if cpuCapacity <= 0 || memoryCapacity <= 0 {
+		klog.Warningf("failed to score be pods by combined pressure, invalid node capacity cpu(%v) memory(%v)",
+			cpuCapacity, memoryCapacity)
+		return nil
+	}
+	scores := make(map[types.UID]float64, len(bePodInfos))
+	for _, info := range bePodInfos {
+		scores[info.pod.UID] = getPodPressureScore(info.podMetric, cpuCapacity, memoryCapacity,
+			m.resManager.config.MemoryEvictCPUWeight, m.resManager.config.MemoryEvictMemoryWeight)
+	}
+	return scores
+}
+
+// getPodPressureScore sums the cpu and memory usage ratios of the pod to the node capacity by the weights, so the pod
+// relieving the most combined pressure gets the highest score.
+func getPodPressureScore(podMetric *metriccache.PodResourceMetric, cpuCapacity, memoryCapacity int64,
+	cpuWeight, memoryWeight float64) float64 {
+	if podMetric == nil {
+		return 0
+	}
+	cpuRatio := float64(podMetric.CPUUsed.CPUUsed.MilliValue()) / float64(cpuCapacity)
+	memoryRatio := float64(podMetric.MemoryUsed.MemoryWithoutCache.Value()) / float64(memoryCapacity)
+	return cpuWeight*cpuRatio + memoryWeight*memoryRatio
+}
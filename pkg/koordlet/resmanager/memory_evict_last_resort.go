@@ -0,0 +1,128 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+)
+
+const (
+	// systemCriticalPriority is the min priority of the system-cluster-critical and system-node-critical pods,
+	// which are never evicted as the last resort
+	systemCriticalPriority = int32(2000000000)
+)
+
+// evictLSPodLastResort evicts the LS pod with the lowest priority when no BE pod is left to evict while the node
+// memory usage is still above the lower bound. It only takes effect if MemoryEvictLSLastResort is enabled, and the
+// memory pressure has persisted with the BE pods exhausted for MemoryEvictLSSustainedSeconds. A single LS pod is
+// evicted in each memory evict process.
+func (m *MemoryEvictor) evictLSPodLastResort(evictCtx *memoryEvictContext, memoryUsed int64) {
+	cfg := m.resManager.config
+	if cfg == nil || !cfg.MemoryEvictLSLastResort {
+		return
+	}
+	now := m.clock.Now()
+	if m.beExhaustedSince.IsZero() {
+		m.beExhaustedSince = now
+		klog.Warningf("no be pod is left to evict while node(%v) memory used %v is above the lower bound %v, "+
+			"ls pods are evicted as the last resort if it persists for %vs", m.resManager.nodeName, memoryUsed,
+			evictCtx.memoryLowerBound(), cfg.MemoryEvictLSSustainedSeconds)
+		return
+	}
+	sustained := now.Sub(m.beExhaustedSince)
+	if sustained < time.Duration(cfg.MemoryEvictLSSustainedSeconds)*time.Second {
+		klog.Warningf("no be pod is left to evict while node(%v) memory used %v is above the lower bound %v for %v, "+
+			"wait for %vs to evict ls pods as the last resort", m.resManager.nodeName, memoryUsed,
+			evictCtx.memoryLowerBound(), sustained, cfg.MemoryEvictLSSustainedSeconds)
+		return
+	}
+
+	lsPodInfos := m.getSortedLSPodInfos(evictCtx.podMetrics)
+	if len(lsPodInfos) == 0 {
+		klog.Warningf("no be or ls pod is left to evict as the last resort, node(%v) memory used %v, lower bound %v",
+			m.resManager.nodeName, memoryUsed, evictCtx.memoryLowerBound())
+		return
+	}
+	lsPod := lsPodInfos[0].pod
+	message := fmt.Sprintf("evictLSPodLastResort for node(%v), no be pod is left to evict while the memory pressure "+
+		"persists for %v, memory used %v, lower bound %v", m.resManager.nodeName, sustained, memoryUsed,
+		evictCtx.memoryLowerBound())
+	klog.Warningf("LAST RESORT: evicting ls pod %v/%v, %v", lsPod.Namespace, lsPod.Name, message)
+	killMsg := fmt.Sprintf("%v, kill pod: %v", message, lsPod.Name)
+	if err := killContainers(lsPod, killMsg); err != nil && cfg.KillContainersStrict {
+		klog.Errorf("failed to kill ls pod %v/%v as the last resort, skip evicting it and retry later, error: %v",
+			lsPod.Namespace, lsPod.Name, err)
+		return
+	}
+	m.resManager.evictPodIfNotEvicted(lsPod, evictCtx.node, evictPodByLastResort, message)
+	m.lastEvictTime = time.Now()
+	m.resManager.decisionLog.record(features.BEMemoryEvict, evictCtx.String(),
+		fmt.Sprintf("evicted ls pod %s/%s as the last resort", lsPod.Namespace, lsPod.Name))
+}
+
+// getSortedLSPodInfos returns the LS pods that can be evicted as the last resort, in the order of the lower priority
+// and more memory usage. The system critical pods and the evicted pods are excluded.
+func (m *MemoryEvictor) getSortedLSPodInfos(podMetrics []*metriccache.PodResourceMetric) []*podInfo {
+	podMetricMap := make(map[string]*metriccache.PodResourceMetric, len(podMetrics))
+	for _, podMetric := range podMetrics {
+		podMetricMap[podMetric.PodUID] = podMetric
+	}
+
+	var lsPodInfos []*podInfo
+	for _, podMeta := range m.resManager.statesInformer.GetAllPods() {
+		pod := podMeta.Pod
+		if !m.resManager.isEnforcementEligible(pod) || extension.GetPodQoSClass(pod) != extension.QoSLS {
+			continue
+		}
+		if pod.Spec.Priority != nil && *pod.Spec.Priority >= systemCriticalPriority {
+			continue
+		}
+		// skip the pods evicted as the last resort before, so the next ls pod is evicted if the pressure persists
+		if _, evicted := m.resManager.podsEvicted.Get(string(pod.UID)); evicted {
+			continue
+		}
+		lsPodInfos = append(lsPodInfos, &podInfo{pod: pod, podMetric: podMetricMap[string(pod.UID)]})
+	}
+
+	getPriority := func(info *podInfo) int32 {
+		if info.pod.Spec.Priority == nil {
+			return 0
+		}
+		return *info.pod.Spec.Priority
+	}
+	getMemoryUsed := func(info *podInfo) int64 {
+		if info.podMetric == nil {
+			return 0
+		}
+		return info.podMetric.MemoryUsed.MemoryWithoutCache.Value()
+	}
+	sort.SliceStable(lsPodInfos, func(i, j int) bool {
+		if priorityI, priorityJ := getPriority(lsPodInfos[i]), getPriority(lsPodInfos[j]); priorityI != priorityJ {
+			return priorityI < priorityJ
+		}
+		return getMemoryUsed(lsPodInfos[i]) > getMemoryUsed(lsPodInfos[j])
+	})
+	return lsPodInfos
+}
@@ -0,0 +1,147 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/runtime/handler"
+	"github.com/koordinator-sh/koordinator/pkg/tools/cache"
+)
+
+func Test_memoryEvict_evictLSPodLastResort(t *testing.T) {
+	tests := []struct {
+		name         string
+		lastResort   bool
+		memoryUsed   string
+		lowerPercent int64
+		// the clock steps before each memory evict process
+		steps []time.Duration
+		// the evicted pods after each memory evict process
+		expectEvicted []map[string]bool
+	}{
+		{
+			name:         "not evict ls pods if disabled",
+			lastResort:   false,
+			memoryUsed:   "95G",
+			lowerPercent: 30,
+			steps:        []time.Duration{0, 10 * time.Minute},
+			expectEvicted: []map[string]bool{
+				{"test_be_pod": true},
+				{"test_be_pod": true},
+			},
+		},
+		{
+			name:         "evict the lowest priority ls pod after the be pods are exhausted for the sustained window",
+			lastResort:   true,
+			memoryUsed:   "95G",
+			lowerPercent: 30,
+			steps:        []time.Duration{0, 100 * time.Second, 200 * time.Second, time.Second},
+			expectEvicted: []map[string]bool{
+				{"test_be_pod": true},
+				{"test_be_pod": true},
+				{"test_be_pod": true, "test_ls_pod_low": true},
+				{"test_be_pod": true, "test_ls_pod_low": true, "test_ls_pod_high": true},
+			},
+		},
+		{
+			name:         "not evict ls pods if the memory is released by evicting be pods",
+			lastResort:   true,
+			memoryUsed:   "85G",
+			lowerPercent: 80,
+			steps:        []time.Duration{0, 10 * time.Minute},
+			expectEvicted: []map[string]bool{
+				{"test_be_pod": true},
+				{"test_be_pod": true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			pods := []*corev1.Pod{
+				createMemoryEvictTestPod("test_be_pod", apiext.QoSBE, 100),
+				createMemoryEvictTestPod("test_ls_pod_high", apiext.QoSLS, 300),
+				createMemoryEvictTestPod("test_ls_pod_low", apiext.QoSLS, 200),
+				createMemoryEvictTestPod("test_ls_pod_critical", apiext.QoSLS, systemCriticalPriority),
+				createMemoryEvictTestPod("test_lsr_pod", apiext.QoSLSR, 0),
+			}
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+				Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse(tt.memoryUsed)},
+				},
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+
+			cfg := NewDefaultConfig()
+			cfg.MemoryEvictLSLastResort = tt.lastResort
+			cfg.MemoryEvictLSSustainedSeconds = 300
+			thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(tt.lowerPercent),
+			}
+			r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+				eventRecorder: &FakeRecorder{}, kubeClient: clientsetfake.NewSimpleClientset(),
+				nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+
+			fakeClock := testingclock.NewFakeClock(time.Now())
+			memoryEvictor := NewMemoryEvictor(r)
+			memoryEvictor.clock = fakeClock
+			for i, step := range tt.steps {
+				fakeClock.Step(step)
+				evictCtx := memoryEvictor.prepareMemoryEvict()
+				assert.NotNil(t, evictCtx)
+				memoryEvictor.killAndEvictBEPods(evictCtx)
+				for _, pod := range pods {
+					_, evicted := r.podsEvicted.Get(string(pod.UID))
+					assert.Equal(t, tt.expectEvicted[i][pod.Name], evicted, "check evicted for pod %s in round %d", pod.Name, i)
+				}
+			}
+		})
+	}
+}
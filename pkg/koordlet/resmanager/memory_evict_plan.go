@@ -0,0 +1,154 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+var (
+	// defaultMemoryEvictionPlanner serves the memory eviction plan of the running memory evictor for the debug http
+	// handler.
+	defaultMemoryEvictionPlanner = &memoryEvictionPlanner{}
+)
+
+// EvictionCandidate is a BE pod which would be evicted in the next memory eviction.
+type EvictionCandidate struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	Priority     *int32 `json:"priority,omitempty"`
+	EvictionCost int32  `json:"evictionCost"`
+	// MemoryUsed is the memory usage (without cache) of the pod in bytes
+	MemoryUsed int64 `json:"memoryUsed"`
+	// ContainerMemoryUsed is the memory usage (without cache) of each container by name in bytes, which is only
+	// retrieved if MemoryEvictByDominantContainer is enabled
+	ContainerMemoryUsed map[string]int64 `json:"containerMemoryUsed,omitempty"`
+	// ProjectedReclaimed is the accumulated memory in bytes reclaimed after evicting the pod and the previous ones
+	ProjectedReclaimed int64 `json:"projectedReclaimed"`
+	// ProjectedNodeMemoryUsed is the node memory usage in bytes after evicting the pod and the previous ones
+	ProjectedNodeMemoryUsed int64  `json:"projectedNodeMemoryUsed"`
+	Reason                  string `json:"reason"`
+}
+
+// PlanMemoryEviction returns the BE pods in the order to be evicted if the memory eviction runs now with the current
+// metrics. It has no side effects, i.e. no pod is marked, killed or evicted, and the evict cooling time is ignored.
+// Since no pod is actually killed in the plan, the node memory usage is projected by subtracting the memory of the
+// candidates, which is what the real eviction does when the measured usage lags behind the kills.
+func (m *MemoryEvictor) PlanMemoryEviction() []EvictionCandidate {
+	evictCtx := m.prepareMemoryEvict()
+	if evictCtx == nil {
+		return nil
+	}
+
+	bePodInfos := m.getSortedPodInfos(evictCtx.podMetrics)
+	reason := fmt.Sprintf("node memory usage %v%% reaches the threshold %v%%, release memory to %v%%",
+		evictCtx.memoryUsed*100/evictCtx.memoryCapacity, evictCtx.thresholdPercent, evictCtx.lowerPercent)
+	if evictCtx.reserveBytes != nil {
+		reason = fmt.Sprintf("node memory free %v is less than the reserve %v, release memory to %v",
+			evictCtx.memoryCapacity-evictCtx.memoryUsed, *evictCtx.reserveBytes, evictCtx.memoryLowerBound())
+	}
+	if m.resManager.config.MemoryEvictSoftEvict {
+		reason += ", soft evicted first"
+	}
+
+	var readyReplicas map[types.UID]int
+	if m.resManager.config.MemoryEvictSkipLastReplica {
+		readyReplicas = m.countReadyReplicasByOwner()
+	}
+
+	candidates := make([]EvictionCandidate, 0)
+	memoryReleased := int64(0)
+	for _, bePod := range bePodInfos {
+		if evictCtx.isMemoryReleased(evictCtx.memoryUsed - memoryReleased) {
+			break
+		}
+		if readyReplicas != nil && isLastReadyReplica(bePod.pod, readyReplicas) {
+			continue
+		}
+		if ownerUID, ok := getReplicaOwnerUID(bePod.pod); ok && readyReplicas != nil && isPodReady(bePod.pod) {
+			readyReplicas[ownerUID]--
+		}
+
+		var podMemoryUsed int64
+		if bePod.podMetric != nil {
+			podMemoryUsed = bePod.podMetric.MemoryUsed.MemoryWithoutCache.Value()
+		}
+		memoryReleased += podMemoryUsed
+		cost, _ := extension.GetPodEvictionCost(bePod.pod)
+		candidates = append(candidates, EvictionCandidate{
+			Namespace:               bePod.pod.Namespace,
+			Name:                    bePod.pod.Name,
+			Priority:                bePod.pod.Spec.Priority,
+			EvictionCost:            cost,
+			MemoryUsed:              podMemoryUsed,
+			ContainerMemoryUsed:     bePod.containerMemoryUsed,
+			ProjectedReclaimed:      memoryReleased,
+			ProjectedNodeMemoryUsed: evictCtx.memoryUsed - memoryReleased,
+			Reason:                  reason,
+		})
+	}
+	return candidates
+}
+
+type memoryEvictionPlanner struct {
+	lock    sync.RWMutex
+	evictor *MemoryEvictor
+}
+
+func (p *memoryEvictionPlanner) set(evictor *MemoryEvictor) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.evictor = evictor
+}
+
+func (p *memoryEvictionPlanner) get() *MemoryEvictor {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.evictor
+}
+
+// MemoryEvictionPlanHttpHandler returns the http handler to dump the memory eviction plan of the node.
+func MemoryEvictionPlanHttpHandler() func(http.ResponseWriter, *http.Request) {
+	return defaultMemoryEvictionPlanner.httpHandler()
+}
+
+func (p *memoryEvictionPlanner) httpHandler() func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		evictor := p.get()
+		if evictor == nil {
+			http.Error(rw, "memory evictor is not running", http.StatusServiceUnavailable)
+			return
+		}
+		data, err := json.Marshal(evictor.PlanMemoryEviction())
+		if err != nil {
+			http.Error(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		if _, err = rw.Write(data); err != nil {
+			klog.Warningf("failed to write memory eviction plan to client %v, error %v", r.RemoteAddr, err)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/runtime/handler"
+	"github.com/koordinator-sh/koordinator/pkg/tools/cache"
+)
+
+func Test_PlanMemoryEviction(t *testing.T) {
+	pods := []*corev1.Pod{
+		createMemoryEvictTestPod("test_ls_pod", apiext.QoSLS, 500),
+		createMemoryEvictTestPod("test_be_pod_priority100_1", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_priority100_2", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_priority120", apiext.QoSBE, 120),
+		createMemoryEvictTestPod("test_be_pod_priority150", apiext.QoSBE, 150),
+	}
+	pods[3].Annotations = map[string]string{apiext.AnnotationPodEvictionCost: "10"}
+	podMetrics := []*metriccache.PodResourceMetric{
+		createPodResourceMetric("test_ls_pod", "40G"),
+		createPodResourceMetric("test_be_pod_priority100_1", "5G"),
+		createPodResourceMetric("test_be_pod_priority100_2", "20G"),
+		createPodResourceMetric("test_be_pod_priority120", "10G"),
+		createPodResourceMetric("test_be_pod_priority150", "10G"),
+	}
+	tests := []struct {
+		name            string
+		nodeMemoryUsed  string
+		thresholdConfig *slov1alpha1.ResourceThresholdStrategy
+		want            []EvictionCandidate
+	}{
+		{
+			name:           "no candidate when memory usage is below the threshold",
+			nodeMemoryUsed: "70G",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+			},
+			want: nil,
+		},
+		{
+			name:           "plan candidates until the lower percent",
+			nodeMemoryUsed: "95G",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(65),
+			},
+			want: []EvictionCandidate{
+				{
+					Namespace:               "",
+					Name:                    "test_be_pod_priority100_2",
+					Priority:                pointer.Int32Ptr(100),
+					MemoryUsed:              20 * 1000 * 1000 * 1000,
+					ProjectedReclaimed:      20 * 1000 * 1000 * 1000,
+					ProjectedNodeMemoryUsed: 75 * 1000 * 1000 * 1000,
+					Reason:                  "node memory usage 95% reaches the threshold 80%, release memory to 65%",
+				},
+				{
+					Namespace:               "",
+					Name:                    "test_be_pod_priority100_1",
+					Priority:                pointer.Int32Ptr(100),
+					MemoryUsed:              5 * 1000 * 1000 * 1000,
+					ProjectedReclaimed:      25 * 1000 * 1000 * 1000,
+					ProjectedNodeMemoryUsed: 70 * 1000 * 1000 * 1000,
+					Reason:                  "node memory usage 95% reaches the threshold 80%, release memory to 65%",
+				},
+				{
+					Namespace:               "",
+					Name:                    "test_be_pod_priority120",
+					Priority:                pointer.Int32Ptr(120),
+					EvictionCost:            10,
+					MemoryUsed:              10 * 1000 * 1000 * 1000,
+					ProjectedReclaimed:      35 * 1000 * 1000 * 1000,
+					ProjectedNodeMemoryUsed: 60 * 1000 * 1000 * 1000,
+					Reason:                  "node memory usage 95% reaches the threshold 80%, release memory to 65%",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockNodeQueryResult := metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+				MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse(tt.nodeMemoryUsed)},
+			}}
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(mockNodeQueryResult).AnyTimes()
+			for _, podMetric := range podMetrics {
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: podMetric}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podMetric.PodUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(), eventRecorder: &FakeRecorder{},
+				metricCache: mockMetricCache, kubeClient: client, nodeSLO: getNodeSLOByThreshold(tt.thresholdConfig),
+				config: NewDefaultConfig()}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			for _, pod := range pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+
+			memoryEvictor := NewMemoryEvictor(r)
+			got := memoryEvictor.PlanMemoryEviction()
+			assert.Equal(t, tt.want, got)
+
+			// the plan has no side effects
+			for _, action := range client.Actions() {
+				assert.NotEqual(t, "eviction", action.GetSubresource(), "plan should not evict pods")
+			}
+			for _, pod := range pods {
+				_, evicted := r.podsEvicted.Get(string(pod.UID))
+				assert.False(t, evicted, "plan should not evict pod %s", pod.Name)
+			}
+
+			// the plan matches the real eviction with the stale node metric
+			memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+			memoryEvictor.memoryEvict()
+			var evictedNames []string
+			for _, action := range client.Actions() {
+				if createAction, ok := action.(k8stesting.CreateAction); ok && action.GetSubresource() == "eviction" {
+					evictedNames = append(evictedNames, createAction.GetObject().(metav1.Object).GetName())
+				}
+			}
+			var plannedNames []string
+			for _, candidate := range got {
+				plannedNames = append(plannedNames, candidate.Name)
+			}
+			assert.Equal(t, plannedNames, evictedNames)
+		})
+	}
+}
+
+func Test_memoryEvictionPlanner_httpHandler(t *testing.T) {
+	planner := &memoryEvictionPlanner{}
+	recorder := httptest.NewRecorder()
+	planner.httpHandler()(recorder, httptest.NewRequest(http.MethodGet, "/debug/memoryevictplan", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	pod := createMemoryEvictTestPod("test_be_pod", apiext.QoSBE, 100)
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas([]*corev1.Pod{pod})).AnyTimes()
+	mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("90G")},
+		},
+	}).AnyTimes()
+	mockMetricCache.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{
+		Metric: createPodResourceMetric(string(pod.UID), "20G"),
+	}).AnyTimes()
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, config: NewDefaultConfig(),
+		nodeSLO: getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+			Enable:                      pointer.BoolPtr(true),
+			MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+		})}
+	planner.set(NewMemoryEvictor(r))
+
+	recorder = httptest.NewRecorder()
+	planner.httpHandler()(recorder, httptest.NewRequest(http.MethodGet, "/debug/memoryevictplan", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var gotCandidates []EvictionCandidate
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &gotCandidates))
+	assert.Equal(t, 1, len(gotCandidates))
+	assert.Equal(t, "test_be_pod", gotCandidates[0].Name)
+	assert.Equal(t, int64(70*1000*1000*1000), gotCandidates[0].ProjectedNodeMemoryUsed)
+}
@@ -23,25 +23,32 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 	critesting "k8s.io/cri-api/pkg/apis/testing"
+	testingclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/runtime"
 	"github.com/koordinator-sh/koordinator/pkg/runtime/handler"
 	"github.com/koordinator-sh/koordinator/pkg/tools/cache"
 	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
 )
 
 func Test_memoryEvict(t *testing.T) {
@@ -293,6 +300,1697 @@ func Test_memoryEvict(t *testing.T) {
 	}
 }
 
+func Test_memoryEvict_releaseToLowerPercent(t *testing.T) {
+	tests := []struct {
+		name               string
+		thresholdConfig    *slov1alpha1.ResourceThresholdStrategy
+		staleNodeMetric    bool
+		expectEvictedCount int
+	}{
+		{
+			name: "release until usage under lower percent",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(50),
+			},
+			expectEvictedCount: 4, // 85G -> 75G -> 65G -> 55G -> 45G
+		},
+		{
+			name: "release until usage under lower percent with stale node metric",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(50),
+			},
+			staleNodeMetric:    true,
+			expectEvictedCount: 4,
+		},
+		{
+			name: "release with buffer when lower percent not set",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+			},
+			expectEvictedCount: 1, // 85G -> 75G
+		},
+		{
+			name: "release with buffer when lower percent is invalid",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(90),
+			},
+			expectEvictedCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			// BE pods with increasing priorities, each of which uses 10G memory
+			var pods []*corev1.Pod
+			for i := 0; i < 6; i++ {
+				pod := createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i))
+				pods = append(pods, pod)
+			}
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+			fakeRecorder := &FakeRecorder{}
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(), eventRecorder: fakeRecorder,
+				kubeClient: client, nodeSLO: getNodeSLOByThreshold(tt.thresholdConfig), config: NewDefaultConfig()}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			// simulated usage model: node memory usage decreases by 10G for each evicted pod
+			evictedCount := func() int {
+				count := 0
+				for _, pod := range pods {
+					if _, evicted := r.podsEvicted.Get(string(pod.UID)); evicted {
+						count++
+					}
+				}
+				return count
+			}
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+				usedGB := int64(85)
+				if !tt.staleNodeMetric {
+					usedGB -= int64(10 * evictedCount())
+				}
+				return metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(usedGB*1000*1000*1000, resource.DecimalSI)},
+				}}
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+			r.metricCache = mockMetricCache
+
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			for _, pod := range pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+
+			memoryEvictor := NewMemoryEvictor(r)
+			memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+			memoryEvictor.memoryEvict()
+
+			assert.Equal(t, tt.expectEvictedCount, evictedCount())
+			// pods with lower priorities are evicted first
+			for i, pod := range pods {
+				_, evicted := r.podsEvicted.Get(string(pod.UID))
+				assert.Equal(t, i < tt.expectEvictedCount, evicted, "check evicted for pod %s", pod.Name)
+			}
+		})
+	}
+}
+
+func Test_memoryEvict_reserveBytes(t *testing.T) {
+	tests := []struct {
+		name               string
+		thresholdConfig    *slov1alpha1.ResourceThresholdStrategy
+		expectEvictedCount int
+	}{
+		{
+			name: "release until the reserve is free",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                  pointer.BoolPtr(true),
+				MemoryEvictReserveBytes: resource.NewQuantity(40*1000*1000*1000, resource.DecimalSI),
+			},
+			expectEvictedCount: 3, // free 15G -> 25G -> 35G -> 45G
+		},
+		{
+			name: "stop once the reserve is exactly free",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                  pointer.BoolPtr(true),
+				MemoryEvictReserveBytes: resource.NewQuantity(35*1000*1000*1000, resource.DecimalSI),
+			},
+			expectEvictedCount: 2, // free 15G -> 25G -> 35G
+		},
+		{
+			name: "no eviction if the reserve is free",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                  pointer.BoolPtr(true),
+				MemoryEvictReserveBytes: resource.NewQuantity(10*1000*1000*1000, resource.DecimalSI),
+			},
+			expectEvictedCount: 0,
+		},
+		{
+			name: "reserve bytes overrides the merged threshold percent",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(90),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(80),
+				MemoryEvictReserveBytes:     resource.NewQuantity(40*1000*1000*1000, resource.DecimalSI),
+			},
+			expectEvictedCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			// BE pods with increasing priorities, each of which uses 10G memory
+			var pods []*corev1.Pod
+			for i := 0; i < 6; i++ {
+				pod := createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i))
+				pods = append(pods, pod)
+			}
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+			fakeRecorder := &FakeRecorder{}
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(), eventRecorder: fakeRecorder,
+				kubeClient: client, nodeSLO: getNodeSLOByThreshold(tt.thresholdConfig), config: NewDefaultConfig()}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			// simulated usage model: node memory usage is 85G and decreases by 10G for each evicted pod
+			evictedCount := func() int {
+				count := 0
+				for _, pod := range pods {
+					if _, evicted := r.podsEvicted.Get(string(pod.UID)); evicted {
+						count++
+					}
+				}
+				return count
+			}
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+				usedGB := 85 - int64(10*evictedCount())
+				return metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(usedGB*1000*1000*1000, resource.DecimalSI)},
+				}}
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+			r.metricCache = mockMetricCache
+
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			for _, pod := range pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+
+			memoryEvictor := NewMemoryEvictor(r)
+			memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+			memoryEvictor.memoryEvict()
+
+			assert.Equal(t, tt.expectEvictedCount, evictedCount())
+			for i, pod := range pods {
+				_, evicted := r.podsEvicted.Get(string(pod.UID))
+				assert.Equal(t, i < tt.expectEvictedCount, evicted, "check evicted for pod %s", pod.Name)
+			}
+		})
+	}
+}
+
+func Test_prepareMemoryEvict_metricAggregation(t *testing.T) {
+	// the node memory usage in GB of a spiky window aggregated by each type
+	windowMemoryUsedGB := map[metriccache.AggregationType]int64{
+		metriccache.AggregationTypeAVG:  70,
+		metriccache.AggregationTypeP95:  85,
+		metriccache.AggregationTypeMax:  90,
+		metriccache.AggregationTypeLast: 75,
+	}
+	tests := []struct {
+		name             string
+		aggregation      slov1alpha1.MetricAggregation
+		expectMemoryUsed int64
+	}{
+		{
+			name:        "no eviction with the average by default",
+			aggregation: "",
+		},
+		{
+			name:        "no eviction with the last",
+			aggregation: slov1alpha1.MetricAggregationLast,
+		},
+		{
+			name:             "evict with the p95",
+			aggregation:      slov1alpha1.MetricAggregationP95,
+			expectMemoryUsed: 85 * 1000 * 1000 * 1000,
+		},
+		{
+			name:             "evict with the max",
+			aggregation:      slov1alpha1.MetricAggregationMax,
+			expectMemoryUsed: 90 * 1000 * 1000 * 1000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			pod := createMemoryEvictTestPod("test_be_pod", apiext.QoSBE, 100)
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas([]*corev1.Pod{pod})).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+				usedGB := windowMemoryUsedGB[param.Aggregate]
+				return metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(usedGB*1000*1000*1000, resource.DecimalSI)},
+				}}
+			}).AnyTimes()
+			mockMetricCache.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{
+				Metric: createPodResourceMetric(string(pod.UID), "10G"),
+			}).AnyTimes()
+
+			thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                         pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent:    pointer.Int64Ptr(80),
+				MemoryEvictMetricWindowSeconds: pointer.Int64Ptr(60),
+				MemoryEvictMetricAggregation:   tt.aggregation,
+			}
+			r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache,
+				nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: NewDefaultConfig()}
+			evictCtx := NewMemoryEvictor(r).prepareMemoryEvict()
+			if tt.expectMemoryUsed == 0 {
+				assert.Nil(t, evictCtx)
+				return
+			}
+			assert.NotNil(t, evictCtx)
+			assert.Equal(t, tt.expectMemoryUsed, evictCtx.memoryUsed)
+		})
+	}
+}
+
+func Test_memoryEvict_shadowThreshold(t *testing.T) {
+	tests := []struct {
+		name               string
+		thresholdConfig    *slov1alpha1.ResourceThresholdStrategy
+		shadowConfig       *slov1alpha1.ResourceThresholdStrategy
+		expectEvictedCount int
+		expectActive       float64
+		expectShadow       float64
+	}{
+		{
+			name: "shadow threshold triggers but never evicts",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(90),
+			},
+			shadowConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                  pointer.BoolPtr(true),
+				MemoryEvictReserveBytes: resource.NewQuantity(40*1000*1000*1000, resource.DecimalSI),
+			},
+			expectEvictedCount: 0,
+			expectActive:       0,
+			expectShadow:       25 * 1000 * 1000 * 1000,
+		},
+		{
+			name: "active threshold evicts while shadow threshold does not trigger",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                  pointer.BoolPtr(true),
+				MemoryEvictReserveBytes: resource.NewQuantity(40*1000*1000*1000, resource.DecimalSI),
+			},
+			shadowConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(90),
+			},
+			expectEvictedCount: 3,
+			expectActive:       25 * 1000 * 1000 * 1000,
+			expectShadow:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			// BE pods with increasing priorities, each of which uses 10G memory
+			var pods []*corev1.Pod
+			for i := 0; i < 6; i++ {
+				pod := createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i))
+				pods = append(pods, pod)
+			}
+			testingNode := getNode("80", "100G")
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(testingNode).AnyTimes()
+
+			nodeSLO := getNodeSLOByThreshold(tt.thresholdConfig)
+			nodeSLO.Spec.ShadowResourceUsedThresholdWithBE = tt.shadowConfig
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(), eventRecorder: &FakeRecorder{},
+				kubeClient: client, nodeSLO: nodeSLO, config: NewDefaultConfig()}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			// simulated usage model: node memory usage is 85G and decreases by 10G for each evicted pod
+			evictedCount := func() int {
+				count := 0
+				for _, pod := range pods {
+					if _, evicted := r.podsEvicted.Get(string(pod.UID)); evicted {
+						count++
+					}
+				}
+				return count
+			}
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+				usedGB := 85 - int64(10*evictedCount())
+				return metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(usedGB*1000*1000*1000, resource.DecimalSI)},
+				}}
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+			r.metricCache = mockMetricCache
+
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			for _, pod := range pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+
+			metrics.Register(testingNode)
+			defer metrics.Register(nil)
+			metrics.ThresholdStrategyDecision.Reset()
+
+			memoryEvictor := NewMemoryEvictor(r)
+			memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+			memoryEvictor.memoryEvict()
+
+			assert.Equal(t, tt.expectEvictedCount, evictedCount())
+			assert.Equal(t, tt.expectActive, testutil.ToFloat64(metrics.ThresholdStrategyDecision.WithLabelValues(
+				testingNode.Name, string(features.BEMemoryEvict), metrics.ThresholdStrategyActive)))
+			assert.Equal(t, tt.expectShadow, testutil.ToFloat64(metrics.ThresholdStrategyDecision.WithLabelValues(
+				testingNode.Name, string(features.BEMemoryEvict), metrics.ThresholdStrategyShadow)))
+		})
+	}
+}
+
+func Test_getMemoryToRelease(t *testing.T) {
+	const gb = 1000 * 1000 * 1000
+	tests := []struct {
+		name            string
+		thresholdConfig *slov1alpha1.ResourceThresholdStrategy
+		memoryUsed      int64
+		want            int64
+	}{
+		{
+			name:            "below threshold percent",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{MemoryEvictThresholdPercent: pointer.Int64Ptr(70)},
+			memoryUsed:      60 * gb,
+			want:            0,
+		},
+		{
+			name:            "release to the default lower percent",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{MemoryEvictThresholdPercent: pointer.Int64Ptr(70)},
+			memoryUsed:      80 * gb,
+			want:            12 * gb,
+		},
+		{
+			name: "release to the lower percent",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(70),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(50),
+			},
+			memoryUsed: 80 * gb,
+			want:       30 * gb,
+		},
+		{
+			name:            "release until the reserve is free",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{MemoryEvictReserveBytes: resource.NewQuantity(30*gb, resource.DecimalSI)},
+			memoryUsed:      80 * gb,
+			want:            10 * gb,
+		},
+		{
+			name:            "reserve is free",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{MemoryEvictReserveBytes: resource.NewQuantity(30*gb, resource.DecimalSI)},
+			memoryUsed:      70 * gb,
+			want:            0,
+		},
+		{
+			name:            "no threshold",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{},
+			memoryUsed:      100 * gb,
+			want:            0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getMemoryToRelease(tt.thresholdConfig, 100*gb, tt.memoryUsed))
+		})
+	}
+}
+
+func Test_memoryEvict_oomKills(t *testing.T) {
+	tests := []struct {
+		name               string
+		oomKillThreshold   int
+		oomKills           []string
+		expectEvictedCount int
+	}{
+		{
+			name:               "evict a be pod when oom kills increase",
+			oomKillThreshold:   1,
+			oomKills:           []string{"5", "7"},
+			expectEvictedCount: 1,
+		},
+		{
+			name:               "take the baseline at the first read",
+			oomKillThreshold:   1,
+			oomKills:           []string{"5"},
+			expectEvictedCount: 0,
+		},
+		{
+			name:               "skip when oom kills do not increase",
+			oomKillThreshold:   1,
+			oomKills:           []string{"5", "5"},
+			expectEvictedCount: 0,
+		},
+		{
+			name:               "skip when oom kills increase below threshold",
+			oomKillThreshold:   3,
+			oomKills:           []string{"5", "7"},
+			expectEvictedCount: 0,
+		},
+		{
+			name:               "reset the baseline when oom kills decrease",
+			oomKillThreshold:   1,
+			oomKills:           []string{"5", "0"},
+			expectEvictedCount: 0,
+		},
+		{
+			name:               "skip when threshold is disabled",
+			oomKillThreshold:   0,
+			oomKills:           []string{"5", "7"},
+			expectEvictedCount: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+
+			// BE pods with increasing priorities, each of which uses 10G memory
+			var pods []*corev1.Pod
+			for i := 0; i < 3; i++ {
+				pod := createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i))
+				pods = append(pods, pod)
+			}
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+			// the node memory usage is below threshold
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+				Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("50G")},
+				},
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+
+			thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+			}
+			cfg := NewDefaultConfig()
+			cfg.MemoryEvictOOMKillThreshold = tt.oomKillThreshold
+			fakeRecorder := &FakeRecorder{}
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+				eventRecorder: fakeRecorder, kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			for _, pod := range pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+
+			memoryEvictor := NewMemoryEvictor(r)
+			beCgroupDir := util.GetKubeQosRelativePath(corev1.PodQOSBestEffort)
+			for _, oomKills := range tt.oomKills {
+				helper.WriteCgroupFileContents(beCgroupDir, system.MemOomControl, fmt.Sprintf("oom_kill_disable 0\nunder_oom 0\noom_kill %s\n", oomKills))
+				memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+				memoryEvictor.memoryEvict()
+			}
+
+			// pods with lower priorities are evicted first
+			for i, pod := range pods {
+				_, evicted := r.podsEvicted.Get(string(pod.UID))
+				assert.Equal(t, i < tt.expectEvictedCount, evicted, "check evicted for pod %s", pod.Name)
+			}
+		})
+	}
+}
+
+func Test_memoryEvict_nodePressureCondition(t *testing.T) {
+	tests := []struct {
+		name               string
+		evictOnCondition   bool
+		conditionStatus    corev1.ConditionStatus
+		expectEvictedCount int
+	}{
+		{
+			name:               "evict a be pod when the node reports memory pressure",
+			evictOnCondition:   true,
+			conditionStatus:    corev1.ConditionTrue,
+			expectEvictedCount: 1,
+		},
+		{
+			name:               "skip when the node reports no memory pressure",
+			evictOnCondition:   true,
+			conditionStatus:    corev1.ConditionFalse,
+			expectEvictedCount: 0,
+		},
+		{
+			name:               "skip when evict on node pressure condition is disabled",
+			evictOnCondition:   false,
+			conditionStatus:    corev1.ConditionTrue,
+			expectEvictedCount: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			// BE pods with increasing priorities, each of which uses 10G memory
+			var pods []*corev1.Pod
+			for i := 0; i < 3; i++ {
+				pod := createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i))
+				pods = append(pods, pod)
+			}
+
+			node := getNode("80", "100G")
+			node.Status.Conditions = []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: tt.conditionStatus},
+			}
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(node).AnyTimes()
+
+			// the node memory usage is below threshold
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+				Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("50G")},
+				},
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+
+			thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+			}
+			cfg := NewDefaultConfig()
+			cfg.EvictOnNodePressureCondition = tt.evictOnCondition
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+				eventRecorder: &FakeRecorder{}, kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			for _, pod := range pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+
+			memoryEvictor := NewMemoryEvictor(r)
+			memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+			memoryEvictor.memoryEvict()
+
+			// pods with lower priorities are evicted first
+			for i, pod := range pods {
+				_, evicted := r.podsEvicted.Get(string(pod.UID))
+				assert.Equal(t, i < tt.expectEvictedCount, evicted, "check evicted for pod %s", pod.Name)
+			}
+		})
+	}
+}
+
+func Test_memoryEvict_markMemoryPressure(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// BE pods each of which uses 10G memory
+	var pods []*corev1.Pod
+	for i := 0; i < 3; i++ {
+		pods = append(pods, createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i)))
+	}
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+	nodeMemoryUsed := "90G"
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(
+		func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+			return metriccache.NodeResourceQueryResult{
+				Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse(nodeMemoryUsed)},
+				},
+			}
+		}).AnyTimes()
+	for _, pod := range pods {
+		podUID := string(pod.UID)
+		mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+		mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+	}
+
+	thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+	}
+	cfg := NewDefaultConfig()
+	cfg.CPUBurstBackOffOnMemoryEvict = true
+	client := clientsetfake.NewSimpleClientset()
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+		eventRecorder: &FakeRecorder{}, kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg,
+		pressureState: newNodePressureState(cfg)}
+	stop := make(chan struct{})
+	_ = r.podsEvicted.Run(stop)
+	defer func() { stop <- struct{}{} }()
+
+	runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	// the memory pressure is active while evicting beyond the threshold
+	memoryEvictor := NewMemoryEvictor(r)
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+	assert.True(t, r.pressureState.isMemoryPressureActive())
+
+	// the memory pressure is kept in the evict cooling time
+	memoryEvictor.memoryEvict()
+	assert.True(t, r.pressureState.isMemoryPressureActive())
+
+	// the memory pressure is cleared once the usage drops below the threshold
+	nodeMemoryUsed = "50G"
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+	assert.False(t, r.pressureState.isMemoryPressureActive())
+}
+
+func Test_memoryEvict_skipTerminatingPod(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// BE pods with increasing priorities, each of which uses 10G memory, where the lowest one is terminating
+	var pods []*corev1.Pod
+	for i := 0; i < 3; i++ {
+		pods = append(pods, createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i)))
+	}
+	pods[0].DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+	// the node memory usage is beyond threshold, which needs to release one pod
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("85G")},
+		},
+	}).AnyTimes()
+	for _, pod := range pods {
+		podUID := string(pod.UID)
+		mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+		mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+	}
+
+	thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+	}
+	client := clientsetfake.NewSimpleClientset()
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+		eventRecorder: &FakeRecorder{}, kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig),
+		config: NewDefaultConfig()}
+	stop := make(chan struct{})
+	_ = r.podsEvicted.Run(stop)
+	defer func() { stop <- struct{}{} }()
+
+	runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	memoryEvictor := NewMemoryEvictor(r)
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+
+	// the terminating pod is not a candidate, so the next one in the order is evicted
+	for i, pod := range pods {
+		_, evicted := r.podsEvicted.Get(string(pod.UID))
+		assert.Equal(t, i == 1, evicted, "check evicted for pod %s", pod.Name)
+	}
+}
+
+func Test_memoryEvict_softEvict(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// BE pods with increasing priorities, each of which uses 10G memory
+	var pods []*corev1.Pod
+	for i := 0; i < 6; i++ {
+		pod := createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i))
+		pods = append(pods, pod)
+	}
+
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+	cfg := NewDefaultConfig()
+	cfg.MemoryEvictSoftEvict = true
+	cfg.MemoryEvictSoftEvictGraceSeconds = 30
+	thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+		MemoryEvictLowerPercent:     pointer.Int64Ptr(70),
+	}
+	client := clientsetfake.NewSimpleClientset()
+	r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(), eventRecorder: &FakeRecorder{},
+		kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+	stop := make(chan struct{})
+	_ = r.podsEvicted.Run(stop)
+	defer func() { stop <- struct{}{} }()
+
+	// simulated usage model: node memory usage decreases by 10G for each evicted pod
+	isEvicted := func(pod *corev1.Pod) bool {
+		_, evicted := r.podsEvicted.Get(string(pod.UID))
+		return evicted
+	}
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+		usedGB := int64(85)
+		for _, pod := range pods {
+			if isEvicted(pod) {
+				usedGB -= 10
+			}
+		}
+		return metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(usedGB*1000*1000*1000, resource.DecimalSI)},
+		}}
+	}).AnyTimes()
+	for _, pod := range pods {
+		podUID := string(pod.UID)
+		mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+		mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+	}
+	r.metricCache = mockMetricCache
+
+	runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	memoryEvictor := NewMemoryEvictor(r)
+	memoryEvictor.clock = fakeClock
+
+	steps := []struct {
+		name         string
+		elapsed      time.Duration
+		expectMarked int
+		expectEvict  int
+	}{
+		{
+			name:         "mark the pods to release at first",
+			elapsed:      0,
+			expectMarked: 2,
+			expectEvict:  0,
+		},
+		{
+			name:         "wait for the marked pods before the deadline",
+			elapsed:      10 * time.Second,
+			expectMarked: 2,
+			expectEvict:  0,
+		},
+		{
+			name:         "evict the marked pods after the deadline",
+			elapsed:      25 * time.Second,
+			expectMarked: 2,
+			expectEvict:  2,
+		},
+	}
+	for _, step := range steps {
+		fakeClock.Step(step.elapsed)
+		memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+		memoryEvictor.memoryEvict()
+
+		for i, pod := range pods {
+			// pods with lower priorities are chosen first
+			if i < step.expectEvict {
+				assert.True(t, isEvicted(pod), "%s: check evicted for pod %s", step.name, pod.Name)
+				continue
+			}
+			got, err := client.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.False(t, isEvicted(pod), "%s: check evicted for pod %s", step.name, pod.Name)
+			_, marked := got.Annotations[apiext.AnnotationPodSoftEvictDeadline]
+			assert.Equal(t, i < step.expectMarked, marked, "%s: check marked for pod %s", step.name, pod.Name)
+		}
+	}
+}
+
+func Test_memoryEvict_softEvictClearMarks(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// BE pods with increasing priorities, each of which uses 10G memory
+	var pods []*corev1.Pod
+	for i := 0; i < 4; i++ {
+		pods = append(pods, createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i)))
+	}
+	candidatePods := pods
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().DoAndReturn(func() []*statesinformer.PodMeta {
+		return getPodMetas(candidatePods)
+	}).AnyTimes()
+	mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+	usedGB := int64(85)
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+		return metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(usedGB*1000*1000*1000, resource.DecimalSI)},
+		}}
+	}).AnyTimes()
+	for _, pod := range pods {
+		podUID := string(pod.UID)
+		mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+		mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.MemoryEvictSoftEvict = true
+	cfg.MemoryEvictSoftEvictGraceSeconds = 30
+	thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+		MemoryEvictLowerPercent:     pointer.Int64Ptr(70),
+	}
+	client := clientsetfake.NewSimpleClientset()
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+		eventRecorder: &FakeRecorder{}, kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	memoryEvictor := NewMemoryEvictor(r)
+	memoryEvictor.clock = testingclock.NewFakeClock(time.Now())
+	getMarkedPods := func() []string {
+		var marked []string
+		for _, pod := range pods {
+			got, err := client.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			if _, ok := got.Annotations[apiext.AnnotationPodSoftEvictDeadline]; ok {
+				marked = append(marked, pod.Name)
+			}
+		}
+		return marked
+	}
+
+	// mark the pods to release at first
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+	assert.Equal(t, []string{"test_be_pod_0", "test_be_pod_1"}, getMarkedPods())
+
+	// the pod no longer a candidate is unmarked, and the next one is marked instead
+	candidatePods = pods[1:]
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+	assert.Equal(t, []string{"test_be_pod_1", "test_be_pod_2"}, getMarkedPods())
+
+	// all marks are cleared once the memory pressure ends
+	usedGB = 50
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+	assert.Empty(t, getMarkedPods())
+	assert.Empty(t, memoryEvictor.softEvictDeadlines)
+}
+
+func Test_memoryEvict_skipLastReplica(t *testing.T) {
+	newReadyReplicaPod := func(name string, priority int32, ownerKind string, ownerUID types.UID) *corev1.Pod {
+		pod := createMemoryEvictTestPod(name, apiext.QoSBE, priority)
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		if ownerKind != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: ownerKind, Name: string(ownerUID), UID: ownerUID, Controller: pointer.BoolPtr(true)},
+			}
+		}
+		return pod
+	}
+	tests := []struct {
+		name             string
+		skipLastReplica  bool
+		expectEvictedPod map[string]bool
+	}{
+		{
+			name:            "evict pods by priority without the last replica protection",
+			skipLastReplica: false,
+			expectEvictedPod: map[string]bool{
+				"test_be_rs_pod_0":   true,
+				"test_be_rs_pod_1":   true,
+				"test_be_sts_pod_0":  true,
+				"test_be_bare_pod_0": true,
+			},
+		},
+		{
+			name:            "skip the last ready replicas of the owners",
+			skipLastReplica: true,
+			expectEvictedPod: map[string]bool{
+				"test_be_rs_pod_0":   true,
+				"test_be_rs_pod_1":   false,
+				"test_be_sts_pod_0":  false,
+				"test_be_bare_pod_0": true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			pods := []*corev1.Pod{
+				newReadyReplicaPod("test_be_rs_pod_0", 100, "ReplicaSet", "test-rs"),
+				newReadyReplicaPod("test_be_rs_pod_1", 101, "ReplicaSet", "test-rs"),
+				newReadyReplicaPod("test_be_sts_pod_0", 102, "StatefulSet", "test-sts"),
+				newReadyReplicaPod("test_be_bare_pod_0", 103, "", ""),
+			}
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+			cfg := NewDefaultConfig()
+			cfg.MemoryEvictSkipLastReplica = tt.skipLastReplica
+			thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+				MemoryEvictLowerPercent:     pointer.Int64Ptr(30),
+			}
+			r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(), eventRecorder: &FakeRecorder{},
+				kubeClient: clientsetfake.NewSimpleClientset(), nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			isEvicted := func(pod *corev1.Pod) bool {
+				_, evicted := r.podsEvicted.Get(string(pod.UID))
+				return evicted
+			}
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+				usedGB := int64(85)
+				for _, pod := range pods {
+					if isEvicted(pod) {
+						usedGB -= 10
+					}
+				}
+				return metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(usedGB*1000*1000*1000, resource.DecimalSI)},
+				}}
+			}).AnyTimes()
+			for _, pod := range pods {
+				podUID := string(pod.UID)
+				mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+				mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+			}
+			r.metricCache = mockMetricCache
+
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			memoryEvictor := NewMemoryEvictor(r)
+			memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+			memoryEvictor.memoryEvict()
+
+			for _, pod := range pods {
+				assert.Equal(t, tt.expectEvictedPod[pod.Name], isEvicted(pod), "check evicted for pod %s", pod.Name)
+			}
+		})
+	}
+}
+
+func Test_killAndEvictBEPod_killContainersStrict(t *testing.T) {
+	tests := []struct {
+		name        string
+		strict      bool
+		wantResult  podKillResult
+		wantEvicted bool
+	}{
+		{
+			name:        "evict the pod when runtime handler is unavailable in non-strict mode",
+			strict:      false,
+			wantResult:  podKilled,
+			wantEvicted: true,
+		},
+		{
+			name:        "skip evicting the pod when runtime handler is unavailable in strict mode",
+			strict:      true,
+			wantResult:  podKillFailed,
+			wantEvicted: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := getNode("80", "120G")
+			pod := createMemoryEvictTestPod("test_be_pod", apiext.QoSBE, 0)
+			pod.Status.ContainerStatuses[0].ContainerID = "cri-o://test_be_pod_main"
+
+			client := clientsetfake.NewSimpleClientset()
+			_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			assert.NoError(t, err)
+			config := NewDefaultConfig()
+			config.KillContainersStrict = tt.strict
+			r := &resmanager{kubeClient: client, eventRecorder: &FakeRecorder{}, podsEvicted: cache.NewCacheDefault(), config: config}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+
+			memoryEvictor := NewMemoryEvictor(r)
+			gotResult := memoryEvictor.killAndEvictBEPod(node, pod, "test evict")
+			assert.Equal(t, tt.wantResult, gotResult)
+			_, gotEvicted := r.podsEvicted.Get(string(pod.UID))
+			assert.Equal(t, tt.wantEvicted, gotEvicted)
+		})
+	}
+}
+
+func Test_killAndEvictBEPods_killContainersStrict(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// BE pods with increasing priorities, each of which uses 10G memory, where the lowest one fails to kill
+	var pods []*corev1.Pod
+	for i := 0; i < 3; i++ {
+		pods = append(pods, createMemoryEvictTestPod(fmt.Sprintf("test_be_pod_%d", i), apiext.QoSBE, int32(100+i)))
+	}
+	pods[0].Status.ContainerStatuses[0].ContainerID = "cri-o://test_be_pod_0_main"
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+	// the node memory usage is beyond threshold, which needs to release one pod
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("85G")},
+		},
+	}).AnyTimes()
+	for _, pod := range pods {
+		podUID := string(pod.UID)
+		mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+		mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+	}
+
+	thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+	}
+	cfg := NewDefaultConfig()
+	cfg.KillContainersStrict = true
+	client := clientsetfake.NewSimpleClientset()
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+		eventRecorder: &FakeRecorder{}, kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+	stop := make(chan struct{})
+	_ = r.podsEvicted.Run(stop)
+	defer func() { stop <- struct{}{} }()
+
+	runtime.DockerHandler = newFakeRuntimeHandlerWithPods(pods...)
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	memoryEvictor := NewMemoryEvictor(r)
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+
+	// the memory of the pod failed to kill is not taken as released, so the next one is evicted
+	for i, pod := range pods {
+		_, evicted := r.podsEvicted.Get(string(pod.UID))
+		assert.Equal(t, i == 1, evicted, "check evicted for pod %s", pod.Name)
+	}
+}
+
+func Test_isLastReadyReplica(t *testing.T) {
+	readyCondition := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	replicaOwner := []metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: "test-rs", Controller: pointer.BoolPtr(true)},
+	}
+	tests := []struct {
+		name          string
+		pod           *corev1.Pod
+		readyReplicas map[types.UID]int
+		want          bool
+	}{
+		{
+			name: "pod without owner is not a replica",
+			pod: &corev1.Pod{
+				Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: readyCondition},
+			},
+			readyReplicas: map[types.UID]int{},
+			want:          false,
+		},
+		{
+			name: "daemonset pod is not a replica",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "test-ds", UID: "test-ds", Controller: pointer.BoolPtr(true)},
+				}},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: readyCondition},
+			},
+			readyReplicas: map[types.UID]int{"test-ds": 1},
+			want:          false,
+		},
+		{
+			name: "unready pod is not the last ready replica",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: replicaOwner},
+				Status:     corev1.PodStatus{Phase: corev1.PodPending},
+			},
+			readyReplicas: map[types.UID]int{"test-rs": 1},
+			want:          false,
+		},
+		{
+			name: "ready pod with other ready replicas",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: replicaOwner},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: readyCondition},
+			},
+			readyReplicas: map[types.UID]int{"test-rs": 2},
+			want:          false,
+		},
+		{
+			name: "ready pod is the last ready replica",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: replicaOwner},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: readyCondition},
+			},
+			readyReplicas: map[types.UID]int{"test-rs": 1},
+			want:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isLastReadyReplica(tt.pod, tt.readyReplicas)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_getSortedPodInfos(t *testing.T) {
+	testingMirrorPod := createMemoryEvictTestPod("test_be_mirror_pod", apiext.QoSBE, 100)
+	testingMirrorPod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "mirror"}
+	testingDaemonSetPod := createMemoryEvictTestPod("test_be_daemonset_pod", apiext.QoSBE, 100)
+	testingDaemonSetPod.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "test-daemonset", Controller: pointer.BoolPtr(true)},
+	}
+	testingBEPod := createMemoryEvictTestPod("test_be_pod", apiext.QoSBE, 100)
+	testingLSPod := createMemoryEvictTestPod("test_ls_pod", apiext.QoSLS, 500)
+	pods := []*corev1.Pod{testingMirrorPod, testingDaemonSetPod, testingBEPod, testingLSPod}
+	podMetrics := []*metriccache.PodResourceMetric{
+		createPodResourceMetric("test_be_mirror_pod", "10G"),
+		createPodResourceMetric("test_be_daemonset_pod", "8G"),
+		createPodResourceMetric("test_be_pod", "4G"),
+		createPodResourceMetric("test_ls_pod", "20G"),
+	}
+	tests := []struct {
+		name                 string
+		excludeDaemonSetPods bool
+		want                 []string
+	}{
+		{
+			name:                 "ignore mirror pod",
+			excludeDaemonSetPods: false,
+			want:                 []string{"test_be_daemonset_pod", "test_be_pod"},
+		},
+		{
+			name:                 "ignore mirror pod and DaemonSet pod",
+			excludeDaemonSetPods: true,
+			want:                 []string{"test_be_pod"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+
+			cfg := NewDefaultConfig()
+			cfg.ExcludeDaemonSetPods = tt.excludeDaemonSetPods
+			memoryEvictor := NewMemoryEvictor(&resmanager{statesInformer: mockStatesInformer, config: cfg})
+
+			got := memoryEvictor.getSortedPodInfos(podMetrics)
+			var gotNames []string
+			for _, info := range got {
+				gotNames = append(gotNames, info.pod.Name)
+			}
+			assert.Equal(t, tt.want, gotNames)
+		})
+	}
+}
+
+func Test_getSortedPodInfos_evictionCost(t *testing.T) {
+	withCost := func(pod *corev1.Pod, cost string) *corev1.Pod {
+		pod.Annotations = map[string]string{apiext.AnnotationPodEvictionCost: cost}
+		return pod
+	}
+	pods := []*corev1.Pod{
+		withCost(createMemoryEvictTestPod("test_be_pod_low_prio_high_cost", apiext.QoSBE, 100), "1000"),
+		withCost(createMemoryEvictTestPod("test_be_pod_low_prio_low_cost", apiext.QoSBE, 100), "10"),
+		createMemoryEvictTestPod("test_be_pod_low_prio_no_cost", apiext.QoSBE, 100),
+		withCost(createMemoryEvictTestPod("test_be_pod_low_prio_invalid_cost", apiext.QoSBE, 100), "invalid"),
+		withCost(createMemoryEvictTestPod("test_be_pod_high_prio_negative_cost", apiext.QoSBE, 200), "-10"),
+		withCost(createMemoryEvictTestPod("test_be_pod_high_prio_high_cost", apiext.QoSBE, 200), "1000"),
+	}
+	podMetrics := []*metriccache.PodResourceMetric{
+		createPodResourceMetric("test_be_pod_low_prio_high_cost", "10G"),
+		createPodResourceMetric("test_be_pod_low_prio_low_cost", "4G"),
+		createPodResourceMetric("test_be_pod_low_prio_no_cost", "2G"),
+		createPodResourceMetric("test_be_pod_low_prio_invalid_cost", "6G"),
+		createPodResourceMetric("test_be_pod_high_prio_negative_cost", "1G"),
+		createPodResourceMetric("test_be_pod_high_prio_high_cost", "8G"),
+	}
+	tests := []struct {
+		name      string
+		costOrder string
+		want      []string
+	}{
+		{
+			name:      "cost as tie-break of the same priority",
+			costOrder: EvictionCostOrderTieBreak,
+			want: []string{
+				"test_be_pod_low_prio_invalid_cost",
+				"test_be_pod_low_prio_no_cost",
+				"test_be_pod_low_prio_low_cost",
+				"test_be_pod_low_prio_high_cost",
+				"test_be_pod_high_prio_negative_cost",
+				"test_be_pod_high_prio_high_cost",
+			},
+		},
+		{
+			name:      "cost as primary factor before priority",
+			costOrder: EvictionCostOrderPrimary,
+			want: []string{
+				"test_be_pod_high_prio_negative_cost",
+				"test_be_pod_low_prio_invalid_cost",
+				"test_be_pod_low_prio_no_cost",
+				"test_be_pod_low_prio_low_cost",
+				"test_be_pod_low_prio_high_cost",
+				"test_be_pod_high_prio_high_cost",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+
+			cfg := NewDefaultConfig()
+			cfg.MemoryEvictCostOrder = tt.costOrder
+			memoryEvictor := NewMemoryEvictor(&resmanager{statesInformer: mockStatesInformer, config: cfg})
+
+			got := memoryEvictor.getSortedPodInfos(podMetrics)
+			var gotNames []string
+			for _, info := range got {
+				gotNames = append(gotNames, info.pod.Name)
+			}
+			assert.Equal(t, tt.want, gotNames)
+		})
+	}
+}
+
+func Test_getSortedPodInfos_combinedPressure(t *testing.T) {
+	withCPU := func(podMetric *metriccache.PodResourceMetric, cpuUsage string) *metriccache.PodResourceMetric {
+		podMetric.CPUUsed = metriccache.CPUMetric{CPUUsed: resource.MustParse(cpuUsage)}
+		return podMetric
+	}
+	pods := []*corev1.Pod{
+		createMemoryEvictTestPod("test_be_pod_memory_heavy", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_cpu_heavy", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_balanced", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_high_prio_cpu_heavy", apiext.QoSBE, 200),
+	}
+	podMetrics := []*metriccache.PodResourceMetric{
+		withCPU(createPodResourceMetric("test_be_pod_memory_heavy", "40G"), "1"),
+		withCPU(createPodResourceMetric("test_be_pod_cpu_heavy", "10G"), "8"),
+		withCPU(createPodResourceMetric("test_be_pod_balanced", "30G"), "5"),
+		withCPU(createPodResourceMetric("test_be_pod_high_prio_cpu_heavy", "40G"), "10"),
+	}
+	tests := []struct {
+		name         string
+		enabled      bool
+		cpuWeight    float64
+		memoryWeight float64
+		node         *corev1.Node
+		want         []string
+	}{
+		{
+			name:         "sort by memory usage if disabled",
+			enabled:      false,
+			cpuWeight:    1,
+			memoryWeight: 1,
+			node:         getNode("10", "100G"),
+			want: []string{
+				"test_be_pod_memory_heavy",
+				"test_be_pod_balanced",
+				"test_be_pod_cpu_heavy",
+				"test_be_pod_high_prio_cpu_heavy",
+			},
+		},
+		{
+			name:         "sort by combined pressure with the same weights",
+			enabled:      true,
+			cpuWeight:    1,
+			memoryWeight: 1,
+			node:         getNode("10", "100G"),
+			want: []string{
+				"test_be_pod_cpu_heavy",
+				"test_be_pod_balanced",
+				"test_be_pod_memory_heavy",
+				"test_be_pod_high_prio_cpu_heavy",
+			},
+		},
+		{
+			name:         "sort by combined pressure with memory weighted more",
+			enabled:      true,
+			cpuWeight:    1,
+			memoryWeight: 3,
+			node:         getNode("10", "100G"),
+			want: []string{
+				"test_be_pod_balanced",
+				"test_be_pod_memory_heavy",
+				"test_be_pod_cpu_heavy",
+				"test_be_pod_high_prio_cpu_heavy",
+			},
+		},
+		{
+			name:         "fallback to memory usage if node is missing",
+			enabled:      true,
+			cpuWeight:    1,
+			memoryWeight: 1,
+			node:         nil,
+			want: []string{
+				"test_be_pod_memory_heavy",
+				"test_be_pod_balanced",
+				"test_be_pod_cpu_heavy",
+				"test_be_pod_high_prio_cpu_heavy",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(tt.node).AnyTimes()
+
+			cfg := NewDefaultConfig()
+			cfg.MemoryEvictCombinedPressure = tt.enabled
+			cfg.MemoryEvictCPUWeight = tt.cpuWeight
+			cfg.MemoryEvictMemoryWeight = tt.memoryWeight
+			memoryEvictor := NewMemoryEvictor(&resmanager{statesInformer: mockStatesInformer, config: cfg})
+
+			got := memoryEvictor.getSortedPodInfos(podMetrics)
+			var gotNames []string
+			for _, info := range got {
+				gotNames = append(gotNames, info.pod.Name)
+			}
+			assert.Equal(t, tt.want, gotNames)
+		})
+	}
+}
+
+func Test_getSortedPodInfos_preferFastGrowers(t *testing.T) {
+	pods := []*corev1.Pod{
+		createMemoryEvictTestPod("test_be_pod_steady", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_fast_growing", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_slow_growing", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_high_prio_growing", apiext.QoSBE, 200),
+	}
+	podMetrics := []*metriccache.PodResourceMetric{
+		createPodResourceMetric("test_be_pod_steady", "40G"),
+		createPodResourceMetric("test_be_pod_fast_growing", "10G"),
+		createPodResourceMetric("test_be_pod_slow_growing", "20G"),
+		createPodResourceMetric("test_be_pod_high_prio_growing", "30G"),
+	}
+	// the pod memory usage of the first data in the growth window, while the last is the current usage
+	podFirstMemory := map[string]string{
+		"test_be_pod_steady":            "40G",
+		"test_be_pod_fast_growing":      "2G",
+		"test_be_pod_slow_growing":      "18G",
+		"test_be_pod_high_prio_growing": "1G",
+	}
+	tests := []struct {
+		name            string
+		preferGrowers   *bool
+		nodeFirstMemory string
+		want            []string
+	}{
+		{
+			name:            "sort by memory usage if disabled",
+			preferGrowers:   nil,
+			nodeFirstMemory: "50G",
+			want: []string{
+				"test_be_pod_steady",
+				"test_be_pod_slow_growing",
+				"test_be_pod_fast_growing",
+				"test_be_pod_high_prio_growing",
+			},
+		},
+		{
+			name:            "sort by memory usage if node memory is not rising",
+			preferGrowers:   pointer.BoolPtr(true),
+			nodeFirstMemory: "80G",
+			want: []string{
+				"test_be_pod_steady",
+				"test_be_pod_slow_growing",
+				"test_be_pod_fast_growing",
+				"test_be_pod_high_prio_growing",
+			},
+		},
+		{
+			name:            "sort by memory growth if node memory is rising",
+			preferGrowers:   pointer.BoolPtr(true),
+			nodeFirstMemory: "50G",
+			want: []string{
+				"test_be_pod_fast_growing",
+				"test_be_pod_slow_growing",
+				"test_be_pod_steady",
+				"test_be_pod_high_prio_growing",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).DoAndReturn(func(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
+				memoryUsed := "80G"
+				if param.Aggregate == metriccache.AggregationTypeFirst {
+					memoryUsed = tt.nodeFirstMemory
+				}
+				return metriccache.NodeResourceQueryResult{Metric: &metriccache.NodeResourceMetric{
+					MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse(memoryUsed)},
+				}}
+			}).AnyTimes()
+			mockMetricCache.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).DoAndReturn(func(podUID *string, param *metriccache.QueryParam) metriccache.PodResourceQueryResult {
+				if param.Aggregate == metriccache.AggregationTypeFirst {
+					return metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(*podUID, podFirstMemory[*podUID])}
+				}
+				for _, podMetric := range podMetrics {
+					if podMetric.PodUID == *podUID {
+						return metriccache.PodResourceQueryResult{Metric: podMetric}
+					}
+				}
+				return metriccache.PodResourceQueryResult{}
+			}).AnyTimes()
+
+			thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                       pointer.BoolPtr(true),
+				MemoryEvictPreferFastGrowers: tt.preferGrowers,
+			}
+			memoryEvictor := NewMemoryEvictor(&resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache,
+				nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: NewDefaultConfig()})
+
+			got := memoryEvictor.getSortedPodInfos(podMetrics)
+			var gotNames []string
+			for _, info := range got {
+				gotNames = append(gotNames, info.pod.Name)
+			}
+			assert.Equal(t, tt.want, gotNames)
+		})
+	}
+}
+
+func Test_getSortedPodInfos_byDominantContainer(t *testing.T) {
+	// the memory usage of the containers of each pod by the container name
+	podContainerMemory := map[string]map[string]string{
+		"test_be_pod_spread": {
+			"main":      "10G",
+			"sidecar-0": "10G",
+			"sidecar-1": "10G",
+		},
+		"test_be_pod_huge_main": {
+			"main":      "24G",
+			"sidecar-0": "1G",
+		},
+		"test_be_pod_single": {
+			"main": "20G",
+		},
+	}
+	var pods []*corev1.Pod
+	var podMetrics []*metriccache.PodResourceMetric
+	containerMetrics := map[string]*metriccache.ContainerResourceMetric{}
+	for _, podName := range []string{"test_be_pod_spread", "test_be_pod_huge_main", "test_be_pod_single"} {
+		pod := createMemoryEvictTestPod(podName, apiext.QoSBE, 100)
+		pod.Spec.Containers = nil
+		pod.Status.ContainerStatuses = nil
+		podMemory := resource.NewQuantity(0, resource.BinarySI)
+		for containerName, memoryUsed := range podContainerMemory[podName] {
+			containerID := fmt.Sprintf("docker://%s_%s", podName, containerName)
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: containerName})
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+				Name:        containerName,
+				ContainerID: containerID,
+			})
+			containerMetrics[containerID] = &metriccache.ContainerResourceMetric{
+				ContainerID: containerID,
+				MemoryUsed:  metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse(memoryUsed)},
+			}
+			podMemory.Add(resource.MustParse(memoryUsed))
+		}
+		pods = append(pods, pod)
+		podMetrics = append(podMetrics, createPodResourceMetric(podName, podMemory.String()))
+	}
+
+	tests := []struct {
+		name                string
+		byDominantContainer bool
+		want                []string
+	}{
+		{
+			name:                "sort by pod memory usage if disabled",
+			byDominantContainer: false,
+			want:                []string{"test_be_pod_spread", "test_be_pod_huge_main", "test_be_pod_single"},
+		},
+		{
+			name:                "sort by dominant container memory usage",
+			byDominantContainer: true,
+			want:                []string{"test_be_pod_huge_main", "test_be_pod_single", "test_be_pod_spread"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetContainerResourceMetric(gomock.Any(), gomock.Any()).DoAndReturn(func(containerID *string, param *metriccache.QueryParam) metriccache.ContainerResourceQueryResult {
+				return metriccache.ContainerResourceQueryResult{Metric: containerMetrics[*containerID]}
+			}).AnyTimes()
+
+			cfg := NewDefaultConfig()
+			cfg.MemoryEvictByDominantContainer = tt.byDominantContainer
+			thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{Enable: pointer.BoolPtr(true)}
+			memoryEvictor := NewMemoryEvictor(&resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache,
+				nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg})
+
+			got := memoryEvictor.getSortedPodInfos(podMetrics)
+			var gotNames []string
+			for _, info := range got {
+				gotNames = append(gotNames, info.pod.Name)
+				if !tt.byDominantContainer {
+					assert.Nil(t, info.containerMemoryUsed)
+					continue
+				}
+				// the candidates carry the memory usage of each container
+				wantContainerMemory := map[string]int64{}
+				for containerName, memoryUsed := range podContainerMemory[info.pod.Name] {
+					quantity := resource.MustParse(memoryUsed)
+					wantContainerMemory[containerName] = quantity.Value()
+				}
+				assert.Equal(t, wantContainerMemory, info.containerMemoryUsed)
+			}
+			assert.Equal(t, tt.want, gotNames)
+		})
+	}
+}
+
+func Test_getMemoryGrowth(t *testing.T) {
+	first := &metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("10G")}
+	last := &metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("16G")}
+	assert.Equal(t, float64(100*1000*1000), getMemoryGrowth(first, last, 60))
+	assert.Equal(t, float64(-100*1000*1000), getMemoryGrowth(last, first, 60))
+	assert.Equal(t, float64(0), getMemoryGrowth(first, last, 0))
+}
+
+func Test_getPodPressureScore(t *testing.T) {
+	podMetric := &metriccache.PodResourceMetric{
+		CPUUsed:    metriccache.CPUMetric{CPUUsed: resource.MustParse("2")},
+		MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("25G")},
+	}
+	assert.InDelta(t, 0.45, getPodPressureScore(podMetric, 10000, 100e9, 1, 1), 1e-9)
+	assert.InDelta(t, 0.95, getPodPressureScore(podMetric, 10000, 100e9, 1, 3), 1e-9)
+	assert.InDelta(t, 0.2, getPodPressureScore(podMetric, 10000, 100e9, 1, 0), 1e-9)
+	assert.Equal(t, float64(0), getPodPressureScore(nil, 10000, 100e9, 1, 1))
+}
+
 func createMemoryEvictTestPod(name string, qosClass apiext.QoSClass, priority int32) *corev1.Pod {
 	return &corev1.Pod{
 		TypeMeta: metav1.TypeMeta{Kind: "Pod"},
@@ -331,3 +2029,178 @@ func createPodResourceMetric(podUID string, memoryUsage string) *metriccache.Pod
 		MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse(memoryUsage)},
 	}
 }
+
+func Test_memoryEvict_emptyPodMetrics(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	pods := []*corev1.Pod{
+		createMemoryEvictTestPod("test_be_pod_0", apiext.QoSBE, 100),
+		createMemoryEvictTestPod("test_be_pod_1", apiext.QoSBE, 120),
+	}
+	node := getNode("80", "100G")
+	node.Status.Conditions = []corev1.NodeCondition{
+		{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+	}
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	mockStatesInformer.EXPECT().GetNode().Return(node).AnyTimes()
+
+	// the node memory usage is beyond threshold, while no pod metric is collected yet
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("90G")},
+		},
+	}).AnyTimes()
+	mockMetricCache.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{}).AnyTimes()
+
+	thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+	}
+	cfg := NewDefaultConfig()
+	cfg.EvictOnNodePressureCondition = true
+	client := clientsetfake.NewSimpleClientset()
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+		eventRecorder: &FakeRecorder{}, kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig), config: cfg}
+	stop := make(chan struct{})
+	_ = r.podsEvicted.Run(stop)
+	defer func() { stop <- struct{}{} }()
+
+	runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	memoryEvictor := NewMemoryEvictor(r)
+	assert.Nil(t, memoryEvictor.prepareMemoryEvict())
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+
+	for _, pod := range pods {
+		_, evicted := r.podsEvicted.Get(string(pod.UID))
+		assert.False(t, evicted, "check evicted for pod %s", pod.Name)
+	}
+}
+
+func Test_memoryEvict_enforcementPodSelector(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// only the pod with the higher priority opts in the enforcement
+	notMatchedPod := createMemoryEvictTestPod("test_be_pod_not_matched", apiext.QoSBE, 100)
+	matchedPod := createMemoryEvictTestPod("test_be_pod_matched", apiext.QoSBE, 120)
+	matchedPod.Labels["qos-enforce"] = "true"
+	pods := []*corev1.Pod{notMatchedPod, matchedPod}
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "100G")).AnyTimes()
+
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("95G")},
+		},
+	}).AnyTimes()
+	for _, pod := range pods {
+		podUID := string(pod.UID)
+		mockPodQueryResult := metriccache.PodResourceQueryResult{Metric: createPodResourceMetric(podUID, "10G")}
+		mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(mockPodQueryResult).AnyTimes()
+	}
+
+	thresholdConfig := &slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+	}
+	podSelector, err := parseEnforcementPodSelector("qos-enforce=true")
+	assert.NoError(t, err)
+	client := clientsetfake.NewSimpleClientset()
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, podsEvicted: cache.NewCacheDefault(),
+		eventRecorder: &FakeRecorder{}, kubeClient: client, nodeSLO: getNodeSLOByThreshold(thresholdConfig),
+		config: NewDefaultConfig(), enforcementPodSelector: podSelector}
+	stop := make(chan struct{})
+	_ = r.podsEvicted.Run(stop)
+	defer func() { stop <- struct{}{} }()
+
+	runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	memoryEvictor := NewMemoryEvictor(r)
+	memoryEvictor.lastEvictTime = time.Now().Add(-30 * time.Second)
+	memoryEvictor.memoryEvict()
+
+	_, evicted := r.podsEvicted.Get(string(matchedPod.UID))
+	assert.True(t, evicted, "the matched pod should be evicted")
+	_, evicted = r.podsEvicted.Get(string(notMatchedPod.UID))
+	assert.False(t, evicted, "the not matched pod should be left untouched")
+}
+
+func Test_killAndEvictBEPods_evictMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		evictCtx    *memoryEvictContext
+		wantMessage string
+	}{
+		{
+			name: "evict by the threshold percent",
+			evictCtx: &memoryEvictContext{
+				memoryCapacity:   100 * 1000 * 1000 * 1000,
+				memoryUsed:       90 * 1000 * 1000 * 1000,
+				thresholdPercent: 80,
+				lowerPercent:     78,
+			},
+			wantMessage: "need to release memory: 12000000000, memory used 90000000000(90%) of capacity 100000000000, threshold 80%",
+		},
+		{
+			name: "evict by the reserve bytes",
+			evictCtx: &memoryEvictContext{
+				memoryCapacity: 100 * 1000 * 1000 * 1000,
+				memoryUsed:     90 * 1000 * 1000 * 1000,
+				reserveBytes:   pointer.Int64Ptr(20 * 1000 * 1000 * 1000),
+			},
+			wantMessage: "need to release memory: 10000000000, memory used 90000000000 of capacity 100000000000, " +
+				"free memory 10000000000 below reserve bytes 20000000000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			pod := createMemoryEvictTestPod("test_be_pod", apiext.QoSBE, 100)
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas([]*corev1.Pod{pod})).AnyTimes()
+			podMetric := createPodResourceMetric(string(pod.UID), "10G")
+
+			fakeRecorder := record.NewFakeRecorder(10)
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{statesInformer: mockStatesInformer, podsEvicted: cache.NewCacheDefault(),
+				eventRecorder: fakeRecorder, kubeClient: client, config: NewDefaultConfig()}
+			stop := make(chan struct{})
+			_ = r.podsEvicted.Run(stop)
+			defer func() { stop <- struct{}{} }()
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			assert.NoError(t, err)
+
+			tt.evictCtx.node = getNode("80", "100G")
+			tt.evictCtx.podMetrics = []*metriccache.PodResourceMetric{podMetric}
+			NewMemoryEvictor(r).killAndEvictBEPods(tt.evictCtx)
+
+			// the measured usage and the threshold go to the eviction event and audit with the same message
+			_, evicted := r.podsEvicted.Get(string(pod.UID))
+			assert.True(t, evicted)
+			select {
+			case event := <-fakeRecorder.Events:
+				assert.Contains(t, event, tt.wantMessage)
+			default:
+				t.Error("no eviction event is recorded")
+			}
+		})
+	}
+}
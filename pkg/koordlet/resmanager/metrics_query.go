@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 )
@@ -32,6 +34,47 @@ func (r *resmanager) collectNodeAndPodMetricLast() (*metriccache.NodeResourceMet
 	return r.collectNodeAndPodMetrics(queryParam)
 }
 
+// query the data aggregated in windowSeconds if specified, otherwise the last data for 2 * collectResUsedIntervalSeconds
+func (r *resmanager) collectNodeAndPodMetricWithWindow(windowSeconds *int64,
+	aggregation slov1alpha1.MetricAggregation) (*metriccache.NodeResourceMetric, []*metriccache.PodResourceMetric) {
+	if windowSeconds == nil || *windowSeconds <= 0 {
+		return r.collectNodeAndPodMetricLast()
+	}
+	queryParam := generateQueryParams(*windowSeconds, getAggregationType(aggregation))
+	return r.collectNodeAndPodMetrics(queryParam)
+}
+
+// query the memory growth rates in bytes per second of the node and the given pods from the first to the last data in
+// windowSeconds, where the node growth is 0 if the node metric is missing and the pods missing metrics are omitted
+func (r *resmanager) collectMemoryGrowthWithWindow(windowSeconds int64,
+	podsMeta []*statesinformer.PodMeta) (float64, map[string]float64) {
+	firstParam := generateQueryParams(windowSeconds, metriccache.AggregationTypeFirst)
+	lastParam := &metriccache.QueryParam{Aggregate: metriccache.AggregationTypeLast, Start: firstParam.Start, End: firstParam.End}
+
+	var nodeGrowth float64
+	nodeFirst, nodeLast := r.collectNodeMetric(firstParam).Metric, r.collectNodeMetric(lastParam).Metric
+	if nodeFirst != nil && nodeLast != nil {
+		nodeGrowth = getMemoryGrowth(&nodeFirst.MemoryUsed, &nodeLast.MemoryUsed, windowSeconds)
+	}
+	podGrowths := make(map[string]float64, len(podsMeta))
+	for _, podMeta := range podsMeta {
+		podFirst, podLast := r.collectPodMetric(podMeta, firstParam).Metric, r.collectPodMetric(podMeta, lastParam).Metric
+		if podFirst == nil || podLast == nil {
+			continue
+		}
+		podGrowths[podFirst.PodUID] = getMemoryGrowth(&podFirst.MemoryUsed, &podLast.MemoryUsed, windowSeconds)
+	}
+	return nodeGrowth, podGrowths
+}
+
+// query node data for 2 * collectResUsedIntervalSeconds
+func (r *resmanager) collectNodeMetricLast() *metriccache.NodeResourceMetric {
+	queryParam := generateQueryParamsLast(r.collectResUsedIntervalSeconds * 2)
+	return r.collectNodeMetric(queryParam).Metric
+}
+
+// collectNodeAndPodMetrics returns nil pod metrics if none of the pods has metrics, e.g. at the koordlet startup or
+// after the metric cache restarts, so callers can tell the missing samples from the zero usage and skip this round.
 func (r *resmanager) collectNodeAndPodMetrics(queryParam *metriccache.QueryParam) (*metriccache.NodeResourceMetric, []*metriccache.PodResourceMetric) {
 	// collect node's and all pods' metrics with the same query param
 	nodeQueryResult := r.collectNodeMetric(queryParam)
@@ -46,6 +89,10 @@ func (r *resmanager) collectNodeAndPodMetrics(queryParam *metriccache.QueryParam
 			podsMetrics = append(podsMetrics, podMetric)
 		}
 	}
+	if len(podsMeta) > 0 && len(podsMetrics) == 0 {
+		klog.Warningf("pod metrics not exist for all %v pods", len(podsMeta))
+		return nodeMetric, nil
+	}
 	return nodeMetric, podsMetrics
 }
 
@@ -98,6 +145,24 @@ func (r *resmanager) collectContainerResMetricLast(containerID *string) metricca
 	return queryResult
 }
 
+// collectContainersMemoryUsedLast returns the last memory usage without cache of the running containers of the pod by
+// the container name, where the containers without the metric are absent.
+func (r *resmanager) collectContainersMemoryUsedLast(pod *corev1.Pod) map[string]int64 {
+	containerMemoryUsed := make(map[string]int64, len(pod.Status.ContainerStatuses))
+	for i := range pod.Status.ContainerStatuses {
+		containerStat := &pod.Status.ContainerStatuses[i]
+		if containerStat.ContainerID == "" {
+			continue
+		}
+		queryResult := r.collectContainerResMetricLast(&containerStat.ContainerID)
+		if queryResult.Error != nil || queryResult.Metric == nil {
+			continue
+		}
+		containerMemoryUsed[containerStat.Name] = queryResult.Metric.MemoryUsed.MemoryWithoutCache.Value()
+	}
+	return containerMemoryUsed
+}
+
 func (r *resmanager) collectContainerThrottledMetricLast(containerID *string) metriccache.ContainerThrottledQueryResult {
 	if containerID == nil {
 		return metriccache.ContainerThrottledQueryResult{
@@ -117,24 +182,43 @@ func (r *resmanager) collectContainerThrottledMetricLast(containerID *string) me
 	return queryResult
 }
 
-func generateQueryParamsAvg(windowSeconds int64) *metriccache.QueryParam {
-	end := time.Now()
-	start := end.Add(-time.Duration(windowSeconds) * time.Second)
-	queryParam := &metriccache.QueryParam{
-		Aggregate: metriccache.AggregationTypeAVG,
-		Start:     &start,
-		End:       &end,
+// getMemoryGrowth returns the growth rate in bytes per second of the memory usage without cache in windowSeconds.
+func getMemoryGrowth(first, last *metriccache.MemoryMetric, windowSeconds int64) float64 {
+	if windowSeconds <= 0 {
+		return 0
 	}
-	return queryParam
+	return float64(last.MemoryWithoutCache.Value()-first.MemoryWithoutCache.Value()) / float64(windowSeconds)
+}
+
+func generateQueryParamsAvg(windowSeconds int64) *metriccache.QueryParam {
+	return generateQueryParams(windowSeconds, metriccache.AggregationTypeAVG)
 }
 
 func generateQueryParamsLast(windowSeconds int64) *metriccache.QueryParam {
+	return generateQueryParams(windowSeconds, metriccache.AggregationTypeLast)
+}
+
+func generateQueryParams(windowSeconds int64, aggregate metriccache.AggregationType) *metriccache.QueryParam {
 	end := time.Now()
 	start := end.Add(-time.Duration(windowSeconds) * time.Second)
 	queryParam := &metriccache.QueryParam{
-		Aggregate: metriccache.AggregationTypeLast,
+		Aggregate: aggregate,
 		Start:     &start,
 		End:       &end,
 	}
 	return queryParam
 }
+
+// getAggregationType returns the aggregation type of the metric cache query, which is avg by default.
+func getAggregationType(aggregation slov1alpha1.MetricAggregation) metriccache.AggregationType {
+	switch aggregation {
+	case slov1alpha1.MetricAggregationP95:
+		return metriccache.AggregationTypeP95
+	case slov1alpha1.MetricAggregationMax:
+		return metriccache.AggregationTypeMax
+	case slov1alpha1.MetricAggregationLast:
+		return metriccache.AggregationTypeLast
+	default:
+		return metriccache.AggregationTypeAVG
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+)
+
+func Test_collectNodeAndPodMetricWithWindow(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	pod := createTestPod(apiext.QoSBE, "test_window_pod")
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas([]*corev1.Pod{pod})).AnyTimes()
+
+	mc, err := metriccache.NewMetricCache(metriccache.NewDefaultConfig())
+	assert.NoError(t, err)
+	// the same samples: 40G at 50s ago, 60G at 20s ago, 80G at 2s ago
+	now := time.Now()
+	for _, sample := range []struct {
+		ago      time.Duration
+		memoryGB int64
+	}{
+		{ago: 50 * time.Second, memoryGB: 40},
+		{ago: 20 * time.Second, memoryGB: 60},
+		{ago: 2 * time.Second, memoryGB: 80},
+	} {
+		memory := *resource.NewQuantity(sample.memoryGB<<30, resource.BinarySI)
+		err = mc.InsertNodeResourceMetric(now.Add(-sample.ago), &metriccache.NodeResourceMetric{
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: memory},
+		})
+		assert.NoError(t, err)
+		err = mc.InsertPodResourceMetric(now.Add(-sample.ago), &metriccache.PodResourceMetric{
+			PodUID:     string(pod.UID),
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: memory},
+		})
+		assert.NoError(t, err)
+	}
+
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mc, collectResUsedIntervalSeconds: 5}
+	strategy := &slov1alpha1.ResourceThresholdStrategy{
+		CPUSuppressMetricWindowSeconds: pointer.Int64Ptr(10),
+		MemoryEvictMetricWindowSeconds: pointer.Int64Ptr(60),
+	}
+
+	tests := []struct {
+		name             string
+		windowSeconds    *int64
+		aggregation      slov1alpha1.MetricAggregation
+		expectMemoryUsed int64
+	}{
+		{
+			name:             "use the last metrics by default",
+			windowSeconds:    nil,
+			expectMemoryUsed: 80 << 30,
+		},
+		{
+			name:             "cpu suppress reads the average in a short window",
+			windowSeconds:    strategy.CPUSuppressMetricWindowSeconds,
+			expectMemoryUsed: 80 << 30,
+		},
+		{
+			name:             "memory evict reads the average in a long window",
+			windowSeconds:    strategy.MemoryEvictMetricWindowSeconds,
+			expectMemoryUsed: 60 << 30,
+		},
+		{
+			name:             "memory evict reads the max in a long window",
+			windowSeconds:    strategy.MemoryEvictMetricWindowSeconds,
+			aggregation:      slov1alpha1.MetricAggregationMax,
+			expectMemoryUsed: 80 << 30,
+		},
+		{
+			name:             "memory evict reads the last in a long window",
+			windowSeconds:    strategy.MemoryEvictMetricWindowSeconds,
+			aggregation:      slov1alpha1.MetricAggregationLast,
+			expectMemoryUsed: 80 << 30,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeMetric, podMetrics := r.collectNodeAndPodMetricWithWindow(tt.windowSeconds, tt.aggregation)
+			assert.NotNil(t, nodeMetric)
+			assert.Equal(t, tt.expectMemoryUsed, nodeMetric.MemoryUsed.MemoryWithoutCache.Value())
+			assert.Equal(t, 1, len(podMetrics))
+			assert.Equal(t, tt.expectMemoryUsed, podMetrics[0].MemoryUsed.MemoryWithoutCache.Value())
+		})
+	}
+}
+
+func Test_collectNodeAndPodMetrics_emptyPodMetrics(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	mockMetricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse("2")},
+		},
+	}).AnyTimes()
+	mockMetricCache.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{}).AnyTimes()
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, collectResUsedIntervalSeconds: 5}
+
+	// no pod metric is collected for the running pods
+	pods := []*corev1.Pod{createTestPod(apiext.QoSBE, "test_be_pod"), createTestPod(apiext.QoSLS, "test_ls_pod")}
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).Times(1)
+	nodeMetric, podMetrics := r.collectNodeAndPodMetricLast()
+	assert.NotNil(t, nodeMetric)
+	assert.Nil(t, podMetrics)
+
+	// no pod is running on the node
+	mockStatesInformer.EXPECT().GetAllPods().Return(nil).Times(1)
+	nodeMetric, podMetrics = r.collectNodeAndPodMetricLast()
+	assert.NotNil(t, nodeMetric)
+	assert.NotNil(t, podMetrics)
+	assert.Equal(t, 0, len(podMetrics))
+}
+
+func Test_getAggregationType(t *testing.T) {
+	tests := []struct {
+		aggregation slov1alpha1.MetricAggregation
+		want        metriccache.AggregationType
+	}{
+		{aggregation: "", want: metriccache.AggregationTypeAVG},
+		{aggregation: slov1alpha1.MetricAggregationAvg, want: metriccache.AggregationTypeAVG},
+		{aggregation: slov1alpha1.MetricAggregationP95, want: metriccache.AggregationTypeP95},
+		{aggregation: slov1alpha1.MetricAggregationMax, want: metriccache.AggregationTypeMax},
+		{aggregation: slov1alpha1.MetricAggregationLast, want: metriccache.AggregationTypeLast},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.aggregation), func(t *testing.T) {
+			assert.Equal(t, tt.want, getAggregationType(tt.aggregation))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	coreinformerv1 "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func newNamespaceInformer(client clientset.Interface) cache.SharedIndexInformer {
+	return coreinformerv1.NewNamespaceInformer(client, time.Hour*12, cache.Indexers{})
+}
+
+// getNamespaceMemoryQoSPolicy returns the default memory qos policy of the pods in the namespace, which is empty if
+// the namespace policy is disabled, not set or the namespace is not found.
+func (r *resmanager) getNamespaceMemoryQoSPolicy(namespace string) slov1alpha1.PodMemoryQoSPolicy {
+	if r.namespaceLister == nil || namespace == "" {
+		return ""
+	}
+	ns, err := r.namespaceLister.Get(namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.V(4).Infof("failed to get namespace %s for memory qos policy, error: %v", namespace, err)
+		}
+		return ""
+	}
+	policy, err := apiext.GetNamespaceMemoryQoSPolicy(ns)
+	if err != nil {
+		klog.V(4).Infof("ignore memory qos policy of namespace %s, error: %v", namespace, err)
+		return ""
+	}
+	return policy
+}
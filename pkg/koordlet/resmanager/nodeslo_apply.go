@@ -0,0 +1,122 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/featuregate"
+	"k8s.io/utils/clock"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+)
+
+// nodeSLOApplyTracker measures the enforcement latency of the NodeSLO spec updates. Each applied spec change is
+// tagged with a generation, and a feature cycle consumes the generation current at its start. The latency from the
+// update received to the first completed cycle of each feature consuming it is recorded.
+// A nil nodeSLOApplyTracker is valid and tracks nothing.
+type nodeSLOApplyTracker struct {
+	lock  sync.Mutex
+	clock clock.Clock
+	// generation increases for each applied spec change, and updateTime is the time the latest change is received,
+	// which is zero if it is not received by an update, e.g. the NodeSLO is created
+	generation int64
+	updateTime time.Time
+	// pendingTime is the time the first update not applied yet is received, e.g. during the coalesce window
+	pendingTime time.Time
+	// appliedGenerations is the generation consumed by the last completed cycle of each feature
+	appliedGenerations map[featuregate.Feature]int64
+}
+
+func newNodeSLOApplyTracker() *nodeSLOApplyTracker {
+	return &nodeSLOApplyTracker{
+		clock:              clock.RealClock{},
+		appliedGenerations: map[featuregate.Feature]int64{},
+	}
+}
+
+// received marks a spec update received, which is applied later by specChanged or dropped by specRejected.
+func (t *nodeSLOApplyTracker) received() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.pendingTime.IsZero() {
+		t.pendingTime = t.clock.Now()
+	}
+}
+
+// specChanged tags the applied spec with a new generation.
+func (t *nodeSLOApplyTracker) specChanged() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.generation++
+	t.updateTime = t.pendingTime
+	t.pendingTime = time.Time{}
+}
+
+func (t *nodeSLOApplyTracker) specRejected() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.pendingTime = time.Time{}
+}
+
+func (t *nodeSLOApplyTracker) currentGeneration() int64 {
+	if t == nil {
+		return 0
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.generation
+}
+
+// applied records the feature completed a cycle with the spec of the generation, and observes the latency if it is
+// the first cycle consuming the latest update.
+func (t *nodeSLOApplyTracker) applied(feature featuregate.Feature, generation int64) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if generation <= t.appliedGenerations[feature] {
+		return
+	}
+	t.appliedGenerations[feature] = generation
+	if generation == t.generation && !t.updateTime.IsZero() {
+		metrics.RecordNodeSLOApplyLatency(string(feature), t.clock.Since(t.updateTime).Seconds())
+	}
+}
+
+// track wraps the moduleFunc of the feature to record the generation consumed by each completed cycle.
+func (t *nodeSLOApplyTracker) track(feature featuregate.Feature, moduleFunc func()) func() {
+	if t == nil {
+		return moduleFunc
+	}
+	return func() {
+		generation := t.currentGeneration()
+		moduleFunc()
+		t.applied(feature, generation)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+)
+
+// getNodeSLOApplyLatency returns the sample count and sum of the apply latency histogram of the feature
+func getNodeSLOApplyLatency(t *testing.T, feature string) (uint64, float64) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.NodeSLOApplyLatency)
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == metrics.FeatureKey && label.GetValue() == feature {
+					return metric.GetHistogram().GetSampleCount(), metric.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+	return 0, 0
+}
+
+func Test_nodeSLOApplyTracker(t *testing.T) {
+	testingNode := getNode("80", "120G")
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.NodeSLOApplyLatency.Reset()
+	defer metrics.NodeSLOApplyLatency.Reset()
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	tracker := newNodeSLOApplyTracker()
+	tracker.clock = fakeClock
+	r := &resmanager{nodeSLOApply: tracker}
+	cycles := 0
+	cpuSuppressCycle := tracker.track(features.BECPUSuppress, func() { cycles++ })
+	memoryEvictCycle := tracker.track(features.BEMemoryEvict, func() {})
+	cpuSuppress, memoryEvict := string(features.BECPUSuppress), string(features.BEMemoryEvict)
+
+	// the creation is not an update to measure
+	r.createNodeSLO(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		CPUSuppressThresholdPercent: pointer.Int64Ptr(80),
+	}))
+	cpuSuppressCycle()
+	count, _ := getNodeSLOApplyLatency(t, cpuSuppress)
+	assert.Equal(t, uint64(0), count)
+
+	// the spec update is applied after the coalesce window, and consumed by the next cycle of each feature
+	tracker.received()
+	fakeClock.Step(2 * time.Second)
+	tracker.received()
+	r.updateNodeSLOSpec(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		CPUSuppressThresholdPercent: pointer.Int64Ptr(60),
+	}))
+	fakeClock.Step(3 * time.Second)
+	cpuSuppressCycle()
+	cpuSuppressCycle()
+	count, sum := getNodeSLOApplyLatency(t, cpuSuppress)
+	assert.Equal(t, uint64(1), count)
+	assert.Equal(t, float64(5), sum)
+	fakeClock.Step(time.Second)
+	memoryEvictCycle()
+	count, sum = getNodeSLOApplyLatency(t, memoryEvict)
+	assert.Equal(t, uint64(1), count)
+	assert.Equal(t, float64(6), sum)
+	assert.Equal(t, 3, cycles)
+
+	// the rejected update is not measured
+	tracker.received()
+	r.updateNodeSLOSpec(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(200),
+	}))
+	cpuSuppressCycle()
+	count, _ = getNodeSLOApplyLatency(t, cpuSuppress)
+	assert.Equal(t, uint64(1), count)
+
+	// the cycle started before the update does not consume it
+	tracker.received()
+	updateDuringCycle := tracker.track(features.BECPUSuppress, func() {
+		r.updateNodeSLOSpec(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+			Enable:                      pointer.BoolPtr(true),
+			CPUSuppressThresholdPercent: pointer.Int64Ptr(70),
+		}))
+	})
+	updateDuringCycle()
+	count, _ = getNodeSLOApplyLatency(t, cpuSuppress)
+	assert.Equal(t, uint64(1), count)
+	fakeClock.Step(time.Second)
+	cpuSuppressCycle()
+	count, sum = getNodeSLOApplyLatency(t, cpuSuppress)
+	assert.Equal(t, uint64(2), count)
+	assert.Equal(t, float64(6), sum)
+}
+
+func Test_nodeSLOApplyTracker_nil(t *testing.T) {
+	var tracker *nodeSLOApplyTracker
+	called := false
+	tracker.received()
+	tracker.specChanged()
+	tracker.specRejected()
+	tracker.track(features.BECPUSuppress, func() { called = true })()
+	assert.True(t, called)
+	assert.Equal(t, int64(0), tracker.currentGeneration())
+}
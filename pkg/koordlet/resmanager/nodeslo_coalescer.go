@@ -0,0 +1,80 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+// nodeSLOUpdateCoalescer coalesces the NodeSLO updates which arrive within the window, so that only the latest one
+// is applied at the end of the window. The window starts from the first pending update and is not extended by the
+// following ones, so an update is applied no later than the window.
+type nodeSLOUpdateCoalescer struct {
+	window time.Duration
+	apply  func(nodeSLO *slov1alpha1.NodeSLO)
+	clock  clock.Clock
+
+	lock    sync.Mutex
+	pending *slov1alpha1.NodeSLO
+}
+
+func newNodeSLOUpdateCoalescer(window time.Duration, apply func(nodeSLO *slov1alpha1.NodeSLO)) *nodeSLOUpdateCoalescer {
+	return &nodeSLOUpdateCoalescer{
+		window: window,
+		apply:  apply,
+		clock:  clock.RealClock{},
+	}
+}
+
+// enqueue applies the nodeSLO immediately if the window is disabled, otherwise it replaces the pending update and
+// schedules the apply if it is the first one in the window.
+func (c *nodeSLOUpdateCoalescer) enqueue(nodeSLO *slov1alpha1.NodeSLO) {
+	if c.window <= 0 {
+		c.apply(nodeSLO)
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	scheduled := c.pending != nil
+	c.pending = nodeSLO
+	if scheduled {
+		return
+	}
+	// register the timer before returning so the fake clock in tests can step it deterministically
+	afterCh := c.clock.After(c.window)
+	go func() {
+		<-afterCh
+		c.flush()
+	}()
+}
+
+func (c *nodeSLOUpdateCoalescer) flush() {
+	c.lock.Lock()
+	nodeSLO := c.pending
+	c.pending = nil
+	c.lock.Unlock()
+
+	if nodeSLO != nil {
+		c.apply(nodeSLO)
+	}
+}
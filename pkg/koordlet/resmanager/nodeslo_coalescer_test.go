@@ -0,0 +1,87 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func Test_nodeSLOUpdateCoalescer(t *testing.T) {
+	newNodeSLO := func(cpuSuppressThresholdPercent int64) *slov1alpha1.NodeSLO {
+		return &slov1alpha1.NodeSLO{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec: slov1alpha1.NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+					Enable:                      pointer.BoolPtr(true),
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(cpuSuppressThresholdPercent),
+				},
+			},
+		}
+	}
+
+	t.Run("apply only the latest update within the window", func(t *testing.T) {
+		var lock sync.Mutex
+		var applied []*slov1alpha1.NodeSLO
+		c := newNodeSLOUpdateCoalescer(time.Second, func(nodeSLO *slov1alpha1.NodeSLO) {
+			lock.Lock()
+			defer lock.Unlock()
+			applied = append(applied, nodeSLO)
+		})
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		c.clock = fakeClock
+		getApplied := func() []*slov1alpha1.NodeSLO {
+			lock.Lock()
+			defer lock.Unlock()
+			return append([]*slov1alpha1.NodeSLO{}, applied...)
+		}
+
+		c.enqueue(newNodeSLO(60))
+		c.enqueue(newNodeSLO(65))
+		fakeClock.Step(500 * time.Millisecond)
+		c.enqueue(newNodeSLO(70))
+		assert.Empty(t, getApplied())
+
+		fakeClock.Step(500 * time.Millisecond)
+		assert.Eventually(t, func() bool { return len(getApplied()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, newNodeSLO(70), getApplied()[0])
+
+		// the update after the window starts a new one
+		c.enqueue(newNodeSLO(75))
+		fakeClock.Step(time.Second)
+		assert.Eventually(t, func() bool { return len(getApplied()) == 2 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, newNodeSLO(75), getApplied()[1])
+	})
+
+	t.Run("apply every update if the window is disabled", func(t *testing.T) {
+		var applied []*slov1alpha1.NodeSLO
+		c := newNodeSLOUpdateCoalescer(0, func(nodeSLO *slov1alpha1.NodeSLO) {
+			applied = append(applied, nodeSLO)
+		})
+		c.enqueue(newNodeSLO(60))
+		c.enqueue(newNodeSLO(65))
+		assert.Equal(t, []*slov1alpha1.NodeSLO{newNodeSLO(60), newNodeSLO(65)}, applied)
+	})
+}
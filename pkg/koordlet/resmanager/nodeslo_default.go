@@ -0,0 +1,72 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// loadDefaultNodeSLOSpec loads the default NodeSLO spec override from DefaultNodeSLOSpecPath if configured, which
+// replaces the built-in default spec to merge the NodeSLO with.
+func (r *resmanager) loadDefaultNodeSLOSpec() error {
+	if r.config == nil || r.config.DefaultNodeSLOSpecPath == "" {
+		return nil
+	}
+	spec, err := loadDefaultNodeSLOSpecFromFile(r.config.DefaultNodeSLOSpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to load default NodeSLO spec from %s, err: %v", r.config.DefaultNodeSLOSpecPath, err)
+	}
+	r.defaultNodeSLOSpec = spec
+	klog.Infof("load default NodeSLO spec from %s: %s", r.config.DefaultNodeSLOSpecPath, util.DumpJSON(spec))
+	return nil
+}
+
+// getDefaultNodeSLOSpec returns a copy of the default NodeSLO spec, which is the built-in one if no override is loaded.
+func (r *resmanager) getDefaultNodeSLOSpec() slov1alpha1.NodeSLOSpec {
+	if r.defaultNodeSLOSpec == nil {
+		return util.DefaultNodeSLOSpecConfig()
+	}
+	return *r.defaultNodeSLOSpec.DeepCopy()
+}
+
+// loadDefaultNodeSLOSpecFromFile reads the json NodeSLO spec from the file and merges it with the built-in default
+// spec, so the file only needs to contain the fields to override.
+func loadDefaultNodeSLOSpecFromFile(path string) (*slov1alpha1.NodeSLOSpec, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	override := &slov1alpha1.NodeSLOSpec{}
+	if err = json.Unmarshal(content, override); err != nil {
+		return nil, err
+	}
+	spec := util.DefaultNodeSLOSpecConfig()
+	if _, err = util.MergeCfg(&spec, override); err != nil {
+		return nil, err
+	}
+	if err = validateNodeSLOSpec(&spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
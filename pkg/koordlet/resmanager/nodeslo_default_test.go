@@ -0,0 +1,100 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+func Test_loadDefaultNodeSLOSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "override the thresholds",
+			content: `{"resourceUsedThresholdWithBE":{"cpuSuppressThresholdPercent":50,"memoryEvictThresholdPercent":80}}`,
+		},
+		{
+			name:    "invalid json",
+			content: `{"resourceUsedThresholdWithBE":`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid spec",
+			content: `{"resourceUsedThresholdWithBE":{"cpuSuppressThresholdPercent":150}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "default-nodeslo-spec.json")
+			assert.NoError(t, ioutil.WriteFile(path, []byte(tt.content), 0644))
+			r := &resmanager{config: &Config{DefaultNodeSLOSpecPath: path}}
+			err := r.loadDefaultNodeSLOSpec()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if tt.wantErr {
+				assert.Equal(t, util.DefaultNodeSLOSpecConfig(), r.getDefaultNodeSLOSpec())
+			}
+		})
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		r := &resmanager{config: NewDefaultConfig()}
+		assert.NoError(t, r.loadDefaultNodeSLOSpec())
+		assert.Equal(t, util.DefaultNodeSLOSpecConfig(), r.getDefaultNodeSLOSpec())
+	})
+
+	t.Run("file not exist", func(t *testing.T) {
+		r := &resmanager{config: &Config{DefaultNodeSLOSpecPath: filepath.Join(t.TempDir(), "not-exist.json")}}
+		assert.Error(t, r.loadDefaultNodeSLOSpec())
+	})
+}
+
+func Test_mergeNodeSLOSpec_defaultOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "default-nodeslo-spec.json")
+	content := `{"resourceUsedThresholdWithBE":{"cpuSuppressThresholdPercent":50,"memoryEvictThresholdPercent":80}}`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	r := &resmanager{config: &Config{DefaultNodeSLOSpecPath: path}}
+	assert.NoError(t, r.loadDefaultNodeSLOSpec())
+
+	r.createNodeSLO(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(90),
+	}))
+	got := r.getNodeSLOCopy().Spec
+	// the unset fields are merged with the override, which is merged with the built-in defaults
+	assert.Equal(t, pointer.Int64Ptr(50), got.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+	assert.Equal(t, pointer.Int64Ptr(90), got.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+	assert.Equal(t, util.DefaultNodeSLOSpecConfig().ResourceUsedThresholdWithBE.Enable, got.ResourceUsedThresholdWithBE.Enable)
+	assert.Equal(t, util.DefaultNodeSLOSpecConfig().ResourceUsedThresholdWithBE.CPUSuppressPolicy, got.ResourceUsedThresholdWithBE.CPUSuppressPolicy)
+
+	// the default NodeSLO applied at startup also uses the override
+	r = &resmanager{config: &Config{DefaultNodeSLOSpecPath: path}}
+	assert.NoError(t, r.loadDefaultNodeSLOSpec())
+	r.applyDefaultNodeSLO()
+	assert.Equal(t, pointer.Int64Ptr(50), r.getNodeSLOCopy().Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+	assert.Equal(t, pointer.Int64Ptr(80), r.getNodeSLOCopy().Spec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+}
@@ -0,0 +1,103 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// diffNodeSLOSpec returns the changed fields between the two specs, each of which is formatted as the json path with
+// the old and new values, e.g. "resourceUsedThresholdWithBE.cpuSuppressThresholdPercent: 65 -> 80".
+func diffNodeSLOSpec(oldSpec, newSpec *slov1alpha1.NodeSLOSpec) []string {
+	var diffs []string
+	diffValue("", reflect.ValueOf(oldSpec), reflect.ValueOf(newSpec), &diffs)
+	return diffs
+}
+
+// formatNodeSLOSpecDiff returns the changed fields between the two specs in one line for logging.
+func formatNodeSLOSpecDiff(oldSpec, newSpec *slov1alpha1.NodeSLOSpec) string {
+	diffs := diffNodeSLOSpec(oldSpec, newSpec)
+	if len(diffs) == 0 {
+		return "no change"
+	}
+	return strings.Join(diffs, ", ")
+}
+
+// logNodeSLOChange logs the changed fields of the merged nodeSLO spec, while the full content is only logged with a
+// high verbosity since it is enormous.
+func logNodeSLOChange(action string, oldNodeSLO, newNodeSLO *slov1alpha1.NodeSLO) {
+	if oldNodeSLO == nil {
+		klog.Infof("%s nodeSLO %s", action, newNodeSLO.Name)
+	} else {
+		klog.Infof("%s nodeSLO %s spec, changed fields: %s", action, newNodeSLO.Name,
+			formatNodeSLOSpecDiff(&oldNodeSLO.Spec, &newNodeSLO.Spec))
+	}
+	klog.V(5).Infof("%s nodeSLO content: %s", action, util.DumpJSON(newNodeSLO))
+}
+
+func diffValue(path string, oldValue, newValue reflect.Value, diffs *[]string) {
+	if oldValue.Kind() == reflect.Ptr {
+		if oldValue.IsNil() || newValue.IsNil() {
+			if oldValue.IsNil() != newValue.IsNil() {
+				*diffs = append(*diffs, formatFieldDiff(path, oldValue, newValue))
+			}
+			return
+		}
+		if oldValue.Elem().Kind() == reflect.Struct {
+			oldValue, newValue = oldValue.Elem(), newValue.Elem()
+		}
+	}
+
+	// compare the pointers to the non-struct values as a whole, e.g. *int64
+	if oldValue.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(oldValue.Interface(), newValue.Interface()) {
+			*diffs = append(*diffs, formatFieldDiff(path, oldValue, newValue))
+		}
+		return
+	}
+
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" && field.Anonymous {
+			// the embedded struct is inlined in json
+			diffValue(path, oldValue.Field(i), newValue.Field(i), diffs)
+			continue
+		}
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		diffValue(name, oldValue.Field(i), newValue.Field(i), diffs)
+	}
+}
+
+func formatFieldDiff(path string, oldValue, newValue reflect.Value) string {
+	return fmt.Sprintf("%s: %s -> %s", path, util.DumpJSON(oldValue.Interface()), util.DumpJSON(newValue.Interface()))
+}
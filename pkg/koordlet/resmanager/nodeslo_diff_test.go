@@ -0,0 +1,88 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+func Test_diffNodeSLOSpec(t *testing.T) {
+	oldSpec := util.DefaultNodeSLOSpecConfig()
+	tests := []struct {
+		name   string
+		modify func(spec *slov1alpha1.NodeSLOSpec)
+		want   []string
+	}{
+		{
+			name:   "no change",
+			modify: func(spec *slov1alpha1.NodeSLOSpec) {},
+			want:   nil,
+		},
+		{
+			name: "change nested fields",
+			modify: func(spec *slov1alpha1.NodeSLOSpec) {
+				spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent = pointer.Int64Ptr(80)
+				spec.ResourceUsedThresholdWithBE.CPUSuppressCalcPolicy = slov1alpha1.CPUSuppressCalcByRequest
+				spec.FeatureGates = map[string]bool{"BECPUSuppress": true}
+			},
+			want: []string{
+				"resourceUsedThresholdWithBE.cpuSuppressThresholdPercent: 65 -> 80",
+				`resourceUsedThresholdWithBE.cpuSuppressCalcPolicy: "" -> "request"`,
+				`featureGates: null -> {"BECPUSuppress":true}`,
+			},
+		},
+		{
+			name: "change a field to nil",
+			modify: func(spec *slov1alpha1.NodeSLOSpec) {
+				spec.CPUBurstStrategy.CFSQuotaBurstPercent = nil
+				spec.ResourceQoSStrategy = nil
+			},
+			want: []string{
+				"resourceQoSStrategy: " + util.DumpJSON(oldSpec.ResourceQoSStrategy) + " -> null",
+				"cpuBurstStrategy.cfsQuotaBurstPercent: 300 -> null",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newSpec := oldSpec.DeepCopy()
+			tt.modify(newSpec)
+			assert.Equal(t, tt.want, diffNodeSLOSpec(&oldSpec, newSpec))
+		})
+	}
+}
+
+func Test_formatNodeSLOSpecDiff(t *testing.T) {
+	oldSpec := util.DefaultNodeSLOSpecConfig()
+	assert.Equal(t, "no change", formatNodeSLOSpecDiff(&oldSpec, oldSpec.DeepCopy()))
+
+	// a representative update only logs the changed fields instead of the whole spec
+	newSpec := oldSpec.DeepCopy()
+	newSpec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent = pointer.Int64Ptr(80)
+	newSpec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent = pointer.Int64Ptr(75)
+	got := formatNodeSLOSpecDiff(&oldSpec, newSpec)
+	assert.Equal(t, "resourceUsedThresholdWithBE.cpuSuppressThresholdPercent: 65 -> 80, "+
+		"resourceUsedThresholdWithBE.memoryEvictThresholdPercent: 70 -> 75", got)
+	assert.NotContains(t, got, "resourceQoSStrategy")
+	assert.NotContains(t, got, "cpuBurstStrategy")
+}
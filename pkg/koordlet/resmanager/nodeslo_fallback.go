@@ -0,0 +1,160 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// waitForNodeSLOSync waits for the NodeSLO informer to sync. If the last-known-good NodeSLO can be loaded from the
+// fallback file, it returns immediately so that the enforcement continues when the apiserver is unavailable.
+func (r *resmanager) waitForNodeSLOSync(stopCh <-chan struct{}) error {
+	if r.loadFallbackNodeSLO() {
+		klog.Infof("load NodeSLO from fallback file %s, skip waiting for node slo caches to sync",
+			r.config.NodeSLOFallbackPath)
+		return nil
+	}
+	if !cache.WaitForCacheSync(stopCh, r.nodeSLOInformer.HasSynced) {
+		return fmt.Errorf("time out waiting for node slo caches to sync")
+	}
+	return nil
+}
+
+// waitForNodeSLO waits until the NodeSLO of the node is received. During the cold start the NodeSLO may not be created
+// yet, so if it is still absent after the timeout, the default NodeSLO spec is applied to start the enforcement. The
+// defaults are superseded once the NodeSLO is received. It waits until stopped if the timeout is not positive.
+func (r *resmanager) waitForNodeSLO(stopCh <-chan struct{}, timeout time.Duration) error {
+	if timeout <= 0 {
+		if !cache.WaitForCacheSync(stopCh, r.hasSynced) {
+			return fmt.Errorf("time out waiting for sync NodeSLO")
+		}
+		return nil
+	}
+	err := wait.PollImmediate(100*time.Millisecond, timeout, func() (bool, error) {
+		select {
+		case <-stopCh:
+			return false, fmt.Errorf("stopped waiting for sync NodeSLO")
+		default:
+		}
+		return r.hasSynced(), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		r.applyDefaultNodeSLO()
+		return nil
+	}
+	return err
+}
+
+// applyDefaultNodeSLO applies the default NodeSLO spec if no NodeSLO is received yet.
+func (r *resmanager) applyDefaultNodeSLO() {
+	r.nodeSLORWMutex.Lock()
+	defer r.nodeSLORWMutex.Unlock()
+	if r.nodeSLO != nil && r.nodeSLO.Spec.ResourceUsedThresholdWithBE != nil {
+		return
+	}
+	r.nodeSLO = &slov1alpha1.NodeSLO{
+		ObjectMeta: metav1.ObjectMeta{Name: r.nodeName},
+		Spec:       r.getDefaultNodeSLOSpec(),
+	}
+	r.nodeSLOApply.specChanged()
+	metrics.RecordNodeSLOSpecInfo(hashNodeSLOSpec(&r.nodeSLO.Spec))
+	klog.Warningf("NodeSLO %s is not received at startup, use the default NodeSLO spec until it is received", r.nodeName)
+	klog.V(5).Infof("default nodeSLO content: %s", util.DumpJSON(r.nodeSLO))
+}
+
+// loadFallbackNodeSLO loads the NodeSLO from the fallback file if no NodeSLO is received yet.
+// It returns whether the NodeSLO is loaded.
+func (r *resmanager) loadFallbackNodeSLO() bool {
+	if r.config == nil || r.config.NodeSLOFallbackPath == "" {
+		return false
+	}
+	nodeSLO, err := loadNodeSLOFromFile(r.config.NodeSLOFallbackPath)
+	if err != nil {
+		klog.Warningf("failed to load NodeSLO from fallback file %s, error: %v", r.config.NodeSLOFallbackPath, err)
+		return false
+	}
+
+	r.nodeSLORWMutex.Lock()
+	defer r.nodeSLORWMutex.Unlock()
+	if r.nodeSLO != nil {
+		klog.V(4).Infof("NodeSLO has been received, skip loading from fallback file")
+		return true
+	}
+	r.nodeSLO = nodeSLO.DeepCopy()
+	// merge nodeSLO spec with the default config
+	if err = r.mergeNodeSLOSpec(nodeSLO); err != nil {
+		r.nodeSLO = nil
+		klog.Warningf("failed to merge NodeSLO from fallback file %s, error: %v", r.config.NodeSLOFallbackPath, err)
+		return false
+	}
+	klog.Infof("update nodeSLO content from fallback file: new %s", util.DumpJSON(r.nodeSLO))
+	metrics.RecordNodeSLOSpecInfo(hashNodeSLOSpec(&r.nodeSLO.Spec))
+	return true
+}
+
+// saveFallbackNodeSLO persists the latest received NodeSLO into the fallback file if configured.
+func (r *resmanager) saveFallbackNodeSLO(nodeSLO *slov1alpha1.NodeSLO) {
+	if r.config == nil || r.config.NodeSLOFallbackPath == "" || nodeSLO == nil {
+		return
+	}
+	if err := saveNodeSLOToFile(r.config.NodeSLOFallbackPath, nodeSLO); err != nil {
+		klog.Warningf("failed to save NodeSLO to fallback file %s, error: %v", r.config.NodeSLOFallbackPath, err)
+	}
+}
+
+func loadNodeSLOFromFile(path string) (*slov1alpha1.NodeSLO, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	nodeSLO := &slov1alpha1.NodeSLO{}
+	if err = json.Unmarshal(content, nodeSLO); err != nil {
+		return nil, err
+	}
+	return nodeSLO, nil
+}
+
+// saveNodeSLOToFile writes the NodeSLO into a temporary file and renames it, so a crash during the writing never
+// corrupts the last-known-good config.
+func saveNodeSLOToFile(path string, nodeSLO *slov1alpha1.NodeSLO) error {
+	content, err := json.Marshal(nodeSLO)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
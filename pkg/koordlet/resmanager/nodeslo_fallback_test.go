@@ -0,0 +1,220 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+func Test_loadFallbackNodeSLO(t *testing.T) {
+	fallbackPath := filepath.Join(t.TempDir(), "nodeslo.json")
+	nodeSLO := getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+	})
+	nodeSLO.Name = "test-node"
+
+	t.Run("fallback is not configured", func(t *testing.T) {
+		r := &resmanager{config: NewDefaultConfig()}
+		assert.False(t, r.loadFallbackNodeSLO())
+		assert.Nil(t, r.getNodeSLOCopy())
+	})
+
+	t.Run("fallback file not exist", func(t *testing.T) {
+		r := &resmanager{config: &Config{NodeSLOFallbackPath: fallbackPath}}
+		assert.False(t, r.loadFallbackNodeSLO())
+		assert.Nil(t, r.getNodeSLOCopy())
+	})
+
+	t.Run("load on start", func(t *testing.T) {
+		assert.NoError(t, saveNodeSLOToFile(fallbackPath, nodeSLO))
+		r := &resmanager{config: &Config{NodeSLOFallbackPath: fallbackPath}}
+		assert.True(t, r.loadFallbackNodeSLO())
+		got := r.getNodeSLOCopy()
+		assert.NotNil(t, got)
+		assert.Equal(t, "test-node", got.Name)
+		assert.Equal(t, pointer.Int64Ptr(80), got.Spec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+		// merged with the default config
+		assert.NotNil(t, got.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+		assert.True(t, r.hasSynced())
+	})
+
+	t.Run("received NodeSLO is not overwritten", func(t *testing.T) {
+		assert.NoError(t, saveNodeSLOToFile(fallbackPath, nodeSLO))
+		r := &resmanager{config: &Config{}}
+		received := getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+			Enable:                      pointer.BoolPtr(true),
+			MemoryEvictThresholdPercent: pointer.Int64Ptr(60),
+		})
+		r.createNodeSLO(received)
+		r.config.NodeSLOFallbackPath = fallbackPath
+		assert.True(t, r.loadFallbackNodeSLO())
+		assert.Equal(t, pointer.Int64Ptr(60), r.getNodeSLOCopy().Spec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+	})
+}
+
+func Test_saveFallbackNodeSLO(t *testing.T) {
+	fallbackPath := filepath.Join(t.TempDir(), "fallback", "nodeslo.json")
+	r := &resmanager{config: &Config{NodeSLOFallbackPath: fallbackPath}}
+
+	r.createNodeSLO(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+	}))
+	got, err := loadNodeSLOFromFile(fallbackPath)
+	assert.NoError(t, err)
+	assert.Equal(t, pointer.Int64Ptr(80), got.Spec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+	// persist the received spec rather than the merged one
+	assert.Nil(t, got.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+
+	r.updateNodeSLOSpec(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		MemoryEvictThresholdPercent: pointer.Int64Ptr(70),
+	}))
+	got, err = loadNodeSLOFromFile(fallbackPath)
+	assert.NoError(t, err)
+	assert.Equal(t, pointer.Int64Ptr(70), got.Spec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+}
+
+func Test_waitForNodeSLOSync(t *testing.T) {
+	newFailedInformer := func() cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (apiruntime.Object, error) {
+					return nil, fmt.Errorf("apiserver is unavailable")
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return nil, fmt.Errorf("apiserver is unavailable")
+				},
+			},
+			&slov1alpha1.NodeSLO{},
+			time.Hour,
+			cache.Indexers{},
+		)
+	}
+
+	t.Run("fallback on list failure", func(t *testing.T) {
+		fallbackPath := filepath.Join(t.TempDir(), "nodeslo.json")
+		assert.NoError(t, saveNodeSLOToFile(fallbackPath, getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+			Enable:                      pointer.BoolPtr(true),
+			MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+		})))
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		r := &resmanager{config: &Config{NodeSLOFallbackPath: fallbackPath}, nodeSLOInformer: newFailedInformer()}
+		go r.nodeSLOInformer.Run(stopCh)
+
+		assert.NoError(t, r.waitForNodeSLOSync(stopCh))
+		assert.True(t, r.hasSynced())
+		assert.Equal(t, pointer.Int64Ptr(80), r.getNodeSLOCopy().Spec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+	})
+
+	t.Run("wait until stopped without fallback", func(t *testing.T) {
+		stopCh := make(chan struct{})
+		r := &resmanager{config: NewDefaultConfig(), nodeSLOInformer: newFailedInformer()}
+		go r.nodeSLOInformer.Run(stopCh)
+		time.AfterFunc(200*time.Millisecond, func() { close(stopCh) })
+
+		assert.Error(t, r.waitForNodeSLOSync(stopCh))
+		assert.False(t, r.hasSynced())
+	})
+}
+
+func Test_waitForNodeSLO(t *testing.T) {
+	t.Run("apply defaults if nodeSLO is absent past the timeout", func(t *testing.T) {
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		r := &resmanager{config: NewDefaultConfig(), nodeName: "test-node"}
+
+		assert.NoError(t, r.waitForNodeSLO(stopCh, 200*time.Millisecond))
+		assert.True(t, r.hasSynced())
+		nodeSLO := r.getNodeSLOCopy()
+		assert.Equal(t, "test-node", nodeSLO.Name)
+		assert.Equal(t, util.DefaultNodeSLOSpecConfig(), nodeSLO.Spec)
+
+		// the defaults are superseded once the nodeSLO appears
+		r.createNodeSLO(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+			Enable:                      pointer.BoolPtr(true),
+			MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+		}))
+		assert.Equal(t, pointer.Int64Ptr(80), r.getNodeSLOCopy().Spec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+	})
+
+	t.Run("record the default spec as applied", func(t *testing.T) {
+		testingNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+		metrics.Register(testingNode)
+		defer metrics.Register(nil)
+		metrics.NodeSLOSpecInfo.Reset()
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		r := &resmanager{config: NewDefaultConfig(), nodeName: "test-node", nodeSLOApply: newNodeSLOApplyTracker()}
+
+		assert.NoError(t, r.waitForNodeSLO(stopCh, 200*time.Millisecond))
+		assert.Equal(t, int64(1), r.nodeSLOApply.currentGeneration())
+		specHash := hashNodeSLOSpec(&r.getNodeSLOCopy().Spec)
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.NodeSLOSpecInfo.WithLabelValues(testingNode.Name, specHash)))
+	})
+
+	t.Run("keep the nodeSLO received before the timeout", func(t *testing.T) {
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		r := &resmanager{config: NewDefaultConfig(), nodeName: "test-node"}
+		time.AfterFunc(100*time.Millisecond, func() {
+			r.createNodeSLO(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+				Enable:                      pointer.BoolPtr(true),
+				MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+			}))
+		})
+
+		assert.NoError(t, r.waitForNodeSLO(stopCh, 10*time.Second))
+		assert.Equal(t, pointer.Int64Ptr(80), r.getNodeSLOCopy().Spec.ResourceUsedThresholdWithBE.MemoryEvictThresholdPercent)
+	})
+
+	t.Run("wait until stopped without timeout", func(t *testing.T) {
+		stopCh := make(chan struct{})
+		r := &resmanager{config: NewDefaultConfig(), nodeName: "test-node"}
+		time.AfterFunc(200*time.Millisecond, func() { close(stopCh) })
+
+		assert.Error(t, r.waitForNodeSLO(stopCh, 0))
+		assert.False(t, r.hasSynced())
+	})
+
+	t.Run("stop before the timeout", func(t *testing.T) {
+		stopCh := make(chan struct{})
+		r := &resmanager{config: NewDefaultConfig(), nodeName: "test-node"}
+		time.AfterFunc(200*time.Millisecond, func() { close(stopCh) })
+
+		assert.Error(t, r.waitForNodeSLO(stopCh, 10*time.Second))
+		assert.False(t, r.hasSynced())
+	})
+}
@@ -0,0 +1,130 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	expireCache "github.com/koordinator-sh/koordinator/pkg/tools/cache"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// OOMQuarantine evicts the BE pods which are oom killed repeatedly, since a pod restart-looping by the oom kills
+// wastes the resources and churns the node.
+type OOMQuarantine struct {
+	resManager *resmanager
+	// podOOMRecords records the oom kills of the BE pods keyed by pod UID, which expire if not updated in the window
+	podOOMRecords *expireCache.Cache
+	clock         clock.Clock
+}
+
+// podOOMRecord is the oom kills of a pod observed within the window
+type podOOMRecord struct {
+	// lastOOMKill is the oom kill count in the memory.oom_control of the pod cgroup at the last read
+	lastOOMKill int64
+	// oomKillTimes are the times when the oom kills are observed
+	oomKillTimes []time.Time
+}
+
+func NewOOMQuarantine(mgr *resmanager) *OOMQuarantine {
+	window := time.Duration(mgr.config.OOMQuarantineWindowSeconds) * time.Second
+	return &OOMQuarantine{
+		resManager:    mgr,
+		podOOMRecords: expireCache.NewCache(window, time.Minute),
+		clock:         clock.RealClock{},
+	}
+}
+
+func (q *OOMQuarantine) RunInit(stopCh <-chan struct{}) error {
+	return q.podOOMRecords.Run(stopCh)
+}
+
+func (q *OOMQuarantine) quarantine() {
+	killCount := q.resManager.config.OOMQuarantineKillCount
+	if killCount <= 0 {
+		klog.V(5).Infof("skip oom quarantine, kill count %v is not positive", killCount)
+		return
+	}
+	node := q.resManager.statesInformer.GetNode()
+	if node == nil {
+		klog.Warningf("skip oom quarantine, Node %v is nil", q.resManager.nodeName)
+		return
+	}
+
+	for _, podMeta := range q.resManager.statesInformer.GetAllPods() {
+		if podMeta == nil || podMeta.Pod == nil {
+			continue
+		}
+		pod := podMeta.Pod
+		if pod.Status.Phase != corev1.PodRunning || apiext.GetPodQoSClass(pod) != apiext.QoSBE ||
+			!q.resManager.isEnforcementEligible(pod) {
+			continue
+		}
+		oomKills := q.updatePodOOMKills(podMeta)
+		if oomKills < killCount {
+			continue
+		}
+		message := fmt.Sprintf("quarantine pod %v/%v for node(%v), oom killed %v times within %vs, threshold %v times",
+			pod.Namespace, pod.Name, q.resManager.nodeName, oomKills, q.resManager.config.OOMQuarantineWindowSeconds,
+			killCount)
+		klog.Infof("%v, evict it", message)
+		q.resManager.evictPodIfNotEvicted(pod, node, evictPodByRepeatedOOM, message)
+		q.resManager.decisionLog.record(features.BEOOMQuarantine, fmt.Sprintf("pod=%v/%v, oomKills=%v",
+			pod.Namespace, pod.Name, oomKills), "evict the repeatedly oom killed pod")
+	}
+}
+
+// updatePodOOMKills reads the oom kill count of the pod cgroup, and returns the number of the oom kills observed
+// within the window. The first read only takes the baseline, and a decreased count, e.g. the cgroup is recreated,
+// resets the baseline.
+func (q *OOMQuarantine) updatePodOOMKills(podMeta *statesinformer.PodMeta) int {
+	pod := podMeta.Pod
+	oomControl, err := util.GetCgroupMemOOMControl(util.GetPodCgroupDirWithKube(podMeta.CgroupDir))
+	if err != nil {
+		klog.Warningf("failed to read memory oom control of pod %s, error: %v", util.GetPodKey(pod), err)
+		return 0
+	}
+
+	now := q.clock.Now()
+	record := &podOOMRecord{lastOOMKill: oomControl.OOMKill}
+	if value, ok := q.podOOMRecords.Get(string(pod.UID)); ok {
+		lastRecord := value.(*podOOMRecord)
+		if oomControl.OOMKill >= lastRecord.lastOOMKill {
+			window := time.Duration(q.resManager.config.OOMQuarantineWindowSeconds) * time.Second
+			for _, oomKillTime := range lastRecord.oomKillTimes {
+				if now.Sub(oomKillTime) <= window {
+					record.oomKillTimes = append(record.oomKillTimes, oomKillTime)
+				}
+			}
+			// the oom kills are more than enough to quarantine the pod if the increase exceeds the kill count
+			increased := util.MinInt64(oomControl.OOMKill-lastRecord.lastOOMKill, int64(q.resManager.config.OOMQuarantineKillCount))
+			for i := int64(0); i < increased; i++ {
+				record.oomKillTimes = append(record.oomKillTimes, now)
+			}
+		}
+	}
+	_ = q.podOOMRecords.SetDefault(string(pod.UID), record)
+	return len(record.oomKillTimes)
+}
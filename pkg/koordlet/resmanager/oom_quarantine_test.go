@@ -0,0 +1,161 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	testingclock "k8s.io/utils/clock/testing"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/tools/cache"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+func TestOOMQuarantine_quarantine(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	newPodMeta := func(qosClass apiext.QoSClass, name string) *statesinformer.PodMeta {
+		pod := createTestPod(qosClass, name)
+		pod.Status.Phase = corev1.PodRunning
+		return &statesinformer.PodMeta{Pod: pod, CgroupDir: util.GetPodKubeRelativePath(pod)}
+	}
+	writeOOMKills := func(podMeta *statesinformer.PodMeta, oomKills int) {
+		helper.WriteCgroupFileContents(util.GetPodCgroupDirWithKube(podMeta.CgroupDir), system.MemOomControl,
+			fmt.Sprintf("oom_kill_disable 0\nunder_oom 0\noom_kill %d\n", oomKills))
+	}
+	// repeatedPod is oom killed every minute, sparsePod every 6 minutes, and lsPod is not a BE pod
+	repeatedPod := newPodMeta(apiext.QoSBE, "test_be_pod_repeated")
+	sparsePod := newPodMeta(apiext.QoSBE, "test_be_pod_sparse")
+	lsPod := newPodMeta(apiext.QoSLS, "test_ls_pod")
+	podMetas := []*statesinformer.PodMeta{repeatedPod, sparsePod, lsPod}
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetNode().Return(getNode("80", "120G")).AnyTimes()
+	mockStatesInformer.EXPECT().GetAllPods().Return(podMetas).AnyTimes()
+
+	var evictedPods []string
+	client := clientsetfake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		evictedPods = append(evictedPods, eviction.Name)
+		return true, nil, nil
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	cfg := NewDefaultConfig()
+	podsEvicted := cache.NewCacheDefault()
+	_ = podsEvicted.Run(stop)
+	r := &resmanager{
+		config:         cfg,
+		statesInformer: mockStatesInformer,
+		eventRecorder:  &FakeRecorder{},
+		kubeClient:     client,
+		podsEvicted:    podsEvicted,
+	}
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	q := NewOOMQuarantine(r)
+	q.clock = fakeClock
+	q.podOOMRecords = cache.NewCacheWithClock(time.Duration(cfg.OOMQuarantineWindowSeconds)*time.Second, time.Minute, fakeClock)
+	assert.NoError(t, q.RunInit(stop))
+
+	for minute := 0; minute <= 18; minute++ {
+		writeOOMKills(repeatedPod, minute)
+		writeOOMKills(sparsePod, minute/6)
+		writeOOMKills(lsPod, minute)
+		q.quarantine()
+		if minute < cfg.OOMQuarantineKillCount {
+			// the first read only takes the baseline
+			assert.Empty(t, evictedPods, "minute %v", minute)
+		}
+		fakeClock.Step(time.Minute)
+	}
+	assert.Equal(t, []string{repeatedPod.Pod.Name}, evictedPods)
+}
+
+func TestOOMQuarantine_updatePodOOMKills(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	podMeta := &statesinformer.PodMeta{Pod: pod, CgroupDir: util.GetPodKubeRelativePath(pod)}
+	writeOOMKills := func(oomKills int) {
+		helper.WriteCgroupFileContents(util.GetPodCgroupDirWithKube(podMeta.CgroupDir), system.MemOomControl,
+			fmt.Sprintf("oom_kill_disable 0\nunder_oom 0\noom_kill %d\n", oomKills))
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	cfg := NewDefaultConfig()
+	window := time.Duration(cfg.OOMQuarantineWindowSeconds) * time.Second
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	q := NewOOMQuarantine(&resmanager{config: cfg})
+	q.clock = fakeClock
+	q.podOOMRecords = cache.NewCacheWithClock(window, time.Minute, fakeClock)
+	assert.NoError(t, q.RunInit(stop))
+
+	// no memory oom control
+	assert.Equal(t, 0, q.updatePodOOMKills(podMeta))
+	// take the baseline
+	writeOOMKills(5)
+	assert.Equal(t, 0, q.updatePodOOMKills(podMeta))
+	writeOOMKills(6)
+	assert.Equal(t, 1, q.updatePodOOMKills(podMeta))
+	// the increase is capped by the kill count
+	writeOOMKills(106)
+	assert.Equal(t, 1+cfg.OOMQuarantineKillCount, q.updatePodOOMKills(podMeta))
+	// the oom kills out of the window are dropped
+	fakeClock.Step(window / 2)
+	writeOOMKills(107)
+	assert.Equal(t, 2+cfg.OOMQuarantineKillCount, q.updatePodOOMKills(podMeta))
+	fakeClock.Step(window/2 + time.Second)
+	assert.Equal(t, 1, q.updatePodOOMKills(podMeta))
+	// a decreased count resets the baseline
+	writeOOMKills(1)
+	assert.Equal(t, 0, q.updatePodOOMKills(podMeta))
+	writeOOMKills(2)
+	assert.Equal(t, 1, q.updatePodOOMKills(podMeta))
+	// a failed read keeps the baseline
+	helper.WriteCgroupFileContents(util.GetPodCgroupDirWithKube(podMeta.CgroupDir), system.MemOomControl,
+		"oom_kill_disable 0\nunder_oom 0\n")
+	assert.Equal(t, 0, q.updatePodOOMKills(podMeta))
+	writeOOMKills(3)
+	assert.Equal(t, 2, q.updatePodOOMKills(podMeta))
+	// the record expires if not updated in the window
+	fakeClock.Step(window + time.Second)
+	writeOOMKills(3)
+	assert.Equal(t, 0, q.updatePodOOMKills(podMeta))
+}
@@ -0,0 +1,65 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+// nodePressureState coordinates the feature loops under the node pressure. The higher priority features, e.g. the
+// memory eviction, mark the pressure active, and the lower priority ones, e.g. the cpu burst, back off while it is
+// active. The pressure expires after its ttl, so a feature stopped or paused under the pressure never leaves it active
+// forever. A nil nodePressureState is valid and is never active.
+type nodePressureState struct {
+	lock  sync.RWMutex
+	clock clock.Clock
+	// memoryUntil is the time the memory pressure marked by the memory eviction expires
+	memoryUntil time.Time
+}
+
+func newNodePressureState(cfg *Config) *nodePressureState {
+	if !cfg.CPUBurstBackOffOnMemoryEvict {
+		return nil
+	}
+	return &nodePressureState{clock: clock.RealClock{}}
+}
+
+// setMemoryPressure marks the memory pressure active for the ttl, or clears it if the ttl is not positive.
+func (s *nodePressureState) setMemoryPressure(ttl time.Duration) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if ttl <= 0 {
+		s.memoryUntil = time.Time{}
+		return
+	}
+	s.memoryUntil = s.clock.Now().Add(ttl)
+}
+
+func (s *nodePressureState) isMemoryPressureActive() bool {
+	if s == nil {
+		return false
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.clock.Now().Before(s.memoryUntil)
+}
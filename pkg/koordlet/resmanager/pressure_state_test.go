@@ -0,0 +1,54 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func Test_nodePressureState(t *testing.T) {
+	// disabled by default
+	assert.Nil(t, newNodePressureState(NewDefaultConfig()))
+	var nilState *nodePressureState
+	nilState.setMemoryPressure(time.Minute)
+	assert.False(t, nilState.isMemoryPressureActive())
+
+	cfg := NewDefaultConfig()
+	cfg.CPUBurstBackOffOnMemoryEvict = true
+	s := newNodePressureState(cfg)
+	assert.NotNil(t, s)
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	s.clock = fakeClock
+	assert.False(t, s.isMemoryPressureActive())
+
+	s.setMemoryPressure(10 * time.Second)
+	assert.True(t, s.isMemoryPressureActive())
+	fakeClock.Step(9 * time.Second)
+	assert.True(t, s.isMemoryPressureActive())
+	// the pressure expires if not marked again
+	fakeClock.Step(time.Second)
+	assert.False(t, s.isMemoryPressureActive())
+
+	s.setMemoryPressure(10 * time.Second)
+	assert.True(t, s.isMemoryPressureActive())
+	s.setMemoryPressure(0)
+	assert.False(t, s.isMemoryPressureActive())
+}
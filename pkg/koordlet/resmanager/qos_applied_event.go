@@ -0,0 +1,131 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+const (
+	qosAppliedReason = "qosApplied"
+)
+
+// qosAppliedEvents records a Normal event on the pod when its qos cgroup values are first applied or changed, at most
+// once per interval for each pod. A nil qosAppliedEvents is valid and records nothing.
+type qosAppliedEvents struct {
+	lock     sync.Mutex
+	clock    clock.Clock
+	interval time.Duration
+	recorder record.EventRecorder
+	// records is the last recorded summary of each pod by the pod uid
+	records map[string]qosAppliedRecord
+}
+
+type qosAppliedRecord struct {
+	summary string
+	time    time.Time
+}
+
+// newQoSAppliedEvents returns a qosAppliedEvents recording at most once per intervalSeconds for each pod, or nil if
+// intervalSeconds is not positive.
+func newQoSAppliedEvents(recorder record.EventRecorder, intervalSeconds int, clock clock.Clock) *qosAppliedEvents {
+	if intervalSeconds <= 0 || recorder == nil {
+		return nil
+	}
+	return &qosAppliedEvents{
+		clock:    clock,
+		interval: time.Duration(intervalSeconds) * time.Second,
+		recorder: recorder,
+		records:  map[string]qosAppliedRecord{},
+	}
+}
+
+// record records the event of the applied summary for the pod, and returns whether the event is recorded. The event
+// is skipped if the summary is unchanged or the last event of the pod is within the interval; the skipped summary is
+// recorded by a later call after the interval if it still differs.
+func (e *qosAppliedEvents) record(pod *corev1.Pod, summary string) bool {
+	if e == nil || pod == nil || summary == "" {
+		return false
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	now := e.clock.Now()
+	last, ok := e.records[string(pod.UID)]
+	if ok && (last.summary == summary || now.Sub(last.time) < e.interval) {
+		return false
+	}
+	e.records[string(pod.UID)] = qosAppliedRecord{summary: summary, time: now}
+	e.recorder.Eventf(pod, corev1.EventTypeNormal, qosAppliedReason, "koordlet applied qos cgroup settings: %s", summary)
+	return true
+}
+
+// prune forgets the records of the pods not in podMetas, e.g. the deleted pods.
+func (e *qosAppliedEvents) prune(podMetas []*statesinformer.PodMeta) {
+	if e == nil {
+		return
+	}
+	uids := make(map[string]struct{}, len(podMetas))
+	for _, podMeta := range podMetas {
+		uids[string(podMeta.Pod.UID)] = struct{}{}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for uid := range e.records {
+		if _, ok := uids[uid]; !ok {
+			delete(e.records, uid)
+		}
+	}
+}
+
+// summarizeAppliedPodQoS summarizes the memory.min, memory.low, memory.high and cpu values of the pod-level and
+// container-level resources of a pod in the order of the resources, e.g.
+// "memory.min=1048576, main/memory.min=1048576, main/memory.high=max".
+func summarizeAppliedPodQoS(resources []MergeableResourceUpdater) string {
+	var entries []string
+	for _, resource := range resources {
+		cgroupResource, ok := resource.(*CgroupResourceUpdater)
+		if !ok || cgroupResource.owner == nil {
+			continue
+		}
+		fileName := cgroupResource.file.ResourceFileName
+		if fileName != system.MemMinFileName && fileName != system.MemLowFileName &&
+			fileName != system.MemHighFileName && getCgroupResourceType(cgroupResource) != metrics.CgroupReconcileResourceCPU {
+			continue
+		}
+		value := cgroupResource.Value()
+		if value == strconv.FormatInt(math.MaxInt64, 10) {
+			value = "max"
+		}
+		if cgroupResource.owner.Container != "" {
+			fileName = cgroupResource.owner.Container + "/" + fileName
+		}
+		entries = append(entries, fileName+"="+value)
+	}
+	return strings.Join(entries, ", ")
+}
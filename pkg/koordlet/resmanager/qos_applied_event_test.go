@@ -0,0 +1,181 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+func Test_qosAppliedEvents_record(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default", UID: "test-pod-uid"}}
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	recorder := record.NewFakeRecorder(10)
+	e := newQoSAppliedEvents(recorder, 60, fakeClock)
+
+	// first applied
+	assert.True(t, e.record(pod, "memory.min=100"))
+	assert.Equal(t, "Normal qosApplied koordlet applied qos cgroup settings: memory.min=100", <-recorder.Events)
+	// unchanged
+	fakeClock.Step(2 * time.Minute)
+	assert.False(t, e.record(pod, "memory.min=100"))
+	// changed, while the last event is beyond the interval
+	assert.True(t, e.record(pod, "memory.min=200"))
+	assert.Equal(t, "Normal qosApplied koordlet applied qos cgroup settings: memory.min=200", <-recorder.Events)
+	// changed within the interval
+	fakeClock.Step(30 * time.Second)
+	assert.False(t, e.record(pod, "memory.min=300"))
+	assert.False(t, e.record(pod, "memory.min=400"))
+	// the latest summary is recorded after the interval
+	fakeClock.Step(30 * time.Second)
+	assert.True(t, e.record(pod, "memory.min=400"))
+	assert.Equal(t, "Normal qosApplied koordlet applied qos cgroup settings: memory.min=400", <-recorder.Events)
+	// no summary to record
+	assert.False(t, e.record(pod, ""))
+	assert.Len(t, recorder.Events, 0)
+
+	// forget the deleted pods
+	e.prune(nil)
+	assert.Len(t, e.records, 0)
+
+	t.Run("disabled", func(t *testing.T) {
+		e := newQoSAppliedEvents(recorder, 0, fakeClock)
+		assert.Nil(t, e)
+		assert.False(t, e.record(pod, "memory.min=100"))
+		e.prune(nil)
+		assert.Len(t, recorder.Events, 0)
+	})
+}
+
+func Test_summarizeAppliedPodQoS(t *testing.T) {
+	maxValue := strconv.FormatInt(math.MaxInt64, 10)
+	resources := []MergeableResourceUpdater{
+		NewMergeableCgroupResourceUpdater(PodOwnerRef("default", "test-pod"), "pod", system.MemMin, "100",
+			mergeFuncUpdateCgroupIfLarger),
+		NewCommonCgroupResourceUpdater(PodOwnerRef("default", "test-pod"), "pod", system.MemWmarkRatio, "95"),
+		NewMergeableCgroupResourceUpdater(ContainerOwnerRef("default", "test-pod", "main"), "pod/main", system.MemLow,
+			"100", mergeFuncUpdateCgroupIfLarger),
+		NewMergeableCgroupResourceUpdater(ContainerOwnerRef("default", "test-pod", "main"), "pod/main", system.MemHigh,
+			maxValue, mergeFuncUpdateCgroupIfLarger),
+		NewCommonCgroupResourceUpdater(ContainerOwnerRef("default", "test-pod", "main"), "pod/main", system.CPUBurst, "2000"),
+	}
+	assert.Equal(t, "memory.min=100, main/memory.low=100, main/memory.high=max, main/cpu.cfs_burst_us=2000",
+		summarizeAppliedPodQoS(resources))
+	assert.Equal(t, "", summarizeAppliedPodQoS(nil))
+}
+
+func TestCgroupResourcesReconcile_qosAppliedEvent(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+	}
+	testingStrategy := &slov1alpha1.ResourceQoSStrategy{
+		LS: &slov1alpha1.ResourceQoS{
+			MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+				Enable: pointer.BoolPtr(true),
+				MemoryQoS: slov1alpha1.MemoryQoS{
+					MinLimitPercent: pointer.Int64Ptr(100),
+				},
+			},
+		},
+	}
+	testingPod := createPod(corev1.PodQOSBurstable, apiext.QoSLS)
+	podDir := util.GetPodCgroupDirWithKube(testingPod.CgroupDir)
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldIsAnolisOS := system.HostSystemInfo.IsAnolisOS
+	system.HostSystemInfo.IsAnolisOS = true
+	defer func() {
+		system.HostSystemInfo.IsAnolisOS = oldIsAnolisOS
+	}()
+	helper.WriteCgroupFileContents(podDir, system.MemMin, "0")
+	for i := range testingPod.Pod.Status.ContainerStatuses {
+		containerDir, _ := util.GetContainerCgroupPathWithKube(testingPod.CgroupDir, &testingPod.Pod.Status.ContainerStatuses[i])
+		helper.WriteCgroupFileContents(containerDir, system.MemMin, "0")
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	si := mockstatesinformer.NewMockStatesInformer(ctrl)
+	si.EXPECT().GetNode().Return(testingNode).AnyTimes()
+	si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{testingPod}).AnyTimes()
+	recorder := record.NewFakeRecorder(10)
+	resmgr := &resmanager{
+		config:         &Config{ReconcileIntervalSeconds: 3600, QoSAppliedEventIntervalSeconds: 60},
+		statesInformer: si,
+		nodeSLO:        createNodeSLOWithQoSStrategy(testingStrategy),
+		eventRecorder:  recorder,
+	}
+	reconciler := NewCgroupResourcesReconcile(resmgr)
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	reconciler.qosAppliedEvents.clock = fakeClock
+	stop := make(chan struct{})
+	defer close(stop)
+	reconciler.executor.Run(stop)
+
+	// first applied
+	assert.NoError(t, reconciler.ReconcilePod(string(testingPod.Pod.UID)))
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Normal qosApplied koordlet applied qos cgroup settings: memory.min=1073741824, test/memory.min=0, "+
+		"main/memory.min=1073741824", <-recorder.Events)
+
+	// unchanged
+	reconciler.calculateAndUpdateResources(resmgr.getNodeSLOCopy())
+	assert.Len(t, recorder.Events, 0)
+
+	// changed within the interval
+	resmgr.nodeSLO.Spec.ResourceQoSStrategy.LS.MemoryQoS.MinLimitPercent = pointer.Int64Ptr(50)
+	assert.NoError(t, reconciler.ReconcilePod(string(testingPod.Pod.UID)))
+	assert.Len(t, recorder.Events, 0)
+
+	// changed after the interval
+	fakeClock.Step(time.Minute)
+	reconciler.calculateAndUpdateResources(resmgr.getNodeSLOCopy())
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Normal qosApplied koordlet applied qos cgroup settings: memory.min=536870912, test/memory.min=0, "+
+		"main/memory.min=536870912", <-recorder.Events)
+
+	// disabled by default
+	resmgr.config = NewDefaultConfig()
+	assert.Nil(t, NewCgroupResourcesReconcile(resmgr).qosAppliedEvents)
+}
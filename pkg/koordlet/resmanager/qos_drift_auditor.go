@@ -0,0 +1,123 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+// QoSDriftAuditor periodically reads back the cgroup values of a sample of pods and compares them with the values
+// implied by the merged NodeSLO. It only reports the drifts via logs and metrics, while the correction is left to
+// the reconcilers.
+type QoSDriftAuditor struct {
+	resmanager      *resmanager
+	cgroupReconcile *CgroupResourcesReconcile
+	samplePods      int
+}
+
+func NewQoSDriftAuditor(resmanager *resmanager) *QoSDriftAuditor {
+	return &QoSDriftAuditor{
+		resmanager:      resmanager,
+		cgroupReconcile: &CgroupResourcesReconcile{resmanager: resmanager},
+		samplePods:      resmanager.config.QoSDriftAuditSamplePods,
+	}
+}
+
+func (a *QoSDriftAuditor) audit() {
+	nodeSLO := a.resmanager.getNodeSLOCopy()
+	if nodeSLO == nil || nodeSLO.Spec.ResourceQoSStrategy == nil {
+		klog.Warningf("skip qos drift audit, nodeSLO or nodeSLO.Spec.ResourceQoSStrategy is nil %v", util.DumpJSON(nodeSLO))
+		return
+	}
+	node := a.resmanager.statesInformer.GetNode()
+	if node == nil || node.Status.Allocatable == nil {
+		klog.Warningf("skip qos drift audit, node is invalid: %v", util.DumpJSON(node))
+		return
+	}
+
+	driftCount := 0
+	allPodMetas := a.resmanager.statesInformer.GetAllPods()
+	// expect the memory.min and memory.high scaled as the reconciliation does
+	totalMemoryMin := a.cgroupReconcile.sumPodsMemoryMin(nodeSLO.Spec.ResourceQoSStrategy, allPodMetas)
+	memoryMinRatio := a.cgroupReconcile.getMemoryMinScaleRatio(totalMemoryMin, node)
+	memoryHighRatio := a.cgroupReconcile.getAppliedMemoryHighScaleRatio(node)
+	podMetas := samplePodMetas(allPodMetas, a.samplePods)
+	for _, podMeta := range podMetas {
+		pod := podMeta.Pod
+		if pod.Status.Phase != corev1.PodRunning || !a.resmanager.isEnforcementEligible(pod) {
+			continue
+		}
+		podQoSCfg := getPodResourceQoSByQoSClass(pod, nodeSLO.Spec.ResourceQoSStrategy, a.resmanager.config)
+		mergedPodCfg, err := a.cgroupReconcile.getMergedPodResourceQoS(pod, podQoSCfg)
+		if err != nil {
+			klog.V(4).Infof("skip qos drift audit for pod %s, failed to retrieve pod resourceQoS, err: %v",
+				util.GetPodKey(pod), err)
+			continue
+		}
+		podResources, containerResources := a.cgroupReconcile.calculatePodAndContainerResources(podMeta, node, mergedPodCfg,
+			memoryMinRatio, memoryHighRatio)
+		for _, resource := range append(podResources, containerResources...) {
+			if isCgroupResourceDrifted(resource) {
+				driftCount++
+			}
+		}
+	}
+	klog.V(5).Infof("finish qos drift audit, sampled pods %v, drifted resources %v", len(podMetas), driftCount)
+}
+
+// samplePodMetas returns at most sampleSize pods randomly; it returns all pods if sampleSize is not positive
+func samplePodMetas(podMetas []*statesinformer.PodMeta, sampleSize int) []*statesinformer.PodMeta {
+	if sampleSize <= 0 || len(podMetas) <= sampleSize {
+		return podMetas
+	}
+	sampled := make([]*statesinformer.PodMeta, len(podMetas))
+	copy(sampled, podMetas)
+	rand.Shuffle(len(sampled), func(i, j int) {
+		sampled[i], sampled[j] = sampled[j], sampled[i]
+	})
+	return sampled[:sampleSize]
+}
+
+// isCgroupResourceDrifted checks if the current cgroup value differs from the expected one, and records the drift
+func isCgroupResourceDrifted(resource MergeableResourceUpdater) bool {
+	cgroupResource, ok := resource.(*CgroupResourceUpdater)
+	if !ok {
+		return false
+	}
+	current, err := system.CgroupFileRead(cgroupResource.ParentDir, cgroupResource.file)
+	if err != nil {
+		klog.V(5).Infof("failed to read cgroup %s for qos drift audit, err: %v", resource.Key(), err)
+		return false
+	}
+	expected := cgroupResource.Value()
+	if current == expected || (current == "max" && expected == strconv.FormatInt(math.MaxInt64, 10)) {
+		return false
+	}
+	klog.Warningf("qos config drift detected for cgroup %s, expect %s, current %s", resource.Key(), expected, current)
+	metrics.RecordQoSConfigDrift(cgroupResource.file.ResourceFileName)
+	return true
+}
@@ -0,0 +1,140 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+func TestQoSDriftAuditor_audit(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+	testingQoSStrategy := &slov1alpha1.ResourceQoSStrategy{
+		LS: &slov1alpha1.ResourceQoS{
+			MemoryQoS: &slov1alpha1.MemoryQoSCfg{
+				Enable: pointer.BoolPtr(true),
+				MemoryQoS: slov1alpha1.MemoryQoS{
+					WmarkRatio:        pointer.Int64Ptr(95),
+					WmarkScalePermill: pointer.Int64Ptr(20),
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                string
+		podWmarkRatio       string
+		podWmarkScaleFactor string
+		wantDrift           float64
+	}{
+		{
+			name:                "no drift when cgroup values are consistent",
+			podWmarkRatio:       "95",
+			podWmarkScaleFactor: "20",
+			wantDrift:           0,
+		},
+		{
+			name:                "record drift of one resource",
+			podWmarkRatio:       "80",
+			podWmarkScaleFactor: "20",
+			wantDrift:           1,
+		},
+		{
+			name:                "record drift of two resources",
+			podWmarkRatio:       "80",
+			podWmarkScaleFactor: "10",
+			wantDrift:           2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+
+			metrics.Register(testingNode)
+			defer metrics.Register(nil)
+			metrics.QoSConfigDrift.Reset()
+
+			podMeta := createPod(corev1.PodQOSBurstable, apiext.QoSLS)
+			podDir := util.GetPodCgroupDirWithKube(podMeta.CgroupDir)
+			helper.WriteCgroupFileContents(podDir, system.MemWmarkRatio, tt.podWmarkRatio)
+			helper.WriteCgroupFileContents(podDir, system.MemWmarkScaleFactor, tt.podWmarkScaleFactor)
+
+			si := mockstatesinformer.NewMockStatesInformer(ctrl)
+			si.EXPECT().GetNode().Return(testingNode).AnyTimes()
+			si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{podMeta}).AnyTimes()
+			resmgr := &resmanager{
+				config:         NewDefaultConfig(),
+				statesInformer: si,
+				nodeSLO:        createNodeSLOWithQoSStrategy(testingQoSStrategy),
+			}
+
+			auditor := NewQoSDriftAuditor(resmgr)
+			auditor.audit()
+
+			gotDrift := testutil.ToFloat64(metrics.QoSConfigDrift.WithLabelValues(testingNode.Name, system.MemWmarkRatio.ResourceFileName)) +
+				testutil.ToFloat64(metrics.QoSConfigDrift.WithLabelValues(testingNode.Name, system.MemWmarkScaleFactor.ResourceFileName))
+			assert.Equal(t, tt.wantDrift, gotDrift)
+
+			// the auditor does not correct the drift
+			gotWmarkRatio := helper.ReadCgroupFileContents(podDir, system.MemWmarkRatio)
+			assert.Equal(t, tt.podWmarkRatio, gotWmarkRatio)
+		})
+	}
+}
+
+func Test_samplePodMetas(t *testing.T) {
+	podMetas := []*statesinformer.PodMeta{
+		createPod(corev1.PodQOSBurstable, apiext.QoSLS),
+		createPod(corev1.PodQOSBestEffort, apiext.QoSBE),
+		createPod(corev1.PodQOSGuaranteed, apiext.QoSLSR),
+	}
+	assert.Equal(t, podMetas, samplePodMetas(podMetas, 0))
+	assert.Equal(t, podMetas, samplePodMetas(podMetas, 3))
+	got := samplePodMetas(podMetas, 2)
+	assert.Equal(t, 2, len(got))
+	for _, podMeta := range got {
+		assert.Contains(t, podMetas, podMeta)
+	}
+}
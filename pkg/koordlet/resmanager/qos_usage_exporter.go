@@ -0,0 +1,92 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+)
+
+const (
+	// qosClassNone is the qos label value of the pods without a koordinator qos class
+	qosClassNone = "NONE"
+)
+
+var (
+	// qosUsageClasses are the qos classes to export the usage, which are exported as zero if there is no pod
+	qosUsageClasses = []apiext.QoSClass{apiext.QoSLSE, apiext.QoSLSR, apiext.QoSLS, apiext.QoSBE, apiext.QoSSystem, apiext.QoSNone}
+)
+
+// QoSUsageExporter exports the aggregate cpu and memory usage of the running pods grouped by the qos class for the
+// capacity planning. The usage of each pod is the last collected metrics in the metric cache.
+type QoSUsageExporter struct {
+	resManager *resmanager
+}
+
+type qosResourceUsage struct {
+	cpuCores    float64
+	memoryBytes float64
+}
+
+func NewQoSUsageExporter(resManager *resmanager) *QoSUsageExporter {
+	return &QoSUsageExporter{resManager: resManager}
+}
+
+func (e *QoSUsageExporter) export() {
+	usages := e.calculateQoSUsages()
+	if usages == nil {
+		return
+	}
+	for _, qos := range qosUsageClasses {
+		usage := usages[qos]
+		labelValue := string(qos)
+		if qos == apiext.QoSNone {
+			labelValue = qosClassNone
+		}
+		metrics.RecordQoSResourceUsage(labelValue, string(corev1.ResourceCPU), usage.cpuCores)
+		metrics.RecordQoSResourceUsage(labelValue, string(corev1.ResourceMemory), usage.memoryBytes)
+	}
+}
+
+// calculateQoSUsages sums the usage of the running pods by qos class, and returns nil if the pods are unavailable.
+func (e *QoSUsageExporter) calculateQoSUsages() map[apiext.QoSClass]qosResourceUsage {
+	podMetas := e.resManager.statesInformer.GetAllPods()
+	if podMetas == nil {
+		klog.V(4).Infof("skip exporting qos usage, pods are not available")
+		return nil
+	}
+	queryParam := generateQueryParamsLast(e.resManager.collectResUsedIntervalSeconds * 2)
+	usages := make(map[apiext.QoSClass]qosResourceUsage, len(qosUsageClasses))
+	for _, podMeta := range podMetas {
+		if podMeta == nil || podMeta.Pod == nil || podMeta.Pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		podMetric := e.resManager.collectPodMetric(podMeta, queryParam).Metric
+		if podMetric == nil {
+			continue
+		}
+		qos := apiext.GetPodQoSClass(podMeta.Pod)
+		usage := usages[qos]
+		usage.cpuCores += float64(podMetric.CPUUsed.CPUUsed.MilliValue()) / 1000
+		usage.memoryBytes += float64(podMetric.MemoryUsed.MemoryWithoutCache.Value())
+		usages[qos] = usage
+	}
+	return usages
+}
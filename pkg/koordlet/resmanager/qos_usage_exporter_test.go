@@ -0,0 +1,113 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+)
+
+func TestQoSUsageExporter_export(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	testingNode := getNode("80", "120G")
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.QoSResourceUsage.Reset()
+	defer metrics.QoSResourceUsage.Reset()
+
+	newPod := func(qos apiext.QoSClass, name string, phase corev1.PodPhase) *corev1.Pod {
+		pod := createTestPod(qos, name)
+		pod.Status.Phase = phase
+		return pod
+	}
+	noQoSPod := newPod(apiext.QoSNone, "test_none_pod", corev1.PodRunning)
+	noQoSPod.Labels = nil
+	pods := []*corev1.Pod{
+		newPod(apiext.QoSLSR, "test_lsr_pod", corev1.PodRunning),
+		newPod(apiext.QoSLS, "test_ls_pod_0", corev1.PodRunning),
+		newPod(apiext.QoSLS, "test_ls_pod_1", corev1.PodRunning),
+		newPod(apiext.QoSBE, "test_be_pod", corev1.PodRunning),
+		newPod(apiext.QoSSystem, "test_system_pod", corev1.PodRunning),
+		noQoSPod,
+		// the pod not running is skipped
+		newPod(apiext.QoSBE, "test_be_pod_pending", corev1.PodPending),
+		// the pod without metrics is skipped
+		newPod(apiext.QoSBE, "test_be_pod_no_metric", corev1.PodRunning),
+	}
+	podUsages := map[string]struct {
+		cpu    string
+		memory string
+	}{
+		"test_lsr_pod":        {cpu: "4", memory: "8Gi"},
+		"test_ls_pod_0":       {cpu: "1500m", memory: "2Gi"},
+		"test_ls_pod_1":       {cpu: "500m", memory: "1Gi"},
+		"test_be_pod":         {cpu: "3", memory: "4Gi"},
+		"test_system_pod":     {cpu: "200m", memory: "512Mi"},
+		"test_none_pod":       {cpu: "100m", memory: "256Mi"},
+		"test_be_pod_pending": {cpu: "10", memory: "10Gi"},
+	}
+
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().Return(getPodMetas(pods)).AnyTimes()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	for _, pod := range pods {
+		podUID := string(pod.UID)
+		result := metriccache.PodResourceQueryResult{}
+		if usage, ok := podUsages[pod.Name]; ok {
+			result.Metric = &metriccache.PodResourceMetric{
+				PodUID:     podUID,
+				CPUUsed:    metriccache.CPUMetric{CPUUsed: resource.MustParse(usage.cpu)},
+				MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse(usage.memory)},
+			}
+		}
+		mockMetricCache.EXPECT().GetPodResourceMetric(&podUID, gomock.Any()).Return(result).AnyTimes()
+	}
+
+	r := &resmanager{statesInformer: mockStatesInformer, metricCache: mockMetricCache, collectResUsedIntervalSeconds: 1}
+	NewQoSUsageExporter(r).export()
+
+	gib := float64(1 << 30)
+	wantUsages := map[string]struct {
+		cpu    float64
+		memory float64
+	}{
+		"LSE":    {cpu: 0, memory: 0},
+		"LSR":    {cpu: 4, memory: 8 * gib},
+		"LS":     {cpu: 2, memory: 3 * gib},
+		"BE":     {cpu: 3, memory: 4 * gib},
+		"SYSTEM": {cpu: 0.2, memory: 0.5 * gib},
+		"NONE":   {cpu: 0.1, memory: 0.25 * gib},
+	}
+	for qos, want := range wantUsages {
+		gotCPU := testutil.ToFloat64(metrics.QoSResourceUsage.WithLabelValues(testingNode.Name, qos, "cpu"))
+		assert.InDelta(t, want.cpu, gotCPU, 1e-9, "check cpu usage of qos %s", qos)
+		gotMemory := testutil.ToFloat64(metrics.QoSResourceUsage.WithLabelValues(testingNode.Name, qos, "memory"))
+		assert.Equal(t, want.memory, gotMemory, "check memory usage of qos %s", qos)
+	}
+}
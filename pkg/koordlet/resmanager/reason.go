@@ -25,6 +25,9 @@ const (
 	updateResctrlTasks    = "UpdateResctrlTasks"    // update resctrl tasks
 
 	evictPodByNodeMemoryUsage = "EvictPodByNodeMemoryUsage"
+	evictPodByNodeDiskUsage   = "EvictPodByNodeDiskUsage"
+	evictPodByRepeatedOOM     = "RepeatedOOM"
+	evictPodByLastResort      = "EvictLSPodByNodeMemoryUsage"
 
 	adjustBEByNodeCPUUsage = "AdjustBEByNodeCPUUsage"
 )
@@ -0,0 +1,207 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+// resctrlClosidsWarningPercent is the percentage of the CLOSIDs in use to warn that they are nearly exhausted
+const resctrlClosidsWarningPercent = 90
+
+// ResctrlMonitor exports the llc occupancy and the memory bandwidth of each qos class by the resctrl monitoring.
+// The resctrl groups created by the ResctrlReconcile are monitored directly; for the qos class without a resctrl group,
+// a monitoring group is created under the root group and the tasks of the pods are assigned to it.
+// It also exports the CLOSIDs in use, since creating a resctrl group fails once they are exhausted.
+type ResctrlMonitor struct {
+	resManager *resmanager
+	executor   *ResourceUpdateExecutor
+	clock      clock.Clock
+	// readMonData reads the monitoring data of the given resctrl group path
+	readMonData func(groupPath string) (*system.ResctrlMonData, error)
+	// lastSamples are the last monitoring data of each qos class to calculate the memory bandwidth
+	lastSamples map[string]*resctrlMonSample
+}
+
+type resctrlMonSample struct {
+	time time.Time
+	data system.ResctrlMonData
+}
+
+func NewResctrlMonitor(resManager *resmanager) *ResctrlMonitor {
+	executor := NewResourceUpdateExecutor("ResctrlMonitorExecutor", resManager.config.ReconcileIntervalSeconds*60)
+	return &ResctrlMonitor{
+		resManager:  resManager,
+		executor:    executor,
+		clock:       clock.RealClock{},
+		readMonData: system.ReadResctrlMonData,
+		lastSamples: map[string]*resctrlMonSample{},
+	}
+}
+
+func (r *ResctrlMonitor) RunInit(stopCh <-chan struct{}) error {
+	r.executor.Run(stopCh)
+	return nil
+}
+
+// getMonGroupPath returns the resctrl group path to monitor the qos class, and whether it is a monitoring group
+// created by the monitor.
+func getMonGroupPath(group string) (string, bool) {
+	if _, err := os.Stat(system.GetResctrlGroupRootDirPath(group)); err == nil {
+		return group, false
+	}
+	return system.GetResctrlMonGroupPath(RootResctrlGroup, group), true
+}
+
+func initMonGroupIfNotExist(monGroupPath string) error {
+	path := system.GetResctrlGroupRootDirPath(monGroupPath)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.Mkdir(path, 0755); err != nil {
+		return err
+	}
+	klog.V(4).Infof("create resctrl monitoring group %v", path)
+	return nil
+}
+
+func (r *ResctrlMonitor) reconcileMonGroupTasks(monGroupPaths map[string]string) {
+	curTaskMaps := map[string]map[int]struct{}{}
+	for group, monGroupPath := range monGroupPaths {
+		tasksMap, err := system.ReadResctrlTasksMap(monGroupPath)
+		if err != nil {
+			klog.Warningf("failed to read tasks for resctrl monitoring group %s, err: %s", monGroupPath, err)
+		}
+		curTaskMaps[group] = tasksMap
+	}
+
+	taskIds := map[string][]int{}
+	for _, podMeta := range r.resManager.statesInformer.GetAllPods() {
+		pod := podMeta.Pod
+		// only Running and Pending pods are considered
+		if pod.Status.Phase != corev1.PodRunning && pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+		group := getPodResctrlGroup(pod)
+		if _, ok := monGroupPaths[group]; !ok {
+			continue
+		}
+		taskIds[group] = append(taskIds[group], getPodCgroupNewTaskIds(podMeta, curTaskMaps[group])...)
+	}
+
+	for group, monGroupPath := range monGroupPaths {
+		if len(taskIds[group]) <= 0 {
+			continue
+		}
+		// the task ids are the realtime diff, so the update should not be cacheable
+		if err := r.executor.Update(calculateL3TasksResource(monGroupPath, taskIds[group])); err != nil {
+			klog.Warningf("failed to write tasks for resctrl monitoring group %s, err: %s", monGroupPath, err)
+		}
+	}
+}
+
+func (r *ResctrlMonitor) recordMonData(group, monGroupPath string) {
+	data, err := r.readMonData(monGroupPath)
+	if err != nil {
+		klog.Warningf("failed to read resctrl monitoring data for group %s, err: %s", monGroupPath, err)
+		delete(r.lastSamples, group)
+		return
+	}
+	metrics.RecordResctrlLLCOccupancy(group, float64(data.LLCOccupancy))
+
+	now := r.clock.Now()
+	last := r.lastSamples[group]
+	r.lastSamples[group] = &resctrlMonSample{time: now, data: *data}
+	if last == nil {
+		return
+	}
+	// skip when the counters are reset, e.g. the monitoring group is recreated
+	seconds := now.Sub(last.time).Seconds()
+	if seconds <= 0 || data.MBMTotalBytes < last.data.MBMTotalBytes || data.MBMLocalBytes < last.data.MBMLocalBytes {
+		return
+	}
+	metrics.RecordResctrlMemoryBandwidth(group, metrics.ResctrlMemoryBandwidthTotal,
+		float64(data.MBMTotalBytes-last.data.MBMTotalBytes)/seconds)
+	metrics.RecordResctrlMemoryBandwidth(group, metrics.ResctrlMemoryBandwidthLocal,
+		float64(data.MBMLocalBytes-last.data.MBMLocalBytes)/seconds)
+}
+
+// recordClosids exports the CLOSIDs used by the resctrl control groups versus the platform maximum, and warns when
+// they are nearly exhausted. It is skipped if the resctrl fs is not mounted.
+func (r *ResctrlMonitor) recordClosids() {
+	maxClosids, err := system.ReadResctrlNumClosids()
+	if err != nil {
+		klog.V(5).Infof("skip recording resctrl closids, failed to read num_closids, err: %v", err)
+		return
+	}
+	usedClosids, err := system.CountResctrlCtrlGroups()
+	if err != nil {
+		klog.Warningf("failed to count resctrl groups, err: %v", err)
+		return
+	}
+	metrics.RecordResctrlClosids(metrics.ResctrlClosidsUsed, float64(usedClosids))
+	metrics.RecordResctrlClosids(metrics.ResctrlClosidsMax, float64(maxClosids))
+	if usedClosids*100 >= maxClosids*resctrlClosidsWarningPercent {
+		klog.Warningf("resctrl closids are nearly exhausted, %d of %d in use, creating resctrl groups may fail",
+			usedClosids, maxClosids)
+	}
+}
+
+func (r *ResctrlMonitor) monitor() {
+	// Step 0. create the monitoring groups for the qos classes without a resctrl group
+	// Step 1. reconcile the monitoring groups against `tasks` file
+	// Step 2. export the monitoring data of each qos class
+	if r.resManager == nil || r.executor == nil {
+		klog.Warning("ResctrlMonitor failed, uninitialized")
+		return
+	}
+	r.recordClosids()
+	if !system.IsResctrlMonSupported() {
+		klog.V(5).Infof("ResctrlMonitor skipped, resctrl monitoring is not enabled")
+		return
+	}
+
+	groupPaths := map[string]string{}
+	monGroupPaths := map[string]string{}
+	for _, group := range resctrlGroupList {
+		groupPath, isMonGroup := getMonGroupPath(group)
+		if isMonGroup {
+			if err := initMonGroupIfNotExist(groupPath); err != nil {
+				klog.Warningf("failed to create resctrl monitoring group %s, err: %s", groupPath, err)
+				continue
+			}
+			monGroupPaths[group] = groupPath
+		}
+		groupPaths[group] = groupPath
+	}
+
+	r.reconcileMonGroupTasks(monGroupPaths)
+
+	for _, group := range resctrlGroupList {
+		if groupPath, ok := groupPaths[group]; ok {
+			r.recordMonData(group, groupPath)
+		}
+	}
+}
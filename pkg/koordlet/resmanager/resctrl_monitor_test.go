@@ -0,0 +1,249 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+func testingPrepareResctrlMonGroup(t *testing.T, groupPath string, llcOccupancy, mbmTotalBytes, mbmLocalBytes uint64) {
+	groupDir := system.GetResctrlGroupRootDirPath(groupPath)
+	monDataDir := filepath.Join(system.GetResctrlMonDataDirPath(groupPath), system.ResctrlMonL3DirPrefix+"00")
+	err := os.MkdirAll(monDataDir, 0700)
+	assert.NoError(t, err)
+	if _, err = os.Stat(system.GetResctrlTasksFilePath(groupPath)); os.IsNotExist(err) {
+		err = ioutil.WriteFile(filepath.Join(groupDir, system.ResctrlTaskFileName), []byte{}, 0666)
+		assert.NoError(t, err)
+	}
+	for fileName, value := range map[string]uint64{
+		system.LLCOccupancyFileName:  llcOccupancy,
+		system.MBMTotalBytesFileName: mbmTotalBytes,
+		system.MBMLocalBytesFileName: mbmLocalBytes,
+	} {
+		err = ioutil.WriteFile(filepath.Join(monDataDir, fileName), []byte(fmt.Sprintf("%d\n", value)), 0666)
+		assert.NoError(t, err)
+	}
+}
+
+func TestResctrlMonitor_monitor(t *testing.T) {
+	testingNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.ResctrlLLCOccupancy.Reset()
+	metrics.ResctrlMemoryBandwidth.Reset()
+
+	testingContainerParentDir := "kubepods.slice/p0/cri-containerd-c0.scope"
+	testingPodMeta := &statesinformer.PodMeta{
+		Pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod0",
+				UID:  "p0",
+				Labels: map[string]string{
+					extension.LabelPodQoS: string(extension.QoSLS),
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "container0"}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "container0", ContainerID: "containerd://c0"},
+				},
+			},
+		},
+		CgroupDir: "p0",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+	statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{testingPodMeta}).AnyTimes()
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	system.Conf.SysFSRootDir = path.Join(helper.TempDir, "resctrlMonitor")
+	helper.MkDirAll(path.Join("resctrlMonitor", system.ResctrlDir, system.RdtInfoDir, system.L3MonDir))
+	helper.MkDirAll(path.Join("resctrlMonitor", system.ResctrlDir, system.ResctrlMonGroupsDir))
+	testingPrepareContainerCgroupCPUTasks(t, testingContainerParentDir, "122450\n122454")
+
+	// the BE resctrl group is created by the ResctrlReconcile, and the LS monitoring group has been created before
+	lsMonGroupPath := system.GetResctrlMonGroupPath(RootResctrlGroup, LSResctrlGroup)
+	testingPrepareResctrlMonGroup(t, BEResctrlGroup, 1048576, 1000, 500)
+	testingPrepareResctrlMonGroup(t, lsMonGroupPath, 2097152, 2000, 2000)
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	r := NewResctrlMonitor(&resmanager{statesInformer: statesInformer, config: NewDefaultConfig()})
+	r.clock = fakeClock
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, r.RunInit(stop))
+
+	r.monitor()
+
+	// the LSR monitoring group is created, and the LS pod tasks are assigned to the LS monitoring group
+	_, err := os.Stat(system.GetResctrlGroupRootDirPath(system.GetResctrlMonGroupPath(RootResctrlGroup, LSRResctrlGroup)))
+	assert.NoError(t, err)
+	// the task ids are written one by one into the fake tasks file
+	out, err := ioutil.ReadFile(system.GetResctrlTasksFilePath(lsMonGroupPath))
+	assert.NoError(t, err)
+	assert.Equal(t, "122450122454", string(out))
+	assert.Equal(t, float64(1048576), testutil.ToFloat64(metrics.ResctrlLLCOccupancy.WithLabelValues(testingNode.Name, BEResctrlGroup)))
+	assert.Equal(t, float64(2097152), testutil.ToFloat64(metrics.ResctrlLLCOccupancy.WithLabelValues(testingNode.Name, LSResctrlGroup)))
+	// the bandwidth needs two samples
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.ResctrlMemoryBandwidth))
+
+	fakeClock.Step(10 * time.Second)
+	testingPrepareResctrlMonGroup(t, BEResctrlGroup, 524288, 11000, 5500)
+	testingPrepareResctrlMonGroup(t, lsMonGroupPath, 2097152, 4000, 3000)
+	r.monitor()
+
+	assert.Equal(t, float64(524288), testutil.ToFloat64(metrics.ResctrlLLCOccupancy.WithLabelValues(testingNode.Name, BEResctrlGroup)))
+	assert.Equal(t, float64(1000), testutil.ToFloat64(metrics.ResctrlMemoryBandwidth.WithLabelValues(testingNode.Name,
+		BEResctrlGroup, metrics.ResctrlMemoryBandwidthTotal)))
+	assert.Equal(t, float64(500), testutil.ToFloat64(metrics.ResctrlMemoryBandwidth.WithLabelValues(testingNode.Name,
+		BEResctrlGroup, metrics.ResctrlMemoryBandwidthLocal)))
+	assert.Equal(t, float64(200), testutil.ToFloat64(metrics.ResctrlMemoryBandwidth.WithLabelValues(testingNode.Name,
+		LSResctrlGroup, metrics.ResctrlMemoryBandwidthTotal)))
+	assert.Equal(t, float64(100), testutil.ToFloat64(metrics.ResctrlMemoryBandwidth.WithLabelValues(testingNode.Name,
+		LSResctrlGroup, metrics.ResctrlMemoryBandwidthLocal)))
+	// the LSR monitoring group has no data
+	assert.NotContains(t, r.lastSamples, LSRResctrlGroup)
+}
+
+func TestResctrlMonitor_recordMonData(t *testing.T) {
+	testingNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.ResctrlMemoryBandwidth.Reset()
+
+	samples := []*system.ResctrlMonData{
+		{LLCOccupancy: 100, MBMTotalBytes: 1000, MBMLocalBytes: 1000},
+		{LLCOccupancy: 100, MBMTotalBytes: 2000, MBMLocalBytes: 1500},
+		// the counters are reset
+		{LLCOccupancy: 100, MBMTotalBytes: 500, MBMLocalBytes: 500},
+		{LLCOccupancy: 100, MBMTotalBytes: 800, MBMLocalBytes: 600},
+	}
+	wantTotalBandwidths := []float64{0, 100, 100, 30}
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	r := &ResctrlMonitor{
+		clock:       fakeClock,
+		lastSamples: map[string]*resctrlMonSample{},
+	}
+	for i := range samples {
+		r.readMonData = func(groupPath string) (*system.ResctrlMonData, error) {
+			assert.Equal(t, BEResctrlGroup, groupPath)
+			return samples[i], nil
+		}
+		r.recordMonData(BEResctrlGroup, BEResctrlGroup)
+		assert.Equal(t, wantTotalBandwidths[i], testutil.ToFloat64(metrics.ResctrlMemoryBandwidth.WithLabelValues(
+			testingNode.Name, BEResctrlGroup, metrics.ResctrlMemoryBandwidthTotal)), "sample %d", i)
+		fakeClock.Step(10 * time.Second)
+	}
+
+	// the last sample is dropped if failed to read
+	r.readMonData = func(groupPath string) (*system.ResctrlMonData, error) {
+		return nil, fmt.Errorf("expected error")
+	}
+	r.recordMonData(BEResctrlGroup, BEResctrlGroup)
+	assert.NotContains(t, r.lastSamples, BEResctrlGroup)
+}
+
+func TestResctrlMonitor_recordClosids(t *testing.T) {
+	testingNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+
+	tests := []struct {
+		name        string
+		numClosids  string
+		groups      []string
+		wantUsed    float64
+		wantMax     float64
+		wantWarning bool
+	}{
+		{
+			name:        "closids are nearly exhausted",
+			numClosids:  "8\n",
+			groups:      []string{LSRResctrlGroup, LSResctrlGroup, BEResctrlGroup, "agent-0", "agent-1", "agent-2", "agent-3"},
+			wantUsed:    8,
+			wantMax:     8,
+			wantWarning: true,
+		},
+		{
+			name:        "closids are enough",
+			numClosids:  "16\n",
+			groups:      []string{LSRResctrlGroup, LSResctrlGroup, BEResctrlGroup},
+			wantUsed:    4,
+			wantMax:     16,
+			wantWarning: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics.ResctrlClosids.Reset()
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			system.Conf.SysFSRootDir = path.Join(helper.TempDir, "resctrlClosids")
+			helper.MkDirAll(path.Join("resctrlClosids", system.ResctrlDir, system.RdtInfoDir, system.L3MonDir))
+			helper.MkDirAll(path.Join("resctrlClosids", system.ResctrlDir, system.RdtInfoDir, system.L3CatDir))
+			helper.WriteFileContents(path.Join("resctrlClosids", system.ResctrlDir, system.RdtInfoDir, system.L3CatDir,
+				system.NumClosidsFileName), tt.numClosids)
+			for _, group := range append(tt.groups, system.ResctrlMonGroupsDir, system.ResctrlMonDataDir) {
+				helper.MkDirAll(path.Join("resctrlClosids", system.ResctrlDir, group))
+			}
+
+			var buf bytes.Buffer
+			klog.LogToStderr(false)
+			klog.SetOutput(&buf)
+			defer klog.LogToStderr(true)
+
+			r := NewResctrlMonitor(&resmanager{config: NewDefaultConfig()})
+			r.recordClosids()
+			klog.Flush()
+
+			assert.Equal(t, tt.wantUsed, testutil.ToFloat64(metrics.ResctrlClosids.WithLabelValues(testingNode.Name,
+				metrics.ResctrlClosidsUsed)))
+			assert.Equal(t, tt.wantMax, testutil.ToFloat64(metrics.ResctrlClosids.WithLabelValues(testingNode.Name,
+				metrics.ResctrlClosidsMax)))
+			assert.Equal(t, tt.wantWarning, strings.Contains(buf.String(), "resctrl closids are nearly exhausted"))
+		})
+	}
+}
@@ -24,13 +24,17 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 	"github.com/koordinator-sh/koordinator/pkg/util/system"
@@ -53,6 +57,13 @@ const (
 	MbSchemataPrefix = "MB:"
 )
 
+const (
+	// resctrlInitMinBackoff is the backoff before retrying to initialize the resctrl cat after the first failure
+	resctrlInitMinBackoff = 30 * time.Second
+	// resctrlInitMaxBackoff is the upper bound of the backoff, which doubles on each consecutive failure
+	resctrlInitMaxBackoff = 10 * time.Minute
+)
+
 var (
 	// resctrlGroupList is the list of resctrl groups to be reconcile
 	resctrlGroupList = []string{LSRResctrlGroup, LSResctrlGroup, BEResctrlGroup}
@@ -61,6 +72,11 @@ var (
 type ResctrlReconcile struct {
 	resManager *resmanager
 	executor   *ResourceUpdateExecutor
+	clock      clock.Clock
+	// initFailures is the count of the consecutive failures to initialize the resctrl cat
+	initFailures int
+	// nextInitTime is the earliest time to retry initializing the resctrl cat, the reconcile is skipped quietly before it
+	nextInitTime time.Time
 }
 
 func NewResctrlReconcile(resManager *resmanager) *ResctrlReconcile {
@@ -68,6 +84,7 @@ func NewResctrlReconcile(resManager *resmanager) *ResctrlReconcile {
 	return &ResctrlReconcile{
 		resManager: resManager,
 		executor:   executor,
+		clock:      clock.RealClock{},
 	}
 }
 
@@ -100,13 +117,15 @@ func getResourceQoSForResctrlGroup(strategy *slov1alpha1.ResourceQoSStrategy, gr
 		return strategy.LS
 	case BEResctrlGroup:
 		return strategy.BE
+	case RootResctrlGroup:
+		return strategy.CgroupRoot
 	}
 	return nil
 }
 
-func initCatResctrl() error {
+func initCatResctrl(mount bool) error {
 	// check if the resctrl root and l3_cat feature are enabled correctly
-	if err := system.CheckAndTryEnableResctrlCat(); err != nil {
+	if err := system.CheckAndTryEnableResctrlCat(mount); err != nil {
 		klog.Errorf("check resctrl cat failed, err: %s", err)
 		return err
 	}
@@ -135,7 +154,9 @@ func initCatGroupIfNotExist(group string) error {
 	return nil
 }
 
-func calculateCatL3MaskValue(cbm uint, startPercent, endPercent int64) (string, error) {
+// calculateCatL3MaskValue returns a contiguous l3 mask within the cbm of the resctrl root for the percent interval, which
+// has at least minBits bits set as the platform requires in min_cbm_bits.
+func calculateCatL3MaskValue(cbm uint, minBits uint, startPercent, endPercent int64) (string, error) {
 	// check if the parsed cbm value is valid, eg. 0xff, 0x1, 0x7ff, ...
 	// NOTE: (Cache Bit Masks) X86 hardware requires that these masks have all the '1' bits in a contiguous block.
 	//       ref: https://www.kernel.org/doc/Documentation/x86/intel_rdt_ui.txt
@@ -157,9 +178,27 @@ func calculateCatL3MaskValue(cbm uint, startPercent, endPercent int64) (string,
 	// ways 11, l3Mask 0x3c ('b111100)
 	// cbm 0x7ff ('b11111111111), start 0%, end 30%
 	// ways 11, l3Mask 0xf ('b1111)
-	ways := float64(bits.Len(cbm))
-	startWay := uint64(math.Ceil(ways * float64(startPercent) / 100))
-	endWay := uint64(math.Ceil(ways * float64(endPercent) / 100))
+	ways := uint64(bits.Len(cbm))
+	if minBits <= 0 {
+		minBits = 1
+	}
+	if uint64(minBits) > ways {
+		return "", fmt.Errorf("illegal min cbm bits %v for cbm %v", minBits, cbm)
+	}
+	startWay := uint64(math.Ceil(float64(ways) * float64(startPercent) / 100))
+	endWay := uint64(math.Ceil(float64(ways) * float64(endPercent) / 100))
+	// since the cache is allocated in ways, a narrow interval may round to fewer ways than required; extend it to the
+	// higher ways first, and shift it to the lower ways if it exceeds the cbm
+	// eg.
+	// cbm 0xff ('b11111111), min bits 2, start 90%, end 95%
+	// ways 8, l3Mask 0xc0 ('b11000000)
+	if endWay-startWay < uint64(minBits) {
+		endWay = startWay + uint64(minBits)
+		if endWay > ways {
+			endWay = ways
+			startWay = ways - uint64(minBits)
+		}
+	}
 
 	var l3Mask uint64 = (1 << endWay) - (1 << startWay)
 	return strconv.FormatUint(l3Mask, 16), nil
@@ -220,6 +259,45 @@ func calculateMbaPercentForGroup(group string, mbaPercentConfig *int64) string {
 	return strconv.FormatInt(*mbaPercentConfig, 10)
 }
 
+func calculateMbaMBpsForGroup(group string, mbaMBpsConfig *int64) string {
+	if mbaMBpsConfig == nil {
+		klog.Warningf("cat MBA will not change, since MBAMBps is nil for group %v", group)
+		return ""
+	}
+
+	if *mbaMBpsConfig <= 0 {
+		klog.Warningf("cat MBA will not change, since MBAMBps is not positive for group %v, "+
+			"MBAMBps %d", group, *mbaMBpsConfig)
+		return ""
+	}
+
+	return strconv.FormatInt(*mbaMBpsConfig, 10)
+}
+
+// calculateMbaValueForGroup returns the MB schemata value of the group according to the mba mode of the resctrl mount,
+// where the value is specified by MBAMBps in MBps mode, and by MBAPercent in percentage mode
+func calculateMbaValueForGroup(group string, resctrlQoS *slov1alpha1.ResctrlQoS, isMBpsMode bool) (string, error) {
+	if err := slov1alpha1.ValidateResctrlMBA(resctrlQoS, field.NewPath(group)).ToAggregate(); err != nil {
+		return "", err
+	}
+
+	if isMBpsMode {
+		if resctrlQoS.MBAMBps == nil && resctrlQoS.MBAPercent != nil {
+			klog.Warningf("cat MBA will not change, since resctrl is in MBps mode but only MBAPercent is set for "+
+				"group %v", group)
+			return "", nil
+		}
+		return calculateMbaMBpsForGroup(group, resctrlQoS.MBAMBps), nil
+	}
+
+	if resctrlQoS.MBAPercent == nil && resctrlQoS.MBAMBps != nil {
+		klog.Warningf("cat MBA will not change, since resctrl is in percentage mode but only MBAMBps is set for "+
+			"group %v", group)
+		return "", nil
+	}
+	return calculateMbaPercentForGroup(group, resctrlQoS.MBAPercent), nil
+}
+
 func getPodCgroupNewTaskIds(podMeta *statesinformer.PodMeta, tasksMap map[int]struct{}) []int {
 	var taskIds []int
 
@@ -275,7 +353,7 @@ func calculateL3TasksResource(group string, taskIds []int) ResourceUpdater {
 	return NewDetailCommonResourceUpdater(tasksPath, tasksPath, builder.String(), GroupOwnerRef(group), updateResctrlTasksFunc)
 }
 
-func (r *ResctrlReconcile) calculateAndApplyCatL3PolicyForGroup(group string, cbm uint, l3Num int,
+func (r *ResctrlReconcile) calculateAndApplyCatL3PolicyForGroup(group string, cbm, minCbmBits uint, l3Num int,
 	resourceQoS *slov1alpha1.ResourceQoS) error {
 	if resourceQoS == nil || resourceQoS.ResctrlQoS == nil || resourceQoS.ResctrlQoS.CATRangeStartPercent == nil ||
 		resourceQoS.ResctrlQoS.CATRangeEndPercent == nil {
@@ -286,7 +364,7 @@ func (r *ResctrlReconcile) calculateAndApplyCatL3PolicyForGroup(group string, cb
 
 	startPercent, endPercent := *resourceQoS.ResctrlQoS.CATRangeStartPercent, *resourceQoS.ResctrlQoS.CATRangeEndPercent
 	// calculate policy
-	l3MaskValue, err := calculateCatL3MaskValue(cbm, startPercent, endPercent)
+	l3MaskValue, err := calculateCatL3MaskValue(cbm, minCbmBits, startPercent, endPercent)
 	if err != nil {
 		klog.Warningf("failed to calculate l3 cat schemata for group %v, err: %v", group, err)
 		return err
@@ -307,19 +385,23 @@ func (r *ResctrlReconcile) calculateAndApplyCatL3PolicyForGroup(group string, cb
 	return nil
 }
 
-func (r *ResctrlReconcile) calculateAndApplyCatMbPolicyForGroup(group string, l3Num int, resourceQoS *slov1alpha1.ResourceQoS) error {
+func (r *ResctrlReconcile) calculateAndApplyCatMbPolicyForGroup(group string, l3Num int, resourceQoS *slov1alpha1.ResourceQoS,
+	isMBpsMode bool) error {
 	if resourceQoS == nil || resourceQoS.ResctrlQoS == nil {
 		klog.Warningf("skipped, since resourceQoS or ResctrlQoS is nil for group %v, "+
 			"resourceQoS %v", resourceQoS, group)
 		return nil
 	}
 
-	memBwPercent := calculateMbaPercentForGroup(group, resourceQoS.ResctrlQoS.MBAPercent)
-	if memBwPercent == "" {
+	memBwValue, err := calculateMbaValueForGroup(group, &resourceQoS.ResctrlQoS.ResctrlQoS, isMBpsMode)
+	if err != nil {
+		return err
+	}
+	if memBwValue == "" {
 		return nil
 	}
 	// calculate updating resource
-	resource := calculateMbSchemataResource(group, memBwPercent, l3Num)
+	resource := calculateMbSchemataResource(group, memBwValue, l3Num)
 
 	// write policy into resctrl files if need update
 	isUpdated, err := r.executor.UpdateByCache(resource)
@@ -328,7 +410,7 @@ func (r *ResctrlReconcile) calculateAndApplyCatMbPolicyForGroup(group string, l3
 		return err
 	}
 	klog.V(5).Infof("apply mb cat policy for group %s finished, schemata %v, l3 number %v, isUpdated %v",
-		group, memBwPercent, l3Num, isUpdated)
+		group, memBwValue, l3Num, isUpdated)
 	return nil
 }
 
@@ -375,6 +457,12 @@ func (r *ResctrlReconcile) reconcileCatResctrlPolicy(qosStrategy *slov1alpha1.Re
 		return
 	}
 	cbm := uint(cbmValue)
+	// the min_cbm_bits is not exposed by some platforms, where a single bit is taken as valid
+	minCbmBits, err := system.ReadCatL3MinCbmBits()
+	if err != nil {
+		klog.V(4).Infof("failed to read cat l3 min cbm bits, use 1, err: %v", err)
+		minCbmBits = 1
+	}
 
 	// get the number of l3 caches; it is larger than 0
 	l3Num := int(nodeCPUInfo.TotalInfo.NumberL3s)
@@ -383,18 +471,36 @@ func (r *ResctrlReconcile) reconcileCatResctrlPolicy(qosStrategy *slov1alpha1.Re
 		return
 	}
 
+	// check if the MB schemata should be specified in MBps rather than in percentage
+	isMBpsMode, err := system.IsResctrlMBAMBpsEnabled()
+	if err != nil {
+		klog.V(4).Infof("failed to check if resctrl mba_MBps is enabled, use percentage mode, err: %v", err)
+	}
+
 	// calculate and apply l3 cat policy for each group
 	for _, group := range resctrlGroupList {
 		resQoSStrategy := getResourceQoSForResctrlGroup(qosStrategy, group)
-		err = r.calculateAndApplyCatL3PolicyForGroup(group, cbm, l3Num, resQoSStrategy)
+		err = r.calculateAndApplyCatL3PolicyForGroup(group, cbm, minCbmBits, l3Num, resQoSStrategy)
 		if err != nil {
 			klog.Warningf("failed to apply l3 cat policy for group %v, err: %v", group, err)
 		}
-		err = r.calculateAndApplyCatMbPolicyForGroup(group, l3Num, resQoSStrategy)
+		err = r.calculateAndApplyCatMbPolicyForGroup(group, l3Num, resQoSStrategy, isMBpsMode)
 		if err != nil {
 			klog.Warningf("failed to apply cat MB policy for group %v, err: %v", group, err)
 		}
 	}
+
+	// the root group holds the tasks not assigned to any group, so apply its policies only if the CgroupRoot config
+	// is specified; the enable option only takes effect on the tasks assignment and is ignored here
+	if qosStrategy.CgroupRoot != nil && qosStrategy.CgroupRoot.ResctrlQoS != nil {
+		rootQoSStrategy := getResourceQoSForResctrlGroup(qosStrategy, RootResctrlGroup)
+		if err = r.calculateAndApplyCatL3PolicyForGroup(RootResctrlGroup, cbm, minCbmBits, l3Num, rootQoSStrategy); err != nil {
+			klog.Warningf("failed to apply l3 cat policy for root group, err: %v", err)
+		}
+		if err = r.calculateAndApplyCatMbPolicyForGroup(RootResctrlGroup, l3Num, rootQoSStrategy, isMBpsMode); err != nil {
+			klog.Warningf("failed to apply cat MB policy for root group, err: %v", err)
+		}
+	}
 }
 
 func (r *ResctrlReconcile) reconcileResctrlGroups(qosStrategy *slov1alpha1.ResourceQoSStrategy) {
@@ -473,10 +579,42 @@ func (r *ResctrlReconcile) reconcile() {
 		return
 	}
 
-	if err := initCatResctrl(); err != nil {
-		klog.Warningf("ResctrlReconcile failed, cannot initialize cat resctrl group, err: %s", err)
+	if r.initFailures > 0 && r.clock.Now().Before(r.nextInitTime) {
+		klog.V(5).Infof("ResctrlReconcile skipped, resctrl cat is unavailable at %s, retry after %v",
+			system.GetResctrlSubsystemDirPath(), r.nextInitTime)
+		return
+	}
+	if err := initCatResctrl(r.resManager.config == nil || r.resManager.config.ResctrlAutoMount); err != nil {
+		r.initFailures++
+		backoff := r.getInitBackoff()
+		r.nextInitTime = r.clock.Now().Add(backoff)
+		if r.initFailures == 1 {
+			klog.Warningf("ResctrlReconcile paused, cannot initialize cat resctrl group, retry in %v, err: %s", backoff, err)
+		} else {
+			klog.V(4).Infof("ResctrlReconcile paused, cannot initialize cat resctrl group for %d times, retry in %v, err: %s",
+				r.initFailures, backoff, err)
+		}
 		return
 	}
+	if r.initFailures > 0 {
+		klog.Infof("ResctrlReconcile resumed, cat resctrl group is initialized after %d failures", r.initFailures)
+		r.initFailures = 0
+	}
+	start := time.Now()
 	r.reconcileCatResctrlPolicy(nodeSLO.Spec.ResourceQoSStrategy)
 	r.reconcileResctrlGroups(nodeSLO.Spec.ResourceQoSStrategy)
+	metrics.RecordCgroupReconcileDuration(metrics.CgroupReconcileResourceResctrl, time.Since(start).Seconds())
+}
+
+// getInitBackoff returns the backoff before the next try to initialize the resctrl cat, which doubles on each
+// consecutive failure and is capped at resctrlInitMaxBackoff
+func (r *ResctrlReconcile) getInitBackoff() time.Duration {
+	backoff := resctrlInitMinBackoff
+	for i := 1; i < r.initFailures && backoff < resctrlInitMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > resctrlInitMaxBackoff {
+		backoff = resctrlInitMaxBackoff
+	}
+	return backoff
 }
@@ -23,17 +23,20 @@ import (
 	"path"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -106,6 +109,7 @@ func testingPrepareContainerCgroupCPUTasks(t *testing.T, containerParentPath, ta
 func Test_calculateCatL3Schemata(t *testing.T) {
 	type args struct {
 		cbm          uint
+		minBits      uint
 		startPercent int64
 		endPercent   int64
 	}
@@ -210,10 +214,75 @@ func Test_calculateCatL3Schemata(t *testing.T) {
 			want:    "1fe",
 			wantErr: false,
 		},
+		{
+			name: "min bits exceed the ways",
+			args: args{
+				cbm:          0xf,
+				minBits:      5,
+				startPercent: 0,
+				endPercent:   100,
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "extend the narrow interval rounded to no way on 8 ways",
+			args: args{
+				cbm:          0xff,
+				startPercent: 10,
+				endPercent:   12,
+			},
+			want:    "2",
+			wantErr: false,
+		},
+		{
+			name: "extend the interval to min bits on 11 ways",
+			args: args{
+				cbm:          0x7ff,
+				minBits:      2,
+				startPercent: 10,
+				endPercent:   15,
+			},
+			want:    "c",
+			wantErr: false,
+		},
+		{
+			name: "shift the interval to the lower ways for min bits on 20 ways",
+			args: args{
+				cbm:          0xfffff,
+				minBits:      4,
+				startPercent: 90,
+				endPercent:   100,
+			},
+			want:    "f0000",
+			wantErr: false,
+		},
+		{
+			name: "keep the interval satisfying min bits on 12 ways",
+			args: args{
+				cbm:          0xfff,
+				minBits:      2,
+				startPercent: 0,
+				endPercent:   50,
+			},
+			want:    "3f",
+			wantErr: false,
+		},
+		{
+			name: "use all ways for min bits equal to the ways on 4 ways",
+			args: args{
+				cbm:          0xf,
+				minBits:      4,
+				startPercent: 50,
+				endPercent:   60,
+			},
+			want:    "f",
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := calculateCatL3MaskValue(tt.args.cbm, tt.args.startPercent, tt.args.endPercent)
+			got, err := calculateCatL3MaskValue(tt.args.cbm, tt.args.minBits, tt.args.startPercent, tt.args.endPercent)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
 		})
@@ -236,7 +305,7 @@ func Test_initCatResctrl(t *testing.T) {
 		_, err := os.Stat(resctrlDirPath)
 		assert.NoError(t, err)
 
-		err = initCatResctrl()
+		err = initCatResctrl(true)
 		// skip init if resctrl group path exists
 		assert.NoError(t, err)
 
@@ -247,7 +316,7 @@ func Test_initCatResctrl(t *testing.T) {
 		testingPrepareResctrlL3CatPath(t, "ff", "L3:0=ff")
 
 		// do not panic but create resctrl group if the path does not exist
-		err = initCatResctrl()
+		err = initCatResctrl(true)
 		assert.NoError(t, err)
 
 		resctrlDirPath = filepath.Join(system.Conf.SysFSRootDir, system.ResctrlDir)
@@ -260,8 +329,14 @@ func Test_initCatResctrl(t *testing.T) {
 
 		// path is invalid, do not panic but log the error
 		system.Conf.SysFSRootDir = "invalidPath"
-		err = initCatResctrl()
+		err = initCatResctrl(true)
 		assert.Error(t, err)
+
+		// the configured resctrl root dir is used
+		system.Conf.ResctrlRootDir = resctrlDirPath
+		defer func() { system.Conf.ResctrlRootDir = "" }()
+		err = initCatResctrl(false)
+		assert.NoError(t, err)
 	})
 }
 
@@ -643,7 +718,7 @@ func TestResctrlReconcile_calculateAndApplyCatL3PolicyForGroup(t *testing.T) {
 			}
 
 			// execute function
-			err := r.calculateAndApplyCatL3PolicyForGroup(tt.args.group, tt.args.cbm, tt.args.l3Num,
+			err := r.calculateAndApplyCatL3PolicyForGroup(tt.args.group, tt.args.cbm, 1, tt.args.l3Num,
 				getResourceQoSForResctrlGroup(tt.args.qosStrategy, tt.args.group))
 			assert.Equal(t, tt.wantErr, err != nil)
 
@@ -659,6 +734,7 @@ func TestResctrlReconcile_calculateAndApplyCatMbPolicyForGroup(t *testing.T) {
 		group       string
 		l3Num       int
 		qosStrategy *slov1alpha1.ResourceQoSStrategy
+		isMBpsMode  bool
 	}
 	type field struct {
 		invalidPath bool
@@ -757,6 +833,63 @@ func TestResctrlReconcile_calculateAndApplyCatMbPolicyForGroup(t *testing.T) {
 			want:    "MB:0=90;1=90;\n",
 			wantErr: false,
 		},
+		{
+			name: "apply policy correctly in MBps mode",
+			args: args{
+				group: LSResctrlGroup,
+				l3Num: 2,
+				qosStrategy: &slov1alpha1.ResourceQoSStrategy{
+					LS: &slov1alpha1.ResourceQoS{
+						ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+							ResctrlQoS: slov1alpha1.ResctrlQoS{
+								MBAMBps: pointer.Int64Ptr(2000),
+							},
+						},
+					},
+				},
+				isMBpsMode: true,
+			},
+			want:    "MB:0=2000;1=2000;\n",
+			wantErr: false,
+		},
+		{
+			name: "warning to MBps policy in percentage mode",
+			args: args{
+				group: LSResctrlGroup,
+				l3Num: 2,
+				qosStrategy: &slov1alpha1.ResourceQoSStrategy{
+					LS: &slov1alpha1.ResourceQoS{
+						ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+							ResctrlQoS: slov1alpha1.ResctrlQoS{
+								MBAMBps: pointer.Int64Ptr(2000),
+							},
+						},
+					},
+				},
+			},
+			want:    "    L3:0=ff;1=ff\n    MB:0=100;1=100",
+			wantErr: false,
+		},
+		{
+			name: "throw an error when both percent and MBps set",
+			args: args{
+				group: LSResctrlGroup,
+				l3Num: 2,
+				qosStrategy: &slov1alpha1.ResourceQoSStrategy{
+					LS: &slov1alpha1.ResourceQoS{
+						ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+							ResctrlQoS: slov1alpha1.ResctrlQoS{
+								MBAPercent: pointer.Int64Ptr(90),
+								MBAMBps:    pointer.Int64Ptr(2000),
+							},
+						},
+					},
+				},
+				isMBpsMode: true,
+			},
+			want:    "    L3:0=ff;1=ff\n    MB:0=100;1=100",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -794,7 +927,7 @@ func TestResctrlReconcile_calculateAndApplyCatMbPolicyForGroup(t *testing.T) {
 
 			// execute function
 			err := r.calculateAndApplyCatMbPolicyForGroup(tt.args.group, tt.args.l3Num,
-				getResourceQoSForResctrlGroup(tt.args.qosStrategy, tt.args.group))
+				getResourceQoSForResctrlGroup(tt.args.qosStrategy, tt.args.group), tt.args.isMBpsMode)
 			assert.Equal(t, tt.wantErr, err != nil)
 
 			schemataPath := filepath.Join(validSysFSRootDir, system.ResctrlDir, tt.args.group, system.SchemataFileName)
@@ -1007,6 +1140,159 @@ func TestResctrlReconcile_reconcileCatResctrlPolicy(t *testing.T) {
 	})
 }
 
+func TestResctrlReconcile_reconcileCatResctrlPolicyWithMBAMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		mountsStr     string
+		expectLSMBStr string
+		expectBEMBStr string
+	}{
+		{
+			name:          "apply percent in percentage mode",
+			mountsStr:     "resctrl /sys/fs/resctrl resctrl rw,relatime 0 0\n",
+			expectLSMBStr: "MB:0=90;1=90;\n",
+			expectBEMBStr: "    L3:0=f;1=f\n    MB:0=100;1=100",
+		},
+		{
+			name:          "apply MBps in MBps mode",
+			mountsStr:     "resctrl /sys/fs/resctrl resctrl rw,relatime,mba_MBps 0 0\n",
+			expectLSMBStr: "    L3:0=ff;1=ff\n    MB:0=100;1=100",
+			expectBEMBStr: "MB:0=2000;1=2000;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+
+			sysFSRootDirName := "reconcileCatResctrlPolicyWithMBAMode"
+			helper.MkDirAll(sysFSRootDirName)
+
+			system.Conf.SysFSRootDir = path.Join(helper.TempDir, sysFSRootDirName)
+			system.CommonRootDir = ""
+			helper.WriteProcSubFileContents(system.ProcMountsFileName, tt.mountsStr)
+
+			testingPrepareResctrlL3CatGroups(t, "7ff", "L3:0=7ff;1=7ff\n")
+
+			qosStrategy := &slov1alpha1.ResourceQoSStrategy{
+				LS: &slov1alpha1.ResourceQoS{
+					ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+						ResctrlQoS: slov1alpha1.ResctrlQoS{
+							MBAPercent: pointer.Int64Ptr(90),
+						},
+					},
+				},
+				BE: &slov1alpha1.ResourceQoS{
+					ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+						ResctrlQoS: slov1alpha1.ResctrlQoS{
+							MBAMBps: pointer.Int64Ptr(2000),
+						},
+					},
+				},
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+			metricCache.EXPECT().GetNodeCPUInfo(&metriccache.QueryParam{}).Return(&metriccache.NodeCPUInfo{
+				BasicInfo: util.CPUBasicInfo{CatL3CbmMask: "7ff"},
+				TotalInfo: util.CPUTotalInfo{NumberL3s: 2},
+			}, nil).Times(1)
+			r := ResctrlReconcile{
+				resManager: &resmanager{metricCache: metricCache},
+				executor:   NewResourceUpdateExecutor("ResctrlReconcile", 60),
+			}
+			stop := make(chan struct{})
+			r.RunInit(stop)
+			defer func() { stop <- struct{}{} }()
+
+			r.reconcileCatResctrlPolicy(qosStrategy)
+
+			got, _ := ioutil.ReadFile(system.GetResctrlSchemataFilePath(LSResctrlGroup))
+			assert.Equal(t, tt.expectLSMBStr, string(got))
+			got, _ = ioutil.ReadFile(system.GetResctrlSchemataFilePath(BEResctrlGroup))
+			assert.Equal(t, tt.expectBEMBStr, string(got))
+		})
+	}
+}
+
+func TestResctrlReconcile_reconcileCatResctrlPolicyForRootGroup(t *testing.T) {
+	tests := []struct {
+		name               string
+		rootCfg            *slov1alpha1.ResourceQoS
+		expectRootSchemata string
+	}{
+		{
+			name:               "keep root schemata if cgroupRoot is not set",
+			rootCfg:            nil,
+			expectRootSchemata: "L3:0=7ff;1=7ff\n",
+		},
+		{
+			name: "apply l3 cat policy for root group",
+			rootCfg: &slov1alpha1.ResourceQoS{
+				ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+					ResctrlQoS: slov1alpha1.ResctrlQoS{
+						CATRangeStartPercent: pointer.Int64Ptr(0),
+						CATRangeEndPercent:   pointer.Int64Ptr(30),
+					},
+				},
+			},
+			expectRootSchemata: "L3:0=f;1=f;\n",
+		},
+		{
+			name: "apply mb policy for root group",
+			rootCfg: &slov1alpha1.ResourceQoS{
+				ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+					ResctrlQoS: slov1alpha1.ResctrlQoS{
+						MBAPercent: pointer.Int64Ptr(80),
+					},
+				},
+			},
+			expectRootSchemata: "MB:0=80;1=80;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+
+			sysFSRootDirName := "reconcileCatResctrlPolicyForRootGroup"
+			helper.MkDirAll(sysFSRootDirName)
+
+			system.Conf.SysFSRootDir = path.Join(helper.TempDir, sysFSRootDirName)
+			system.CommonRootDir = ""
+
+			testingPrepareResctrlL3CatGroups(t, "7ff", "L3:0=7ff;1=7ff\n")
+
+			qosStrategy := &slov1alpha1.ResourceQoSStrategy{
+				CgroupRoot: tt.rootCfg,
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+			metricCache.EXPECT().GetNodeCPUInfo(&metriccache.QueryParam{}).Return(&metriccache.NodeCPUInfo{
+				BasicInfo: util.CPUBasicInfo{CatL3CbmMask: "7ff"},
+				TotalInfo: util.CPUTotalInfo{NumberL3s: 2},
+			}, nil).Times(1)
+			r := ResctrlReconcile{
+				resManager: &resmanager{metricCache: metricCache},
+				executor:   NewResourceUpdateExecutor("ResctrlReconcile", 60),
+			}
+			stop := make(chan struct{})
+			r.RunInit(stop)
+			defer func() { stop <- struct{}{} }()
+
+			r.reconcileCatResctrlPolicy(qosStrategy)
+
+			got, _ := ioutil.ReadFile(system.GetResctrlSchemataFilePath(RootResctrlGroup))
+			assert.Equal(t, tt.expectRootSchemata, string(got))
+		})
+	}
+}
+
 func TestResctrlReconcile_reconcileResctrlGroups(t *testing.T) {
 	// preparing
 	wantResctrlTaskStr := "122450122454123111128912"
@@ -1199,20 +1485,36 @@ func TestResctrlReconcile_reconcile(t *testing.T) {
 
 		cpuInfoContents := "flags		: fpu vme de pse cat_l3 mba"
 		helper.WriteProcSubFileContents("cpuinfo", cpuInfoContents)
+		kernelCmdlineContents := "BOOT_IMAGE=/boot/vmlinuz rdt=cmt,l3cat,mba"
+		helper.WriteProcSubFileContents("cmdline", kernelCmdlineContents)
+
+		metrics.Register(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+		defer metrics.Register(nil)
+		metrics.CgroupReconcileDuration.Reset()
 
 		r.reconcile()
+		assert.Equal(t, uint64(1), getCgroupReconcileDurationSampleCount(t, metrics.CgroupReconcileResourceResctrl))
 
 		// test nil resmgr
 		r.resManager = nil
 		r.reconcile()
 		r.resManager = rm
 
-		// test init cat resctrl failed
+		// test init cat resctrl failed, the reconcile is paused until the backoff elapses
+		fakeClock := testingclock.NewFakeClock(time.Now())
+		r.clock = fakeClock
 		system.Conf.SysFSRootDir = "invalidPath"
 		r.reconcile()
+		assert.Equal(t, 1, r.initFailures)
 		system.Conf.SysFSRootDir = validSysFSRootDir
+		r.reconcile()
+		assert.Equal(t, uint64(1), getCgroupReconcileDurationSampleCount(t, metrics.CgroupReconcileResourceResctrl))
 
+		// retry and resume after the backoff
+		fakeClock.Step(resctrlInitMinBackoff)
 		r.reconcile()
+		assert.Equal(t, 0, r.initFailures)
+		assert.Equal(t, uint64(2), getCgroupReconcileDurationSampleCount(t, metrics.CgroupReconcileResourceResctrl))
 
 		// test strategy parse error
 		r.resManager.nodeSLO.Spec.ResourceQoSStrategy = nil
@@ -1221,6 +1523,71 @@ func TestResctrlReconcile_reconcile(t *testing.T) {
 	})
 }
 
+func TestResctrlReconcile_reconcile_resctrlUnavailable(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.WriteProcSubFileContents("cpuinfo", "flags		: fpu vme de pse cat_l3 mba")
+	helper.WriteProcSubFileContents("cmdline", "BOOT_IMAGE=/boot/vmlinuz rdt=cmt,l3cat,mba")
+
+	// resctrl is not mounted at the configured root dir
+	system.Conf.ResctrlRootDir = filepath.Join(helper.TempDir, "fake-resctrl")
+	defer func() { system.Conf.ResctrlRootDir = "" }()
+
+	cfg := NewDefaultConfig()
+	cfg.ResctrlAutoMount = false
+	rm := &resmanager{
+		config: cfg,
+		nodeSLO: &slov1alpha1.NodeSLO{
+			Spec: slov1alpha1.NodeSLOSpec{ResourceQoSStrategy: util.DefaultResourceQoSStrategy()},
+		},
+	}
+	r := NewResctrlReconcile(rm)
+	stop := make(chan struct{})
+	r.RunInit(stop)
+	defer func() { stop <- struct{}{} }()
+
+	metrics.Register(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	defer metrics.Register(nil)
+	metrics.CgroupReconcileDuration.Reset()
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	r.clock = fakeClock
+
+	r.reconcile()
+	assert.Equal(t, 1, r.initFailures)
+	_, err := os.Stat(system.Conf.ResctrlRootDir)
+	assert.True(t, os.IsNotExist(err), "resctrl root dir should not be created")
+
+	// skip quietly within the backoff
+	r.reconcile()
+	assert.Equal(t, 1, r.initFailures)
+
+	// retry after the backoff, which doubles on the consecutive failure
+	fakeClock.Step(resctrlInitMinBackoff)
+	r.reconcile()
+	assert.Equal(t, 2, r.initFailures)
+	assert.Equal(t, fakeClock.Now().Add(2*resctrlInitMinBackoff), r.nextInitTime)
+	assert.Equal(t, uint64(0), getCgroupReconcileDurationSampleCount(t, metrics.CgroupReconcileResourceResctrl))
+}
+
+func TestResctrlReconcile_getInitBackoff(t *testing.T) {
+	tests := []struct {
+		name         string
+		initFailures int
+		want         time.Duration
+	}{
+		{name: "first failure", initFailures: 1, want: resctrlInitMinBackoff},
+		{name: "double on the consecutive failure", initFailures: 3, want: 4 * resctrlInitMinBackoff},
+		{name: "capped at the max backoff", initFailures: 100, want: resctrlInitMaxBackoff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ResctrlReconcile{initFailures: tt.initFailures}
+			assert.Equal(t, tt.want, r.getInitBackoff())
+		})
+	}
+}
+
 func Test_calculateMbaPercentForGroup(t *testing.T) {
 
 	type args struct {
@@ -1280,6 +1647,120 @@ func Test_calculateMbaPercentForGroup(t *testing.T) {
 	}
 }
 
+func Test_calculateMbaMBpsForGroup(t *testing.T) {
+	type args struct {
+		group  string
+		mbMBps *int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "mbMBps not config",
+			args: args{
+				group: "BE",
+			},
+			want: "",
+		},
+		{
+			name: "mbMBps value is invalid, not positive",
+			args: args{
+				group:  "BE",
+				mbMBps: pointer.Int64Ptr(0),
+			},
+			want: "",
+		},
+		{
+			name: "mbMBps value is valid",
+			args: args{
+				group:  "BE",
+				mbMBps: pointer.Int64Ptr(1500),
+			},
+			want: "1500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateMbaMBpsForGroup(tt.args.group, tt.args.mbMBps)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_calculateMbaValueForGroup(t *testing.T) {
+	type args struct {
+		group      string
+		resctrlQoS *slov1alpha1.ResctrlQoS
+		isMBpsMode bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "use percent in percentage mode",
+			args: args{
+				group:      "BE",
+				resctrlQoS: &slov1alpha1.ResctrlQoS{MBAPercent: pointer.Int64Ptr(80)},
+			},
+			want:    "80",
+			wantErr: false,
+		},
+		{
+			name: "ignore MBps in percentage mode",
+			args: args{
+				group:      "BE",
+				resctrlQoS: &slov1alpha1.ResctrlQoS{MBAMBps: pointer.Int64Ptr(1500)},
+			},
+			want:    "",
+			wantErr: false,
+		},
+		{
+			name: "use MBps in MBps mode",
+			args: args{
+				group:      "BE",
+				resctrlQoS: &slov1alpha1.ResctrlQoS{MBAMBps: pointer.Int64Ptr(1500)},
+				isMBpsMode: true,
+			},
+			want:    "1500",
+			wantErr: false,
+		},
+		{
+			name: "ignore percent in MBps mode",
+			args: args{
+				group:      "BE",
+				resctrlQoS: &slov1alpha1.ResctrlQoS{MBAPercent: pointer.Int64Ptr(80)},
+				isMBpsMode: true,
+			},
+			want:    "",
+			wantErr: false,
+		},
+		{
+			name: "throw an error when both set",
+			args: args{
+				group: "BE",
+				resctrlQoS: &slov1alpha1.ResctrlQoS{
+					MBAPercent: pointer.Int64Ptr(80),
+					MBAMBps:    pointer.Int64Ptr(1500),
+				},
+			},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateMbaValueForGroup(tt.args.group, tt.args.resctrlQoS, tt.args.isMBpsMode)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func Test_calculateL3SchemataResource(t *testing.T) {
 	t.Run("test", func(t *testing.T) {
 		helper := system.NewFileTestUtil(t)
@@ -18,25 +18,35 @@ package resmanager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	policylisterv1 "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/component-base/featuregate"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	slolisterv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
@@ -53,10 +63,15 @@ import (
 const (
 	evictPodSuccess = "evictPodSuccess"
 	evictPodFail    = "evictPodFail"
+
+	// killContainersWorkers is the max number of containers of a pod to stop concurrently
+	killContainersWorkers = 4
 )
 
 type ResManager interface {
 	Run(stopCh <-chan struct{}) error
+	// Healthz returns an error if any running feature loop has not completed a cycle in time.
+	Healthz() error
 }
 
 type resmanager struct {
@@ -67,14 +82,54 @@ type resmanager struct {
 	statesInformer                statesinformer.StatesInformer
 	metricCache                   metriccache.MetricCache
 	podsEvicted                   *expireCache.Cache
-	nodeSLOInformer               cache.SharedIndexInformer
-	nodeSLOLister                 slolisterv1alpha1.NodeSLOLister
-	kubeClient                    clientset.Interface
-	eventRecorder                 record.EventRecorder
+	evictFailEvents               *expireCache.Cache
+	// ownersEvicted records the controller owners of the evicted pods during the cooldown, which is nil if disabled
+	ownersEvicted *expireCache.Cache
+	// evictionHistory buffers the evictions to record in the history ConfigMap, which is nil if disabled
+	evictionHistory   *evictionHistoryRecorder
+	beOverloadTainter *BEOverloadTainter
+	nodeSLOInformer   cache.SharedIndexInformer
+	nodeSLOLister     slolisterv1alpha1.NodeSLOLister
+	pdbInformer       cache.SharedIndexInformer
+	pdbLister         policylisterv1.PodDisruptionBudgetLister
+	namespaceInformer cache.SharedIndexInformer
+	namespaceLister   corelisterv1.NamespaceLister
+	kubeClient        clientset.Interface
+	eventRecorder     record.EventRecorder
+	// writeRateLimiter throttles the writes to the apiserver, while the reads from informers are not limited
+	writeRateLimiter flowcontrol.RateLimiter
+	// evictSlots bounds the evictions in flight across all the features, which is nil if not limited
+	evictSlots chan struct{}
+	// nodeSLOUpdateCoalescer coalesces the rapid NodeSLO updates so that only the latest spec is applied
+	nodeSLOUpdateCoalescer *nodeSLOUpdateCoalescer
+	// decisionLog retains the latest decisions of the reconcilers, which is nil if disabled
+	decisionLog *decisionLog
+	// featureHealth tracks the liveness of the feature loops
+	featureHealth *featureHealth
+	// featurePause holds the feature loops paused by the debug http handler
+	featurePause *featurePause
+	// nodeSLOApply measures the latency of the features applying the NodeSLO spec updates
+	nodeSLOApply *nodeSLOApplyTracker
+	// pressureState is marked by the memory eviction for the cpu burst to back off, which is nil if disabled
+	pressureState *nodePressureState
+	// memoryHighScale is the memory.high scale ratio of be containers applied by the cgroup reconciliation
+	memoryHighScale memoryHighScaleState
+	// defaultNodeSLOSpec is the default spec to merge the NodeSLO with, which is the built-in one if nil
+	defaultNodeSLOSpec *slov1alpha1.NodeSLOSpec
+	// enforcementGrace is the grace after the pod start for each qos class parsed from EnforcementGraceSeconds
+	enforcementGrace map[apiext.QoSClass]time.Duration
+	// enforcementPodSelector is parsed from EnforcementPodSelector, which is nil if all pods are enforced
+	enforcementPodSelector labels.Selector
+	// evictionV1UnavailableTime is the unix nano time the policy/v1 Eviction API is found unavailable on the
+	// apiserver, which is 0 if it is available
+	evictionV1UnavailableTime int64
 
 	// nodeSLO stores the latest nodeSLO object for the current node
 	nodeSLO        *slov1alpha1.NodeSLO
 	nodeSLORWMutex sync.RWMutex
+	// nodeSLOAppliedUID and nodeSLOAppliedGeneration are of the last nodeSLO object merged into nodeSLO
+	nodeSLOAppliedUID        types.UID
+	nodeSLOAppliedGeneration int64
 }
 
 func newNodeSLOInformer(client koordclientset.Interface, nodeName string) cache.SharedIndexInformer {
@@ -99,49 +154,102 @@ func newNodeSLOInformer(client koordclientset.Interface, nodeName string) cache.
 }
 
 // mergeNodeSLOSpec merges nodeSLO with default config; ensure use the function with a RWMutex
-func (r *resmanager) mergeNodeSLOSpec(nodeSLO *slov1alpha1.NodeSLO) {
+// r.nodeSLO keeps unchanged if an error is returned
+func (r *resmanager) mergeNodeSLOSpec(nodeSLO *slov1alpha1.NodeSLO) error {
 	if r.nodeSLO == nil || nodeSLO == nil {
-		klog.Errorf("failed to merge with nil nodeSLO, old: %v, new: %v", r.nodeSLO, nodeSLO)
-		return
+		return fmt.Errorf("failed to merge with nil nodeSLO, old: %v, new: %v", r.nodeSLO, nodeSLO)
+	}
+	if err := validateNodeSLOSpec(&nodeSLO.Spec); err != nil {
+		return fmt.Errorf("failed to merge with invalid nodeSLO spec, err: %v", err)
 	}
 
+	defaultSpec := r.getDefaultNodeSLOSpec()
+
 	// merge ResourceUsedThresholdWithBE individually for nil-ResourceUsedThresholdWithBE case
-	mergedResourceUsedThresholdWithBESpec := mergeSLOSpecResourceUsedThresholdWithBE(util.DefaultNodeSLOSpecConfig().ResourceUsedThresholdWithBE,
+	mergedResourceUsedThresholdWithBESpec := mergeSLOSpecResourceUsedThresholdWithBE(defaultSpec.ResourceUsedThresholdWithBE,
 		nodeSLO.Spec.ResourceUsedThresholdWithBE)
 	if mergedResourceUsedThresholdWithBESpec != nil {
 		r.nodeSLO.Spec.ResourceUsedThresholdWithBE = mergedResourceUsedThresholdWithBESpec
 	}
 
+	// merge ShadowResourceUsedThresholdWithBE only if it is set, which is evaluated without being applied
+	if nodeSLO.Spec.ShadowResourceUsedThresholdWithBE != nil {
+		r.nodeSLO.Spec.ShadowResourceUsedThresholdWithBE = mergeSLOSpecResourceUsedThresholdWithBE(
+			defaultSpec.ResourceUsedThresholdWithBE, nodeSLO.Spec.ShadowResourceUsedThresholdWithBE)
+	}
+
 	// merge ResourceQoSStrategy
-	mergedResourceQoSStrategySpec := mergeSLOSpecResourceQoSStrategy(util.DefaultNodeSLOSpecConfig().ResourceQoSStrategy,
+	mergedResourceQoSStrategySpec := mergeSLOSpecResourceQoSStrategy(defaultSpec.ResourceQoSStrategy,
 		nodeSLO.Spec.ResourceQoSStrategy)
+	mergeResctrlMBAConfig(mergedResourceQoSStrategySpec, nodeSLO.Spec.ResourceQoSStrategy)
 	mergeNoneResourceQoSIfDisabled(mergedResourceQoSStrategySpec)
 	if mergedResourceQoSStrategySpec != nil {
 		r.nodeSLO.Spec.ResourceQoSStrategy = mergedResourceQoSStrategySpec
 	}
 
 	// merge CPUBurstStrategy
-	mergedCPUBurstStrategySpec := mergeSLOSpecCPUBurstStrategy(util.DefaultNodeSLOSpecConfig().CPUBurstStrategy,
+	mergedCPUBurstStrategySpec := mergeSLOSpecCPUBurstStrategy(defaultSpec.CPUBurstStrategy,
 		nodeSLO.Spec.CPUBurstStrategy)
 	if mergedCPUBurstStrategySpec != nil {
 		r.nodeSLO.Spec.CPUBurstStrategy = mergedCPUBurstStrategySpec
 	}
+	return nil
+}
+
+// isNodeSLOGenerationApplied returns whether the generation of the nodeSLO has been merged, e.g. the nodeSLO is
+// received again on a resync without a real change; ensure use the function with a RWMutex.
+// The nodeSLO without a generation is never taken as applied.
+func (r *resmanager) isNodeSLOGenerationApplied(nodeSLO *slov1alpha1.NodeSLO) bool {
+	if r.nodeSLO == nil || nodeSLO == nil || nodeSLO.Generation <= 0 {
+		return false
+	}
+	return nodeSLO.UID == r.nodeSLOAppliedUID && nodeSLO.Generation <= r.nodeSLOAppliedGeneration
+}
+
+func (r *resmanager) setNodeSLOGenerationApplied(nodeSLO *slov1alpha1.NodeSLO) {
+	r.nodeSLOAppliedUID = nodeSLO.UID
+	r.nodeSLOAppliedGeneration = nodeSLO.Generation
 }
 
 func (r *resmanager) createNodeSLO(nodeSLO *slov1alpha1.NodeSLO) {
 	r.nodeSLORWMutex.Lock()
 	defer r.nodeSLORWMutex.Unlock()
 
-	oldNodeSLOStr := util.DumpJSON(r.nodeSLO)
+	if r.isNodeSLOGenerationApplied(nodeSLO) {
+		klog.V(5).Infof("skip creating nodeSLO %s, generation %v has been applied", nodeSLO.Name, nodeSLO.Generation)
+		return
+	}
+
+	oldNodeSLO := r.nodeSLO
 
 	r.nodeSLO = nodeSLO.DeepCopy()
-	r.nodeSLO.Spec = nodeSLO.Spec
 
 	// merge nodeSLO spec with the default config
-	r.mergeNodeSLOSpec(nodeSLO)
+	if err := r.mergeNodeSLOSpec(nodeSLO); err != nil {
+		r.nodeSLO = oldNodeSLO
+		metrics.RecordNodeSLOMergeFailed()
+		klog.Errorf("skip creating nodeSLO and keep the previous config, err: %v", err)
+		klog.V(5).Infof("keep the previous nodeSLO content: %s", util.DumpJSON(oldNodeSLO))
+		return
+	}
+
+	r.setNodeSLOGenerationApplied(nodeSLO)
+	logNodeSLOChange("create", oldNodeSLO, r.nodeSLO)
+	r.nodeSLOApply.specChanged()
+	metrics.RecordNodeSLOSpecInfo(hashNodeSLOSpec(&r.nodeSLO.Spec))
+
+	r.saveFallbackNodeSLO(nodeSLO)
+}
+
+// isFeatureEnabledByNodeSLO returns whether the feature is enabled at runtime by the feature gates of the NodeSLO
+func (r *resmanager) isFeatureEnabledByNodeSLO(feature featuregate.Feature) bool {
+	r.nodeSLORWMutex.RLock()
+	defer r.nodeSLORWMutex.RUnlock()
 
-	newNodeSLOStr := util.DumpJSON(r.nodeSLO)
-	klog.Infof("update nodeSLO content: old %s, new %s", oldNodeSLOStr, newNodeSLOStr)
+	if r.nodeSLO == nil {
+		return false
+	}
+	return r.nodeSLO.Spec.FeatureGates[string(feature)]
 }
 
 func (r *resmanager) getNodeSLOCopy() *slov1alpha1.NodeSLO {
@@ -159,15 +267,35 @@ func (r *resmanager) updateNodeSLOSpec(nodeSLO *slov1alpha1.NodeSLO) {
 	r.nodeSLORWMutex.Lock()
 	defer r.nodeSLORWMutex.Unlock()
 
-	oldNodeSLOStr := util.DumpJSON(r.nodeSLO)
+	if r.isNodeSLOGenerationApplied(nodeSLO) {
+		klog.V(5).Infof("skip updating nodeSLO %s spec, generation %v has been applied", nodeSLO.Name, nodeSLO.Generation)
+		r.nodeSLOApply.specRejected()
+		return
+	}
 
-	r.nodeSLO.Spec = nodeSLO.Spec
+	oldNodeSLO := r.nodeSLO
+
+	if oldNodeSLO != nil && nodeSLO != nil {
+		r.nodeSLO = oldNodeSLO.DeepCopy()
+		r.nodeSLO.Spec = *nodeSLO.Spec.DeepCopy()
+	}
 
 	// merge nodeSLO spec with the default config
-	r.mergeNodeSLOSpec(nodeSLO)
+	if err := r.mergeNodeSLOSpec(nodeSLO); err != nil {
+		r.nodeSLO = oldNodeSLO
+		metrics.RecordNodeSLOMergeFailed()
+		klog.Errorf("skip updating nodeSLO spec and keep the previous config, err: %v", err)
+		klog.V(5).Infof("keep the previous nodeSLO content: %s", util.DumpJSON(oldNodeSLO))
+		r.nodeSLOApply.specRejected()
+		return
+	}
 
-	newNodeSLOStr := util.DumpJSON(r.nodeSLO)
-	klog.Infof("update nodeSLO content: old %s, new %s", oldNodeSLOStr, newNodeSLOStr)
+	r.setNodeSLOGenerationApplied(nodeSLO)
+	logNodeSLOChange("update", oldNodeSLO, r.nodeSLO)
+	r.nodeSLOApply.specChanged()
+	metrics.RecordNodeSLOSpecInfo(hashNodeSLOSpec(&r.nodeSLO.Spec))
+
+	r.saveFallbackNodeSLO(nodeSLO)
 }
 
 func NewResManager(cfg *Config, schema *apiruntime.Scheme, kubeClient clientset.Interface, crdClient *koordclientset.Clientset, nodeName string,
@@ -178,25 +306,54 @@ func NewResManager(cfg *Config, schema *apiruntime.Scheme, kubeClient clientset.
 	eventBroadcaster.StartRecordingToSink(&clientcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(schema, corev1.EventSource{Component: "slo-agent-reporter", Host: nodeName})
 
+	featureHealth := newFeatureHealth()
 	r := &resmanager{
 		config:                        cfg,
 		nodeName:                      nodeName,
 		schema:                        schema,
 		statesInformer:                statesInformer,
 		metricCache:                   metricCache,
-		podsEvicted:                   expireCache.NewCacheDefault(),
+		podsEvicted:                   expireCache.NewCache(time.Duration(cfg.EvictionDedupTTLSeconds)*time.Second, time.Minute),
+		evictFailEvents:               expireCache.NewCache(time.Duration(cfg.EvictFailEventIntervalSeconds)*time.Second, time.Minute),
 		nodeSLOInformer:               informer,
 		nodeSLOLister:                 slolisterv1alpha1.NewNodeSLOLister(informer.GetIndexer()),
 		kubeClient:                    kubeClient,
 		eventRecorder:                 recorder,
+		writeRateLimiter:              newWriteRateLimiter(cfg),
+		evictSlots:                    newEvictSlots(cfg),
+		evictionHistory:               newEvictionHistoryRecorder(cfg),
+		decisionLog:                   newDecisionLog(cfg.ReconcileDecisionLogSize),
+		featureHealth:                 featureHealth,
+		featurePause:                  newFeaturePause(featureHealth.isRunning),
+		nodeSLOApply:                  newNodeSLOApplyTracker(),
+		pressureState:                 newNodePressureState(cfg),
 		collectResUsedIntervalSeconds: collectResUsedIntervalSeconds,
 	}
+	if cfg.EvictPDBPreflight {
+		r.pdbInformer = newPDBInformer(kubeClient)
+		r.pdbLister = policylisterv1.NewPodDisruptionBudgetLister(r.pdbInformer.GetIndexer())
+	}
+	if cfg.EvictOwnerCooldownSeconds > 0 {
+		r.ownersEvicted = expireCache.NewCache(time.Duration(cfg.EvictOwnerCooldownSeconds)*time.Second, time.Minute)
+	}
+	if cfg.NamespaceMemoryQoSPolicy {
+		r.namespaceInformer = newNamespaceInformer(kubeClient)
+		r.namespaceLister = corelisterv1.NewNamespaceLister(r.namespaceInformer.GetIndexer())
+	}
+	defaultDecisionLog.set(r.decisionLog)
+	defaultFeatureHealth.set(r.featureHealth)
+	defaultFeaturePause.set(r.featurePause)
+	r.nodeSLOUpdateCoalescer = newNodeSLOUpdateCoalescer(time.Duration(cfg.NodeSLOUpdateCoalesceSeconds)*time.Second,
+		func(nodeSLO *slov1alpha1.NodeSLO) {
+			r.updateNodeSLOSpec(nodeSLO)
+			r.recordFeatureStatuses()
+		})
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			nodeSLO, ok := obj.(*slov1alpha1.NodeSLO)
 			if ok {
 				r.createNodeSLO(nodeSLO)
-				klog.Infof("create NodeSLO %v", nodeSLO)
+				klog.V(4).Infof("create NodeSLO %s", nodeSLO.Name)
 			} else {
 				klog.Errorf("node slo informer add func parse nodeSLO failed")
 			}
@@ -212,8 +369,9 @@ func NewResManager(cfg *Config, schema *apiruntime.Scheme, kubeClient clientset.
 				klog.V(5).Infof("find NodeSLO spec %s has not changed", newNodeSLO.Name)
 				return
 			}
-			klog.Infof("update NodeSLO spec %v", newNodeSLO.Spec)
-			r.updateNodeSLOSpec(newNodeSLO)
+			klog.V(4).Infof("receive NodeSLO %s spec update", newNodeSLO.Name)
+			r.nodeSLOApply.received()
+			r.nodeSLOUpdateCoalescer.enqueue(newNodeSLO)
 		},
 	})
 
@@ -222,13 +380,13 @@ func NewResManager(cfg *Config, schema *apiruntime.Scheme, kubeClient clientset.
 
 // isFeatureDisabled returns whether the featuregate is disabled by nodeSLO config
 func isFeatureDisabled(nodeSLO *slov1alpha1.NodeSLO, feature featuregate.Feature) (bool, error) {
-	if nodeSLO == nil || nodeSLO.Spec == (slov1alpha1.NodeSLOSpec{}) {
+	if nodeSLO == nil || reflect.DeepEqual(nodeSLO.Spec, slov1alpha1.NodeSLOSpec{}) {
 		return true, fmt.Errorf("cannot parse feature config for invalid nodeSLO %v", nodeSLO)
 	}
 
 	spec := nodeSLO.Spec
 	switch feature {
-	case features.BECPUSuppress, features.BEMemoryEvict:
+	case features.BECPUSuppress, features.BEMemoryEvict, features.BEDiskEvict:
 		if spec.ResourceUsedThresholdWithBE == nil || spec.ResourceUsedThresholdWithBE.Enable == nil {
 			return true, fmt.Errorf("cannot parse feature config for invalid nodeSLO %v", nodeSLO)
 		}
@@ -238,44 +396,120 @@ func isFeatureDisabled(nodeSLO *slov1alpha1.NodeSLO, feature featuregate.Feature
 	}
 }
 
+// runFeature runs the feature loop gated by the NodeSLO, and tracks its liveness and the NodeSLO spec it applies.
+func (r *resmanager) runFeature(moduleInit func() error, moduleFunc func(), feature featuregate.Feature, interval int,
+	stopCh <-chan struct{}) {
+	r.featureHealth.runFeature(moduleInit, r.featurePause.wrap(feature, r.nodeSLOApply.track(feature, moduleFunc)), feature,
+		r.isFeatureEnabledByNodeSLO, interval, stopCh)
+}
+
+func (r *resmanager) Healthz() error {
+	return r.featureHealth.healthz()
+}
+
 func (r *resmanager) Run(stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 	klog.Info("Starting resmanager")
 
+	util.FeatureJitterFactor = r.config.FeatureJitterFactor
+	switch r.config.EvictAPIUnavailableFallback {
+	case "", EvictAPIFallbackV1beta1, EvictAPIFallbackDelete:
+	default:
+		return fmt.Errorf("unsupported EvictAPIUnavailableFallback %q, should be v1beta1, delete or empty",
+			r.config.EvictAPIUnavailableFallback)
+	}
+
+	if err := r.loadDefaultNodeSLOSpec(); err != nil {
+		return err
+	}
+	enforcementGrace, err := parseEnforcementGraceSeconds(r.config.EnforcementGraceSeconds)
+	if err != nil {
+		return err
+	}
+	r.enforcementGrace = enforcementGrace
+	enforcementPodSelector, err := parseEnforcementPodSelector(r.config.EnforcementPodSelector)
+	if err != nil {
+		return err
+	}
+	r.enforcementPodSelector = enforcementPodSelector
+
 	r.podsEvicted.Run(stopCh)
+	r.evictFailEvents.Run(stopCh)
+	if r.ownersEvicted != nil {
+		r.ownersEvicted.Run(stopCh)
+	}
+	if r.evictionHistory != nil {
+		go r.runEvictionHistoryWorker(stopCh)
+	}
 
 	klog.Infof("starting informer for NodeSLO")
 	go r.nodeSLOInformer.Run(stopCh)
-	if !cache.WaitForCacheSync(stopCh, r.nodeSLOInformer.HasSynced) {
-		return fmt.Errorf("time out waiting for node slo caches to sync")
+	if err := r.waitForNodeSLOSync(stopCh); err != nil {
+		return err
+	}
+	if r.pdbInformer != nil {
+		// the pdb preflight is best-effort and skipped until the informer synced, so do not wait for it
+		klog.Infof("starting informer for PodDisruptionBudget")
+		go r.pdbInformer.Run(stopCh)
+	}
+	if r.namespaceInformer != nil {
+		// the pods inherit the node-level memory qos until the informer synced, and get reconciled periodically
+		klog.Infof("starting informer for Namespace")
+		go r.namespaceInformer.Run(stopCh)
 	}
 
 	if !cache.WaitForCacheSync(stopCh, r.statesInformer.HasSynced) {
 		return fmt.Errorf("time out waiting for kubelet meta service caches to sync")
 	}
-	if !cache.WaitForCacheSync(stopCh, r.hasSynced) {
-		return fmt.Errorf("time out waiting for sync NodeSLO")
+	if err := r.waitForNodeSLO(stopCh, time.Duration(r.config.NodeSLOStartupTimeoutSeconds)*time.Second); err != nil {
+		return err
 	}
 
-	util.RunFeature(r.reconcileBECgroup, []featuregate.Feature{features.BECgroupReconcile}, r.config.ReconcileIntervalSeconds, stopCh)
+	noInit := func() error { return nil }
+	r.runFeature(noInit, r.reconcileBECgroup, features.BECgroupReconcile, r.config.ReconcileIntervalSeconds, stopCh)
 
 	cgroupResourceReconcile := NewCgroupResourcesReconcile(r)
-	util.RunFeatureWithInit(func() error { return cgroupResourceReconcile.RunInit(stopCh) }, cgroupResourceReconcile.reconcile,
-		[]featuregate.Feature{features.CgroupReconcile}, r.config.ReconcileIntervalSeconds, stopCh)
+	r.runFeature(func() error { return cgroupResourceReconcile.RunInit(stopCh) }, cgroupResourceReconcile.reconcile,
+		features.CgroupReconcile, r.config.ReconcileIntervalSeconds, stopCh)
 
 	cpuSuppress := NewCPUSuppress(r)
-	util.RunFeature(cpuSuppress.suppressBECPU, []featuregate.Feature{features.BECPUSuppress}, r.config.CPUSuppressIntervalSeconds, stopCh)
+	r.runFeature(noInit, cpuSuppress.suppressBECPU, features.BECPUSuppress, r.config.CPUSuppressIntervalSeconds, stopCh)
 
 	cpuBurst := NewCPUBurst(r)
-	util.RunFeatureWithInit(func() error { return cpuBurst.init(stopCh) }, cpuBurst.start,
-		[]featuregate.Feature{features.CPUBurst}, r.config.ReconcileIntervalSeconds, stopCh)
+	r.runFeature(func() error { return cpuBurst.init(stopCh) }, cpuBurst.start,
+		features.CPUBurst, r.config.ReconcileIntervalSeconds, stopCh)
+
+	// create the tainter before running the evictors, since it records the be evictions
+	r.beOverloadTainter = NewBEOverloadTainter(r)
+	r.runFeature(noInit, r.beOverloadTainter.reconcile, features.BEOverloadTaint, r.config.ReconcileIntervalSeconds, stopCh)
 
 	memoryEvictor := NewMemoryEvictor(r)
-	util.RunFeature(memoryEvictor.memoryEvict, []featuregate.Feature{features.BEMemoryEvict}, r.config.MemoryEvictIntervalSeconds, stopCh)
+	defaultMemoryEvictionPlanner.set(memoryEvictor)
+	r.runFeature(noInit, memoryEvictor.memoryEvict, features.BEMemoryEvict, r.config.MemoryEvictIntervalSeconds, stopCh)
+
+	diskEvictor := NewDiskEvictor(r)
+	r.runFeature(noInit, diskEvictor.diskEvict, features.BEDiskEvict, r.config.DiskEvictIntervalSeconds, stopCh)
 
 	rdtResCtrl := NewResctrlReconcile(r)
-	util.RunFeatureWithInit(func() error { return rdtResCtrl.RunInit(stopCh) }, rdtResCtrl.reconcile,
-		[]featuregate.Feature{features.RdtResctrl}, r.config.ReconcileIntervalSeconds, stopCh)
+	r.runFeature(func() error { return rdtResCtrl.RunInit(stopCh) }, rdtResCtrl.reconcile,
+		features.RdtResctrl, r.config.ReconcileIntervalSeconds, stopCh)
+
+	rdtMonitor := NewResctrlMonitor(r)
+	r.runFeature(func() error { return rdtMonitor.RunInit(stopCh) }, rdtMonitor.monitor,
+		features.RdtMonitor, r.config.ReconcileIntervalSeconds, stopCh)
+
+	oomQuarantine := NewOOMQuarantine(r)
+	r.runFeature(func() error { return oomQuarantine.RunInit(stopCh) }, oomQuarantine.quarantine,
+		features.BEOOMQuarantine, r.config.ReconcileIntervalSeconds, stopCh)
+
+	qosDriftAuditor := NewQoSDriftAuditor(r)
+	r.runFeature(noInit, qosDriftAuditor.audit, features.QoSDriftAudit, r.config.QoSDriftAuditIntervalSeconds, stopCh)
+
+	qosUsageExporter := NewQoSUsageExporter(r)
+	r.runFeature(noInit, qosUsageExporter.export, features.QoSUsageMetrics, r.config.ReconcileIntervalSeconds, stopCh)
+
+	defaultFeatureStatus.set(r)
+	r.recordFeatureStatuses()
 
 	klog.Info("Starting resmanager successfully")
 	<-stopCh
@@ -290,6 +524,119 @@ func (r *resmanager) hasSynced() bool {
 	return r.nodeSLO != nil && r.nodeSLO.Spec.ResourceUsedThresholdWithBE != nil
 }
 
+// isEnforcementEligible returns whether the pod can be handled by the qos enforcement and eviction.
+// Mirror pods and terminating pods are always excluded, and DaemonSet pods are excluded when ExcludeDaemonSetPods is
+// set. The pods not matching the EnforcementPodSelector are excluded if it is set.
+func (r *resmanager) isEnforcementEligible(pod *corev1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	// the terminating pods are leaving the node anyway, neither evict them nor re-apply their qos
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	if r.enforcementPodSelector != nil && !r.enforcementPodSelector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	if r.config != nil && r.config.ExcludeDaemonSetPods {
+		if ownerRef := metav1.GetControllerOf(pod); ownerRef != nil && ownerRef.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseEnforcementPodSelector parses the label selector of the enforced pods, e.g. "app in (web),tier!=batch".
+// It returns nil if the selector is empty.
+func parseEnforcementPodSelector(selector string) (labels.Selector, error) {
+	if len(selector) <= 0 {
+		return nil, nil
+	}
+	podSelector, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("illegal EnforcementPodSelector %q, error: %v", selector, err)
+	}
+	return podSelector, nil
+}
+
+// parseEnforcementGraceSeconds parses the grace seconds by the qos class name, e.g. {"LS": "60"}.
+func parseEnforcementGraceSeconds(graceSeconds map[string]string) (map[apiext.QoSClass]time.Duration, error) {
+	enforcementGrace := make(map[apiext.QoSClass]time.Duration, len(graceSeconds))
+	for qosName, secondsStr := range graceSeconds {
+		qosClass := apiext.QoSClass(qosName)
+		switch qosClass {
+		case apiext.QoSLSE, apiext.QoSLSR, apiext.QoSLS, apiext.QoSBE, apiext.QoSSystem:
+		default:
+			return nil, fmt.Errorf("unsupported qos class %q in EnforcementGraceSeconds", qosName)
+		}
+		seconds, err := strconv.ParseInt(secondsStr, 10, 64)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("illegal grace seconds %q of qos class %s in EnforcementGraceSeconds", secondsStr, qosName)
+		}
+		enforcementGrace[qosClass] = time.Duration(seconds) * time.Second
+	}
+	return enforcementGrace, nil
+}
+
+// isPodInEnforcementGrace returns whether the pod started within the enforcement grace of its qos class at now,
+// during which the qos limits are relaxed for the startup burst of the pod.
+func (r *resmanager) isPodInEnforcementGrace(pod *corev1.Pod, now time.Time) bool {
+	if pod == nil || pod.Status.StartTime == nil {
+		return false
+	}
+	grace := r.enforcementGrace[apiext.GetPodQoSClass(pod)]
+	return grace > 0 && now.Before(pod.Status.StartTime.Add(grace))
+}
+
+func newWriteRateLimiter(cfg *Config) flowcontrol.RateLimiter {
+	if cfg.APIServerWriteQPS <= 0 {
+		return flowcontrol.NewFakeAlwaysRateLimiter()
+	}
+	return flowcontrol.NewTokenBucketRateLimiter(float32(cfg.APIServerWriteQPS), cfg.APIServerWriteBurst)
+}
+
+// getSuppressReleaseStepPercent returns the percent of the gap to restore in each cycle when releasing the suppression,
+// or 0 if the suppression should be released at once.
+func (r *resmanager) getSuppressReleaseStepPercent() int64 {
+	if r.config == nil || r.config.SuppressReleaseStepPercent <= 0 || r.config.SuppressReleaseStepPercent >= 100 {
+		return 0
+	}
+	return int64(r.config.SuppressReleaseStepPercent)
+}
+
+// throttleWrite blocks until the next apiserver write is allowed by the write rate limiter
+func (r *resmanager) throttleWrite() {
+	if r.writeRateLimiter == nil {
+		return
+	}
+	r.writeRateLimiter.Accept()
+}
+
+func newEvictSlots(cfg *Config) chan struct{} {
+	if cfg.EvictMaxInFlight <= 0 {
+		return nil
+	}
+	return make(chan struct{}, cfg.EvictMaxInFlight)
+}
+
+// acquireEvictSlot blocks until the number of the evictions in flight is below the limit.
+func (r *resmanager) acquireEvictSlot() {
+	if r.evictSlots == nil {
+		return
+	}
+	r.evictSlots <- struct{}{}
+}
+
+func (r *resmanager) releaseEvictSlot() {
+	if r.evictSlots == nil {
+		return
+	}
+	<-r.evictSlots
+}
+
 func (r *resmanager) evictPodsIfNotEvicted(evictPods []*corev1.Pod, node *corev1.Node, reason string, message string) {
 	for _, evictPod := range evictPods {
 		r.evictPodIfNotEvicted(evictPod, node, reason, message)
@@ -310,52 +657,258 @@ func (r *resmanager) evictPodIfNotEvicted(evictPod *corev1.Pod, node *corev1.Nod
 
 func (r *resmanager) evictPod(evictPod *corev1.Pod, node *corev1.Node, reason string, message string) bool {
 	podEvictMessage := fmt.Sprintf("evict Pod:%s, reason: %s, message: %v", evictPod.Name, reason, message)
+	if allowed, pdbMessage := r.checkEvictionByPDB(evictPod); !allowed {
+		skipMessage := fmt.Sprintf("skip evicting Pod:%s, reason: %s, message: %v", evictPod.Name, reason, pdbMessage)
+		if r.recordEvictPodEvent(evictPod, node, evictPodSkippedByPDB, reason, skipMessage) {
+			klog.Infof("skip evicting pod %v/%v, reason: %v, %v", evictPod.Namespace, evictPod.Name, reason, pdbMessage)
+		}
+		return false
+	}
+	if allowed, cooldownMessage := r.checkEvictionByOwnerCooldown(evictPod); !allowed {
+		skipMessage := fmt.Sprintf("skip evicting Pod:%s, reason: %s, message: %v", evictPod.Name, reason, cooldownMessage)
+		if r.recordEvictPodEvent(evictPod, node, evictPodSkippedByOwnerCooldown, reason, skipMessage) {
+			klog.Infof("skip evicting pod %v/%v, reason: %v, %v", evictPod.Namespace, evictPod.Name, reason, cooldownMessage)
+		}
+		return false
+	}
 	_ = audit.V(0).Pod(evictPod.Namespace, evictPod.Name).Reason(reason).Message(message).Do()
-	podEvict := policyv1.Eviction{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      evictPod.Name,
-			Namespace: evictPod.Namespace,
-		},
+	annotated := false
+	if r.config != nil && r.config.EvictAnnotatePod {
+		if err := r.annotatePodEviction(evictPod, reason, message); err != nil {
+			klog.Warningf("failed to annotate the eviction of pod %v/%v, error: %v", evictPod.Namespace, evictPod.Name, err)
+		} else {
+			annotated = true
+		}
 	}
-
-	if err := r.kubeClient.CoreV1().Pods(evictPod.Namespace).EvictV1(context.TODO(), &podEvict); err == nil {
-		r.eventRecorder.Eventf(node, corev1.EventTypeWarning, evictPodSuccess, podEvictMessage)
+	gracePeriodSeconds := r.getEvictGracePeriodSeconds(evictPod)
+	r.acquireEvictSlot()
+	err := r.evictOrForceDeletePod(evictPod, gracePeriodSeconds)
+	r.releaseEvictSlot()
+	if err == nil {
+		r.eventRecorder.Event(node, corev1.EventTypeWarning, evictPodSuccess, podEvictMessage)
 		metrics.RecordPodEviction(reason)
+		r.recordOwnerEviction(evictPod)
+		r.recordEvictionHistory(evictPod, reason, message)
+		if r.beOverloadTainter != nil && apiext.GetPodQoSClass(evictPod) == apiext.QoSBE {
+			r.beOverloadTainter.recordEviction(time.Now())
+		}
 		klog.Infof("evict pod %v/%v success, reason: %v", evictPod.Namespace, evictPod.Name, reason)
 		return true
 	} else if !errors.IsNotFound(err) {
-		r.eventRecorder.Eventf(node, corev1.EventTypeWarning, evictPodFail, podEvictMessage)
-		klog.Errorf("evict pod %v/%v failed, reason: %v, error: %v", evictPod.Namespace, evictPod.Name, reason, err)
+		// the pod keeps running, e.g. the eviction is rejected by a PDB, so the annotations should not be left on it
+		if annotated {
+			if err := r.clearPodEvictionAnnotations(evictPod); err != nil {
+				klog.Warningf("failed to clear the eviction annotations of pod %v/%v, error: %v",
+					evictPod.Namespace, evictPod.Name, err)
+			}
+		}
+		if r.recordEvictPodEvent(evictPod, node, evictPodFail, reason, podEvictMessage) {
+			klog.Errorf("evict pod %v/%v failed, reason: %v, error: %v", evictPod.Namespace, evictPod.Name, reason, err)
+		} else {
+			klog.V(4).Infof("evict pod %v/%v failed again, reason: %v, error: %v", evictPod.Namespace, evictPod.Name, reason, err)
+		}
 		return false
 	}
 	return true
 }
 
-// killContainers kills containers inside the pod
-func killContainers(pod *corev1.Pod, message string) {
-	for _, container := range pod.Spec.Containers {
-		containerID, containerStatus, err := util.FindContainerIdAndStatusByName(&pod.Status, container.Name)
-		if err != nil {
-			klog.Errorf("failed to find container id and status, error: %v", err)
-			return
+// isNodeConditionTrue returns whether the node reports the condition of the type as true.
+func isNodeConditionTrue(node *corev1.Node, conditionType corev1.NodeConditionType) bool {
+	if node == nil {
+		return false
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
 		}
+	}
+	return false
+}
 
-		if containerStatus == nil || containerStatus.State.Running == nil {
-			return
+// evictOrForceDeletePod evicts the pod with the grace period, or nil to use the terminationGracePeriodSeconds of the
+// pod. If EvictForceDeleteZeroGrace is enabled and the grace period in effect is zero, the pod is deleted directly with
+// a zero grace period, so it is terminated at once instead of waiting for the kubelet to kill it gracefully. A nil
+// terminationGracePeriodSeconds is taken as the default one of the apiserver, which is not zero.
+func (r *resmanager) evictOrForceDeletePod(pod *corev1.Pod, gracePeriodSeconds *int64) error {
+	effectiveGracePeriod := gracePeriodSeconds
+	if effectiveGracePeriod == nil {
+		effectiveGracePeriod = pod.Spec.TerminationGracePeriodSeconds
+	}
+	if r.config != nil && r.config.EvictForceDeleteZeroGrace && effectiveGracePeriod != nil && *effectiveGracePeriod == 0 {
+		klog.Infof("force delete pod %v/%v instead of evicting, since its grace period is zero", pod.Namespace, pod.Name)
+		r.throttleWrite()
+		return r.kubeClient.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{
+			GracePeriodSeconds: pointer.Int64Ptr(0),
+			Preconditions:      metav1.NewUIDPreconditions(string(pod.UID)),
+		})
+	}
+	return r.evictPodByAPI(pod, gracePeriodSeconds)
+}
+
+// annotatePodEviction patches the eviction reason and message on the pod, so the controllers watching the terminating
+// pod can tell it is evicted by koordlet and why.
+func (r *resmanager) annotatePodEviction(pod *corev1.Pod, reason string, message string) error {
+	return r.patchPodEvictionAnnotations(pod, map[string]interface{}{
+		apiext.AnnotationPodEvictionReason:  reason,
+		apiext.AnnotationPodEvictionMessage: message,
+	})
+}
+
+// clearPodEvictionAnnotations removes the eviction reason and message patched by annotatePodEviction from the pod.
+func (r *resmanager) clearPodEvictionAnnotations(pod *corev1.Pod) error {
+	// a null value in the merge patch removes the key
+	return r.patchPodEvictionAnnotations(pod, map[string]interface{}{
+		apiext.AnnotationPodEvictionReason:  nil,
+		apiext.AnnotationPodEvictionMessage: nil,
+	})
+}
+
+func (r *resmanager) patchPodEvictionAnnotations(pod *corev1.Pod, annotations map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	r.throttleWrite()
+	_, err = r.kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, patch,
+		metav1.PatchOptions{})
+	return err
+}
+
+// getEvictGracePeriodSeconds returns the grace period seconds to evict the pod with by its qos class, or nil to use
+// the terminationGracePeriodSeconds of the pod.
+func (r *resmanager) getEvictGracePeriodSeconds(pod *corev1.Pod) *int64 {
+	nodeSLO := r.getNodeSLOCopy()
+	if nodeSLO == nil || nodeSLO.Spec.ResourceUsedThresholdWithBE == nil ||
+		nodeSLO.Spec.ResourceUsedThresholdWithBE.EvictGracePeriodSeconds == nil {
+		return nil
+	}
+	gracePeriod := nodeSLO.Spec.ResourceUsedThresholdWithBE.EvictGracePeriodSeconds
+	switch apiext.GetPodQoSClass(pod) {
+	case apiext.QoSLSR:
+		return gracePeriod.LSR
+	case apiext.QoSLS:
+		return gracePeriod.LS
+	case apiext.QoSBE:
+		return gracePeriod.BE
+	}
+	return nil
+}
+
+// recordEvictPodEvent records the evict failure or skipped event at most once per EvictFailEventIntervalSeconds for
+// the same pod and reason, to avoid flooding the event store with a stuck pod. It returns whether the event is recorded.
+func (r *resmanager) recordEvictPodEvent(evictPod *corev1.Pod, node *corev1.Node, eventReason string, reason string, message string) bool {
+	key := fmt.Sprintf("%s/%s/%s", evictPod.UID, eventReason, reason)
+	if r.evictFailEvents != nil {
+		if _, recorded := r.evictFailEvents.Get(key); recorded {
+			return false
 		}
+		_ = r.evictFailEvents.SetDefault(key, struct{}{})
+	}
+	r.eventRecorder.Event(node, corev1.EventTypeWarning, eventReason, message)
+	return true
+}
 
-		if containerID != "" {
-			runtimeType, _, _ := util.ParseContainerId(containerStatus.ContainerID)
-			runtimeHandler, err := runtime.GetRuntimeHandler(runtimeType)
-			if err != nil || runtimeHandler == nil {
-				klog.Errorf("%s, kill container(%s) error! GetRuntimeHandler fail! error: %v", message, containerStatus.ContainerID, err)
-				continue
-			}
-			if err := runtimeHandler.StopContainer(containerID, 0); err != nil {
-				klog.Errorf("%s, stop container error! error: %v", message, err)
+// killContainers kills containers inside the pod, and returns the errors of the containers failed to kill since the
+// runtime handler is unavailable or the stop fails. The containers are stopped stage by stage in the stop order, and the containers in
+// the same stage are stopped concurrently by at most killContainersWorkers workers.
+func killContainers(pod *corev1.Pod, message string) error {
+	var errs []error
+	errLock := sync.Mutex{}
+	for _, stage := range getContainerStopStages(pod) {
+		workqueue.ParallelizeUntil(context.TODO(), killContainersWorkers, len(stage), func(i int) {
+			if err := killContainer(pod, &stage[i], message); err != nil {
+				errLock.Lock()
+				errs = append(errs, err)
+				errLock.Unlock()
 			}
+		})
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// killContainer stops the running container, and returns the error if the runtime handler is unavailable or the stop
+// fails.
+func killContainer(pod *corev1.Pod, container *corev1.Container, message string) error {
+	containerID, containerStatus, err := util.FindContainerIdAndStatusByName(&pod.Status, container.Name)
+	if err != nil {
+		klog.Errorf("failed to find container id and status, error: %v", err)
+		return nil
+	}
+
+	if containerStatus == nil || containerStatus.State.Running == nil {
+		return nil
+	}
+
+	if containerID == "" {
+		klog.Warningf("%s, get container ID failed, pod %s/%s containerName %s status: %v", message, pod.Namespace, pod.Name, container.Name, pod.Status.ContainerStatuses)
+		return nil
+	}
+	runtimeType, _, _ := util.ParseContainerId(containerStatus.ContainerID)
+	runtimeHandler, err := runtime.GetRuntimeHandler(runtimeType)
+	if err != nil || runtimeHandler == nil {
+		klog.Errorf("%s, kill container(%s) error! GetRuntimeHandler fail! error: %v", message, containerStatus.ContainerID, err)
+		metrics.RecordContainerKillRuntimeError(runtimeType)
+		return fmt.Errorf("failed to get runtime handler %q for container %s, error: %v",
+			runtimeType, containerStatus.ContainerID, err)
+	}
+	if err := runtimeHandler.StopContainer(containerID, 0); err != nil {
+		klog.Errorf("%s, stop container error! error: %v", message, err)
+		return fmt.Errorf("failed to stop container %s, error: %v", containerStatus.ContainerID, err)
+	}
+	return nil
+}
+
+// getContainerStopStages returns the containers of the pod grouped into the stages to stop in order. The containers
+// listed in the stop-order annotation are stopped first one per stage, then the other containers in one stage, and
+// the sidecar containers are stopped at last in one stage. The ephemeral containers are stopped with the other
+// containers.
+func getContainerStopStages(pod *corev1.Pod) [][]corev1.Container {
+	podContainers := util.GetPodContainers(pod, true)
+	stopOrder := apiext.GetPodContainerStopOrder(pod)
+	sidecars := apiext.GetPodSidecarContainers(pod)
+	if len(stopOrder) == 0 && len(sidecars) == 0 {
+		if len(podContainers) == 0 {
+			return nil
+		}
+		return [][]corev1.Container{podContainers}
+	}
+
+	containerByName := make(map[string]corev1.Container, len(podContainers))
+	for _, container := range podContainers {
+		containerByName[container.Name] = container
+	}
+	var stages [][]corev1.Container
+	ordered := make(map[string]bool, len(podContainers))
+	for _, name := range stopOrder {
+		if container, ok := containerByName[name]; ok && !ordered[name] {
+			stages = append(stages, []corev1.Container{container})
+			ordered[name] = true
+		}
+	}
+	isSidecar := make(map[string]bool, len(sidecars))
+	for _, name := range sidecars {
+		isSidecar[name] = true
+	}
+	var containers, sidecarContainers []corev1.Container
+	for _, container := range podContainers {
+		if ordered[container.Name] {
+			continue
+		}
+		if isSidecar[container.Name] {
+			sidecarContainers = append(sidecarContainers, container)
 		} else {
-			klog.Warningf("%s, get container ID failed, pod %s/%s containerName %s status: %v", message, pod.Namespace, pod.Name, container.Name, pod.Status.ContainerStatuses)
+			containers = append(containers, container)
 		}
 	}
+	if len(containers) > 0 {
+		stages = append(stages, containers)
+	}
+	if len(sidecarContainers) > 0 {
+		stages = append(stages, sidecarContainers)
+	}
+	return stages
 }
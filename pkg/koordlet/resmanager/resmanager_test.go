@@ -17,20 +17,38 @@
 package resmanager
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/kubernetes"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/component-base/featuregate"
+	"k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	critesting "k8s.io/cri-api/pkg/apis/testing"
+	"k8s.io/klog/v2"
+	testingclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
@@ -38,8 +56,11 @@ import (
 	clientsetalpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor"
 	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/runtime/handler"
 	"github.com/koordinator-sh/koordinator/pkg/tools/cache"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
@@ -62,6 +83,24 @@ func TestNewResManager(t *testing.T) {
 	})
 }
 
+func Test_mergeNodeSLOSpec_shadowThreshold(t *testing.T) {
+	r := &resmanager{nodeSLO: &slov1alpha1.NodeSLO{}}
+	err := r.mergeNodeSLOSpec(&slov1alpha1.NodeSLO{})
+	assert.NoError(t, err)
+	assert.Nil(t, r.nodeSLO.Spec.ShadowResourceUsedThresholdWithBE)
+
+	shadow := &slov1alpha1.ResourceThresholdStrategy{CPUSuppressThresholdPercent: pointer.Int64Ptr(50)}
+	err = r.mergeNodeSLOSpec(&slov1alpha1.NodeSLO{
+		Spec: slov1alpha1.NodeSLOSpec{ShadowResourceUsedThresholdWithBE: shadow},
+	})
+	assert.NoError(t, err)
+	want := util.DefaultNodeSLOSpecConfig().ResourceUsedThresholdWithBE
+	want.CPUSuppressThresholdPercent = pointer.Int64Ptr(50)
+	assert.Equal(t, want, r.nodeSLO.Spec.ShadowResourceUsedThresholdWithBE)
+	// the active threshold is not affected
+	assert.Equal(t, util.DefaultNodeSLOSpecConfig().ResourceUsedThresholdWithBE, r.nodeSLO.Spec.ResourceUsedThresholdWithBE)
+}
+
 func Test_mergeNodeSLOSpec(t *testing.T) {
 	testingCustomNodeSLOSpec := slov1alpha1.NodeSLOSpec{
 		ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
@@ -99,25 +138,49 @@ func Test_mergeNodeSLOSpec(t *testing.T) {
 		nodeSLO *slov1alpha1.NodeSLO
 	}
 	tests := []struct {
-		name  string
-		args  args
-		field field
-		want  *slov1alpha1.NodeSLO
+		name    string
+		args    args
+		field   field
+		want    *slov1alpha1.NodeSLO
+		wantErr bool
 	}{
 		{
 			name: "skip the merge if the old one is nil",
 			args: args{
 				nodeSLO: &slov1alpha1.NodeSLO{},
 			},
-			field: field{nodeSLO: nil},
-			want:  nil,
+			field:   field{nodeSLO: nil},
+			want:    nil,
+			wantErr: true,
 		},
 		{
 			name: "skip the merge if the new one is nil",
 			field: field{
 				nodeSLO: &slov1alpha1.NodeSLO{},
 			},
-			want: &slov1alpha1.NodeSLO{},
+			want:    &slov1alpha1.NodeSLO{},
+			wantErr: true,
+		},
+		{
+			name: "skip the merge if the new one is invalid",
+			args: args{
+				nodeSLO: &slov1alpha1.NodeSLO{
+					Spec: slov1alpha1.NodeSLOSpec{
+						ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+							CPUSuppressThresholdPercent: pointer.Int64Ptr(120),
+						},
+					},
+				},
+			},
+			field: field{
+				nodeSLO: &slov1alpha1.NodeSLO{
+					Spec: util.DefaultNodeSLOSpecConfig(),
+				},
+			},
+			want: &slov1alpha1.NodeSLO{
+				Spec: util.DefaultNodeSLOSpecConfig(),
+			},
+			wantErr: true,
 		},
 		{
 			name: "use default and do not panic if the new is nil",
@@ -129,6 +192,7 @@ func Test_mergeNodeSLOSpec(t *testing.T) {
 			want: &slov1alpha1.NodeSLO{
 				Spec: util.DefaultNodeSLOSpecConfig(),
 			},
+			wantErr: true,
 		},
 		{
 			name: "merge with the default",
@@ -181,7 +245,8 @@ func Test_mergeNodeSLOSpec(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := resmanager{nodeSLO: tt.field.nodeSLO}
-			r.mergeNodeSLOSpec(tt.args.nodeSLO)
+			err := r.mergeNodeSLOSpec(tt.args.nodeSLO)
+			assert.Equal(t, tt.wantErr, err != nil, err)
 			assert.Equal(t, tt.want, r.nodeSLO)
 		})
 	}
@@ -275,6 +340,195 @@ func Test_updateNodeSLOSpec(t *testing.T) {
 	assert.Equal(t, testingUpdatedNodeSLO, r.nodeSLO)
 }
 
+func Test_updateNodeSLOSpec_recordSpecInfo(t *testing.T) {
+	testingNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.NodeSLOSpecInfo.Reset()
+
+	newNodeSLO := func(cpuSuppressThresholdPercent int64) *slov1alpha1.NodeSLO {
+		return &slov1alpha1.NodeSLO{
+			Spec: slov1alpha1.NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+					Enable:                      pointer.BoolPtr(true),
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(cpuSuppressThresholdPercent),
+				},
+				FeatureGates: map[string]bool{
+					string(features.BECPUSuppress): true,
+					string(features.BEMemoryEvict): true,
+				},
+			},
+		}
+	}
+	r := &resmanager{}
+	getSpecHash := func() string {
+		assert.Equal(t, 1, testutil.CollectAndCount(metrics.NodeSLOSpecInfo), "only the applied spec is exported")
+		specHash := hashNodeSLOSpec(&r.nodeSLO.Spec)
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.NodeSLOSpecInfo.WithLabelValues(testingNode.Name, specHash)))
+		return specHash
+	}
+
+	r.createNodeSLO(newNodeSLO(80))
+	createdHash := getSpecHash()
+
+	// the hash is stable for the same spec
+	r.updateNodeSLOSpec(newNodeSLO(80))
+	assert.Equal(t, createdHash, getSpecHash())
+
+	// the hash changes with the spec
+	r.updateNodeSLOSpec(newNodeSLO(60))
+	updatedHash := getSpecHash()
+	assert.NotEqual(t, createdHash, updatedHash)
+
+	// the hash keeps unchanged if the spec is rejected
+	invalidNodeSLO := newNodeSLO(60)
+	invalidNodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent = pointer.Int64Ptr(200)
+	r.updateNodeSLOSpec(invalidNodeSLO)
+	assert.Equal(t, updatedHash, getSpecHash())
+}
+
+func Test_updateNodeSLOSpec_skipAppliedGeneration(t *testing.T) {
+	var buf bytes.Buffer
+	klog.LogToStderr(false)
+	klog.SetOutput(&buf)
+	defer klog.LogToStderr(true)
+
+	newNodeSLO := func(uid types.UID, generation int64, cpuSuppressThresholdPercent int64) *slov1alpha1.NodeSLO {
+		return &slov1alpha1.NodeSLO{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node", UID: uid, Generation: generation},
+			Spec: slov1alpha1.NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+					Enable:                      pointer.BoolPtr(true),
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(cpuSuppressThresholdPercent),
+				},
+			},
+		}
+	}
+	r := &resmanager{nodeSLOApply: newNodeSLOApplyTracker()}
+	assertApplied := func(wantGeneration int64, wantLog string) {
+		klog.Flush()
+		assert.Equal(t, wantGeneration, r.nodeSLOApply.currentGeneration())
+		if len(wantLog) > 0 {
+			assert.Contains(t, buf.String(), wantLog)
+		} else {
+			assert.Empty(t, buf.String())
+		}
+		buf.Reset()
+	}
+
+	r.createNodeSLO(newNodeSLO("uid-1", 1, 80))
+	assertApplied(1, "create nodeSLO test-node")
+
+	// resync without a real change
+	r.createNodeSLO(newNodeSLO("uid-1", 1, 80))
+	assertApplied(1, "")
+	r.updateNodeSLOSpec(newNodeSLO("uid-1", 1, 80))
+	assertApplied(1, "")
+
+	r.updateNodeSLOSpec(newNodeSLO("uid-1", 2, 60))
+	assertApplied(2, "update nodeSLO test-node spec")
+	assert.Equal(t, int64(60), *r.getNodeSLOCopy().Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+
+	// the recreated nodeSLO restarts the generation
+	r.createNodeSLO(newNodeSLO("uid-2", 1, 70))
+	assertApplied(3, "create nodeSLO test-node spec")
+	assert.Equal(t, int64(70), *r.getNodeSLOCopy().Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+
+	// the nodeSLO without a generation is always merged
+	r.updateNodeSLOSpec(newNodeSLO("", 0, 70))
+	assertApplied(4, "update nodeSLO test-node spec")
+}
+
+func Test_updateNodeSLOSpec_keepPreviousConfig(t *testing.T) {
+	testingNode := getNode("80", "120G")
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.NodeSLOMergeFailed.Reset()
+
+	r := resmanager{}
+	r.createNodeSLO(getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+		Enable:                      pointer.BoolPtr(true),
+		CPUSuppressThresholdPercent: pointer.Int64Ptr(80),
+	}))
+	previousNodeSLO := r.getNodeSLOCopy()
+	assert.NotNil(t, previousNodeSLO)
+
+	tests := []struct {
+		name    string
+		nodeSLO *slov1alpha1.NodeSLO
+	}{
+		{
+			name:    "nil nodeSLO",
+			nodeSLO: nil,
+		},
+		{
+			name: "partially invalid threshold",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+						Enable:                      pointer.BoolPtr(true),
+						CPUSuppressThresholdPercent: pointer.Int64Ptr(60),
+						MemoryEvictThresholdPercent: pointer.Int64Ptr(200),
+					},
+				},
+			},
+		},
+		{
+			name: "partially invalid cpu burst policy",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+						CPUSuppressThresholdPercent: pointer.Int64Ptr(60),
+					},
+					CPUBurstStrategy: &slov1alpha1.CPUBurstStrategy{
+						CPUBurstConfig: slov1alpha1.CPUBurstConfig{Policy: "unknown"},
+					},
+				},
+			},
+		},
+		{
+			name: "memory evict lower percent not less than the threshold",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+						MemoryEvictThresholdPercent: pointer.Int64Ptr(70),
+						MemoryEvictLowerPercent:     pointer.Int64Ptr(75),
+					},
+				},
+			},
+		},
+		{
+			name: "invalid cat range",
+			nodeSLO: &slov1alpha1.NodeSLO{
+				Spec: slov1alpha1.NodeSLOSpec{
+					ResourceQoSStrategy: &slov1alpha1.ResourceQoSStrategy{
+						BE: &slov1alpha1.ResourceQoS{
+							ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+								ResctrlQoS: slov1alpha1.ResctrlQoS{
+									CATRangeStartPercent: pointer.Int64Ptr(50),
+									CATRangeEndPercent:   pointer.Int64Ptr(10),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.updateNodeSLOSpec(tt.nodeSLO)
+			assert.Equal(t, previousNodeSLO, r.getNodeSLOCopy())
+			assert.Equal(t, float64(i+1), testutil.ToFloat64(metrics.NodeSLOMergeFailed.WithLabelValues(testingNode.Name)))
+		})
+	}
+
+	t.Run("invalid nodeSLO on create", func(t *testing.T) {
+		r.createNodeSLO(tests[1].nodeSLO)
+		assert.Equal(t, previousNodeSLO, r.getNodeSLOCopy())
+	})
+}
+
 func Test_isFeatureDisabled(t *testing.T) {
 	type args struct {
 		nodeSLO *slov1alpha1.NodeSLO
@@ -388,6 +642,287 @@ func Test_EvictPodsIfNotEvicted(t *testing.T) {
 
 }
 
+func Test_killContainers(t *testing.T) {
+	testingNode := getNode("80", "120G")
+	tests := []struct {
+		name             string
+		containerIDs     []string
+		wantErr          bool
+		wantRuntimeError map[string]float64
+	}{
+		{
+			name:         "kill containers successfully",
+			containerIDs: []string{"docker://test-container-0", "docker://test-container-1"},
+			wantErr:      false,
+		},
+		{
+			name:             "failed to get the handler of a missing runtime type",
+			containerIDs:     []string{"docker://test-container-0", "cri-o://test-container-1"},
+			wantErr:          true,
+			wantRuntimeError: map[string]float64{"cri-o": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics.Register(testingNode)
+			defer metrics.Register(nil)
+			metrics.ContainerKillRuntimeErrors.Reset()
+			defer metrics.ContainerKillRuntimeErrors.Reset()
+
+			oldDockerHandler := runtime.DockerHandler
+			defer func() {
+				runtime.DockerHandler = oldDockerHandler
+			}()
+
+			pod := createTestPod(apiext.QoSBE, "test_be_pod")
+			pod.Spec.Containers = nil
+			pod.Status.ContainerStatuses = nil
+			for i, containerID := range tt.containerIDs {
+				containerName := fmt.Sprintf("container-%d", i)
+				pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: containerName})
+				pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+					Name:        containerName,
+					ContainerID: containerID,
+					State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				})
+			}
+			runtime.DockerHandler = newFakeRuntimeHandlerWithPods(pod)
+
+			err := killContainers(pod, "test kill")
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			for runtimeType, want := range tt.wantRuntimeError {
+				got := testutil.ToFloat64(metrics.ContainerKillRuntimeErrors.WithLabelValues(testingNode.Name, runtimeType))
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
+
+// newFakeRuntimeHandlerWithPods returns a fake runtime handler with the containers of the pods, so they can be stopped.
+func newFakeRuntimeHandlerWithPods(pods ...*corev1.Pod) handler.ContainerRuntimeHandler {
+	var containers []*critesting.FakeContainer
+	for _, pod := range pods {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			_, containerID, _ := util.ParseContainerId(containerStatus.ContainerID)
+			containers = append(containers, &critesting.FakeContainer{
+				SandboxID:       string(pod.UID),
+				ContainerStatus: v1alpha2.ContainerStatus{Id: containerID},
+			})
+		}
+	}
+	fakeHandler := handler.NewFakeRuntimeHandler()
+	fakeHandler.(*handler.FakeRuntimeHandler).SetFakeContainers(containers)
+	return fakeHandler
+}
+
+// stopRecordingRuntimeHandler records the ids of the stopped containers in order, and the max number of the
+// containers stopping concurrently, and fails to stop the containers in stopFailed
+type stopRecordingRuntimeHandler struct {
+	handler.ContainerRuntimeHandler
+	lock        sync.Mutex
+	stopped     []string
+	stopping    int
+	maxStopping int
+	stopDelay   time.Duration
+	stopFailed  map[string]bool
+}
+
+func (h *stopRecordingRuntimeHandler) StopContainer(containerID string, timeout int64) error {
+	h.lock.Lock()
+	h.stopping++
+	if h.stopping > h.maxStopping {
+		h.maxStopping = h.stopping
+	}
+	h.lock.Unlock()
+
+	time.Sleep(h.stopDelay)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.stopping--
+	h.stopped = append(h.stopped, containerID)
+	if h.stopFailed[containerID] {
+		return fmt.Errorf("failed to stop container %s", containerID)
+	}
+	return nil
+}
+
+func Test_killContainers_stopOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantStages  [][]string
+	}{
+		{
+			name:       "stop all in one stage by default",
+			wantStages: [][]string{{"main", "sidecar", "worker"}},
+		},
+		{
+			name:        "stop sidecars at last",
+			annotations: map[string]string{apiext.AnnotationPodSidecarContainers: "sidecar"},
+			wantStages:  [][]string{{"main", "worker"}, {"sidecar"}},
+		},
+		{
+			name:        "stop the listed containers first",
+			annotations: map[string]string{apiext.AnnotationPodContainerStopOrder: "worker, main"},
+			wantStages:  [][]string{{"worker"}, {"main"}, {"sidecar"}},
+		},
+		{
+			name: "stop the listed containers first and sidecars at last",
+			annotations: map[string]string{
+				apiext.AnnotationPodContainerStopOrder: "worker,unknown,worker",
+				apiext.AnnotationPodSidecarContainers:  "sidecar",
+			},
+			wantStages: [][]string{{"worker"}, {"main"}, {"sidecar"}},
+		},
+		{
+			name: "the stop order takes precedence over sidecars",
+			annotations: map[string]string{
+				apiext.AnnotationPodContainerStopOrder: "sidecar",
+				apiext.AnnotationPodSidecarContainers:  "sidecar",
+			},
+			wantStages: [][]string{{"sidecar"}, {"main", "worker"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordingHandler := &stopRecordingRuntimeHandler{ContainerRuntimeHandler: handler.NewFakeRuntimeHandler()}
+			oldDockerHandler := runtime.DockerHandler
+			runtime.DockerHandler = recordingHandler
+			defer func() {
+				runtime.DockerHandler = oldDockerHandler
+			}()
+
+			pod := createTestPod(apiext.QoSBE, "test_be_pod")
+			pod.Annotations = tt.annotations
+			pod.Spec.Containers = nil
+			pod.Status.ContainerStatuses = nil
+			for _, containerName := range []string{"main", "sidecar", "worker"} {
+				pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: containerName})
+				pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+					Name:        containerName,
+					ContainerID: "docker://" + containerName,
+					State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				})
+			}
+
+			var gotStages [][]string
+			for _, stage := range getContainerStopStages(pod) {
+				var names []string
+				for _, container := range stage {
+					names = append(names, container.Name)
+				}
+				gotStages = append(gotStages, names)
+			}
+			assert.Equal(t, tt.wantStages, gotStages)
+
+			assert.NoError(t, killContainers(pod, "test kill"))
+			// the containers in a stage are stopped in any order, but all before the next stage
+			stopped := recordingHandler.stopped
+			for _, stage := range tt.wantStages {
+				assert.GreaterOrEqual(t, len(stopped), len(stage))
+				assert.ElementsMatch(t, stage, stopped[:len(stage)])
+				stopped = stopped[len(stage):]
+			}
+			assert.Empty(t, stopped)
+		})
+	}
+}
+
+func Test_killContainers_ephemeralContainers(t *testing.T) {
+	recordingHandler := &stopRecordingRuntimeHandler{ContainerRuntimeHandler: handler.NewFakeRuntimeHandler()}
+	oldDockerHandler := runtime.DockerHandler
+	runtime.DockerHandler = recordingHandler
+	defer func() {
+		runtime.DockerHandler = oldDockerHandler
+	}()
+
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	pod.Annotations = map[string]string{apiext.AnnotationPodSidecarContainers: "sidecar"}
+	pod.Spec.InitContainers = []corev1.Container{{Name: "init"}}
+	pod.Spec.Containers = []corev1.Container{{Name: "main"}, {Name: "sidecar"}}
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{
+		{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger"}},
+		{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "exited-debugger"}},
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	terminated := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{Name: "init", ContainerID: "docker://init", State: terminated},
+	}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: "main", ContainerID: "docker://main", State: running},
+		{Name: "sidecar", ContainerID: "docker://sidecar", State: running},
+	}
+	pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{
+		{Name: "debugger", ContainerID: "docker://debugger", State: running},
+		{Name: "exited-debugger", ContainerID: "docker://exited-debugger", State: terminated},
+	}
+
+	// the running ephemeral container is stopped with the other containers before the sidecars, while the completed
+	// init container and ephemeral container are skipped
+	assert.NoError(t, killContainers(pod, "test kill"))
+	assert.Len(t, recordingHandler.stopped, 3)
+	assert.ElementsMatch(t, []string{"main", "debugger"}, recordingHandler.stopped[:2])
+	assert.Equal(t, "sidecar", recordingHandler.stopped[2])
+}
+
+func Test_killContainers_concurrently(t *testing.T) {
+	testingNode := getNode("80", "120G")
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	metrics.ContainerKillRuntimeErrors.Reset()
+	defer metrics.ContainerKillRuntimeErrors.Reset()
+
+	recordingHandler := &stopRecordingRuntimeHandler{
+		ContainerRuntimeHandler: handler.NewFakeRuntimeHandler(),
+		stopDelay:               10 * time.Millisecond,
+		stopFailed:              map[string]bool{},
+	}
+	oldDockerHandler := runtime.DockerHandler
+	runtime.DockerHandler = recordingHandler
+	defer func() {
+		runtime.DockerHandler = oldDockerHandler
+	}()
+
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	pod.Spec.Containers = nil
+	pod.Status.ContainerStatuses = nil
+	var wantStopped []string
+	for i := 0; i < 30; i++ {
+		containerName := fmt.Sprintf("container-%d", i)
+		containerID := "docker://" + containerName
+		if i%10 == 0 {
+			// the handler of the runtime type is missing
+			containerID = "cri-o://" + containerName
+		} else {
+			wantStopped = append(wantStopped, containerName)
+		}
+		if i%10 == 5 {
+			// the stop fails, which is also aggregated
+			recordingHandler.stopFailed[containerName] = true
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: containerName})
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name:        containerName,
+			ContainerID: containerID,
+			State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		})
+	}
+	// a terminated container is skipped without stopping the others
+	pod.Status.ContainerStatuses[1].State = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+	wantStopped = wantStopped[1:]
+
+	err := killContainers(pod, "test kill")
+	assert.Error(t, err)
+	aggErr, ok := err.(utilerrors.Aggregate)
+	assert.True(t, ok)
+	assert.Len(t, aggErr.Errors(), 6)
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.ContainerKillRuntimeErrors.WithLabelValues(testingNode.Name, "cri-o")))
+	assert.ElementsMatch(t, wantStopped, recordingHandler.stopped)
+	assert.LessOrEqual(t, recordingHandler.maxStopping, killContainersWorkers)
+}
+
 func Test_evictPod(t *testing.T) {
 	// test data
 	pod := createTestPod(apiext.QoSBE, "test_be_pod")
@@ -420,6 +955,695 @@ func Test_evictPod(t *testing.T) {
 
 }
 
+func Test_evictPod_gracePeriodByQoSClass(t *testing.T) {
+	node := getNode("80", "120G")
+	tests := []struct {
+		name            string
+		pod             *corev1.Pod
+		gracePeriod     *slov1alpha1.EvictGracePeriodSeconds
+		wantGracePeriod *int64
+	}{
+		{
+			name: "BE pod uses the grace period of BE",
+			pod:  createTestPod(apiext.QoSBE, "test_be_pod"),
+			gracePeriod: &slov1alpha1.EvictGracePeriodSeconds{
+				LS: pointer.Int64Ptr(30),
+				BE: pointer.Int64Ptr(0),
+			},
+			wantGracePeriod: pointer.Int64Ptr(0),
+		},
+		{
+			name: "LS pod uses the grace period of LS",
+			pod:  createTestPod(apiext.QoSLS, "test_ls_pod"),
+			gracePeriod: &slov1alpha1.EvictGracePeriodSeconds{
+				LS: pointer.Int64Ptr(30),
+				BE: pointer.Int64Ptr(0),
+			},
+			wantGracePeriod: pointer.Int64Ptr(30),
+		},
+		{
+			name: "LSR pod uses its own grace period if not set",
+			pod:  createTestPod(apiext.QoSLSR, "test_lsr_pod"),
+			gracePeriod: &slov1alpha1.EvictGracePeriodSeconds{
+				LS: pointer.Int64Ptr(30),
+				BE: pointer.Int64Ptr(0),
+			},
+			wantGracePeriod: nil,
+		},
+		{
+			name:            "pod uses its own grace period if no config",
+			pod:             createTestPod(apiext.QoSBE, "test_be_pod"),
+			wantGracePeriod: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{
+				eventRecorder: &FakeRecorder{},
+				kubeClient:    client,
+				nodeSLO: getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+					EvictGracePeriodSeconds: tt.gracePeriod,
+				}),
+			}
+			_, err := client.CoreV1().Pods(tt.pod.Namespace).Create(context.TODO(), tt.pod, metav1.CreateOptions{})
+			assert.NoError(t, err)
+
+			assert.True(t, r.evictPod(tt.pod, node, "evict pod", ""))
+
+			var gotEviction *policyv1.Eviction
+			for _, action := range client.Actions() {
+				if createAction, ok := action.(k8stesting.CreateAction); ok && action.GetSubresource() == "eviction" {
+					gotEviction = createAction.GetObject().(*policyv1.Eviction)
+				}
+			}
+			assert.NotNil(t, gotEviction)
+			if tt.wantGracePeriod == nil {
+				assert.Nil(t, gotEviction.DeleteOptions)
+				return
+			}
+			assert.NotNil(t, gotEviction.DeleteOptions)
+			assert.Equal(t, tt.wantGracePeriod, gotEviction.DeleteOptions.GracePeriodSeconds)
+		})
+	}
+}
+
+func Test_evictPod_forceDeleteZeroGrace(t *testing.T) {
+	node := getNode("80", "120G")
+	tests := []struct {
+		name            string
+		forceDelete     bool
+		podGracePeriod  *int64
+		gracePeriod     *slov1alpha1.EvictGracePeriodSeconds
+		wantForceDelete bool
+	}{
+		{
+			name:            "force delete the pod with zero grace period",
+			forceDelete:     true,
+			podGracePeriod:  pointer.Int64Ptr(0),
+			wantForceDelete: true,
+		},
+		{
+			name:            "evict the pod with zero grace period if disabled",
+			forceDelete:     false,
+			podGracePeriod:  pointer.Int64Ptr(0),
+			wantForceDelete: false,
+		},
+		{
+			name:            "evict the pod with nil grace period",
+			forceDelete:     true,
+			podGracePeriod:  nil,
+			wantForceDelete: false,
+		},
+		{
+			name:            "evict the pod with positive grace period",
+			forceDelete:     true,
+			podGracePeriod:  pointer.Int64Ptr(30),
+			wantForceDelete: false,
+		},
+		{
+			name:           "force delete the pod with zero grace period of the qos config",
+			forceDelete:    true,
+			podGracePeriod: pointer.Int64Ptr(30),
+			gracePeriod: &slov1alpha1.EvictGracePeriodSeconds{
+				BE: pointer.Int64Ptr(0),
+			},
+			wantForceDelete: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := createTestPod(apiext.QoSBE, "test_be_pod")
+			pod.Spec.TerminationGracePeriodSeconds = tt.podGracePeriod
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{
+				config:        &Config{EvictForceDeleteZeroGrace: tt.forceDelete},
+				eventRecorder: &FakeRecorder{},
+				kubeClient:    client,
+				nodeSLO: getNodeSLOByThreshold(&slov1alpha1.ResourceThresholdStrategy{
+					EvictGracePeriodSeconds: tt.gracePeriod,
+				}),
+			}
+			_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			assert.NoError(t, err)
+
+			assert.True(t, r.evictPod(pod, node, "evict pod", ""))
+
+			var gotDelete *k8stesting.DeleteActionImpl
+			gotEviction := false
+			for _, action := range client.Actions() {
+				if deleteAction, ok := action.(k8stesting.DeleteActionImpl); ok {
+					gotDelete = &deleteAction
+				}
+				if _, ok := action.(k8stesting.CreateAction); ok && action.GetSubresource() == "eviction" {
+					gotEviction = true
+				}
+			}
+			assert.Equal(t, tt.wantForceDelete, gotDelete != nil)
+			assert.Equal(t, !tt.wantForceDelete, gotEviction)
+			if gotDelete != nil {
+				assert.Equal(t, pod.Name, gotDelete.GetName())
+			}
+		})
+	}
+}
+
+func Test_evictPod_annotatePod(t *testing.T) {
+	node := getNode("80", "120G")
+	tests := []struct {
+		name             string
+		annotatePod      bool
+		wantAnnotations  map[string]string
+		wantPatchActions int
+	}{
+		{
+			name:             "not annotate the pod by default",
+			annotatePod:      false,
+			wantPatchActions: 0,
+		},
+		{
+			name:        "annotate the pod before evicting",
+			annotatePod: true,
+			wantAnnotations: map[string]string{
+				apiext.AnnotationPodEvictionReason:  evictPodByNodeMemoryUsage,
+				apiext.AnnotationPodEvictionMessage: "killAndEvictBEPods for node(test-node), need to release memory: 1000",
+			},
+			wantPatchActions: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			cfg.EvictAnnotatePod = tt.annotatePod
+			client := clientsetfake.NewSimpleClientset()
+			r := &resmanager{
+				config:        cfg,
+				eventRecorder: &FakeRecorder{},
+				kubeClient:    client,
+			}
+			pod := createTestPod(apiext.QoSBE, "test_be_pod")
+			_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			assert.NoError(t, err)
+
+			assert.True(t, r.evictPod(pod, node, evictPodByNodeMemoryUsage,
+				"killAndEvictBEPods for node(test-node), need to release memory: 1000"))
+
+			patchActions, evicted := 0, false
+			for _, action := range client.Actions() {
+				if patchAction, ok := action.(k8stesting.PatchAction); ok {
+					assert.False(t, evicted, "the pod should be annotated before evicting")
+					assert.Equal(t, types.MergePatchType, patchAction.GetPatchType())
+					gotPatch := &corev1.Pod{}
+					assert.NoError(t, json.Unmarshal(patchAction.GetPatch(), gotPatch))
+					assert.Equal(t, tt.wantAnnotations, gotPatch.Annotations)
+					patchActions++
+				}
+				if action.GetSubresource() == "eviction" {
+					evicted = true
+				}
+			}
+			assert.True(t, evicted)
+			assert.Equal(t, tt.wantPatchActions, patchActions)
+		})
+	}
+}
+
+func Test_evictPod_clearAnnotationsOnFailure(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.EvictAnnotatePod = true
+	client := clientsetfake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		return true, nil, errors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 0)
+	})
+	r := &resmanager{
+		config:        cfg,
+		eventRecorder: &FakeRecorder{},
+		kubeClient:    client,
+	}
+	pod := createTestPod(apiext.QoSBE, "test_be_pod")
+	pod.Annotations = map[string]string{"test-annotation": "test"}
+	_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	assert.False(t, r.evictPod(pod, getNode("80", "120G"), evictPodByNodeMemoryUsage, "need to release memory"))
+
+	var patchActions int
+	for _, action := range client.Actions() {
+		if _, ok := action.(k8stesting.PatchAction); ok {
+			patchActions++
+		}
+	}
+	assert.Equal(t, 2, patchActions, "annotate and then clear the pod")
+	gotPod, err := client.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"test-annotation": "test"}, gotPod.Annotations)
+}
+
+func Test_isFeatureEnabledByNodeSLO(t *testing.T) {
+	r := &resmanager{}
+	assert.False(t, r.isFeatureEnabledByNodeSLO(features.BECPUSuppress), "nil nodeSLO")
+
+	r.nodeSLO = &slov1alpha1.NodeSLO{}
+	assert.False(t, r.isFeatureEnabledByNodeSLO(features.BECPUSuppress), "feature gates not set")
+
+	r.nodeSLO.Spec.FeatureGates = map[string]bool{
+		string(features.BECPUSuppress): true,
+		string(features.BEMemoryEvict): false,
+	}
+	assert.True(t, r.isFeatureEnabledByNodeSLO(features.BECPUSuppress))
+	assert.False(t, r.isFeatureEnabledByNodeSLO(features.BEMemoryEvict))
+	assert.False(t, r.isFeatureEnabledByNodeSLO(features.BEDiskEvict))
+}
+
+func Test_evictPod_writeRateLimited(t *testing.T) {
+	node := getNode("80", "120G")
+	fakeRecorder := &FakeRecorder{}
+	client := clientsetfake.NewSimpleClientset()
+	start := time.Now()
+	fakeClock := testingclock.NewFakeClock(start)
+	r := &resmanager{
+		eventRecorder: fakeRecorder,
+		kubeClient:    client,
+		// 10 writes per second with a burst of 5
+		writeRateLimiter: flowcontrol.NewTokenBucketRateLimiterWithClock(10, 5, fakeClock),
+	}
+
+	podNum := 25
+	for i := 0; i < podNum; i++ {
+		pod := createTestPod(apiext.QoSBE, fmt.Sprintf("test_be_pod_%d", i))
+		_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		r.evictPod(pod, node, "evict pod", "")
+	}
+
+	evictCount := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "create" && action.GetSubresource() == "eviction" {
+			evictCount++
+		}
+	}
+	assert.Equal(t, podNum, evictCount)
+	// the first 5 writes consume the burst, the remaining 20 writes wait for 100ms each
+	assert.Equal(t, 2*time.Second, fakeClock.Since(start).Round(time.Millisecond))
+}
+
+func Test_evictPod_throttleEachWrite(t *testing.T) {
+	node := getNode("80", "120G")
+	pods := []*corev1.Pod{createTestPod(apiext.QoSBE, "test_be_pod_0"), createTestPod(apiext.QoSBE, "test_be_pod_1")}
+	client := clientsetfake.NewSimpleClientset(pods[0], pods[1])
+	// the apiserver does not serve the policy/v1 eviction, and the update of the eviction history conflicts once
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		return true, nil, apiruntime.NewNotRegisteredErrForKind("test",
+			schema.GroupVersionKind{Group: "policy", Version: "v1", Kind: "Eviction"})
+	})
+	conflicts := 1
+	client.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+		if conflicts <= 0 {
+			return false, nil, nil
+		}
+		conflicts--
+		return true, nil, errors.NewConflict(corev1.Resource("configmaps"), "test", fmt.Errorf("conflict"))
+	})
+	cfg := NewDefaultConfig()
+	cfg.EvictAnnotatePod = true
+	cfg.EvictAPIUnavailableFallback = EvictAPIFallbackDelete
+	cfg.EvictionHistoryNamespace = "koordinator-system"
+	rateLimiter := newCountingRateLimiter()
+	r := &resmanager{
+		config:           cfg,
+		nodeName:         node.Name,
+		eventRecorder:    &FakeRecorder{},
+		kubeClient:       client,
+		writeRateLimiter: rateLimiter,
+		evictionHistory:  newEvictionHistoryRecorder(cfg),
+	}
+
+	for _, pod := range pods {
+		assert.True(t, r.evictPod(pod, node, evictPodByNodeMemoryUsage, "need to release memory"))
+		r.flushEvictionHistory()
+	}
+
+	writes := 0
+	for _, action := range client.Actions() {
+		switch action.GetVerb() {
+		case "create", "update", "patch", "delete":
+			writes++
+		}
+	}
+	// pod 0: annotate, evict by policy/v1, delete, create the history
+	// pod 1: annotate, delete, update the history on the conflict and retry
+	assert.Equal(t, 8, writes)
+	assert.Equal(t, writes, rateLimiter.getAccepts())
+}
+
+func Test_newWriteRateLimiter(t *testing.T) {
+	disabled := newWriteRateLimiter(&Config{APIServerWriteQPS: 0, APIServerWriteBurst: 1})
+	for i := 0; i < 100; i++ {
+		assert.True(t, disabled.TryAccept())
+	}
+
+	limited := newWriteRateLimiter(&Config{APIServerWriteQPS: 0.001, APIServerWriteBurst: 3})
+	for i := 0; i < 3; i++ {
+		assert.True(t, limited.TryAccept())
+	}
+	assert.False(t, limited.TryAccept())
+}
+
+// blockingEvictClientset blocks the pod evictions until released, and records the max number of the evictions in
+// flight, since the reactors of the fake clientset are invoked serially.
+type blockingEvictClientset struct {
+	*clientsetfake.Clientset
+	release     chan struct{}
+	lock        sync.Mutex
+	evicting    int
+	maxEvicting int
+	evicted     int
+}
+
+func (c *blockingEvictClientset) CoreV1() typedcorev1.CoreV1Interface {
+	return &blockingEvictCoreV1{CoreV1Interface: c.Clientset.CoreV1(), clientset: c}
+}
+
+func (c *blockingEvictClientset) getEvicting() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.evicting
+}
+
+type blockingEvictCoreV1 struct {
+	typedcorev1.CoreV1Interface
+	clientset *blockingEvictClientset
+}
+
+func (c *blockingEvictCoreV1) Pods(namespace string) typedcorev1.PodInterface {
+	return &blockingEvictPods{PodInterface: c.CoreV1Interface.Pods(namespace), clientset: c.clientset}
+}
+
+type blockingEvictPods struct {
+	typedcorev1.PodInterface
+	clientset *blockingEvictClientset
+}
+
+func (p *blockingEvictPods) EvictV1(ctx context.Context, eviction *policyv1.Eviction) error {
+	c := p.clientset
+	c.lock.Lock()
+	c.evicting++
+	if c.evicting > c.maxEvicting {
+		c.maxEvicting = c.evicting
+	}
+	c.lock.Unlock()
+
+	<-c.release
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.evicting--
+	c.evicted++
+	return p.PodInterface.EvictV1(ctx, eviction)
+}
+
+func Test_evictPod_maxInFlight(t *testing.T) {
+	node := getNode("80", "120G")
+	client := &blockingEvictClientset{Clientset: clientsetfake.NewSimpleClientset(), release: make(chan struct{})}
+	r := &resmanager{
+		eventRecorder: record.NewFakeRecorder(100),
+		kubeClient:    client,
+		evictSlots:    newEvictSlots(&Config{EvictMaxInFlight: 3}),
+	}
+
+	podNum := 20
+	var wg sync.WaitGroup
+	for i := 0; i < podNum; i++ {
+		pod := createTestPod(apiext.QoSBE, fmt.Sprintf("test_be_pod_%d", i))
+		_, err := client.Clientset.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(t, r.evictPod(pod, node, "evict pod", ""))
+		}()
+	}
+
+	// the other evictions wait for a free slot while the first ones are blocked
+	assert.Eventually(t, func() bool { return client.getEvicting() == 3 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 3, client.getEvicting())
+
+	close(client.release)
+	wg.Wait()
+	assert.Equal(t, podNum, client.evicted)
+	assert.Equal(t, 3, client.maxEvicting)
+}
+
+func Test_newEvictSlots(t *testing.T) {
+	assert.Nil(t, newEvictSlots(&Config{EvictMaxInFlight: 0}))
+	assert.Equal(t, 2, cap(newEvictSlots(&Config{EvictMaxInFlight: 2})))
+}
+
+func Test_evictPod_throttleFailEvents(t *testing.T) {
+	pod := createTestPod(apiext.QoSBE, "test_be_pod_evict_fail")
+	node := getNode("80", "120G")
+
+	fakeRecorder := record.NewFakeRecorder(100)
+	client := clientsetfake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		return true, nil, fmt.Errorf("evict forbidden by pdb")
+	})
+	evictFailEvents := cache.NewCache(time.Minute, time.Minute)
+	stop := make(chan struct{})
+	defer close(stop)
+	_ = evictFailEvents.Run(stop)
+	resmanager := &resmanager{eventRecorder: fakeRecorder, kubeClient: client, evictFailEvents: evictFailEvents}
+
+	for i := 0; i < 10; i++ {
+		got := resmanager.evictPod(pod, node, "evict pod on memory pressure", "")
+		assert.False(t, got)
+	}
+	assert.Equal(t, 1, len(fakeRecorder.Events), "repeated evict failures should record at most one event")
+
+	// evict failure with another reason is recorded separately
+	got := resmanager.evictPod(pod, node, "evict pod on cpu pressure", "")
+	assert.False(t, got)
+	assert.Equal(t, 2, len(fakeRecorder.Events))
+}
+
+func Test_evictPodIfNotEvicted_dedupTTL(t *testing.T) {
+	pod := createTestPod(apiext.QoSBE, "test_be_pod_evict_dedup")
+	node := getNode("80", "120G")
+
+	evictCount := 0
+	client := clientsetfake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		evictCount++
+		return true, nil, nil
+	})
+	cfg := NewDefaultConfig()
+	cfg.EvictionDedupTTLSeconds = 30
+	ttl := time.Duration(cfg.EvictionDedupTTLSeconds) * time.Second
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	podsEvicted := cache.NewCacheWithClock(ttl, time.Minute, fakeClock)
+	stop := make(chan struct{})
+	defer close(stop)
+	_ = podsEvicted.Run(stop)
+	resmanager := &resmanager{config: cfg, eventRecorder: record.NewFakeRecorder(100), kubeClient: client, podsEvicted: podsEvicted}
+
+	resmanager.evictPodIfNotEvicted(pod, node, "evict pod on memory pressure", "")
+	assert.Equal(t, 1, evictCount)
+
+	// the pod is skipped until the ttl elapses
+	fakeClock.Step(ttl)
+	resmanager.evictPodIfNotEvicted(pod, node, "evict pod on memory pressure", "")
+	assert.Equal(t, 1, evictCount, "pod should not be evicted again within the ttl")
+
+	// the pod becomes re-eligible right after the ttl
+	fakeClock.Step(time.Nanosecond)
+	resmanager.evictPodIfNotEvicted(pod, node, "evict pod on memory pressure", "")
+	assert.Equal(t, 2, evictCount, "pod should be evicted again after the ttl")
+}
+
+func Test_parseEnforcementGraceSeconds(t *testing.T) {
+	tests := []struct {
+		name         string
+		graceSeconds map[string]string
+		want         map[apiext.QoSClass]time.Duration
+		wantErr      bool
+	}{
+		{
+			name: "empty",
+			want: map[apiext.QoSClass]time.Duration{},
+		},
+		{
+			name:         "parse the grace of the qos classes",
+			graceSeconds: map[string]string{"LS": "60", "BE": "0"},
+			want:         map[apiext.QoSClass]time.Duration{apiext.QoSLS: time.Minute, apiext.QoSBE: 0},
+		},
+		{
+			name:         "unsupported qos class",
+			graceSeconds: map[string]string{"Burstable": "60"},
+			wantErr:      true,
+		},
+		{
+			name:         "illegal seconds",
+			graceSeconds: map[string]string{"LS": "1m"},
+			wantErr:      true,
+		},
+		{
+			name:         "negative seconds",
+			graceSeconds: map[string]string{"LS": "-1"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEnforcementGraceSeconds(tt.graceSeconds)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_isPodInEnforcementGrace(t *testing.T) {
+	now := time.Now()
+	r := &resmanager{enforcementGrace: map[apiext.QoSClass]time.Duration{apiext.QoSLS: time.Minute}}
+	lsPod := createTestPod(apiext.QoSLS, "test_ls_pod")
+	assert.False(t, r.isPodInEnforcementGrace(lsPod, now), "not started")
+
+	lsPod.Status.StartTime = &metav1.Time{Time: now.Add(-30 * time.Second)}
+	assert.True(t, r.isPodInEnforcementGrace(lsPod, now))
+	assert.False(t, r.isPodInEnforcementGrace(lsPod, now.Add(30*time.Second)))
+
+	bePod := createTestPod(apiext.QoSBE, "test_be_pod")
+	bePod.Status.StartTime = &metav1.Time{Time: now}
+	assert.False(t, r.isPodInEnforcementGrace(bePod, now), "no grace for the qos class")
+	assert.False(t, (&resmanager{}).isPodInEnforcementGrace(lsPod, now), "no grace configured")
+}
+
+func Test_parseEnforcementPodSelector(t *testing.T) {
+	got, err := parseEnforcementPodSelector("")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = parseEnforcementPodSelector("app in (web),tier!=batch")
+	assert.NoError(t, err)
+	assert.True(t, got.Matches(labels.Set{"app": "web", "tier": "online"}))
+	assert.False(t, got.Matches(labels.Set{"app": "web", "tier": "batch"}))
+
+	_, err = parseEnforcementPodSelector("app in web")
+	assert.Error(t, err)
+}
+
+func Test_isEnforcementEligible(t *testing.T) {
+	testingMirrorPod := createTestPod(apiext.QoSBE, "test_mirror_pod")
+	testingMirrorPod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "mirror"}
+	testingDaemonSetPod := createTestPod(apiext.QoSBE, "test_daemonset_pod")
+	testingDaemonSetPod.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "test-daemonset", Controller: pointer.BoolPtr(true)},
+	}
+	testingDeploymentPod := createTestPod(apiext.QoSBE, "test_deployment_pod")
+	testingDeploymentPod.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-replicaset", Controller: pointer.BoolPtr(true)},
+	}
+	testingOptInPod := createTestPod(apiext.QoSBE, "test_opt_in_pod")
+	testingOptInPod.Labels["qos-enforce"] = "true"
+	testingTerminatingPod := createTestPod(apiext.QoSBE, "test_terminating_pod")
+	testingTerminatingPod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	tests := []struct {
+		name                 string
+		pod                  *corev1.Pod
+		excludeDaemonSetPods bool
+		podSelector          string
+		want                 bool
+	}{
+		{
+			name: "nil pod is not eligible",
+			pod:  nil,
+			want: false,
+		},
+		{
+			name: "common pod is eligible",
+			pod:  testingDeploymentPod,
+			want: true,
+		},
+		{
+			name: "mirror pod is not eligible",
+			pod:  testingMirrorPod,
+			want: false,
+		},
+		{
+			name: "terminating pod is not eligible",
+			pod:  testingTerminatingPod,
+			want: false,
+		},
+		{
+			name:                 "mirror pod is not eligible when excluding DaemonSet pods",
+			pod:                  testingMirrorPod,
+			excludeDaemonSetPods: true,
+			want:                 false,
+		},
+		{
+			name:                 "DaemonSet pod is eligible when not excluding DaemonSet pods",
+			pod:                  testingDaemonSetPod,
+			excludeDaemonSetPods: false,
+			want:                 true,
+		},
+		{
+			name:                 "DaemonSet pod is not eligible when excluding DaemonSet pods",
+			pod:                  testingDaemonSetPod,
+			excludeDaemonSetPods: true,
+			want:                 false,
+		},
+		{
+			name:                 "common pod is eligible when excluding DaemonSet pods",
+			pod:                  testingDeploymentPod,
+			excludeDaemonSetPods: true,
+			want:                 true,
+		},
+		{
+			name:        "pod matching the selector is eligible",
+			pod:         testingOptInPod,
+			podSelector: "qos-enforce=true",
+			want:        true,
+		},
+		{
+			name:        "pod not matching the selector is not eligible",
+			pod:         testingDeploymentPod,
+			podSelector: "qos-enforce=true",
+			want:        false,
+		},
+		{
+			name:        "mirror pod matching the selector is not eligible",
+			pod:         testingMirrorPod,
+			podSelector: "!qos-enforce",
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			cfg.ExcludeDaemonSetPods = tt.excludeDaemonSetPods
+			podSelector, err := parseEnforcementPodSelector(tt.podSelector)
+			assert.NoError(t, err)
+			r := &resmanager{config: cfg, enforcementPodSelector: podSelector}
+			got := r.isEnforcementEligible(tt.pod)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func createTestPod(qosClass apiext.QoSClass, name string) *corev1.Pod {
 	return &corev1.Pod{
 		TypeMeta: metav1.TypeMeta{Kind: "Pod"},
@@ -18,6 +18,8 @@ package resmanager
 
 import (
 	"encoding/json"
+	"fmt"
+	"hash/fnv"
 
 	"k8s.io/klog/v2"
 
@@ -25,6 +27,21 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
+// validateNodeSLOSpec checks the fields of the nodeSLO spec which cannot be fixed by merging with the default config
+func validateNodeSLOSpec(spec *slov1alpha1.NodeSLOSpec) error {
+	return slov1alpha1.ValidateNodeSLOSpec(spec).ToAggregate()
+}
+
+// hashNodeSLOSpec returns the hash of the nodeSLO spec, which is stable for the same spec since the json encoding
+// sorts the map keys
+func hashNodeSLOSpec(spec *slov1alpha1.NodeSLOSpec) string {
+	// ignore err for serializing the struct type
+	data, _ := json.Marshal(spec)
+	hasher := fnv.New64a()
+	_, _ = hasher.Write(data)
+	return fmt.Sprintf("%016x", hasher.Sum64())
+}
+
 // mergeSLOSpecResourceUsedThresholdWithBE merges the nodeSLO ResourceUsedThresholdWithBE with default configs
 func mergeSLOSpecResourceUsedThresholdWithBE(defaultSpec, newSpec *slov1alpha1.ResourceThresholdStrategy) *slov1alpha1.ResourceThresholdStrategy {
 	spec := &slov1alpha1.ResourceThresholdStrategy{}
@@ -67,6 +84,26 @@ func mergeSLOSpecCPUBurstStrategy(defaultSpec,
 	return out
 }
 
+// mergeResctrlMBAConfig removes the default MBAPercent from the merged resctrl qos if only MBAMBps is specified in the
+// new spec, since MBAPercent and MBAMBps are mutually exclusive
+func mergeResctrlMBAConfig(mergedSpec, newSpec *slov1alpha1.ResourceQoSStrategy) {
+	if mergedSpec == nil || newSpec == nil {
+		return
+	}
+	mergeResctrlMBAConfigForQoS(mergedSpec.LSR, newSpec.LSR)
+	mergeResctrlMBAConfigForQoS(mergedSpec.LS, newSpec.LS)
+	mergeResctrlMBAConfigForQoS(mergedSpec.BE, newSpec.BE)
+}
+
+func mergeResctrlMBAConfigForQoS(mergedQoS, newQoS *slov1alpha1.ResourceQoS) {
+	if mergedQoS == nil || mergedQoS.ResctrlQoS == nil || newQoS == nil || newQoS.ResctrlQoS == nil {
+		return
+	}
+	if newQoS.ResctrlQoS.MBAMBps != nil && newQoS.ResctrlQoS.MBAPercent == nil {
+		mergedQoS.ResctrlQoS.MBAPercent = nil
+	}
+}
+
 // mergeNoneResourceQoSIfDisabled complete ResourceQoSStrategy according to enable statuses of qos features
 func mergeNoneResourceQoSIfDisabled(resourceQoS *slov1alpha1.ResourceQoSStrategy) {
 	mergeNoneResctrlQoSIfDisabled(resourceQoS)
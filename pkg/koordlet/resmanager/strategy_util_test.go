@@ -27,6 +27,64 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
+func Test_validateNodeSLOSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *slov1alpha1.NodeSLOSpec
+		wantErr bool
+	}{
+		{
+			name:    "nil spec is invalid",
+			spec:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "empty spec is valid",
+			spec:    &slov1alpha1.NodeSLOSpec{},
+			wantErr: false,
+		},
+		{
+			name: "valid spec",
+			spec: &slov1alpha1.NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(65),
+					MemoryEvictThresholdPercent: pointer.Int64Ptr(70),
+					MemoryEvictLowerPercent:     pointer.Int64Ptr(65),
+				},
+				CPUBurstStrategy: &slov1alpha1.CPUBurstStrategy{
+					CPUBurstConfig: slov1alpha1.CPUBurstConfig{Policy: slov1alpha1.CPUBurstAuto},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "threshold percent out of range",
+			spec: &slov1alpha1.NodeSLOSpec{
+				ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+					CPUSuppressThresholdPercent: pointer.Int64Ptr(65),
+					MemoryEvictLowerPercent:     pointer.Int64Ptr(-1),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cpu burst policy",
+			spec: &slov1alpha1.NodeSLOSpec{
+				CPUBurstStrategy: &slov1alpha1.CPUBurstStrategy{
+					CPUBurstConfig: slov1alpha1.CPUBurstConfig{Policy: "unknown"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNodeSLOSpec(tt.spec)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
 func Test_mergeSLOSpecResourceUsedThresholdWithBE(t *testing.T) {
 	testingDefaultSpec := util.DefaultResourceThresholdStrategy()
 	testingNewSpec := &slov1alpha1.ResourceThresholdStrategy{
@@ -232,3 +290,61 @@ func Test_mergeNoneResourceQoSIfDisabled(t *testing.T) {
 		})
 	}
 }
+
+func Test_mergeResctrlMBAConfig(t *testing.T) {
+	testLSMBpsOnly := &slov1alpha1.ResourceQoSStrategy{
+		LS: &slov1alpha1.ResourceQoS{
+			ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+				ResctrlQoS: slov1alpha1.ResctrlQoS{
+					MBAMBps: pointer.Int64Ptr(2000),
+				},
+			},
+		},
+	}
+	testLSMBpsOnlyResult := util.DefaultResourceQoSStrategy()
+	testLSMBpsOnlyResult.LS.ResctrlQoS.MBAPercent = nil
+	testLSMBpsOnlyResult.LS.ResctrlQoS.MBAMBps = pointer.Int64Ptr(2000)
+
+	testLSBothSet := &slov1alpha1.ResourceQoSStrategy{
+		LS: &slov1alpha1.ResourceQoS{
+			ResctrlQoS: &slov1alpha1.ResctrlQoSCfg{
+				ResctrlQoS: slov1alpha1.ResctrlQoS{
+					MBAPercent: pointer.Int64Ptr(90),
+					MBAMBps:    pointer.Int64Ptr(2000),
+				},
+			},
+		},
+	}
+	testLSBothSetResult := util.DefaultResourceQoSStrategy()
+	testLSBothSetResult.LS.ResctrlQoS.MBAPercent = pointer.Int64Ptr(90)
+	testLSBothSetResult.LS.ResctrlQoS.MBAMBps = pointer.Int64Ptr(2000)
+
+	tests := []struct {
+		name    string
+		newSpec *slov1alpha1.ResourceQoSStrategy
+		want    *slov1alpha1.ResourceQoSStrategy
+	}{
+		{
+			name:    "keep the default for empty spec",
+			newSpec: &slov1alpha1.ResourceQoSStrategy{},
+			want:    util.DefaultResourceQoSStrategy(),
+		},
+		{
+			name:    "remove the default percent when only MBps specified",
+			newSpec: testLSMBpsOnly,
+			want:    testLSMBpsOnlyResult,
+		},
+		{
+			name:    "keep both for the invalid spec",
+			newSpec: testLSBothSet,
+			want:    testLSBothSetResult,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeSLOSpecResourceQoSStrategy(util.DefaultResourceQoSStrategy(), tt.newSpec)
+			mergeResctrlMBAConfig(got, tt.newSpec)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
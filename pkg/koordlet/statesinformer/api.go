@@ -29,3 +29,41 @@ func (in *PodMeta) DeepCopy() *PodMeta {
 	out.CgroupDir = in.CgroupDir
 	return out
 }
+
+// PodEventHandler handles the pod events which are observed when the statesInformer syncs pods from kubelet.
+type PodEventHandler interface {
+	OnPodAdded(pod *PodMeta)
+	OnPodUpdated(oldPod, newPod *PodMeta)
+}
+
+type PodEventHandlerFuncs struct {
+	PodAddedFunc   func(pod *PodMeta)
+	PodUpdatedFunc func(oldPod, newPod *PodMeta)
+}
+
+func (r PodEventHandlerFuncs) OnPodAdded(pod *PodMeta) {
+	if r.PodAddedFunc != nil {
+		r.PodAddedFunc(pod)
+	}
+}
+
+func (r PodEventHandlerFuncs) OnPodUpdated(oldPod, newPod *PodMeta) {
+	if r.PodUpdatedFunc != nil {
+		r.PodUpdatedFunc(oldPod, newPod)
+	}
+}
+
+// NodeEventHandler handles the node events which are observed when the statesInformer syncs the node from apiserver.
+type NodeEventHandler interface {
+	OnNodeUpdated(oldNode, newNode *corev1.Node)
+}
+
+type NodeEventHandlerFuncs struct {
+	NodeUpdatedFunc func(oldNode, newNode *corev1.Node)
+}
+
+func (r NodeEventHandlerFuncs) OnNodeUpdated(oldNode, newNode *corev1.Node) {
+	if r.NodeUpdatedFunc != nil {
+		r.NodeUpdatedFunc(oldNode, newNode)
+	}
+}
@@ -51,6 +51,30 @@ func (m *MockStatesInformer) EXPECT() *MockStatesInformerMockRecorder {
 	return m.recorder
 }
 
+// AddNodeEventHandler mocks base method.
+func (m *MockStatesInformer) AddNodeEventHandler(handler statesinformer.NodeEventHandler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddNodeEventHandler", handler)
+}
+
+// AddNodeEventHandler indicates an expected call of AddNodeEventHandler.
+func (mr *MockStatesInformerMockRecorder) AddNodeEventHandler(handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNodeEventHandler", reflect.TypeOf((*MockStatesInformer)(nil).AddNodeEventHandler), handler)
+}
+
+// AddPodEventHandler mocks base method.
+func (m *MockStatesInformer) AddPodEventHandler(handler statesinformer.PodEventHandler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddPodEventHandler", handler)
+}
+
+// AddPodEventHandler indicates an expected call of AddPodEventHandler.
+func (mr *MockStatesInformerMockRecorder) AddPodEventHandler(handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPodEventHandler", reflect.TypeOf((*MockStatesInformer)(nil).AddPodEventHandler), handler)
+}
+
 // GetAllPods mocks base method.
 func (m *MockStatesInformer) GetAllPods() []*statesinformer.PodMeta {
 	m.ctrl.T.Helper()
@@ -34,6 +34,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/pleg"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -46,6 +47,14 @@ type StatesInformer interface {
 	GetNode() *corev1.Node
 
 	GetAllPods() []*PodMeta
+
+	// AddPodEventHandler registers a handler which is notified when pods are added or updated.
+	// NOTE: the handler is called synchronously in the pod syncing, so it should not block.
+	AddPodEventHandler(handler PodEventHandler)
+
+	// AddNodeEventHandler registers a handler which is notified when the synced node is updated.
+	// NOTE: the handler is called synchronously in the node syncing, so it should not block.
+	AddNodeEventHandler(handler NodeEventHandler)
 }
 
 type statesInformer struct {
@@ -63,6 +72,12 @@ type statesInformer struct {
 	podRWMutex     sync.RWMutex
 	podMap         map[string]*PodMeta
 	podUpdatedTime time.Time
+
+	podHandlerMutex  sync.RWMutex
+	podEventHandlers []PodEventHandler
+
+	nodeHandlerMutex  sync.RWMutex
+	nodeEventHandlers []NodeEventHandler
 }
 
 func NewStatesInformer(config *Config, kubeClient clientset.Interface, pleg pleg.Pleg, nodeName string) StatesInformer {
@@ -161,6 +176,18 @@ func (m *statesInformer) GetAllPods() []*PodMeta {
 	return pods
 }
 
+func (m *statesInformer) AddPodEventHandler(handler PodEventHandler) {
+	m.podHandlerMutex.Lock()
+	defer m.podHandlerMutex.Unlock()
+	m.podEventHandlers = append(m.podEventHandlers, handler)
+}
+
+func (m *statesInformer) AddNodeEventHandler(handler NodeEventHandler) {
+	m.nodeHandlerMutex.Lock()
+	defer m.nodeHandlerMutex.Unlock()
+	m.nodeEventHandlers = append(m.nodeEventHandlers, handler)
+}
+
 func newNodeInformer(client clientset.Interface, nodeName string) cache.SharedIndexInformer {
 	tweakListOptionsFunc := func(opt *metav1.ListOptions) {
 		opt.FieldSelector = "metadata.name=" + nodeName
@@ -186,11 +213,24 @@ func newNodeInformer(client clientset.Interface, nodeName string) cache.SharedIn
 func (m *statesInformer) syncNode(newNode *corev1.Node) {
 	klog.V(5).Infof("node update detail %v", newNode)
 	m.nodeRWMutex.Lock()
-	defer m.nodeRWMutex.Unlock()
+	oldNode := m.node
 	m.node = newNode
 
 	// also register node for metrics
 	metrics.Register(newNode)
+	m.nodeRWMutex.Unlock()
+
+	if oldNode != nil {
+		m.notifyNodeEvents(oldNode, newNode)
+	}
+}
+
+func (m *statesInformer) notifyNodeEvents(oldNode, newNode *corev1.Node) {
+	m.nodeHandlerMutex.RLock()
+	defer m.nodeHandlerMutex.RUnlock()
+	for _, handler := range m.nodeEventHandlers {
+		handler.OnNodeUpdated(oldNode.DeepCopy(), newNode.DeepCopy())
+	}
 }
 
 func (m *statesInformer) syncKubelet() error {
@@ -200,19 +240,53 @@ func (m *statesInformer) syncKubelet() error {
 		return err
 	}
 	newPodMap := make(map[string]*PodMeta, len(podList.Items))
+	unclassifiedPods := 0
 	for _, pod := range podList.Items {
 		newPodMap[string(pod.UID)] = &PodMeta{
 			Pod:       pod.DeepCopy(),
 			CgroupDir: genPodCgroupParentDir(&pod),
 		}
+		if !apiext.IsPodQoSClassified(&pod) {
+			unclassifiedPods++
+		}
 	}
+	metrics.RecordUnclassifiedPods(float64(unclassifiedPods))
+	m.podRWMutex.Lock()
+	oldPodMap := m.podMap
 	m.podMap = newPodMap
+	m.podRWMutex.Unlock()
 	m.hasSynced.Store(true)
 	m.podUpdatedTime = time.Now()
-	klog.Infof("get pods from kubelet success, len %d", len(m.podMap))
+	klog.Infof("get pods from kubelet success, len %d", len(newPodMap))
+
+	m.notifyPodEvents(oldPodMap, newPodMap)
 	return nil
 }
 
+// notifyPodEvents notifies the pod event handlers with the pods added or updated since the last sync.
+func (m *statesInformer) notifyPodEvents(oldPodMap, newPodMap map[string]*PodMeta) {
+	m.podHandlerMutex.RLock()
+	defer m.podHandlerMutex.RUnlock()
+	if len(m.podEventHandlers) <= 0 {
+		return
+	}
+	for uid, newPod := range newPodMap {
+		oldPod, ok := oldPodMap[uid]
+		if !ok {
+			for _, handler := range m.podEventHandlers {
+				handler.OnPodAdded(newPod.DeepCopy())
+			}
+			continue
+		}
+		if reflect.DeepEqual(oldPod.Pod, newPod.Pod) {
+			continue
+		}
+		for _, handler := range m.podEventHandlers {
+			handler.OnPodUpdated(oldPod.DeepCopy(), newPod.DeepCopy())
+		}
+	}
+}
+
 func (m *statesInformer) syncKubeletLoop(duration time.Duration, stopCh <-chan struct{}) {
 	timer := time.NewTimer(duration)
 	defer timer.Stop()
@@ -20,9 +20,14 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/util/system"
 )
@@ -152,6 +157,115 @@ func Test_metaService_syncNode(t *testing.T) {
 	m.syncNode(testingNode)
 }
 
+func Test_statesInformer_syncNodeEvents(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	}
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+
+	m := &statesInformer{}
+	var updated []string
+	m.AddNodeEventHandler(NodeEventHandlerFuncs{
+		NodeUpdatedFunc: func(oldNode, newNode *corev1.Node) {
+			assert.Equal(t, "100Gi", oldNode.Status.Allocatable.Memory().String())
+			updated = append(updated, newNode.Status.Allocatable.Memory().String())
+		},
+	})
+
+	// node added
+	m.syncNode(testingNode)
+	assert.Nil(t, updated)
+
+	// node updated
+	newNode := testingNode.DeepCopy()
+	newNode.Status.Allocatable[corev1.ResourceMemory] = resource.MustParse("90Gi")
+	m.syncNode(newNode)
+	assert.Equal(t, []string{"90Gi"}, updated)
+	assert.Equal(t, newNode, m.GetNode())
+}
+
+type fakeKubeletStub struct {
+	pods corev1.PodList
+}
+
+func (f *fakeKubeletStub) GetAllPods() (corev1.PodList, error) {
+	return f.pods, nil
+}
+
+func Test_statesInformer_syncKubeletPodEvents(t *testing.T) {
+	testingPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-pod",
+			UID:  "xxx-yyy-zzz",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+		},
+	}
+	kubelet := &fakeKubeletStub{pods: corev1.PodList{Items: []corev1.Pod{testingPod}}}
+	m := &statesInformer{
+		kubelet:   kubelet,
+		hasSynced: atomic.NewBool(false),
+		podMap:    map[string]*PodMeta{},
+	}
+	var added, updated []string
+	m.AddPodEventHandler(PodEventHandlerFuncs{
+		PodAddedFunc: func(pod *PodMeta) {
+			added = append(added, string(pod.Pod.UID))
+		},
+		PodUpdatedFunc: func(oldPod, newPod *PodMeta) {
+			assert.Equal(t, corev1.PodPending, oldPod.Pod.Status.Phase)
+			updated = append(updated, string(newPod.Pod.UID))
+		},
+	})
+
+	// pod added
+	assert.NoError(t, m.syncKubelet())
+	assert.Equal(t, []string{"xxx-yyy-zzz"}, added)
+	assert.Nil(t, updated)
+
+	// pod not changed
+	assert.NoError(t, m.syncKubelet())
+	assert.Equal(t, []string{"xxx-yyy-zzz"}, added)
+	assert.Nil(t, updated)
+
+	// pod updated
+	kubelet.pods.Items[0].Status.Phase = corev1.PodRunning
+	assert.NoError(t, m.syncKubelet())
+	assert.Equal(t, []string{"xxx-yyy-zzz"}, added)
+	assert.Equal(t, []string{"xxx-yyy-zzz"}, updated)
+}
+
+func Test_statesInformer_syncKubeletUnclassifiedPods(t *testing.T) {
+	testingNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	metrics.Register(testingNode)
+	defer metrics.Register(nil)
+	apiext.DefaultQoSClass = apiext.QoSBE
+	defer func() { apiext.DefaultQoSClass = apiext.QoSNone }()
+
+	kubelet := &fakeKubeletStub{pods: corev1.PodList{Items: []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-pod-0", UID: "uid-0"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-pod-1", UID: "uid-1", Labels: map[string]string{apiext.LabelPodQoS: "unknown"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-pod-2", UID: "uid-2", Labels: map[string]string{apiext.LabelPodQoS: string(apiext.QoSLS)}}},
+	}}}
+	m := &statesInformer{
+		kubelet:   kubelet,
+		hasSynced: atomic.NewBool(false),
+		podMap:    map[string]*PodMeta{},
+	}
+	assert.NoError(t, m.syncKubelet())
+	// the pods taken as DefaultQoSClass are still unclassified
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.UnclassifiedPods.WithLabelValues(testingNode.Name)))
+}
+
 // TODO: fix data race, https://github.com/koordinator-sh/koordinator/issues/77
 
 // type testKubeletStub struct {
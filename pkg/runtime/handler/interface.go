@@ -24,6 +24,8 @@ const (
 	defaultConnectionTimeout = 5 * time.Second
 )
 
+// ContainerRuntimeHandler operates the containers through the container runtime. The implementations must be safe
+// for concurrent use, e.g. the koordlet stops the containers of a pod concurrently.
 type ContainerRuntimeHandler interface {
 	StopContainer(containerID string, timeout int64) error
 	UpdateContainerResources(containerID string, opts UpdateOptions) error
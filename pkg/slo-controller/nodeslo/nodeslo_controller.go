@@ -79,6 +79,12 @@ func (r *NodeSLOReconciler) getNodeSLOSpec(node *corev1.Node, oldSpec *slov1alph
 		ResourceUsedThresholdWithBE: util.DefaultResourceThresholdStrategy(),
 		ResourceQoSStrategy:         &slov1alpha1.ResourceQoSStrategy{},
 	}
+	// the feature gates and the shadow threshold are set on the NodeSLO per node rather than from the configmap, keep
+	// them unchanged
+	if oldSpec != nil {
+		nodeSLOSpec.FeatureGates = oldSpec.FeatureGates
+		nodeSLOSpec.ShadowResourceUsedThresholdWithBE = oldSpec.ShadowResourceUsedThresholdWithBE
+	}
 
 	// TODO: record an event about the failure reason on configmap/crd when failed to load the config
 	configMap := &corev1.ConfigMap{}
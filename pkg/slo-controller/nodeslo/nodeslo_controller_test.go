@@ -235,6 +235,33 @@ func TestNodeSLOReconciler_initNodeSLO(t *testing.T) {
 	}
 }
 
+func TestNodeSLOReconciler_getNodeSLOSpec_keepPerNodeConfigs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	slov1alpha1.AddToScheme(scheme)
+	r := &NodeSLOReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+	testingNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	oldSpec := &slov1alpha1.NodeSLOSpec{
+		FeatureGates: map[string]bool{"BECPUSuppress": true},
+		ShadowResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+			CPUSuppressThresholdPercent: pointer.Int64Ptr(50),
+		},
+	}
+
+	got, err := r.getNodeSLOSpec(testingNode, oldSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, oldSpec.FeatureGates, got.FeatureGates)
+	assert.Equal(t, oldSpec.ShadowResourceUsedThresholdWithBE, got.ShadowResourceUsedThresholdWithBE)
+
+	got, err = r.getNodeSLOSpec(testingNode, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, got.FeatureGates)
+	assert.Nil(t, got.ShadowResourceUsedThresholdWithBE)
+}
+
 func TestNodeSLOReconciler_Reconcile(t *testing.T) {
 	// initial variants
 	scheme := runtime.NewScheme()
@@ -339,3 +366,62 @@ func TestNodeSLOReconciler_Reconcile(t *testing.T) {
 		t.Errorf("the testing NodeSLO should not exist after the Node is deleted, err: %s", err)
 	}
 }
+
+func TestNodeSLOReconciler_Reconcile_memoryEvictReserveBytes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	slov1alpha1.AddToScheme(scheme)
+	r := &NodeSLOReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Log:    ctrl.Log.WithName("controllers").WithName("NodeSLO"),
+		Scheme: scheme,
+	}
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Labels: map[string]string{
+				"xxx": "yyy",
+			},
+		},
+	}
+	testingConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.SLOCtrlConfigMap,
+			Namespace: config.ConfigNameSpace,
+		},
+		Data: map[string]string{
+			config.ResourceThresholdConfigKey: `
+{
+  "clusterStrategy": {
+    "enable": true,
+    "memoryEvictThresholdPercent": 80
+  },
+  "nodeStrategies": [
+    {
+      "nodeSelector": {
+        "matchLabels": {
+          "xxx": "yyy"
+        }
+      },
+      "memoryEvictReserveBytes": "2Gi"
+    }
+  ]
+}
+`,
+		},
+	}
+	nodeReq := ctrl.Request{NamespacedName: types.NamespacedName{Name: testingNode.Name}}
+	assert.NoError(t, r.Client.Create(context.TODO(), testingNode))
+	assert.NoError(t, r.Client.Create(context.TODO(), testingConfigMap))
+	_, err := r.Reconcile(context.TODO(), nodeReq)
+	assert.NoError(t, err)
+
+	// the cluster threshold percent is dropped in favor of the node reserve bytes
+	nodeSLO := &slov1alpha1.NodeSLO{}
+	assert.NoError(t, r.Client.Get(context.TODO(), nodeReq.NamespacedName, nodeSLO))
+	got := nodeSLO.Spec.ResourceUsedThresholdWithBE
+	assert.Equal(t, pointer.BoolPtr(true), got.Enable)
+	assert.Nil(t, got.MemoryEvictThresholdPercent)
+	assert.NotNil(t, got.MemoryEvictReserveBytes)
+	assert.Equal(t, int64(2<<30), got.MemoryEvictReserveBytes.Value())
+}
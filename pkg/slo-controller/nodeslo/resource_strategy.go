@@ -44,10 +44,14 @@ func getResourceThresholdSpec(node *corev1.Node, configMap *corev1.ConfigMap) (*
 		return nil, err
 	}
 
+	// the default memory evict threshold percent should be dropped if the memory evict reserve bytes is specified
+	isMemoryEvictPercentSpecified := false
+
 	// use cluster strategy if no node strategy matched
 	if cfg.ClusterStrategy != nil {
 		mergedStrategyInterface, _ := util.MergeCfg(mergedStrategy, cfg.ClusterStrategy)
 		mergedStrategy = mergedStrategyInterface.(*slov1alpha1.ResourceThresholdStrategy)
+		isMemoryEvictPercentSpecified = cfg.ClusterStrategy.MemoryEvictThresholdPercent != nil
 	}
 
 	// NOTE: sort selectors by the string order
@@ -67,11 +71,21 @@ func getResourceThresholdSpec(node *corev1.Node, configMap *corev1.ConfigMap) (*
 			if nodeStrategy.ResourceThresholdStrategy != nil {
 				mergedStrategyInterface, _ := util.MergeCfg(mergedStrategy, nodeStrategy.ResourceThresholdStrategy)
 				mergedStrategy = mergedStrategyInterface.(*slov1alpha1.ResourceThresholdStrategy)
+				if nodeStrategy.MemoryEvictThresholdPercent != nil {
+					isMemoryEvictPercentSpecified = true
+				} else if nodeStrategy.MemoryEvictReserveBytes != nil {
+					// the node reserve bytes overrides the threshold percent inherited from the cluster strategy
+					isMemoryEvictPercentSpecified = false
+				}
 			}
 			break
 		}
 	}
 
+	if mergedStrategy.MemoryEvictReserveBytes != nil && !isMemoryEvictPercentSpecified {
+		mergedStrategy.MemoryEvictThresholdPercent = nil
+	}
+
 	return mergedStrategy, nil
 }
 
@@ -23,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
@@ -212,6 +213,96 @@ func Test_getResourceThresholdSpec(t *testing.T) {
 	}
 }
 
+func Test_getResourceThresholdSpec_memoryEvictReserveBytes(t *testing.T) {
+	testingNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Labels: map[string]string{
+				"xxx": "yyy",
+			},
+		},
+	}
+	tests := []struct {
+		name                            string
+		cfg                             *config.ResourceThresholdCfg
+		wantMemoryEvictThresholdPercent *int64
+	}{
+		{
+			name: "drop the default threshold percent",
+			cfg: &config.ResourceThresholdCfg{
+				ClusterStrategy: &slov1alpha1.ResourceThresholdStrategy{
+					MemoryEvictReserveBytes: resource.NewQuantity(2<<30, resource.BinarySI),
+				},
+			},
+			wantMemoryEvictThresholdPercent: nil,
+		},
+		{
+			name: "drop the default threshold percent for the node strategy",
+			cfg: &config.ResourceThresholdCfg{
+				NodeStrategies: []config.NodeResourceThresholdStrategy{
+					{
+						NodeSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								"xxx": "yyy",
+							},
+						},
+						ResourceThresholdStrategy: &slov1alpha1.ResourceThresholdStrategy{
+							MemoryEvictReserveBytes: resource.NewQuantity(2<<30, resource.BinarySI),
+						},
+					},
+				},
+			},
+			wantMemoryEvictThresholdPercent: nil,
+		},
+		{
+			name: "drop the cluster threshold percent for the node strategy",
+			cfg: &config.ResourceThresholdCfg{
+				ClusterStrategy: &slov1alpha1.ResourceThresholdStrategy{
+					MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+				},
+				NodeStrategies: []config.NodeResourceThresholdStrategy{
+					{
+						NodeSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								"xxx": "yyy",
+							},
+						},
+						ResourceThresholdStrategy: &slov1alpha1.ResourceThresholdStrategy{
+							MemoryEvictReserveBytes: resource.NewQuantity(2<<30, resource.BinarySI),
+						},
+					},
+				},
+			},
+			wantMemoryEvictThresholdPercent: nil,
+		},
+		{
+			name: "keep the specified threshold percent",
+			cfg: &config.ResourceThresholdCfg{
+				ClusterStrategy: &slov1alpha1.ResourceThresholdStrategy{
+					MemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+					MemoryEvictReserveBytes:     resource.NewQuantity(2<<30, resource.BinarySI),
+				},
+			},
+			wantMemoryEvictThresholdPercent: pointer.Int64Ptr(80),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgStr, _ := json.Marshal(tt.cfg)
+			configMap := &corev1.ConfigMap{
+				Data: map[string]string{
+					config.ResourceThresholdConfigKey: string(cfgStr),
+				},
+			}
+			got, gotErr := getResourceThresholdSpec(testingNode, configMap)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.wantMemoryEvictThresholdPercent, got.MemoryEvictThresholdPercent)
+			assert.NotNil(t, got.MemoryEvictReserveBytes)
+			assert.Equal(t, int64(2<<30), got.MemoryEvictReserveBytes.Value())
+		})
+	}
+}
+
 func Test_generateThresholdCfg(t *testing.T) {
 	cfg := config.ResourceThresholdCfg{}
 	cfg.ClusterStrategy = util.DefaultResourceThresholdStrategy()
@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 const (
@@ -42,6 +43,7 @@ type Cache struct {
 	gcInterval        time.Duration
 	gcStarted         bool
 	mu                sync.Mutex
+	clock             clock.Clock
 }
 
 func NewCacheDefault() *Cache {
@@ -49,14 +51,21 @@ func NewCacheDefault() *Cache {
 		items:             map[string]item{},
 		defaultExpiration: defaultExpiration,
 		gcInterval:        defaultGCInterval,
+		clock:             clock.RealClock{},
 	}
 }
 
 func NewCache(expiration time.Duration, gcInterval time.Duration) *Cache {
+	return NewCacheWithClock(expiration, gcInterval, clock.RealClock{})
+}
+
+// NewCacheWithClock creates a Cache which checks the expiration with the given clock.
+func NewCacheWithClock(expiration time.Duration, gcInterval time.Duration, clock clock.Clock) *Cache {
 	cache := Cache{
 		items:             map[string]item{},
 		defaultExpiration: expiration,
 		gcInterval:        gcInterval,
+		clock:             clock,
 	}
 	if cache.defaultExpiration <= 0 {
 		cache.defaultExpiration = defaultExpiration
@@ -79,7 +88,7 @@ func (c *Cache) Run(stopCh <-chan struct{}) error {
 func (c *Cache) gcExpiredCache() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	gcTime := time.Now()
+	gcTime := c.clock.Now()
 	var gcKeys []string
 	for key, item := range c.items {
 		if gcTime.After(item.expirationTime) {
@@ -106,7 +115,7 @@ func (c *Cache) set(key string, value interface{}, expiration time.Duration) err
 	}
 	item := item{
 		object:         value,
-		expirationTime: time.Now().Add(expiration),
+		expirationTime: c.clock.Now().Add(expiration),
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -121,7 +130,7 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 	if !ok {
 		return nil, false
 	}
-	if item.expirationTime.Before(time.Now()) {
+	if item.expirationTime.Before(c.clock.Now()) {
 		return nil, false
 	}
 	return item.object, true
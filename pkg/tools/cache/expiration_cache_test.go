@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	testingclock "k8s.io/utils/clock/testing"
 )
 
 func Test_Cache_Get(t *testing.T) {
@@ -58,6 +59,26 @@ func Test_Cache_Set(t *testing.T) {
 
 }
 
+func Test_Cache_WithClock(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	cache := NewCacheWithClock(time.Minute, time.Minute, fakeClock)
+	cache.gcStarted = true
+
+	_ = cache.SetDefault("key", "value")
+	fakeClock.Step(time.Minute)
+	value, found := cache.Get("key")
+	assert.True(t, found, "value found before expiration")
+	assert.Equal(t, "value", value)
+
+	fakeClock.Step(time.Nanosecond)
+	value, found = cache.Get("key")
+	assert.True(t, !found, "value not found after expiration")
+	assert.Nil(t, value, "value must be nil")
+
+	cache.gcExpiredCache()
+	assert.Equal(t, 0, len(cache.items))
+}
+
 func Test_gcExpiredCache(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -214,8 +214,11 @@ func GetContainerCurTasks(podParentDir string, c *corev1.ContainerStatus) ([]int
 }
 
 func FindContainerIdAndStatusByName(status *corev1.PodStatus, name string) (string, *corev1.ContainerStatus, error) {
-	allStatuses := status.InitContainerStatuses
+	allStatuses := make([]corev1.ContainerStatus, 0,
+		len(status.InitContainerStatuses)+len(status.ContainerStatuses)+len(status.EphemeralContainerStatuses))
+	allStatuses = append(allStatuses, status.InitContainerStatuses...)
 	allStatuses = append(allStatuses, status.ContainerStatuses...)
+	allStatuses = append(allStatuses, status.EphemeralContainerStatuses...)
 	for _, container := range allStatuses {
 		if container.Name == name && container.ContainerID != "" {
 			_, cID, err := ParseContainerId(container.ContainerID)
@@ -347,6 +347,30 @@ func Test_FindContainerIdAndStatusByName(t *testing.T) {
 			expectContainerId:     "",
 			expectErr:             true,
 		},
+		{
+			name: "testEphemeralContainer",
+			podStatus: &corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:        "main",
+						ContainerID: fmt.Sprintf("docker://%s", "main"),
+					},
+				},
+				EphemeralContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:        "debugger",
+						ContainerID: fmt.Sprintf("docker://%s", "debugger"),
+					},
+				},
+			},
+			containerName: "debugger",
+			expectContainerStatus: &corev1.ContainerStatus{
+				Name:        "debugger",
+				ContainerID: fmt.Sprintf("docker://%s", "debugger"),
+			},
+			expectContainerId: "debugger",
+			expectErr:         false,
+		},
 		{
 			name: "testNotfoundContainer",
 			podStatus: &corev1.PodStatus{
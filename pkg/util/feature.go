@@ -17,6 +17,7 @@
 package util
 
 import (
+	"math/rand"
 	"reflect"
 	"runtime"
 	"time"
@@ -28,6 +29,10 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/features"
 )
 
+// FeatureJitterFactor is the max fraction of the interval to randomly delay the first run of the feature modules, which
+// desynchronizes the reconciliation of the nodes started at the same time. The jitter is disabled if it is not positive.
+var FeatureJitterFactor = 0.1
+
 // RunFeature runs moduleFunc only if interval > 0 AND at least one feature dependency is enabled
 func RunFeature(moduleFunc func(), featureDependency []featuregate.Feature, interval int, stopCh <-chan struct{}) bool {
 	ret, _ := RunFeatureWithInit(func() error { return nil }, moduleFunc, featureDependency, interval, stopCh)
@@ -37,32 +42,147 @@ func RunFeature(moduleFunc func(), featureDependency []featuregate.Feature, inte
 // RunFeatureWithInit runs moduleFunc only if interval > 0 , at least one feature dependency is enabled
 // and moduleInit function returns nil
 func RunFeatureWithInit(moduleInit func() error, moduleFunc func(), featureDependency []featuregate.Feature, interval int, stopCh <-chan struct{}) (bool, error) {
-	moduleInitName := runtime.FuncForPC(reflect.ValueOf(moduleInit).Pointer()).Name()
-	moduleFuncName := runtime.FuncForPC(reflect.ValueOf(moduleFunc).Pointer()).Name()
+	return runFeatureWithInit(getFuncName(moduleFunc), moduleInit, moduleFunc, featureDependency, interval, stopCh)
+}
+
+// runFeatureWithInit is RunFeatureWithInit which logs the module by moduleName.
+func runFeatureWithInit(moduleName string, moduleInit func() error, moduleFunc func(), featureDependency []featuregate.Feature,
+	interval int, stopCh <-chan struct{}) (bool, error) {
 	if interval <= 0 {
-		klog.Infof("time interval %v is disabled, skip run %v module", interval, moduleFuncName)
+		klog.Infof("time interval %v is disabled, skip run %v module", interval, moduleName)
 		return false, nil
 	}
 
-	moduleFuncEnabled := len(featureDependency) == 0
-	for _, feature := range featureDependency {
-		if features.DefaultKoordletFeatureGate.Enabled(feature) {
-			moduleFuncEnabled = true
-			break
-		}
-	}
+	moduleFuncEnabled := len(featureDependency) == 0 || isAnyFeatureEnabled(featureDependency)
 	if !moduleFuncEnabled {
-		klog.Infof("all feature dependency %v is disabled, skip run module %v", featureDependency, moduleFuncName)
+		klog.Infof("all feature dependency %v is disabled, skip run module %v", featureDependency, moduleName)
 		return false, nil
 	}
 
-	klog.Infof("starting %v feature init module", moduleInitName)
+	klog.Infof("starting %v feature init module", moduleName)
 	if err := moduleInit(); err != nil {
-		klog.Errorf("starting %v feature init module error %v", moduleInitName, err)
+		klog.Errorf("starting %v feature init module error %v", moduleName, err)
 		return false, err
 	}
 
-	klog.Infof("starting %v feature dependency module, interval seconds %v", moduleFuncName, interval)
-	go wait.Until(moduleFunc, time.Duration(interval)*time.Second, stopCh)
+	period := time.Duration(interval) * time.Second
+	startDelay := getFeatureStartDelay(period)
+	klog.Infof("starting %v feature dependency module, interval seconds %v, start delay %v", moduleName, interval, startDelay)
+	go func() {
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(startDelay):
+		}
+		wait.Until(moduleFunc, period, stopCh)
+	}()
+	return true, nil
+}
+
+// DynamicFeatureGate returns whether the feature is enabled at runtime, e.g. by the NodeSLO of the node.
+type DynamicFeatureGate func(feature featuregate.Feature) bool
+
+// RunFeatureWithDynamicGate runs moduleFunc like RunFeatureWithInit if at least one feature dependency is enabled by
+// the static feature gate. Otherwise, the module is checked every interval and runs only while the dynamicGate enables
+// at least one feature dependency, so it can be started and stopped at runtime. The moduleInit is called at the first
+// time the module gets enabled and retried in the next interval if it fails. The module is logged by moduleName, since
+// the moduleFunc can be a closure without a meaningful name.
+func RunFeatureWithDynamicGate(moduleName string, moduleInit func() error, moduleFunc func(),
+	featureDependency []featuregate.Feature, dynamicGate DynamicFeatureGate, interval int, stopCh <-chan struct{}) (bool, error) {
+	if interval <= 0 || dynamicGate == nil || len(featureDependency) == 0 || isAnyFeatureEnabled(featureDependency) {
+		return runFeatureWithInit(moduleName, moduleInit, moduleFunc, featureDependency, interval, stopCh)
+	}
+
+	runner := newDynamicFeatureRunner(moduleName, moduleInit, moduleFunc, featureDependency, dynamicGate)
+	period := time.Duration(interval) * time.Second
+	startDelay := getFeatureStartDelay(period)
+	klog.Infof("starting %v dynamic feature dependency module, interval seconds %v, start delay %v",
+		runner.moduleName, interval, startDelay)
+	go func() {
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(startDelay):
+		}
+		wait.Until(runner.run, period, stopCh)
+	}()
 	return true, nil
 }
+
+func isAnyFeatureEnabled(featureDependency []featuregate.Feature) bool {
+	for _, feature := range featureDependency {
+		if features.DefaultKoordletFeatureGate.Enabled(feature) {
+			return true
+		}
+	}
+	return false
+}
+
+// dynamicFeatureRunner runs the module while the dynamic gate enables any of its feature dependencies.
+type dynamicFeatureRunner struct {
+	moduleInit        func() error
+	moduleFunc        func()
+	moduleName        string
+	featureDependency []featuregate.Feature
+	dynamicGate       DynamicFeatureGate
+
+	initialized bool
+	running     bool
+}
+
+func newDynamicFeatureRunner(moduleName string, moduleInit func() error, moduleFunc func(),
+	featureDependency []featuregate.Feature, dynamicGate DynamicFeatureGate) *dynamicFeatureRunner {
+	return &dynamicFeatureRunner{
+		moduleInit:        moduleInit,
+		moduleFunc:        moduleFunc,
+		moduleName:        moduleName,
+		featureDependency: featureDependency,
+		dynamicGate:       dynamicGate,
+	}
+}
+
+func (d *dynamicFeatureRunner) isEnabled() bool {
+	for _, feature := range d.featureDependency {
+		if d.dynamicGate(feature) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *dynamicFeatureRunner) run() {
+	if !d.isEnabled() {
+		if d.running {
+			klog.Infof("all dynamic feature dependency %v is disabled, stop running module %v",
+				d.featureDependency, d.moduleName)
+			d.running = false
+		}
+		return
+	}
+
+	if !d.initialized {
+		if err := d.moduleInit(); err != nil {
+			klog.Errorf("init dynamic feature module %v error %v, retry later", d.moduleName, err)
+			return
+		}
+		d.initialized = true
+	}
+	if !d.running {
+		klog.Infof("dynamic feature dependency %v is enabled, start running module %v",
+			d.featureDependency, d.moduleName)
+		d.running = true
+	}
+	d.moduleFunc()
+}
+
+func getFuncName(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// getFeatureStartDelay returns a random delay in [0, FeatureJitterFactor * period) before the first run of the module.
+func getFeatureStartDelay(period time.Duration) time.Duration {
+	if FeatureJitterFactor <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Float64() * FeatureJitterFactor * float64(period))
+}
@@ -0,0 +1,171 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/component-base/featuregate"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+func Test_getFeatureStartDelay(t *testing.T) {
+	oldFactor := FeatureJitterFactor
+	defer func() { FeatureJitterFactor = oldFactor }()
+
+	period := 10 * time.Second
+	FeatureJitterFactor = 0.5
+	delay1 := getFeatureStartDelay(period)
+	delay2 := getFeatureStartDelay(period)
+	assert.True(t, delay1 >= 0 && delay1 < 5*time.Second, "delay %v out of range", delay1)
+	assert.True(t, delay2 >= 0 && delay2 < 5*time.Second, "delay %v out of range", delay2)
+	assert.NotEqual(t, delay1, delay2, "runners with the same interval should get different start delays")
+
+	FeatureJitterFactor = 0
+	assert.Equal(t, time.Duration(0), getFeatureStartDelay(period))
+}
+
+func TestRunFeature(t *testing.T) {
+	oldFactor := FeatureJitterFactor
+	defer func() { FeatureJitterFactor = oldFactor }()
+	FeatureJitterFactor = 0.1
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	assert.False(t, RunFeature(func() {}, nil, 0, stopCh), "module should not run with the interval disabled")
+
+	called := make(chan struct{}, 1)
+	moduleFunc := func() {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+	}
+	assert.True(t, RunFeature(moduleFunc, nil, 1, stopCh))
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Errorf("module should run after the start delay")
+	}
+}
+
+func Test_dynamicFeatureRunner(t *testing.T) {
+	enabled := false
+	initErr := fmt.Errorf("init failed")
+	initCalled, funcCalled := 0, 0
+	runner := newDynamicFeatureRunner(string(features.BEMemoryEvict), func() error {
+		initCalled++
+		return initErr
+	}, func() {
+		funcCalled++
+	}, []featuregate.Feature{features.BECPUSuppress, features.BEMemoryEvict}, func(feature featuregate.Feature) bool {
+		return enabled && feature == features.BEMemoryEvict
+	})
+
+	assert.Equal(t, string(features.BEMemoryEvict), runner.moduleName)
+
+	// disabled by the dynamic gate
+	runner.run()
+	assert.Equal(t, 0, initCalled)
+	assert.Equal(t, 0, funcCalled)
+
+	// enabled but the init fails
+	enabled = true
+	runner.run()
+	assert.Equal(t, 1, initCalled)
+	assert.Equal(t, 0, funcCalled)
+	assert.False(t, runner.running)
+
+	// init is retried and the module starts
+	initErr = nil
+	runner.run()
+	runner.run()
+	assert.Equal(t, 2, initCalled)
+	assert.Equal(t, 2, funcCalled)
+	assert.True(t, runner.running)
+
+	// module stops when disabled
+	enabled = false
+	runner.run()
+	assert.Equal(t, 2, funcCalled)
+	assert.False(t, runner.running)
+
+	// module restarts without init again
+	enabled = true
+	runner.run()
+	assert.Equal(t, 2, initCalled)
+	assert.Equal(t, 3, funcCalled)
+	assert.True(t, runner.running)
+}
+
+func TestRunFeatureWithDynamicGate(t *testing.T) {
+	oldFactor := FeatureJitterFactor
+	defer func() { FeatureJitterFactor = oldFactor }()
+	FeatureJitterFactor = 0
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	var enabled, funcCalled int32
+	gateChecked := make(chan struct{}, 10)
+	dynamicGate := func(feature featuregate.Feature) bool {
+		select {
+		case gateChecked <- struct{}{}:
+		default:
+		}
+		return atomic.LoadInt32(&enabled) == 1
+	}
+	moduleFunc := func() {
+		atomic.AddInt32(&funcCalled, 1)
+	}
+	waitGateChecked := func() {
+		select {
+		case <-gateChecked:
+		case <-time.After(3 * time.Second):
+			t.Fatalf("dynamic gate should be checked every interval")
+		}
+	}
+
+	ok, err := RunFeatureWithDynamicGate(string(features.BECPUSuppress), func() error { return nil }, moduleFunc,
+		[]featuregate.Feature{features.BECPUSuppress}, dynamicGate, 1, stopCh)
+	assert.NoError(t, err)
+	assert.True(t, ok, "module should be watched by the dynamic gate even if the static gate is disabled")
+
+	// not running until enabled at runtime
+	waitGateChecked()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&funcCalled))
+
+	// started after enabled
+	atomic.StoreInt32(&enabled, 1)
+	waitGateChecked()
+	waitGateChecked()
+	called := atomic.LoadInt32(&funcCalled)
+	assert.True(t, called >= 1, "module should run after enabled")
+
+	// stopped after disabled
+	atomic.StoreInt32(&enabled, 0)
+	waitGateChecked()
+	called = atomic.LoadInt32(&funcCalled)
+	waitGateChecked()
+	assert.Equal(t, called, atomic.LoadInt32(&funcCalled), "module should not run after disabled")
+}
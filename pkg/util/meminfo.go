@@ -24,6 +24,8 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
 )
 
 const (
@@ -172,3 +174,43 @@ func GetContainerMemStatUsageBytes(podCgroupDir string, c *corev1.ContainerStatu
 	}
 	return readCgroupMemStat(containerMemStatPath)
 }
+
+// CgroupMemOOMControl is the oom status of a memory cgroup in memory.oom_control
+type CgroupMemOOMControl struct {
+	// UnderOOM is whether the cgroup is under oom currently
+	UnderOOM bool
+	// OOMKill is the number of the processes belonging to the cgroup killed by the OOM killer
+	OOMKill int64
+}
+
+// GetCgroupMemOOMControl returns the oom status of the cgroup, which fails if the kernel does not count the oom kills.
+// @cgroupDir kubepods.slice/kubepods-besteffort.slice/
+func GetCgroupMemOOMControl(cgroupDir string) (*CgroupMemOOMControl, error) {
+	rawOOMControl, err := system.CgroupFileRead(cgroupDir, system.MemOomControl)
+	if err != nil {
+		return nil, err
+	}
+	// format: oom_kill_disable $disable\nunder_oom $under_oom\noom_kill $oom_kill\n
+	oomControl := &CgroupMemOOMControl{}
+	isOOMKillFound := false
+	for _, line := range strings.Split(rawOOMControl, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || (fields[0] != "under_oom" && fields[0] != "oom_kill") {
+			continue
+		}
+		v, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse memory oom control %q, err: %s", rawOOMControl, err)
+		}
+		if fields[0] == "under_oom" {
+			oomControl.UnderOOM = v != 0
+		} else {
+			oomControl.OOMKill = v
+			isOOMKillFound = true
+		}
+	}
+	if !isOOMKillFound {
+		return nil, fmt.Errorf("oom_kill is not found in memory oom control %q", rawOOMControl)
+	}
+	return oomControl, nil
+}
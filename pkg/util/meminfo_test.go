@@ -201,3 +201,51 @@ func TestGetContainerMemStatUsageBytes(t *testing.T) {
 	_, err = GetContainerMemStatUsageBytes(tempDir, container)
 	assert.NotNil(t, err)
 }
+
+func TestGetCgroupMemOOMControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *CgroupMemOOMControl
+		wantErr bool
+	}{
+		{
+			name:    "parse memory oom control",
+			content: "oom_kill_disable 0\nunder_oom 0\noom_kill 2\n",
+			want:    &CgroupMemOOMControl{OOMKill: 2},
+		},
+		{
+			name:    "parse memory oom control under oom",
+			content: "oom_kill_disable 1\nunder_oom 1\noom_kill 5\n",
+			want:    &CgroupMemOOMControl{UnderOOM: true, OOMKill: 5},
+		},
+		{
+			name:    "oom kill not supported",
+			content: "oom_kill_disable 0\nunder_oom 0\n",
+			wantErr: true,
+		},
+		{
+			name:    "illegal format",
+			content: "oom_kill_disable 0\nunder_oom 0\noom_kill x\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			cgroupDir := GetKubeQosRelativePath(corev1.PodQOSBestEffort)
+			helper.WriteCgroupFileContents(cgroupDir, system.MemOomControl, tt.content)
+			got, err := GetCgroupMemOOMControl(cgroupDir)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("file not exist", func(t *testing.T) {
+		helper := system.NewFileTestUtil(t)
+		defer helper.Cleanup()
+		_, err := GetCgroupMemOOMControl(GetKubeQosRelativePath(corev1.PodQOSBestEffort))
+		assert.Error(t, err)
+	})
+}
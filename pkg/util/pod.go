@@ -101,6 +101,20 @@ func GetKubeQosClass(pod *corev1.Pod) corev1.PodQOSClass {
 	return qosClass
 }
 
+// GetPodContainers returns the containers of the pod, appended with the ephemeral containers if includeEphemeral is
+// true. The init containers are not included since they have completed when the pod is running.
+func GetPodContainers(pod *corev1.Pod, includeEphemeral bool) []corev1.Container {
+	if !includeEphemeral || len(pod.Spec.EphemeralContainers) == 0 {
+		return pod.Spec.Containers
+	}
+	containers := make([]corev1.Container, 0, len(pod.Spec.Containers)+len(pod.Spec.EphemeralContainers))
+	containers = append(containers, pod.Spec.Containers...)
+	for i := range pod.Spec.EphemeralContainers {
+		containers = append(containers, corev1.Container(pod.Spec.EphemeralContainers[i].EphemeralContainerCommon))
+	}
+	return containers
+}
+
 func GetPodBEMilliCPURequest(pod *corev1.Pod) int64 {
 	podCPUMilliReq := int64(0)
 	// TODO: count init containers and pod overhead
@@ -178,6 +178,35 @@ func Test_GetPodRequest(t *testing.T) {
 	}
 }
 
+func Test_GetPodContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "main"}, {Name: "sidecar"}},
+			EphemeralContainers: []corev1.EphemeralContainer{
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"}},
+			},
+		},
+	}
+	getNames := func(containers []corev1.Container) []string {
+		var names []string
+		for _, container := range containers {
+			names = append(names, container.Name)
+		}
+		return names
+	}
+	if got := getNames(GetPodContainers(pod, false)); !reflect.DeepEqual(got, []string{"main", "sidecar"}) {
+		t.Errorf("should get the containers, got %v", got)
+	}
+	got := GetPodContainers(pod, true)
+	if names := getNames(got); !reflect.DeepEqual(names, []string{"main", "sidecar", "debugger"}) {
+		t.Errorf("should get the containers and the ephemeral containers, got %v", names)
+	}
+	if got[2].Image != "busybox" {
+		t.Errorf("should keep the spec of the ephemeral container, got %v", got[2])
+	}
+}
+
 func Test_GetRootCgroupCurCPUSet(t *testing.T) {
 	// prepare testing tmp files
 	var cgroupRootDir string
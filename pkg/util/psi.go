@@ -0,0 +1,82 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+const (
+	ProcCPUPressureFileName = "pressure/cpu"
+)
+
+// PSIStats is the "some" line of the pressure stall information, where the averages are the percentages of the time
+// that at least one task is stalled on the resource in the last 10, 60 and 300 seconds.
+type PSIStats struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	// Total is the accumulated stall time in microseconds
+	Total uint64
+}
+
+func readPSISome(psiPath string) (*PSIStats, error) {
+	// format: some avg10=0.00 avg60=0.00 avg300=0.00 total=0\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0
+	rawPSI, err := ioutil.ReadFile(psiPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(rawPSI), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 5 || fields[0] != "some" {
+			continue
+		}
+		stats := &PSIStats{}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("%s is illegally formatted, line %s", psiPath, line)
+			}
+			switch kv[0] {
+			case "avg10":
+				stats.Avg10, err = strconv.ParseFloat(kv[1], 64)
+			case "avg60":
+				stats.Avg60, err = strconv.ParseFloat(kv[1], 64)
+			case "avg300":
+				stats.Avg300, err = strconv.ParseFloat(kv[1], 64)
+			case "total":
+				stats.Total, err = strconv.ParseUint(kv[1], 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse psi %s, err: %s", line, err)
+			}
+		}
+		return stats, nil
+	}
+	return nil, fmt.Errorf("%s is illegally formatted", psiPath)
+}
+
+// GetNodeCPUPressure returns the node's cpu pressure stall information, which requires the kernel with PSI enabled
+func GetNodeCPUPressure() (*PSIStats, error) {
+	return readPSISome(filepath.Join(system.Conf.ProcRootDir, ProcCPUPressureFileName))
+}
@@ -0,0 +1,74 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/koordinator/pkg/util/system"
+)
+
+func Test_GetNodeCPUPressure(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *PSIStats
+		wantErr bool
+	}{
+		{
+			name: "parse some and full lines",
+			content: "some avg10=12.50 avg60=8.00 avg300=2.25 total=123456\n" +
+				"full avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+			want: &PSIStats{Avg10: 12.5, Avg60: 8, Avg300: 2.25, Total: 123456},
+		},
+		{
+			name:    "parse the some line only of the old kernels",
+			content: "some avg10=0.00 avg60=0.10 avg300=0.00 total=100\n",
+			want:    &PSIStats{Avg10: 0, Avg60: 0.1, Avg300: 0, Total: 100},
+		},
+		{
+			name:    "missing some line",
+			content: "full avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+			wantErr: true,
+		},
+		{
+			name:    "illegal value",
+			content: "some avg10=abc avg60=0.00 avg300=0.00 total=0\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.WriteProcSubFileContents(ProcCPUPressureFileName, tt.content)
+
+			got, err := GetNodeCPUPressure()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("psi not supported", func(t *testing.T) {
+		helper := system.NewFileTestUtil(t)
+		defer helper.Cleanup()
+		_, err := GetNodeCPUPressure()
+		assert.Error(t, err)
+	})
+}
@@ -115,6 +115,12 @@ func GetCgroupFilePath(cgroupTaskDir string, file CgroupFile) string {
 	return path.Join(Conf.CgroupRootDir, file.Subfs, cgroupTaskDir, file.ResourceFileName)
 }
 
+// IsMemorySwapAccountingEnabled checks if the memory swap accounting is available for the cgroup, i.e. the kernel
+// exposes `memory.swap.max` in the cgroup directory.
+func IsMemorySwapAccountingEnabled(cgroupTaskDir string) bool {
+	return FileExists(GetCgroupFilePath(cgroupTaskDir, MemSwapMax))
+}
+
 func GetCgroupCurTasks(cgroupPath string) ([]int, error) {
 	var tasks []int
 	rawContent, err := ioutil.ReadFile(cgroupPath)
@@ -52,8 +52,10 @@ const (
 	MemMinFileName              = "memory.min"
 	MemLowFileName              = "memory.low"
 	MemHighFileName             = "memory.high"
+	MemSwapMaxFileName          = "memory.swap.max"
 	MemoryLimitFileName         = "memory.limit_in_bytes"
 	MemStatFileName             = "memory.stat"
+	MemOomControlFileName       = "memory.oom_control"
 )
 
 var (
@@ -68,6 +70,7 @@ var (
 	MemMinValidator                      = &RangeValidator{name: MemMinFileName, min: 0, max: math.MaxInt64}
 	MemLowValidator                      = &RangeValidator{name: MemLowFileName, min: 0, max: math.MaxInt64}
 	MemHighValidator                     = &RangeValidator{name: MemHighFileName, min: 0, max: math.MaxInt64} // write value(>node.total) -> read "max"
+	MemSwapMaxValidator                  = &RangeValidator{name: MemSwapMaxFileName, min: 0, max: math.MaxInt64}
 )
 
 var (
@@ -83,6 +86,7 @@ var (
 	CpuacctStat = CgroupFile{ResourceFileName: CpuacctStatFileName, Subfs: CgroupCPUacctDir, IsAnolisOS: false}
 
 	MemStat             = CgroupFile{ResourceFileName: MemStatFileName, Subfs: CgroupMemDir, IsAnolisOS: false}
+	MemOomControl       = CgroupFile{ResourceFileName: MemOomControlFileName, Subfs: CgroupMemDir, IsAnolisOS: false}
 	MemoryLimit         = CgroupFile{ResourceFileName: MemoryLimitFileName, Subfs: CgroupMemDir, IsAnolisOS: false}
 	MemWmarkRatio       = CgroupFile{ResourceFileName: MemWmarkRatioFileName, Subfs: CgroupMemDir, IsAnolisOS: true, Validator: MemWmarkRatioValidator}
 	MemPriority         = CgroupFile{ResourceFileName: MemPriorityFileName, Subfs: CgroupMemDir, IsAnolisOS: true, Validator: MemPriorityValidator}
@@ -93,6 +97,7 @@ var (
 	MemMin              = CgroupFile{ResourceFileName: MemMinFileName, Subfs: CgroupMemDir, IsAnolisOS: true, Validator: MemMinValidator}
 	MemLow              = CgroupFile{ResourceFileName: MemLowFileName, Subfs: CgroupMemDir, IsAnolisOS: true, Validator: MemLowValidator}
 	MemHigh             = CgroupFile{ResourceFileName: MemHighFileName, Subfs: CgroupMemDir, IsAnolisOS: true, Validator: MemHighValidator}
+	MemSwapMax          = CgroupFile{ResourceFileName: MemSwapMaxFileName, Subfs: CgroupMemDir, IsAnolisOS: false, Validator: MemSwapMaxValidator}
 )
 
 type CgroupFile struct {
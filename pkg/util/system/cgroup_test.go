@@ -176,3 +176,14 @@ func TestCalcCPUThrottledRatio(t *testing.T) {
 		})
 	}
 }
+
+func TestIsMemorySwapAccountingEnabled(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	testMemDir := "pod0"
+
+	assert.False(t, IsMemorySwapAccountingEnabled(testMemDir))
+
+	helper.WriteCgroupFileContents(testMemDir, MemSwapMax, "max")
+	assert.True(t, IsMemorySwapAccountingEnabled(testMemDir))
+}
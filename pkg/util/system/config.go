@@ -30,13 +30,16 @@ var Conf = NewDsModeConfig()
 var AgentMode = DS_MODE
 
 type Config struct {
-	CgroupRootDir    string
-	CgroupKubePath   string
-	SysRootDir       string
-	SysFSRootDir     string
-	ProcRootDir      string
-	VarRunRootDir    string
-	NodeNameOverride string
+	CgroupRootDir        string
+	CgroupKubePath       string
+	SysRootDir           string
+	SysFSRootDir         string
+	ResctrlRootDir       string
+	ProcRootDir          string
+	VarRunRootDir        string
+	VarLibKubeletRootDir string
+	VarLogRootDir        string
+	NodeNameOverride     string
 
 	ContainerdEndPoint string
 	DockerEndPoint     string
@@ -44,12 +47,14 @@ type Config struct {
 
 func NewHostModeConfig() *Config {
 	return &Config{
-		CgroupKubePath: "kubepods/",
-		CgroupRootDir:  "/sys/fs/cgroup/",
-		ProcRootDir:    "/proc/",
-		SysRootDir:     "/sys/",
-		SysFSRootDir:   "/sys/fs/",
-		VarRunRootDir:  "/var/run/",
+		CgroupKubePath:       "kubepods/",
+		CgroupRootDir:        "/sys/fs/cgroup/",
+		ProcRootDir:          "/proc/",
+		SysRootDir:           "/sys/",
+		SysFSRootDir:         "/sys/fs/",
+		VarRunRootDir:        "/var/run/",
+		VarLibKubeletRootDir: "/var/lib/kubelet/",
+		VarLogRootDir:        "/var/log/",
 	}
 }
 
@@ -58,10 +63,12 @@ func NewDsModeConfig() *Config {
 		CgroupKubePath: "kubepods/",
 		CgroupRootDir:  "/host-cgroup/",
 		// some dirs are not covered by ns, or unused with `hostPID` is on
-		ProcRootDir:   "/proc/",
-		SysRootDir:    "/host-sys/",
-		SysFSRootDir:  "/host-sys-fs/",
-		VarRunRootDir: "/host-var-run/",
+		ProcRootDir:          "/proc/",
+		SysRootDir:           "/host-sys/",
+		SysFSRootDir:         "/host-sys-fs/",
+		VarRunRootDir:        "/host-var-run/",
+		VarLibKubeletRootDir: "/host-var-lib-kubelet/",
+		VarLogRootDir:        "/host-var-log/",
 	}
 }
 
@@ -85,8 +92,11 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.CgroupRootDir, "CgroupRootDir", c.CgroupRootDir, "Cgroup root dir")
 	fs.StringVar(&c.SysFSRootDir, "SysRootDir", c.SysFSRootDir, "host /sys dir in container")
 	fs.StringVar(&c.SysFSRootDir, "SysFSRootDir", c.SysFSRootDir, "host /sys/fs dir in container, used by resctrl fs")
+	fs.StringVar(&c.ResctrlRootDir, "ResctrlRootDir", c.ResctrlRootDir, "host resctrl fs dir in container, default to resctrl under SysFSRootDir if empty")
 	fs.StringVar(&c.ProcRootDir, "ProcRootDir", c.ProcRootDir, "host /proc dir in container")
 	fs.StringVar(&c.VarRunRootDir, "VarRunRootDir", c.VarRunRootDir, "host /var/run dir in container")
+	fs.StringVar(&c.VarLibKubeletRootDir, "VarLibKubeletRootDir", c.VarLibKubeletRootDir, "host /var/lib/kubelet dir in container")
+	fs.StringVar(&c.VarLogRootDir, "VarLogRootDir", c.VarLogRootDir, "host /var/log dir in container")
 
 	fs.StringVar(&c.CgroupKubePath, "CgroupKubeDir", c.CgroupKubePath, "Cgroup kube dir")
 	fs.StringVar(&c.NodeNameOverride, "node-name-override", c.NodeNameOverride, "If non-empty, will use this string as identification instead of the actual machine name. ")
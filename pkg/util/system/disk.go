@@ -0,0 +1,103 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const (
+	kubeletPodsDirName = "pods"
+	// kubeletEmptyDirVolumesDirName is the dir of the emptyDir volumes under the kubelet pod dir
+	kubeletEmptyDirVolumesDirName = "volumes/kubernetes.io~empty-dir"
+	podLogsDirName                = "pods"
+)
+
+// GetKubeletPodDir returns the kubelet dir of the pod, e.g. /var/lib/kubelet/pods/{podUID}.
+func GetKubeletPodDir(podUID string) string {
+	return filepath.Join(Conf.VarLibKubeletRootDir, kubeletPodsDirName, podUID)
+}
+
+// GetKubeletPodEmptyDirVolumesDir returns the dir of the emptyDir volumes of the pod,
+// e.g. /var/lib/kubelet/pods/{podUID}/volumes/kubernetes.io~empty-dir.
+func GetKubeletPodEmptyDirVolumesDir(podUID string) string {
+	return filepath.Join(GetKubeletPodDir(podUID), kubeletEmptyDirVolumesDirName)
+}
+
+// GetPodLogDir returns the log dir of the pod, e.g. /var/log/pods/{namespace}_{name}_{podUID}.
+func GetPodLogDir(namespace, name, podUID string) string {
+	return filepath.Join(Conf.VarLogRootDir, podLogsDirName, fmt.Sprintf("%s_%s_%s", namespace, name, podUID))
+}
+
+// GetPodDiskUsedBytes returns the disk usage of the emptyDir volumes and the logs of the pod, where the dirs not
+// existing are taken as empty.
+func GetPodDiskUsedBytes(namespace, name, podUID string) (int64, error) {
+	var usedBytes int64
+	for _, dir := range []string{GetKubeletPodEmptyDirVolumesDir(podUID), GetPodLogDir(namespace, name, podUID)} {
+		dirUsedBytes, err := GetDirUsedBytes(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		usedBytes += dirUsedBytes
+	}
+	return usedBytes, nil
+}
+
+// GetDirUsedBytes returns the total size of the regular files under the dir. The walk does not cross the mount points,
+// e.g. the PV or the memory-backed emptyDir mounted under the dir.
+func GetDirUsedBytes(dir string) (int64, error) {
+	rootInfo, err := os.Lstat(dir)
+	if err != nil {
+		return 0, err
+	}
+	rootDev, hasDev := getDeviceID(rootInfo)
+	var usedBytes int64
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// files can be removed during the walk
+			if os.IsNotExist(err) && path != dir {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() && path != dir && hasDev {
+			if dev, ok := getDeviceID(info); ok && dev != rootDev {
+				return filepath.SkipDir
+			}
+		}
+		if info.Mode().IsRegular() {
+			usedBytes += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return usedBytes, nil
+}
+
+// getDeviceID returns the id of the device containing the file, which differs across the mount points
+var getDeviceID = func(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
@@ -0,0 +1,92 @@
+/*
+ Copyright 2022 The Koordinator Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetKubeletPodDir(t *testing.T) {
+	oldConf := *Conf
+	defer func() { Conf = &oldConf }()
+	Conf.VarLibKubeletRootDir = "/var/lib/kubelet/"
+	assert.Equal(t, "/var/lib/kubelet/pods/test-pod-uid", GetKubeletPodDir("test-pod-uid"))
+}
+
+func Test_GetDirUsedBytes(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	helper.CreateFile("pods/test-pod/volumes/data")
+	helper.WriteFileContents("pods/test-pod/volumes/data", "0123456789")
+	helper.WriteFileContents("pods/test-pod/etc-hosts", "01234")
+	helper.MkDirAll("pods/test-pod/containers")
+
+	got, err := GetDirUsedBytes(filepath.Join(helper.TempDir, "pods/test-pod"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), got)
+
+	_, err = GetDirUsedBytes(filepath.Join(helper.TempDir, "pods/not-exist"))
+	assert.Error(t, err)
+
+	// skip the dirs of the other devices, e.g. the mounted PV
+	helper.CreateFile("pods/test-pod/volumes/pv/data")
+	helper.WriteFileContents("pods/test-pod/volumes/pv/data", "0123456789")
+	oldGetDeviceID := getDeviceID
+	defer func() { getDeviceID = oldGetDeviceID }()
+	getDeviceID = func(info os.FileInfo) (uint64, bool) {
+		if info.Name() == "pv" {
+			return 2, true
+		}
+		return 1, true
+	}
+	got, err = GetDirUsedBytes(filepath.Join(helper.TempDir, "pods/test-pod"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), got)
+}
+
+func Test_GetPodDiskUsedBytes(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldConf := *Conf
+	defer func() { Conf = &oldConf }()
+	Conf.VarLibKubeletRootDir = filepath.Join(helper.TempDir, "kubelet")
+	Conf.VarLogRootDir = filepath.Join(helper.TempDir, "log")
+
+	// only the emptyDir volumes and the logs are counted
+	helper.CreateFile("kubelet/pods/test-pod-uid/volumes/kubernetes.io~empty-dir/cache/data")
+	helper.WriteFileContents("kubelet/pods/test-pod-uid/volumes/kubernetes.io~empty-dir/cache/data", "0123456789")
+	helper.CreateFile("kubelet/pods/test-pod-uid/volumes/kubernetes.io~nfs/nfs/data")
+	helper.WriteFileContents("kubelet/pods/test-pod-uid/volumes/kubernetes.io~nfs/nfs/data", "0123456789")
+	helper.CreateFile("kubelet/pods/test-pod-uid/etc-hosts")
+	helper.WriteFileContents("kubelet/pods/test-pod-uid/etc-hosts", "01234")
+	helper.CreateFile("log/pods/default_test-pod_test-pod-uid/main/0.log")
+	helper.WriteFileContents("log/pods/default_test-pod_test-pod-uid/main/0.log", "012")
+
+	got, err := GetPodDiskUsedBytes("default", "test-pod", "test-pod-uid")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(13), got)
+
+	// the dirs not existing are taken as empty
+	got, err = GetPodDiskUsedBytes("default", "not-exist", "not-exist-uid")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), got)
+}
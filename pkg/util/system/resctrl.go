@@ -32,14 +32,30 @@ const (
 	ResctrlDir string = "resctrl/"
 	RdtInfoDir string = "info"
 	L3CatDir   string = "L3"
+	L3MonDir   string = "L3_MON"
+
+	// ResctrlMonGroupsDir is the dir of the monitoring groups under a resctrl group
+	ResctrlMonGroupsDir string = "mon_groups"
+	// ResctrlMonDataDir is the dir of the monitoring data of a resctrl group, having a sub dir for each l3 domain
+	ResctrlMonDataDir     string = "mon_data"
+	ResctrlMonL3DirPrefix string = "mon_L3_"
+	LLCOccupancyFileName  string = "llc_occupancy"
+	MBMTotalBytesFileName string = "mbm_total_bytes"
+	MBMLocalBytesFileName string = "mbm_local_bytes"
 
 	SchemataFileName      string = "schemata"
 	CbmMaskFileName       string = "cbm_mask"
+	MinCbmBitsFileName    string = "min_cbm_bits"
+	NumClosidsFileName    string = "num_closids"
 	ResctrlTaskFileName   string = "tasks"
 	CPUInfoFileName       string = "cpuinfo"
 	KernelCmdlineFileName string = "cmdline"
+	ProcMountsFileName    string = "mounts"
 
 	ResctrlName string = "resctrl"
+
+	// ResctrlMBpsMountOption is the resctrl mount option which makes the MB schemata specified in MBps
+	ResctrlMBpsMountOption string = "mba_MBps"
 )
 
 var (
@@ -88,32 +104,119 @@ func IsSupportResctrl() (bool, error) {
 	return isSupportResctrl, nil
 }
 
+// GetResctrlSubsystemDirPath returns the mount point of the resctrl fs, which is ResctrlRootDir if configured.
 // @return /sys/fs/resctrl
 func GetResctrlSubsystemDirPath() string {
+	if Conf.ResctrlRootDir != "" {
+		return Conf.ResctrlRootDir
+	}
 	return filepath.Join(Conf.SysFSRootDir, ResctrlDir)
 }
 
 // @groupPath BE
 // @return /sys/fs/resctrl/BE
 func GetResctrlGroupRootDirPath(groupPath string) string {
-	return filepath.Join(Conf.SysFSRootDir, ResctrlDir, groupPath)
+	return filepath.Join(GetResctrlSubsystemDirPath(), groupPath)
 }
 
 // @return /sys/fs/resctrl/info/L3/cbm_mask
 func GetResctrlL3CbmFilePath() string {
-	return filepath.Join(Conf.SysFSRootDir, ResctrlDir, RdtInfoDir, L3CatDir, CbmMaskFileName)
+	return filepath.Join(GetResctrlSubsystemDirPath(), RdtInfoDir, L3CatDir, CbmMaskFileName)
+}
+
+// @return /sys/fs/resctrl/info/L3/min_cbm_bits
+func GetResctrlL3MinCbmBitsFilePath() string {
+	return filepath.Join(GetResctrlSubsystemDirPath(), RdtInfoDir, L3CatDir, MinCbmBitsFileName)
 }
 
 // @groupPath BE
 // @return /sys/fs/resctrl/BE/schemata
 func GetResctrlSchemataFilePath(groupPath string) string {
-	return filepath.Join(Conf.SysFSRootDir, ResctrlDir, groupPath, SchemataFileName)
+	return filepath.Join(GetResctrlSubsystemDirPath(), groupPath, SchemataFileName)
 }
 
 // @groupPath BE
 // @return /sys/fs/resctrl/BE/tasks
 func GetResctrlTasksFilePath(groupPath string) string {
-	return filepath.Join(Conf.SysFSRootDir, ResctrlDir, groupPath, ResctrlTaskFileName)
+	return filepath.Join(GetResctrlSubsystemDirPath(), groupPath, ResctrlTaskFileName)
+}
+
+// @return /sys/fs/resctrl/info/L3_MON
+func GetResctrlL3MonInfoDirPath() string {
+	return filepath.Join(GetResctrlSubsystemDirPath(), RdtInfoDir, L3MonDir)
+}
+
+// @groupPath BE, monGroup BE
+// @return BE/mon_groups/BE
+func GetResctrlMonGroupPath(groupPath, monGroup string) string {
+	return filepath.Join(groupPath, ResctrlMonGroupsDir, monGroup)
+}
+
+// @groupPath BE
+// @return /sys/fs/resctrl/BE/mon_data
+func GetResctrlMonDataDirPath(groupPath string) string {
+	return filepath.Join(GetResctrlSubsystemDirPath(), groupPath, ResctrlMonDataDir)
+}
+
+// ResctrlMonData is the monitoring data of a resctrl group summed over all l3 domains.
+type ResctrlMonData struct {
+	// LLCOccupancy is the bytes of the last level cache occupied by the tasks (CMT)
+	LLCOccupancy uint64
+	// MBMTotalBytes and MBMLocalBytes are the cumulative bytes of the memory bandwidth (MBM), which are zero if
+	// the kernel does not support
+	MBMTotalBytes uint64
+	MBMLocalBytes uint64
+}
+
+// IsResctrlMonSupported checks if the resctrl monitoring of l3 is enabled
+func IsResctrlMonSupported() bool {
+	_, err := os.Stat(GetResctrlL3MonInfoDirPath())
+	return err == nil
+}
+
+// ReadResctrlMonData reads and returns the monitoring data of the given resctrl group or monitoring group
+func ReadResctrlMonData(groupPath string) (*ResctrlMonData, error) {
+	monDataDir := GetResctrlMonDataDirPath(groupPath)
+	domainDirs, err := ioutil.ReadDir(monDataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	monData := &ResctrlMonData{}
+	domains := 0
+	for _, domainDir := range domainDirs {
+		if !domainDir.IsDir() || !strings.HasPrefix(domainDir.Name(), ResctrlMonL3DirPrefix) {
+			continue
+		}
+		domainPath := filepath.Join(monDataDir, domainDir.Name())
+		for fileName, value := range map[string]*uint64{
+			LLCOccupancyFileName:  &monData.LLCOccupancy,
+			MBMTotalBytesFileName: &monData.MBMTotalBytes,
+			MBMLocalBytesFileName: &monData.MBMLocalBytes,
+		} {
+			v, err := readResctrlMonFile(filepath.Join(domainPath, fileName))
+			if err != nil {
+				return nil, err
+			}
+			*value += v
+		}
+		domains++
+	}
+	if domains <= 0 {
+		return nil, fmt.Errorf("no l3 domain found in %s", monDataDir)
+	}
+	return monData, nil
+}
+
+// readResctrlMonFile reads a monitoring event file, and returns zero if the event is not supported
+func readResctrlMonFile(filePath string) (uint64, error) {
+	rawContent, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(rawContent)), 10, 64)
 }
 
 // ReadCatL3Cbm reads and returns the value of cat l3 cbm_mask
@@ -126,6 +229,74 @@ func ReadCatL3CbmString() (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// ReadCatL3MinCbmBits reads and returns the minimum number of consecutive bits required in a cat l3 cbm
+func ReadCatL3MinCbmBits() (uint, error) {
+	out, err := ioutil.ReadFile(GetResctrlL3MinCbmBitsFilePath())
+	if err != nil {
+		return 0, err
+	}
+	minBits, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(minBits), nil
+}
+
+// ReadResctrlNumClosids reads and returns the number of CLOSIDs of the platform, i.e. the minimum num_closids of the
+// allocation resources like L3 and MB, which limits the resctrl groups including the root group
+func ReadResctrlNumClosids() (int, error) {
+	infoDir := filepath.Join(GetResctrlSubsystemDirPath(), RdtInfoDir)
+	resourceDirs, err := ioutil.ReadDir(infoDir)
+	if err != nil {
+		return 0, err
+	}
+	numClosids := -1
+	for _, resourceDir := range resourceDirs {
+		// the monitoring resources like L3_MON have no CLOSIDs
+		if !resourceDir.IsDir() || strings.HasSuffix(resourceDir.Name(), "_MON") {
+			continue
+		}
+		out, err := ioutil.ReadFile(filepath.Join(infoDir, resourceDir.Name(), NumClosidsFileName))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(string(out)))
+		if err != nil {
+			return 0, err
+		}
+		if numClosids < 0 || num < numClosids {
+			numClosids = num
+		}
+	}
+	if numClosids < 0 {
+		return 0, fmt.Errorf("no %s found in %s", NumClosidsFileName, infoDir)
+	}
+	return numClosids, nil
+}
+
+// CountResctrlCtrlGroups returns the number of the resctrl control groups, each of which holds a CLOSID, including
+// the root group and the groups created by any agent
+func CountResctrlCtrlGroups() (int, error) {
+	groupDirs, err := ioutil.ReadDir(GetResctrlSubsystemDirPath())
+	if err != nil {
+		return 0, err
+	}
+	count := 1 // the root group
+	for _, groupDir := range groupDirs {
+		if !groupDir.IsDir() {
+			continue
+		}
+		switch groupDir.Name() {
+		case RdtInfoDir, ResctrlMonGroupsDir, ResctrlMonDataDir:
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
 // ReadResctrlTasksMap reads and returns the map of given resctrl group's task ids
 func ReadResctrlTasksMap(groupPath string) (map[int]struct{}, error) {
 	tasksPath := GetResctrlTasksFilePath(groupPath)
@@ -151,16 +322,19 @@ func ReadResctrlTasksMap(groupPath string) (map[int]struct{}, error) {
 	return tasksMap, nil
 }
 
-// CheckAndTryEnableResctrlCat checks if resctrl and l3_cat are enabled; if not, try to enable the features by mount
-// resctrl subsystem; See MountResctrlSubsystem() for the detail.
+// CheckAndTryEnableResctrlCat checks if resctrl and l3_cat are enabled; if not and mount is true, try to enable the
+// features by mount resctrl subsystem; See MountResctrlSubsystem() for the detail.
 // It returns whether the resctrl cat is enabled, and the error if failed to enable or to check resctrl interfaces
-func CheckAndTryEnableResctrlCat() error {
+func CheckAndTryEnableResctrlCat(mount bool) error {
 	// resctrl cat is correctly enabled: l3_cbm path exists
 	l3CbmFilePath := GetResctrlL3CbmFilePath()
 	_, err := os.Stat(l3CbmFilePath)
 	if err == nil {
 		return nil
 	}
+	if !mount {
+		return fmt.Errorf("resctrl cat is not enabled at %s, err: %s", GetResctrlSubsystemDirPath(), err)
+	}
 	newMount, err := MountResctrlSubsystem()
 	if err != nil {
 		return err
@@ -176,3 +350,29 @@ func CheckAndTryEnableResctrlCat() error {
 	}
 	return nil
 }
+
+// IsResctrlMBAMBpsEnabled checks if the resctrl subsystem is mounted with the `mba_MBps` option, in which case the MB
+// schemata are specified in MBps instead of percentage
+func IsResctrlMBAMBpsEnabled() (bool, error) {
+	mountsPath := filepath.Join(Conf.ProcRootDir, ProcMountsFileName)
+	rawContent, err := ioutil.ReadFile(mountsPath)
+	if err != nil {
+		return false, err
+	}
+
+	// e.g. `resctrl /sys/fs/resctrl resctrl rw,relatime,mba_MBps 0 0`
+	lines := strings.Split(string(rawContent), "\n")
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[2] != ResctrlName {
+			continue
+		}
+		for _, option := range strings.Split(fields[3], ",") {
+			if option == ResctrlMBpsMountOption {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("resctrl is not mounted in %s", mountsPath)
+}
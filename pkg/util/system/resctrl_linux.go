@@ -28,7 +28,7 @@ import (
 	"syscall"
 )
 
-// MountResctrlSubsystem mounts resctrl fs under the sysFSRoot to enable the kernel feature on supported environment
+// MountResctrlSubsystem mounts resctrl fs at GetResctrlSubsystemDirPath() to enable the kernel feature on supported environment
 // NOTE: Linux kernel (>= 4.10), Intel cpu and bare-mental host are required; Also, Intel RDT
 // features should be enabled in kernel configurations and kernel commandline.
 // For more info, please see https://github.com/intel/intel-cmt-cat/wiki/resctrl
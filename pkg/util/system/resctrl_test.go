@@ -103,8 +103,10 @@ func Test_ReadResctrlTasksMap(t *testing.T) {
 
 func Test_CheckAndTryEnableResctrlCat(t *testing.T) {
 	type fields struct {
-		cbmStr      string
-		invalidPath bool
+		cbmStr         string
+		invalidPath    bool
+		resctrlRootDir bool
+		mount          bool
 	}
 	tests := []struct {
 		name    string
@@ -113,14 +115,29 @@ func Test_CheckAndTryEnableResctrlCat(t *testing.T) {
 	}{
 		{
 			name:    "return disabled for a invalid path",
+			fields:  fields{invalidPath: true, mount: true},
+			wantErr: true,
+		},
+		{
+			name:    "return disabled without mounting for a invalid path",
 			fields:  fields{invalidPath: true},
 			wantErr: true,
 		},
 		{
 			name:    "return enabled for a valid l3_cbm",
-			fields:  fields{cbmStr: "3f"},
+			fields:  fields{cbmStr: "3f", mount: true},
+			wantErr: false,
+		},
+		{
+			name:    "return enabled for a valid l3_cbm under the configured resctrl root dir",
+			fields:  fields{cbmStr: "3f", resctrlRootDir: true},
 			wantErr: false,
 		},
+		{
+			name:    "return disabled for a invalid configured resctrl root dir",
+			fields:  fields{cbmStr: "3f", resctrlRootDir: true, invalidPath: true},
+			wantErr: true,
+		},
 		// TODO: add mount case
 	}
 	for _, tt := range tests {
@@ -140,11 +157,19 @@ func Test_CheckAndTryEnableResctrlCat(t *testing.T) {
 			Conf = &Config{
 				SysFSRootDir: sysFSRootDir,
 			}
+			if tt.fields.resctrlRootDir {
+				// the resctrl root dir takes precedence over the sys fs root dir
+				Conf.ResctrlRootDir = resctrlDir
+				Conf.SysFSRootDir = "invalidPath"
+			}
 			if tt.fields.invalidPath {
 				Conf.SysFSRootDir = "invalidPath"
+				if tt.fields.resctrlRootDir {
+					Conf.ResctrlRootDir = "invalidPath"
+				}
 			}
 
-			gotErr := CheckAndTryEnableResctrlCat()
+			gotErr := CheckAndTryEnableResctrlCat(tt.fields.mount)
 
 			assert.Equal(t, tt.wantErr, gotErr != nil)
 		})
@@ -181,3 +206,269 @@ func Test_MountResctrlSubsystem(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func Test_IsResctrlMBAMBpsEnabled(t *testing.T) {
+	type fields struct {
+		mountsStr   string
+		invalidPath bool
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "throw an error for invalid path",
+			fields:  fields{invalidPath: true},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "throw an error when resctrl not mounted",
+			fields: fields{
+				mountsStr: "proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0\n",
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "resctrl mounted in percent mode",
+			fields: fields{
+				mountsStr: "proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0\n" +
+					"resctrl /sys/fs/resctrl resctrl rw,relatime 0 0\n",
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "resctrl mounted in MBps mode",
+			fields: fields{
+				mountsStr: "proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0\n" +
+					"resctrl /sys/fs/resctrl resctrl rw,relatime,mba_MBps 0 0\n",
+			},
+			want:    true,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := NewFileTestUtil(t)
+			defer helper.Cleanup()
+
+			if tt.fields.invalidPath {
+				Conf.ProcRootDir = "invalidPath"
+			} else {
+				helper.WriteProcSubFileContents(ProcMountsFileName, tt.fields.mountsStr)
+			}
+
+			got, err := IsResctrlMBAMBpsEnabled()
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_ReadCatL3MinCbmBits(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    uint
+		wantErr bool
+	}{
+		{
+			name:    "throw an error when min_cbm_bits does not exist",
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:    "throw an error for invalid content",
+			content: "invalid\n",
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:    "read min cbm bits correctly",
+			content: "2\n",
+			want:    2,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sysFSRootDir, _ := ioutil.TempDir("", "ReadCatL3MinCbmBits")
+			defer os.RemoveAll(sysFSRootDir)
+			Conf = &Config{
+				SysFSRootDir: sysFSRootDir,
+			}
+			if len(tt.content) > 0 {
+				filePath := GetResctrlL3MinCbmBitsFilePath()
+				assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0700))
+				assert.NoError(t, ioutil.WriteFile(filePath, []byte(tt.content), 0666))
+			}
+
+			got, err := ReadCatL3MinCbmBits()
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_ReadResctrlMonData(t *testing.T) {
+	type fields struct {
+		// monFiles maps the file path relative to the mon_data dir to the file content
+		monFiles    map[string]string
+		invalidPath bool
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *ResctrlMonData
+		wantErr bool
+	}{
+		{
+			name:    "throw an error for invalid path",
+			fields:  fields{invalidPath: true},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "throw an error for no l3 domain",
+			fields:  fields{monFiles: map[string]string{}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "sum the data of all l3 domains",
+			fields: fields{monFiles: map[string]string{
+				"mon_L3_00/llc_occupancy":   "1048576\n",
+				"mon_L3_00/mbm_total_bytes": "4000\n",
+				"mon_L3_00/mbm_local_bytes": "3000\n",
+				"mon_L3_01/llc_occupancy":   "2097152\n",
+				"mon_L3_01/mbm_total_bytes": "6000\n",
+				"mon_L3_01/mbm_local_bytes": "1000\n",
+			}},
+			want: &ResctrlMonData{
+				LLCOccupancy:  3145728,
+				MBMTotalBytes: 10000,
+				MBMLocalBytes: 4000,
+			},
+			wantErr: false,
+		},
+		{
+			name: "mbm not supported",
+			fields: fields{monFiles: map[string]string{
+				"mon_L3_00/llc_occupancy": "1048576\n",
+			}},
+			want: &ResctrlMonData{
+				LLCOccupancy: 1048576,
+			},
+			wantErr: false,
+		},
+		{
+			name: "parse error for invalid data",
+			fields: fields{monFiles: map[string]string{
+				"mon_L3_00/llc_occupancy": "Unavailable\n",
+			}},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sysFSRootDir string
+			sysFSRootDir, _ = ioutil.TempDir("", "ReadResctrlMonData")
+			defer os.RemoveAll(sysFSRootDir)
+			monDataDir := filepath.Join(sysFSRootDir, ResctrlDir, "BE", ResctrlMonDataDir)
+			err := os.MkdirAll(monDataDir, 0700)
+			assert.NoError(t, err)
+			for filePath, content := range tt.fields.monFiles {
+				err = os.MkdirAll(filepath.Dir(filepath.Join(monDataDir, filePath)), 0700)
+				assert.NoError(t, err)
+				err = ioutil.WriteFile(filepath.Join(monDataDir, filePath), []byte(content), 0666)
+				assert.NoError(t, err)
+			}
+
+			Conf = &Config{
+				SysFSRootDir: sysFSRootDir,
+			}
+			if tt.fields.invalidPath {
+				Conf.SysFSRootDir = "invalidPath"
+			}
+
+			got, err := ReadResctrlMonData("BE")
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_ReadResctrlNumClosids(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "throw an error when num_closids does not exist",
+			files:   map[string]string{"L3_MON/num_rmids": "64\n"},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:    "throw an error for invalid content",
+			files:   map[string]string{"L3/num_closids": "invalid\n"},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:    "read num closids of L3",
+			files:   map[string]string{"L3/num_closids": "16\n", "L3_MON/num_rmids": "64\n"},
+			want:    16,
+			wantErr: false,
+		},
+		{
+			name:    "read the minimum num closids of L3 and MB",
+			files:   map[string]string{"L3/num_closids": "16\n", "MB/num_closids": "8\n"},
+			want:    8,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sysFSRootDir, _ := ioutil.TempDir("", "ReadResctrlNumClosids")
+			defer os.RemoveAll(sysFSRootDir)
+			Conf = &Config{
+				SysFSRootDir: sysFSRootDir,
+			}
+			for file, content := range tt.files {
+				filePath := filepath.Join(GetResctrlSubsystemDirPath(), RdtInfoDir, file)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0700))
+				assert.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0666))
+			}
+
+			got, err := ReadResctrlNumClosids()
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_CountResctrlCtrlGroups(t *testing.T) {
+	sysFSRootDir, _ := ioutil.TempDir("", "CountResctrlCtrlGroups")
+	defer os.RemoveAll(sysFSRootDir)
+	Conf = &Config{
+		SysFSRootDir: sysFSRootDir,
+	}
+	_, err := CountResctrlCtrlGroups()
+	assert.Error(t, err)
+
+	for _, dir := range []string{RdtInfoDir, ResctrlMonGroupsDir, ResctrlMonDataDir, "BE", "LS", "other-agent"} {
+		assert.NoError(t, os.MkdirAll(GetResctrlGroupRootDirPath(dir), 0700))
+	}
+	assert.NoError(t, ioutil.WriteFile(GetResctrlSchemataFilePath(""), []byte("L3:0=fff\n"), 0666))
+	got, err := CountResctrlCtrlGroups()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, got) // root, BE, LS and other-agent
+}
@@ -124,6 +124,15 @@ func GenerateCPUSetStr(cpuset []int32) string {
 	return strings.Trim(strings.Join(strings.Fields(fmt.Sprint(cpuset)), ","), "[]")
 }
 
+// ReadCgroupCPUSet reads the cgroup cpuset file according to the specified cgroup dir
+func ReadCgroupCPUSet(cgroupFileDir string) ([]int32, error) {
+	rawContent, err := ioutil.ReadFile(filepath.Join(cgroupFileDir, system.CPUSFileName))
+	if err != nil {
+		return nil, err
+	}
+	return ParseCPUSetStr(string(rawContent))
+}
+
 // WriteCgroupCPUSet writes the cgroup cpuset file according to the specified cgroup dir
 func WriteCgroupCPUSet(cgroupFileDir, cpusetStr string) error {
 	return ioutil.WriteFile(filepath.Join(cgroupFileDir, system.CPUSFileName), []byte(cpusetStr), 0644)